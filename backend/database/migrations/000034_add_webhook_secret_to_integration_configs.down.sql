@@ -0,0 +1,4 @@
+-- Migration: Remove webhook secret from integration_configs
+
+ALTER TABLE integration_configs
+    DROP COLUMN encrypted_webhook_secret;
@@ -0,0 +1,5 @@
+-- Migration: Add webhook secret to integration_configs
+-- Stores the (encrypted) secret used to verify the signature of provider webhook deliveries
+
+ALTER TABLE integration_configs
+    ADD COLUMN encrypted_webhook_secret TEXT;
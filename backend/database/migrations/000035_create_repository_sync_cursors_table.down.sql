@@ -0,0 +1,4 @@
+-- Migration: Drop repository_sync_cursors table
+
+DROP INDEX IF EXISTS idx_repository_sync_cursors_integration_config_id;
+DROP TABLE IF EXISTS repository_sync_cursors;
@@ -0,0 +1,15 @@
+-- Migration: Create repository_sync_cursors table
+-- Stores the most recent pull request update seen per repository, so syncs only fetch what changed since
+
+CREATE TABLE repository_sync_cursors (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    integration_config_id UUID NOT NULL,
+    repository VARCHAR(255) NOT NULL,
+    last_updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (integration_config_id) REFERENCES integration_configs(id) ON DELETE CASCADE,
+    UNIQUE (integration_config_id, repository)
+);
+
+CREATE INDEX idx_repository_sync_cursors_integration_config_id ON repository_sync_cursors(integration_config_id);
@@ -0,0 +1,17 @@
+-- Migration: Revert the repositories of GitHub integrations to comma-separated names
+-- Sync rules other than the repository names are dropped
+
+UPDATE integration_configs
+SET metadata = jsonb_set(
+    metadata,
+    '{repositories}',
+    to_jsonb(COALESCE(
+        (
+            SELECT string_agg(repository->>'name', ',')
+            FROM jsonb_array_elements(metadata->'repositories') AS repository
+        ),
+        ''
+    ))
+)
+WHERE provider_name = 'github'
+  AND jsonb_typeof(metadata->'repositories') = 'array';
@@ -0,0 +1,19 @@
+-- Migration: Convert the repositories of GitHub integrations to repository sync rules
+-- GitHub syncs used to skip every pull request whose base branch is "prod". That rule is now part of the
+-- per-repository sync rules, existing integrations keep it as an explicit exclude_base_branches rule.
+
+UPDATE integration_configs
+SET metadata = jsonb_set(
+    metadata,
+    '{repositories}',
+    COALESCE(
+        (
+            SELECT jsonb_agg(jsonb_build_object('name', trim(repository), 'exclude_base_branches', jsonb_build_array('prod')))
+            FROM unnest(string_to_array(metadata->>'repositories', ',')) AS repository
+            WHERE trim(repository) <> ''
+        ),
+        '[]'::jsonb
+    )
+)
+WHERE provider_name = 'github'
+  AND jsonb_typeof(metadata->'repositories') = 'string';
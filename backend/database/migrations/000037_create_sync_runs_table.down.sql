@@ -0,0 +1,4 @@
+-- Migration: Drop sync_runs table
+
+DROP INDEX IF EXISTS idx_sync_runs_integration_config_id_started_at;
+DROP TABLE IF EXISTS sync_runs;
@@ -0,0 +1,18 @@
+-- Migration: Create sync_runs table
+-- Records every sync of an integration, per repository for source control integrations
+
+CREATE TABLE sync_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    integration_config_id UUID NOT NULL,
+    repository VARCHAR(255),
+    status VARCHAR(50) NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    pull_requests_upserted INTEGER NOT NULL DEFAULT 0,
+    comments_upserted INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (integration_config_id) REFERENCES integration_configs(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_sync_runs_integration_config_id_started_at ON sync_runs(integration_config_id, started_at DESC);
@@ -0,0 +1,3 @@
+-- Migration: Drop integration_sync_leases table
+
+DROP TABLE IF EXISTS integration_sync_leases;
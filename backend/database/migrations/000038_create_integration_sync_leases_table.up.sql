@@ -0,0 +1,11 @@
+-- Migration: Create integration_sync_leases table
+-- A replica holds the lease of an integration while syncing it, so an integration is synced by one replica at a time.
+-- Leases are renewed during the sync and can be taken over once expired, e.g. when the replica died mid-run.
+
+CREATE TABLE integration_sync_leases (
+    integration_config_id UUID PRIMARY KEY,
+    holder VARCHAR(255) NOT NULL,
+    acquired_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    FOREIGN KEY (integration_config_id) REFERENCES integration_configs(id) ON DELETE CASCADE
+);
@@ -0,0 +1,3 @@
+-- Migration: Remove sync_schedule from integration_configs
+
+ALTER TABLE integration_configs DROP COLUMN IF EXISTS sync_schedule;
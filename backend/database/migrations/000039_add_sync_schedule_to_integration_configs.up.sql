@@ -0,0 +1,4 @@
+-- Migration: Add sync_schedule to integration_configs
+-- Cron expression of the integration's sync schedule, integrations without one use the default schedule of their provider type
+
+ALTER TABLE integration_configs ADD COLUMN sync_schedule VARCHAR(100);
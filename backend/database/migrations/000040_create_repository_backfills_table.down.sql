@@ -0,0 +1,5 @@
+-- Migration: Drop repository_backfills table
+
+DROP INDEX IF EXISTS idx_repository_backfills_status;
+DROP INDEX IF EXISTS idx_repository_backfills_integration_config_id;
+DROP TABLE IF EXISTS repository_backfills;
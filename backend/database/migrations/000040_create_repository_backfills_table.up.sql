@@ -0,0 +1,24 @@
+-- Migration: Create repository_backfills table
+-- Imports the pull request history of a repository between two dates in chunks. The checkpoint is the end of
+-- the last imported chunk, so a backfill resumes from it after a crash or a rate limit pause.
+
+CREATE TABLE repository_backfills (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    integration_config_id UUID NOT NULL,
+    repository VARCHAR(255) NOT NULL,
+    start_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    checkpoint TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    paused_until TIMESTAMP WITH TIME ZONE,
+    pull_requests_synced INTEGER NOT NULL DEFAULT 0,
+    comments_synced INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    FOREIGN KEY (integration_config_id) REFERENCES integration_configs(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_repository_backfills_integration_config_id ON repository_backfills(integration_config_id, created_at DESC);
+CREATE INDEX idx_repository_backfills_status ON repository_backfills(status);
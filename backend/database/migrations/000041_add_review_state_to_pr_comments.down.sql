@@ -0,0 +1,4 @@
+-- Migration: Remove review_state from pr_comments
+
+DROP INDEX IF EXISTS idx_pr_comments_pr_id_review_state;
+ALTER TABLE pr_comments DROP COLUMN IF EXISTS review_state;
@@ -0,0 +1,14 @@
+-- Migration: Add review_state to pr_comments
+-- Reviews are stored as REVIEW comments, the state tells approvals from "changes requested" reviews.
+-- GitLab and Bitbucket reviews are identified by their provider ID, GitHub reviews get their state when
+-- their pull request is synced again.
+
+ALTER TABLE pr_comments ADD COLUMN review_state VARCHAR(50);
+
+UPDATE pr_comments SET review_state = 'APPROVED'
+WHERE type = 'REVIEW' AND provider_id LIKE 'approval-%';
+
+UPDATE pr_comments SET review_state = 'CHANGES_REQUESTED'
+WHERE type = 'REVIEW' AND provider_id LIKE 'changes-requested-%';
+
+CREATE INDEX idx_pr_comments_pr_id_review_state ON pr_comments(pr_id, review_state) WHERE review_state IS NOT NULL;
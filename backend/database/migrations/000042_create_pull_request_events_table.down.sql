@@ -0,0 +1,3 @@
+-- Migration: Drop pull_request_events table
+
+DROP TABLE IF EXISTS pull_request_events;
@@ -0,0 +1,18 @@
+-- Migration: Create pull_request_events table
+-- Timeline of pull requests: when they became ready for review, had reviewers requested, were reopened, etc.
+
+CREATE TABLE pull_request_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pr_id UUID NOT NULL,
+    external_account_id UUID NOT NULL,
+    provider_id VARCHAR(255) NOT NULL,
+    event VARCHAR(50) NOT NULL,
+    metadata JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    FOREIGN KEY (pr_id) REFERENCES pull_requests(id) ON DELETE CASCADE,
+    FOREIGN KEY (external_account_id) REFERENCES member_external_accounts(id) ON DELETE CASCADE,
+    UNIQUE (pr_id, provider_id)
+);
+
+CREATE INDEX idx_pull_request_events_pr_id_created_at ON pull_request_events(pr_id, created_at);
+CREATE INDEX idx_pull_request_events_event_created_at ON pull_request_events(event, created_at);
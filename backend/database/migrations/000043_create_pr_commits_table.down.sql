@@ -0,0 +1,3 @@
+-- Migration: Drop pr_commits table
+
+DROP TABLE IF EXISTS pr_commits;
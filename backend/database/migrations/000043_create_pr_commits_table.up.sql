@@ -0,0 +1,25 @@
+-- Migration: Create pr_commits table
+-- Commits of pull requests, their author is linked to an external account when the provider knows it
+
+CREATE TABLE pr_commits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pr_id UUID NOT NULL,
+    external_account_id UUID,
+    sha VARCHAR(64) NOT NULL,
+    message TEXT,
+    author_name VARCHAR(255),
+    author_email VARCHAR(255),
+    authored_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    committer_name VARCHAR(255),
+    committer_email VARCHAR(255),
+    committed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    additions INTEGER NOT NULL DEFAULT 0,
+    deletions INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (pr_id) REFERENCES pull_requests(id) ON DELETE CASCADE,
+    FOREIGN KEY (external_account_id) REFERENCES member_external_accounts(id) ON DELETE SET NULL,
+    UNIQUE (pr_id, sha)
+);
+
+CREATE INDEX idx_pr_commits_pr_id_committed_at ON pr_commits(pr_id, committed_at);
+CREATE INDEX idx_pr_commits_external_account_id ON pr_commits(external_account_id);
@@ -0,0 +1,3 @@
+-- Migration: Drop pr_files table
+
+DROP TABLE IF EXISTS pr_files;
@@ -0,0 +1,16 @@
+-- Migration: Create pr_files table
+-- Files changed by pull requests, used to find the most frequently changed paths of repositories
+
+CREATE TABLE pr_files (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pr_id UUID NOT NULL,
+    path TEXT NOT NULL,
+    previous_path TEXT,
+    status VARCHAR(50) NOT NULL,
+    additions INTEGER NOT NULL DEFAULT 0,
+    deletions INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (pr_id) REFERENCES pull_requests(id) ON DELETE CASCADE,
+    UNIQUE (pr_id, path)
+);
+
+CREATE INDEX idx_pr_files_path ON pr_files(path);
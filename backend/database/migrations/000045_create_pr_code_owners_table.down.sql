@@ -0,0 +1,3 @@
+-- Migration: Drop pr_code_owners table
+
+DROP TABLE IF EXISTS pr_code_owners;
@@ -0,0 +1,14 @@
+-- Migration: Create pr_code_owners table
+-- Code owners required to review pull requests by the CODEOWNERS file of their repository, and whether they did
+
+CREATE TABLE pr_code_owners (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pr_id UUID NOT NULL,
+    owner VARCHAR(255) NOT NULL,
+    files INTEGER NOT NULL DEFAULT 0,
+    reviewed BOOLEAN NOT NULL DEFAULT FALSE,
+    reviewed_by UUID,
+    FOREIGN KEY (pr_id) REFERENCES pull_requests(id) ON DELETE CASCADE,
+    FOREIGN KEY (reviewed_by) REFERENCES member_external_accounts(id) ON DELETE SET NULL,
+    UNIQUE (pr_id, owner)
+);
@@ -0,0 +1,4 @@
+-- Migration: Drop deployments and deployment_statuses tables
+
+DROP TABLE IF EXISTS deployment_statuses;
+DROP TABLE IF EXISTS deployments;
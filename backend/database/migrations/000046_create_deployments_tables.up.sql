@@ -0,0 +1,34 @@
+-- Migration: Create deployments and deployment_statuses tables
+-- Deployments of repositories, from the provider's deployments or its releases, used for the DORA metrics
+
+CREATE TABLE deployments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL,
+    repository_name VARCHAR(255) NOT NULL,
+    provider_id VARCHAR(255) NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    environment VARCHAR(255) NOT NULL,
+    production BOOLEAN NOT NULL DEFAULT FALSE,
+    sha VARCHAR(64),
+    ref VARCHAR(255),
+    status VARCHAR(50) NOT NULL,
+    external_account_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (external_account_id) REFERENCES member_external_accounts(id) ON DELETE SET NULL,
+    UNIQUE (organization_id, source, provider_id)
+);
+
+CREATE INDEX idx_deployments_organization_id_repository_name_created_at ON deployments(organization_id, repository_name, created_at);
+
+CREATE TABLE deployment_statuses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    deployment_id UUID NOT NULL,
+    provider_id VARCHAR(255) NOT NULL,
+    state VARCHAR(50) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    FOREIGN KEY (deployment_id) REFERENCES deployments(id) ON DELETE CASCADE,
+    UNIQUE (deployment_id, provider_id)
+);
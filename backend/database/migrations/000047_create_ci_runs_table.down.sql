@@ -0,0 +1,3 @@
+-- Migration: Drop ci_runs table
+
+DROP TABLE IF EXISTS ci_runs;
@@ -0,0 +1,21 @@
+-- Migration: Create ci_runs table
+-- CI runs of pull requests, from GitHub Actions workflow runs and the check runs of other CI providers
+
+CREATE TABLE ci_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pr_id UUID NOT NULL,
+    provider_id VARCHAR(255) NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    head_sha VARCHAR(64) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    conclusion VARCHAR(50),
+    attempt INTEGER NOT NULL DEFAULT 1,
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    FOREIGN KEY (pr_id) REFERENCES pull_requests(id) ON DELETE CASCADE,
+    UNIQUE (pr_id, source, provider_id)
+);
+
+CREATE INDEX idx_ci_runs_pr_id_head_sha ON ci_runs(pr_id, head_sha);
@@ -0,0 +1,3 @@
+-- Migration: Drop identity_matches table
+
+DROP TABLE IF EXISTS identity_matches;
@@ -0,0 +1,23 @@
+-- Migration: Create identity_matches table
+-- Proposed links between external accounts and organization members, with the signals they were found by and their review status
+
+CREATE TABLE identity_matches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL,
+    external_account_id UUID NOT NULL,
+    member_id UUID NOT NULL,
+    confidence DOUBLE PRECISION NOT NULL,
+    signals JSONB NOT NULL DEFAULT '[]',
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    reviewed_by UUID,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (external_account_id) REFERENCES member_external_accounts(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES organization_members(id) ON DELETE CASCADE,
+    FOREIGN KEY (reviewed_by) REFERENCES users(id) ON DELETE SET NULL,
+    UNIQUE (external_account_id, member_id)
+);
+
+CREATE INDEX idx_identity_matches_organization_id_status ON identity_matches(organization_id, status);
@@ -0,0 +1,5 @@
+-- Migration: Drop bot_rules table and the is_bot flag of external accounts
+
+ALTER TABLE member_external_accounts DROP COLUMN IF EXISTS is_bot;
+
+DROP TABLE IF EXISTS bot_rules;
@@ -0,0 +1,21 @@
+-- Migration: Create bot_rules table and flag bot external accounts
+-- Organizations register their bot and service accounts by username or by a regular expression on the username.
+-- Accounts matching a rule, or reported as bots by their provider, are flagged with is_bot and left out of the metrics.
+
+CREATE TABLE bot_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL,
+    username VARCHAR(255),
+    pattern VARCHAR(255),
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    CHECK ((username IS NULL) <> (pattern IS NULL))
+);
+
+CREATE INDEX idx_bot_rules_organization_id ON bot_rules(organization_id);
+
+ALTER TABLE member_external_accounts ADD COLUMN is_bot BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE member_external_accounts SET is_bot = TRUE
+WHERE metadata->>'type' = 'Bot';
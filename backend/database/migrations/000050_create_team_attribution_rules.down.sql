@@ -0,0 +1,9 @@
+-- Migration: Drop team_attribution_rules table and the team attribution of pull requests
+
+DROP INDEX IF EXISTS idx_pull_requests_team_id;
+
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS labels;
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS head_branch;
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS team_id;
+
+DROP TABLE IF EXISTS team_attribution_rules;
@@ -0,0 +1,45 @@
+-- Migration: Create team_attribution_rules table and attribute pull requests to teams
+-- A rule attributes the pull requests matching it to a team, by ticket key prefix, title or branch regex, label,
+-- repository name or changed file glob. Rules are evaluated by descending priority, the first match wins.
+-- Pull requests keep the team they were attributed to, their head branch and labels are stored so that the
+-- rules can be evaluated again for the pull requests already imported.
+
+CREATE TABLE team_attribution_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL,
+    team_id UUID NOT NULL,
+    type VARCHAR(50) NOT NULL CHECK (type IN ('ticket_prefix', 'title_regex', 'branch_regex', 'label', 'repository', 'path_glob')),
+    pattern VARCHAR(255) NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_team_attribution_rules_organization_id ON team_attribution_rules(organization_id);
+
+ALTER TABLE pull_requests ADD COLUMN team_id UUID REFERENCES teams(id) ON DELETE SET NULL;
+ALTER TABLE pull_requests ADD COLUMN head_branch VARCHAR(255);
+ALTER TABLE pull_requests ADD COLUMN labels JSONB;
+
+CREATE INDEX idx_pull_requests_team_id ON pull_requests(team_id);
+
+-- GitHub stores the head branch under head.ref and labels as objects, GitLab and Bitbucket store the source
+-- branch, GitLab labels are plain strings
+UPDATE pull_requests SET
+    head_branch = COALESCE(metadata->'head'->>'ref', metadata->>'source_branch'),
+    labels = COALESCE((
+        SELECT jsonb_agg(CASE jsonb_typeof(label) WHEN 'object' THEN label->>'name' ELSE label #>> '{}' END)
+        FROM jsonb_array_elements(CASE WHEN jsonb_typeof(metadata->'labels') = 'array' THEN metadata->'labels' ELSE '[]'::jsonb END) label
+    ), '[]'::jsonb)
+WHERE metadata IS NOT NULL;
+
+-- Attribute the pull requests matched by the team prefix so far
+UPDATE pull_requests pr
+SET team_id = t.id
+FROM member_external_accounts sca
+INNER JOIN teams t ON t.organization_id = sca.organization_id
+WHERE pr.external_account_id = sca.id
+  AND pr.prefix IS NOT NULL
+  AND UPPER(t.pr_prefix) = UPPER(pr.prefix);
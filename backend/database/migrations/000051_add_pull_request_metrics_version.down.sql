@@ -0,0 +1,5 @@
+-- Migration: Remove metrics_version from pull_requests
+
+DROP INDEX IF EXISTS idx_pull_requests_metrics_version;
+
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS metrics_version;
@@ -0,0 +1,8 @@
+-- Migration: Add metrics_version to pull_requests
+-- The metrics of a pull request are calculated at sync time and stored in its metrics JSON. metrics_version records
+-- the version of the calculation they were stored with, the recompute job rebuilds the metrics of the pull requests
+-- stored with an older version. Existing metrics have version 0 and are all recomputed.
+
+ALTER TABLE pull_requests ADD COLUMN metrics_version INT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_pull_requests_metrics_version ON pull_requests(metrics_version);
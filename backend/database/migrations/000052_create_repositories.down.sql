@@ -0,0 +1,7 @@
+-- Migration: Drop repositories table and the repository of pull requests
+
+DROP INDEX IF EXISTS idx_pull_requests_repository_id;
+
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS repository_id;
+
+DROP TABLE IF EXISTS repositories;
@@ -0,0 +1,67 @@
+-- Migration: Create repositories table and link pull requests to their repository
+-- Repositories are populated by the source control sync with their provider metadata. A repository can be owned by a
+-- team, pull requests reference the repository they were opened against.
+
+CREATE TABLE repositories (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    owner VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    default_branch VARCHAR(255),
+    archived BOOLEAN NOT NULL DEFAULT FALSE,
+    visibility VARCHAR(50),
+    team_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE SET NULL,
+    UNIQUE (organization_id, provider, owner, name)
+);
+
+CREATE INDEX idx_repositories_team_id ON repositories(team_id);
+
+ALTER TABLE pull_requests ADD COLUMN repository_id UUID REFERENCES repositories(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_pull_requests_repository_id ON pull_requests(repository_id);
+
+-- Create the repositories of the pull requests already imported, with the owner and name the sync saves them with.
+-- GitHub stores them in the base repository of the pull request, the other providers only in the pull request URL:
+--   GitLab:                 https://host/<namespace>/<project>/-/merge_requests/<iid>
+--   Bitbucket Data Center:  https://host/projects/<key>/repos/<slug>/pull-requests/<id>
+--   Bitbucket Cloud:        https://host/<workspace>/<slug>/pull-requests/<id>
+-- Pull requests whose repository cannot be found are linked the next time their repository is synced.
+CREATE TEMPORARY TABLE pull_request_repositories AS
+SELECT pr.id AS pull_request_id, sca.organization_id, sca.provider_name AS provider,
+    CASE sca.provider_name
+        WHEN 'github' THEN pr.metadata->'base'->'repo'->'owner'->>'login'
+        WHEN 'gitlab' THEN substring(pr.url from '^https?://[^/]+/(.+)/[^/]+/-/merge_requests/')
+        WHEN 'bitbucket' THEN COALESCE(
+            substring(pr.url from '/projects/([^/]+)/repos/[^/]+/pull-requests/'),
+            substring(pr.url from '^https?://[^/]+/([^/]+)/[^/]+/pull-requests/'))
+    END AS owner,
+    CASE sca.provider_name
+        WHEN 'github' THEN pr.metadata->'base'->'repo'->>'name'
+        WHEN 'gitlab' THEN substring(pr.url from '/([^/]+)/-/merge_requests/')
+        WHEN 'bitbucket' THEN substring(pr.url from '/([^/]+)/pull-requests/')
+    END AS name
+FROM pull_requests pr
+INNER JOIN member_external_accounts sca ON pr.external_account_id = sca.id
+WHERE sca.organization_id IS NOT NULL;
+
+INSERT INTO repositories (organization_id, provider, owner, name)
+SELECT DISTINCT organization_id, provider, owner, name
+FROM pull_request_repositories
+WHERE owner IS NOT NULL AND name IS NOT NULL
+ON CONFLICT (organization_id, provider, owner, name) DO NOTHING;
+
+UPDATE pull_requests pr
+SET repository_id = r.id
+FROM pull_request_repositories prr
+INNER JOIN repositories r ON r.organization_id = prr.organization_id
+    AND r.provider = prr.provider
+    AND r.owner = prr.owner
+    AND r.name = prr.name
+WHERE pr.id = prr.pull_request_id;
+
+DROP TABLE pull_request_repositories;
@@ -0,0 +1,13 @@
+-- Migration: Key CI runs without attempt
+-- Only the latest attempt of each CI run is kept
+
+ALTER TABLE ci_runs DROP CONSTRAINT IF EXISTS ci_runs_pr_id_source_provider_id_attempt_key;
+
+DELETE FROM ci_runs cr
+USING ci_runs latest
+WHERE latest.pr_id = cr.pr_id
+AND latest.source = cr.source
+AND latest.provider_id = cr.provider_id
+AND latest.attempt > cr.attempt;
+
+ALTER TABLE ci_runs ADD CONSTRAINT ci_runs_pr_id_source_provider_id_key UNIQUE (pr_id, source, provider_id);
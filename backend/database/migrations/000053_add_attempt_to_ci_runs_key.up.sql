@@ -0,0 +1,6 @@
+-- Migration: Key CI runs by attempt
+-- Every attempt of a re-run CI run is stored, instead of only the latest attempt
+
+ALTER TABLE ci_runs DROP CONSTRAINT IF EXISTS ci_runs_pr_id_source_provider_id_key;
+
+ALTER TABLE ci_runs ADD CONSTRAINT ci_runs_pr_id_source_provider_id_attempt_key UNIQUE (pr_id, source, provider_id, attempt);
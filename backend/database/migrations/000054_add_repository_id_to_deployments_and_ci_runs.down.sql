@@ -0,0 +1,9 @@
+-- Migration: Drop the repository of deployments and CI runs
+
+DROP INDEX IF EXISTS idx_ci_runs_repository_id;
+
+ALTER TABLE ci_runs DROP COLUMN IF EXISTS repository_id;
+
+DROP INDEX IF EXISTS idx_deployments_repository_id_created_at;
+
+ALTER TABLE deployments DROP COLUMN IF EXISTS repository_id;
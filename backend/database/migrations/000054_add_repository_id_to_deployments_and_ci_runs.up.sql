@@ -0,0 +1,34 @@
+-- Migration: Link deployments and CI runs to their repository
+-- The DORA and CI metrics group deployments and CI runs by repository, repository names are only unique per owner.
+
+ALTER TABLE deployments ADD COLUMN repository_id UUID REFERENCES repositories(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_deployments_repository_id_created_at ON deployments(repository_id, created_at);
+
+ALTER TABLE ci_runs ADD COLUMN repository_id UUID REFERENCES repositories(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_ci_runs_repository_id ON ci_runs(repository_id);
+
+-- CI runs are in the repository of their pull request
+UPDATE ci_runs cr
+SET repository_id = pr.repository_id
+FROM pull_requests pr
+WHERE pr.id = cr.pr_id
+AND pr.repository_id IS NOT NULL;
+
+-- Deployments are only synced from GitHub and saved with the name of their repository, without its owner. They are
+-- linked when the organization has a single GitHub repository with that name, the others are linked when they are
+-- synced again and are left out of the DORA metrics until then.
+UPDATE deployments d
+SET repository_id = r.id
+FROM repositories r
+WHERE r.organization_id = d.organization_id
+AND r.provider = 'github'
+AND r.name = d.repository_name
+AND NOT EXISTS (
+    SELECT 1 FROM repositories same_name
+    WHERE same_name.organization_id = r.organization_id
+    AND same_name.provider = r.provider
+    AND same_name.name = r.name
+    AND same_name.id != r.id
+);
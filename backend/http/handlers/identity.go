@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"ems.dev/backend/http/utils"
+	identityapi "ems.dev/backend/services/identity/api"
+	"ems.dev/backend/services/identity/types"
+	orgapi "ems.dev/backend/services/organization/api"
+	"github.com/gin-gonic/gin"
+)
+
+// IdentityHandler handles the identity resolution HTTP requests, which link external accounts to members
+type IdentityHandler struct {
+	identityApi identityapi.IdentityAPI
+	orgApi      orgapi.OrganizationAPI
+}
+
+// NewIdentityHandler creates a new instance of IdentityHandler
+func NewIdentityHandler(identityApi identityapi.IdentityAPI, orgApi orgapi.OrganizationAPI) *IdentityHandler {
+	return &IdentityHandler{
+		identityApi: identityApi,
+		orgApi:      orgApi,
+	}
+}
+
+// RegisterRoutes registers all identity resolution routes with the provided router group.
+// It sets up the following routes:
+// - POST /api/organizations/:id/identity-matches/resolve - Propose members for the unlinked external accounts
+// - GET /api/organizations/:id/identity-matches - List the identity matches, the review queue by default
+// - POST /api/organizations/:id/identity-matches/:matchId/accept - Link the account of a match to its member
+// - POST /api/organizations/:id/identity-matches/:matchId/reject - Reject a match
+func (h *IdentityHandler) RegisterRoutes(router *gin.RouterGroup) {
+	organizations := router.Group("/organizations")
+	{
+		organizations.POST("/:id/identity-matches/resolve", h.ResolveIdentities)
+		organizations.GET("/:id/identity-matches", h.ListIdentityMatches)
+		organizations.POST("/:id/identity-matches/:matchId/accept", h.AcceptIdentityMatch)
+		organizations.POST("/:id/identity-matches/:matchId/reject", h.RejectIdentityMatch)
+	}
+}
+
+// ResolveIdentities handles the POST /api/organizations/:id/identity-matches/resolve endpoint.
+// It proposes members for the external accounts which aren't linked to one, replacing the pending matches.
+// The body is optional, auto_apply links the accounts with a single confident match.
+// Returns:
+// - 200: The proposed and auto applied matches
+// - 400: If the organization ID or the body is invalid
+// - 403: If the user is not an owner of the organization
+// - 500: If there's a database error
+func (h *IdentityHandler) ResolveIdentities(c *gin.Context) {
+	orgID, err := utils.GetOrganizationIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Check if user is an owner of the organization
+	if !utils.CheckOrganizationOwnership(c, h.orgApi, orgID) {
+		return
+	}
+
+	var params types.ResolveIdentitiesParams
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&params); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	matches, err := h.identityApi.ResolveIdentities(c.Request.Context(), orgID, params)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"identity_matches": matches})
+}
+
+// ListIdentityMatches handles the GET /api/organizations/:id/identity-matches endpoint.
+// It lists the matches with the given status, pending by default.
+// Query Parameters:
+// - status: Optional status filter, one of pending, accepted, rejected and auto_applied
+// Returns:
+// - 200: The identity matches, most confident first
+// - 400: If the organization ID is missing
+// - 403: If the user does not have access to the organization
+// - 500: If there's a database error
+func (h *IdentityHandler) ListIdentityMatches(c *gin.Context) {
+	orgID, err := utils.GetOrganizationIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Check if user has access to the organization
+	if !utils.CheckOrganizationMembership(c, h.orgApi, &orgID) {
+		return
+	}
+
+	status := c.DefaultQuery("status", types.IdentityMatchStatusPending)
+
+	matches, err := h.identityApi.ListIdentityMatches(c.Request.Context(), &types.IdentityMatchParams{
+		OrganizationID: orgID,
+		Statuses:       []string{status},
+	})
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"identity_matches": matches})
+}
+
+// AcceptIdentityMatch handles the POST /api/organizations/:id/identity-matches/:matchId/accept endpoint.
+// It links the external account of a pending match to its member.
+// Returns:
+// - 200: The accepted match
+// - 403: If the user is not an owner of the organization
+// - 404: If the match is not found
+// - 409: If the match was already reviewed
+// - 500: If there's a database error
+func (h *IdentityHandler) AcceptIdentityMatch(c *gin.Context) {
+	h.reviewIdentityMatch(c, h.identityApi.AcceptIdentityMatch)
+}
+
+// RejectIdentityMatch handles the POST /api/organizations/:id/identity-matches/:matchId/reject endpoint.
+// It rejects a pending match, which isn't proposed again.
+// Returns:
+// - 200: The rejected match
+// - 403: If the user is not an owner of the organization
+// - 404: If the match is not found
+// - 409: If the match was already reviewed
+// - 500: If there's a database error
+func (h *IdentityHandler) RejectIdentityMatch(c *gin.Context) {
+	h.reviewIdentityMatch(c, h.identityApi.RejectIdentityMatch)
+}
+
+// reviewIdentityMatch validates a review request and saves it with the given review function
+func (h *IdentityHandler) reviewIdentityMatch(c *gin.Context, review func(ctx context.Context, organizationID string, matchID string, userID string) (*types.IdentityMatch, error)) {
+	orgID, err := utils.GetOrganizationIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	matchID := c.Param("matchId")
+	if matchID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "match ID is required"})
+		return
+	}
+
+	// Check if user is an owner of the organization
+	if !utils.CheckOrganizationOwnership(c, h.orgApi, orgID) {
+		return
+	}
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	match, err := review(c.Request.Context(), orgID, matchID, user.ID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"identity_match": match})
+}
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 
 	httptypes "ems.dev/backend/http/types/integration"
 	"ems.dev/backend/http/utils"
@@ -11,15 +13,22 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// IntegrationSyncer syncs an integration on demand
+type IntegrationSyncer interface {
+	TriggerSync(integration *inttypes.IntegrationConfig) error
+}
+
 type IntegrationHandler struct {
 	integrationAPI intapi.IntegrationAPI
 	orgAPI         orgapi.OrganizationAPI
+	syncers        map[inttypes.IntegrationProviderType]IntegrationSyncer
 }
 
-func NewIntegrationHandler(integrationAPI intapi.IntegrationAPI, orgAPI orgapi.OrganizationAPI) *IntegrationHandler {
+func NewIntegrationHandler(integrationAPI intapi.IntegrationAPI, orgAPI orgapi.OrganizationAPI, syncers map[inttypes.IntegrationProviderType]IntegrationSyncer) *IntegrationHandler {
 	return &IntegrationHandler{
 		integrationAPI: integrationAPI,
 		orgAPI:         orgAPI,
+		syncers:        syncers,
 	}
 }
 
@@ -154,6 +163,158 @@ func (h *IntegrationHandler) DeleteIntegrationConfig(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// GetSyncRuns handles listing the sync runs of an integration config, most recent first
+func (h *IntegrationHandler) GetSyncRuns(c *gin.Context) {
+	if len(c.Params) != 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid number of parameters"})
+		return
+	}
+
+	id := c.Params[1].Value
+
+	config, err := h.integrationAPI.GetIntegrationConfig(c.Request.Context(), id)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	// Check if user is a member of the organization
+	if !utils.CheckOrganizationMembership(c, h.orgAPI, &config.OrganizationID) {
+		return
+	}
+
+	params := &inttypes.SyncRunParams{}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		params.Limit = limit
+	}
+
+	if repository := c.Query("repository"); repository != "" {
+		params.Repository = &repository
+	}
+
+	if statusStr := c.Query("status"); statusStr != "" {
+		status := inttypes.SyncRunStatus(statusStr)
+		switch status {
+		case inttypes.SyncRunStatusRunning, inttypes.SyncRunStatusSucceeded, inttypes.SyncRunStatusFailed, inttypes.SyncRunStatusRateLimited:
+			params.Status = &status
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid status %q", statusStr)})
+			return
+		}
+	}
+
+	runs, err := h.integrationAPI.GetSyncRuns(c.Request.Context(), id, params)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sync_runs": runs})
+}
+
+// TriggerSync handles starting an immediate sync of an integration config
+func (h *IntegrationHandler) TriggerSync(c *gin.Context) {
+	if len(c.Params) != 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid number of parameters"})
+		return
+	}
+
+	id := c.Params[1].Value
+
+	config, err := h.integrationAPI.GetIntegrationConfig(c.Request.Context(), id)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	// Check if user is an owner of the organization
+	if !utils.CheckOrganizationOwnership(c, h.orgAPI, config.OrganizationID) {
+		return
+	}
+
+	syncer, ok := h.syncers[config.ProviderType]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s integrations can't be synced", config.ProviderType)})
+		return
+	}
+
+	if err := syncer.TriggerSync(config); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "sync started"})
+}
+
+// CreateRepositoryBackfills handles starting backfills of the pull request history of an integration's repositories
+func (h *IntegrationHandler) CreateRepositoryBackfills(c *gin.Context) {
+	if len(c.Params) != 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid number of parameters"})
+		return
+	}
+
+	id := c.Params[1].Value
+
+	config, err := h.integrationAPI.GetIntegrationConfig(c.Request.Context(), id)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	// Check if user is an owner of the organization
+	if !utils.CheckOrganizationOwnership(c, h.orgAPI, config.OrganizationID) {
+		return
+	}
+
+	var req inttypes.CreateRepositoryBackfillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	backfills, err := h.integrationAPI.CreateRepositoryBackfills(c.Request.Context(), id, &req)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"backfills": backfills})
+}
+
+// GetRepositoryBackfills handles listing the backfills of an integration config with their progress
+func (h *IntegrationHandler) GetRepositoryBackfills(c *gin.Context) {
+	if len(c.Params) != 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid number of parameters"})
+		return
+	}
+
+	id := c.Params[1].Value
+
+	config, err := h.integrationAPI.GetIntegrationConfig(c.Request.Context(), id)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	// Check if user is a member of the organization
+	if !utils.CheckOrganizationMembership(c, h.orgAPI, &config.OrganizationID) {
+		return
+	}
+
+	backfills, err := h.integrationAPI.GetRepositoryBackfills(c.Request.Context(), id)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"backfills": backfills})
+}
+
 // RegisterRoutes registers the integration routes
 func (h *IntegrationHandler) RegisterRoutes(router *gin.RouterGroup) {
 	integrations := router.Group("/organizations/:id/integrations")
@@ -163,5 +324,9 @@ func (h *IntegrationHandler) RegisterRoutes(router *gin.RouterGroup) {
 		integrations.GET("/:id", h.GetIntegrationConfig)
 		integrations.PUT("/:id", h.UpdateIntegrationConfig)
 		integrations.DELETE("/:id", h.DeleteIntegrationConfig)
+		integrations.GET("/:id/sync-runs", h.GetSyncRuns)
+		integrations.POST("/:id/sync", h.TriggerSync)
+		integrations.POST("/:id/backfills", h.CreateRepositoryBackfills)
+		integrations.GET("/:id/backfills", h.GetRepositoryBackfills)
 	}
 }
@@ -373,6 +373,100 @@ func (h *MemberHandler) UpdateExternalAccount(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"external_account": updatedAccount})
 }
 
+// ListBotRules handles retrieving the bot rules of an organization
+// Returns:
+// - 200: Success response with list of bot rules
+// - 400: Bad request if organization ID is missing
+// - 403: Forbidden if user does not have access to the organization
+// - 500: Internal server error if service layer fails
+func (h *MemberHandler) ListBotRules(c *gin.Context) {
+	orgID, err := utils.GetOrganizationIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Check if user has access to the organization
+	if !utils.CheckOrganizationMembership(c, h.orgApi, &orgID) {
+		return
+	}
+
+	rules, err := h.memberApi.GetBotRules(c.Request.Context(), orgID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"bot_rules": rules})
+}
+
+// CreateBotRule handles registering a bot or service account by username or by a regular expression pattern.
+// The external accounts of the organization matching it are flagged as bots and left out of the metrics.
+// Returns:
+// - 201: The created bot rule
+// - 400: If the request body is invalid, or the pattern isn't a valid regular expression
+// - 403: If the user is not an owner of the organization
+// - 500: Internal server error if service layer fails
+func (h *MemberHandler) CreateBotRule(c *gin.Context) {
+	orgID, err := utils.GetOrganizationIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Check if user is an owner of the organization
+	if !utils.CheckOrganizationOwnership(c, h.orgApi, orgID) {
+		return
+	}
+
+	var req membertypes.CreateBotRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.memberApi.CreateBotRule(c.Request.Context(), orgID, req)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"bot_rule": rule})
+}
+
+// DeleteBotRule handles deleting a bot rule, the accounts no longer matching a rule count in the metrics again
+// Returns:
+// - 204: If the bot rule was deleted
+// - 400: If the rule ID is missing
+// - 403: If the user is not an owner of the organization
+// - 404: If the bot rule is not found
+// - 500: Internal server error if service layer fails
+func (h *MemberHandler) DeleteBotRule(c *gin.Context) {
+	orgID, err := utils.GetOrganizationIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ruleID := c.Param("ruleId")
+	if ruleID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rule ID is required"})
+		return
+	}
+
+	// Check if user is an owner of the organization
+	if !utils.CheckOrganizationOwnership(c, h.orgApi, orgID) {
+		return
+	}
+
+	if err := h.memberApi.DeleteBotRule(c.Request.Context(), orgID, ruleID); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 // RegisterRoutes registers all member-related routes
 func (h *MemberHandler) RegisterRoutes(api *gin.RouterGroup) {
 	members := api.Group("/organizations/:id/members")
@@ -390,4 +484,12 @@ func (h *MemberHandler) RegisterRoutes(api *gin.RouterGroup) {
 		externalAccounts.GET("/external-accounts", h.ListOrganizationExternalAccounts)
 		externalAccounts.PUT("/external-accounts/:accountId", h.UpdateExternalAccount)
 	}
+
+	// Bot and service account registry (organization-level)
+	botRules := api.Group("/organizations/:id/bot-rules")
+	{
+		botRules.GET("", h.ListBotRules)
+		botRules.POST("", h.CreateBotRule)
+		botRules.DELETE("/:ruleId", h.DeleteBotRule)
+	}
 }
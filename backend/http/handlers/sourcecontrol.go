@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
@@ -18,19 +19,26 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// MetricsRecomputeQueue queues the recompute of the stored metrics of pull requests
+type MetricsRecomputeQueue interface {
+	Queue(ctx context.Context, params *servicetypes.RecomputeMetricsParams) (*servicetypes.RecomputeMetricsResult, error)
+}
+
 type SourceControlHandler struct {
-	scApi      sourcecontrolapi.SourceControlAPI
-	orgApi     organizationapi.OrganizationAPI
-	metricsApi metricsapi.MetricsAPI
-	memberApi  memberapi.MemberAPI
+	scApi          sourcecontrolapi.SourceControlAPI
+	orgApi         organizationapi.OrganizationAPI
+	metricsApi     metricsapi.MetricsAPI
+	memberApi      memberapi.MemberAPI
+	recomputeQueue MetricsRecomputeQueue
 }
 
-func NewSourceControlHandler(scApi sourcecontrolapi.SourceControlAPI, orgApi organizationapi.OrganizationAPI, metricsApi metricsapi.MetricsAPI, memberApi memberapi.MemberAPI) *SourceControlHandler {
+func NewSourceControlHandler(scApi sourcecontrolapi.SourceControlAPI, orgApi organizationapi.OrganizationAPI, metricsApi metricsapi.MetricsAPI, memberApi memberapi.MemberAPI, recomputeQueue MetricsRecomputeQueue) *SourceControlHandler {
 	return &SourceControlHandler{
-		scApi:      scApi,
-		orgApi:     orgApi,
-		metricsApi: metricsApi,
-		memberApi:  memberApi,
+		scApi:          scApi,
+		orgApi:         orgApi,
+		metricsApi:     metricsApi,
+		memberApi:      memberApi,
+		recomputeQueue: recomputeQueue,
 	}
 }
 
@@ -40,6 +48,8 @@ func NewSourceControlHandler(scApi sourcecontrolapi.SourceControlAPI, orgApi org
 // Query Parameters:
 // - userIds: Optional list of user IDs to filter pull requests by
 // - repositoryName: Optional repository name to filter pull requests by
+// - teamId: Optional team ID to filter the pull requests attributed to a team by
+// - repositoryId: Optional repository ID to filter pull requests by
 // - startDate: Optional start date in format "2006-01-02" to filter pull requests by
 // - endDate: Optional end date in format "2006-01-02" to filter pull requests by
 // - status: Optional status to filter pull requests by (one of: "open", "closed", "merged")
@@ -101,12 +111,22 @@ func (h *SourceControlHandler) ListOrganizationPullRequests(c *gin.Context) {
 	if query.Prefix != "" {
 		prefix = &query.Prefix
 	}
+	var teamID *string
+	if query.TeamID != "" {
+		teamID = &query.TeamID
+	}
+	var repositoryID *string
+	if query.RepositoryID != "" {
+		repositoryID = &query.RepositoryID
+	}
 
 	prs, err := h.scApi.GetPullRequests(c.Request.Context(), &servicetypes.PullRequestParams{
 		OrganizationID: &orgID,
 		UserIDs:        query.UserIDs,
 		RepositoryName: query.RepositoryName,
 		Prefix:         prefix,
+		TeamID:         teamID,
+		RepositoryID:   repositoryID,
 		StartDate:      startDate,
 		EndDate:        endDate,
 		Status:         query.Status,
@@ -168,6 +188,7 @@ func (h *SourceControlHandler) ListOrganizationPullRequests(c *gin.Context) {
 			URL:            pr.URL,
 			Status:         pr.Status,
 			RepositoryName: pr.RepositoryName,
+			RepositoryID:   pr.RepositoryID,
 			CreatedAt:      pr.CreatedAt,
 			MergedAt:       pr.MergedAt,
 			Comments:       pr.Comments,
@@ -478,12 +499,362 @@ func (h *SourceControlHandler) GetOrganizationSourceControlMetrics(c *gin.Contex
 	c.JSON(http.StatusOK, metrics)
 }
 
+// GetOrganizationFileHotspots handles retrieving the most frequently changed files of an organization's repositories
+// Params:
+// - c: The Gin context containing request and response
+// Path Parameters:
+// - id: Organization ID
+// Query Parameters:
+// - repositoryId: Optional repository ID to get hotspots for, all repositories by default
+// - startDate: Optional start date in format "2006-01-02" to filter pull requests by
+// - endDate: Optional end date in format "2006-01-02" to filter pull requests by
+// - limit: Optional number of hotspots per repository, 10 by default and at most 100
+// Returns:
+// - 200: Success response with the hotspots of each repository, and who changes them
+// - 400: Bad request if organization ID is missing or query parameters are invalid
+// - 401: Unauthorized if user is not authenticated
+// - 403: Forbidden if user does not have access to the organization
+// - 500: Internal server error if service layer fails
+func (h *SourceControlHandler) GetOrganizationFileHotspots(c *gin.Context) {
+	orgID, err := utils.GetOrganizationIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Check if user has access to the organization
+	if !utils.CheckOrganizationMembership(c, h.orgApi, &orgID) {
+		return
+	}
+
+	var query sourcecontrol.GetFileHotspotsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	params := &servicetypes.FileHotspotParams{
+		OrganizationID: orgID,
+		Limit:          query.Limit,
+	}
+	if query.RepositoryID != "" {
+		params.RepositoryID = &query.RepositoryID
+	}
+	if query.StartDate != "" {
+		parsed, err := time.Parse("2006-01-02", query.StartDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid startDate format, expected YYYY-MM-DD"})
+			return
+		}
+		params.StartDate = &parsed
+	}
+	if query.EndDate != "" {
+		parsed, err := time.Parse("2006-01-02", query.EndDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid endDate format, expected YYYY-MM-DD"})
+			return
+		}
+		params.EndDate = &parsed
+	}
+
+	repositories, err := h.scApi.GetFileHotspots(c.Request.Context(), params)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sourcecontrol.GetFileHotspotsResponse{
+		Repositories: repositories,
+	})
+}
+
+// RecomputePullRequestMetrics handles queueing the rebuild of the stored metrics of an organization's pull requests
+// from their stored comments, reviews, timeline events and commits, e.g. after the bot rules changed. The metrics
+// are rebuilt in the background by the metrics recompute job.
+// Params:
+// - c: The Gin context containing request and response
+// Path Parameters:
+// - id: Organization ID
+// Query Parameters:
+// - startDate: Optional start date in format "2006-01-02" to recompute the pull requests created from
+// - endDate: Optional end date in format "2006-01-02" to recompute the pull requests created until
+// Returns:
+// - 202: Accepted response with the number of pull requests queued for recompute
+// - 400: Bad request if organization ID is missing or query parameters are invalid
+// - 401: Unauthorized if user is not authenticated
+// - 403: Forbidden if user is not an owner of the organization
+// - 500: Internal server error if service layer fails
+func (h *SourceControlHandler) RecomputePullRequestMetrics(c *gin.Context) {
+	orgID, err := utils.GetOrganizationIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Check if user is an owner of the organization
+	if !utils.CheckOrganizationOwnership(c, h.orgApi, orgID) {
+		return
+	}
+
+	var query sourcecontrol.RecomputePullRequestMetricsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	params := &servicetypes.RecomputeMetricsParams{
+		OrganizationID: &orgID,
+	}
+	if query.StartDate != "" {
+		parsed, err := time.Parse("2006-01-02", query.StartDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid startDate format, expected YYYY-MM-DD"})
+			return
+		}
+		params.StartDate = &parsed
+	}
+	if query.EndDate != "" {
+		parsed, err := time.Parse("2006-01-02", query.EndDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid endDate format, expected YYYY-MM-DD"})
+			return
+		}
+		params.EndDate = &parsed
+	}
+
+	result, err := h.recomputeQueue.Queue(c.Request.Context(), params)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, result)
+}
+
+// ListOrganizationRepositories handles retrieving the repositories synced for an organization
+// Params:
+// - c: The Gin context containing request and response
+// Path Parameters:
+// - id: Organization ID
+// Query Parameters:
+// - provider: Optional provider to filter repositories by (one of: "github", "gitlab", "bitbucket")
+// - teamId: Optional team ID to filter the repositories owned by a team by
+// - includeArchived: Optional flag to include archived repositories, excluded by default
+// Returns:
+// - 200: Success response with the repositories in ListRepositoriesResponse format
+// - 400: Bad request if organization ID is missing or query parameters are invalid
+// - 401: Unauthorized if user is not authenticated
+// - 403: Forbidden if user does not have access to the organization
+// - 500: Internal server error if service layer fails
+func (h *SourceControlHandler) ListOrganizationRepositories(c *gin.Context) {
+	orgID, err := utils.GetOrganizationIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Check if user has access to the organization
+	if !utils.CheckOrganizationMembership(c, h.orgApi, &orgID) {
+		return
+	}
+
+	var query sourcecontrol.ListRepositoriesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	params := &servicetypes.RepositoryParams{
+		OrganizationID:  orgID,
+		Provider:        query.Provider,
+		IncludeArchived: query.IncludeArchived,
+	}
+	if query.TeamID != "" {
+		params.TeamID = &query.TeamID
+	}
+
+	repositories, err := h.scApi.GetRepositories(c.Request.Context(), params)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sourcecontrol.ListRepositoriesResponse{
+		Repositories: repositories,
+	})
+}
+
+// GetOrganizationRepository handles retrieving a repository of an organization
+// Params:
+// - c: The Gin context containing request and response
+// Path Parameters:
+// - id: Organization ID
+// - repositoryId: Repository ID
+// Returns:
+// - 200: Success response with the repository
+// - 400: Bad request if organization ID or repository ID is missing
+// - 401: Unauthorized if user is not authenticated
+// - 403: Forbidden if user does not have access to the organization
+// - 404: Not found if the repository does not belong to the organization
+// - 500: Internal server error if service layer fails
+func (h *SourceControlHandler) GetOrganizationRepository(c *gin.Context) {
+	orgID, err := utils.GetOrganizationIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	repositoryID := c.Param("repositoryId")
+	if repositoryID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "repository ID is required"})
+		return
+	}
+
+	// Check if user has access to the organization
+	if !utils.CheckOrganizationMembership(c, h.orgApi, &orgID) {
+		return
+	}
+
+	repository, err := h.scApi.GetRepository(c.Request.Context(), orgID, repositoryID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"repository": repository})
+}
+
+// UpdateOrganizationRepository handles setting the team owning a repository of an organization
+// Params:
+// - c: The Gin context containing request and response
+// Path Parameters:
+// - id: Organization ID
+// - repositoryId: Repository ID
+// Request Body:
+// - team_id: ID of the team owning the repository, null to remove the owner
+// Returns:
+// - 200: Success response with the updated repository
+// - 400: Bad request if the request is invalid or the team is not one of the organization
+// - 401: Unauthorized if user is not authenticated
+// - 403: Forbidden if user is not an owner of the organization
+// - 404: Not found if the repository does not belong to the organization
+// - 500: Internal server error if service layer fails
+func (h *SourceControlHandler) UpdateOrganizationRepository(c *gin.Context) {
+	orgID, err := utils.GetOrganizationIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	repositoryID := c.Param("repositoryId")
+	if repositoryID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "repository ID is required"})
+		return
+	}
+
+	// Check if user is an owner of the organization
+	if !utils.CheckOrganizationOwnership(c, h.orgApi, orgID) {
+		return
+	}
+
+	var req sourcecontrol.UpdateRepositoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	repository, err := h.scApi.UpdateRepositoryTeam(c.Request.Context(), orgID, repositoryID, req.TeamID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"repository": repository})
+}
+
+// GetRepositorySourceControlMetrics handles retrieving source control metrics for the pull requests of a repository
+// Params:
+// - c: The Gin context containing request and response
+// Path Parameters:
+// - id: Organization ID
+// - repositoryId: Repository ID
+// Query Parameters:
+// - startDate: Optional start date in format "2006-01-02" to filter metrics by
+// - endDate: Optional end date in format "2006-01-02" to filter metrics by
+// - interval: Optional time interval for graph metrics (daily, weekly, monthly)
+// Returns:
+// - 200: Success response with repository metrics
+// - 400: Bad request if organization ID is missing or query parameters are invalid
+// - 401: Unauthorized if user is not authenticated
+// - 403: Forbidden if user does not have access to the organization
+// - 404: Not found if the repository does not belong to the organization
+// - 500: Internal server error if service layer fails
+func (h *SourceControlHandler) GetRepositorySourceControlMetrics(c *gin.Context) {
+	orgID, err := utils.GetOrganizationIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	repositoryID := c.Param("repositoryId")
+	if repositoryID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "repository ID is required"})
+		return
+	}
+
+	// Check if user has access to the organization
+	if !utils.CheckOrganizationMembership(c, h.orgApi, &orgID) {
+		return
+	}
+
+	var query sourcecontrol.GetRepositoryMetricsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	params := metricsTypes.OrganizationMetricsParams{
+		OrganizationID: orgID,
+		Interval:       query.Interval,
+	}
+	if query.StartDate != "" {
+		parsed, err := time.Parse("2006-01-02", query.StartDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid startDate format, expected YYYY-MM-DD"})
+			return
+		}
+		params.StartDate = &parsed
+	}
+	if query.EndDate != "" {
+		parsed, err := time.Parse("2006-01-02", query.EndDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid endDate format, expected YYYY-MM-DD"})
+			return
+		}
+		params.EndDate = &parsed
+	}
+
+	metrics, err := h.metricsApi.CalculateRepositorySourceControlMetrics(c.Request.Context(), repositoryID, params)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, metrics)
+}
+
 // RegisterRoutes registers all source control-related routes
 func (h *SourceControlHandler) RegisterRoutes(api *gin.RouterGroup) {
 	sourceControl := api.Group("/organizations/:id")
 	{
 		sourceControl.GET("/pull-requests", h.ListOrganizationPullRequests)
+		sourceControl.POST("/pull-requests/recompute-metrics", h.RecomputePullRequestMetrics)
 		sourceControl.GET("/sourcecontrol/metrics", h.GetOrganizationSourceControlMetrics)
+		sourceControl.GET("/sourcecontrol/hotspots", h.GetOrganizationFileHotspots)
+		sourceControl.GET("/repositories", h.ListOrganizationRepositories)
+		sourceControl.GET("/repositories/:repositoryId", h.GetOrganizationRepository)
+		sourceControl.PUT("/repositories/:repositoryId", h.UpdateOrganizationRepository)
+		sourceControl.GET("/repositories/:repositoryId/metrics", h.GetRepositorySourceControlMetrics)
 	}
 
 	// Member-specific routes
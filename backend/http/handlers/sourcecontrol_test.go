@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	organizationapi "ems.dev/backend/services/organization/api"
+	orgtypes "ems.dev/backend/services/organization/types"
+	sourcecontrolapi "ems.dev/backend/services/sourcecontrol/api"
+	servicetypes "ems.dev/backend/services/sourcecontrol/types"
+	usertypes "ems.dev/backend/services/user/types"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockOrganizationAPI struct {
+	organizationapi.OrganizationAPI
+	mock.Mock
+}
+
+func (m *MockOrganizationAPI) GetMemberOrganizations(ctx context.Context, userID string) ([]orgtypes.Organization, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]orgtypes.Organization), args.Error(1)
+}
+
+type MockSourceControlAPI struct {
+	sourcecontrolapi.SourceControlAPI
+	mock.Mock
+}
+
+func (m *MockSourceControlAPI) GetFileHotspots(ctx context.Context, params *servicetypes.FileHotspotParams) ([]*servicetypes.RepositoryHotspots, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*servicetypes.RepositoryHotspots), args.Error(1)
+}
+
+func TestGetOrganizationFileHotspots(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repositories := []*servicetypes.RepositoryHotspots{
+		{
+			RepositoryID:   "repo-1",
+			RepositoryName: "acme/api",
+			Hotspots:       []*servicetypes.FileHotspot{{RepositoryID: "repo-1", RepositoryName: "acme/api", Path: "main.go", Changes: 3}},
+		},
+	}
+
+	tests := []struct {
+		name                 string
+		url                  string
+		expectedRepositoryID *string // Repository the hotspots are requested for, all of them when nil
+		expectedStatus       int
+		expectedRepositories []string
+	}{
+		{
+			name:                 "hotspots of every repository",
+			url:                  "/organizations/org-1/hotspots",
+			expectedStatus:       http.StatusOK,
+			expectedRepositories: []string{"repo-1"},
+		},
+		{
+			name:                 "hotspots of a repository",
+			url:                  "/organizations/org-1/hotspots?repositoryId=repo-1",
+			expectedRepositoryID: ptr("repo-1"),
+			expectedStatus:       http.StatusOK,
+			expectedRepositories: []string{"repo-1"},
+		},
+		{
+			name:           "limit above the maximum",
+			url:            "/organizations/org-1/hotspots?limit=101",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "organization of another user",
+			url:            "/organizations/org-2/hotspots",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgAPI := new(MockOrganizationAPI)
+			orgAPI.On("GetMemberOrganizations", mock.Anything, "user-1").Return([]orgtypes.Organization{{ID: "org-1"}}, nil)
+			scAPI := new(MockSourceControlAPI)
+			if tt.expectedStatus == http.StatusOK {
+				scAPI.On("GetFileHotspots", mock.Anything, mock.MatchedBy(func(params *servicetypes.FileHotspotParams) bool {
+					return params.OrganizationID == "org-1" && assert.ObjectsAreEqual(tt.expectedRepositoryID, params.RepositoryID)
+				})).Return(repositories, nil).Once()
+			}
+
+			handler := NewSourceControlHandler(scAPI, orgAPI, nil, nil, nil)
+			router := gin.New()
+			router.GET("/organizations/:id/hotspots", func(c *gin.Context) {
+				c.Set("user", &usertypes.User{ID: "user-1"})
+			}, handler.GetOrganizationFileHotspots)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			scAPI.AssertExpectations(t)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Repositories []struct {
+					RepositoryID   string `json:"repository_id"`
+					RepositoryName string `json:"repository_name"`
+				} `json:"repositories"`
+			}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			ids := []string{}
+			for _, repository := range response.Repositories {
+				ids = append(ids, repository.RepositoryID)
+				assert.Equal(t, "acme/api", repository.RepositoryName)
+			}
+			assert.Equal(t, tt.expectedRepositories, ids)
+		})
+	}
+}
+
+func ptr(value string) *string {
+	return &value
+}
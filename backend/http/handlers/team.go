@@ -36,6 +36,11 @@ func NewTeamHandler(teamApi teamapi.TeamAPI, orgApi orgapi.OrganizationAPI) *Tea
 // - DELETE /api/organizations/:id/teams/:teamId - Delete a team
 // - POST /api/organizations/:id/teams/:teamId/members - Add a team member
 // - DELETE /api/organizations/:id/teams/:teamId/members/:memberId - Remove a team member
+// - GET /api/organizations/:id/team-attribution-rules - List the team attribution rules of an organization
+// - POST /api/organizations/:id/team-attribution-rules - Create a team attribution rule
+// - PUT /api/organizations/:id/team-attribution-rules/:ruleId - Update a team attribution rule
+// - DELETE /api/organizations/:id/team-attribution-rules/:ruleId - Delete a team attribution rule
+// - POST /api/organizations/:id/team-attribution-rules/reevaluate - Attribute the imported pull requests again
 func (h *TeamHandler) RegisterRoutes(router *gin.RouterGroup) {
 	organizations := router.Group("/organizations")
 	{
@@ -46,6 +51,11 @@ func (h *TeamHandler) RegisterRoutes(router *gin.RouterGroup) {
 		organizations.DELETE("/:id/teams/:teamId", h.DeleteTeam)
 		organizations.POST("/:id/teams/:teamId/members", h.AddTeamMember)
 		organizations.DELETE("/:id/teams/:teamId/members/:memberId", h.RemoveTeamMember)
+		organizations.GET("/:id/team-attribution-rules", h.ListAttributionRules)
+		organizations.POST("/:id/team-attribution-rules", h.CreateAttributionRule)
+		organizations.POST("/:id/team-attribution-rules/reevaluate", h.ReevaluateAttribution)
+		organizations.PUT("/:id/team-attribution-rules/:ruleId", h.UpdateAttributionRule)
+		organizations.DELETE("/:id/team-attribution-rules/:ruleId", h.DeleteAttributionRule)
 	}
 }
 
@@ -325,3 +335,159 @@ func (h *TeamHandler) RemoveTeamMember(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// ListAttributionRules handles the GET /api/organizations/:id/team-attribution-rules endpoint.
+// It lists the team attribution rules of an organization in evaluation order.
+// Returns:
+// - 200: The attribution rules
+// - 500: If there's a database error
+func (h *TeamHandler) ListAttributionRules(c *gin.Context) {
+	orgID, err := utils.GetOrganizationIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Check if user has access to the organization
+	if !utils.CheckOrganizationMembership(c, h.orgApi, &orgID) {
+		return
+	}
+
+	rules, err := h.teamApi.ListAttributionRules(c.Request.Context(), orgID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attribution_rules": rules})
+}
+
+// CreateAttributionRule handles the POST /api/organizations/:id/team-attribution-rules endpoint.
+// It creates a rule attributing the matching pull requests to a team.
+// Returns:
+// - 201: The created attribution rule
+// - 400: If the request body or the pattern is invalid
+// - 500: If there's a database error
+func (h *TeamHandler) CreateAttributionRule(c *gin.Context) {
+	orgID, err := utils.GetOrganizationIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Check if user is an owner of the organization
+	if !utils.CheckOrganizationOwnership(c, h.orgApi, orgID) {
+		return
+	}
+
+	var req types.CreateAttributionRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.teamApi.CreateAttributionRule(c.Request.Context(), orgID, req)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"attribution_rule": rule})
+}
+
+// UpdateAttributionRule handles the PUT /api/organizations/:id/team-attribution-rules/:ruleId endpoint.
+// It updates the team, type, pattern or priority of a rule.
+// Returns:
+// - 200: The updated attribution rule
+// - 400: If the request body or the pattern is invalid
+// - 404: If the rule is not found
+// - 500: If there's a database error
+func (h *TeamHandler) UpdateAttributionRule(c *gin.Context) {
+	orgID, err := utils.GetOrganizationIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ruleID := c.Param("ruleId")
+	if ruleID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rule ID is required"})
+		return
+	}
+
+	// Check if user is an owner of the organization
+	if !utils.CheckOrganizationOwnership(c, h.orgApi, orgID) {
+		return
+	}
+
+	var req types.UpdateAttributionRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.teamApi.UpdateAttributionRule(c.Request.Context(), orgID, ruleID, req)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attribution_rule": rule})
+}
+
+// DeleteAttributionRule handles the DELETE /api/organizations/:id/team-attribution-rules/:ruleId endpoint.
+// Returns:
+// - 204: If the rule was deleted
+// - 404: If the rule is not found
+// - 500: If there's a database error
+func (h *TeamHandler) DeleteAttributionRule(c *gin.Context) {
+	orgID, err := utils.GetOrganizationIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ruleID := c.Param("ruleId")
+	if ruleID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rule ID is required"})
+		return
+	}
+
+	// Check if user is an owner of the organization
+	if !utils.CheckOrganizationOwnership(c, h.orgApi, orgID) {
+		return
+	}
+
+	if err := h.teamApi.DeleteAttributionRule(c.Request.Context(), orgID, ruleID); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ReevaluateAttribution handles the POST /api/organizations/:id/team-attribution-rules/reevaluate endpoint.
+// It attributes the already imported pull requests of an organization to teams with the current rules.
+// Returns:
+// - 200: The number of evaluated and changed pull requests
+// - 500: If there's a database error
+func (h *TeamHandler) ReevaluateAttribution(c *gin.Context) {
+	orgID, err := utils.GetOrganizationIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Check if user is an owner of the organization
+	if !utils.CheckOrganizationOwnership(c, h.orgApi, orgID) {
+		return
+	}
+
+	result, err := h.teamApi.ReevaluateAttribution(c.Request.Context(), orgID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+
+	"ems.dev/backend/libraries/github"
+	intapi "ems.dev/backend/services/integration/api"
+	inttypes "ems.dev/backend/services/integration/types"
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookPayloadSize is the maximum payload size GitHub delivers (25MB)
+const maxWebhookPayloadSize = 25 << 20
+
+// GithubWebhookProcessor ingests verified GitHub webhook deliveries
+type GithubWebhookProcessor interface {
+	HandleWebhookEvent(ctx context.Context, config *inttypes.IntegrationConfig, eventType string, payload []byte) error
+}
+
+type WebhookHandler struct {
+	integrationAPI  intapi.IntegrationAPI
+	githubProcessor GithubWebhookProcessor
+}
+
+func NewWebhookHandler(integrationAPI intapi.IntegrationAPI, githubProcessor GithubWebhookProcessor) *WebhookHandler {
+	return &WebhookHandler{
+		integrationAPI:  integrationAPI,
+		githubProcessor: githubProcessor,
+	}
+}
+
+// HandleGithubWebhook handles a GitHub webhook delivery. The route is public, deliveries are
+// authenticated with the X-Hub-Signature-256 HMAC computed with the integration's webhook secret.
+func (h *WebhookHandler) HandleGithubWebhook(c *gin.Context) {
+	integrationID := c.Param("integrationId")
+
+	payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookPayloadSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read payload"})
+		return
+	}
+
+	// Unknown integrations are rejected like invalid signatures, so that callers cannot probe which integrations exist
+	config, err := h.integrationAPI.GetIntegrationConfig(c.Request.Context(), integrationID)
+	if err != nil || config.ProviderName != inttypes.IntegrationProviderGithub || config.EncryptedWebhookSecret == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid signature"})
+		return
+	}
+
+	secret, err := h.integrationAPI.DecryptToken(*config.EncryptedWebhookSecret)
+	if err != nil {
+		log.Printf("Failed to decrypt webhook secret of integration %s: %v", integrationID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process webhook"})
+		return
+	}
+
+	if !github.VerifyWebhookSignature(secret, payload, c.GetHeader(github.WebhookSignatureHeader)) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid signature"})
+		return
+	}
+
+	eventType := c.GetHeader(github.WebhookEventHeader)
+	if eventType == github.WebhookEventPing {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		return
+	}
+
+	// The route is public, processing errors are logged and not returned to the caller
+	if err := h.githubProcessor.HandleWebhookEvent(c.Request.Context(), config, eventType, payload); err != nil {
+		log.Printf("Failed to process %s webhook of integration %s: %v", eventType, integrationID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// RegisterRoutes registers the webhook routes
+func (h *WebhookHandler) RegisterRoutes(router *gin.RouterGroup) {
+	webhooks := router.Group("/webhooks")
+	{
+		webhooks.POST("/github/:integrationId", h.HandleGithubWebhook)
+	}
+}
@@ -13,6 +13,10 @@ func (s *Server) setupRoutes() {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	// Webhook routes, authenticated by their signature
+	webhookHandler := handlers.NewWebhookHandler(s.integrationApi, s.githubWebhookProcessor)
+	webhookHandler.RegisterRoutes(&s.router.RouterGroup)
+
 	// Protected routes
 	protected := s.router.Group("/api")
 	protected.Use(middleware.AuthMiddleware(s.userApi, s.authApi))
@@ -26,11 +30,11 @@ func (s *Server) setupRoutes() {
 		orgHandler.RegisterRoutes(protected)
 
 		// Integration routes
-		integrationHandler := handlers.NewIntegrationHandler(s.integrationApi, s.orgApi)
+		integrationHandler := handlers.NewIntegrationHandler(s.integrationApi, s.orgApi, s.integrationSyncers)
 		integrationHandler.RegisterRoutes(protected)
 
 		// Source control routes
-		sourceControlHandler := handlers.NewSourceControlHandler(s.sourcecontrolApi, s.orgApi, s.metricsApi, s.memberApi)
+		sourceControlHandler := handlers.NewSourceControlHandler(s.sourcecontrolApi, s.orgApi, s.metricsApi, s.memberApi, s.metricsRecomputeQueue)
 		sourceControlHandler.RegisterRoutes(protected)
 
 		// Team routes
@@ -45,6 +49,10 @@ func (s *Server) setupRoutes() {
 		memberHandler := handlers.NewMemberHandler(s.memberApi, s.orgApi)
 		memberHandler.RegisterRoutes(protected)
 
+		// Identity resolution routes
+		identityHandler := handlers.NewIdentityHandler(s.identityApi, s.orgApi)
+		identityHandler.RegisterRoutes(protected)
+
 		// Direct reports routes
 		directsHandler := handlers.NewDirectsHandler(s.directsApi, s.orgApi, s.titleApi)
 		directsHandler.RegisterRoutes(protected)
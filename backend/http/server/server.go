@@ -1,15 +1,21 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"net/http"
 	"os"
 
+	"ems.dev/backend/http/handlers"
 	aicodeassistantapi "ems.dev/backend/services/aicodeassistant/api"
 	apiai "ems.dev/backend/services/ai/api"
 	authapi "ems.dev/backend/services/auth/api"
 	conversationapi "ems.dev/backend/services/conversation/api"
 	conversationtemplateapi "ems.dev/backend/services/conversationtemplate/api"
 	directsapi "ems.dev/backend/services/directs/api"
+	identityapi "ems.dev/backend/services/identity/api"
 	integrationapi "ems.dev/backend/services/integration/api"
+	integrationtypes "ems.dev/backend/services/integration/types"
 	memberapi "ems.dev/backend/services/member/api"
 	metricsapi "ems.dev/backend/services/metrics/api"
 	orgapi "ems.dev/backend/services/organization/api"
@@ -23,6 +29,7 @@ import (
 
 type Server struct {
 	router                  *gin.Engine
+	httpServer              *http.Server
 	db                      *gorm.DB
 	userApi                 userapi.UserAPI
 	orgApi                  orgapi.OrganizationAPI
@@ -38,9 +45,13 @@ type Server struct {
 	conversationApi         conversationapi.ConversationAPIInterface
 	aiApi                   apiai.AIServiceInterface
 	aiCodeAssistantApi      aicodeassistantapi.AICodeAssistantAPI
+	identityApi             identityapi.IdentityAPI
+	githubWebhookProcessor  handlers.GithubWebhookProcessor
+	integrationSyncers      map[integrationtypes.IntegrationProviderType]handlers.IntegrationSyncer
+	metricsRecomputeQueue   handlers.MetricsRecomputeQueue
 }
 
-func New(db *gorm.DB, userApi userapi.UserAPI, orgApi orgapi.OrganizationAPI, teamApi teamapi.TeamAPI, titleApi titleapi.TitleAPI, authApi authapi.AuthAPI, integrationApi integrationapi.IntegrationAPI, sourcecontrolApi sourcecontrolapi.SourceControlAPI, memberApi memberapi.MemberAPI, metricsApi metricsapi.MetricsAPI, directsApi directsapi.DirectReportsAPI, conversationTemplateApi conversationtemplateapi.ConversationTemplateAPIInterface, conversationApi conversationapi.ConversationAPIInterface, aiApi apiai.AIServiceInterface, aiCodeAssistantApi aicodeassistantapi.AICodeAssistantAPI) *Server {
+func New(db *gorm.DB, userApi userapi.UserAPI, orgApi orgapi.OrganizationAPI, teamApi teamapi.TeamAPI, titleApi titleapi.TitleAPI, authApi authapi.AuthAPI, integrationApi integrationapi.IntegrationAPI, sourcecontrolApi sourcecontrolapi.SourceControlAPI, memberApi memberapi.MemberAPI, metricsApi metricsapi.MetricsAPI, directsApi directsapi.DirectReportsAPI, conversationTemplateApi conversationtemplateapi.ConversationTemplateAPIInterface, conversationApi conversationapi.ConversationAPIInterface, aiApi apiai.AIServiceInterface, aiCodeAssistantApi aicodeassistantapi.AICodeAssistantAPI, identityApi identityapi.IdentityAPI, githubWebhookProcessor handlers.GithubWebhookProcessor, integrationSyncers map[integrationtypes.IntegrationProviderType]handlers.IntegrationSyncer, metricsRecomputeQueue handlers.MetricsRecomputeQueue) *Server {
 	s := &Server{
 		router:                  gin.Default(),
 		db:                      db,
@@ -58,8 +69,14 @@ func New(db *gorm.DB, userApi userapi.UserAPI, orgApi orgapi.OrganizationAPI, te
 		conversationApi:         conversationApi,
 		aiApi:                   aiApi,
 		aiCodeAssistantApi:      aiCodeAssistantApi,
+		identityApi:             identityApi,
+		githubWebhookProcessor:  githubWebhookProcessor,
+		integrationSyncers:      integrationSyncers,
+		metricsRecomputeQueue:   metricsRecomputeQueue,
 	}
 
+	s.httpServer = &http.Server{Handler: s.router}
+
 	s.setupMiddleware()
 	s.setupRoutes()
 
@@ -87,5 +104,14 @@ func (s *Server) setupMiddleware() {
 }
 
 func (s *Server) Run(addr string) error {
-	return s.router.Run(addr)
+	s.httpServer.Addr = addr
+	if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting requests and waits for the in-flight ones
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
 }
@@ -13,6 +13,7 @@ type GetOrganizationIntegrationConfigsRequest struct {
 	ProviderName   integrationtypes.IntegrationProvider     `json:"provider_name"`
 	ProviderType   integrationtypes.IntegrationProviderType `json:"provider_type"`
 	Metadata       datatypes.JSON                           `json:"metadata"`
+	SyncSchedule   *string                                  `json:"sync_schedule"`
 	LastSyncedAt   *time.Time                               `json:"last_synced_at"`
 	CreatedAt      time.Time                                `json:"created_at" gorm:"default:now()"`
 	UpdatedAt      time.Time                                `json:"updated_at"`
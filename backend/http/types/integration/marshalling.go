@@ -11,6 +11,7 @@ func MarshalIntegrationConfig(integration *integrationtypes.IntegrationConfig) *
 		ProviderName:   integration.ProviderName,
 		ProviderType:   integration.ProviderType,
 		Metadata:       integration.Metadata,
+		SyncSchedule:   integration.SyncSchedule,
 		LastSyncedAt:   integration.LastSyncedAt,
 		CreatedAt:      integration.CreatedAt,
 		UpdatedAt:      integration.UpdatedAt,
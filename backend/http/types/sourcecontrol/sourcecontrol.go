@@ -2,12 +2,16 @@ package sourcecontrol
 
 import (
 	"time"
+
+	servicetypes "ems.dev/backend/services/sourcecontrol/types"
 )
 
 type ListOrganizationPullRequestsQuery struct {
 	UserIDs        []string `form:"userIds" binding:"omitempty"`
 	RepositoryName string   `form:"repositoryName" binding:"omitempty"`
 	Prefix         string   `form:"prefix" binding:"omitempty"`
+	TeamID         string   `form:"teamId" binding:"omitempty"`
+	RepositoryID   string   `form:"repositoryId" binding:"omitempty"`
 	StartDate      string   `form:"startDate" binding:"omitempty,datetime=2006-01-02"`
 	EndDate        string   `form:"endDate" binding:"omitempty,datetime=2006-01-02"`
 	Status         string   `form:"status" binding:"omitempty,oneof=open closed merged"`
@@ -30,6 +34,7 @@ type PullRequest struct {
 	URL            string             `json:"url"`
 	Status         string             `json:"status"`
 	RepositoryName string             `json:"repository_name"`
+	RepositoryID   *string            `json:"repository_id,omitempty"`
 	CreatedAt      time.Time          `json:"created_at"`
 	MergedAt       *time.Time         `json:"merged_at,omitempty"`
 	Comments       int                `json:"comments"`
@@ -71,3 +76,40 @@ type GetOrganizationMetricsQuery struct {
 	Interval  string   `form:"interval" binding:"omitempty,oneof=daily weekly monthly"`
 	TeamIDs   []string `form:"teamIds" binding:"omitempty"`
 }
+
+type GetFileHotspotsQuery struct {
+	RepositoryID string `form:"repositoryId" binding:"omitempty"`
+	StartDate    string `form:"startDate" binding:"omitempty,datetime=2006-01-02"`
+	EndDate      string `form:"endDate" binding:"omitempty,datetime=2006-01-02"`
+	Limit        int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type RecomputePullRequestMetricsQuery struct {
+	StartDate string `form:"startDate" binding:"omitempty,datetime=2006-01-02"`
+	EndDate   string `form:"endDate" binding:"omitempty,datetime=2006-01-02"`
+}
+
+type GetFileHotspotsResponse struct {
+	Repositories []*servicetypes.RepositoryHotspots `json:"repositories"`
+}
+
+type ListRepositoriesQuery struct {
+	Provider        string `form:"provider" binding:"omitempty,oneof=github gitlab bitbucket"`
+	TeamID          string `form:"teamId" binding:"omitempty"`
+	IncludeArchived bool   `form:"includeArchived" binding:"omitempty"`
+}
+
+type ListRepositoriesResponse struct {
+	Repositories []*servicetypes.Repository `json:"repositories"`
+}
+
+// UpdateRepositoryRequest sets the team owning a repository, a null team removes the owner
+type UpdateRepositoryRequest struct {
+	TeamID *string `json:"team_id"`
+}
+
+type GetRepositoryMetricsQuery struct {
+	StartDate string `form:"startDate" binding:"omitempty,datetime=2006-01-02"`
+	EndDate   string `form:"endDate" binding:"omitempty,datetime=2006-01-02"`
+	Interval  string `form:"interval" binding:"omitempty,oneof=daily weekly monthly"`
+}
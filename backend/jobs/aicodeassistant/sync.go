@@ -2,9 +2,17 @@ package aicodeassistant
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"ems.dev/backend/jobs/aicodeassistant/providers"
+	"ems.dev/backend/jobs/scheduler"
+	"ems.dev/backend/jobs/synclock"
+	"ems.dev/backend/jobs/workerpool"
+	liberrors "ems.dev/backend/libraries/errors"
 	intapi "ems.dev/backend/services/integration/api"
 	"ems.dev/backend/services/integration/types"
 	orgapi "ems.dev/backend/services/organization/api"
@@ -14,16 +22,32 @@ type SyncJob struct {
 	integrationAPI  intapi.IntegrationAPI
 	orgAPI          orgapi.OrganizationAPI
 	providerFactory *providers.Factory
+	poolConfig      workerpool.Config
+	locker          *synclock.Locker
+	schedules       *scheduler.IntegrationSchedules
+	// background runs the manually triggered syncs
+	background *workerpool.Background
+
+	// running holds the IDs of the integrations being synced, by a scheduled run or a manual trigger
+	running   map[string]bool
+	runningMu sync.Mutex
 }
 
-func NewSyncJob(integrationAPI intapi.IntegrationAPI, orgAPI orgapi.OrganizationAPI, providerFactory *providers.Factory) *SyncJob {
+func NewSyncJob(integrationAPI intapi.IntegrationAPI, orgAPI orgapi.OrganizationAPI, providerFactory *providers.Factory, poolConfig workerpool.Config, locker *synclock.Locker, schedules *scheduler.IntegrationSchedules) *SyncJob {
 	return &SyncJob{
 		integrationAPI:  integrationAPI,
 		orgAPI:          orgAPI,
 		providerFactory: providerFactory,
+		poolConfig:      poolConfig,
+		locker:          locker,
+		schedules:       schedules,
+		background:      workerpool.NewBackground(),
+		running:         make(map[string]bool),
 	}
 }
 
+// Run syncs the AI code assistant integrations of every organization which are due according to their
+// schedule. Integrations are synced concurrently on a bounded worker pool, each with a timeout.
 func (j *SyncJob) Run(ctx context.Context) error {
 	// Get all organizations
 	orgs, err := j.orgAPI.GetOrganizations(ctx)
@@ -31,6 +55,8 @@ func (j *SyncJob) Run(ctx context.Context) error {
 		return fmt.Errorf("failed to get organizations: %w", err)
 	}
 
+	var synced, failed atomic.Int32
+	pool := workerpool.New(j.poolConfig.Integrations)
 	for _, org := range orgs {
 		// Get all integrations for the organization
 		integrations, err := j.integrationAPI.GetOrganizationIntegrationConfigs(ctx, org.ID)
@@ -45,21 +71,138 @@ func (j *SyncJob) Run(ctx context.Context) error {
 				continue
 			}
 
-			// Get provider implementation
-			provider, err := j.providerFactory.GetProvider(string(integration.ProviderName))
-			if err != nil {
-				fmt.Printf("Failed to create provider for %s: %v\n", integration.ProviderName, err)
+			if !j.schedules.Due(ctx, &integration, time.Now()) {
 				continue
 			}
 
-			// Sync usage data
-			// TODO: Move this to run in a go routine
-			if err := provider.SyncUsageData(ctx, &integration); err != nil {
-				fmt.Printf("Failed to sync usage data for integration %s: %v\n", integration.ID, err)
-				continue
-			}
+			integration := integration
+			pool.Go(ctx, integration.ID, func(ctx context.Context) {
+				if !j.startSync(integration.ID) {
+					fmt.Printf("Skipping integration %s, it is already being synced\n", integration.ID)
+					return
+				}
+				defer j.finishSync(integration.ID)
+
+				// Another replica may be syncing the integration
+				ctx, unlock, err := j.locker.Lock(ctx, integration.ID)
+				if errors.Is(err, synclock.ErrLocked) {
+					fmt.Printf("Skipping integration %s, it is being synced by another instance\n", integration.ID)
+					return
+				}
+				if err != nil {
+					fmt.Printf("Failed to lock integration %s: %v\n", integration.ID, err)
+					return
+				}
+				defer unlock()
+
+				synced.Add(1)
+				if !j.syncIntegration(ctx, &integration) {
+					failed.Add(1)
+				}
+			})
 		}
 	}
+	pool.Wait()
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if failed.Load() > 0 {
+		return fmt.Errorf("%d of %d integrations failed to sync", failed.Load(), synced.Load())
+	}
 	return nil
 }
+
+// TriggerSync starts a sync of the integration in the background. Fails when the integration is already
+// being synced, by this or another instance.
+func (j *SyncJob) TriggerSync(integration *types.IntegrationConfig) error {
+	if !j.startSync(integration.ID) {
+		return liberrors.NewConflictError("integration is already being synced")
+	}
+
+	lockCtx, unlock, err := j.locker.Lock(context.Background(), integration.ID)
+	if err != nil {
+		j.finishSync(integration.ID)
+		if errors.Is(err, synclock.ErrLocked) {
+			return liberrors.NewConflictError(err.Error())
+		}
+		return err
+	}
+
+	j.background.Go(lockCtx, integration.ID, func(ctx context.Context) {
+		defer j.finishSync(integration.ID)
+		defer unlock()
+		j.syncIntegration(ctx, integration)
+	})
+	return nil
+}
+
+// Shutdown waits for the manually triggered syncs, cancelling them when the context is done first
+func (j *SyncJob) Shutdown(ctx context.Context) error {
+	return j.background.Shutdown(ctx)
+}
+
+// syncIntegration syncs the usage data of an integration with a timeout and records it as a sync run.
+// Returns whether the sync succeeded.
+func (j *SyncJob) syncIntegration(ctx context.Context, integration *types.IntegrationConfig) bool {
+	run, err := j.integrationAPI.StartSyncRun(ctx, integration.ID, nil)
+	if err != nil {
+		fmt.Printf("Failed to record sync run for integration %s: %v\n", integration.ID, err)
+	}
+
+	err = j.syncUsageData(ctx, integration)
+	if err != nil {
+		fmt.Printf("Failed to sync usage data for integration %s: %v\n", integration.ID, err)
+	}
+
+	if run == nil {
+		return err == nil
+	}
+
+	status := types.SyncRunStatusSucceeded
+	if err != nil {
+		status = types.SyncRunStatusFailed
+	}
+
+	// Recorded even when the sync timed out, so it doesn't use the sync's context
+	if finishErr := j.integrationAPI.FinishSyncRun(context.Background(), run, status, types.SyncCounts{}, err); finishErr != nil {
+		fmt.Printf("Failed to record outcome of sync run %s: %v\n", run.ID, finishErr)
+	}
+	return err == nil
+}
+
+func (j *SyncJob) syncUsageData(ctx context.Context, integration *types.IntegrationConfig) error {
+	// Get provider implementation
+	provider, err := j.providerFactory.GetProvider(string(integration.ProviderName))
+	if err != nil {
+		return fmt.Errorf("failed to create provider for %s: %w", integration.ProviderName, err)
+	}
+
+	if j.poolConfig.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.poolConfig.Timeout)
+		defer cancel()
+	}
+
+	// Sync usage data
+	return provider.SyncUsageData(ctx, integration)
+}
+
+// startSync marks the integration as being synced. Returns false if it already is.
+func (j *SyncJob) startSync(integrationID string) bool {
+	j.runningMu.Lock()
+	defer j.runningMu.Unlock()
+
+	if j.running[integrationID] {
+		return false
+	}
+	j.running[integrationID] = true
+	return true
+}
+
+func (j *SyncJob) finishSync(integrationID string) {
+	j.runningMu.Lock()
+	defer j.runningMu.Unlock()
+
+	delete(j.running, integrationID)
+}
@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"ems.dev/backend/libraries/cron"
+	intapi "ems.dev/backend/services/integration/api"
+	"ems.dev/backend/services/integration/types"
+)
+
+// IntegrationSchedules decides when integrations are due to sync, from the cron expression stored on each
+// integration or the default schedule of their provider type. Every integration is delayed by its own
+// jitter, so integrations sharing a schedule don't all start at once. The last sync is read from the sync
+// runs, so replicas agree on it.
+type IntegrationSchedules struct {
+	integrationAPI  intapi.IntegrationAPI
+	defaultSchedule cron.Schedule
+	maxJitter       time.Duration
+
+	// attemptedAt holds the time of the last sync attempt by integration ID, so an integration failing
+	// before recording a sync run waits for its next activation instead of being retried at every tick
+	attemptedAt map[string]time.Time
+	mu          sync.Mutex
+}
+
+func NewIntegrationSchedules(integrationAPI intapi.IntegrationAPI, defaultSchedule cron.Schedule, maxJitter time.Duration) *IntegrationSchedules {
+	return &IntegrationSchedules{
+		integrationAPI:  integrationAPI,
+		defaultSchedule: defaultSchedule,
+		maxJitter:       maxJitter,
+		attemptedAt:     make(map[string]time.Time),
+	}
+}
+
+// Due reports whether the integration should be synced now, and if so records the attempt. Integrations
+// which never synced are due immediately.
+func (s *IntegrationSchedules) Due(ctx context.Context, integration *types.IntegrationConfig, now time.Time) bool {
+	var last time.Time
+	if integration.LastSyncedAt != nil {
+		last = *integration.LastSyncedAt
+	}
+
+	runs, err := s.integrationAPI.GetSyncRuns(ctx, integration.ID, &types.SyncRunParams{Limit: 1})
+	if err != nil {
+		fmt.Printf("Failed to get last sync run of integration %s: %v\n", integration.ID, err)
+	} else if len(runs) > 0 && runs[0].StartedAt.After(last) {
+		last = runs[0].StartedAt
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if attemptedAt, ok := s.attemptedAt[integration.ID]; ok && attemptedAt.After(last) {
+		last = attemptedAt
+	}
+
+	if !last.IsZero() {
+		// The last sync was delayed by the jitter too, removing it keeps interval schedules from drifting
+		jitter := s.jitter(integration.ID)
+		next := s.schedule(integration).Next(last.Add(-jitter))
+		if next.IsZero() || now.Before(next.Add(jitter)) {
+			return false
+		}
+	}
+
+	s.attemptedAt[integration.ID] = now
+	return true
+}
+
+// Retry forgets the last attempt of the integration, for syncs which couldn't start, e.g. because another
+// replica or a backfill holds the integration's lease. The integration is due again at the next tick.
+func (s *IntegrationSchedules) Retry(integrationID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attemptedAt, integrationID)
+}
+
+// schedule returns the integration's schedule, falling back to the default one when it has none or an
+// invalid one
+func (s *IntegrationSchedules) schedule(integration *types.IntegrationConfig) cron.Schedule {
+	if integration.SyncSchedule == nil || *integration.SyncSchedule == "" {
+		return s.defaultSchedule
+	}
+
+	schedule, err := cron.Parse(*integration.SyncSchedule)
+	if err != nil {
+		fmt.Printf("Invalid sync schedule for integration %s, using the default schedule: %v\n", integration.ID, err)
+		return s.defaultSchedule
+	}
+	return schedule
+}
+
+// jitter returns the delay of the integration's syncs. It is derived from the integration ID so that every
+// replica computes the same one.
+func (s *IntegrationSchedules) jitter(integrationID string) time.Duration {
+	if s.maxJitter <= 0 {
+		return 0
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(integrationID))
+	return time.Duration(h.Sum64() % uint64(s.maxJitter))
+}
@@ -0,0 +1,177 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ems.dev/backend/libraries/cron"
+	intapi "ems.dev/backend/services/integration/api"
+	"ems.dev/backend/services/integration/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockIntegrationAPI is a mock of the integration API, only the sync runs are read by the schedules
+type MockIntegrationAPI struct {
+	intapi.IntegrationAPI
+	mock.Mock
+}
+
+func (m *MockIntegrationAPI) GetSyncRuns(ctx context.Context, integrationConfigID string, params *types.SyncRunParams) ([]types.SyncRun, error) {
+	args := m.Called(ctx, integrationConfigID, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.SyncRun), args.Error(1)
+}
+
+func at(hour, minute int) time.Time {
+	return time.Date(2024, 1, 1, hour, minute, 0, 0, time.UTC)
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func hourly(t *testing.T) cron.Schedule {
+	schedule, err := cron.Parse("@hourly")
+	require.NoError(t, err)
+	return schedule
+}
+
+func TestIntegrationSchedulesDue(t *testing.T) {
+	tests := []struct {
+		name         string
+		syncSchedule *string
+		lastSyncedAt *time.Time
+		lastRunAt    *time.Time
+		runsErr      error
+		now          time.Time
+		expected     bool
+	}{
+		{
+			name:     "never synced is due immediately",
+			now:      at(12, 10),
+			expected: true,
+		},
+		{
+			name:         "due at the next activation after the last sync",
+			lastSyncedAt: timePtr(at(11, 30)),
+			now:          at(12, 0),
+			expected:     true,
+		},
+		{
+			name:         "not due before the next activation",
+			lastSyncedAt: timePtr(at(12, 5)),
+			now:          at(12, 10),
+			expected:     false,
+		},
+		{
+			name:         "a sync run more recent than the last successful sync counts",
+			lastSyncedAt: timePtr(at(11, 5)),
+			lastRunAt:    timePtr(at(12, 5)),
+			now:          at(12, 10),
+			expected:     false,
+		},
+		{
+			name:      "a sync run counts when the integration never synced successfully",
+			lastRunAt: timePtr(at(12, 5)),
+			now:       at(12, 10),
+			expected:  false,
+		},
+		{
+			name:         "falls back on the last successful sync when the sync runs can't be read",
+			lastSyncedAt: timePtr(at(11, 5)),
+			runsErr:      errors.New("database error"),
+			now:          at(12, 10),
+			expected:     true,
+		},
+		{
+			name:         "integration schedule overrides the default",
+			syncSchedule: stringPtr("*/5 * * * *"),
+			lastSyncedAt: timePtr(at(12, 4)),
+			now:          at(12, 5),
+			expected:     true,
+		},
+		{
+			name:         "invalid integration schedule falls back on the default",
+			syncSchedule: stringPtr("every five minutes"),
+			lastSyncedAt: timePtr(at(12, 4)),
+			now:          at(12, 5),
+			expected:     false,
+		},
+		{
+			name:         "empty integration schedule uses the default",
+			syncSchedule: stringPtr(""),
+			lastSyncedAt: timePtr(at(11, 4)),
+			now:          at(12, 0),
+			expected:     true,
+		},
+		{
+			name:         "schedule which never matches is never due",
+			syncSchedule: stringPtr("0 0 31 2 *"),
+			lastSyncedAt: timePtr(at(11, 4)),
+			now:          at(12, 0).AddDate(1, 0, 0),
+			expected:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			integrationAPI := &MockIntegrationAPI{}
+			runs := []types.SyncRun{}
+			if tt.lastRunAt != nil {
+				runs = append(runs, types.SyncRun{StartedAt: *tt.lastRunAt})
+			}
+			integrationAPI.On("GetSyncRuns", mock.Anything, "integration-1", &types.SyncRunParams{Limit: 1}).Return(runs, tt.runsErr)
+
+			schedules := NewIntegrationSchedules(integrationAPI, hourly(t), 0)
+			integration := &types.IntegrationConfig{ID: "integration-1", SyncSchedule: tt.syncSchedule, LastSyncedAt: tt.lastSyncedAt}
+
+			assert.Equal(t, tt.expected, schedules.Due(context.Background(), integration, tt.now))
+		})
+	}
+}
+
+func TestIntegrationSchedulesAttempts(t *testing.T) {
+	integrationAPI := &MockIntegrationAPI{}
+	integrationAPI.On("GetSyncRuns", mock.Anything, "integration-1", mock.Anything).Return([]types.SyncRun{}, nil)
+
+	schedules := NewIntegrationSchedules(integrationAPI, hourly(t), 0)
+	integration := &types.IntegrationConfig{ID: "integration-1"}
+
+	// An attempt which recorded no sync run waits for the next activation
+	assert.True(t, schedules.Due(context.Background(), integration, at(12, 10)))
+	assert.False(t, schedules.Due(context.Background(), integration, at(12, 11)))
+	assert.True(t, schedules.Due(context.Background(), integration, at(13, 0)))
+
+	// A retried attempt is due again at the next tick
+	schedules.Retry(integration.ID)
+	assert.True(t, schedules.Due(context.Background(), integration, at(13, 1)))
+}
+
+func TestIntegrationSchedulesJitter(t *testing.T) {
+	integrationAPI := &MockIntegrationAPI{}
+	integrationAPI.On("GetSyncRuns", mock.Anything, mock.Anything, mock.Anything).Return([]types.SyncRun{}, nil)
+
+	schedules := NewIntegrationSchedules(integrationAPI, hourly(t), 30*time.Minute)
+
+	for _, id := range []string{"integration-1", "integration-2", "integration-3"} {
+		jitter := schedules.jitter(id)
+		assert.Equal(t, jitter, schedules.jitter(id), "jitter must be stable")
+		assert.GreaterOrEqual(t, jitter, time.Duration(0))
+		assert.Less(t, jitter, 30*time.Minute)
+
+		// The last sync ran at the jittered 11:00 activation, the next one is the jittered 12:00 activation
+		integration := &types.IntegrationConfig{ID: id, LastSyncedAt: timePtr(at(11, 0).Add(jitter))}
+		assert.False(t, schedules.Due(context.Background(), integration, at(12, 0).Add(jitter).Add(-time.Second)), id)
+		assert.True(t, schedules.Due(context.Background(), integration, at(12, 0).Add(jitter)), id)
+	}
+}
@@ -2,6 +2,8 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 )
 
@@ -9,36 +11,82 @@ type Job interface {
 	Run(ctx context.Context) error
 }
 
+// Scheduler runs a job at every tick. Jobs decide which of their integrations are due with
+// IntegrationSchedules, so the tick only bounds how late a scheduled sync can start.
 type Scheduler struct {
+	name     string
 	job      Job
 	interval time.Duration
+
+	// Runs use their own context so that shutting down lets the in-flight run finish
+	runCtx    context.Context
+	cancelRun context.CancelFunc
+	stop      chan struct{}
+	stopOnce  sync.Once
+	done      chan struct{}
 }
 
-func NewScheduler(job Job, interval time.Duration) *Scheduler {
+func NewScheduler(name string, job Job, interval time.Duration) *Scheduler {
+	runCtx, cancelRun := context.WithCancel(context.Background())
 	return &Scheduler{
-		job:      job,
-		interval: interval,
+		name:      name,
+		job:       job,
+		interval:  interval,
+		runCtx:    runCtx,
+		cancelRun: cancelRun,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
 	}
 }
 
+// Start runs the job immediately and then at every tick, until the context is done or Shutdown is called
 func (s *Scheduler) Start(ctx context.Context) {
+	defer close(s.done)
+
 	ticker := time.NewTicker(s.interval)
 	defer ticker.Stop()
 
-	// Run immediately
-	if err := s.job.Run(ctx); err != nil {
-		// Log error but continue
-	}
-
-	// Then run on schedule
 	for {
 		select {
 		case <-ctx.Done():
 			return
+		case <-s.stop:
+			return
+		default:
+		}
+
+		s.run()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stop:
+			return
 		case <-ticker.C:
-			if err := s.job.Run(ctx); err != nil {
-				// Log error but continue
-			}
 		}
 	}
 }
+
+// Shutdown stops scheduling runs and waits for the in-flight run. The run is cancelled when the context
+// is done before it finishes. Start must have been called.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		fmt.Printf("Job %s didn't finish in time, cancelling it\n", s.name)
+		s.cancelRun()
+		<-s.done
+		return ctx.Err()
+	}
+}
+
+// run runs the job once and logs its error. The outcome of every integration sync is recorded as a sync run.
+func (s *Scheduler) run() {
+	startedAt := time.Now()
+	if err := s.job.Run(s.runCtx); err != nil {
+		fmt.Printf("Job %s failed after %s: %v\n", s.name, time.Since(startedAt).Round(time.Millisecond), err)
+	}
+}
@@ -0,0 +1,242 @@
+package sourcecontrol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"ems.dev/backend/jobs/sourcecontrol/providers"
+	"ems.dev/backend/jobs/synclock"
+	"ems.dev/backend/jobs/workerpool"
+	"ems.dev/backend/libraries/github"
+	intapi "ems.dev/backend/services/integration/api"
+	"ems.dev/backend/services/integration/types"
+)
+
+// BackfillConfig configures the backfill job
+type BackfillConfig struct {
+	// Integrations is the number of integrations backfilled concurrently
+	Integrations int
+	// ChunkSize is the range of pull request creation dates imported between two checkpoints
+	ChunkSize time.Duration
+	// MaxRunTime bounds how long a run holds an integration's sync lease, so that the integration's
+	// scheduled syncs get to run during long backfills
+	MaxRunTime time.Duration
+}
+
+// BackfillJob imports the pull request history of repositories. Every run continues the active backfills
+// from their checkpoints, one chunk at a time, so that backfills interrupted by a crash, a shutdown or a
+// rate limit pick up where they stopped.
+type BackfillJob struct {
+	integrationAPI  intapi.IntegrationAPI
+	providerFactory providers.ProviderFactory
+	config          BackfillConfig
+	locker          *synclock.Locker
+}
+
+func NewBackfillJob(integrationAPI intapi.IntegrationAPI, providerFactory providers.ProviderFactory, config BackfillConfig, locker *synclock.Locker) *BackfillJob {
+	return &BackfillJob{
+		integrationAPI:  integrationAPI,
+		providerFactory: providerFactory,
+		config:          config,
+		locker:          locker,
+	}
+}
+
+// Run continues the active backfills. The backfills of an integration run one after the other, oldest
+// first, while holding the integration's sync lease.
+func (j *BackfillJob) Run(ctx context.Context) error {
+	backfills, err := j.integrationAPI.GetActiveRepositoryBackfills(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get active backfills: %w", err)
+	}
+
+	// Backfills are returned most recent first
+	byIntegration := make(map[string][]types.RepositoryBackfill)
+	integrationIDs := []string{}
+	for i := len(backfills) - 1; i >= 0; i-- {
+		backfill := backfills[i]
+		if _, ok := byIntegration[backfill.IntegrationConfigID]; !ok {
+			integrationIDs = append(integrationIDs, backfill.IntegrationConfigID)
+		}
+		byIntegration[backfill.IntegrationConfigID] = append(byIntegration[backfill.IntegrationConfigID], backfill)
+	}
+
+	var started, failed atomic.Int32
+	pool := workerpool.New(j.config.Integrations)
+	for _, integrationID := range integrationIDs {
+		integrationID := integrationID
+		pool.Go(ctx, integrationID, func(ctx context.Context) {
+			ran, ok := j.backfillIntegration(ctx, integrationID, byIntegration[integrationID])
+			if ran {
+				started.Add(1)
+			}
+			if !ok {
+				failed.Add(1)
+			}
+		})
+	}
+	pool.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if failed.Load() > 0 {
+		return fmt.Errorf("%d of %d integrations failed to backfill", failed.Load(), started.Load())
+	}
+	return nil
+}
+
+// backfillIntegration continues the backfills of an integration whose rate limit pause is over. Returns
+// whether it ran, and false when one of the backfills failed.
+func (j *BackfillJob) backfillIntegration(ctx context.Context, integrationID string, backfills []types.RepositoryBackfill) (bool, bool) {
+	now := time.Now()
+	ready := []types.RepositoryBackfill{}
+	for _, backfill := range backfills {
+		if backfill.Status == types.BackfillStatusPaused && backfill.PausedUntil != nil && now.Before(*backfill.PausedUntil) {
+			continue
+		}
+		ready = append(ready, backfill)
+	}
+	if len(ready) == 0 {
+		return false, true
+	}
+
+	integration, err := j.integrationAPI.GetIntegrationConfig(ctx, integrationID)
+	if err != nil {
+		fmt.Printf("Failed to get integration %s to backfill: %v\n", integrationID, err)
+		return true, false
+	}
+
+	provider, err := j.providerFactory.GetProvider(string(integration.ProviderName))
+	if err != nil {
+		fmt.Printf("Failed to create provider for %s: %v\n", integration.ProviderName, err)
+		return true, false
+	}
+
+	backfiller, ok := provider.(providers.RepositoryBackfiller)
+	if !ok {
+		for i := range ready {
+			j.fail(&ready[i], fmt.Errorf("backfill is not supported for %s integrations", integration.ProviderName))
+		}
+		return true, false
+	}
+
+	repositories, err := types.ParseRepositoryConfigs(integration.Metadata)
+	if err != nil {
+		fmt.Printf("Failed to parse metadata for integration %s: %v\n", integration.ID, err)
+		return true, false
+	}
+
+	// Backfills and syncs of an integration share its token, they run one at a time
+	ctx, unlock, err := j.locker.Lock(ctx, integration.ID)
+	if errors.Is(err, synclock.ErrLocked) {
+		fmt.Printf("Postponing backfill of integration %s, it is being synced\n", integration.ID)
+		return false, true
+	}
+	if err != nil {
+		fmt.Printf("Failed to lock integration %s: %v\n", integration.ID, err)
+		return true, false
+	}
+	defer unlock()
+
+	if j.config.MaxRunTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.config.MaxRunTime)
+		defer cancel()
+	}
+
+	succeeded := true
+	for i := range ready {
+		if ctx.Err() != nil {
+			break
+		}
+
+		backfill := &ready[i]
+		if err := j.backfillRepository(ctx, integration, backfiller, repositories, backfill); err != nil {
+			if _, rateLimited := github.IsRateLimitError(err); !rateLimited {
+				succeeded = false
+			}
+			fmt.Printf("Failed to backfill repository %s for integration %s: %v\n", backfill.Repository, integration.ID, err)
+		}
+	}
+	return true, succeeded
+}
+
+// backfillRepository imports the chunks of a backfill from its checkpoint, saving the checkpoint after
+// every chunk. A backfill interrupted by the end of the run keeps its checkpoint, a rate limited backfill
+// is paused until the limit resets.
+func (j *BackfillJob) backfillRepository(ctx context.Context, integration *types.IntegrationConfig, backfiller providers.RepositoryBackfiller, repositories []types.RepositoryConfig, backfill *types.RepositoryBackfill) error {
+	var repository *types.RepositoryConfig
+	for i := range repositories {
+		if repositories[i].Name == backfill.Repository {
+			repository = &repositories[i]
+			break
+		}
+	}
+	if repository == nil {
+		err := fmt.Errorf("repository %s is no longer synced by the integration", backfill.Repository)
+		j.fail(backfill, err)
+		return err
+	}
+
+	backfill.Status = types.BackfillStatusRunning
+	backfill.PausedUntil = nil
+	j.save(backfill)
+
+	for backfill.Checkpoint.Before(backfill.EndDate) {
+		chunkEnd := backfill.Checkpoint.Add(j.config.ChunkSize)
+		if chunkEnd.After(backfill.EndDate) {
+			chunkEnd = backfill.EndDate
+		}
+
+		counts, err := backfiller.BackfillRepository(ctx, integration, *repository, backfill.Checkpoint, chunkEnd)
+		backfill.PullRequestsSynced += counts.PullRequests
+		backfill.CommentsSynced += counts.Comments
+
+		if err != nil {
+			// Stopped by a shutdown, the end of the run or a lost lease, the chunk is imported again by the next run
+			if ctx.Err() != nil {
+				j.save(backfill)
+				return nil
+			}
+
+			if rateLimitErr, ok := github.IsRateLimitError(err); ok {
+				backfill.Status = types.BackfillStatusPaused
+				backfill.PausedUntil = &rateLimitErr.ResetAt
+				j.save(backfill)
+				return err
+			}
+
+			j.fail(backfill, err)
+			return err
+		}
+
+		backfill.Checkpoint = chunkEnd
+		if !backfill.Checkpoint.Before(backfill.EndDate) {
+			completedAt := time.Now()
+			backfill.Status = types.BackfillStatusCompleted
+			backfill.CompletedAt = &completedAt
+		}
+		j.save(backfill)
+	}
+	return nil
+}
+
+// fail marks a backfill as failed, it can be restarted by creating a new one
+func (j *BackfillJob) fail(backfill *types.RepositoryBackfill, err error) {
+	message := err.Error()
+	backfill.Status = types.BackfillStatusFailed
+	backfill.ErrorMessage = &message
+	j.save(backfill)
+}
+
+// save saves a backfill's checkpoint and status. Saved even when the backfill was interrupted, so it
+// doesn't use the backfill's context.
+func (j *BackfillJob) save(backfill *types.RepositoryBackfill) {
+	if err := j.integrationAPI.UpdateRepositoryBackfill(context.Background(), backfill); err != nil {
+		fmt.Printf("Failed to save backfill %s of repository %s: %v\n", backfill.ID, backfill.Repository, err)
+	}
+}
@@ -0,0 +1,192 @@
+package sourcecontrol
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ems.dev/backend/jobs/synclock"
+	"ems.dev/backend/libraries/github"
+	"ems.dev/backend/services/integration/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/datatypes"
+)
+
+func TestBackfillJobRun(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(3 * 24 * time.Hour)
+	resetAt := time.Now().Add(time.Hour)
+
+	integration := &types.IntegrationConfig{
+		ID:           "integration-1",
+		ProviderName: types.IntegrationProviderGithub,
+		ProviderType: types.IntegrationProviderTypeSourceControl,
+		Metadata:     datatypes.JSON(`{"repositories": "repo-a"}`),
+	}
+
+	tests := []struct {
+		name     string
+		backfill types.RepositoryBackfill
+		// provider is nil for providers which can't backfill
+		provider  func() *MockBackfillProvider
+		leaseFree bool
+		// expectedChunks is the number of chunks imported by the provider
+		expectedChunks      int
+		expectedStatus      types.BackfillStatus
+		expectedCheckpoint  time.Time
+		expectedPRs         int
+		expectedPausedUntil *time.Time
+		expectedError       bool
+	}{
+		{
+			name:      "imports every chunk and completes",
+			backfill:  types.RepositoryBackfill{ID: "backfill-1", IntegrationConfigID: integration.ID, Repository: "repo-a", StartDate: start, EndDate: end, Checkpoint: start, Status: types.BackfillStatusPending},
+			leaseFree: true,
+			provider: func() *MockBackfillProvider {
+				provider := &MockBackfillProvider{}
+				provider.On("BackfillRepository", mock.Anything, integration, types.RepositoryConfig{Name: "repo-a"}, mock.Anything, mock.Anything).Return(types.SyncCounts{PullRequests: 2, Comments: 1}, nil)
+				return provider
+			},
+			expectedChunks:     3,
+			expectedStatus:     types.BackfillStatusCompleted,
+			expectedCheckpoint: end,
+			expectedPRs:        6,
+		},
+		{
+			name:      "continues from the checkpoint",
+			backfill:  types.RepositoryBackfill{ID: "backfill-1", IntegrationConfigID: integration.ID, Repository: "repo-a", StartDate: start, EndDate: end, Checkpoint: end.Add(-24 * time.Hour), Status: types.BackfillStatusRunning},
+			leaseFree: true,
+			provider: func() *MockBackfillProvider {
+				provider := &MockBackfillProvider{}
+				provider.On("BackfillRepository", mock.Anything, integration, types.RepositoryConfig{Name: "repo-a"}, end.Add(-24*time.Hour), end).Return(types.SyncCounts{PullRequests: 1}, nil)
+				return provider
+			},
+			expectedChunks:     1,
+			expectedStatus:     types.BackfillStatusCompleted,
+			expectedCheckpoint: end,
+			expectedPRs:        1,
+		},
+		{
+			name:      "rate limit pauses the backfill at its checkpoint",
+			backfill:  types.RepositoryBackfill{ID: "backfill-1", IntegrationConfigID: integration.ID, Repository: "repo-a", StartDate: start, EndDate: end, Checkpoint: start, Status: types.BackfillStatusPending},
+			leaseFree: true,
+			provider: func() *MockBackfillProvider {
+				provider := &MockBackfillProvider{}
+				provider.On("BackfillRepository", mock.Anything, integration, types.RepositoryConfig{Name: "repo-a"}, start, start.Add(24*time.Hour)).Return(types.SyncCounts{PullRequests: 1}, nil).Once()
+				provider.On("BackfillRepository", mock.Anything, integration, types.RepositoryConfig{Name: "repo-a"}, start.Add(24*time.Hour), start.Add(48*time.Hour)).Return(types.SyncCounts{}, &github.RateLimitError{ResetAt: resetAt}).Once()
+				return provider
+			},
+			expectedChunks:      2,
+			expectedStatus:      types.BackfillStatusPaused,
+			expectedCheckpoint:  start.Add(24 * time.Hour),
+			expectedPRs:         1,
+			expectedPausedUntil: &resetAt,
+		},
+		{
+			name:      "provider error fails the backfill",
+			backfill:  types.RepositoryBackfill{ID: "backfill-1", IntegrationConfigID: integration.ID, Repository: "repo-a", StartDate: start, EndDate: end, Checkpoint: start, Status: types.BackfillStatusPending},
+			leaseFree: true,
+			provider: func() *MockBackfillProvider {
+				provider := &MockBackfillProvider{}
+				provider.On("BackfillRepository", mock.Anything, integration, types.RepositoryConfig{Name: "repo-a"}, mock.Anything, mock.Anything).Return(types.SyncCounts{}, errors.New("boom"))
+				return provider
+			},
+			expectedChunks:     1,
+			expectedStatus:     types.BackfillStatusFailed,
+			expectedCheckpoint: start,
+			expectedError:      true,
+		},
+		{
+			name:               "repository no longer synced fails the backfill",
+			backfill:           types.RepositoryBackfill{ID: "backfill-1", IntegrationConfigID: integration.ID, Repository: "repo-b", StartDate: start, EndDate: end, Checkpoint: start, Status: types.BackfillStatusPending},
+			leaseFree:          true,
+			provider:           func() *MockBackfillProvider { return &MockBackfillProvider{} },
+			expectedStatus:     types.BackfillStatusFailed,
+			expectedCheckpoint: start,
+			expectedError:      true,
+		},
+		{
+			name:               "postponed while the integration is being synced",
+			backfill:           types.RepositoryBackfill{ID: "backfill-1", IntegrationConfigID: integration.ID, Repository: "repo-a", StartDate: start, EndDate: end, Checkpoint: start, Status: types.BackfillStatusPending},
+			leaseFree:          false,
+			provider:           func() *MockBackfillProvider { return &MockBackfillProvider{} },
+			expectedStatus:     types.BackfillStatusPending,
+			expectedCheckpoint: start,
+		},
+		{
+			name:               "unsupported provider fails the backfill",
+			backfill:           types.RepositoryBackfill{ID: "backfill-1", IntegrationConfigID: integration.ID, Repository: "repo-a", StartDate: start, EndDate: end, Checkpoint: start, Status: types.BackfillStatusPending},
+			expectedStatus:     types.BackfillStatusFailed,
+			expectedCheckpoint: start,
+			expectedError:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			integrationAPI := &MockIntegrationAPI{}
+			providerFactory := &MockProviderFactory{}
+
+			integrationAPI.On("GetActiveRepositoryBackfills", mock.Anything).Return([]types.RepositoryBackfill{tt.backfill}, nil)
+			integrationAPI.On("GetIntegrationConfig", mock.Anything, integration.ID).Return(integration, nil)
+			integrationAPI.On("AcquireSyncLease", mock.Anything, integration.ID, "holder", time.Minute).Return(tt.leaseFree, nil).Maybe()
+			integrationAPI.On("ReleaseSyncLease", mock.Anything, integration.ID, "holder").Return(nil).Maybe()
+
+			var saved []types.RepositoryBackfill
+			integrationAPI.On("UpdateRepositoryBackfill", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				saved = append(saved, *args.Get(1).(*types.RepositoryBackfill))
+			}).Return(nil).Maybe()
+
+			var provider *MockBackfillProvider
+			if tt.provider != nil {
+				provider = tt.provider()
+				providerFactory.On("GetProvider", "github").Return(provider, nil)
+			} else {
+				providerFactory.On("GetProvider", "github").Return(&MockProvider{}, nil)
+			}
+
+			job := NewBackfillJob(integrationAPI, providerFactory, BackfillConfig{Integrations: 1, ChunkSize: 24 * time.Hour}, synclock.NewLocker(integrationAPI, "holder", time.Minute))
+			err := job.Run(context.Background())
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if provider != nil {
+				provider.AssertNumberOfCalls(t, "BackfillRepository", tt.expectedChunks)
+			}
+
+			last := tt.backfill
+			if len(saved) > 0 {
+				last = saved[len(saved)-1]
+			}
+			assert.Equal(t, tt.expectedStatus, last.Status)
+			assert.Equal(t, tt.expectedCheckpoint, last.Checkpoint)
+			assert.Equal(t, tt.expectedPRs, last.PullRequestsSynced)
+			assert.Equal(t, tt.expectedPausedUntil, last.PausedUntil)
+			if tt.expectedStatus == types.BackfillStatusFailed {
+				assert.NotNil(t, last.ErrorMessage)
+			}
+		})
+	}
+}
+
+func TestBackfillJobRunSkipsPausedBackfills(t *testing.T) {
+	pausedUntil := time.Now().Add(time.Hour)
+	integrationAPI := &MockIntegrationAPI{}
+	providerFactory := &MockProviderFactory{}
+
+	integrationAPI.On("GetActiveRepositoryBackfills", mock.Anything).Return([]types.RepositoryBackfill{
+		{ID: "backfill-1", IntegrationConfigID: "integration-1", Repository: "repo-a", Status: types.BackfillStatusPaused, PausedUntil: &pausedUntil},
+	}, nil)
+
+	job := NewBackfillJob(integrationAPI, providerFactory, BackfillConfig{Integrations: 1, ChunkSize: 24 * time.Hour}, synclock.NewLocker(integrationAPI, "holder", time.Minute))
+	assert.NoError(t, job.Run(context.Background()))
+
+	integrationAPI.AssertNotCalled(t, "GetIntegrationConfig", mock.Anything, mock.Anything)
+	integrationAPI.AssertNotCalled(t, "UpdateRepositoryBackfill", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,217 @@
+package sourcecontrol
+
+import (
+	"context"
+	"time"
+
+	"ems.dev/backend/jobs/sourcecontrol/providers"
+	"ems.dev/backend/services/integration/types"
+	orgapi "ems.dev/backend/services/organization/api"
+	orgtypes "ems.dev/backend/services/organization/types"
+	sourcecontrolapi "ems.dev/backend/services/sourcecontrol/api"
+	sourcecontroltypes "ems.dev/backend/services/sourcecontrol/types"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockIntegrationAPI is a mock implementation of the integration API interface
+type MockIntegrationAPI struct {
+	mock.Mock
+}
+
+func (m *MockIntegrationAPI) CreateIntegrationConfig(ctx context.Context, orgID string, req *types.CreateIntegrationConfigRequest) (*types.IntegrationConfig, error) {
+	args := m.Called(ctx, orgID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.IntegrationConfig), args.Error(1)
+}
+
+func (m *MockIntegrationAPI) GetIntegrationConfig(ctx context.Context, id string) (*types.IntegrationConfig, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.IntegrationConfig), args.Error(1)
+}
+
+func (m *MockIntegrationAPI) GetOrganizationIntegrationConfigs(ctx context.Context, orgID string) ([]types.IntegrationConfig, error) {
+	args := m.Called(ctx, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.IntegrationConfig), args.Error(1)
+}
+
+func (m *MockIntegrationAPI) UpdateIntegrationConfig(ctx context.Context, id string, req *types.UpdateIntegrationConfigRequest) (*types.IntegrationConfig, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.IntegrationConfig), args.Error(1)
+}
+
+func (m *MockIntegrationAPI) DeleteIntegrationConfig(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockIntegrationAPI) DecryptToken(encryptedToken string) (string, error) {
+	args := m.Called(encryptedToken)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockIntegrationAPI) UpdateLastSyncedAt(ctx context.Context, id string, syncedAt time.Time) error {
+	args := m.Called(ctx, id, syncedAt)
+	return args.Error(0)
+}
+
+func (m *MockIntegrationAPI) GetRepositorySyncCursor(ctx context.Context, integrationConfigID, repository string) (*types.RepositorySyncCursor, error) {
+	args := m.Called(ctx, integrationConfigID, repository)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.RepositorySyncCursor), args.Error(1)
+}
+
+func (m *MockIntegrationAPI) UpdateRepositorySyncCursor(ctx context.Context, integrationConfigID, repository string, lastUpdatedAt time.Time) error {
+	args := m.Called(ctx, integrationConfigID, repository, lastUpdatedAt)
+	return args.Error(0)
+}
+
+func (m *MockIntegrationAPI) StartSyncRun(ctx context.Context, integrationConfigID string, repository *string) (*types.SyncRun, error) {
+	args := m.Called(ctx, integrationConfigID, repository)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.SyncRun), args.Error(1)
+}
+
+func (m *MockIntegrationAPI) FinishSyncRun(ctx context.Context, run *types.SyncRun, status types.SyncRunStatus, counts types.SyncCounts, syncErr error) error {
+	args := m.Called(ctx, run, status, counts, syncErr)
+	return args.Error(0)
+}
+
+func (m *MockIntegrationAPI) GetSyncRuns(ctx context.Context, integrationConfigID string, params *types.SyncRunParams) ([]types.SyncRun, error) {
+	args := m.Called(ctx, integrationConfigID, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.SyncRun), args.Error(1)
+}
+
+func (m *MockIntegrationAPI) AcquireSyncLease(ctx context.Context, integrationConfigID, holder string, ttl time.Duration) (bool, error) {
+	args := m.Called(ctx, integrationConfigID, holder, ttl)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockIntegrationAPI) RenewSyncLease(ctx context.Context, integrationConfigID, holder string, ttl time.Duration) (bool, error) {
+	args := m.Called(ctx, integrationConfigID, holder, ttl)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockIntegrationAPI) ReleaseSyncLease(ctx context.Context, integrationConfigID, holder string) error {
+	args := m.Called(ctx, integrationConfigID, holder)
+	return args.Error(0)
+}
+
+func (m *MockIntegrationAPI) CreateRepositoryBackfills(ctx context.Context, integrationConfigID string, req *types.CreateRepositoryBackfillRequest) ([]types.RepositoryBackfill, error) {
+	args := m.Called(ctx, integrationConfigID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.RepositoryBackfill), args.Error(1)
+}
+
+func (m *MockIntegrationAPI) GetRepositoryBackfills(ctx context.Context, integrationConfigID string) ([]types.RepositoryBackfill, error) {
+	args := m.Called(ctx, integrationConfigID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.RepositoryBackfill), args.Error(1)
+}
+
+func (m *MockIntegrationAPI) GetActiveRepositoryBackfills(ctx context.Context) ([]types.RepositoryBackfill, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.RepositoryBackfill), args.Error(1)
+}
+
+func (m *MockIntegrationAPI) UpdateRepositoryBackfill(ctx context.Context, backfill *types.RepositoryBackfill) error {
+	args := m.Called(ctx, backfill)
+	return args.Error(0)
+}
+
+// MockProviderFactory is a mock implementation of the provider factory interface
+type MockProviderFactory struct {
+	mock.Mock
+}
+
+func (m *MockProviderFactory) GetProvider(providerName string) (providers.SourceControlProvider, error) {
+	args := m.Called(providerName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(providers.SourceControlProvider), args.Error(1)
+}
+
+// MockProvider is a mock source control provider which can't backfill repositories
+type MockProvider struct {
+	mock.Mock
+}
+
+func (m *MockProvider) Name() string {
+	return "mock"
+}
+
+func (m *MockProvider) SyncRepositories(ctx context.Context, config *types.IntegrationConfig, repositories []types.RepositoryConfig) (types.SyncCounts, error) {
+	args := m.Called(ctx, config, repositories)
+	return args.Get(0).(types.SyncCounts), args.Error(1)
+}
+
+// MockBackfillProvider is a mock source control provider which can backfill repositories
+type MockBackfillProvider struct {
+	MockProvider
+}
+
+func (m *MockBackfillProvider) BackfillRepository(ctx context.Context, config *types.IntegrationConfig, repository types.RepositoryConfig, from, to time.Time) (types.SyncCounts, error) {
+	args := m.Called(ctx, config, repository, from, to)
+	return args.Get(0).(types.SyncCounts), args.Error(1)
+}
+
+// MockOrganizationAPI is a mock of the organization API, only the methods used by the jobs are implemented
+type MockOrganizationAPI struct {
+	orgapi.OrganizationAPI
+	mock.Mock
+}
+
+func (m *MockOrganizationAPI) GetOrganizations(ctx context.Context) ([]orgtypes.Organization, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]orgtypes.Organization), args.Error(1)
+}
+
+// MockSourceControlAPI is a mock of the source control API, only the methods used by the jobs are implemented
+type MockSourceControlAPI struct {
+	sourcecontrolapi.SourceControlAPI
+	mock.Mock
+}
+
+func (m *MockSourceControlAPI) RecomputePullRequestMetrics(ctx context.Context, params *sourcecontroltypes.RecomputeMetricsParams) (*sourcecontroltypes.RecomputeMetricsResult, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sourcecontroltypes.RecomputeMetricsResult), args.Error(1)
+}
+
+func (m *MockSourceControlAPI) MarkPullRequestMetricsOutdated(ctx context.Context, params *sourcecontroltypes.RecomputeMetricsParams) (*sourcecontroltypes.RecomputeMetricsResult, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sourcecontroltypes.RecomputeMetricsResult), args.Error(1)
+}
@@ -0,0 +1,495 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"ems.dev/backend/libraries/bitbucket"
+	bitbuckettypes "ems.dev/backend/libraries/bitbucket/types"
+	"ems.dev/backend/services/integration/api"
+	"ems.dev/backend/services/integration/types"
+	memberapi "ems.dev/backend/services/member/api"
+	membertypes "ems.dev/backend/services/member/types"
+	sourcecontrolapi "ems.dev/backend/services/sourcecontrol/api"
+	internaltypes "ems.dev/backend/services/sourcecontrol/types"
+	teamapi "ems.dev/backend/services/team/api"
+	teamtypes "ems.dev/backend/services/team/types"
+	"github.com/samber/lo"
+	"gorm.io/datatypes"
+)
+
+type BitbucketProvider struct {
+	cloudClient      bitbucket.BitbucketClient
+	dataCenterClient bitbucket.BitbucketClient
+	integrationAPI   api.IntegrationAPI
+	sourceControlAPI sourcecontrolapi.SourceControlAPI
+	memberAPI        memberapi.MemberAPI
+	teamAPI          teamapi.TeamAPI
+
+	// authorMu serializes author upserts, repositories are synced concurrently and share their authors
+	authorMu sync.Mutex
+}
+
+func NewProvider(
+	cloudClient bitbucket.BitbucketClient,
+	dataCenterClient bitbucket.BitbucketClient,
+	integrationAPI api.IntegrationAPI,
+	sourceControlAPI sourcecontrolapi.SourceControlAPI,
+	memberAPI memberapi.MemberAPI,
+	teamAPI teamapi.TeamAPI,
+) *BitbucketProvider {
+	return &BitbucketProvider{
+		cloudClient:      cloudClient,
+		dataCenterClient: dataCenterClient,
+		integrationAPI:   integrationAPI,
+		sourceControlAPI: sourceControlAPI,
+		memberAPI:        memberAPI,
+		teamAPI:          teamAPI,
+	}
+}
+
+func (p *BitbucketProvider) Name() string {
+	return "bitbucket"
+}
+
+// syncedComment is a comment or review fetched from Bitbucket, normalised to the shape stored as a PRComment
+type syncedComment struct {
+	ProviderID string
+	User       bitbuckettypes.User
+	Body       string
+	Type       string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	// ReviewState is only set on reviews
+	ReviewState *string
+}
+
+// SyncRepositories fetches and syncs pull requests for the given Bitbucket repositories.
+// Repositories are "workspace/repo" on Bitbucket Cloud and "PROJECT/repo" on Data Center. Integrations
+// with a "base_url" metadata entry are synced from that Data Center instance, all others from bitbucket.org.
+func (p *BitbucketProvider) SyncRepositories(ctx context.Context, config *types.IntegrationConfig, repositories []types.RepositoryConfig) (types.SyncCounts, error) {
+	counts := types.SyncCounts{}
+
+	// Decrypt the token
+	token, err := p.integrationAPI.DecryptToken(config.EncryptedToken)
+	if err != nil {
+		return counts, fmt.Errorf("failed to decrypt token: %w", err)
+	}
+
+	client := p.cloudClient
+	baseURL := ""
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(config.Metadata, &metadata); err == nil {
+		if configuredURL, ok := metadata["base_url"].(string); ok && configuredURL != "" {
+			client = p.dataCenterClient
+			baseURL = configuredURL
+		}
+	}
+
+	// Get the rules attributing the PRs to the organization's teams
+	attributor, err := p.teamAPI.GetAttributor(ctx, config.OrganizationID)
+	if err != nil {
+		// Log error but don't fail - team attribution is optional
+		fmt.Printf("Warning: failed to fetch team attribution rules: %v\n", err)
+		attributor = teamtypes.NewAttributor(nil, nil)
+	}
+
+	// Process each repository
+	now := time.Now()
+	for _, repoConfig := range repositories {
+		repo := repoConfig.Name
+
+		parts := strings.Split(repo, "/")
+		if len(parts) != 2 {
+			return counts, fmt.Errorf("invalid repository format: %s. Expected format: workspace/repo", repo)
+		}
+
+		repoName := parts[1]
+
+		repositoryID, err := p.syncRepository(ctx, config, client, baseURL, repo, token)
+		if err != nil {
+			return counts, err
+		}
+
+		// 1. Fetch PRs from Bitbucket
+		prs, err := client.GetPullRequests(ctx, baseURL, repo, token, 20)
+		if err != nil {
+			return counts, fmt.Errorf("failed to fetch pull requests for %s: %w", repo, err)
+		}
+
+		prIds := []string{}
+		for _, pr := range prs {
+			prIds = append(prIds, fmt.Sprintf("%d", pr.ID))
+		}
+
+		// 2. Get all PRs from the database. Bitbucket PR IDs are only unique within a repository,
+		// so the lookup is scoped to the organization and repository.
+		importedPRs, err := p.sourceControlAPI.GetPullRequests(ctx, &internaltypes.PullRequestParams{
+			ProviderIDs:    prIds,
+			OrganizationID: &config.OrganizationID,
+			RepositoryName: repoName,
+		})
+		if err != nil {
+			return counts, fmt.Errorf("failed to fetch imported pull requests for %s: %w", repo, err)
+		}
+
+		importedPRsMap := make(map[string]internaltypes.PullRequest)
+		for _, pr := range importedPRs {
+			importedPRsMap[pr.ProviderID] = *pr
+		}
+
+		// 3. Process each PR
+		for _, pr := range prs {
+			// Ignore PRs excluded by the repository sync rules. Bitbucket has no pull request labels.
+			if !repoConfig.ShouldSync(types.RepositoryPullRequest{
+				BaseBranch: pr.DestinationBranch,
+				Draft:      pr.Draft,
+				Author:     pr.Author.Username,
+				UpdatedAt:  pr.UpdatedAt,
+			}, now) {
+				continue
+			}
+
+			status := convertState(pr.State)
+
+			// Skip PRs which are already imported and closed
+			existingPR, exists := importedPRsMap[fmt.Sprintf("%d", pr.ID)]
+			if exists {
+				if existingPR.Status != "open" && existingPR.Status == status {
+					continue
+				}
+			}
+
+			// Get detailed PR information
+			prDetails, err := client.GetPullRequest(ctx, baseURL, repo, token, pr.ID)
+			if err != nil {
+				return counts, fmt.Errorf("failed to fetch pull request details for PR #%d: %w", pr.ID, err)
+			}
+
+			diffStat, err := client.GetPullRequestDiffStat(ctx, baseURL, repo, token, pr.ID)
+			if err != nil {
+				// Log error but don't fail - line counts are optional
+				fmt.Printf("Warning: failed to fetch diff stat for PR #%d: %v\n", pr.ID, err)
+			} else {
+				prDetails.Additions = diffStat.Additions
+				prDetails.Deletions = diffStat.Deletions
+				prDetails.ChangedFiles = diffStat.ChangedFiles
+			}
+
+			activity, err := client.GetPullRequestActivity(ctx, baseURL, repo, token, pr.ID)
+			if err != nil {
+				return counts, fmt.Errorf("failed to fetch activity for PR #%d: %w", pr.ID, err)
+			}
+
+			// 4. Insert/Update author
+			authorAccount, err := p.upsertAuthor(ctx, config.OrganizationID, prDetails.Author)
+			if err != nil {
+				return counts, fmt.Errorf("failed to upsert author for PR #%d: %w", pr.ID, err)
+			}
+
+			// 5. Insert/Update PR
+			prDetailsBytes, _ := json.Marshal(prDetails)
+
+			// Attribute the PR to a team, commits are only needed by ticket prefix rules. Bitbucket PRs have
+			// no labels and the diff stat has no paths, so label and path glob rules never match them.
+			var commitMessages []string
+			if attributor.Uses(teamtypes.AttributionRuleTicketPrefix) {
+				commits, err := client.GetPullRequestCommits(ctx, baseURL, repo, token, pr.ID)
+				if err != nil {
+					// Log error but don't fail - commit fetching is optional
+					fmt.Printf("Warning: failed to fetch commits for PR #%d: %v\n", pr.ID, err)
+				}
+				commitMessages = lo.Map(commits, func(commit *bitbuckettypes.Commit, _ int) string { return commit.Message })
+			}
+			attribution := attributor.Attribute(teamtypes.AttributionInput{
+				RepositoryName: repoName,
+				Title:          prDetails.Title,
+				Branch:         prDetails.SourceBranch,
+				CommitMessages: commitMessages,
+			})
+			var teamID, matchedPrefix *string
+			if attribution != nil {
+				teamID = &attribution.TeamID
+				matchedPrefix = attribution.Prefix
+			}
+
+			sourceControlPR := &internaltypes.PullRequest{
+				ExternalAccountID: authorAccount.ID,
+				ProviderID:        fmt.Sprintf("%d", prDetails.ID),
+				RepositoryName:    repoName,
+				Title:             prDetails.Title,
+				Description:       prDetails.Description,
+				Status:            convertState(prDetails.State),
+				CreatedAt:         prDetails.CreatedAt,
+				MergedAt:          mergedAt(prDetails, activity),
+				LastUpdatedAt:     prDetails.UpdatedAt,
+				Comments:          prDetails.CommentCount,
+				Additions:         prDetails.Additions,
+				Deletions:         prDetails.Deletions,
+				ChangedFiles:      prDetails.ChangedFiles,
+				URL:               prDetails.URL,
+				Prefix:            matchedPrefix,
+				TeamID:            teamID,
+				RepositoryID:      repositoryID,
+				HeadBranch:        prDetails.SourceBranch,
+				Metadata:          datatypes.JSON(prDetailsBytes),
+			}
+
+			if exists {
+				sourceControlPR.ID = existingPR.ID
+				err := p.sourceControlAPI.UpdatePullRequest(ctx, sourceControlPR)
+				if err != nil {
+					return counts, fmt.Errorf("failed to update pull request for PR #%d: %w", pr.ID, err)
+				}
+				// Updates skip nil fields, a PR which no longer matches any rule is detached from its team explicitly
+				if attribution == nil && (existingPR.TeamID != nil || existingPR.Prefix != nil) {
+					if err := p.sourceControlAPI.UpdatePullRequestTeams(ctx, []*internaltypes.PullRequestTeam{{PRID: existingPR.ID}}); err != nil {
+						return counts, fmt.Errorf("failed to update team of pull request for PR #%d: %w", pr.ID, err)
+					}
+				}
+			} else {
+				createdPR, err := p.sourceControlAPI.CreatePullRequest(ctx, sourceControlPR)
+				if err != nil {
+					return counts, fmt.Errorf("failed to create pull request for PR #%d: %w", pr.ID, err)
+				}
+				sourceControlPR.ID = createdPR.ID
+			}
+
+			counts.PullRequests++
+
+			// 6. Get all reviews, comments and inline comments
+			existingComments, err := p.sourceControlAPI.GetPullRequestComments(ctx, sourceControlPR.ID)
+			if err != nil {
+				return counts, fmt.Errorf("failed to fetch comments for PR #%d: %w", pr.ID, err)
+			}
+
+			existingCommentsMap := make(map[string]internaltypes.PRComment)
+			for _, comment := range existingComments {
+				existingCommentsMap[comment.ProviderID] = *comment
+			}
+
+			allComments := collectComments(prDetails, activity)
+
+			// 7. Process each new comment
+			for _, comment := range allComments {
+				if _, exists := existingCommentsMap[comment.ProviderID]; exists {
+					continue
+				}
+
+				// Insert/Update comment author
+				commentAuthor, err := p.upsertAuthor(ctx, config.OrganizationID, comment.User)
+				if err != nil {
+					return counts, fmt.Errorf("failed to upsert comment author for PR #%d: %w", pr.ID, err)
+				}
+
+				updatedAt := comment.UpdatedAt
+				sourceControlComment := &internaltypes.PRComment{
+					PRID:              sourceControlPR.ID,
+					ExternalAccountID: commentAuthor.ID,
+					ProviderID:        comment.ProviderID,
+					Body:              comment.Body,
+					Type:              comment.Type,
+					CreatedAt:         comment.CreatedAt,
+					UpdatedAt:         &updatedAt,
+					ReviewState:       comment.ReviewState,
+				}
+
+				if err := p.sourceControlAPI.CreatePRComments(ctx, []*internaltypes.PRComment{sourceControlComment}); err != nil {
+					return counts, fmt.Errorf("failed to save comment for PR #%d: %w", pr.ID, err)
+				}
+				counts.Comments++
+			}
+
+			// 8. Calculate PR metrics from the stored comments and reviews
+			if err := p.sourceControlAPI.RefreshPullRequestMetrics(ctx, sourceControlPR); err != nil {
+				return counts, fmt.Errorf("failed to save pull request metrics for PR #%d: %w", pr.ID, err)
+			}
+		}
+	}
+
+	return counts, nil
+}
+
+// collectComments returns the comments and reviews of a pull request. Approvals and "changes requested"
+// reviews are stored as REVIEW comments, keeping the latest one per user and state since they can be
+// withdrawn and given again. Participants who approved without a matching activity entry fall back to
+// the last update of the pull request.
+func collectComments(pr *bitbuckettypes.PullRequest, activity *bitbuckettypes.Activity) []*syncedComment {
+	comments := []*syncedComment{}
+	for _, comment := range activity.Comments {
+		// Comments of deleted users have no author
+		if comment.User.Username == "" {
+			continue
+		}
+
+		commentType := bitbuckettypes.CommentTypeComment
+		if comment.Inline {
+			commentType = bitbuckettypes.CommentTypeReviewComment
+		}
+
+		comments = append(comments, &syncedComment{
+			ProviderID: fmt.Sprintf("%d", comment.ID),
+			User:       comment.User,
+			Body:       comment.Body,
+			Type:       string(commentType),
+			CreatedAt:  comment.CreatedAt,
+			UpdatedAt:  comment.UpdatedAt,
+		})
+	}
+
+	reviews := make(map[string]*syncedComment)
+	addReview := func(user bitbuckettypes.User, state string, date time.Time) {
+		providerID := fmt.Sprintf("%s-%s", reviewProviderIDPrefix(state), user.ID)
+		if existing, ok := reviews[providerID]; ok && !date.After(existing.CreatedAt) {
+			return
+		}
+		reviews[providerID] = &syncedComment{
+			ProviderID:  providerID,
+			User:        user,
+			Type:        string(bitbuckettypes.CommentTypeReview),
+			CreatedAt:   date,
+			UpdatedAt:   date,
+			ReviewState: reviewState(state),
+		}
+	}
+
+	for _, approval := range activity.Approvals {
+		if approval.User.Username == "" {
+			continue
+		}
+		addReview(approval.User, approval.State, approval.Date)
+	}
+
+	for _, participant := range pr.Participants {
+		if !participant.Approved {
+			continue
+		}
+		providerID := fmt.Sprintf("%s-%s", reviewProviderIDPrefix(bitbuckettypes.ApprovalStateApproved), participant.User.ID)
+		if _, ok := reviews[providerID]; !ok {
+			addReview(participant.User, bitbuckettypes.ApprovalStateApproved, pr.UpdatedAt)
+		}
+	}
+
+	for _, review := range reviews {
+		comments = append(comments, review)
+	}
+
+	return comments
+}
+
+// reviewProviderIDPrefix returns the ProviderID prefix of a review, reviews have no ID on Bitbucket
+func reviewProviderIDPrefix(state string) string {
+	if state == bitbuckettypes.ApprovalStateChangesRequested {
+		return "changes-requested"
+	}
+	return "approval"
+}
+
+// reviewState maps a Bitbucket approval state to the stored review state
+func reviewState(state string) *string {
+	reviewState := internaltypes.ReviewStateApproved
+	if state == bitbuckettypes.ApprovalStateChangesRequested {
+		reviewState = internaltypes.ReviewStateChangesRequested
+	}
+	return &reviewState
+}
+
+// mergedAt returns when a pull request was merged. Cloud only exposes it through the activity,
+// Data Center also sets the close date of the pull request.
+func mergedAt(pr *bitbuckettypes.PullRequest, activity *bitbuckettypes.Activity) *time.Time {
+	if pr.State != bitbuckettypes.PullRequestStateMerged {
+		return nil
+	}
+	if activity.MergedAt != nil {
+		return activity.MergedAt
+	}
+	return pr.ClosedAt
+}
+
+// upsertAuthor handles the creation or update of an external account (source control)
+// Returns a SourceControlAccount type for backward compatibility with existing code
+func (p *BitbucketProvider) upsertAuthor(ctx context.Context, organizationID string, user bitbuckettypes.User) (*internaltypes.SourceControlAccount, error) {
+	p.authorMu.Lock()
+	defer p.authorMu.Unlock()
+
+	// Check if account exists
+	sourceControlType := string(membertypes.ExternalAccountTypeSourceControl)
+	accounts, err := p.memberAPI.GetExternalAccounts(ctx, &membertypes.ExternalAccountParams{
+		OrganizationID: organizationID,
+		Usernames:      []string{user.Username},
+		AccountType:    &sourceControlType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch external account: %w", err)
+	}
+
+	for _, account := range accounts {
+		if account.ProviderName == p.Name() && account.Username == user.Username {
+			return convertExternalAccountToSourceControlAccount(&account), nil
+		}
+	}
+
+	// Create the account without member_id and let the application handle the association later
+	metadata, _ := json.Marshal(accountMetadata(user))
+	newAccount := &membertypes.ExternalAccount{
+		AccountType:    sourceControlType,
+		ProviderName:   p.Name(),
+		OrganizationID: &organizationID,
+		Username:       user.Username,
+		ProviderID:     user.ID,
+		Metadata:       datatypes.JSON(metadata),
+	}
+
+	if err := p.memberAPI.CreateExternalAccounts(ctx, []*membertypes.ExternalAccount{newAccount}); err != nil {
+		return nil, fmt.Errorf("failed to create external account: %w", err)
+	}
+
+	return convertExternalAccountToSourceControlAccount(newAccount), nil
+}
+
+// accountMetadata builds the external account metadata for a Bitbucket user. It includes a GitHub style
+// "type" field ("User" or "Bot"), which flags the bot accounts when they are created.
+func accountMetadata(user bitbuckettypes.User) map[string]interface{} {
+	accountType := "User"
+	if user.IsBot() {
+		accountType = "Bot"
+	}
+
+	return map[string]interface{}{
+		"id":           user.ID,
+		"login":        user.Username,
+		"display_name": user.DisplayName,
+		"email":        user.Email,
+		"type":         accountType,
+	}
+}
+
+// convertState maps Bitbucket PR states onto the GitHub style statuses used by the metrics queries,
+// where a merged PR is "closed" with a merged_at timestamp
+func convertState(state string) string {
+	switch state {
+	case bitbuckettypes.PullRequestStateMerged, bitbuckettypes.PullRequestStateDeclined, bitbuckettypes.PullRequestStateSuperseded:
+		return "closed"
+	default:
+		return "open"
+	}
+}
+
+// convertExternalAccountToSourceControlAccount converts an ExternalAccount to SourceControlAccount
+func convertExternalAccountToSourceControlAccount(account *membertypes.ExternalAccount) *internaltypes.SourceControlAccount {
+	return &internaltypes.SourceControlAccount{
+		ID:             account.ID,
+		MemberID:       account.MemberID,
+		OrganizationID: account.OrganizationID,
+		ProviderName:   account.ProviderName,
+		ProviderID:     account.ProviderID,
+		Username:       account.Username,
+		Metadata:       account.Metadata,
+		IsBot:          account.IsBot,
+		LastSyncedAt:   account.LastSyncedAt,
+	}
+}
@@ -0,0 +1,132 @@
+package bitbucket
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	bitbuckettypes "ems.dev/backend/libraries/bitbucket/types"
+	internaltypes "ems.dev/backend/services/sourcecontrol/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviewState(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    string
+		expected string
+	}{
+		{name: "approved", state: bitbuckettypes.ApprovalStateApproved, expected: internaltypes.ReviewStateApproved},
+		{name: "changes requested", state: bitbuckettypes.ApprovalStateChangesRequested, expected: internaltypes.ReviewStateChangesRequested},
+		{name: "cloud approvals have no state", state: "", expected: internaltypes.ReviewStateApproved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, *reviewState(tt.state))
+		})
+	}
+}
+
+func TestCollectComments(t *testing.T) {
+	alice := bitbuckettypes.User{ID: "1", Username: "alice"}
+	bob := bitbuckettypes.User{ID: "2", Username: "bob"}
+	carol := bitbuckettypes.User{ID: "3", Username: "carol"}
+	deleted := bitbuckettypes.User{}
+
+	day := func(d int) time.Time {
+		return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	type expectedComment struct {
+		providerID  string
+		username    string
+		commentType string
+		createdAt   time.Time
+		reviewState *string
+	}
+	approved := internaltypes.ReviewStateApproved
+	changesRequested := internaltypes.ReviewStateChangesRequested
+
+	tests := []struct {
+		name     string
+		pr       *bitbuckettypes.PullRequest
+		activity *bitbuckettypes.Activity
+		expected []expectedComment
+	}{
+		{
+			name: "comments and inline comments",
+			pr:   &bitbuckettypes.PullRequest{},
+			activity: &bitbuckettypes.Activity{Comments: []*bitbuckettypes.Comment{
+				{ID: 1, User: alice, Body: "looks good", CreatedAt: day(1)},
+				{ID: 2, User: bob, Body: "nit", Inline: true, CreatedAt: day(2)},
+				{ID: 3, User: deleted, Body: "ghost", CreatedAt: day(3)},
+			}},
+			expected: []expectedComment{
+				{providerID: "1", username: "alice", commentType: string(bitbuckettypes.CommentTypeComment), createdAt: day(1)},
+				{providerID: "2", username: "bob", commentType: string(bitbuckettypes.CommentTypeReviewComment), createdAt: day(2)},
+			},
+		},
+		{
+			name: "approvals and change requests are separate reviews with their state",
+			pr:   &bitbuckettypes.PullRequest{},
+			activity: &bitbuckettypes.Activity{Approvals: []*bitbuckettypes.Approval{
+				{User: alice, State: bitbuckettypes.ApprovalStateChangesRequested, Date: day(1)},
+				{User: alice, State: bitbuckettypes.ApprovalStateApproved, Date: day(2)},
+				{User: deleted, State: bitbuckettypes.ApprovalStateApproved, Date: day(2)},
+			}},
+			expected: []expectedComment{
+				{providerID: "approval-1", username: "alice", commentType: string(bitbuckettypes.CommentTypeReview), createdAt: day(2), reviewState: &approved},
+				{providerID: "changes-requested-1", username: "alice", commentType: string(bitbuckettypes.CommentTypeReview), createdAt: day(1), reviewState: &changesRequested},
+			},
+		},
+		{
+			name: "keeps the latest review of a user and state",
+			pr:   &bitbuckettypes.PullRequest{},
+			activity: &bitbuckettypes.Activity{Approvals: []*bitbuckettypes.Approval{
+				{User: bob, State: bitbuckettypes.ApprovalStateApproved, Date: day(3)},
+				{User: bob, State: bitbuckettypes.ApprovalStateApproved, Date: day(1)},
+				{User: bob, State: bitbuckettypes.ApprovalStateApproved, Date: day(2)},
+			}},
+			expected: []expectedComment{
+				{providerID: "approval-2", username: "bob", commentType: string(bitbuckettypes.CommentTypeReview), createdAt: day(3), reviewState: &approved},
+			},
+		},
+		{
+			name: "approved participants without activity fall back to the last update",
+			pr: &bitbuckettypes.PullRequest{UpdatedAt: day(9), Participants: []bitbuckettypes.Participant{
+				{User: alice, Approved: true},
+				{User: bob, Approved: false},
+				{User: carol, Approved: true},
+			}},
+			activity: &bitbuckettypes.Activity{Approvals: []*bitbuckettypes.Approval{
+				{User: alice, State: bitbuckettypes.ApprovalStateApproved, Date: day(4)},
+			}},
+			expected: []expectedComment{
+				{providerID: "approval-1", username: "alice", commentType: string(bitbuckettypes.CommentTypeReview), createdAt: day(4), reviewState: &approved},
+				{providerID: "approval-3", username: "carol", commentType: string(bitbuckettypes.CommentTypeReview), createdAt: day(9), reviewState: &approved},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comments := collectComments(tt.pr, tt.activity)
+
+			// Reviews are collected from a map
+			sort.Slice(comments, func(i, j int) bool { return comments[i].ProviderID < comments[j].ProviderID })
+
+			actual := make([]expectedComment, 0, len(comments))
+			for _, comment := range comments {
+				actual = append(actual, expectedComment{
+					providerID:  comment.ProviderID,
+					username:    comment.User.Username,
+					commentType: comment.Type,
+					createdAt:   comment.CreatedAt,
+					reviewState: comment.ReviewState,
+				})
+			}
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
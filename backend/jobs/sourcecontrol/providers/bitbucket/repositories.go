@@ -0,0 +1,49 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ems.dev/backend/libraries/bitbucket"
+	"ems.dev/backend/services/integration/types"
+	internaltypes "ems.dev/backend/services/sourcecontrol/types"
+)
+
+// syncRepository fetches the details of a repository and saves it, returns the ID of the saved repository.
+// Repositories whose details cannot be fetched are saved by workspace (or project key) and slug, keeping the
+// details saved before.
+func (p *BitbucketProvider) syncRepository(ctx context.Context, config *types.IntegrationConfig, client bitbucket.BitbucketClient, baseURL, repo, token string) (*string, error) {
+	parts := strings.Split(repo, "/")
+	repository := &internaltypes.Repository{
+		OrganizationID: config.OrganizationID,
+		Provider:       p.Name(),
+		Owner:          parts[0],
+		Name:           parts[1],
+	}
+
+	details, err := client.GetRepository(ctx, baseURL, repo, token)
+	if err != nil {
+		fmt.Printf("Warning: failed to fetch repository %s: %v\n", repo, err)
+	} else {
+		visibility := internaltypes.RepositoryVisibilityPublic
+		if details.Private {
+			visibility = internaltypes.RepositoryVisibilityPrivate
+		}
+
+		repository.Owner = details.Owner
+		repository.Name = details.Slug
+		repository.Archived = details.Archived
+		repository.Visibility = &visibility
+		// Empty repositories have no default branch
+		if details.DefaultBranch != "" {
+			repository.DefaultBranch = &details.DefaultBranch
+		}
+	}
+
+	if err := p.sourceControlAPI.UpsertRepository(ctx, repository); err != nil {
+		return nil, fmt.Errorf("failed to save repository %s: %w", repo, err)
+	}
+
+	return &repository.ID, nil
+}
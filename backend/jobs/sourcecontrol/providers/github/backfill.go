@@ -0,0 +1,113 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"ems.dev/backend/services/integration/types"
+	internaltypes "ems.dev/backend/services/sourcecontrol/types"
+)
+
+// BackfillRepository imports the pull requests of a repository created from from (inclusive) to to
+// (exclusive), oldest first. Pull requests excluded by the repository sync rules are skipped, except for
+// the max lookback which only bounds the regular syncs. The repository sync cursor isn't moved.
+func (p *GitHubProvider) BackfillRepository(ctx context.Context, config *types.IntegrationConfig, repoConfig types.RepositoryConfig, from, to time.Time) (types.SyncCounts, error) {
+	counts := types.SyncCounts{}
+
+	parts := strings.Split(repoConfig.Name, "/")
+	if len(parts) != 2 {
+		return counts, fmt.Errorf("invalid repository format: %s. Expected format: owner/repo", repoConfig.Name)
+	}
+	owner, repoName := parts[0], parts[1]
+
+	token, err := p.getToken(ctx, config)
+	if err != nil {
+		return counts, err
+	}
+
+	repositoryID, err := p.syncRepository(ctx, config, token, owner, repoName)
+	if err != nil {
+		return counts, err
+	}
+
+	numbers, err := p.searchPullRequests(ctx, owner, repoName, token, from, to)
+	if err != nil {
+		return counts, fmt.Errorf("failed to search pull requests for %s: %w", repoConfig.Name, err)
+	}
+
+	attributor := p.getAttributor(ctx, config.OrganizationID)
+	codeOwners := p.newCodeOwnersResolver(ctx, owner, repoName, token)
+	repoConfig.MaxLookbackDays = 0
+	now := time.Now()
+
+	for _, number := range numbers {
+		pr, err := p.githubClient.GetPullRequest(ctx, owner, repoName, token, number)
+		if err != nil {
+			return counts, fmt.Errorf("failed to fetch pull request %d: %w", number, err)
+		}
+
+		// Ignore PRs excluded by the repository sync rules
+		if !repoConfig.ShouldSync(syncRulesPullRequest(pr), now) {
+			continue
+		}
+
+		importedPRs, err := p.sourceControlAPI.GetPullRequests(ctx, &internaltypes.PullRequestParams{
+			ProviderIDs: []string{fmt.Sprintf("%d", pr.ID)},
+		})
+		if err != nil {
+			return counts, fmt.Errorf("failed to fetch imported pull request %d: %w", number, err)
+		}
+
+		// Skip closed PRs which didn't change since a sync imported them
+		var existing *internaltypes.PullRequest
+		if len(importedPRs) > 0 {
+			existing = importedPRs[0]
+			if existing.Status != "open" && existing.Status == pr.State && !pr.UpdatedAt.After(existing.LastUpdatedAt) {
+				continue
+			}
+		}
+
+		// The pull request is fetched again with its reviews and comments, the ETag cache serves it without
+		// counting against the rate limit
+		details, err := p.fetchPullRequestDetails(ctx, owner, repoName, token, number)
+		if err != nil {
+			return counts, err
+		}
+
+		if err := p.syncPullRequest(ctx, config, token, owner, repoName, repositoryID, details, existing, attributor, codeOwners, &counts); err != nil {
+			return counts, err
+		}
+	}
+
+	return counts, nil
+}
+
+// searchPullRequests returns the numbers of the pull requests created in the range, splitting it in
+// halves until every part fits in the results of a search
+func (p *GitHubProvider) searchPullRequests(ctx context.Context, owner, repoName, token string, from, to time.Time) ([]int, error) {
+	numbers, truncated, err := p.githubClient.SearchPullRequestNumbers(ctx, owner, repoName, token, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if !truncated {
+		return numbers, nil
+	}
+
+	// Searches have a second precision
+	middle := from.Add(to.Sub(from) / 2).Truncate(time.Second)
+	if !middle.After(from) {
+		return nil, fmt.Errorf("too many pull requests created at %s to search them", from.Format(time.RFC3339))
+	}
+
+	older, err := p.searchPullRequests(ctx, owner, repoName, token, from, middle)
+	if err != nil {
+		return nil, err
+	}
+	newer, err := p.searchPullRequests(ctx, owner, repoName, token, middle, to)
+	if err != nil {
+		return nil, err
+	}
+	return append(older, newer...), nil
+}
@@ -0,0 +1,200 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	githubtypes "ems.dev/backend/libraries/github/types"
+	"ems.dev/backend/services/integration/types"
+	internaltypes "ems.dev/backend/services/sourcecontrol/types"
+)
+
+// ciRunStatusWindow is how long before the last sync workflow runs are fetched again, runs which were in progress
+// complete or are re-run after they are created
+const ciRunStatusWindow = 24 * time.Hour
+
+// githubActionsApp is the app of the check runs created by GitHub Actions jobs, they are synced as workflow runs
+const githubActionsApp = "github-actions"
+
+// syncWorkflowRuns saves the GitHub Actions workflow runs of a repository created since the given time, the most
+// recent ones if since is nil. Runs are linked to the imported PRs they ran for, runs of fork PRs have no PR and are
+// skipped. Every attempt of a re-run workflow run is saved.
+func (p *GitHubProvider) syncWorkflowRuns(ctx context.Context, config *types.IntegrationConfig, token, owner, repoName string, since *time.Time) error {
+	if since != nil {
+		windowStart := since.Add(-ciRunStatusWindow)
+		since = &windowStart
+	}
+
+	workflowRuns, err := p.githubClient.GetWorkflowRuns(ctx, owner, repoName, token, since)
+	if err != nil {
+		return fmt.Errorf("failed to fetch workflow runs for %s/%s: %w", owner, repoName, err)
+	}
+
+	// Runs of a PR are listed next to each other, PRs and their saved attempts are only looked up once
+	prs := make(map[int]*internaltypes.PullRequest)
+	savedAttempts := make(map[string]map[ciRunAttempt]bool)
+	runs := []*internaltypes.CIRun{}
+	for _, workflowRun := range workflowRuns {
+		// Earlier attempts are fetched once for all the PRs of the run
+		earlierAttempts := make(map[int]*githubtypes.WorkflowRun)
+
+		for _, runPR := range workflowRun.PullRequests {
+			pr, found := prs[runPR.ID]
+			if !found {
+				if pr, err = p.findPullRequest(ctx, config, repoName, runPR.ID); err != nil {
+					return err
+				}
+				prs[runPR.ID] = pr
+
+				if pr != nil {
+					if savedAttempts[pr.ID], err = p.getSavedWorkflowRunAttempts(ctx, pr); err != nil {
+						return err
+					}
+				}
+			}
+			if pr == nil {
+				continue
+			}
+
+			runs = append(runs, workflowCIRun(pr, workflowRun))
+
+			// The runs list only has the latest attempt of a run, earlier attempts which weren't saved are fetched
+			for attempt := 1; attempt < workflowRun.RunAttempt; attempt++ {
+				if savedAttempts[pr.ID][ciRunAttempt{providerID: fmt.Sprintf("%d", workflowRun.ID), attempt: attempt}] {
+					continue
+				}
+
+				earlierAttempt, fetched := earlierAttempts[attempt]
+				if !fetched {
+					if earlierAttempt, err = p.githubClient.GetWorkflowRunAttempt(ctx, owner, repoName, token, workflowRun.ID, attempt); err != nil {
+						return fmt.Errorf("failed to fetch attempt %d of workflow run %d: %w", attempt, workflowRun.ID, err)
+					}
+					earlierAttempts[attempt] = earlierAttempt
+				}
+
+				runs = append(runs, workflowCIRun(pr, earlierAttempt))
+			}
+		}
+	}
+
+	if err := p.sourceControlAPI.UpsertCIRuns(ctx, runs); err != nil {
+		return fmt.Errorf("failed to save workflow runs for %s/%s: %w", owner, repoName, err)
+	}
+
+	return nil
+}
+
+// ciRunAttempt identifies an attempt of a CI run
+type ciRunAttempt struct {
+	providerID string
+	attempt    int
+}
+
+// getSavedWorkflowRunAttempts returns the completed workflow run attempts saved for a PR, they don't change anymore
+func (p *GitHubProvider) getSavedWorkflowRunAttempts(ctx context.Context, pr *internaltypes.PullRequest) (map[ciRunAttempt]bool, error) {
+	savedRuns, err := p.sourceControlAPI.GetPullRequestCIRuns(ctx, pr.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch CI runs for PR %s: %w", pr.ProviderID, err)
+	}
+
+	attempts := make(map[ciRunAttempt]bool, len(savedRuns))
+	for _, run := range savedRuns {
+		if run.Source == internaltypes.CIRunSourceWorkflowRun && run.Status == "completed" {
+			attempts[ciRunAttempt{providerID: run.ProviderID, attempt: run.Attempt}] = true
+		}
+	}
+	return attempts, nil
+}
+
+// savePullRequestCheckRuns saves the check runs of the head commit of a PR. Check runs of GitHub Actions are
+// skipped, they are saved with their workflow runs. Nothing is saved when the head commit is unknown.
+func (p *GitHubProvider) savePullRequestCheckRuns(ctx context.Context, token, owner, repoName string, pr *internaltypes.PullRequest, headSHA string) error {
+	if headSHA == "" {
+		return nil
+	}
+
+	checkRuns, err := p.githubClient.GetCheckRuns(ctx, owner, repoName, token, headSHA)
+	if err != nil {
+		return fmt.Errorf("failed to fetch check runs for PR %s: %w", pr.ProviderID, err)
+	}
+
+	runs := make([]*internaltypes.CIRun, 0, len(checkRuns))
+	for _, checkRun := range checkRuns {
+		if checkRun.App != nil && checkRun.App.Slug == githubActionsApp {
+			continue
+		}
+
+		runs = append(runs, &internaltypes.CIRun{
+			PRID:         pr.ID,
+			RepositoryID: pr.RepositoryID,
+			ProviderID:   fmt.Sprintf("%d", checkRun.ID),
+			Source:       internaltypes.CIRunSourceCheckRun,
+			Name:         checkRun.Name,
+			HeadSHA:      checkRun.HeadSha,
+			Status:       strings.ToLower(checkRun.Status),
+			Conclusion:   lowerConclusion(checkRun.Conclusion),
+			Attempt:      1,
+			StartedAt:    checkRun.StartedAt,
+			CompletedAt:  checkRun.CompletedAt,
+			CreatedAt:    checkRunCreatedAt(checkRun),
+		})
+	}
+
+	if err := p.sourceControlAPI.UpsertCIRuns(ctx, runs); err != nil {
+		return fmt.Errorf("failed to save check runs for PR %s: %w", pr.ProviderID, err)
+	}
+
+	return nil
+}
+
+// workflowCIRun converts an attempt of a workflow run of a PR. Workflow runs have no completion time, a completed
+// attempt was last updated when it completed.
+func workflowCIRun(pr *internaltypes.PullRequest, workflowRun *githubtypes.WorkflowRun) *internaltypes.CIRun {
+	status := strings.ToLower(workflowRun.Status)
+
+	var completedAt *time.Time
+	if status == "completed" {
+		updatedAt := workflowRun.UpdatedAt
+		completedAt = &updatedAt
+	}
+
+	attempt := workflowRun.RunAttempt
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	return &internaltypes.CIRun{
+		PRID:         pr.ID,
+		RepositoryID: pr.RepositoryID,
+		ProviderID:   fmt.Sprintf("%d", workflowRun.ID),
+		Source:       internaltypes.CIRunSourceWorkflowRun,
+		Name:         workflowRun.Name,
+		HeadSHA:      workflowRun.HeadSha,
+		Status:       status,
+		Conclusion:   lowerConclusion(workflowRun.Conclusion),
+		Attempt:      attempt,
+		StartedAt:    workflowRun.RunStartedAt,
+		CompletedAt:  completedAt,
+		CreatedAt:    workflowRun.CreatedAt,
+	}
+}
+
+// checkRunCreatedAt returns when a check run was created. Check runs only have a start time, queued runs which
+// didn't start yet are dated now.
+func checkRunCreatedAt(checkRun *githubtypes.CheckRun) time.Time {
+	if checkRun.StartedAt != nil {
+		return *checkRun.StartedAt
+	}
+	return time.Now()
+}
+
+// lowerConclusion lowercases the conclusion of a run, runs which didn't complete have none
+func lowerConclusion(conclusion *string) *string {
+	if conclusion == nil {
+		return nil
+	}
+	lowered := strings.ToLower(*conclusion)
+	return &lowered
+}
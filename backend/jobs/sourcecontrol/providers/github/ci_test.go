@@ -0,0 +1,221 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	githubtypes "ems.dev/backend/libraries/github/types"
+	"ems.dev/backend/services/integration/types"
+	internaltypes "ems.dev/backend/services/sourcecontrol/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWorkflowCIRun(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	updatedAt := createdAt.Add(time.Hour)
+	failure := "Failure"
+	repositoryID := "repo-1"
+	pr := &internaltypes.PullRequest{ID: "pr-1", RepositoryID: &repositoryID}
+
+	tests := []struct {
+		name                string
+		workflowRun         *githubtypes.WorkflowRun
+		expectedStatus      string
+		expectedConclusion  *string
+		expectedAttempt     int
+		expectedCompletedAt *time.Time
+	}{
+		{
+			name:            "run in progress",
+			workflowRun:     &githubtypes.WorkflowRun{ID: 1, Status: "in_progress", RunAttempt: 1, CreatedAt: createdAt, UpdatedAt: updatedAt},
+			expectedStatus:  "in_progress",
+			expectedAttempt: 1,
+		},
+		{
+			name:                "completed re-run attempt",
+			workflowRun:         &githubtypes.WorkflowRun{ID: 1, Status: "Completed", Conclusion: &failure, RunAttempt: 2, CreatedAt: createdAt, UpdatedAt: updatedAt},
+			expectedStatus:      "completed",
+			expectedConclusion:  lowerConclusion(&failure),
+			expectedAttempt:     2,
+			expectedCompletedAt: &updatedAt,
+		},
+		{
+			name:            "runs without attempt are the first attempt",
+			workflowRun:     &githubtypes.WorkflowRun{ID: 1, Status: "queued", CreatedAt: createdAt, UpdatedAt: updatedAt},
+			expectedStatus:  "queued",
+			expectedAttempt: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := workflowCIRun(pr, tt.workflowRun)
+
+			assert.Equal(t, "pr-1", run.PRID)
+			assert.Equal(t, &repositoryID, run.RepositoryID)
+			assert.Equal(t, "1", run.ProviderID)
+			assert.Equal(t, internaltypes.CIRunSourceWorkflowRun, run.Source)
+			assert.Equal(t, tt.expectedStatus, run.Status)
+			assert.Equal(t, tt.expectedConclusion, run.Conclusion)
+			assert.Equal(t, tt.expectedAttempt, run.Attempt)
+			assert.Equal(t, tt.expectedCompletedAt, run.CompletedAt)
+			assert.Equal(t, createdAt, run.CreatedAt)
+		})
+	}
+}
+
+func TestSyncWorkflowRuns(t *testing.T) {
+	config := &types.IntegrationConfig{OrganizationID: "org-1"}
+	repositoryID := "repo-1"
+	pr := &internaltypes.PullRequest{ID: "pr-1", ProviderID: "10", RepositoryID: &repositoryID}
+
+	workflowRun := func(id, attempt int, prIDs ...int) *githubtypes.WorkflowRun {
+		run := &githubtypes.WorkflowRun{ID: id, Status: "completed", RunAttempt: attempt}
+		for _, prID := range prIDs {
+			run.PullRequests = append(run.PullRequests, githubtypes.WorkflowRunPullRequest{ID: prID})
+		}
+		return run
+	}
+	savedRun := func(id, attempt int, status string) *internaltypes.CIRun {
+		return &internaltypes.CIRun{ProviderID: fmt.Sprintf("%d", id), Source: internaltypes.CIRunSourceWorkflowRun, Status: status, Attempt: attempt}
+	}
+
+	tests := []struct {
+		name         string
+		workflowRuns []*githubtypes.WorkflowRun
+		savedRuns    []*internaltypes.CIRun
+		attempts     map[int]error // earlier attempts of run 1 which are fetched
+		// expected are the run and attempt of the saved runs
+		expected      []string
+		expectedError string
+	}{
+		{
+			name:         "runs of imported pull requests",
+			workflowRuns: []*githubtypes.WorkflowRun{workflowRun(1, 1, 10), workflowRun(2, 1, 10)},
+			expected:     []string{"1/1", "2/1"},
+		},
+		{
+			name:         "runs of forks and of pull requests which weren't imported are skipped",
+			workflowRuns: []*githubtypes.WorkflowRun{workflowRun(1, 1), workflowRun(2, 1, 20)},
+			expected:     []string{},
+		},
+		{
+			name:         "earlier attempts of re-runs are fetched",
+			workflowRuns: []*githubtypes.WorkflowRun{workflowRun(1, 3, 10)},
+			attempts:     map[int]error{1: nil, 2: nil},
+			expected:     []string{"1/3", "1/1", "1/2"},
+		},
+		{
+			name:         "completed attempts which were saved aren't fetched again",
+			workflowRuns: []*githubtypes.WorkflowRun{workflowRun(1, 3, 10)},
+			savedRuns:    []*internaltypes.CIRun{savedRun(1, 1, "completed"), savedRun(1, 2, "in_progress"), savedRun(2, 2, "completed")},
+			attempts:     map[int]error{2: nil},
+			expected:     []string{"1/3", "1/2"},
+		},
+		{
+			name:          "failed attempt fetches fail the sync",
+			workflowRuns:  []*githubtypes.WorkflowRun{workflowRun(1, 2, 10)},
+			attempts:      map[int]error{1: errors.New("not found")},
+			expectedError: "failed to fetch attempt 1 of workflow run 1: not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			githubClient := new(MockGithubClient)
+			sourceControlAPI := new(MockSourceControlAPI)
+			provider := &GitHubProvider{githubClient: githubClient, sourceControlAPI: sourceControlAPI}
+
+			githubClient.On("GetWorkflowRuns", ctx, "owner", "repo", "token", (*time.Time)(nil)).Return(tt.workflowRuns, nil)
+			for attempt, err := range tt.attempts {
+				if err != nil {
+					githubClient.On("GetWorkflowRunAttempt", ctx, "owner", "repo", "token", 1, attempt).Return(nil, err).Once()
+					continue
+				}
+				githubClient.On("GetWorkflowRunAttempt", ctx, "owner", "repo", "token", 1, attempt).Return(workflowRun(1, attempt, 10), nil).Once()
+			}
+			sourceControlAPI.On("GetPullRequests", ctx, mock.MatchedBy(func(params *internaltypes.PullRequestParams) bool {
+				return params.ProviderIDs[0] == "10"
+			})).Return([]*internaltypes.PullRequest{pr}, nil).Once()
+			sourceControlAPI.On("GetPullRequests", ctx, mock.Anything).Return([]*internaltypes.PullRequest{}, nil)
+			sourceControlAPI.On("GetPullRequestCIRuns", ctx, "pr-1").Return(tt.savedRuns, nil).Once()
+
+			var saved []string
+			sourceControlAPI.On("UpsertCIRuns", ctx, mock.Anything).Run(func(args mock.Arguments) {
+				saved = []string{}
+				for _, run := range args.Get(1).([]*internaltypes.CIRun) {
+					assert.Equal(t, "pr-1", run.PRID)
+					assert.Equal(t, &repositoryID, run.RepositoryID)
+					saved = append(saved, fmt.Sprintf("%s/%d", run.ProviderID, run.Attempt))
+				}
+			}).Return(nil)
+
+			err := provider.syncWorkflowRuns(ctx, config, "token", "owner", "repo", nil)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, saved)
+			githubClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSavePullRequestCheckRuns(t *testing.T) {
+	repositoryID := "repo-1"
+	pr := &internaltypes.PullRequest{ID: "pr-1", RepositoryID: &repositoryID}
+
+	tests := []struct {
+		name      string
+		headSHA   string
+		checkRuns []*githubtypes.CheckRun
+		// expected are the provider IDs of the saved runs, nil when nothing is saved
+		expected []string
+	}{
+		{
+			name:    "check runs of other CI providers are saved",
+			headSHA: "abc",
+			checkRuns: []*githubtypes.CheckRun{
+				{ID: 1, Status: "completed"},
+				{ID: 2, Status: "completed", App: &githubtypes.CheckRunApp{Slug: githubActionsApp}},
+			},
+			expected: []string{"1"},
+		},
+		{
+			name:    "check runs aren't fetched without head commit",
+			headSHA: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			githubClient := new(MockGithubClient)
+			sourceControlAPI := new(MockSourceControlAPI)
+			provider := &GitHubProvider{githubClient: githubClient, sourceControlAPI: sourceControlAPI}
+
+			if tt.headSHA != "" {
+				githubClient.On("GetCheckRuns", ctx, "owner", "repo", "token", tt.headSHA).Return(tt.checkRuns, nil).Once()
+			}
+			var saved []string
+			sourceControlAPI.On("UpsertCIRuns", ctx, mock.Anything).Run(func(args mock.Arguments) {
+				saved = []string{}
+				for _, run := range args.Get(1).([]*internaltypes.CIRun) {
+					assert.Equal(t, &repositoryID, run.RepositoryID)
+					saved = append(saved, run.ProviderID)
+				}
+			}).Return(nil)
+
+			err := provider.savePullRequestCheckRuns(ctx, "token", "owner", "repo", pr, tt.headSHA)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, saved)
+			githubClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"ems.dev/backend/libraries/github"
+	githubtypes "ems.dev/backend/libraries/github/types"
+	"ems.dev/backend/services/integration/types"
+	internaltypes "ems.dev/backend/services/sourcecontrol/types"
+)
+
+// codeOwnersResolver finds the code owners of the pull requests of a repository. Team owners are resolved to
+// their members once per sync of the repository.
+type codeOwnersResolver struct {
+	githubClient github.GithubClient
+	token        string
+	codeOwners   *github.CodeOwners
+	// teamMembers are the logins of the members of @org/team owners, nil when they can't be read
+	teamMembers map[string]map[string]bool
+}
+
+// newCodeOwnersResolver fetches the CODEOWNERS file of a repository. Returns nil if the repository has none.
+func (p *GitHubProvider) newCodeOwnersResolver(ctx context.Context, owner, repoName, token string) *codeOwnersResolver {
+	codeOwners, err := p.githubClient.GetCodeOwners(ctx, owner, repoName, token)
+	if err != nil {
+		// Log error but don't fail - code owners are optional
+		fmt.Printf("Warning: failed to fetch CODEOWNERS of %s/%s: %v\n", owner, repoName, err)
+		return nil
+	}
+	if codeOwners == nil {
+		return nil
+	}
+
+	return &codeOwnersResolver{
+		githubClient: p.githubClient,
+		token:        token,
+		codeOwners:   codeOwners,
+		teamMembers:  make(map[string]map[string]bool),
+	}
+}
+
+// members returns the logins an owner stands for, lowercased. Returns nil for owners which can't be matched
+// with reviewers: emails, and teams whose members the token can't read.
+func (r *codeOwnersResolver) members(ctx context.Context, owner string) map[string]bool {
+	if !strings.HasPrefix(owner, "@") {
+		return nil
+	}
+
+	handle := strings.ToLower(strings.TrimPrefix(owner, "@"))
+	org, team, isTeam := strings.Cut(handle, "/")
+	if !isTeam {
+		return map[string]bool{handle: true}
+	}
+
+	if members, resolved := r.teamMembers[handle]; resolved {
+		return members
+	}
+
+	users, err := r.githubClient.GetTeamMembers(ctx, org, team, r.token)
+	if err != nil {
+		// Reading team members needs the organization members permission
+		fmt.Printf("Warning: failed to fetch members of team %s: %v\n", owner, err)
+		r.teamMembers[handle] = nil
+		return nil
+	}
+
+	members := make(map[string]bool, len(users))
+	for _, user := range users {
+		members[strings.ToLower(user.Login)] = true
+	}
+	r.teamMembers[handle] = members
+	return members
+}
+
+// prCodeOwners returns the owners of the files changed by a PR and whether they reviewed it. An owner reviewed
+// the PR when the owner, or a member of the owning team, submitted a review other than the PR author.
+func (r *codeOwnersResolver) prCodeOwners(ctx context.Context, pr *githubtypes.PullRequest, files []*githubtypes.PullRequestFile, reviews []*githubtypes.Review) ([]*internaltypes.PRCodeOwner, map[string]*githubtypes.Review) {
+	ownedFiles := make(map[string]int)
+	for _, file := range files {
+		for _, owner := range r.codeOwners.Owners(file.Filename) {
+			ownedFiles[owner]++
+		}
+	}
+
+	// Earliest reviews first, so that the first owner review is attributed
+	submitted := make([]*githubtypes.Review, 0, len(reviews))
+	for _, review := range reviews {
+		if strings.EqualFold(review.State, "PENDING") || review.User.Type == "Bot" || strings.EqualFold(review.User.Login, pr.User.Login) {
+			continue
+		}
+		submitted = append(submitted, review)
+	}
+	sort.SliceStable(submitted, func(i, j int) bool {
+		return submitted[i].SubmittedAt.Before(submitted[j].SubmittedAt)
+	})
+
+	owners := make([]*internaltypes.PRCodeOwner, 0, len(ownedFiles))
+	ownerReviews := make(map[string]*githubtypes.Review)
+	for owner, count := range ownedFiles {
+		members := r.members(ctx, owner)
+		if members == nil {
+			continue
+		}
+
+		prCodeOwner := &internaltypes.PRCodeOwner{
+			Owner: owner,
+			Files: count,
+		}
+		for _, review := range submitted {
+			if members[strings.ToLower(review.User.Login)] {
+				prCodeOwner.Reviewed = true
+				ownerReviews[owner] = review
+				break
+			}
+		}
+		owners = append(owners, prCodeOwner)
+	}
+
+	return owners, ownerReviews
+}
+
+// savePullRequestCodeOwners replaces the code owners of a PR, linking every owner to the account of its first
+// review
+func (p *GitHubProvider) savePullRequestCodeOwners(ctx context.Context, config *types.IntegrationConfig, resolver *codeOwnersResolver, pr *internaltypes.PullRequest, details *githubtypes.PullRequestDetails) error {
+	owners, ownerReviews := resolver.prCodeOwners(ctx, details.PullRequest, details.Files, details.Reviews)
+	for _, owner := range owners {
+		owner.PRID = pr.ID
+
+		review, reviewed := ownerReviews[owner.Owner]
+		if !reviewed {
+			continue
+		}
+
+		reviewer, err := p.upsertAuthor(ctx, config.OrganizationID, review.User)
+		if err != nil {
+			return fmt.Errorf("failed to upsert code owner reviewer for PR %s: %w", pr.ProviderID, err)
+		}
+		owner.ReviewedBy = &reviewer.ID
+	}
+
+	if err := p.sourceControlAPI.ReplacePRCodeOwners(ctx, pr.ID, owners); err != nil {
+		return fmt.Errorf("failed to save code owners for PR %s: %w", pr.ProviderID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	githubtypes "ems.dev/backend/libraries/github/types"
+	"ems.dev/backend/services/integration/types"
+	internaltypes "ems.dev/backend/services/sourcecontrol/types"
+)
+
+// savePullRequestCommits saves the commits of a PR which weren't saved yet. Commit
+// authors are linked to their GitHub account when their email is linked to one.
+func (p *GitHubProvider) savePullRequestCommits(ctx context.Context, config *types.IntegrationConfig, pr *internaltypes.PullRequest, commits []*githubtypes.Commit) error {
+	prCommits := make([]*internaltypes.PRCommit, 0, len(commits))
+	for _, commit := range commits {
+		prCommit := &internaltypes.PRCommit{
+			PRID:           pr.ID,
+			SHA:            commit.Sha,
+			Message:        commit.Commit.Message,
+			AuthorName:     commit.Commit.Author.Name,
+			AuthorEmail:    commit.Commit.Author.Email,
+			AuthoredAt:     commit.Commit.Author.Date,
+			CommitterName:  commit.Commit.Committer.Name,
+			CommitterEmail: commit.Commit.Committer.Email,
+			CommittedAt:    commit.Commit.Committer.Date,
+		}
+		if commit.Stats != nil {
+			prCommit.Additions = commit.Stats.Additions
+			prCommit.Deletions = commit.Stats.Deletions
+		}
+
+		if commit.Author != nil && commit.Author.Login != "" {
+			author, err := p.upsertAuthor(ctx, config.OrganizationID, *commit.Author)
+			if err != nil {
+				return fmt.Errorf("failed to upsert author of commit %s for PR %s: %w", commit.Sha, pr.ProviderID, err)
+			}
+			prCommit.ExternalAccountID = &author.ID
+		}
+
+		prCommits = append(prCommits, prCommit)
+	}
+
+	if err := p.sourceControlAPI.CreatePRCommits(ctx, prCommits); err != nil {
+		return fmt.Errorf("failed to save commits for PR %s: %w", pr.ProviderID, err)
+	}
+
+	return nil
+}
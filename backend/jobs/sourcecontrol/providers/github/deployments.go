@@ -0,0 +1,159 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	githubtypes "ems.dev/backend/libraries/github/types"
+	"ems.dev/backend/services/integration/types"
+	internaltypes "ems.dev/backend/services/sourcecontrol/types"
+)
+
+// deploymentStatusWindow is how long before the last sync deployments are fetched again, their statuses keep
+// changing after they are created
+const deploymentStatusWindow = 24 * time.Hour
+
+// syncDeployments saves the deployments and releases of a repository created since the given time, all of them if
+// since is nil
+func (p *GitHubProvider) syncDeployments(ctx context.Context, config *types.IntegrationConfig, token, owner, repoName string, repositoryID *string, since *time.Time) error {
+	if since != nil {
+		windowStart := since.Add(-deploymentStatusWindow)
+		since = &windowStart
+	}
+
+	deployments, err := p.githubClient.GetDeployments(ctx, owner, repoName, token, since)
+	if err != nil {
+		return fmt.Errorf("failed to fetch deployments for %s/%s: %w", owner, repoName, err)
+	}
+
+	for _, deployment := range deployments {
+		if err := p.saveDeployment(ctx, config, token, owner, repoName, repositoryID, deployment); err != nil {
+			return err
+		}
+	}
+
+	releases, err := p.githubClient.GetReleases(ctx, owner, repoName, token, since)
+	if err != nil {
+		return fmt.Errorf("failed to fetch releases for %s/%s: %w", owner, repoName, err)
+	}
+
+	for _, release := range releases {
+		if err := p.saveRelease(ctx, config, repoName, repositoryID, release); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveDeployment saves a deployment with its statuses. The status of the deployment is its latest final status,
+// older deployments are marked inactive when a newer one succeeds.
+func (p *GitHubProvider) saveDeployment(ctx context.Context, config *types.IntegrationConfig, token, owner, repoName string, repositoryID *string, deployment *githubtypes.Deployment) error {
+	statuses, err := p.githubClient.GetDeploymentStatuses(ctx, owner, repoName, token, deployment.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch statuses of deployment %d: %w", deployment.ID, err)
+	}
+
+	sourceControlDeployment := &internaltypes.Deployment{
+		OrganizationID: config.OrganizationID,
+		RepositoryName: repoName,
+		RepositoryID:   repositoryID,
+		ProviderID:     fmt.Sprintf("%d", deployment.ID),
+		Source:         internaltypes.DeploymentSourceDeployment,
+		Environment:    deployment.Environment,
+		Production:     isProductionEnvironment(deployment),
+		SHA:            deployment.Sha,
+		Ref:            deployment.Ref,
+		Status:         internaltypes.DeploymentStatusPending,
+		CreatedAt:      deployment.CreatedAt,
+	}
+
+	// Statuses are listed newest first
+	for _, status := range statuses {
+		state := strings.ToLower(status.State)
+		if state == internaltypes.DeploymentStatusSuccess || state == internaltypes.DeploymentStatusFailure || state == internaltypes.DeploymentStatusError {
+			finishedAt := status.CreatedAt
+			sourceControlDeployment.Status = state
+			sourceControlDeployment.FinishedAt = &finishedAt
+			break
+		}
+	}
+
+	if deployment.Creator != nil && deployment.Creator.Login != "" {
+		creator, err := p.upsertAuthor(ctx, config.OrganizationID, *deployment.Creator)
+		if err != nil {
+			return fmt.Errorf("failed to upsert creator of deployment %d: %w", deployment.ID, err)
+		}
+		sourceControlDeployment.ExternalAccountID = &creator.ID
+	}
+
+	if err := p.sourceControlAPI.UpsertDeployment(ctx, sourceControlDeployment); err != nil {
+		return fmt.Errorf("failed to save deployment %d: %w", deployment.ID, err)
+	}
+
+	deploymentStatuses := make([]*internaltypes.DeploymentStatus, 0, len(statuses))
+	for _, status := range statuses {
+		deploymentStatuses = append(deploymentStatuses, &internaltypes.DeploymentStatus{
+			DeploymentID: sourceControlDeployment.ID,
+			ProviderID:   fmt.Sprintf("%d", status.ID),
+			State:        strings.ToLower(status.State),
+			Description:  status.Description,
+			CreatedAt:    status.CreatedAt,
+		})
+	}
+
+	if err := p.sourceControlAPI.CreateDeploymentStatuses(ctx, deploymentStatuses); err != nil {
+		return fmt.Errorf("failed to save statuses of deployment %d: %w", deployment.ID, err)
+	}
+
+	return nil
+}
+
+// saveRelease saves a published release as a successful production deployment, for repositories which ship
+// through releases instead of deployments. Drafts and pre-releases are skipped.
+func (p *GitHubProvider) saveRelease(ctx context.Context, config *types.IntegrationConfig, repoName string, repositoryID *string, release *githubtypes.Release) error {
+	if release.Draft || release.Prerelease || release.PublishedAt == nil {
+		return nil
+	}
+
+	deployment := &internaltypes.Deployment{
+		OrganizationID: config.OrganizationID,
+		RepositoryName: repoName,
+		RepositoryID:   repositoryID,
+		ProviderID:     fmt.Sprintf("%d", release.ID),
+		Source:         internaltypes.DeploymentSourceRelease,
+		Environment:    "production",
+		Production:     true,
+		Ref:            release.TagName,
+		Status:         internaltypes.DeploymentStatusSuccess,
+		CreatedAt:      *release.PublishedAt,
+		FinishedAt:     release.PublishedAt,
+	}
+
+	if release.Author != nil && release.Author.Login != "" {
+		author, err := p.upsertAuthor(ctx, config.OrganizationID, *release.Author)
+		if err != nil {
+			return fmt.Errorf("failed to upsert author of release %d: %w", release.ID, err)
+		}
+		deployment.ExternalAccountID = &author.ID
+	}
+
+	if err := p.sourceControlAPI.UpsertDeployment(ctx, deployment); err != nil {
+		return fmt.Errorf("failed to save release %d: %w", release.ID, err)
+	}
+
+	return nil
+}
+
+// isProductionEnvironment reports whether a deployment targets production. GitHub only sets
+// production_environment when the deployment creator specifies it, so environments named production count too.
+func isProductionEnvironment(deployment *githubtypes.Deployment) bool {
+	if deployment.ProductionEnvironment {
+		return true
+	}
+
+	environment := strings.ToLower(deployment.Environment)
+	return environment == "production" || environment == "prod"
+}
@@ -0,0 +1,109 @@
+package github
+
+import (
+	"context"
+	"time"
+
+	"ems.dev/backend/libraries/github"
+	githubtypes "ems.dev/backend/libraries/github/types"
+	memberapi "ems.dev/backend/services/member/api"
+	membertypes "ems.dev/backend/services/member/types"
+	sourcecontrolapi "ems.dev/backend/services/sourcecontrol/api"
+	internaltypes "ems.dev/backend/services/sourcecontrol/types"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockGithubClient is a mock of the GitHub client, only the methods used by the tested syncs are implemented
+type MockGithubClient struct {
+	github.GithubClient
+	mock.Mock
+}
+
+func (m *MockGithubClient) GetWorkflowRuns(ctx context.Context, owner, repo, token string, since *time.Time) ([]*githubtypes.WorkflowRun, error) {
+	args := m.Called(ctx, owner, repo, token, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*githubtypes.WorkflowRun), args.Error(1)
+}
+
+func (m *MockGithubClient) GetWorkflowRunAttempt(ctx context.Context, owner, repo, token string, runID, attempt int) (*githubtypes.WorkflowRun, error) {
+	args := m.Called(ctx, owner, repo, token, runID, attempt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*githubtypes.WorkflowRun), args.Error(1)
+}
+
+func (m *MockGithubClient) GetCheckRuns(ctx context.Context, owner, repo, token, ref string) ([]*githubtypes.CheckRun, error) {
+	args := m.Called(ctx, owner, repo, token, ref)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*githubtypes.CheckRun), args.Error(1)
+}
+
+// MockSourceControlAPI is a mock of the source control API, only the methods used by the tested syncs are
+// implemented
+type MockSourceControlAPI struct {
+	sourcecontrolapi.SourceControlAPI
+	mock.Mock
+}
+
+func (m *MockSourceControlAPI) GetPullRequests(ctx context.Context, params *internaltypes.PullRequestParams) ([]*internaltypes.PullRequest, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*internaltypes.PullRequest), args.Error(1)
+}
+
+func (m *MockSourceControlAPI) GetPullRequestCIRuns(ctx context.Context, prID string) ([]*internaltypes.CIRun, error) {
+	args := m.Called(ctx, prID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*internaltypes.CIRun), args.Error(1)
+}
+
+func (m *MockSourceControlAPI) UpsertCIRuns(ctx context.Context, runs []*internaltypes.CIRun) error {
+	args := m.Called(ctx, runs)
+	return args.Error(0)
+}
+
+func (m *MockSourceControlAPI) GetPullRequestComments(ctx context.Context, prID string) ([]*internaltypes.PRComment, error) {
+	args := m.Called(ctx, prID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*internaltypes.PRComment), args.Error(1)
+}
+
+func (m *MockSourceControlAPI) UpdatePRComment(ctx context.Context, comment *internaltypes.PRComment) error {
+	args := m.Called(ctx, comment)
+	return args.Error(0)
+}
+
+func (m *MockSourceControlAPI) RefreshPullRequestMetrics(ctx context.Context, pr *internaltypes.PullRequest) error {
+	args := m.Called(ctx, pr)
+	return args.Error(0)
+}
+
+// MockMemberAPI is a mock of the member API, only the methods used by the tested syncs are implemented
+type MockMemberAPI struct {
+	memberapi.MemberAPI
+	mock.Mock
+}
+
+func (m *MockMemberAPI) GetExternalAccounts(ctx context.Context, params *membertypes.ExternalAccountParams) ([]membertypes.ExternalAccount, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]membertypes.ExternalAccount), args.Error(1)
+}
+
+func (m *MockMemberAPI) CreateExternalAccounts(ctx context.Context, accounts []*membertypes.ExternalAccount) error {
+	args := m.Called(ctx, accounts)
+	return args.Error(0)
+}
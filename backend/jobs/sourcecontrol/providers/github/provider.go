@@ -4,8 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"ems.dev/backend/libraries/github"
@@ -28,6 +28,9 @@ type GitHubProvider struct {
 	sourceControlAPI sourcecontrolapi.SourceControlAPI
 	memberAPI        memberapi.MemberAPI
 	teamAPI          teamapi.TeamAPI
+
+	// authorMu serializes author upserts, repositories are synced concurrently and share their authors
+	authorMu sync.Mutex
 }
 
 func NewProvider(
@@ -51,83 +54,65 @@ func (p *GitHubProvider) Name() string {
 }
 
 // TOOD: Need to extract this logic to sync.go. This should be provide agnostic and instead is using the github client directly.
-func (p *GitHubProvider) SyncRepositories(ctx context.Context, config *types.IntegrationConfig, repositories []string) error {
-	// Decrypt the token
-	token, err := p.integrationAPI.DecryptToken(config.EncryptedToken)
-	if err != nil {
-		return fmt.Errorf("failed to decrypt token: %w", err)
-	}
+func (p *GitHubProvider) SyncRepositories(ctx context.Context, config *types.IntegrationConfig, repositories []types.RepositoryConfig) (types.SyncCounts, error) {
+	counts := types.SyncCounts{}
 
-	// Get all teams for the organization and extract their PR prefixes
-	teams, err := p.teamAPI.ListTeams(ctx, teamtypes.TeamSearchParams{
-		OrganizationID: &config.OrganizationID,
-	})
-	if err != nil {
-		// Log error but don't fail - prefix matching is optional
-		fmt.Printf("Warning: failed to fetch teams for prefix matching: %v\n", err)
-	}
+	// Get the rules attributing the PRs to the organization's teams
+	attributor := p.getAttributor(ctx, config.OrganizationID)
 
-	// Extract prefixes from teams (filter out empty/null prefixes)
-	prefixes := []string{}
-	for _, team := range teams {
-		if team.PRPrefix != nil && *team.PRPrefix != "" {
-			prefixes = append(prefixes, *team.PRPrefix)
-		}
+	fetchMode, err := types.ParseGithubFetchMode(config.Metadata)
+	if err != nil {
+		return counts, err
 	}
 
-	// Helper function to find matching prefix for a PR title
-	// Matches if title starts with prefix followed by a hyphen (e.g., "WL-123: Fix bug")
-	findPrefixForTitle := func(title string) *string {
-		upperTitle := strings.ToUpper(title)
-		for _, prefix := range prefixes {
-			prefixWithHyphen := strings.ToUpper(prefix) + "-"
-			// Check if title starts with the prefix followed by a hyphen (case-insensitive)
-			if strings.HasPrefix(upperTitle, prefixWithHyphen) {
-				return &prefix
-			}
-		}
-		return nil
-	}
+	// Process each repository
+	for _, repoConfig := range repositories {
+		repo := repoConfig.Name
 
-	// Helper function to find matching prefix in commit messages
-	// Matches if commit message starts with prefix followed by a hyphen (e.g., "WL-123: Fix bug")
-	findPrefixInCommits := func(commits []*githubtypes.Commit) *string {
-		for _, commit := range commits {
-			commitMessage := strings.TrimSpace(commit.Commit.Message)
-			// Check first line of commit message (commit messages can be multi-line)
-			firstLine := strings.Split(commitMessage, "\n")[0]
-			upperFirstLine := strings.ToUpper(firstLine)
-
-			for _, prefix := range prefixes {
-				prefixWithHyphen := strings.ToUpper(prefix) + "-"
-				// Check if commit message starts with the prefix followed by a hyphen (case-insensitive)
-				if strings.HasPrefix(upperFirstLine, prefixWithHyphen) {
-					return &prefix
-				}
-			}
+		// Fetched per repository, GitHub App installation tokens expire after an hour
+		token, err := p.getToken(ctx, config)
+		if err != nil {
+			return counts, err
 		}
-		return nil
-	}
 
-	// Process each repository
-	for _, repo := range repositories {
 		parts := strings.Split(repo, "/")
 		if len(parts) != 2 {
-			return fmt.Errorf("invalid repository format: %s. Expected format: owner/repo", repo)
+			return counts, fmt.Errorf("invalid repository format: %s. Expected format: owner/repo", repo)
 		}
 
 		owner, repoName := parts[0], parts[1]
+		codeOwners := p.newCodeOwnersResolver(ctx, owner, repoName, token)
+
+		repositoryID, err := p.syncRepository(ctx, config, token, owner, repoName)
+		if err != nil {
+			return counts, err
+		}
 
-		// 1. Fetch all PRs from GitHub
-		// TODO: This is really inefficient, better to filter by date and try to backfill little by little
-		prs, err := p.githubClient.GetPullRequests(ctx, owner, repoName, token, 20)
+		// 1. Fetch the PRs updated since the last sync of the repository
+		cursor, err := p.integrationAPI.GetRepositorySyncCursor(ctx, config.ID, repo)
 		if err != nil {
-			return fmt.Errorf("failed to fetch pull requests for %s: %w", repo, err)
+			return counts, fmt.Errorf("failed to fetch sync cursor for %s: %w", repo, err)
+		}
+
+		var since *time.Time
+		if cursor != nil {
+			since = &cursor.LastUpdatedAt
+		}
+
+		// PRs older than the max lookback are never synced, so there is no need to fetch them
+		now := time.Now()
+		if lookbackStart := repoConfig.LookbackStart(now); lookbackStart != nil && (since == nil || lookbackStart.After(*since)) {
+			since = lookbackStart
+		}
+
+		prs, err := p.fetchPullRequests(ctx, fetchMode, owner, repoName, token, since)
+		if err != nil {
+			return counts, fmt.Errorf("failed to fetch pull requests for %s: %w", repo, err)
 		}
 
 		prIds := []string{}
 		for _, pr := range prs {
-			prIds = append(prIds, fmt.Sprintf("%d", pr.ID))
+			prIds = append(prIds, fmt.Sprintf("%d", pr.PullRequest.ID))
 		}
 
 		// 2. Get all PRs from the database
@@ -136,7 +121,7 @@ func (p *GitHubProvider) SyncRepositories(ctx context.Context, config *types.Int
 			ProviderIDs: prIds,
 		})
 		if err != nil {
-			return fmt.Errorf("failed to fetch imported pull requests for %s: %w", repo, err)
+			return counts, fmt.Errorf("failed to fetch imported pull requests for %s: %w", repo, err)
 		}
 
 		// Create a map of imported PRs for quick lookup
@@ -147,251 +132,464 @@ func (p *GitHubProvider) SyncRepositories(ctx context.Context, config *types.Int
 
 		// 3. Process each PR
 		for _, pr := range prs {
-			// TODO: This should come from the integrations config. It should give the user the possibility to add settings to each repo he wants to import. (ex. Exclude PRs to prod branch)
-			// Ignore PRs who's base ref is prod
-			if pr.Base.Ref == "prod" {
+			// Ignore PRs excluded by the repository sync rules
+			if !repoConfig.ShouldSync(syncRulesPullRequest(pr.PullRequest), now) {
 				continue
 			}
 
-			// Check if PR exists in DB and skip if not open
-			existingPR, exists := importedPRsMap[fmt.Sprintf("%d", pr.ID)]
+			// Check if PR exists in DB and skip closed PRs which didn't change since they were imported
+			existingPR, exists := importedPRsMap[fmt.Sprintf("%d", pr.PullRequest.ID)]
 
 			if exists {
-				if existingPR.Status != "open" && existingPR.Status == pr.State {
+				if existingPR.Status != "open" && existingPR.Status == pr.PullRequest.State && !pr.PullRequest.UpdatedAt.After(existingPR.LastUpdatedAt) {
 					continue
 				}
 			}
 
-			// Get detailed PR information
-			prDetails, err := p.githubClient.GetPullRequest(ctx, owner, repoName, token, pr.Number)
-			if err != nil {
-				return fmt.Errorf("failed to fetch pull request details for PR %d: %w", pr.Number, err)
+			// The REST list only has a summary of each PR, its details are fetched one by one
+			details := pr
+			if fetchMode == types.GithubFetchModeREST {
+				if details, err = p.fetchPullRequestDetails(ctx, owner, repoName, token, pr.PullRequest.Number); err != nil {
+					return counts, err
+				}
 			}
 
-			// 4. Insert/Update author
-			authorAccount, err := p.upsertAuthor(ctx, config.OrganizationID, prDetails.User)
-			if err != nil {
-				return fmt.Errorf("failed to upsert author for PR %d: %w", pr.Number, err)
+			var existing *internaltypes.PullRequest
+			if exists {
+				existing = &existingPR
 			}
 
-			// 5. Insert/Update PR
-			prDetailsBytes, _ := json.Marshal(prDetails)
-
-			// Match PR title with team prefixes
-			matchedPrefix := findPrefixForTitle(prDetails.Title)
+			if err := p.syncPullRequest(ctx, config, token, owner, repoName, repositoryID, details, existing, attributor, codeOwners, &counts); err != nil {
+				return counts, err
+			}
+		}
 
-			// If no prefix found in title, try to derive it from commits
-			if matchedPrefix == nil {
-				commits, err := p.githubClient.GetPullRequestCommits(ctx, owner, repoName, token, pr.Number)
-				if err != nil {
-					// Log error but don't fail - commit fetching is optional
-					fmt.Printf("Warning: failed to fetch commits for PR %d: %v\n", pr.Number, err)
-				} else {
-					matchedPrefix = findPrefixInCommits(commits)
-				}
+		// 4. Save the deployments and releases, tokens without access to them still sync pull requests
+		if err := p.syncDeployments(ctx, config, token, owner, repoName, repositoryID, since); err != nil {
+			if _, rateLimited := github.IsRateLimitError(err); rateLimited {
+				return counts, err
 			}
+			fmt.Printf("Warning: failed to sync deployments for %s: %v\n", repo, err)
+		}
 
-			sourceControlPR := &internaltypes.PullRequest{
-				ExternalAccountID: authorAccount.ID,
-				ProviderID:        fmt.Sprintf("%d", prDetails.ID),
-				RepositoryName:    repoName,
-				Title:             prDetails.Title,
-				Description:       prDetails.Body,
-				Status:            prDetails.State,
-				CreatedAt:         prDetails.CreatedAt,
-				MergedAt:          prDetails.MergedAt,
-				LastUpdatedAt:     prDetails.UpdatedAt,
-				Comments:          prDetails.Comments,
-				ReviewComments:    prDetails.ReviewComments,
-				Additions:         prDetails.Additions,
-				Deletions:         prDetails.Deletions,
-				ChangedFiles:      prDetails.ChangedFiles,
-				URL:               prDetails.URL,
-				Prefix:            matchedPrefix,
-				Metadata:          datatypes.JSON(prDetailsBytes),
+		// 5. Save the workflow runs of the imported PRs, tokens without access to Actions still sync pull requests
+		if err := p.syncWorkflowRuns(ctx, config, token, owner, repoName, since); err != nil {
+			if _, rateLimited := github.IsRateLimitError(err); rateLimited {
+				return counts, err
 			}
+			fmt.Printf("Warning: failed to sync workflow runs for %s: %v\n", repo, err)
+		}
 
-			if exists {
-				sourceControlPR.ID = existingPR.ID
-				err := p.sourceControlAPI.UpdatePullRequest(ctx, sourceControlPR)
-				if err != nil {
-					return fmt.Errorf("failed to update pull request %d: %w", pr.Number, err)
+		// 6. Move the repository cursor to the most recent update that was synced
+		if len(prs) > 0 {
+			lastUpdatedAt := prs[0].PullRequest.UpdatedAt
+			for _, pr := range prs {
+				if pr.PullRequest.UpdatedAt.After(lastUpdatedAt) {
+					lastUpdatedAt = pr.PullRequest.UpdatedAt
 				}
-			} else {
-				createdPR, err := p.sourceControlAPI.CreatePullRequest(ctx, sourceControlPR)
-				if err != nil {
-					return fmt.Errorf("failed to create pull request %d: %w", pr.Number, err)
-				}
-				// Update the sourceControlPR with the created PR's ID if needed
-				sourceControlPR.ID = createdPR.ID
 			}
 
-			// 6. Get all reviews, comments and review comments
-			existingComments, err := p.sourceControlAPI.GetPullRequestComments(ctx, sourceControlPR.ID)
-			if err != nil {
-				return fmt.Errorf("failed to fetch comments for PR %d: %w", pr.Number, err)
+			if err := p.integrationAPI.UpdateRepositorySyncCursor(ctx, config.ID, repo, lastUpdatedAt); err != nil {
+				return counts, fmt.Errorf("failed to update sync cursor for %s: %w", repo, err)
 			}
+		}
+	}
 
-			existingCommentsMap := make(map[string]internaltypes.PRComment)
-			for _, comment := range existingComments {
-				existingCommentsMap[comment.ProviderID] = *comment
-			}
+	return counts, nil
+}
 
-			reviews, err := p.githubClient.GetPullRequestReviews(ctx, owner, repoName, token, pr.Number)
-			if err != nil {
-				return fmt.Errorf("failed to fetch reviews for PR %d: %w", pr.Number, err)
-			}
+// fetchPullRequests fetches the PRs of a repository updated since the last sync. The GraphQL fetch mode
+// returns the PRs with their reviews, comments and commits, the REST fetch mode only returns the PRs.
+func (p *GitHubProvider) fetchPullRequests(ctx context.Context, fetchMode, owner, repoName, token string, since *time.Time) ([]*githubtypes.PullRequestDetails, error) {
+	if fetchMode == types.GithubFetchModeGraphQL {
+		return p.githubClient.GetPullRequestsWithDetails(ctx, owner, repoName, token, since)
+	}
 
-			allComments := []*githubtypes.ReviewComment{}
-			for _, review := range reviews {
-				if _, exists := existingCommentsMap[fmt.Sprintf("%d", review.ID)]; exists {
-					continue
-				}
+	prs, err := p.githubClient.GetPullRequestsUpdatedSince(ctx, owner, repoName, token, since)
+	if err != nil {
+		return nil, err
+	}
 
-				allComments = append(allComments, &githubtypes.ReviewComment{
-					ID:        review.ID,
-					User:      review.User,
-					Body:      review.Body,
-					CreatedAt: review.SubmittedAt,
-					Type:      string(githubtypes.CommentTypeReview),
-				})
-			}
+	details := make([]*githubtypes.PullRequestDetails, 0, len(prs))
+	for _, pr := range prs {
+		details = append(details, &githubtypes.PullRequestDetails{PullRequest: pr})
+	}
+	return details, nil
+}
 
-			reviewComments, err := p.githubClient.GetPullRequestReviewComments(ctx, owner, repoName, token, pr.Number)
-			if err != nil {
-				return fmt.Errorf("failed to fetch review comments for PR %d: %w", pr.Number, err)
-			}
+// fetchPullRequestDetails fetches a PR with its reviews, comments, commits, timeline and changed files from the REST API
+func (p *GitHubProvider) fetchPullRequestDetails(ctx context.Context, owner, repoName, token string, prNumber int) (*githubtypes.PullRequestDetails, error) {
+	pr, err := p.githubClient.GetPullRequest(ctx, owner, repoName, token, prNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pull request details for PR %d: %w", prNumber, err)
+	}
 
-			for _, reviewComment := range reviewComments {
-				if _, exists := existingCommentsMap[fmt.Sprintf("%d", reviewComment.ID)]; exists {
-					continue
-				}
+	reviews, err := p.githubClient.GetPullRequestReviews(ctx, owner, repoName, token, prNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch reviews for PR %d: %w", prNumber, err)
+	}
 
-				reviewComment.Type = string(githubtypes.CommentTypeReviewComment)
-				allComments = append(allComments, reviewComment)
-			}
+	reviewComments, err := p.githubClient.GetPullRequestReviewComments(ctx, owner, repoName, token, prNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch review comments for PR %d: %w", prNumber, err)
+	}
 
-			comments, err := p.githubClient.GetPullRequestComments(ctx, owner, repoName, token, pr.Number)
-			if err != nil {
-				return fmt.Errorf("failed to fetch regular comments for PR %d: %w", pr.Number, err)
-			}
+	comments, err := p.githubClient.GetPullRequestComments(ctx, owner, repoName, token, prNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch regular comments for PR %d: %w", prNumber, err)
+	}
 
-			for _, comment := range comments {
-				if _, exists := existingCommentsMap[fmt.Sprintf("%d", comment.ID)]; exists {
-					continue
-				}
+	commits, err := p.githubClient.GetPullRequestCommits(ctx, owner, repoName, token, prNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch commits for PR %d: %w", prNumber, err)
+	}
 
-				comment.Type = string(githubtypes.CommentTypeComment)
-				allComments = append(allComments, comment)
-			}
+	timeline, err := p.githubClient.GetPullRequestTimeline(ctx, owner, repoName, token, prNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch timeline for PR %d: %w", prNumber, err)
+	}
 
-			// 7. Process each comment
-			for _, comment := range allComments {
-				// Insert/Update comment author
-				commentAuthor, err := p.upsertAuthor(ctx, config.OrganizationID, comment.User)
-				if err != nil {
-					return fmt.Errorf("failed to upsert comment author for PR %d: %w", pr.Number, err)
-				}
+	files, err := p.githubClient.GetPullRequestFiles(ctx, owner, repoName, token, prNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch changed files for PR %d: %w", prNumber, err)
+	}
 
-				// Insert/Update comment
-				sourceControlComment := &internaltypes.PRComment{
-					PRID:              sourceControlPR.ID,
-					ExternalAccountID: commentAuthor.ID,
-					ProviderID:        fmt.Sprintf("%d", comment.ID),
-					Body:              comment.Body,
-					Type:              comment.Type,
-					CreatedAt:         comment.CreatedAt,
-					UpdatedAt:         &comment.UpdatedAt,
-				}
+	return &githubtypes.PullRequestDetails{
+		PullRequest:    pr,
+		Reviews:        reviews,
+		ReviewComments: reviewComments,
+		Comments:       comments,
+		Commits:        commits,
+		Timeline:       timeline,
+		Files:          files,
+	}, nil
+}
+
+// syncPullRequest saves a PR with its new reviews and comments, and calculates its metrics. The upserted
+// records are added to counts. codeOwners is nil for repositories without a CODEOWNERS file.
+func (p *GitHubProvider) syncPullRequest(ctx context.Context, config *types.IntegrationConfig, token, owner, repoName string, repositoryID *string, details *githubtypes.PullRequestDetails, existingPR *internaltypes.PullRequest, attributor *teamtypes.Attributor, codeOwners *codeOwnersResolver, counts *types.SyncCounts) error {
+	prDetails := details.PullRequest
+
+	// 1. Insert/Update author and PR
+	sourceControlPR, err := p.savePullRequest(ctx, config, token, owner, repoName, repositoryID, prDetails, details.Commits, details.Files, existingPR, attributor)
+	if err != nil {
+		return err
+	}
+	counts.PullRequests++
+
+	// 2. Collect the reviews, comments and review comments which weren't imported yet
+	existingComments, err := p.sourceControlAPI.GetPullRequestComments(ctx, sourceControlPR.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch comments for PR %d: %w", prDetails.Number, err)
+	}
+
+	existingCommentsMap := make(map[string]internaltypes.PRComment)
+	for _, comment := range existingComments {
+		existingCommentsMap[comment.ProviderID] = *comment
+	}
 
-				if err := p.sourceControlAPI.CreatePRComments(ctx, []*internaltypes.PRComment{sourceControlComment}); err != nil {
-					return fmt.Errorf("failed to save comment for PR %d: %w", pr.Number, err)
+	allComments := []*githubtypes.ReviewComment{}
+	for _, review := range details.Reviews {
+		state := strings.ToUpper(review.State)
+		if existing, exists := existingCommentsMap[fmt.Sprintf("%d", review.ID)]; exists {
+			// Approvals can be dismissed after they were imported, and reviews imported before states were stored have none
+			if existing.ReviewState == nil || *existing.ReviewState != state {
+				existing.ReviewState = &state
+				if err := p.sourceControlAPI.UpdatePRComment(ctx, &existing); err != nil {
+					return fmt.Errorf("failed to update review state for PR %d: %w", prDetails.Number, err)
 				}
 			}
+			continue
+		}
 
-			// 9. Calculate PR metrics
-			metrics := make(map[string]interface{})
+		allComments = append(allComments, &githubtypes.ReviewComment{
+			ID:          review.ID,
+			User:        review.User,
+			Body:        review.Body,
+			CreatedAt:   review.SubmittedAt,
+			Type:        string(githubtypes.CommentTypeReview),
+			ReviewState: state,
+		})
+	}
 
-			// Filter out comments that are from bots
-			nonBotComments := lo.Filter(allComments, func(comment *githubtypes.ReviewComment, _ int) bool {
-				return comment.User.Type != "Bot"
-			})
+	for _, reviewComment := range details.ReviewComments {
+		if _, exists := existingCommentsMap[fmt.Sprintf("%d", reviewComment.ID)]; exists {
+			continue
+		}
 
-			metrics["number_of_non_bot_comments"] = len(nonBotComments)
+		reviewComment.Type = string(githubtypes.CommentTypeReviewComment)
+		allComments = append(allComments, reviewComment)
+	}
 
-			// Calculate time to merge if the PR is merged
-			if prDetails.MergedAt != nil {
-				timeToMerge := prDetails.MergedAt.Sub(prDetails.CreatedAt)
-				metrics["time_to_merge_seconds"] = int64(timeToMerge.Seconds())
-			}
+	for _, comment := range details.Comments {
+		if _, exists := existingCommentsMap[fmt.Sprintf("%d", comment.ID)]; exists {
+			continue
+		}
 
-			// Calculate time to first review
-			var firstReviewedAt time.Time
-			if len(reviews) > 0 {
-				firstReviewedAt = reviews[0].SubmittedAt
-				metrics["time_to_first_non_bot_review_seconds"] = int64(firstReviewedAt.Sub(prDetails.CreatedAt).Seconds())
-			}
+		comment.Type = string(githubtypes.CommentTypeComment)
+		allComments = append(allComments, comment)
+	}
+
+	// 3. Process each comment
+	for _, comment := range allComments {
+		// Insert/Update comment author
+		commentAuthor, err := p.upsertAuthor(ctx, config.OrganizationID, comment.User)
+		if err != nil {
+			return fmt.Errorf("failed to upsert comment author for PR %d: %w", prDetails.Number, err)
+		}
 
-			if len(nonBotComments) > 0 {
-				// Sort comments by creation time to find the first one
-				sort.Slice(nonBotComments, func(i, j int) bool {
-					return nonBotComments[i].CreatedAt.Before(nonBotComments[j].CreatedAt)
-				})
+		// Insert/Update comment
+		sourceControlComment := &internaltypes.PRComment{
+			PRID:              sourceControlPR.ID,
+			ExternalAccountID: commentAuthor.ID,
+			ProviderID:        fmt.Sprintf("%d", comment.ID),
+			Body:              comment.Body,
+			Type:              comment.Type,
+			CreatedAt:         comment.CreatedAt,
+			UpdatedAt:         &comment.UpdatedAt,
+			ReviewState:       reviewState(comment),
+		}
 
-				firstComment := nonBotComments[0]
-				if firstComment.CreatedAt.Before(firstReviewedAt) {
-					firstReviewedAt = firstComment.CreatedAt
-				}
+		if err := p.sourceControlAPI.CreatePRComments(ctx, []*internaltypes.PRComment{sourceControlComment}); err != nil {
+			return fmt.Errorf("failed to save comment for PR %d: %w", prDetails.Number, err)
+		}
+		counts.Comments++
+	}
 
-				timeToFirstReview := firstReviewedAt.Sub(prDetails.CreatedAt)
-				metrics["time_to_first_non_bot_review_seconds"] = int64(timeToFirstReview.Seconds())
-			}
+	// 4. Save the timeline events which weren't imported yet
+	if err := p.savePullRequestEvents(ctx, config, sourceControlPR, details.Timeline); err != nil {
+		return err
+	}
 
-			// Update PR with metrics
-			metricsBytes, _ := json.Marshal(metrics)
-			sourceControlPR.Metrics = datatypes.JSON(metricsBytes)
+	// 5. Save the commits which weren't imported yet
+	if err := p.savePullRequestCommits(ctx, config, sourceControlPR, details.Commits); err != nil {
+		return err
+	}
 
-			// Save updated PR with metrics
-			if err := p.sourceControlAPI.UpdatePullRequest(ctx, sourceControlPR); err != nil {
-				return fmt.Errorf("failed to save pull request metrics for PR %d: %w", pr.Number, err)
-			}
+	// 6. Replace the changed files, later pushes can drop files from the PR
+	if details.Files != nil {
+		if err := p.sourceControlAPI.ReplacePRFiles(ctx, sourceControlPR.ID, prFiles(sourceControlPR.ID, details.Files)); err != nil {
+			return fmt.Errorf("failed to save changed files for PR %d: %w", prDetails.Number, err)
 		}
 	}
 
+	// 7. Find the code owners required to review the changed files and whether they did
+	if codeOwners != nil && details.Files != nil {
+		if err := p.savePullRequestCodeOwners(ctx, config, codeOwners, sourceControlPR, details); err != nil {
+			return err
+		}
+	}
+
+	// 8. Save the check runs of the head commit, tokens without access to checks still sync pull requests
+	if err := p.savePullRequestCheckRuns(ctx, token, owner, repoName, sourceControlPR, prDetails.Head.Sha); err != nil {
+		if _, rateLimited := github.IsRateLimitError(err); rateLimited {
+			return err
+		}
+		fmt.Printf("Warning: %v\n", err)
+	}
+
+	// 9. Calculate PR metrics from the stored comments, timeline events and commits
+	if err := p.sourceControlAPI.RefreshPullRequestMetrics(ctx, sourceControlPR); err != nil {
+		return fmt.Errorf("failed to save pull request metrics for PR %d: %w", prDetails.Number, err)
+	}
+
 	return nil
 }
 
+// prFiles converts the changed files of a PR
+func prFiles(prID string, files []*githubtypes.PullRequestFile) []*internaltypes.PRFile {
+	prFiles := make([]*internaltypes.PRFile, 0, len(files))
+	for _, file := range files {
+		prFile := &internaltypes.PRFile{
+			PRID:      prID,
+			Path:      file.Filename,
+			Status:    file.Status,
+			Additions: file.Additions,
+			Deletions: file.Deletions,
+		}
+		if file.PreviousFilename != "" {
+			previousPath := file.PreviousFilename
+			prFile.PreviousPath = &previousPath
+		}
+		prFiles = append(prFiles, prFile)
+	}
+	return prFiles
+}
+
+// reviewState returns the review state to store for a comment, nil for comments which aren't reviews
+func reviewState(comment *githubtypes.ReviewComment) *string {
+	if comment.ReviewState == "" {
+		return nil
+	}
+	return &comment.ReviewState
+}
+
+// syncRulesPullRequest returns the attributes of a PR the repository sync rules apply to
+func syncRulesPullRequest(pr *githubtypes.PullRequest) types.RepositoryPullRequest {
+	labels := make([]string, 0, len(pr.Labels))
+	for _, label := range pr.Labels {
+		labels = append(labels, label.Name)
+	}
+
+	return types.RepositoryPullRequest{
+		BaseBranch: pr.Base.Ref,
+		Draft:      pr.Draft,
+		Labels:     labels,
+		Author:     pr.User.Login,
+		UpdatedAt:  pr.UpdatedAt,
+	}
+}
+
+// getToken returns the token to call the GitHub API with. GitHub App integrations store the app's
+// private key and call the API with an installation token, which the client caches until it expires.
+func (p *GitHubProvider) getToken(ctx context.Context, config *types.IntegrationConfig) (string, error) {
+	credential, err := p.integrationAPI.DecryptToken(config.EncryptedToken)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt token: %w", err)
+	}
+
+	appAuth, err := types.ParseGithubAppAuth(config.Metadata)
+	if err != nil {
+		return "", err
+	}
+	if appAuth == nil {
+		return credential, nil
+	}
+
+	token, err := p.githubClient.GetInstallationToken(ctx, appAuth.AppID, appAuth.InstallationID, credential)
+	if err != nil {
+		return "", fmt.Errorf("failed to get installation token for app %s: %w", appAuth.AppID, err)
+	}
+	return token, nil
+}
+
+// getAttributor returns the attributor matching the PRs of the organization with its teams
+func (p *GitHubProvider) getAttributor(ctx context.Context, organizationID string) *teamtypes.Attributor {
+	attributor, err := p.teamAPI.GetAttributor(ctx, organizationID)
+	if err != nil {
+		// Log error but don't fail - team attribution is optional
+		fmt.Printf("Warning: failed to fetch team attribution rules: %v\n", err)
+		return teamtypes.NewAttributor(nil, nil)
+	}
+	return attributor
+}
+
+// savePullRequest upserts the author and the pull request linked to its repository, and attributes the PR to a team
+// with the attribution rules of the organization. Commits and changed files are fetched when nil is passed and a
+// rule needs them.
+func (p *GitHubProvider) savePullRequest(ctx context.Context, config *types.IntegrationConfig, token, owner, repoName string, repositoryID *string, prDetails *githubtypes.PullRequest, commits []*githubtypes.Commit, files []*githubtypes.PullRequestFile, existingPR *internaltypes.PullRequest, attributor *teamtypes.Attributor) (*internaltypes.PullRequest, error) {
+	authorAccount, err := p.upsertAuthor(ctx, config.OrganizationID, prDetails.User)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert author for PR %d: %w", prDetails.Number, err)
+	}
+
+	prDetailsBytes, _ := json.Marshal(prDetails)
+
+	labels := lo.Map(prDetails.Labels, func(label githubtypes.Label, _ int) string { return label.Name })
+	labelsBytes, _ := json.Marshal(labels)
+
+	if commits == nil && attributor.Uses(teamtypes.AttributionRuleTicketPrefix) {
+		if commits, err = p.githubClient.GetPullRequestCommits(ctx, owner, repoName, token, prDetails.Number); err != nil {
+			// Log error but don't fail - commit fetching is optional
+			fmt.Printf("Warning: failed to fetch commits for PR %d: %v\n", prDetails.Number, err)
+		}
+	}
+	if files == nil && attributor.Uses(teamtypes.AttributionRulePathGlob) {
+		if files, err = p.githubClient.GetPullRequestFiles(ctx, owner, repoName, token, prDetails.Number); err != nil {
+			// Log error but don't fail - file fetching is optional
+			fmt.Printf("Warning: failed to fetch changed files for PR %d: %v\n", prDetails.Number, err)
+		}
+	}
+
+	attribution := attributor.Attribute(teamtypes.AttributionInput{
+		RepositoryName: repoName,
+		Title:          prDetails.Title,
+		Branch:         prDetails.Head.Ref,
+		Labels:         labels,
+		Paths:          lo.Map(files, func(file *githubtypes.PullRequestFile, _ int) string { return file.Filename }),
+		CommitMessages: lo.Map(commits, func(commit *githubtypes.Commit, _ int) string { return commit.Commit.Message }),
+	})
+	var teamID, matchedPrefix *string
+	if attribution != nil {
+		teamID = &attribution.TeamID
+		matchedPrefix = attribution.Prefix
+	}
+
+	sourceControlPR := &internaltypes.PullRequest{
+		ExternalAccountID: authorAccount.ID,
+		ProviderID:        fmt.Sprintf("%d", prDetails.ID),
+		RepositoryName:    repoName,
+		Title:             prDetails.Title,
+		Description:       prDetails.Body,
+		Status:            prDetails.State,
+		CreatedAt:         prDetails.CreatedAt,
+		MergedAt:          prDetails.MergedAt,
+		LastUpdatedAt:     prDetails.UpdatedAt,
+		Comments:          prDetails.Comments,
+		ReviewComments:    prDetails.ReviewComments,
+		Additions:         prDetails.Additions,
+		Deletions:         prDetails.Deletions,
+		ChangedFiles:      prDetails.ChangedFiles,
+		URL:               prDetails.URL,
+		Prefix:            matchedPrefix,
+		TeamID:            teamID,
+		RepositoryID:      repositoryID,
+		HeadBranch:        prDetails.Head.Ref,
+		Labels:            datatypes.JSON(labelsBytes),
+		Metadata:          datatypes.JSON(prDetailsBytes),
+	}
+
+	if existingPR != nil {
+		sourceControlPR.ID = existingPR.ID
+		if err := p.sourceControlAPI.UpdatePullRequest(ctx, sourceControlPR); err != nil {
+			return nil, fmt.Errorf("failed to update pull request %d: %w", prDetails.Number, err)
+		}
+		// Updates skip nil fields, a PR which no longer matches any rule is detached from its team explicitly
+		if attribution == nil && (existingPR.TeamID != nil || existingPR.Prefix != nil) {
+			if err := p.sourceControlAPI.UpdatePullRequestTeams(ctx, []*internaltypes.PullRequestTeam{{PRID: existingPR.ID}}); err != nil {
+				return nil, fmt.Errorf("failed to update team of pull request %d: %w", prDetails.Number, err)
+			}
+		}
+	} else {
+		createdPR, err := p.sourceControlAPI.CreatePullRequest(ctx, sourceControlPR)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create pull request %d: %w", prDetails.Number, err)
+		}
+		sourceControlPR.ID = createdPR.ID
+	}
+
+	return sourceControlPR, nil
+}
+
 // upsertAuthor handles the creation or update of an external account (source control)
 // Returns a SourceControlAccount type for backward compatibility with existing code
 func (p *GitHubProvider) upsertAuthor(ctx context.Context, organizationID string, user githubtypes.User) (*internaltypes.SourceControlAccount, error) {
-	// Check if account exists
-	sourceControlType := "sourcecontrol"
+	p.authorMu.Lock()
+	defer p.authorMu.Unlock()
+
+	// Check if account exists, GitLab and Bitbucket accounts can have the same username
+	sourceControlType := string(membertypes.ExternalAccountTypeSourceControl)
 	accounts, err := p.memberAPI.GetExternalAccounts(ctx, &membertypes.ExternalAccountParams{
-		Usernames:   []string{user.Login},
-		AccountType: &sourceControlType,
+		OrganizationID: organizationID,
+		Usernames:      []string{user.Login},
+		AccountType:    &sourceControlType,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch external account: %w", err)
 	}
 
-	accountsMap := make(map[string]membertypes.ExternalAccount)
 	for _, account := range accounts {
-		accountsMap[account.Username] = account
-	}
-
-	if account, exists := accountsMap[user.Login]; exists {
-		if account.OrganizationID != nil && *account.OrganizationID == organizationID {
+		if account.ProviderName == p.Name() && account.Username == user.Login {
 			// Convert to SourceControlAccount for backward compatibility
 			return convertExternalAccountToSourceControlAccount(&account), nil
 		}
 	}
 
-	// Get the member ID for this user in this org username
-	// For now, we'll create the account without member_id and let the application handle the association later
+	// The account is created without member_id, identity resolution proposes the member it belongs to
 	metadata, _ := json.Marshal(user)
 	newAccount := &membertypes.ExternalAccount{
 		AccountType:    "sourcecontrol",
-		ProviderName:   "github",
+		ProviderName:   p.Name(),
 		OrganizationID: &organizationID,
 		Username:       user.Login,
 		ProviderID:     fmt.Sprintf("%d", user.ID),
@@ -417,6 +615,7 @@ func convertExternalAccountToSourceControlAccount(account *membertypes.ExternalA
 		ProviderID:     account.ProviderID,
 		Username:       account.Username,
 		Metadata:       account.Metadata,
+		IsBot:          account.IsBot,
 		LastSyncedAt:   account.LastSyncedAt,
 	}
 }
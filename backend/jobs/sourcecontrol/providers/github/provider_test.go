@@ -0,0 +1,64 @@
+package github
+
+import (
+	"context"
+	"testing"
+
+	githubtypes "ems.dev/backend/libraries/github/types"
+	membertypes "ems.dev/backend/services/member/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertAuthor(t *testing.T) {
+	orgID := "org-1"
+	account := func(id, provider string, organizationID *string) membertypes.ExternalAccount {
+		return membertypes.ExternalAccount{ID: id, ProviderName: provider, OrganizationID: organizationID, Username: "alice"}
+	}
+
+	tests := []struct {
+		name     string
+		accounts []membertypes.ExternalAccount
+		// expectedID is the ID of the returned account, empty when an account is created
+		expectedID string
+	}{
+		{
+			name:       "existing GitHub account",
+			accounts:   []membertypes.ExternalAccount{account("gitlab-1", "gitlab", &orgID), account("github-1", "github", &orgID)},
+			expectedID: "github-1",
+		},
+		{
+			name:     "accounts of other providers with the same username aren't reused",
+			accounts: []membertypes.ExternalAccount{account("gitlab-1", "gitlab", &orgID), account("bitbucket-1", "bitbucket", &orgID)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			memberAPI := new(MockMemberAPI)
+			provider := &GitHubProvider{memberAPI: memberAPI}
+
+			memberAPI.On("GetExternalAccounts", ctx, mock.MatchedBy(func(params *membertypes.ExternalAccountParams) bool {
+				return params.OrganizationID == orgID && params.Usernames[0] == "alice"
+			})).Return(tt.accounts, nil).Once()
+			var created []*membertypes.ExternalAccount
+			memberAPI.On("CreateExternalAccounts", ctx, mock.Anything).Run(func(args mock.Arguments) {
+				created = args.Get(1).([]*membertypes.ExternalAccount)
+			}).Return(nil)
+
+			author, err := provider.upsertAuthor(ctx, orgID, githubtypes.User{ID: 7, Login: "alice"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedID, author.ID)
+			if tt.expectedID != "" {
+				assert.Empty(t, created)
+				return
+			}
+			require.Len(t, created, 1)
+			assert.Equal(t, "github", created[0].ProviderName)
+			assert.Equal(t, &orgID, created[0].OrganizationID)
+			assert.Equal(t, "7", created[0].ProviderID)
+		})
+	}
+}
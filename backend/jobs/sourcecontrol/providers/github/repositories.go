@@ -0,0 +1,59 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"ems.dev/backend/libraries/github"
+	githubtypes "ems.dev/backend/libraries/github/types"
+	"ems.dev/backend/services/integration/types"
+	internaltypes "ems.dev/backend/services/sourcecontrol/types"
+)
+
+// syncRepository fetches the details of a repository and saves it, returns the ID of the saved repository.
+// Repositories whose details cannot be fetched are saved by owner and name, keeping the details saved before.
+func (p *GitHubProvider) syncRepository(ctx context.Context, config *types.IntegrationConfig, token, owner, repoName string) (*string, error) {
+	repo, err := p.githubClient.GetRepository(ctx, owner, repoName, token)
+	if err != nil {
+		if _, rateLimited := github.IsRateLimitError(err); rateLimited {
+			return nil, err
+		}
+		fmt.Printf("Warning: failed to fetch repository %s/%s: %v\n", owner, repoName, err)
+		repo = &githubtypes.Repo{Name: repoName, Owner: githubtypes.User{Login: owner}}
+	}
+
+	return p.saveRepository(ctx, config, repo)
+}
+
+// saveRepository upserts a repository from its GitHub details, returns the ID of the saved repository. Details
+// without an ID were not fetched from GitHub and only carry the owner and name.
+func (p *GitHubProvider) saveRepository(ctx context.Context, config *types.IntegrationConfig, repo *githubtypes.Repo) (*string, error) {
+	repository := &internaltypes.Repository{
+		OrganizationID: config.OrganizationID,
+		Provider:       p.Name(),
+		Owner:          repo.Owner.Login,
+		Name:           repo.Name,
+		Archived:       repo.Archived,
+	}
+
+	if repo.ID != 0 {
+		visibility := repo.Visibility
+		// Visibility is missing from the responses of older GitHub Enterprise Server versions
+		if visibility == "" {
+			visibility = internaltypes.RepositoryVisibilityPublic
+			if repo.Private {
+				visibility = internaltypes.RepositoryVisibilityPrivate
+			}
+		}
+		repository.Visibility = &visibility
+	}
+	if repo.DefaultBranch != "" {
+		repository.DefaultBranch = &repo.DefaultBranch
+	}
+
+	if err := p.sourceControlAPI.UpsertRepository(ctx, repository); err != nil {
+		return nil, fmt.Errorf("failed to save repository %s/%s: %w", repository.Owner, repository.Name, err)
+	}
+
+	return &repository.ID, nil
+}
@@ -0,0 +1,49 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	githubtypes "ems.dev/backend/libraries/github/types"
+	"ems.dev/backend/services/integration/types"
+	internaltypes "ems.dev/backend/services/sourcecontrol/types"
+	"gorm.io/datatypes"
+)
+
+// savePullRequestEvents saves the timeline events of a PR which weren't saved yet
+func (p *GitHubProvider) savePullRequestEvents(ctx context.Context, config *types.IntegrationConfig, pr *internaltypes.PullRequest, timeline []*githubtypes.TimelineEvent) error {
+	events := make([]*internaltypes.PullRequestEvent, 0, len(timeline))
+	for _, event := range timeline {
+		// Events are deduplicated on their node ID, events without one can't be told apart on the next sync
+		if event.NodeID == "" {
+			continue
+		}
+
+		actor, err := p.upsertAuthor(ctx, config.OrganizationID, event.Actor)
+		if err != nil {
+			return fmt.Errorf("failed to upsert actor of %s event for PR %s: %w", event.Event, pr.ProviderID, err)
+		}
+
+		metadata := map[string]interface{}{}
+		if event.RequestedReviewer != nil {
+			metadata["requested_reviewer"] = event.RequestedReviewer.Login
+		}
+		metadataBytes, _ := json.Marshal(metadata)
+
+		events = append(events, &internaltypes.PullRequestEvent{
+			PRID:              pr.ID,
+			ExternalAccountID: actor.ID,
+			ProviderID:        event.NodeID,
+			Event:             event.Event,
+			Metadata:          datatypes.JSON(metadataBytes),
+			CreatedAt:         event.CreatedAt,
+		})
+	}
+
+	if err := p.sourceControlAPI.CreatePullRequestEvents(ctx, events); err != nil {
+		return fmt.Errorf("failed to save timeline events for PR %s: %w", pr.ProviderID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,265 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"ems.dev/backend/libraries/github"
+	githubtypes "ems.dev/backend/libraries/github/types"
+	"ems.dev/backend/services/integration/types"
+	internaltypes "ems.dev/backend/services/sourcecontrol/types"
+)
+
+// HandleWebhookEvent ingests a webhook delivery of a GitHub integration. Pull requests, reviews and
+// comments are upserted from the event payload as they happen, the periodic sync keeps running to
+// reconcile missed deliveries. Events for repositories which are not configured are ignored.
+func (p *GitHubProvider) HandleWebhookEvent(ctx context.Context, config *types.IntegrationConfig, eventType string, payload []byte) error {
+	switch eventType {
+	case github.WebhookEventPullRequest:
+		var event githubtypes.PullRequestEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return fmt.Errorf("failed to parse %s event: %w", eventType, err)
+		}
+		return p.handlePullRequestEvent(ctx, config, &event)
+	case github.WebhookEventPullRequestReview:
+		var event githubtypes.PullRequestReviewEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return fmt.Errorf("failed to parse %s event: %w", eventType, err)
+		}
+		return p.handlePullRequestReviewEvent(ctx, config, &event)
+	case github.WebhookEventIssueComment:
+		var event githubtypes.IssueCommentEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return fmt.Errorf("failed to parse %s event: %w", eventType, err)
+		}
+		return p.handleIssueCommentEvent(ctx, config, &event)
+	default:
+		return nil
+	}
+}
+
+func (p *GitHubProvider) handlePullRequestEvent(ctx context.Context, config *types.IntegrationConfig, event *githubtypes.PullRequestEvent) error {
+	repoConfig := repositoryConfig(config, event.Repository.FullName)
+	if repoConfig == nil || !repoConfig.ShouldSync(syncRulesPullRequest(&event.PullRequest), time.Now()) {
+		return nil
+	}
+
+	token, err := p.getToken(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	existingPR, err := p.findPullRequest(ctx, config, event.Repository.Name, event.PullRequest.ID)
+	if err != nil {
+		return err
+	}
+
+	// Webhook payloads carry the details of the repository
+	repositoryID, err := p.saveRepository(ctx, config, &event.Repository)
+	if err != nil {
+		return err
+	}
+
+	owner := strings.Split(event.Repository.FullName, "/")[0]
+	pr, err := p.savePullRequest(ctx, config, token, owner, event.Repository.Name, repositoryID, &event.PullRequest, nil, nil, existingPR, p.getAttributor(ctx, config.OrganizationID))
+	if err != nil {
+		return err
+	}
+
+	return p.sourceControlAPI.RefreshPullRequestMetrics(ctx, pr)
+}
+
+// handlePullRequestReviewEvent saves submitted and edited reviews, and the state of dismissed reviews
+func (p *GitHubProvider) handlePullRequestReviewEvent(ctx context.Context, config *types.IntegrationConfig, event *githubtypes.PullRequestReviewEvent) error {
+	if event.Action != "submitted" && event.Action != "edited" && event.Action != "dismissed" {
+		return nil
+	}
+
+	repoConfig := repositoryConfig(config, event.Repository.FullName)
+	if repoConfig == nil || !repoConfig.ShouldSync(syncRulesPullRequest(&event.PullRequest), time.Now()) {
+		return nil
+	}
+
+	pr, err := p.findPullRequest(ctx, config, event.Repository.Name, event.PullRequest.ID)
+	if err != nil {
+		return err
+	}
+
+	// The review payload only has a summary of the pull request, fetch the full PR if it was never imported
+	if pr == nil {
+		if pr, err = p.importPullRequest(ctx, config, event.Repository, event.PullRequest.Number); err != nil {
+			return err
+		}
+	}
+
+	review := &githubtypes.ReviewComment{
+		ID:        event.Review.ID,
+		User:      event.Review.User,
+		Body:      event.Review.Body,
+		Type:      string(githubtypes.CommentTypeReview),
+		CreatedAt: event.Review.SubmittedAt,
+		UpdatedAt: event.Review.SubmittedAt,
+		// Webhooks deliver review states in lowercase
+		ReviewState: strings.ToUpper(event.Review.State),
+	}
+	if event.Action == "dismissed" {
+		review.ReviewState = internaltypes.ReviewStateDismissed
+	}
+	if err := p.saveComment(ctx, config, pr, review); err != nil {
+		return err
+	}
+
+	return p.sourceControlAPI.RefreshPullRequestMetrics(ctx, pr)
+}
+
+func (p *GitHubProvider) handleIssueCommentEvent(ctx context.Context, config *types.IntegrationConfig, event *githubtypes.IssueCommentEvent) error {
+	// Comments on plain issues are also delivered as issue comments
+	if event.Issue.PullRequest == nil {
+		return nil
+	}
+
+	if event.Action != "created" && event.Action != "edited" {
+		return nil
+	}
+
+	if repositoryConfig(config, event.Repository.FullName) == nil {
+		return nil
+	}
+
+	// The event only references the PR number, so the PR is always fetched to find its provider ID
+	pr, err := p.importPullRequest(ctx, config, event.Repository, event.Issue.Number)
+	if err != nil || pr == nil {
+		return err
+	}
+
+	comment := event.Comment
+	comment.Type = string(githubtypes.CommentTypeComment)
+	if err := p.saveComment(ctx, config, pr, &comment); err != nil {
+		return err
+	}
+
+	return p.sourceControlAPI.RefreshPullRequestMetrics(ctx, pr)
+}
+
+// importPullRequest fetches a pull request from GitHub and upserts it. Returns nil if the PR is ignored.
+func (p *GitHubProvider) importPullRequest(ctx context.Context, config *types.IntegrationConfig, repo githubtypes.Repo, prNumber int) (*internaltypes.PullRequest, error) {
+	repoConfig := repositoryConfig(config, repo.FullName)
+	if repoConfig == nil {
+		return nil, nil
+	}
+
+	token, err := p.getToken(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	owner := strings.Split(repo.FullName, "/")[0]
+	prDetails, err := p.githubClient.GetPullRequest(ctx, owner, repo.Name, token, prNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pull request details for PR %d: %w", prNumber, err)
+	}
+
+	// Ignore PRs excluded by the repository sync rules
+	if !repoConfig.ShouldSync(syncRulesPullRequest(prDetails), time.Now()) {
+		return nil, nil
+	}
+
+	existingPR, err := p.findPullRequest(ctx, config, repo.Name, prDetails.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Webhook payloads carry the details of the repository
+	repositoryID, err := p.saveRepository(ctx, config, &repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.savePullRequest(ctx, config, token, owner, repo.Name, repositoryID, prDetails, nil, nil, existingPR, p.getAttributor(ctx, config.OrganizationID))
+}
+
+// findPullRequest returns the imported pull request with the given GitHub ID, or nil if it wasn't imported yet
+func (p *GitHubProvider) findPullRequest(ctx context.Context, config *types.IntegrationConfig, repoName string, providerID int) (*internaltypes.PullRequest, error) {
+	prs, err := p.sourceControlAPI.GetPullRequests(ctx, &internaltypes.PullRequestParams{
+		ProviderIDs:    []string{fmt.Sprintf("%d", providerID)},
+		OrganizationID: &config.OrganizationID,
+		RepositoryName: repoName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch imported pull request %d: %w", providerID, err)
+	}
+
+	if len(prs) == 0 {
+		return nil, nil
+	}
+	return prs[0], nil
+}
+
+// saveComment creates the comment, or updates its body if it was edited after being imported
+func (p *GitHubProvider) saveComment(ctx context.Context, config *types.IntegrationConfig, pr *internaltypes.PullRequest, comment *githubtypes.ReviewComment) error {
+	existingComments, err := p.sourceControlAPI.GetPullRequestComments(ctx, pr.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch comments for PR %s: %w", pr.ProviderID, err)
+	}
+
+	providerID := fmt.Sprintf("%d", comment.ID)
+	for _, existing := range existingComments {
+		if existing.ProviderID != providerID {
+			continue
+		}
+		if existing.Body == comment.Body && equalReviewState(existing.ReviewState, reviewState(comment)) {
+			return nil
+		}
+
+		existing.Body = comment.Body
+		existing.UpdatedAt = &comment.UpdatedAt
+		existing.ReviewState = reviewState(comment)
+		if err := p.sourceControlAPI.UpdatePRComment(ctx, existing); err != nil {
+			return fmt.Errorf("failed to update comment %s: %w", providerID, err)
+		}
+		return nil
+	}
+
+	commentAuthor, err := p.upsertAuthor(ctx, config.OrganizationID, comment.User)
+	if err != nil {
+		return fmt.Errorf("failed to upsert comment author for PR %s: %w", pr.ProviderID, err)
+	}
+
+	sourceControlComment := &internaltypes.PRComment{
+		PRID:              pr.ID,
+		ExternalAccountID: commentAuthor.ID,
+		ProviderID:        providerID,
+		Body:              comment.Body,
+		Type:              comment.Type,
+		CreatedAt:         comment.CreatedAt,
+		UpdatedAt:         &comment.UpdatedAt,
+		ReviewState:       reviewState(comment),
+	}
+
+	if err := p.sourceControlAPI.CreatePRComments(ctx, []*internaltypes.PRComment{sourceControlComment}); err != nil {
+		return fmt.Errorf("failed to save comment for PR %s: %w", pr.ProviderID, err)
+	}
+
+	return nil
+}
+
+// equalReviewState reports whether two review states are the same, comments which aren't reviews have none
+func equalReviewState(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// repositoryConfig returns the config of the repository ("owner/repo") if it is one of the integration's
+// repositories, nil otherwise
+func repositoryConfig(config *types.IntegrationConfig, fullName string) *types.RepositoryConfig {
+	repositories, err := types.ParseRepositoryConfigs(config.Metadata)
+	if err != nil {
+		return nil
+	}
+	return types.FindRepositoryConfig(repositories, fullName)
+}
@@ -0,0 +1,106 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"ems.dev/backend/libraries/github"
+	githubtypes "ems.dev/backend/libraries/github/types"
+	"ems.dev/backend/services/integration/types"
+	internaltypes "ems.dev/backend/services/sourcecontrol/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func TestHandlePullRequestReviewEvent(t *testing.T) {
+	submittedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	approved := internaltypes.ReviewStateApproved
+
+	tests := []struct {
+		name                string
+		action              string
+		state               string
+		repository          string
+		expectedReviewState *string // State the stored review is updated to, not updated when nil
+	}{
+		{
+			name:                "dismissed review",
+			action:              "dismissed",
+			state:               "dismissed",
+			repository:          "acme/api",
+			expectedReviewState: ptr(internaltypes.ReviewStateDismissed),
+		},
+		{
+			name:                "dismissed review whose payload has the submitted state",
+			action:              "dismissed",
+			state:               "approved",
+			repository:          "acme/api",
+			expectedReviewState: ptr(internaltypes.ReviewStateDismissed),
+		},
+		{
+			name:                "edited review",
+			action:              "edited",
+			state:               "changes_requested",
+			repository:          "acme/api",
+			expectedReviewState: ptr(internaltypes.ReviewStateChangesRequested),
+		},
+		{
+			name:       "review which is already stored",
+			action:     "submitted",
+			state:      "approved",
+			repository: "acme/api",
+		},
+		{
+			name:       "repository which isn't configured",
+			action:     "dismissed",
+			state:      "dismissed",
+			repository: "acme/web",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			sourceControlAPI := &MockSourceControlAPI{}
+			provider := &GitHubProvider{sourceControlAPI: sourceControlAPI}
+			config := &types.IntegrationConfig{
+				OrganizationID: "org-1",
+				Metadata:       datatypes.JSON(`{"repositories": [{"name": "acme/api"}]}`),
+			}
+			pr := &internaltypes.PullRequest{ID: "pr-1", ProviderID: "7"}
+
+			if tt.repository == "acme/api" {
+				sourceControlAPI.On("GetPullRequests", ctx, mock.Anything).Return([]*internaltypes.PullRequest{pr}, nil)
+				sourceControlAPI.On("GetPullRequestComments", ctx, "pr-1").Return([]*internaltypes.PRComment{
+					{ID: "comment-1", PRID: "pr-1", ProviderID: "42", Body: "LGTM", Type: string(githubtypes.CommentTypeReview), ReviewState: &approved},
+				}, nil)
+				sourceControlAPI.On("RefreshPullRequestMetrics", ctx, pr).Return(nil)
+			}
+			if tt.expectedReviewState != nil {
+				sourceControlAPI.On("UpdatePRComment", ctx, mock.MatchedBy(func(comment *internaltypes.PRComment) bool {
+					return comment.ID == "comment-1" && assert.ObjectsAreEqual(tt.expectedReviewState, comment.ReviewState)
+				})).Return(nil).Once()
+			}
+
+			payload, err := json.Marshal(githubtypes.PullRequestReviewEvent{
+				Action:      tt.action,
+				Review:      githubtypes.Review{ID: 42, User: githubtypes.User{Login: "bob"}, Body: "LGTM", State: tt.state, SubmittedAt: submittedAt},
+				PullRequest: githubtypes.PullRequest{ID: 7, Number: 1, UpdatedAt: time.Now()},
+				Repository:  githubtypes.Repo{Name: tt.repository[len("acme/"):], FullName: tt.repository},
+			})
+			require.NoError(t, err)
+
+			err = provider.HandleWebhookEvent(ctx, config, github.WebhookEventPullRequestReview, payload)
+			assert.NoError(t, err)
+			sourceControlAPI.AssertExpectations(t)
+		})
+	}
+}
+
+func ptr(value string) *string {
+	return &value
+}
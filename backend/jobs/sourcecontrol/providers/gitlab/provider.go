@@ -0,0 +1,537 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"ems.dev/backend/libraries/gitlab"
+	gitlabtypes "ems.dev/backend/libraries/gitlab/types"
+	"ems.dev/backend/services/integration/api"
+	"ems.dev/backend/services/integration/types"
+	memberapi "ems.dev/backend/services/member/api"
+	membertypes "ems.dev/backend/services/member/types"
+	sourcecontrolapi "ems.dev/backend/services/sourcecontrol/api"
+	internaltypes "ems.dev/backend/services/sourcecontrol/types"
+	teamapi "ems.dev/backend/services/team/api"
+	teamtypes "ems.dev/backend/services/team/types"
+	"github.com/samber/lo"
+	"gorm.io/datatypes"
+)
+
+type GitLabProvider struct {
+	gitlabClient     gitlab.GitlabClient
+	integrationAPI   api.IntegrationAPI
+	sourceControlAPI sourcecontrolapi.SourceControlAPI
+	memberAPI        memberapi.MemberAPI
+	teamAPI          teamapi.TeamAPI
+
+	// authorMu serializes author upserts, repositories are synced concurrently and share their authors
+	authorMu sync.Mutex
+}
+
+func NewProvider(
+	gitlabClient gitlab.GitlabClient,
+	integrationAPI api.IntegrationAPI,
+	sourceControlAPI sourcecontrolapi.SourceControlAPI,
+	memberAPI memberapi.MemberAPI,
+	teamAPI teamapi.TeamAPI,
+) *GitLabProvider {
+	return &GitLabProvider{
+		gitlabClient:     gitlabClient,
+		integrationAPI:   integrationAPI,
+		sourceControlAPI: sourceControlAPI,
+		memberAPI:        memberAPI,
+		teamAPI:          teamAPI,
+	}
+}
+
+func (p *GitLabProvider) Name() string {
+	return "gitlab"
+}
+
+// approvalProviderIDPrefix prefixes the provider ID of the REVIEW comments stored for approvals, which are
+// keyed by approver since GitLab approvals have no ID of their own
+const approvalProviderIDPrefix = "approval-"
+
+// syncedComment is a note or approval fetched from GitLab, normalised to the shape stored as a PRComment
+type syncedComment struct {
+	ProviderID string
+	User       gitlabtypes.User
+	Body       string
+	Type       string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	// ReviewState is only set on reviews
+	ReviewState *string
+}
+
+// SyncRepositories fetches and syncs merge requests for the given GitLab projects.
+// Repositories are full project paths (e.g. "group/subgroup/project") and the instance URL is read
+// from the "base_url" integration metadata, defaulting to gitlab.com.
+func (p *GitLabProvider) SyncRepositories(ctx context.Context, config *types.IntegrationConfig, repositories []types.RepositoryConfig) (types.SyncCounts, error) {
+	counts := types.SyncCounts{}
+
+	// Decrypt the token
+	token, err := p.integrationAPI.DecryptToken(config.EncryptedToken)
+	if err != nil {
+		return counts, fmt.Errorf("failed to decrypt token: %w", err)
+	}
+
+	baseURL := gitlab.DefaultBaseURL
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(config.Metadata, &metadata); err == nil {
+		if configuredURL, ok := metadata["base_url"].(string); ok && configuredURL != "" {
+			baseURL = configuredURL
+		}
+	}
+
+	// Get the rules attributing the MRs to the organization's teams
+	attributor, err := p.teamAPI.GetAttributor(ctx, config.OrganizationID)
+	if err != nil {
+		// Log error but don't fail - team attribution is optional
+		fmt.Printf("Warning: failed to fetch team attribution rules: %v\n", err)
+		attributor = teamtypes.NewAttributor(nil, nil)
+	}
+
+	// Process each repository
+	now := time.Now()
+	for _, repoConfig := range repositories {
+		repo := repoConfig.Name
+
+		if !strings.Contains(repo, "/") {
+			return counts, fmt.Errorf("invalid repository format: %s. Expected format: group/project", repo)
+		}
+
+		repoName := path.Base(repo)
+
+		repositoryID, err := p.syncRepository(ctx, config, baseURL, repo, token)
+		if err != nil {
+			return counts, err
+		}
+
+		// 1. Fetch the MRs updated since the last sync of the project
+		cursor, err := p.integrationAPI.GetRepositorySyncCursor(ctx, config.ID, repo)
+		if err != nil {
+			return counts, fmt.Errorf("failed to fetch sync cursor for %s: %w", repo, err)
+		}
+
+		var since *time.Time
+		if cursor != nil {
+			since = &cursor.LastUpdatedAt
+		}
+
+		// MRs older than the max lookback are never synced, so there is no need to fetch them
+		if lookbackStart := repoConfig.LookbackStart(now); lookbackStart != nil && (since == nil || lookbackStart.After(*since)) {
+			since = lookbackStart
+		}
+
+		mrs, err := p.gitlabClient.GetMergeRequestsUpdatedSince(ctx, baseURL, repo, token, since)
+		if err != nil {
+			return counts, fmt.Errorf("failed to fetch merge requests for %s: %w", repo, err)
+		}
+
+		mrIds := []string{}
+		for _, mr := range mrs {
+			mrIds = append(mrIds, fmt.Sprintf("%d", mr.ID))
+		}
+
+		// 2. Get all PRs from the database. Scoped to the organization and repository so that
+		// GitLab MR IDs cannot collide with PR IDs coming from other providers.
+		importedPRs, err := p.sourceControlAPI.GetPullRequests(ctx, &internaltypes.PullRequestParams{
+			ProviderIDs:    mrIds,
+			OrganizationID: &config.OrganizationID,
+			RepositoryName: repoName,
+		})
+		if err != nil {
+			return counts, fmt.Errorf("failed to fetch imported pull requests for %s: %w", repo, err)
+		}
+
+		importedPRsMap := make(map[string]internaltypes.PullRequest)
+		for _, pr := range importedPRs {
+			importedPRsMap[pr.ProviderID] = *pr
+		}
+
+		// 3. Process each MR
+		for _, mr := range mrs {
+			// Ignore MRs excluded by the repository sync rules
+			if !repoConfig.ShouldSync(types.RepositoryPullRequest{
+				BaseBranch: mr.TargetBranch,
+				Draft:      mr.Draft,
+				Labels:     mr.Labels,
+				Author:     mr.Author.Username,
+				UpdatedAt:  mr.UpdatedAt,
+			}, now) {
+				continue
+			}
+
+			status := convertState(mr.State)
+
+			// Skip MRs which are already imported and closed
+			existingPR, exists := importedPRsMap[fmt.Sprintf("%d", mr.ID)]
+			if exists {
+				if existingPR.Status != "open" && existingPR.Status == status {
+					continue
+				}
+			}
+
+			// Get detailed MR information
+			mrDetails, err := p.gitlabClient.GetMergeRequest(ctx, baseURL, repo, token, mr.IID)
+			if err != nil {
+				return counts, fmt.Errorf("failed to fetch merge request details for MR !%d: %w", mr.IID, err)
+			}
+
+			// GitLab does not expose line counts on the MR, so derive them from the diffs
+			changedFiles := gitlab.ParseChangesCount(mrDetails.ChangesCount)
+			diffs, err := p.gitlabClient.GetMergeRequestDiffs(ctx, baseURL, repo, token, mr.IID)
+			if err != nil {
+				// Log error but don't fail - line counts are optional
+				fmt.Printf("Warning: failed to fetch diffs for MR !%d: %v\n", mr.IID, err)
+			} else {
+				for _, diff := range diffs {
+					additions, deletions := gitlab.CountDiffLines(diff.Diff)
+					mrDetails.Additions += additions
+					mrDetails.Deletions += deletions
+				}
+				if changedFiles == 0 {
+					changedFiles = len(diffs)
+				}
+			}
+
+			// 4. Insert/Update author
+			authorAccount, err := p.upsertAuthor(ctx, config.OrganizationID, mrDetails.Author)
+			if err != nil {
+				return counts, fmt.Errorf("failed to upsert author for MR !%d: %w", mr.IID, err)
+			}
+
+			// 5. Insert/Update PR
+			mrDetailsBytes, _ := json.Marshal(mrDetails)
+
+			labelsBytes, _ := json.Marshal(mrDetails.Labels)
+
+			// Attribute the MR to a team, commits are only needed by ticket prefix rules
+			var commitMessages []string
+			if attributor.Uses(teamtypes.AttributionRuleTicketPrefix) {
+				commits, err := p.gitlabClient.GetMergeRequestCommits(ctx, baseURL, repo, token, mr.IID)
+				if err != nil {
+					// Log error but don't fail - commit fetching is optional
+					fmt.Printf("Warning: failed to fetch commits for MR !%d: %v\n", mr.IID, err)
+				}
+				commitMessages = lo.Map(commits, func(commit *gitlabtypes.Commit, _ int) string { return commit.Message })
+			}
+			attribution := attributor.Attribute(teamtypes.AttributionInput{
+				RepositoryName: repoName,
+				Title:          mrDetails.Title,
+				Branch:         mrDetails.SourceBranch,
+				Labels:         mrDetails.Labels,
+				Paths:          lo.Map(diffs, func(diff *gitlabtypes.Diff, _ int) string { return diff.NewPath }),
+				CommitMessages: commitMessages,
+			})
+			var teamID, matchedPrefix *string
+			if attribution != nil {
+				teamID = &attribution.TeamID
+				matchedPrefix = attribution.Prefix
+			}
+
+			sourceControlPR := &internaltypes.PullRequest{
+				ExternalAccountID: authorAccount.ID,
+				ProviderID:        fmt.Sprintf("%d", mrDetails.ID),
+				RepositoryName:    repoName,
+				Title:             mrDetails.Title,
+				Description:       mrDetails.Description,
+				Status:            convertState(mrDetails.State),
+				CreatedAt:         mrDetails.CreatedAt,
+				MergedAt:          mrDetails.MergedAt,
+				LastUpdatedAt:     mrDetails.UpdatedAt,
+				Comments:          mrDetails.UserNotesCount,
+				Additions:         mrDetails.Additions,
+				Deletions:         mrDetails.Deletions,
+				ChangedFiles:      changedFiles,
+				URL:               mrDetails.URL,
+				Prefix:            matchedPrefix,
+				TeamID:            teamID,
+				RepositoryID:      repositoryID,
+				HeadBranch:        mrDetails.SourceBranch,
+				Labels:            datatypes.JSON(labelsBytes),
+				Metadata:          datatypes.JSON(mrDetailsBytes),
+			}
+
+			if exists {
+				sourceControlPR.ID = existingPR.ID
+				err := p.sourceControlAPI.UpdatePullRequest(ctx, sourceControlPR)
+				if err != nil {
+					return counts, fmt.Errorf("failed to update pull request for MR !%d: %w", mr.IID, err)
+				}
+				// Updates skip nil fields, an MR which no longer matches any rule is detached from its team explicitly
+				if attribution == nil && (existingPR.TeamID != nil || existingPR.Prefix != nil) {
+					if err := p.sourceControlAPI.UpdatePullRequestTeams(ctx, []*internaltypes.PullRequestTeam{{PRID: existingPR.ID}}); err != nil {
+						return counts, fmt.Errorf("failed to update team of pull request for MR !%d: %w", mr.IID, err)
+					}
+				}
+			} else {
+				createdPR, err := p.sourceControlAPI.CreatePullRequest(ctx, sourceControlPR)
+				if err != nil {
+					return counts, fmt.Errorf("failed to create pull request for MR !%d: %w", mr.IID, err)
+				}
+				sourceControlPR.ID = createdPR.ID
+			}
+
+			counts.PullRequests++
+
+			// 6. Get all approvals, discussion notes and diff notes
+			existingComments, err := p.sourceControlAPI.GetPullRequestComments(ctx, sourceControlPR.ID)
+			if err != nil {
+				return counts, fmt.Errorf("failed to fetch comments for MR !%d: %w", mr.IID, err)
+			}
+
+			existingCommentsMap := make(map[string]internaltypes.PRComment)
+			for _, comment := range existingComments {
+				existingCommentsMap[comment.ProviderID] = *comment
+			}
+
+			allComments, err := p.fetchComments(ctx, baseURL, repo, token, mrDetails)
+			if err != nil {
+				return counts, err
+			}
+
+			// 7. Process each new comment
+			for _, comment := range allComments {
+				if _, exists := existingCommentsMap[comment.ProviderID]; exists {
+					continue
+				}
+
+				// Insert/Update comment author
+				commentAuthor, err := p.upsertAuthor(ctx, config.OrganizationID, comment.User)
+				if err != nil {
+					return counts, fmt.Errorf("failed to upsert comment author for MR !%d: %w", mr.IID, err)
+				}
+
+				updatedAt := comment.UpdatedAt
+				sourceControlComment := &internaltypes.PRComment{
+					PRID:              sourceControlPR.ID,
+					ExternalAccountID: commentAuthor.ID,
+					ProviderID:        comment.ProviderID,
+					Body:              comment.Body,
+					Type:              comment.Type,
+					CreatedAt:         comment.CreatedAt,
+					UpdatedAt:         &updatedAt,
+					ReviewState:       comment.ReviewState,
+				}
+
+				if err := p.sourceControlAPI.CreatePRComments(ctx, []*internaltypes.PRComment{sourceControlComment}); err != nil {
+					return counts, fmt.Errorf("failed to save comment for MR !%d: %w", mr.IID, err)
+				}
+				counts.Comments++
+			}
+
+			// Approvals which were revoked or given again since the last sync only change state
+			for _, approval := range changedApprovals(existingComments, allComments) {
+				if err := p.sourceControlAPI.UpdatePRComment(ctx, approval); err != nil {
+					return counts, fmt.Errorf("failed to update approval for MR !%d: %w", mr.IID, err)
+				}
+			}
+
+			// 8. Calculate PR metrics from the stored comments and reviews
+			if err := p.sourceControlAPI.RefreshPullRequestMetrics(ctx, sourceControlPR); err != nil {
+				return counts, fmt.Errorf("failed to save pull request metrics for MR !%d: %w", mr.IID, err)
+			}
+		}
+
+		// 9. Move the project cursor to the most recent update that was synced, MRs are listed most recent first
+		if len(mrs) > 0 {
+			if err := p.integrationAPI.UpdateRepositorySyncCursor(ctx, config.ID, repo, mrs[0].UpdatedAt); err != nil {
+				return counts, fmt.Errorf("failed to update sync cursor for %s: %w", repo, err)
+			}
+		}
+	}
+
+	return counts, nil
+}
+
+// fetchComments returns the approvals and the user notes (discussion and diff notes) of a merge request.
+// Approvals are stored as REVIEW comments, using the time of the matching "approved" system note.
+func (p *GitLabProvider) fetchComments(ctx context.Context, baseURL, repo, token string, mr *gitlabtypes.MergeRequest) ([]*syncedComment, error) {
+	discussions, err := p.gitlabClient.GetMergeRequestDiscussions(ctx, baseURL, repo, token, mr.IID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch discussions for MR !%d: %w", mr.IID, err)
+	}
+
+	approvals, err := p.gitlabClient.GetMergeRequestApprovals(ctx, baseURL, repo, token, mr.IID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch approvals for MR !%d: %w", mr.IID, err)
+	}
+
+	comments := []*syncedComment{}
+	approvedAt := make(map[int]time.Time)
+	for _, discussion := range discussions {
+		for _, note := range discussion.Notes {
+			if note.System {
+				// Keep the latest approval time per user, approvals can be revoked and given again
+				if note.Body == gitlabtypes.ApprovedNoteBody && note.CreatedAt.After(approvedAt[note.Author.ID]) {
+					approvedAt[note.Author.ID] = note.CreatedAt
+				}
+				continue
+			}
+
+			commentType := gitlabtypes.CommentTypeComment
+			if note.Type != nil && *note.Type == gitlabtypes.NoteTypeDiffNote {
+				commentType = gitlabtypes.CommentTypeReviewComment
+			}
+
+			comments = append(comments, &syncedComment{
+				ProviderID: fmt.Sprintf("%d", note.ID),
+				User:       note.Author,
+				Body:       note.Body,
+				Type:       string(commentType),
+				CreatedAt:  note.CreatedAt,
+				UpdatedAt:  note.UpdatedAt,
+			})
+		}
+	}
+
+	approved := internaltypes.ReviewStateApproved
+	for _, approval := range approvals.ApprovedBy {
+		createdAt, ok := approvedAt[approval.User.ID]
+		if !ok {
+			// The system note can be missing (e.g. deleted), fall back to the last MR update
+			createdAt = mr.UpdatedAt
+		}
+
+		comments = append(comments, &syncedComment{
+			ProviderID:  fmt.Sprintf("%s%d", approvalProviderIDPrefix, approval.User.ID),
+			User:        approval.User,
+			Type:        string(gitlabtypes.CommentTypeReview),
+			CreatedAt:   createdAt,
+			UpdatedAt:   createdAt,
+			ReviewState: &approved,
+		})
+	}
+
+	return comments, nil
+}
+
+// changedApprovals returns the stored approvals whose state differs from the current approvals of the merge
+// request: approvals missing from them were revoked and are dismissed, dismissed approvals which are back were
+// given again and are approved at their new time.
+func changedApprovals(existing []*internaltypes.PRComment, comments []*syncedComment) []*internaltypes.PRComment {
+	current := make(map[string]*syncedComment)
+	for _, comment := range comments {
+		if strings.HasPrefix(comment.ProviderID, approvalProviderIDPrefix) {
+			current[comment.ProviderID] = comment
+		}
+	}
+
+	changed := []*internaltypes.PRComment{}
+	for _, stored := range existing {
+		if !strings.HasPrefix(stored.ProviderID, approvalProviderIDPrefix) {
+			continue
+		}
+		approved := stored.ReviewState != nil && *stored.ReviewState == internaltypes.ReviewStateApproved
+
+		approval, ok := current[stored.ProviderID]
+		switch {
+		case !ok && approved:
+			dismissed := internaltypes.ReviewStateDismissed
+			changed = append(changed, &internaltypes.PRComment{ID: stored.ID, ReviewState: &dismissed})
+		case ok && !approved:
+			updatedAt := approval.UpdatedAt
+			changed = append(changed, &internaltypes.PRComment{
+				ID:          stored.ID,
+				CreatedAt:   approval.CreatedAt,
+				UpdatedAt:   &updatedAt,
+				ReviewState: approval.ReviewState,
+			})
+		}
+	}
+
+	return changed
+}
+
+// upsertAuthor handles the creation or update of an external account (source control)
+// Returns a SourceControlAccount type for backward compatibility with existing code
+func (p *GitLabProvider) upsertAuthor(ctx context.Context, organizationID string, user gitlabtypes.User) (*internaltypes.SourceControlAccount, error) {
+	p.authorMu.Lock()
+	defer p.authorMu.Unlock()
+
+	// Check if account exists
+	sourceControlType := string(membertypes.ExternalAccountTypeSourceControl)
+	accounts, err := p.memberAPI.GetExternalAccounts(ctx, &membertypes.ExternalAccountParams{
+		OrganizationID: organizationID,
+		Usernames:      []string{user.Username},
+		AccountType:    &sourceControlType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch external account: %w", err)
+	}
+
+	for _, account := range accounts {
+		if account.ProviderName == p.Name() && account.Username == user.Username {
+			return convertExternalAccountToSourceControlAccount(&account), nil
+		}
+	}
+
+	// Create the account without member_id and let the application handle the association later
+	metadata, _ := json.Marshal(accountMetadata(user))
+	newAccount := &membertypes.ExternalAccount{
+		AccountType:    sourceControlType,
+		ProviderName:   p.Name(),
+		OrganizationID: &organizationID,
+		Username:       user.Username,
+		ProviderID:     fmt.Sprintf("%d", user.ID),
+		Metadata:       datatypes.JSON(metadata),
+	}
+
+	if err := p.memberAPI.CreateExternalAccounts(ctx, []*membertypes.ExternalAccount{newAccount}); err != nil {
+		return nil, fmt.Errorf("failed to create external account: %w", err)
+	}
+
+	return convertExternalAccountToSourceControlAccount(newAccount), nil
+}
+
+// accountMetadata builds the external account metadata for a GitLab user. It includes a GitHub style
+// "type" field ("User" or "Bot"), which flags the bot accounts when they are created.
+func accountMetadata(user gitlabtypes.User) map[string]interface{} {
+	accountType := "User"
+	if user.IsBot() {
+		accountType = "Bot"
+	}
+
+	return map[string]interface{}{
+		"id":         user.ID,
+		"login":      user.Username,
+		"name":       user.Name,
+		"avatar_url": user.AvatarURL,
+		"web_url":    user.WebURL,
+		"type":       accountType,
+	}
+}
+
+// convertState maps GitLab MR states onto the GitHub style statuses used by the metrics queries,
+// where a merged PR is "closed" with a merged_at timestamp
+func convertState(state string) string {
+	switch state {
+	case "merged", "closed":
+		return "closed"
+	default:
+		return "open"
+	}
+}
+
+// convertExternalAccountToSourceControlAccount converts an ExternalAccount to SourceControlAccount
+func convertExternalAccountToSourceControlAccount(account *membertypes.ExternalAccount) *internaltypes.SourceControlAccount {
+	return &internaltypes.SourceControlAccount{
+		ID:             account.ID,
+		MemberID:       account.MemberID,
+		OrganizationID: account.OrganizationID,
+		ProviderName:   account.ProviderName,
+		ProviderID:     account.ProviderID,
+		Username:       account.Username,
+		Metadata:       account.Metadata,
+		IsBot:          account.IsBot,
+		LastSyncedAt:   account.LastSyncedAt,
+	}
+}
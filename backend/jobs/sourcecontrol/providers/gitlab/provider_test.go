@@ -0,0 +1,207 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"ems.dev/backend/libraries/gitlab"
+	gitlabtypes "ems.dev/backend/libraries/gitlab/types"
+	internaltypes "ems.dev/backend/services/sourcecontrol/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockGitlabClient is a mock of the GitLab client, only the comments of a merge request are implemented
+type MockGitlabClient struct {
+	gitlab.GitlabClient
+	mock.Mock
+}
+
+func (m *MockGitlabClient) GetMergeRequestDiscussions(ctx context.Context, baseURL, project, token string, mrIID int) ([]*gitlabtypes.Discussion, error) {
+	args := m.Called(ctx, baseURL, project, token, mrIID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*gitlabtypes.Discussion), args.Error(1)
+}
+
+func (m *MockGitlabClient) GetMergeRequestApprovals(ctx context.Context, baseURL, project, token string, mrIID int) (*gitlabtypes.Approvals, error) {
+	args := m.Called(ctx, baseURL, project, token, mrIID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gitlabtypes.Approvals), args.Error(1)
+}
+
+func TestFetchComments(t *testing.T) {
+	alice := gitlabtypes.User{ID: 1, Username: "alice"}
+	bob := gitlabtypes.User{ID: 2, Username: "bob"}
+	diffNote := gitlabtypes.NoteTypeDiffNote
+
+	day := func(d int) time.Time {
+		return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	type expectedComment struct {
+		providerID  string
+		username    string
+		commentType string
+		createdAt   time.Time
+		reviewState *string
+	}
+	approved := internaltypes.ReviewStateApproved
+
+	tests := []struct {
+		name           string
+		discussions    []*gitlabtypes.Discussion
+		approvals      *gitlabtypes.Approvals
+		discussionsErr error
+		approvalsErr   error
+		expected       []expectedComment
+		expectedError  string
+	}{
+		{
+			name: "notes and diff notes",
+			discussions: []*gitlabtypes.Discussion{
+				{Notes: []*gitlabtypes.Note{
+					{ID: 10, Author: alice, Body: "question", CreatedAt: day(1)},
+					{ID: 11, Author: bob, Body: "nit", Type: &diffNote, CreatedAt: day(2)},
+					{ID: 12, Author: bob, Body: "added 1 commit", System: true, CreatedAt: day(2)},
+				}},
+			},
+			approvals: &gitlabtypes.Approvals{},
+			expected: []expectedComment{
+				{providerID: "10", username: "alice", commentType: string(gitlabtypes.CommentTypeComment), createdAt: day(1)},
+				{providerID: "11", username: "bob", commentType: string(gitlabtypes.CommentTypeReviewComment), createdAt: day(2)},
+			},
+		},
+		{
+			name: "approvals are approved reviews dated by their latest system note",
+			discussions: []*gitlabtypes.Discussion{
+				{Notes: []*gitlabtypes.Note{
+					{ID: 20, Author: alice, Body: gitlabtypes.ApprovedNoteBody, System: true, CreatedAt: day(3)},
+					{ID: 21, Author: alice, Body: gitlabtypes.ApprovedNoteBody, System: true, CreatedAt: day(5)},
+					{ID: 22, Author: alice, Body: gitlabtypes.ApprovedNoteBody, System: true, CreatedAt: day(4)},
+				}},
+			},
+			approvals: &gitlabtypes.Approvals{Approved: true, ApprovedBy: []gitlabtypes.Approver{{User: alice}}},
+			expected: []expectedComment{
+				{providerID: "approval-1", username: "alice", commentType: string(gitlabtypes.CommentTypeReview), createdAt: day(5), reviewState: &approved},
+			},
+		},
+		{
+			name:      "approvals without a system note fall back to the last update",
+			approvals: &gitlabtypes.Approvals{Approved: true, ApprovedBy: []gitlabtypes.Approver{{User: bob}}},
+			expected: []expectedComment{
+				{providerID: "approval-2", username: "bob", commentType: string(gitlabtypes.CommentTypeReview), createdAt: day(9), reviewState: &approved},
+			},
+		},
+		{
+			name: "revoked approvals are not reviews",
+			discussions: []*gitlabtypes.Discussion{
+				{Notes: []*gitlabtypes.Note{
+					{ID: 30, Author: alice, Body: gitlabtypes.ApprovedNoteBody, System: true, CreatedAt: day(3)},
+				}},
+			},
+			approvals: &gitlabtypes.Approvals{},
+			expected:  []expectedComment{},
+		},
+		{
+			name:           "discussions error",
+			discussionsErr: errors.New("boom"),
+			expectedError:  "failed to fetch discussions for MR !7: boom",
+		},
+		{
+			name:          "approvals error",
+			approvalsErr:  errors.New("boom"),
+			expectedError: "failed to fetch approvals for MR !7: boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &MockGitlabClient{}
+			client.On("GetMergeRequestDiscussions", mock.Anything, "https://gitlab.example.com", "group/project", "token", 7).Return(tt.discussions, tt.discussionsErr)
+			client.On("GetMergeRequestApprovals", mock.Anything, "https://gitlab.example.com", "group/project", "token", 7).Return(tt.approvals, tt.approvalsErr).Maybe()
+
+			provider := &GitLabProvider{gitlabClient: client}
+			mr := &gitlabtypes.MergeRequest{IID: 7, UpdatedAt: day(9)}
+
+			comments, err := provider.fetchComments(context.Background(), "https://gitlab.example.com", "group/project", "token", mr)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+
+			sort.Slice(comments, func(i, j int) bool { return comments[i].ProviderID < comments[j].ProviderID })
+
+			actual := make([]expectedComment, 0, len(comments))
+			for _, comment := range comments {
+				actual = append(actual, expectedComment{
+					providerID:  comment.ProviderID,
+					username:    comment.User.Username,
+					commentType: comment.Type,
+					createdAt:   comment.CreatedAt,
+					reviewState: comment.ReviewState,
+				})
+			}
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestChangedApprovals(t *testing.T) {
+	approved := internaltypes.ReviewStateApproved
+	dismissed := internaltypes.ReviewStateDismissed
+	reapprovedAt := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	stored := func(id, providerID string, reviewState *string) *internaltypes.PRComment {
+		return &internaltypes.PRComment{ID: id, ProviderID: providerID, ReviewState: reviewState}
+	}
+
+	tests := []struct {
+		name     string
+		existing []*internaltypes.PRComment
+		comments []*syncedComment
+		expected []*internaltypes.PRComment
+	}{
+		{
+			name:     "approvals which are still given",
+			existing: []*internaltypes.PRComment{stored("c1", "approval-1", &approved)},
+			comments: []*syncedComment{{ProviderID: "approval-1", ReviewState: &approved}},
+			expected: []*internaltypes.PRComment{},
+		},
+		{
+			name:     "revoked approvals are dismissed",
+			existing: []*internaltypes.PRComment{stored("c1", "approval-1", &approved), stored("c2", "approval-2", &approved)},
+			comments: []*syncedComment{{ProviderID: "approval-2", ReviewState: &approved}},
+			expected: []*internaltypes.PRComment{{ID: "c1", ReviewState: &dismissed}},
+		},
+		{
+			name:     "dismissed approvals stay dismissed",
+			existing: []*internaltypes.PRComment{stored("c1", "approval-1", &dismissed)},
+			expected: []*internaltypes.PRComment{},
+		},
+		{
+			name:     "approvals given again are approved at their new time",
+			existing: []*internaltypes.PRComment{stored("c1", "approval-1", &dismissed)},
+			comments: []*syncedComment{{ProviderID: "approval-1", CreatedAt: reapprovedAt, UpdatedAt: reapprovedAt, ReviewState: &approved}},
+			expected: []*internaltypes.PRComment{{ID: "c1", CreatedAt: reapprovedAt, UpdatedAt: &reapprovedAt, ReviewState: &approved}},
+		},
+		{
+			name:     "notes are not approvals",
+			existing: []*internaltypes.PRComment{stored("c1", "10", nil)},
+			expected: []*internaltypes.PRComment{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, changedApprovals(tt.existing, tt.comments))
+		})
+	}
+}
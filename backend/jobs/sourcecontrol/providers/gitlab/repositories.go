@@ -0,0 +1,42 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"ems.dev/backend/services/integration/types"
+	internaltypes "ems.dev/backend/services/sourcecontrol/types"
+)
+
+// syncRepository fetches the details of a project and saves it as a repository, returns the ID of the saved
+// repository. Projects whose details cannot be fetched are saved by namespace and path, keeping the details
+// saved before.
+func (p *GitLabProvider) syncRepository(ctx context.Context, config *types.IntegrationConfig, baseURL, repo, token string) (*string, error) {
+	repository := &internaltypes.Repository{
+		OrganizationID: config.OrganizationID,
+		Provider:       p.Name(),
+		Owner:          path.Dir(repo),
+		Name:           path.Base(repo),
+	}
+
+	project, err := p.gitlabClient.GetProject(ctx, baseURL, repo, token)
+	if err != nil {
+		fmt.Printf("Warning: failed to fetch project %s: %v\n", repo, err)
+	} else {
+		repository.Owner = project.Namespace.FullPath
+		repository.Name = project.Path
+		repository.Archived = project.Archived
+		repository.Visibility = &project.Visibility
+		// Empty projects have no default branch
+		if project.DefaultBranch != "" {
+			repository.DefaultBranch = &project.DefaultBranch
+		}
+	}
+
+	if err := p.sourceControlAPI.UpsertRepository(ctx, repository); err != nil {
+		return nil, fmt.Errorf("failed to save repository %s: %w", repo, err)
+	}
+
+	return &repository.ID, nil
+}
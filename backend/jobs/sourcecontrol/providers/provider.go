@@ -2,6 +2,7 @@ package providers
 
 import (
 	"context"
+	"time"
 
 	"ems.dev/backend/services/integration/types"
 )
@@ -10,6 +11,15 @@ import (
 type SourceControlProvider interface {
 	// Name returns the unique identifier for this source control provider
 	Name() string
-	// SyncRepositories fetches and syncs data for the given repositories
-	SyncRepositories(ctx context.Context, config *types.IntegrationConfig, repositories []string) error
+	// SyncRepositories fetches and syncs data for the given repositories, skipping the pull requests
+	// excluded by their sync rules. Returns the number of pull requests and comments upserted, also
+	// when the sync fails part way.
+	SyncRepositories(ctx context.Context, config *types.IntegrationConfig, repositories []types.RepositoryConfig) (types.SyncCounts, error)
+}
+
+// RepositoryBackfiller is implemented by the providers which can import the history of a repository
+type RepositoryBackfiller interface {
+	// BackfillRepository imports the pull requests of the repository created from from (inclusive) to to
+	// (exclusive). Returns the number of pull requests and comments upserted, also when it fails part way.
+	BackfillRepository(ctx context.Context, config *types.IntegrationConfig, repository types.RepositoryConfig, from, to time.Time) (types.SyncCounts, error)
 }
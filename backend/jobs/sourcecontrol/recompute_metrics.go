@@ -0,0 +1,121 @@
+package sourcecontrol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ems.dev/backend/jobs/synclock"
+	intapi "ems.dev/backend/services/integration/api"
+	inttypes "ems.dev/backend/services/integration/types"
+	orgapi "ems.dev/backend/services/organization/api"
+	sourcecontrolapi "ems.dev/backend/services/sourcecontrol/api"
+	"ems.dev/backend/services/sourcecontrol/types"
+)
+
+// MetricsRecomputeJob rebuilds the metrics of the pull requests which were stored with an older version of the
+// metrics calculation, from their stored comments, reviews, timeline events and commits. Every run recomputes at
+// most BatchSize pull requests, so that bumping the version doesn't hold a replica for the whole history.
+type MetricsRecomputeJob struct {
+	integrationAPI   intapi.IntegrationAPI
+	orgAPI           orgapi.OrganizationAPI
+	sourceControlAPI sourcecontrolapi.SourceControlAPI
+	locker           *synclock.Locker
+	batchSize        int
+}
+
+func NewMetricsRecomputeJob(integrationAPI intapi.IntegrationAPI, orgAPI orgapi.OrganizationAPI, sourceControlAPI sourcecontrolapi.SourceControlAPI, locker *synclock.Locker, batchSize int) *MetricsRecomputeJob {
+	return &MetricsRecomputeJob{
+		integrationAPI:   integrationAPI,
+		orgAPI:           orgAPI,
+		sourceControlAPI: sourceControlAPI,
+		locker:           locker,
+		batchSize:        batchSize,
+	}
+}
+
+// Queue marks the metrics of the selected pull requests as outdated. They are recomputed by the next runs of the
+// job, on whichever replica runs the scheduled jobs.
+func (j *MetricsRecomputeJob) Queue(ctx context.Context, params *types.RecomputeMetricsParams) (*types.RecomputeMetricsResult, error) {
+	return j.sourceControlAPI.MarkPullRequestMetricsOutdated(ctx, params)
+}
+
+// Run recomputes the metrics of a batch of outdated pull requests, one organization after the other. The pull
+// requests of an organization are recomputed while holding the sync leases of its source control integrations, so
+// that a sync storing fresher metrics isn't overwritten by the recompute. Organizations being synced are skipped
+// until the next run.
+func (j *MetricsRecomputeJob) Run(ctx context.Context) error {
+	orgs, err := j.orgAPI.GetOrganizations(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get organizations: %w", err)
+	}
+
+	recomputed, failed := 0, 0
+	for _, org := range orgs {
+		if recomputed >= j.batchSize {
+			break
+		}
+
+		count, err := j.recomputeOrganization(ctx, org.ID, j.batchSize-recomputed)
+		if errors.Is(err, synclock.ErrLocked) {
+			fmt.Printf("Skipping the metrics recompute of org %s, it is being synced\n", org.ID)
+			continue
+		}
+		if err != nil {
+			fmt.Printf("Failed to recompute pull request metrics for org %s: %v\n", org.ID, err)
+			failed++
+			continue
+		}
+		recomputed += count
+	}
+
+	if recomputed > 0 {
+		fmt.Printf("Recomputed the metrics of %d pull requests\n", recomputed)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to recompute pull request metrics of %d organizations", failed)
+	}
+	return nil
+}
+
+// recomputeOrganization recomputes the metrics of at most limit outdated pull requests of an organization. Returns
+// synclock.ErrLocked when one of its source control integrations is being synced.
+func (j *MetricsRecomputeJob) recomputeOrganization(ctx context.Context, orgID string, limit int) (int, error) {
+	integrations, err := j.integrationAPI.GetOrganizationIntegrationConfigs(ctx, orgID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get integrations: %w", err)
+	}
+
+	// Every lease cancels the context of the leases taken after it when lost, the last one is cancelled when any is
+	unlocks := []func(){}
+	defer func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}()
+	for _, integration := range integrations {
+		if integration.ProviderType != inttypes.IntegrationProviderTypeSourceControl {
+			continue
+		}
+
+		lockCtx, unlock, err := j.locker.Lock(ctx, integration.ID)
+		if err != nil {
+			return 0, err
+		}
+		unlocks = append(unlocks, unlock)
+		ctx = lockCtx
+	}
+
+	result, err := j.sourceControlAPI.RecomputePullRequestMetrics(ctx, &types.RecomputeMetricsParams{
+		OrganizationID: &orgID,
+		OutdatedOnly:   true,
+		Limit:          limit,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.PullRequests, nil
+}
@@ -0,0 +1,105 @@
+package sourcecontrol
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ems.dev/backend/jobs/synclock"
+	"ems.dev/backend/services/integration/types"
+	orgtypes "ems.dev/backend/services/organization/types"
+	sourcecontroltypes "ems.dev/backend/services/sourcecontrol/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMetricsRecomputeJobRun(t *testing.T) {
+	sourceControl := types.IntegrationConfig{ID: "integration-1", ProviderType: types.IntegrationProviderTypeSourceControl}
+	aiCodeAssistant := types.IntegrationConfig{ID: "integration-2", ProviderType: types.IntegrationProviderTypeAICodeAssistant}
+
+	tests := []struct {
+		name string
+		// integrations are the integrations of org-1, org-2 has none
+		integrations []types.IntegrationConfig
+		leaseFree    bool
+		// recomputed is the number of pull requests recomputed for each organization
+		recomputed     map[string]int
+		recomputeError error
+		// expectedLimits are the limits each organization was recomputed with
+		expectedLimits   map[string]int
+		expectedAcquires int
+		expectedReleases int
+		expectedError    bool
+	}{
+		{
+			name:             "organizations are recomputed under the leases of their source control integrations",
+			integrations:     []types.IntegrationConfig{sourceControl, aiCodeAssistant},
+			leaseFree:        true,
+			recomputed:       map[string]int{"org-1": 4, "org-2": 1},
+			expectedLimits:   map[string]int{"org-1": 10, "org-2": 6},
+			expectedAcquires: 1,
+			expectedReleases: 1,
+		},
+		{
+			name:             "organizations being synced are skipped",
+			integrations:     []types.IntegrationConfig{sourceControl},
+			leaseFree:        false,
+			recomputed:       map[string]int{"org-2": 1},
+			expectedLimits:   map[string]int{"org-2": 10},
+			expectedAcquires: 1,
+		},
+		{
+			name:             "stops at the batch size",
+			integrations:     []types.IntegrationConfig{sourceControl},
+			leaseFree:        true,
+			recomputed:       map[string]int{"org-1": 10},
+			expectedLimits:   map[string]int{"org-1": 10},
+			expectedAcquires: 1,
+			expectedReleases: 1,
+		},
+		{
+			name:             "failed recomputes fail the run after the other organizations",
+			integrations:     []types.IntegrationConfig{sourceControl},
+			leaseFree:        true,
+			recomputeError:   errors.New("db error"),
+			expectedLimits:   map[string]int{"org-1": 10, "org-2": 10},
+			expectedAcquires: 1,
+			expectedReleases: 1,
+			expectedError:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			integrationAPI := &MockIntegrationAPI{}
+			orgAPI := &MockOrganizationAPI{}
+			sourceControlAPI := &MockSourceControlAPI{}
+
+			orgAPI.On("GetOrganizations", mock.Anything).Return([]orgtypes.Organization{{ID: "org-1"}, {ID: "org-2"}}, nil)
+			integrationAPI.On("GetOrganizationIntegrationConfigs", mock.Anything, "org-1").Return(tt.integrations, nil)
+			integrationAPI.On("GetOrganizationIntegrationConfigs", mock.Anything, "org-2").Return([]types.IntegrationConfig{}, nil)
+			integrationAPI.On("AcquireSyncLease", mock.Anything, sourceControl.ID, "holder", time.Minute).Return(tt.leaseFree, nil)
+			integrationAPI.On("ReleaseSyncLease", mock.Anything, sourceControl.ID, "holder").Return(nil)
+
+			for orgID, limit := range tt.expectedLimits {
+				orgID, limit := orgID, limit
+				sourceControlAPI.On("RecomputePullRequestMetrics", mock.Anything, mock.MatchedBy(func(params *sourcecontroltypes.RecomputeMetricsParams) bool {
+					return *params.OrganizationID == orgID && params.Limit == limit && params.OutdatedOnly
+				})).Return(&sourcecontroltypes.RecomputeMetricsResult{PullRequests: tt.recomputed[orgID]}, tt.recomputeError).Once()
+			}
+
+			job := NewMetricsRecomputeJob(integrationAPI, orgAPI, sourceControlAPI, synclock.NewLocker(integrationAPI, "holder", time.Minute), 10)
+			err := job.Run(context.Background())
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			sourceControlAPI.AssertExpectations(t)
+			integrationAPI.AssertNumberOfCalls(t, "AcquireSyncLease", tt.expectedAcquires)
+			integrationAPI.AssertNumberOfCalls(t, "ReleaseSyncLease", tt.expectedReleases)
+		})
+	}
+}
@@ -2,11 +2,18 @@ package sourcecontrol
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"ems.dev/backend/jobs/scheduler"
 	"ems.dev/backend/jobs/sourcecontrol/providers"
+	"ems.dev/backend/jobs/synclock"
+	"ems.dev/backend/jobs/workerpool"
+	liberrors "ems.dev/backend/libraries/errors"
+	"ems.dev/backend/libraries/github"
 	intapi "ems.dev/backend/services/integration/api"
 	"ems.dev/backend/services/integration/types"
 	orgapi "ems.dev/backend/services/organization/api"
@@ -16,16 +23,39 @@ type SyncJob struct {
 	integrationAPI  intapi.IntegrationAPI
 	orgAPI          orgapi.OrganizationAPI
 	providerFactory providers.ProviderFactory
+	poolConfig      workerpool.Config
+	locker          *synclock.Locker
+	schedules       *scheduler.IntegrationSchedules
+	// background runs the manually triggered syncs
+	background *workerpool.Background
+
+	// pausedUntil holds the integrations which hit their provider's rate limit, by integration ID, with
+	// the time the limit resets. They are skipped until then and resume from their sync cursors.
+	pausedUntil map[string]time.Time
+	pausedMu    sync.Mutex
+
+	// running holds the IDs of the integrations being synced, by a scheduled run or a manual trigger
+	running   map[string]bool
+	runningMu sync.Mutex
 }
 
-func NewSyncJob(integrationAPI intapi.IntegrationAPI, orgAPI orgapi.OrganizationAPI, providerFactory providers.ProviderFactory) *SyncJob {
+func NewSyncJob(integrationAPI intapi.IntegrationAPI, orgAPI orgapi.OrganizationAPI, providerFactory providers.ProviderFactory, poolConfig workerpool.Config, locker *synclock.Locker, schedules *scheduler.IntegrationSchedules) *SyncJob {
 	return &SyncJob{
 		integrationAPI:  integrationAPI,
 		orgAPI:          orgAPI,
 		providerFactory: providerFactory,
+		poolConfig:      poolConfig,
+		locker:          locker,
+		schedules:       schedules,
+		background:      workerpool.NewBackground(),
+		pausedUntil:     make(map[string]time.Time),
+		running:         make(map[string]bool),
 	}
 }
 
+// Run syncs the source control integrations of every organization which are due according to their
+// schedule. Integrations are synced concurrently on a bounded worker pool, a failing or slow integration
+// doesn't hold back the others.
 func (j *SyncJob) Run(ctx context.Context) error {
 	// Get all organizations
 	orgs, err := j.orgAPI.GetOrganizations(ctx)
@@ -33,6 +63,8 @@ func (j *SyncJob) Run(ctx context.Context) error {
 		return fmt.Errorf("failed to get organizations: %w", err)
 	}
 
+	var synced, failed atomic.Int32
+	pool := workerpool.New(j.poolConfig.Integrations)
 	for _, org := range orgs {
 		// Get all integrations for the organization
 		integrations, err := j.integrationAPI.GetOrganizationIntegrationConfigs(ctx, org.ID)
@@ -47,39 +79,235 @@ func (j *SyncJob) Run(ctx context.Context) error {
 				continue
 			}
 
-			// Get provider implementation
-			provider, err := j.providerFactory.GetProvider(string(integration.ProviderName))
-			if err != nil {
-				fmt.Printf("Failed to create provider for %s: %v\n", integration.ProviderName, err)
+			if resetAt, paused := j.isPaused(integration.ID); paused {
+				fmt.Printf("Skipping integration %s, rate limited until %s\n", integration.ID, resetAt.Format(time.RFC3339))
 				continue
 			}
 
-			// Parse repositories from metadata
-			var metadata map[string]interface{}
-			if err := json.Unmarshal(integration.Metadata, &metadata); err != nil {
-				fmt.Printf("Failed to parse metadata for integration %s: %v\n", integration.ID, err)
+			if !j.schedules.Due(ctx, &integration, time.Now()) {
 				continue
 			}
 
-			reposStr, ok := metadata["repositories"].(string)
-			if !ok || reposStr == "" {
-				fmt.Printf("No repositories found in metadata for integration %s\n", integration.ID)
-				continue
+			integration := integration
+			pool.Go(ctx, integration.ID, func(ctx context.Context) {
+				if !j.startSync(integration.ID) {
+					fmt.Printf("Skipping integration %s, it is already being synced\n", integration.ID)
+					return
+				}
+				defer j.finishSync(integration.ID)
+
+				// Another replica may be syncing the integration
+				ctx, unlock, err := j.locker.Lock(ctx, integration.ID)
+				if errors.Is(err, synclock.ErrLocked) {
+					fmt.Printf("Skipping integration %s, it is being synced by another instance or backfilled\n", integration.ID)
+					j.schedules.Retry(integration.ID)
+					return
+				}
+				if err != nil {
+					fmt.Printf("Failed to lock integration %s: %v\n", integration.ID, err)
+					return
+				}
+				defer unlock()
+
+				synced.Add(1)
+				if !j.syncIntegration(ctx, &integration) {
+					failed.Add(1)
+				}
+			})
+		}
+	}
+	pool.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if failed.Load() > 0 {
+		return fmt.Errorf("%d of %d integrations failed to sync", failed.Load(), synced.Load())
+	}
+	return nil
+}
+
+// TriggerSync starts a sync of the integration in the background. Fails when the integration is already
+// being synced, by this or another instance, or is paused by its provider's rate limit.
+func (j *SyncJob) TriggerSync(integration *types.IntegrationConfig) error {
+	if resetAt, paused := j.isPaused(integration.ID); paused {
+		return liberrors.NewConflictError(fmt.Sprintf("integration is rate limited until %s", resetAt.Format(time.RFC3339)))
+	}
+
+	if !j.startSync(integration.ID) {
+		return liberrors.NewConflictError("integration is already being synced")
+	}
+
+	lockCtx, unlock, err := j.locker.Lock(context.Background(), integration.ID)
+	if err != nil {
+		j.finishSync(integration.ID)
+		if errors.Is(err, synclock.ErrLocked) {
+			return liberrors.NewConflictError(err.Error())
+		}
+		return err
+	}
+
+	j.background.Go(lockCtx, integration.ID, func(ctx context.Context) {
+		defer j.finishSync(integration.ID)
+		defer unlock()
+		j.syncIntegration(ctx, integration)
+	})
+	return nil
+}
+
+// Shutdown waits for the manually triggered syncs, cancelling them when the context is done first
+func (j *SyncJob) Shutdown(ctx context.Context) error {
+	return j.background.Shutdown(ctx)
+}
+
+// syncIntegration syncs the repositories of an integration on the integration's own workers. Every
+// repository is synced with a timeout and recorded as a sync run, the last synced time is only updated
+// when all of them succeed. Returns whether they did.
+func (j *SyncJob) syncIntegration(ctx context.Context, integration *types.IntegrationConfig) bool {
+	// Get provider implementation
+	provider, err := j.providerFactory.GetProvider(string(integration.ProviderName))
+	if err != nil {
+		fmt.Printf("Failed to create provider for %s: %v\n", integration.ProviderName, err)
+		j.recordFailedRun(integration.ID, err)
+		return false
+	}
+
+	// Parse repositories from metadata
+	repositories, err := types.ParseRepositoryConfigs(integration.Metadata)
+	if err != nil {
+		fmt.Printf("Failed to parse metadata for integration %s: %v\n", integration.ID, err)
+		j.recordFailedRun(integration.ID, err)
+		return false
+	}
+
+	// An integration without repositories has nothing to sync, which is not a failure
+	if len(repositories) == 0 {
+		fmt.Printf("No repositories found in metadata for integration %s\n", integration.ID)
+		return true
+	}
+
+	// Repositories share the integration's token, a rate limit stops the ones which didn't start yet
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	syncStartedAt := time.Now()
+	var failedMu sync.Mutex
+	failed := false
+
+	pool := workerpool.New(j.poolConfig.RepositoriesPerIntegration)
+	for _, repository := range repositories {
+		repository := repository
+		pool.Go(ctx, repository.Name, func(ctx context.Context) {
+			if j.poolConfig.Timeout > 0 {
+				var cancelRepository context.CancelFunc
+				ctx, cancelRepository = context.WithTimeout(ctx, j.poolConfig.Timeout)
+				defer cancelRepository()
 			}
 
-			repositories := strings.Split(reposStr, ",")
-			for i := range repositories {
-				repositories[i] = strings.TrimSpace(repositories[i])
+			run, runErr := j.integrationAPI.StartSyncRun(ctx, integration.ID, &repository.Name)
+			if runErr != nil {
+				fmt.Printf("Failed to record sync run of repository %s for integration %s: %v\n", repository.Name, integration.ID, runErr)
 			}
 
-			// Sync repositories
-			// TODO: Move this to run in a go routine
-			if err := provider.SyncRepositories(ctx, &integration, repositories); err != nil {
-				fmt.Printf("Failed to sync repositories for integration %s: %v\n", integration.ID, err)
-				continue
+			counts, err := provider.SyncRepositories(ctx, integration, []types.RepositoryConfig{repository})
+			j.finishSyncRun(run, counts, err)
+			if err == nil {
+				return
 			}
-		}
+
+			failedMu.Lock()
+			failed = true
+			failedMu.Unlock()
+
+			if rateLimitErr, ok := github.IsRateLimitError(err); ok {
+				j.pause(integration.ID, rateLimitErr.ResetAt)
+				cancel()
+				fmt.Printf("Pausing sync of integration %s until %s: %v\n", integration.ID, rateLimitErr.ResetAt.Format(time.RFC3339), err)
+				return
+			}
+			fmt.Printf("Failed to sync repository %s for integration %s: %v\n", repository.Name, integration.ID, err)
+		})
 	}
+	pool.Wait()
 
-	return nil
+	if failed || ctx.Err() != nil {
+		return false
+	}
+
+	if err := j.integrationAPI.UpdateLastSyncedAt(ctx, integration.ID, syncStartedAt); err != nil {
+		fmt.Printf("Failed to update last synced at for integration %s: %v\n", integration.ID, err)
+		return false
+	}
+	return true
+}
+
+// finishSyncRun records the outcome of a sync run. Recorded even when the sync timed out, so it doesn't
+// use the sync's context.
+func (j *SyncJob) finishSyncRun(run *types.SyncRun, counts types.SyncCounts, err error) {
+	if run == nil {
+		return
+	}
+
+	status := types.SyncRunStatusSucceeded
+	if _, ok := github.IsRateLimitError(err); ok {
+		status = types.SyncRunStatusRateLimited
+	} else if err != nil {
+		status = types.SyncRunStatusFailed
+	}
+
+	if finishErr := j.integrationAPI.FinishSyncRun(context.Background(), run, status, counts, err); finishErr != nil {
+		fmt.Printf("Failed to record outcome of sync run %s: %v\n", run.ID, finishErr)
+	}
+}
+
+// recordFailedRun records a sync of an integration which failed before any of its repositories were synced
+func (j *SyncJob) recordFailedRun(integrationID string, err error) {
+	run, runErr := j.integrationAPI.StartSyncRun(context.Background(), integrationID, nil)
+	if runErr != nil {
+		fmt.Printf("Failed to record sync run for integration %s: %v\n", integrationID, runErr)
+		return
+	}
+	j.finishSyncRun(run, types.SyncCounts{}, err)
+}
+
+// startSync marks the integration as being synced. Returns false if it already is.
+func (j *SyncJob) startSync(integrationID string) bool {
+	j.runningMu.Lock()
+	defer j.runningMu.Unlock()
+
+	if j.running[integrationID] {
+		return false
+	}
+	j.running[integrationID] = true
+	return true
+}
+
+func (j *SyncJob) finishSync(integrationID string) {
+	j.runningMu.Lock()
+	defer j.runningMu.Unlock()
+
+	delete(j.running, integrationID)
+}
+
+// isPaused returns the time the integration's rate limit resets if it is still paused
+func (j *SyncJob) isPaused(integrationID string) (time.Time, bool) {
+	j.pausedMu.Lock()
+	defer j.pausedMu.Unlock()
+
+	resetAt, paused := j.pausedUntil[integrationID]
+	if !paused {
+		return time.Time{}, false
+	}
+	if time.Now().After(resetAt) {
+		delete(j.pausedUntil, integrationID)
+		return time.Time{}, false
+	}
+	return resetAt, true
+}
+
+func (j *SyncJob) pause(integrationID string, resetAt time.Time) {
+	j.pausedMu.Lock()
+	defer j.pausedMu.Unlock()
+
+	j.pausedUntil[integrationID] = resetAt
 }
@@ -0,0 +1,71 @@
+package sourcecontrol
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ems.dev/backend/jobs/workerpool"
+	"ems.dev/backend/services/integration/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/datatypes"
+)
+
+func TestSyncIntegration(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata datatypes.JSON
+		syncErr  error
+		// expectedSyncs is the number of repositories synced by the provider
+		expectedSyncs int
+		expected      bool
+	}{
+		{
+			name:     "no repositories is a successful no-op",
+			metadata: datatypes.JSON(`{}`),
+			expected: true,
+		},
+		{
+			name:     "empty repositories is a successful no-op",
+			metadata: datatypes.JSON(`{"repositories": ""}`),
+			expected: true,
+		},
+		{
+			name:          "every repository synced",
+			metadata:      datatypes.JSON(`{"repositories": "repo-a,repo-b"}`),
+			expectedSyncs: 2,
+			expected:      true,
+		},
+		{
+			name:          "failed repository fails the sync",
+			metadata:      datatypes.JSON(`{"repositories": "repo-a"}`),
+			syncErr:       errors.New("boom"),
+			expectedSyncs: 1,
+			expected:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			integrationAPI := &MockIntegrationAPI{}
+			providerFactory := &MockProviderFactory{}
+			provider := &MockProvider{}
+
+			integration := &types.IntegrationConfig{ID: "integration-1", ProviderName: types.IntegrationProviderGithub, Metadata: tt.metadata}
+			providerFactory.On("GetProvider", "github").Return(provider, nil)
+			provider.On("SyncRepositories", mock.Anything, integration, mock.Anything).Return(types.SyncCounts{}, tt.syncErr)
+			integrationAPI.On("StartSyncRun", mock.Anything, integration.ID, mock.Anything).Return(&types.SyncRun{ID: "run-1"}, nil)
+			integrationAPI.On("FinishSyncRun", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			integrationAPI.On("UpdateLastSyncedAt", mock.Anything, integration.ID, mock.Anything).Return(nil)
+
+			job := NewSyncJob(integrationAPI, nil, providerFactory, workerpool.Config{Integrations: 1, RepositoriesPerIntegration: 1}, nil, nil)
+
+			assert.Equal(t, tt.expected, job.syncIntegration(context.Background(), integration))
+			provider.AssertNumberOfCalls(t, "SyncRepositories", tt.expectedSyncs)
+			if tt.expectedSyncs == 0 {
+				integrationAPI.AssertNotCalled(t, "StartSyncRun", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
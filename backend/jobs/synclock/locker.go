@@ -0,0 +1,117 @@
+package synclock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	intapi "ems.dev/backend/services/integration/api"
+)
+
+// ErrLocked is returned when another replica is syncing the integration
+var ErrLocked = errors.New("integration is being synced by another instance")
+
+// Locker holds the sync leases of integrations, so each integration is synced by one replica at a time.
+// Leases are renewed while the sync runs and expire when a replica dies mid-run.
+type Locker struct {
+	integrationAPI intapi.IntegrationAPI
+	holder         string
+	ttl            time.Duration
+}
+
+// NewLocker creates a locker taking leases as holder, which must be unique per replica
+func NewLocker(integrationAPI intapi.IntegrationAPI, holder string, ttl time.Duration) *Locker {
+	return &Locker{
+		integrationAPI: integrationAPI,
+		holder:         holder,
+		ttl:            ttl,
+	}
+}
+
+// NewHolder returns a holder name unique to this process, prefixed with the hostname for debugging
+func NewHolder() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+// Lock acquires the sync lease of the integration and renews it until unlock is called. The returned
+// context is cancelled when the lease is lost, so the sync stops before another replica takes over.
+// Returns ErrLocked if another replica holds the lease.
+func (l *Locker) Lock(ctx context.Context, integrationID string) (context.Context, func(), error) {
+	acquired, err := l.integrationAPI.AcquireSyncLease(ctx, integrationID, l.holder, l.ttl)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to acquire sync lease: %w", err)
+	}
+	if !acquired {
+		return nil, nil, ErrLocked
+	}
+
+	lockCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		l.renew(lockCtx, cancel, done, integrationID)
+	}()
+
+	var once sync.Once
+	unlock := func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+			cancel()
+
+			// Released even when the sync's context is done, otherwise the integration is stuck until the lease expires
+			if err := l.integrationAPI.ReleaseSyncLease(context.Background(), integrationID, l.holder); err != nil {
+				fmt.Printf("Failed to release sync lease of integration %s: %v\n", integrationID, err)
+			}
+		})
+	}
+
+	return lockCtx, unlock, nil
+}
+
+// renew extends the lease a few times per ttl. The sync is cancelled when the lease is lost, or when it
+// couldn't be renewed for so long that it may have expired.
+func (l *Locker) renew(ctx context.Context, cancel context.CancelFunc, done chan struct{}, integrationID string) {
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	renewedAt := time.Now()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			renewed, err := l.integrationAPI.RenewSyncLease(ctx, integrationID, l.holder, l.ttl)
+			if err != nil {
+				fmt.Printf("Failed to renew sync lease of integration %s: %v\n", integrationID, err)
+				if time.Since(renewedAt) < l.ttl {
+					continue
+				}
+			}
+			if err != nil || !renewed {
+				fmt.Printf("Lost sync lease of integration %s, stopping its sync\n", integrationID)
+				cancel()
+				return
+			}
+			renewedAt = time.Now()
+		}
+	}
+}
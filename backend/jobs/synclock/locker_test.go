@@ -0,0 +1,266 @@
+package synclock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	intapi "ems.dev/backend/services/integration/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ttl is short so the locker renews its leases several times per test
+const ttl = 30 * time.Millisecond
+
+type lease struct {
+	holder    string
+	expiresAt time.Time
+}
+
+// fakeLeases stores the sync leases in memory like the integration_sync_leases table does. Leases expire on a fake
+// clock which only moves when advanced, so they never expire while a test waits for renewals.
+type fakeLeases struct {
+	intapi.IntegrationAPI
+
+	mu         sync.Mutex
+	now        time.Time
+	leases     map[string]lease
+	renewals   int
+	acquireErr error
+	renewErr   error
+}
+
+func newFakeLeases() *fakeLeases {
+	return &fakeLeases{
+		now:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		leases: make(map[string]lease),
+	}
+}
+
+func (f *fakeLeases) AcquireSyncLease(ctx context.Context, integrationConfigID, holder string, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.acquireErr != nil {
+		return false, f.acquireErr
+	}
+	if current, ok := f.leases[integrationConfigID]; ok && !current.expiresAt.Before(f.now) {
+		return false, nil
+	}
+	f.leases[integrationConfigID] = lease{holder: holder, expiresAt: f.now.Add(ttl)}
+	return true, nil
+}
+
+func (f *fakeLeases) RenewSyncLease(ctx context.Context, integrationConfigID, holder string, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.renewErr != nil {
+		return false, f.renewErr
+	}
+	f.renewals++
+	if current, ok := f.leases[integrationConfigID]; !ok || current.holder != holder || current.expiresAt.Before(f.now) {
+		return false, nil
+	}
+	f.leases[integrationConfigID] = lease{holder: holder, expiresAt: f.now.Add(ttl)}
+	return true, nil
+}
+
+func (f *fakeLeases) ReleaseSyncLease(ctx context.Context, integrationConfigID, holder string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if current, ok := f.leases[integrationConfigID]; ok && current.holder == holder {
+		delete(f.leases, integrationConfigID)
+	}
+	return nil
+}
+
+func (f *fakeLeases) advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+func (f *fakeLeases) holder(integrationConfigID string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.leases[integrationConfigID].holder
+}
+
+func (f *fakeLeases) setHolder(integrationConfigID, holder string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.leases[integrationConfigID] = lease{holder: holder, expiresAt: f.now.Add(ttl)}
+}
+
+func (f *fakeLeases) setRenewErr(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.renewErr = err
+}
+
+func (f *fakeLeases) renewalCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.renewals
+}
+
+// assertCancelled fails the test unless ctx is cancelled within a few ttls
+func assertCancelled(t *testing.T, ctx context.Context) {
+	t.Helper()
+	select {
+	case <-ctx.Done():
+	case <-time.After(20 * ttl):
+		t.Fatal("context was not cancelled")
+	}
+}
+
+func TestLock(t *testing.T) {
+	tests := []struct {
+		name          string
+		acquireErr    error
+		heldBy        string
+		expectedError string
+	}{
+		{
+			name: "free integration",
+		},
+		{
+			// A replica doesn't sync an integration twice at a time either
+			name:          "lease of the same replica",
+			heldBy:        "replica-1",
+			expectedError: ErrLocked.Error(),
+		},
+		{
+			name:          "lease of another replica",
+			heldBy:        "replica-2",
+			expectedError: ErrLocked.Error(),
+		},
+		{
+			name:          "acquire error",
+			acquireErr:    errors.New("boom"),
+			expectedError: "failed to acquire sync lease: boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leases := newFakeLeases()
+			leases.acquireErr = tt.acquireErr
+			if tt.heldBy != "" {
+				leases.setHolder("integration-1", tt.heldBy)
+			}
+
+			lockCtx, unlock, err := NewLocker(leases, "replica-1", ttl).Lock(context.Background(), "integration-1")
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				assert.Nil(t, unlock)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "replica-1", leases.holder("integration-1"))
+
+			unlock()
+			assert.Error(t, lockCtx.Err())
+			assert.Equal(t, "", leases.holder("integration-1"))
+			// unlock can be called more than once
+			unlock()
+		})
+	}
+}
+
+func TestLockRenewsLease(t *testing.T) {
+	leases := newFakeLeases()
+	lockCtx, unlock, err := NewLocker(leases, "replica-1", ttl).Lock(context.Background(), "integration-1")
+	require.NoError(t, err)
+	defer unlock()
+
+	time.Sleep(3 * ttl)
+
+	assert.GreaterOrEqual(t, leases.renewalCount(), 3)
+	assert.NoError(t, lockCtx.Err())
+	assert.Equal(t, "replica-1", leases.holder("integration-1"))
+}
+
+func TestLockCancelsSyncWhenLeaseIsLost(t *testing.T) {
+	leases := newFakeLeases()
+	lockCtx, unlock, err := NewLocker(leases, "replica-1", ttl).Lock(context.Background(), "integration-1")
+	require.NoError(t, err)
+
+	// Another replica took over, e.g. after this one was paused past the ttl
+	leases.setHolder("integration-1", "replica-2")
+	assertCancelled(t, lockCtx)
+
+	// Releasing doesn't drop the lease of the other replica
+	unlock()
+	assert.Equal(t, "replica-2", leases.holder("integration-1"))
+}
+
+func TestLockRenewalErrors(t *testing.T) {
+	t.Run("errors are retried within the ttl", func(t *testing.T) {
+		leases := newFakeLeases()
+		lockCtx, unlock, err := NewLocker(leases, "replica-1", ttl).Lock(context.Background(), "integration-1")
+		require.NoError(t, err)
+		defer unlock()
+
+		leases.setRenewErr(errors.New("boom"))
+		time.Sleep(ttl / 2)
+		leases.setRenewErr(nil)
+		time.Sleep(ttl)
+
+		assert.NoError(t, lockCtx.Err())
+	})
+
+	t.Run("the sync is cancelled once the lease may have expired", func(t *testing.T) {
+		leases := newFakeLeases()
+		lockCtx, unlock, err := NewLocker(leases, "replica-1", ttl).Lock(context.Background(), "integration-1")
+		require.NoError(t, err)
+		defer unlock()
+
+		leases.setRenewErr(errors.New("boom"))
+		assertCancelled(t, lockCtx)
+	})
+}
+
+func TestUnlockReleasesLeaseOfCancelledSync(t *testing.T) {
+	leases := newFakeLeases()
+	ctx, cancel := context.WithCancel(context.Background())
+	lockCtx, unlock, err := NewLocker(leases, "replica-1", ttl).Lock(ctx, "integration-1")
+	require.NoError(t, err)
+
+	cancel()
+	assertCancelled(t, lockCtx)
+
+	unlock()
+	assert.Equal(t, "", leases.holder("integration-1"))
+
+	_, unlock, err = NewLocker(leases, "replica-2", ttl).Lock(context.Background(), "integration-1")
+	require.NoError(t, err)
+	unlock()
+}
+
+func TestLockTakesOverExpiredLease(t *testing.T) {
+	leases := newFakeLeases()
+	// A replica which died mid-sync never releases its lease
+	_, err := leases.AcquireSyncLease(context.Background(), "integration-1", "replica-2", ttl)
+	require.NoError(t, err)
+
+	locker := NewLocker(leases, "replica-1", ttl)
+	_, _, err = locker.Lock(context.Background(), "integration-1")
+	assert.Equal(t, ErrLocked, err)
+
+	leases.advance(ttl + time.Millisecond)
+	_, unlock, err := locker.Lock(context.Background(), "integration-1")
+	require.NoError(t, err)
+	defer unlock()
+
+	assert.Equal(t, "replica-1", leases.holder("integration-1"))
+}
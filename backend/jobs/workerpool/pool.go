@@ -0,0 +1,123 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Config bounds the concurrency of a sync job
+type Config struct {
+	// Integrations is the number of integrations synced concurrently
+	Integrations int
+	// RepositoriesPerIntegration is the number of repositories of an integration synced concurrently.
+	// Each integration has its own workers, so a large integration can't starve the others.
+	RepositoriesPerIntegration int
+	// Timeout bounds the sync of a repository, or of a whole integration for providers without repositories
+	Timeout time.Duration
+}
+
+// Pool runs tasks on a bounded number of goroutines
+type Pool struct {
+	workers chan struct{}
+	wg      sync.WaitGroup
+}
+
+// New creates a pool running at most size tasks at a time
+func New(size int) *Pool {
+	if size < 1 {
+		size = 1
+	}
+	return &Pool{
+		workers: make(chan struct{}, size),
+	}
+}
+
+// Go runs the task as soon as a worker is free. Tasks are not started once the context is cancelled.
+// A panicking task is logged and doesn't affect the other tasks.
+func (p *Pool) Go(ctx context.Context, name string, task func(ctx context.Context)) {
+	select {
+	case <-ctx.Done():
+		return
+	case p.workers <- struct{}{}:
+	}
+	// select picks randomly when a worker is free and the context is cancelled at the same time
+	if ctx.Err() != nil {
+		<-p.workers
+		return
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer func() { <-p.workers }()
+
+		runTask(ctx, name, task)
+	}()
+}
+
+// Wait blocks until all started tasks are done
+func (p *Pool) Wait() {
+	p.wg.Wait()
+}
+
+// Background runs tasks outside of a pool, e.g. manually triggered syncs, and waits for them on shutdown
+type Background struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewBackground() *Background {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Background{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Go runs the task in the background, recovering from a panic like the pool's tasks. The task's context
+// is cancelled when ctx is done or when Shutdown gives up waiting.
+func (b *Background) Go(ctx context.Context, name string, task func(ctx context.Context)) {
+	taskCtx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(b.ctx, cancel)
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		defer stop()
+		defer cancel()
+
+		runTask(taskCtx, name, task)
+	}()
+}
+
+// Shutdown waits for the running tasks. They are cancelled when the context is done before they finish.
+func (b *Background) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func runTask(ctx context.Context, name string, task func(ctx context.Context)) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("Sync task %s panicked: %v\n%s\n", name, r, debug.Stack())
+		}
+	}()
+
+	task(ctx)
+}
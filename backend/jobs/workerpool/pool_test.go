@@ -0,0 +1,205 @@
+package workerpool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoolBoundsConcurrency(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int
+		expected int
+	}{
+		{name: "pool size", size: 2, expected: 2},
+		{name: "at least one worker", size: 0, expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := New(tt.size)
+			started := make(chan struct{})
+			release := make(chan struct{})
+			var running, maxRunning, done int32
+
+			// Go blocks while every worker is busy, so the tasks are queued from another goroutine
+			go func() {
+				for i := 0; i < 5; i++ {
+					pool.Go(context.Background(), "task", func(ctx context.Context) {
+						current := atomic.AddInt32(&running, 1)
+						for {
+							max := atomic.LoadInt32(&maxRunning)
+							if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+								break
+							}
+						}
+						started <- struct{}{}
+						<-release
+						atomic.AddInt32(&running, -1)
+						atomic.AddInt32(&done, 1)
+					})
+				}
+			}()
+
+			for i := 0; i < tt.expected; i++ {
+				<-started
+			}
+			select {
+			case <-started:
+				t.Fatal("more tasks started than the pool has workers")
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			close(release)
+			for i := tt.expected; i < 5; i++ {
+				<-started
+			}
+			pool.Wait()
+
+			assert.Equal(t, int32(tt.expected), atomic.LoadInt32(&maxRunning))
+			assert.Equal(t, int32(5), atomic.LoadInt32(&done))
+		})
+	}
+}
+
+func TestPoolCancellation(t *testing.T) {
+	t.Run("tasks are not started once the context is cancelled", func(t *testing.T) {
+		pool := New(1)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var ran atomic.Bool
+		pool.Go(ctx, "task", func(ctx context.Context) { ran.Store(true) })
+		pool.Wait()
+
+		assert.False(t, ran.Load())
+	})
+
+	t.Run("waiting for a worker stops when the context is cancelled", func(t *testing.T) {
+		pool := New(1)
+		release := make(chan struct{})
+		pool.Go(context.Background(), "busy", func(ctx context.Context) { <-release })
+
+		ctx, cancel := context.WithCancel(context.Background())
+		var ran atomic.Bool
+		queued := make(chan struct{})
+		go func() {
+			defer close(queued)
+			pool.Go(ctx, "queued", func(ctx context.Context) { ran.Store(true) })
+		}()
+
+		cancel()
+		<-queued
+		close(release)
+		pool.Wait()
+
+		assert.False(t, ran.Load())
+	})
+
+	t.Run("running tasks see the cancellation", func(t *testing.T) {
+		pool := New(1)
+		ctx, cancel := context.WithCancel(context.Background())
+		started := make(chan struct{})
+
+		var taskErr error
+		pool.Go(ctx, "task", func(ctx context.Context) {
+			close(started)
+			<-ctx.Done()
+			taskErr = ctx.Err()
+		})
+
+		<-started
+		cancel()
+		pool.Wait()
+
+		assert.Equal(t, context.Canceled, taskErr)
+	})
+}
+
+func TestPoolRecoversFromPanics(t *testing.T) {
+	pool := New(1)
+
+	var ran atomic.Bool
+	pool.Go(context.Background(), "panicking", func(ctx context.Context) { panic("boom") })
+	// The single worker is released by the panicking task
+	pool.Go(context.Background(), "task", func(ctx context.Context) { ran.Store(true) })
+	pool.Wait()
+
+	assert.True(t, ran.Load())
+}
+
+func TestBackgroundShutdown(t *testing.T) {
+	t.Run("waits for in-flight tasks", func(t *testing.T) {
+		background := NewBackground()
+		started := make(chan struct{})
+		release := make(chan struct{})
+
+		var done atomic.Bool
+		background.Go(context.Background(), "task", func(ctx context.Context) {
+			close(started)
+			<-release
+			done.Store(true)
+		})
+		<-started
+
+		shutdown := make(chan error)
+		go func() { shutdown <- background.Shutdown(context.Background()) }()
+
+		select {
+		case <-shutdown:
+			t.Fatal("shutdown returned before the task finished")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(release)
+		assert.NoError(t, <-shutdown)
+		assert.True(t, done.Load())
+	})
+
+	t.Run("cancels in-flight tasks when the context is done", func(t *testing.T) {
+		background := NewBackground()
+		started := make(chan struct{})
+
+		var taskErr error
+		background.Go(context.Background(), "task", func(ctx context.Context) {
+			close(started)
+			<-ctx.Done()
+			taskErr = ctx.Err()
+		})
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		assert.Equal(t, context.DeadlineExceeded, background.Shutdown(ctx))
+		assert.Equal(t, context.Canceled, taskErr)
+	})
+
+	t.Run("tasks are cancelled with their own context", func(t *testing.T) {
+		background := NewBackground()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		background.Go(ctx, "task", func(ctx context.Context) {
+			defer wg.Done()
+			<-ctx.Done()
+		})
+
+		cancel()
+		wg.Wait()
+		assert.NoError(t, background.Shutdown(context.Background()))
+	})
+
+	t.Run("recovers from panics", func(t *testing.T) {
+		background := NewBackground()
+		background.Go(context.Background(), "panicking", func(ctx context.Context) { panic("boom") })
+
+		assert.NoError(t, background.Shutdown(context.Background()))
+	})
+}
@@ -0,0 +1,64 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"ems.dev/backend/libraries/bitbucket/types"
+)
+
+// BitbucketClient is implemented by both the Bitbucket Cloud and the Bitbucket Data Center clients.
+// Repositories are given as "workspace/repo_slug" on Cloud and "PROJECT_KEY/repo_slug" on Data Center.
+type BitbucketClient interface {
+	GetRepository(ctx context.Context, baseURL, repository, token string) (*types.Repository, error)
+	GetPullRequests(ctx context.Context, baseURL, repository, token string, maxPages int) ([]*types.PullRequest, error)
+	GetPullRequest(ctx context.Context, baseURL, repository, token string, prID int) (*types.PullRequest, error)
+	GetPullRequestActivity(ctx context.Context, baseURL, repository, token string, prID int) (*types.Activity, error)
+	GetPullRequestDiffStat(ctx context.Context, baseURL, repository, token string, prID int) (*types.DiffStat, error)
+	GetPullRequestCommits(ctx context.Context, baseURL, repository, token string, prID int) ([]*types.Commit, error)
+}
+
+// splitRepository splits a repository into its workspace (or project key) and slug
+func splitRepository(repository string) (string, string, error) {
+	parts := strings.Split(repository, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid repository format: %s. Expected format: workspace/repo", repository)
+	}
+	return parts[0], parts[1], nil
+}
+
+// get performs an authenticated GET request and decodes the JSON response into out.
+// Tokens in the "username:app_password" form are sent with basic auth, anything else as a bearer
+// token (repository, project or workspace access tokens and Data Center HTTP access tokens).
+func get(ctx context.Context, httpClient *http.Client, resourceURL, token string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", resourceURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if username, password, ok := strings.Cut(token, ":"); ok {
+		req.SetBasicAuth(username, password)
+	} else {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
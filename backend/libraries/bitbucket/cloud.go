@@ -0,0 +1,365 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"ems.dev/backend/libraries/bitbucket/types"
+)
+
+// DefaultCloudBaseURL is the base URL of the Bitbucket Cloud API
+const DefaultCloudBaseURL = "https://api.bitbucket.org"
+
+// CloudClient represents a Bitbucket Cloud (bitbucket.org) API client
+type CloudClient struct {
+	httpClient *http.Client
+}
+
+// NewCloudClient creates a new Bitbucket Cloud client
+func NewCloudClient() *CloudClient {
+	return &CloudClient{
+		httpClient: &http.Client{},
+	}
+}
+
+// cloudPage is the envelope of every paginated Bitbucket Cloud response
+type cloudPage[T any] struct {
+	Values []T    `json:"values"`
+	Next   string `json:"next"`
+}
+
+type cloudRepository struct {
+	Slug       string `json:"slug"`
+	Name       string `json:"name"`
+	IsPrivate  bool   `json:"is_private"`
+	MainBranch *struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+	Workspace struct {
+		Slug string `json:"slug"`
+	} `json:"workspace"`
+}
+
+type cloudUser struct {
+	UUID        string `json:"uuid"`
+	AccountID   string `json:"account_id"`
+	Nickname    string `json:"nickname"`
+	DisplayName string `json:"display_name"`
+	Type        string `json:"type"`
+}
+
+type cloudParticipant struct {
+	User     cloudUser `json:"user"`
+	Role     string    `json:"role"`
+	Approved bool      `json:"approved"`
+	State    *string   `json:"state"`
+}
+
+type cloudPullRequest struct {
+	ID           int       `json:"id"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	State        string    `json:"state"`
+	CreatedOn    time.Time `json:"created_on"`
+	UpdatedOn    time.Time `json:"updated_on"`
+	CommentCount int       `json:"comment_count"`
+	Draft        bool      `json:"draft"`
+	Author       cloudUser `json:"author"`
+	Source       struct {
+		Branch struct {
+			Name string `json:"name"`
+		} `json:"branch"`
+	} `json:"source"`
+	Destination struct {
+		Branch struct {
+			Name string `json:"name"`
+		} `json:"branch"`
+	} `json:"destination"`
+	Participants []cloudParticipant `json:"participants"`
+	Links        struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+}
+
+type cloudComment struct {
+	ID      int `json:"id"`
+	Content struct {
+		Raw string `json:"raw"`
+	} `json:"content"`
+	User      cloudUser   `json:"user"`
+	Inline    interface{} `json:"inline"`
+	Deleted   bool        `json:"deleted"`
+	CreatedOn time.Time   `json:"created_on"`
+	UpdatedOn time.Time   `json:"updated_on"`
+}
+
+type cloudReview struct {
+	Date time.Time `json:"date"`
+	User cloudUser `json:"user"`
+}
+
+type cloudActivity struct {
+	Comment          *cloudComment `json:"comment"`
+	Approval         *cloudReview  `json:"approval"`
+	ChangesRequested *cloudReview  `json:"changes_requested"`
+	Update           *struct {
+		State string    `json:"state"`
+		Date  time.Time `json:"date"`
+	} `json:"update"`
+}
+
+type cloudDiffStat struct {
+	LinesAdded   int `json:"lines_added"`
+	LinesRemoved int `json:"lines_removed"`
+}
+
+type cloudCommit struct {
+	Hash    string    `json:"hash"`
+	Message string    `json:"message"`
+	Date    time.Time `json:"date"`
+	Author  struct {
+		Raw string `json:"raw"`
+	} `json:"author"`
+}
+
+// GetRepository fetches the details of a repository
+func (c *CloudClient) GetRepository(ctx context.Context, baseURL, repository, token string) (*types.Repository, error) {
+	resourceURL, err := c.repositoryURL(baseURL, repository, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var repo cloudRepository
+	if err := get(ctx, c.httpClient, strings.TrimSuffix(resourceURL, "/"), token, &repo); err != nil {
+		return nil, err
+	}
+
+	result := &types.Repository{
+		Slug:    repo.Slug,
+		Name:    repo.Name,
+		Owner:   repo.Workspace.Slug,
+		Private: repo.IsPrivate,
+	}
+	// Empty repositories have no main branch
+	if repo.MainBranch != nil {
+		result.DefaultBranch = repo.MainBranch.Name
+	}
+
+	return result, nil
+}
+
+// GetPullRequests fetches pull requests in every state for a repository, most recently updated first
+func (c *CloudClient) GetPullRequests(ctx context.Context, baseURL, repository, token string, maxPages int) ([]*types.PullRequest, error) {
+	resourceURL, err := c.repositoryURL(baseURL, repository, "pullrequests")
+	if err != nil {
+		return nil, err
+	}
+
+	prs, err := getCloudPages[cloudPullRequest](ctx, c.httpClient, resourceURL+"?state=OPEN&state=MERGED&state=DECLINED&state=SUPERSEDED&sort=-updated_on&pagelen=50", token, maxPages)
+	if err != nil {
+		return nil, err
+	}
+
+	var pullRequests []*types.PullRequest
+	for _, pr := range prs {
+		pullRequests = append(pullRequests, pr.toPullRequest())
+	}
+
+	return pullRequests, nil
+}
+
+// GetPullRequest fetches details of a single pull request, including its participants
+func (c *CloudClient) GetPullRequest(ctx context.Context, baseURL, repository, token string, prID int) (*types.PullRequest, error) {
+	resourceURL, err := c.repositoryURL(baseURL, repository, fmt.Sprintf("pullrequests/%d", prID))
+	if err != nil {
+		return nil, err
+	}
+
+	var pr cloudPullRequest
+	if err := get(ctx, c.httpClient, resourceURL, token, &pr); err != nil {
+		return nil, err
+	}
+
+	return pr.toPullRequest(), nil
+}
+
+// GetPullRequestActivity fetches the comments, approvals and state changes of a pull request
+func (c *CloudClient) GetPullRequestActivity(ctx context.Context, baseURL, repository, token string, prID int) (*types.Activity, error) {
+	resourceURL, err := c.repositoryURL(baseURL, repository, fmt.Sprintf("pullrequests/%d/activity", prID))
+	if err != nil {
+		return nil, err
+	}
+
+	activities, err := getCloudPages[cloudActivity](ctx, c.httpClient, resourceURL+"?pagelen=50", token, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := &types.Activity{}
+	for _, a := range activities {
+		switch {
+		case a.Comment != nil && !a.Comment.Deleted:
+			activity.Comments = append(activity.Comments, &types.Comment{
+				ID:        a.Comment.ID,
+				User:      a.Comment.User.toUser(),
+				Body:      a.Comment.Content.Raw,
+				Inline:    a.Comment.Inline != nil,
+				CreatedAt: a.Comment.CreatedOn,
+				UpdatedAt: a.Comment.UpdatedOn,
+			})
+		case a.Approval != nil:
+			activity.Approvals = append(activity.Approvals, &types.Approval{
+				User:  a.Approval.User.toUser(),
+				State: types.ApprovalStateApproved,
+				Date:  a.Approval.Date,
+			})
+		case a.ChangesRequested != nil:
+			activity.Approvals = append(activity.Approvals, &types.Approval{
+				User:  a.ChangesRequested.User.toUser(),
+				State: types.ApprovalStateChangesRequested,
+				Date:  a.ChangesRequested.Date,
+			})
+		case a.Update != nil && a.Update.State == types.PullRequestStateMerged:
+			mergedAt := a.Update.Date
+			activity.MergedAt = &mergedAt
+		}
+	}
+
+	return activity, nil
+}
+
+// GetPullRequestDiffStat fetches the number of added and removed lines of a pull request
+func (c *CloudClient) GetPullRequestDiffStat(ctx context.Context, baseURL, repository, token string, prID int) (*types.DiffStat, error) {
+	resourceURL, err := c.repositoryURL(baseURL, repository, fmt.Sprintf("pullrequests/%d/diffstat", prID))
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := getCloudPages[cloudDiffStat](ctx, c.httpClient, resourceURL+"?pagelen=500", token, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	diffStat := &types.DiffStat{ChangedFiles: len(files)}
+	for _, file := range files {
+		diffStat.Additions += file.LinesAdded
+		diffStat.Deletions += file.LinesRemoved
+	}
+
+	return diffStat, nil
+}
+
+// GetPullRequestCommits fetches commits for a pull request
+func (c *CloudClient) GetPullRequestCommits(ctx context.Context, baseURL, repository, token string, prID int) ([]*types.Commit, error) {
+	resourceURL, err := c.repositoryURL(baseURL, repository, fmt.Sprintf("pullrequests/%d/commits", prID))
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := getCloudPages[cloudCommit](ctx, c.httpClient, resourceURL+"?pagelen=100", token, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*types.Commit
+	for _, commit := range commits {
+		authorName, authorEmail := parseRawAuthor(commit.Author.Raw)
+		result = append(result, &types.Commit{
+			Hash:        commit.Hash,
+			Message:     commit.Message,
+			AuthorName:  authorName,
+			AuthorEmail: authorEmail,
+			Date:        commit.Date,
+		})
+	}
+
+	return result, nil
+}
+
+// repositoryURL builds the API URL for a repository resource
+func (c *CloudClient) repositoryURL(baseURL, repository, resource string) (string, error) {
+	workspace, slug, err := splitRepository(repository)
+	if err != nil {
+		return "", err
+	}
+	if baseURL == "" {
+		baseURL = DefaultCloudBaseURL
+	}
+	return fmt.Sprintf("%s/2.0/repositories/%s/%s/%s", strings.TrimSuffix(baseURL, "/"), workspace, slug, resource), nil
+}
+
+// getCloudPages follows the "next" links of a paginated response. A maxPages of 0 fetches every page.
+func getCloudPages[T any](ctx context.Context, httpClient *http.Client, pageURL, token string, maxPages int) ([]T, error) {
+	var all []T
+	for page := 1; pageURL != "" && (maxPages == 0 || page <= maxPages); page++ {
+		var resp cloudPage[T]
+		if err := get(ctx, httpClient, pageURL, token, &resp); err != nil {
+			return nil, err
+		}
+		all = append(all, resp.Values...)
+		pageURL = resp.Next
+	}
+	return all, nil
+}
+
+func (pr cloudPullRequest) toPullRequest() *types.PullRequest {
+	pullRequest := &types.PullRequest{
+		ID:                pr.ID,
+		State:             pr.State,
+		Title:             pr.Title,
+		Description:       pr.Description,
+		URL:               pr.Links.HTML.Href,
+		CreatedAt:         pr.CreatedOn,
+		UpdatedAt:         pr.UpdatedOn,
+		CommentCount:      pr.CommentCount,
+		Author:            pr.Author.toUser(),
+		SourceBranch:      pr.Source.Branch.Name,
+		DestinationBranch: pr.Destination.Branch.Name,
+		Draft:             pr.Draft,
+	}
+
+	// Cloud does not expose a close date, the last update of a closed pull request is the closest match
+	if pr.State != types.PullRequestStateOpen {
+		closedAt := pr.UpdatedOn
+		pullRequest.ClosedAt = &closedAt
+	}
+
+	for _, participant := range pr.Participants {
+		state := ""
+		if participant.State != nil {
+			state = strings.ToUpper(*participant.State)
+		}
+		pullRequest.Participants = append(pullRequest.Participants, types.Participant{
+			User:     participant.User.toUser(),
+			Role:     participant.Role,
+			Approved: participant.Approved,
+			State:    state,
+		})
+	}
+
+	return pullRequest
+}
+
+func (u cloudUser) toUser() types.User {
+	return types.User{
+		ID:          u.UUID,
+		Username:    u.Nickname,
+		DisplayName: u.DisplayName,
+		Type:        u.Type,
+	}
+}
+
+// parseRawAuthor splits a git author of the form "Name <email>"
+func parseRawAuthor(raw string) (string, string) {
+	address, err := mail.ParseAddress(raw)
+	if err != nil {
+		return raw, ""
+	}
+	return address.Name, address.Address
+}
@@ -0,0 +1,413 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ems.dev/backend/libraries/bitbucket/types"
+)
+
+// Data Center pull request activity actions
+const (
+	dataCenterActionCommented = "COMMENTED"
+	dataCenterActionApproved  = "APPROVED"
+	dataCenterActionReviewed  = "REVIEWED" // Reviewer marked the pull request as "needs work"
+	dataCenterActionMerged    = "MERGED"
+)
+
+// DataCenterClient represents a Bitbucket Data Center (and Server) REST API client.
+// Data Center is always self-hosted, so every call requires the base URL of the instance.
+type DataCenterClient struct {
+	httpClient *http.Client
+}
+
+// NewDataCenterClient creates a new Bitbucket Data Center client
+func NewDataCenterClient() *DataCenterClient {
+	return &DataCenterClient{
+		httpClient: &http.Client{},
+	}
+}
+
+// dataCenterPage is the envelope of every paginated Bitbucket Data Center response
+type dataCenterPage[T any] struct {
+	Values        []T  `json:"values"`
+	IsLastPage    bool `json:"isLastPage"`
+	NextPageStart int  `json:"nextPageStart"`
+}
+
+type dataCenterRepository struct {
+	Slug     string `json:"slug"`
+	Name     string `json:"name"`
+	Public   bool   `json:"public"`
+	Archived bool   `json:"archived"`
+	Project  struct {
+		Key string `json:"key"`
+	} `json:"project"`
+}
+
+type dataCenterUser struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress"`
+	Type         string `json:"type"`
+}
+
+type dataCenterParticipant struct {
+	User     dataCenterUser `json:"user"`
+	Role     string         `json:"role"`
+	Approved bool           `json:"approved"`
+	Status   string         `json:"status"`
+}
+
+type dataCenterRef struct {
+	DisplayID string `json:"displayId"`
+}
+
+type dataCenterPullRequest struct {
+	ID           int                     `json:"id"`
+	Title        string                  `json:"title"`
+	Description  string                  `json:"description"`
+	State        string                  `json:"state"`
+	CreatedDate  int64                   `json:"createdDate"`
+	UpdatedDate  int64                   `json:"updatedDate"`
+	ClosedDate   int64                   `json:"closedDate"`
+	Draft        bool                    `json:"draft"`
+	FromRef      dataCenterRef           `json:"fromRef"`
+	ToRef        dataCenterRef           `json:"toRef"`
+	Author       dataCenterParticipant   `json:"author"`
+	Reviewers    []dataCenterParticipant `json:"reviewers"`
+	Participants []dataCenterParticipant `json:"participants"`
+	Properties   struct {
+		CommentCount int `json:"commentCount"`
+	} `json:"properties"`
+	Links struct {
+		Self []struct {
+			Href string `json:"href"`
+		} `json:"self"`
+	} `json:"links"`
+}
+
+type dataCenterComment struct {
+	ID          int                  `json:"id"`
+	Text        string               `json:"text"`
+	Author      dataCenterUser       `json:"author"`
+	CreatedDate int64                `json:"createdDate"`
+	UpdatedDate int64                `json:"updatedDate"`
+	Comments    []*dataCenterComment `json:"comments"`
+}
+
+type dataCenterActivity struct {
+	ID            int                `json:"id"`
+	CreatedDate   int64              `json:"createdDate"`
+	User          dataCenterUser     `json:"user"`
+	Action        string             `json:"action"`
+	Comment       *dataCenterComment `json:"comment"`
+	CommentAnchor interface{}        `json:"commentAnchor"`
+}
+
+type dataCenterDiff struct {
+	Diffs []struct {
+		Hunks []struct {
+			Segments []struct {
+				Type  string        `json:"type"`
+				Lines []interface{} `json:"lines"`
+			} `json:"segments"`
+		} `json:"hunks"`
+	} `json:"diffs"`
+}
+
+type dataCenterCommit struct {
+	ID              string         `json:"id"`
+	Message         string         `json:"message"`
+	Author          dataCenterUser `json:"author"`
+	AuthorTimestamp int64          `json:"authorTimestamp"`
+}
+
+// GetRepository fetches the details of a repository. The default branch is served by a separate endpoint,
+// which fails for empty repositories, in which case it is left empty.
+func (c *DataCenterClient) GetRepository(ctx context.Context, baseURL, repository, token string) (*types.Repository, error) {
+	resourceURL, err := c.repositoryURL(baseURL, repository, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var repo dataCenterRepository
+	if err := get(ctx, c.httpClient, strings.TrimSuffix(resourceURL, "/"), token, &repo); err != nil {
+		return nil, err
+	}
+
+	result := &types.Repository{
+		Slug:     repo.Slug,
+		Name:     repo.Name,
+		Owner:    repo.Project.Key,
+		Private:  !repo.Public,
+		Archived: repo.Archived,
+	}
+
+	branchURL, err := c.repositoryURL(baseURL, repository, "default-branch")
+	if err != nil {
+		return nil, err
+	}
+	var branch dataCenterRef
+	if err := get(ctx, c.httpClient, branchURL, token, &branch); err == nil {
+		result.DefaultBranch = branch.DisplayID
+	}
+
+	return result, nil
+}
+
+// GetPullRequests fetches pull requests in every state for a repository, newest first
+func (c *DataCenterClient) GetPullRequests(ctx context.Context, baseURL, repository, token string, maxPages int) ([]*types.PullRequest, error) {
+	resourceURL, err := c.repositoryURL(baseURL, repository, "pull-requests")
+	if err != nil {
+		return nil, err
+	}
+
+	prs, err := getDataCenterPages[dataCenterPullRequest](ctx, c.httpClient, resourceURL+"?state=ALL&order=NEWEST", token, maxPages)
+	if err != nil {
+		return nil, err
+	}
+
+	var pullRequests []*types.PullRequest
+	for _, pr := range prs {
+		pullRequests = append(pullRequests, pr.toPullRequest())
+	}
+
+	return pullRequests, nil
+}
+
+// GetPullRequest fetches details of a single pull request, including its reviewers
+func (c *DataCenterClient) GetPullRequest(ctx context.Context, baseURL, repository, token string, prID int) (*types.PullRequest, error) {
+	resourceURL, err := c.repositoryURL(baseURL, repository, fmt.Sprintf("pull-requests/%d", prID))
+	if err != nil {
+		return nil, err
+	}
+
+	var pr dataCenterPullRequest
+	if err := get(ctx, c.httpClient, resourceURL, token, &pr); err != nil {
+		return nil, err
+	}
+
+	return pr.toPullRequest(), nil
+}
+
+// GetPullRequestActivity fetches the comments, approvals and state changes of a pull request.
+// Comment replies are nested in their parent comment and are flattened here.
+func (c *DataCenterClient) GetPullRequestActivity(ctx context.Context, baseURL, repository, token string, prID int) (*types.Activity, error) {
+	resourceURL, err := c.repositoryURL(baseURL, repository, fmt.Sprintf("pull-requests/%d/activities", prID))
+	if err != nil {
+		return nil, err
+	}
+
+	activities, err := getDataCenterPages[dataCenterActivity](ctx, c.httpClient, resourceURL, token, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := &types.Activity{}
+	for _, a := range activities {
+		switch a.Action {
+		case dataCenterActionCommented:
+			if a.Comment != nil {
+				activity.Comments = append(activity.Comments, flattenDataCenterComment(a.Comment, a.CommentAnchor != nil)...)
+			}
+		case dataCenterActionApproved:
+			activity.Approvals = append(activity.Approvals, &types.Approval{
+				User:  a.User.toUser(),
+				State: types.ApprovalStateApproved,
+				Date:  fromMillis(a.CreatedDate),
+			})
+		case dataCenterActionReviewed:
+			activity.Approvals = append(activity.Approvals, &types.Approval{
+				User:  a.User.toUser(),
+				State: types.ApprovalStateChangesRequested,
+				Date:  fromMillis(a.CreatedDate),
+			})
+		case dataCenterActionMerged:
+			mergedAt := fromMillis(a.CreatedDate)
+			activity.MergedAt = &mergedAt
+		}
+	}
+
+	return activity, nil
+}
+
+// GetPullRequestDiffStat fetches the number of added and removed lines of a pull request
+func (c *DataCenterClient) GetPullRequestDiffStat(ctx context.Context, baseURL, repository, token string, prID int) (*types.DiffStat, error) {
+	resourceURL, err := c.repositoryURL(baseURL, repository, fmt.Sprintf("pull-requests/%d/diff", prID))
+	if err != nil {
+		return nil, err
+	}
+
+	var diff dataCenterDiff
+	if err := get(ctx, c.httpClient, resourceURL+"?contextLines=0&withComments=false", token, &diff); err != nil {
+		return nil, err
+	}
+
+	diffStat := &types.DiffStat{ChangedFiles: len(diff.Diffs)}
+	for _, file := range diff.Diffs {
+		for _, hunk := range file.Hunks {
+			for _, segment := range hunk.Segments {
+				switch segment.Type {
+				case "ADDED":
+					diffStat.Additions += len(segment.Lines)
+				case "REMOVED":
+					diffStat.Deletions += len(segment.Lines)
+				}
+			}
+		}
+	}
+
+	return diffStat, nil
+}
+
+// GetPullRequestCommits fetches commits for a pull request
+func (c *DataCenterClient) GetPullRequestCommits(ctx context.Context, baseURL, repository, token string, prID int) ([]*types.Commit, error) {
+	resourceURL, err := c.repositoryURL(baseURL, repository, fmt.Sprintf("pull-requests/%d/commits", prID))
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := getDataCenterPages[dataCenterCommit](ctx, c.httpClient, resourceURL, token, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*types.Commit
+	for _, commit := range commits {
+		result = append(result, &types.Commit{
+			Hash:        commit.ID,
+			Message:     commit.Message,
+			AuthorName:  commit.Author.Name,
+			AuthorEmail: commit.Author.EmailAddress,
+			Date:        fromMillis(commit.AuthorTimestamp),
+		})
+	}
+
+	return result, nil
+}
+
+// repositoryURL builds the API URL for a repository resource
+func (c *DataCenterClient) repositoryURL(baseURL, repository, resource string) (string, error) {
+	if baseURL == "" {
+		return "", fmt.Errorf("base URL is required for Bitbucket Data Center")
+	}
+	projectKey, slug, err := splitRepository(repository)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/%s", strings.TrimSuffix(baseURL, "/"), projectKey, slug, resource), nil
+}
+
+// getDataCenterPages follows the start/nextPageStart pagination. A maxPages of 0 fetches every page.
+func getDataCenterPages[T any](ctx context.Context, httpClient *http.Client, resourceURL, token string, maxPages int) ([]T, error) {
+	separator := "?"
+	if strings.Contains(resourceURL, "?") {
+		separator = "&"
+	}
+
+	var all []T
+	start := 0
+	for page := 1; maxPages == 0 || page <= maxPages; page++ {
+		var resp dataCenterPage[T]
+		pageURL := resourceURL + separator + "limit=100&start=" + strconv.Itoa(start)
+		if err := get(ctx, httpClient, pageURL, token, &resp); err != nil {
+			return nil, err
+		}
+		all = append(all, resp.Values...)
+
+		if resp.IsLastPage {
+			break
+		}
+		start = resp.NextPageStart
+	}
+	return all, nil
+}
+
+func (pr dataCenterPullRequest) toPullRequest() *types.PullRequest {
+	pullRequest := &types.PullRequest{
+		ID:                pr.ID,
+		State:             pr.State,
+		Title:             pr.Title,
+		Description:       pr.Description,
+		CreatedAt:         fromMillis(pr.CreatedDate),
+		UpdatedAt:         fromMillis(pr.UpdatedDate),
+		CommentCount:      pr.Properties.CommentCount,
+		Author:            pr.Author.User.toUser(),
+		SourceBranch:      pr.FromRef.DisplayID,
+		DestinationBranch: pr.ToRef.DisplayID,
+		Draft:             pr.Draft,
+	}
+
+	if len(pr.Links.Self) > 0 {
+		pullRequest.URL = pr.Links.Self[0].Href
+	}
+
+	if pr.ClosedDate != 0 {
+		closedAt := fromMillis(pr.ClosedDate)
+		pullRequest.ClosedAt = &closedAt
+	}
+
+	for _, reviewer := range pr.Reviewers {
+		pullRequest.Participants = append(pullRequest.Participants, reviewer.toParticipant())
+	}
+	for _, participant := range pr.Participants {
+		pullRequest.Participants = append(pullRequest.Participants, participant.toParticipant())
+	}
+
+	return pullRequest
+}
+
+func (p dataCenterParticipant) toParticipant() types.Participant {
+	state := ""
+	switch p.Status {
+	case "APPROVED":
+		state = types.ApprovalStateApproved
+	case "NEEDS_WORK":
+		state = types.ApprovalStateChangesRequested
+	}
+
+	return types.Participant{
+		User:     p.User.toUser(),
+		Role:     p.Role,
+		Approved: p.Approved,
+		State:    state,
+	}
+}
+
+func (u dataCenterUser) toUser() types.User {
+	return types.User{
+		ID:          strconv.Itoa(u.ID),
+		Username:    u.Slug,
+		DisplayName: u.DisplayName,
+		Email:       u.EmailAddress,
+		Type:        u.Type,
+	}
+}
+
+// flattenDataCenterComment returns the comment followed by all of its replies
+func flattenDataCenterComment(comment *dataCenterComment, inline bool) []*types.Comment {
+	comments := []*types.Comment{{
+		ID:        comment.ID,
+		User:      comment.Author.toUser(),
+		Body:      comment.Text,
+		Inline:    inline,
+		CreatedAt: fromMillis(comment.CreatedDate),
+		UpdatedAt: fromMillis(comment.UpdatedDate),
+	}}
+	for _, reply := range comment.Comments {
+		comments = append(comments, flattenDataCenterComment(reply, inline)...)
+	}
+	return comments
+}
+
+// fromMillis converts a Data Center epoch milliseconds timestamp
+func fromMillis(millis int64) time.Time {
+	return time.UnixMilli(millis).UTC()
+}
@@ -0,0 +1,125 @@
+package types
+
+import (
+	"time"
+)
+
+// Pull request states, shared by Bitbucket Cloud and Data Center
+const (
+	PullRequestStateOpen       = "OPEN"
+	PullRequestStateMerged     = "MERGED"
+	PullRequestStateDeclined   = "DECLINED"
+	PullRequestStateSuperseded = "SUPERSEDED"
+)
+
+// Approval states
+const (
+	ApprovalStateApproved         = "APPROVED"
+	ApprovalStateChangesRequested = "CHANGES_REQUESTED"
+)
+
+// Comment types
+type CommentType string
+
+const (
+	CommentTypeComment       CommentType = "COMMENT"
+	CommentTypeReview        CommentType = "REVIEW"
+	CommentTypeReviewComment CommentType = "REVIEW_COMMENT"
+)
+
+// Repository represents a Bitbucket repository. Cloud and Data Center responses are both
+// converted to this type by their respective clients.
+type Repository struct {
+	Slug          string `json:"slug"`           // Repository slug
+	Name          string `json:"name"`           // Display name
+	Owner         string `json:"owner"`          // Workspace (Cloud) or project key (Data Center)
+	DefaultBranch string `json:"default_branch"` // Default branch
+	Private       bool   `json:"private"`        // Whether the repository is private
+	Archived      bool   `json:"archived"`       // Whether the repository is archived, always false on Cloud
+}
+
+// PullRequest represents a Bitbucket pull request. Cloud and Data Center responses are both
+// converted to this type by their respective clients.
+type PullRequest struct {
+	ID                int           `json:"id"`                 // ProviderID
+	State             string        `json:"state"`              // OPEN, MERGED, DECLINED, SUPERSEDED
+	Title             string        `json:"title"`              // Title
+	Description       string        `json:"description"`        // Description
+	URL               string        `json:"html_url"`           // URL
+	CreatedAt         time.Time     `json:"created_at"`         // Created at
+	UpdatedAt         time.Time     `json:"updated_at"`         // Updated at
+	ClosedAt          *time.Time    `json:"closed_at"`          // When PR was merged or declined
+	CommentCount      int           `json:"comments"`           // Number of comments
+	Author            User          `json:"user"`               // Author
+	SourceBranch      string        `json:"source_branch"`      // The source branch
+	DestinationBranch string        `json:"destination_branch"` // The destination branch
+	Draft             bool          `json:"draft"`              // Whether PR is a draft
+	Participants      []Participant `json:"participants"`       // Reviewers and participants
+
+	// Additions, Deletions and ChangedFiles come from the diff stats of the pull request
+	Additions    int `json:"additions"`
+	Deletions    int `json:"deletions"`
+	ChangedFiles int `json:"changed_files"`
+}
+
+// Participant represents a reviewer or participant of a pull request
+type Participant struct {
+	User     User   `json:"user"`
+	Role     string `json:"role"`
+	Approved bool   `json:"approved"`
+	State    string `json:"state"`
+}
+
+// Activity represents the comments and review activity of a pull request
+type Activity struct {
+	Comments  []*Comment
+	Approvals []*Approval
+	MergedAt  *time.Time
+}
+
+// Comment represents a pull request comment
+type Comment struct {
+	ID        int       `json:"id"`
+	User      User      `json:"user"`
+	Body      string    `json:"body"`
+	Inline    bool      `json:"inline"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Approval represents an approval or a "changes requested" (needs work) review of a pull request
+type Approval struct {
+	User  User      `json:"user"`
+	State string    `json:"state"`
+	Date  time.Time `json:"date"`
+}
+
+// DiffStat represents the aggregated line and file changes of a pull request
+type DiffStat struct {
+	Additions    int `json:"additions"`
+	Deletions    int `json:"deletions"`
+	ChangedFiles int `json:"changed_files"`
+}
+
+// User represents a Bitbucket user
+type User struct {
+	ID          string `json:"id"`           // Cloud uuid or Data Center numeric id
+	Username    string `json:"login"`        // Cloud nickname or Data Center slug
+	DisplayName string `json:"display_name"` // Display name
+	Email       string `json:"email"`        // Only exposed by Data Center
+	Type        string `json:"type"`         // Cloud: user, app_user, team. Data Center: NORMAL, SERVICE
+}
+
+// IsBot reports whether the user is an app or service account
+func (u User) IsBot() bool {
+	return u.Type == "app_user" || u.Type == "SERVICE"
+}
+
+// Commit represents a commit of a pull request
+type Commit struct {
+	Hash        string    `json:"hash"`
+	Message     string    `json:"message"`
+	AuthorName  string    `json:"author_name"`
+	AuthorEmail string    `json:"author_email"`
+	Date        time.Time `json:"date"`
+}
@@ -0,0 +1,191 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule returns the next activation time of a schedule after a given time
+type Schedule interface {
+	Next(after time.Time) time.Time
+}
+
+// macros are the supported shorthands for common expressions
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a standard five field cron expression ("minute hour day-of-month month day-of-week"), a
+// macro such as "@daily", or a fixed interval such as "@every 15m". Fields support "*", lists, ranges
+// and steps, e.g. "*/15 9-17 * * 1-5". Day of week 0 and 7 are Sunday.
+func Parse(expression string) (Schedule, error) {
+	expression = strings.TrimSpace(expression)
+
+	if interval, ok := strings.CutPrefix(expression, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(interval))
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval %q: %w", interval, err)
+		}
+		if d < time.Minute {
+			return nil, fmt.Errorf("interval %q must be at least one minute", interval)
+		}
+		return &intervalSchedule{interval: d}, nil
+	}
+
+	if macro, ok := macros[expression]; ok {
+		expression = macro
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields in cron expression %q, got %d", expression, len(fields))
+	}
+
+	minutes, err := parseField(fields[0], 0, 59)
+	if err != nil {
+		return nil, fmt.Errorf("invalid minute field: %w", err)
+	}
+	hours, err := parseField(fields[1], 0, 23)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hour field: %w", err)
+	}
+	daysOfMonth, err := parseField(fields[2], 1, 31)
+	if err != nil {
+		return nil, fmt.Errorf("invalid day of month field: %w", err)
+	}
+	months, err := parseField(fields[3], 1, 12)
+	if err != nil {
+		return nil, fmt.Errorf("invalid month field: %w", err)
+	}
+	daysOfWeek, err := parseField(fields[4], 0, 7)
+	if err != nil {
+		return nil, fmt.Errorf("invalid day of week field: %w", err)
+	}
+	// 7 is an alias of Sunday
+	if daysOfWeek&(1<<7) != 0 {
+		daysOfWeek |= 1
+	}
+
+	return &cronSchedule{
+		minutes:       minutes,
+		hours:         hours,
+		daysOfMonth:   daysOfMonth,
+		months:        months,
+		daysOfWeek:    daysOfWeek,
+		anyDayOfMonth: fields[2] == "*",
+		anyDayOfWeek:  fields[4] == "*",
+	}, nil
+}
+
+// parseField returns the bit set of the values matched by a cron field
+func parseField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		start, end := min, max
+		if rangePart != "*" {
+			startPart, endPart, isRange := strings.Cut(rangePart, "-")
+
+			var err error
+			if start, err = strconv.Atoi(startPart); err != nil {
+				return 0, fmt.Errorf("invalid value %q", startPart)
+			}
+			end = start
+			if isRange {
+				if end, err = strconv.Atoi(endPart); err != nil {
+					return 0, fmt.Errorf("invalid value %q", endPart)
+				}
+			} else if hasStep {
+				// "5/15" runs every 15 from 5 to the maximum
+				end = max
+			}
+		}
+
+		if start < min || end > max || start > end {
+			return 0, fmt.Errorf("%q is out of range %d-%d", part, min, max)
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+	return bits, nil
+}
+
+type cronSchedule struct {
+	minutes     uint64
+	hours       uint64
+	daysOfMonth uint64
+	months      uint64
+	daysOfWeek  uint64
+	// When both day fields are restricted a day matching either of them matches, as in standard cron
+	anyDayOfMonth bool
+	anyDayOfWeek  bool
+}
+
+// maxSearchYears bounds the search of expressions which never match, e.g. "0 0 31 2 *"
+const maxSearchYears = 5
+
+// Next returns the first minute after the given time matching the expression, in the time's location.
+// Returns the zero time if the expression never matches.
+func (s *cronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if s.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *cronSchedule) matchesDay(t time.Time) bool {
+	dayOfMonth := s.daysOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := s.daysOfWeek&(1<<uint(t.Weekday())) != 0
+
+	if s.anyDayOfMonth || s.anyDayOfWeek {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
+
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+// Next returns the time one interval after the given time
+func (s *intervalSchedule) Next(after time.Time) time.Time {
+	return after.Add(s.interval)
+}
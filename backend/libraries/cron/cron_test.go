@@ -0,0 +1,108 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		expression    string
+		expectedError string
+	}{
+		{name: "empty", expression: "", expectedError: "expected 5 fields"},
+		{name: "too few fields", expression: "* * * *", expectedError: "expected 5 fields"},
+		{name: "too many fields", expression: "0 * * * * *", expectedError: "expected 5 fields"},
+		{name: "minute out of range", expression: "60 * * * *", expectedError: "invalid minute field"},
+		{name: "hour out of range", expression: "0 24 * * *", expectedError: "invalid hour field"},
+		{name: "day of month zero", expression: "0 0 0 * *", expectedError: "invalid day of month field"},
+		{name: "month out of range", expression: "0 0 * 13 *", expectedError: "invalid month field"},
+		{name: "day of week out of range", expression: "0 0 * * 8", expectedError: "invalid day of week field"},
+		{name: "zero step", expression: "*/0 * * * *", expectedError: "invalid step"},
+		{name: "negative step", expression: "*/-5 * * * *", expectedError: "invalid step"},
+		{name: "reversed range", expression: "30-10 * * * *", expectedError: "out of range"},
+		{name: "not a number", expression: "a * * * *", expectedError: "invalid value"},
+		{name: "invalid range end", expression: "1-b * * * *", expectedError: "invalid value"},
+		{name: "empty list item", expression: "1,,2 * * * *", expectedError: "invalid value"},
+		{name: "unknown macro", expression: "@nightly", expectedError: "expected 5 fields"},
+		{name: "invalid interval", expression: "@every soon", expectedError: "invalid interval"},
+		{name: "interval below a minute", expression: "@every 30s", expectedError: "must be at least one minute"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.expression)
+			assert.Nil(t, schedule)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedError)
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		after      time.Time
+		expected   time.Time
+	}{
+		// Fields
+		{name: "every minute is strictly after", expression: "* * * * *", after: date(2024, 1, 1, 10, 7), expected: date(2024, 1, 1, 10, 8)},
+		{name: "seconds are truncated", expression: "* * * * *", after: date(2024, 1, 1, 10, 7).Add(30 * time.Second), expected: date(2024, 1, 1, 10, 8)},
+		{name: "step", expression: "*/15 * * * *", after: date(2024, 1, 1, 10, 7), expected: date(2024, 1, 1, 10, 15)},
+		{name: "step from a start value", expression: "5/20 * * * *", after: date(2024, 1, 1, 10, 46), expected: date(2024, 1, 1, 11, 5)},
+		{name: "step over a range", expression: "0 8-18/4 * * *", after: date(2024, 1, 1, 12, 0), expected: date(2024, 1, 1, 16, 0)},
+		{name: "list", expression: "0,30 * * * *", after: date(2024, 1, 1, 10, 0), expected: date(2024, 1, 1, 10, 30)},
+		{name: "list of ranges", expression: "0 1-2,22-23 * * *", after: date(2024, 1, 1, 3, 0), expected: date(2024, 1, 1, 22, 0)},
+		{name: "range of weekdays skips the weekend", expression: "0 9-17 * * 1-5", after: date(2024, 1, 5, 17, 30), expected: date(2024, 1, 8, 9, 0)},
+		{name: "day of week 7 is sunday", expression: "0 0 * * 7", after: date(2024, 1, 1, 0, 0), expected: date(2024, 1, 7, 0, 0)},
+		{name: "day of week 0 is sunday", expression: "0 0 * * 0", after: date(2024, 1, 1, 0, 0), expected: date(2024, 1, 7, 0, 0)},
+
+		// Day of month and day of week, 2024-01-01 is a Monday
+		{name: "restricted day of month and week match either, week first", expression: "0 0 13 * 5", after: date(2024, 1, 1, 0, 0), expected: date(2024, 1, 5, 0, 0)},
+		{name: "restricted day of month and week match either, month first", expression: "0 0 13 * 5", after: date(2024, 1, 12, 0, 0), expected: date(2024, 1, 13, 0, 0)},
+		{name: "any day of week only uses the day of month", expression: "0 0 13 * *", after: date(2024, 1, 1, 0, 0), expected: date(2024, 1, 13, 0, 0)},
+		{name: "any day of month only uses the day of week", expression: "0 0 * * 5", after: date(2024, 1, 6, 0, 0), expected: date(2024, 1, 12, 0, 0)},
+
+		// Boundaries
+		{name: "next month", expression: "0 0 1 * *", after: date(2024, 1, 31, 12, 0), expected: date(2024, 2, 1, 0, 0)},
+		{name: "skips months without the day", expression: "0 0 31 * *", after: date(2024, 4, 1, 0, 0), expected: date(2024, 5, 31, 0, 0)},
+		{name: "next year", expression: "30 23 31 12 *", after: date(2024, 12, 31, 23, 30), expected: date(2025, 12, 31, 23, 30)},
+		{name: "end of day rolls over the year", expression: "0 0 * * *", after: date(2024, 12, 31, 23, 59), expected: date(2025, 1, 1, 0, 0)},
+		{name: "leap day", expression: "0 0 29 2 *", after: date(2024, 3, 1, 0, 0), expected: date(2028, 2, 29, 0, 0)},
+		{name: "never matches", expression: "0 0 31 2 *", after: date(2024, 1, 1, 0, 0), expected: time.Time{}},
+
+		// Macros and intervals
+		{name: "yearly", expression: "@yearly", after: date(2024, 6, 1, 0, 0), expected: date(2025, 1, 1, 0, 0)},
+		{name: "monthly", expression: "@monthly", after: date(2024, 6, 1, 0, 0), expected: date(2024, 7, 1, 0, 0)},
+		{name: "weekly", expression: "@weekly", after: date(2024, 1, 1, 0, 0), expected: date(2024, 1, 7, 0, 0)},
+		{name: "daily", expression: "@daily", after: date(2024, 1, 1, 0, 0), expected: date(2024, 1, 2, 0, 0)},
+		{name: "hourly", expression: "@hourly", after: date(2024, 1, 1, 10, 59), expected: date(2024, 1, 1, 11, 0)},
+		{name: "every", expression: "@every 90m", after: date(2024, 1, 1, 10, 7), expected: date(2024, 1, 1, 11, 37)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.expression)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, schedule.Next(tt.after))
+		})
+	}
+}
+
+func TestNextKeepsLocation(t *testing.T) {
+	location := time.FixedZone("UTC+2", 2*60*60)
+	schedule, err := Parse("0 9 * * *")
+	require.NoError(t, err)
+
+	next := schedule.Next(time.Date(2024, 1, 1, 10, 0, 0, 0, location))
+	assert.Equal(t, time.Date(2024, 1, 2, 9, 0, 0, 0, location), next)
+}
@@ -0,0 +1,200 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// appJWTLifetime is the lifetime of the JWTs used to authenticate as a GitHub App (GitHub allows at most 10 minutes)
+	appJWTLifetime = 9 * time.Minute
+	// appJWTClockDrift backdates the JWT issue time to allow for clock drift with GitHub
+	appJWTClockDrift = time.Minute
+	// installationTokenRefreshMargin is how long before expiry a cached installation token is replaced
+	installationTokenRefreshMargin = 5 * time.Minute
+)
+
+// installationToken is a cached GitHub App installation token
+type installationToken struct {
+	token     string
+	expiresAt time.Time
+}
+
+// installationTokenCache keeps the installation tokens of GitHub Apps until shortly before they expire.
+// mu only guards the maps, minting a token holds the lock of its installation so concurrent callers of
+// the same installation wait for a single mint while other installations are not blocked.
+type installationTokenCache struct {
+	mu     sync.Mutex
+	tokens map[string]installationToken
+	locks  map[string]*sync.Mutex
+}
+
+func newInstallationTokenCache() *installationTokenCache {
+	return &installationTokenCache{
+		tokens: make(map[string]installationToken),
+		locks:  make(map[string]*sync.Mutex),
+	}
+}
+
+// lock acquires the lock of an installation, the returned function releases it
+func (c *installationTokenCache) lock(key string) func() {
+	c.mu.Lock()
+	lock, ok := c.locks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		c.locks[key] = lock
+	}
+	c.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// get returns the cached token of an installation if it is not about to expire
+func (c *installationTokenCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.tokens[key]
+	if !ok || time.Until(cached.expiresAt) <= installationTokenRefreshMargin {
+		return "", false
+	}
+	return cached.token, true
+}
+
+func (c *installationTokenCache) set(key string, token installationToken) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tokens[key] = token
+}
+
+// GetInstallationToken returns an installation access token of a GitHub App installation. The token is
+// minted with a JWT signed by the app's private key (PEM encoded) and cached until shortly before it
+// expires, so it can be requested before every batch of API calls. Tokens are cached per private key, a
+// caller with another key of the same installation never gets a token it could not mint itself.
+func (c *Client) GetInstallationToken(ctx context.Context, appID, installationID, privateKey string) (string, error) {
+	key := installationTokenKey(appID, installationID, privateKey)
+
+	if token, ok := c.installationTokens.get(key); ok {
+		return token, nil
+	}
+
+	unlock := c.installationTokens.lock(key)
+	defer unlock()
+
+	// Another caller may have minted the token while waiting for the lock
+	if token, ok := c.installationTokens.get(key); ok {
+		return token, nil
+	}
+
+	rsaKey, err := ParseAppPrivateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	jwt, err := createAppJWT(appID, rsaKey, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/app/installations/%s/access_tokens", c.baseURL, installationID)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", jwt))
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	c.installationTokens.set(key, installationToken{token: body.Token, expiresAt: body.ExpiresAt})
+	return body.Token, nil
+}
+
+// installationTokenKey is the cache key of the installation tokens minted with a private key
+func installationTokenKey(appID, installationID, privateKey string) string {
+	digest := sha256.Sum256([]byte(privateKey))
+	return appID + "/" + installationID + "/" + hex.EncodeToString(digest[:])
+}
+
+// ParseAppPrivateKey parses the PEM encoded RSA private key of a GitHub App. GitHub generates PKCS#1
+// keys, PKCS#8 keys are accepted as well.
+func ParseAppPrivateKey(privateKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privateKey))
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// createAppJWT creates the RS256 signed JWT that authenticates as the GitHub App
+func createAppJWT(appID string, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-appJWTClockDrift).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": appID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign app JWT: %w", err)
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,215 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testAppPrivateKey(t *testing.T) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+}
+
+// verifyAppJWT checks the signature of an app JWT with the public key of the app
+func verifyAppJWT(jwt string, key *rsa.PublicKey) error {
+	dot := strings.LastIndex(jwt, ".")
+	if dot < 0 {
+		return rsa.ErrVerification
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(jwt[dot+1:])
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256([]byte(jwt[:dot]))
+	return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature)
+}
+
+func TestGetInstallationToken(t *testing.T) {
+	privateKey := testAppPrivateKey(t)
+
+	tests := []struct {
+		name          string
+		installations []string
+		// concurrent requests the installations at once instead of one after the other
+		concurrent    bool
+		expiresIn     time.Duration
+		expectedMints int32
+	}{
+		{
+			name:          "concurrent callers of an installation share one mint",
+			installations: []string{"1", "1", "1", "1"},
+			concurrent:    true,
+			expiresIn:     time.Hour,
+			expectedMints: 1,
+		},
+		{
+			name:          "installations are minted independently",
+			installations: []string{"1", "2", "3"},
+			concurrent:    true,
+			expiresIn:     time.Hour,
+			expectedMints: 3,
+		},
+		{
+			name:          "tokens about to expire are not reused",
+			installations: []string{"1", "1"},
+			expiresIn:     installationTokenRefreshMargin / 2,
+			expectedMints: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mints atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mints.Add(1)
+				installationID := strings.Split(r.URL.Path, "/")[3]
+				w.WriteHeader(http.StatusCreated)
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"token":      "token-" + installationID,
+					"expires_at": time.Now().Add(tt.expiresIn),
+				})
+			}))
+			defer server.Close()
+
+			client := newTestClient(server.URL)
+
+			if !tt.concurrent {
+				for _, installationID := range tt.installations {
+					token, err := client.GetInstallationToken(context.Background(), "app", installationID, privateKey)
+					require.NoError(t, err)
+					assert.Equal(t, "token-"+installationID, token)
+				}
+			} else {
+				var wg sync.WaitGroup
+				for _, installationID := range tt.installations {
+					wg.Add(1)
+					go func(installationID string) {
+						defer wg.Done()
+						token, err := client.GetInstallationToken(context.Background(), "app", installationID, privateKey)
+						assert.NoError(t, err)
+						assert.Equal(t, "token-"+installationID, token)
+					}(installationID)
+				}
+				wg.Wait()
+			}
+
+			assert.Equal(t, tt.expectedMints, mints.Load())
+		})
+	}
+}
+
+func TestGetInstallationTokenIsCachedPerPrivateKey(t *testing.T) {
+	privateKey := testAppPrivateKey(t)
+	revokedKey := testAppPrivateKey(t)
+
+	tests := []struct {
+		name string
+		// keys are the private keys the installation token is requested with, one after the other
+		keys          []string
+		expectedMints int32
+		expectedError string
+	}{
+		{
+			name:          "tokens are reused with the same private key",
+			keys:          []string{privateKey, privateKey},
+			expectedMints: 1,
+		},
+		{
+			name:          "another private key mints its own token",
+			keys:          []string{privateKey, revokedKey},
+			expectedMints: 2,
+			expectedError: "unexpected status code: 401",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mints atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mints.Add(1)
+				// GitHub rejects the JWTs signed by revoked keys
+				rsaKey, err := ParseAppPrivateKey(revokedKey)
+				require.NoError(t, err)
+				jwt := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+				if verifyAppJWT(jwt, &rsaKey.PublicKey) == nil {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"token":      "token",
+					"expires_at": time.Now().Add(time.Hour),
+				})
+			}))
+			defer server.Close()
+
+			client := newTestClient(server.URL)
+
+			var err error
+			for _, key := range tt.keys {
+				_, err = client.GetInstallationToken(context.Background(), "app", "1", key)
+			}
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedMints, mints.Load())
+		})
+	}
+}
+
+func TestGetInstallationTokenDoesNotBlockOtherInstallations(t *testing.T) {
+	privateKey := testAppPrivateKey(t)
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		installationID := strings.Split(r.URL.Path, "/")[3]
+		if installationID == "slow" {
+			<-release
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"token":      "token-" + installationID,
+			"expires_at": time.Now().Add(time.Hour),
+		})
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := newTestClient(server.URL)
+
+	go client.GetInstallationToken(context.Background(), "app", "slow", privateKey)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		token, err := client.GetInstallationToken(context.Background(), "app", "fast", privateKey)
+		assert.NoError(t, err)
+		assert.Equal(t, "token-fast", token)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("minting a token was blocked by the mint of another installation")
+	}
+}
@@ -2,33 +2,71 @@ package github
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"ems.dev/backend/libraries/github/types"
 )
 
 type GithubClient interface {
+	GetRepository(ctx context.Context, owner, repo, token string) (*types.Repo, error)
 	GetPullRequests(ctx context.Context, owner, repo, token string, maxPages int) ([]*types.PullRequest, error)
+	GetPullRequestsUpdatedSince(ctx context.Context, owner, repo, token string, since *time.Time) ([]*types.PullRequest, error)
+	GetPullRequestsWithDetails(ctx context.Context, owner, repo, token string, since *time.Time) ([]*types.PullRequestDetails, error)
+	SearchPullRequestNumbers(ctx context.Context, owner, repo, token string, from, to time.Time) ([]int, bool, error)
 	GetPullRequestReviewComments(ctx context.Context, owner, repo, token string, prNumber int) ([]*types.ReviewComment, error)
 	GetPullRequest(ctx context.Context, owner, repo, token string, prNumber int) (*types.PullRequest, error)
 	GetPullRequestComments(ctx context.Context, owner, repo, token string, prNumber int) ([]*types.ReviewComment, error)
 	GetPullRequestReviews(ctx context.Context, owner, repo, token string, prNumber int) ([]*types.Review, error)
 	GetPullRequestCommits(ctx context.Context, owner, repo, token string, prNumber int) ([]*types.Commit, error)
+	GetPullRequestTimeline(ctx context.Context, owner, repo, token string, prNumber int) ([]*types.TimelineEvent, error)
+	GetPullRequestFiles(ctx context.Context, owner, repo, token string, prNumber int) ([]*types.PullRequestFile, error)
+	GetFileContent(ctx context.Context, owner, repo, token, path string) ([]byte, error)
+	GetCodeOwners(ctx context.Context, owner, repo, token string) (*CodeOwners, error)
+	GetTeamMembers(ctx context.Context, org, teamSlug, token string) ([]*types.User, error)
+	GetDeployments(ctx context.Context, owner, repo, token string, since *time.Time) ([]*types.Deployment, error)
+	GetDeploymentStatuses(ctx context.Context, owner, repo, token string, deploymentID int) ([]*types.DeploymentStatus, error)
+	GetReleases(ctx context.Context, owner, repo, token string, since *time.Time) ([]*types.Release, error)
+	GetWorkflowRuns(ctx context.Context, owner, repo, token string, since *time.Time) ([]*types.WorkflowRun, error)
+	GetWorkflowRunAttempt(ctx context.Context, owner, repo, token string, runID, attempt int) (*types.WorkflowRun, error)
+	GetCheckRuns(ctx context.Context, owner, repo, token, ref string) ([]*types.CheckRun, error)
+	GetInstallationToken(ctx context.Context, appID, installationID, privateKey string) (string, error)
 }
 
 // Client represents a GitHub API client
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
+
+	// maxRetries is the number of times a request is retried after a 5xx or a short rate limit wait
+	maxRetries int
+	// retryBackoff is the wait before the first retry of a failed request, doubled on every attempt
+	retryBackoff time.Duration
+	// maxRateLimitWait is the longest the client waits for a rate limit to reset before returning a RateLimitError
+	maxRateLimitWait time.Duration
+
+	rateLimits         *rateLimitTracker
+	etags              *etagCache
+	installationTokens *installationTokenCache
 }
 
 // NewClient creates a new GitHub client
 func NewClient() *Client {
 	return &Client{
-		baseURL:    "https://api.github.com",
-		httpClient: &http.Client{},
+		baseURL:            "https://api.github.com",
+		httpClient:         &http.Client{},
+		maxRetries:         3,
+		retryBackoff:       time.Second,
+		maxRateLimitWait:   time.Minute,
+		rateLimits:         newRateLimitTracker(),
+		etags:              newETagCache(etagCacheMaxEntries, etagCacheMaxBytes),
+		installationTokens: newInstallationTokenCache(),
 	}
 }
 
@@ -40,27 +78,10 @@ func (c *Client) GetPullRequests(ctx context.Context, owner, repo, token string,
 
 	for page <= maxPages {
 		pageURL := fmt.Sprintf("%s&page=%d", url, page)
-		req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
-		}
-
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-		req.Header.Set("Accept", "application/vnd.github.v3+json")
-
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("failed to make request: %w", err)
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-		}
 
 		var prs []*types.PullRequest
-		if err := json.NewDecoder(resp.Body).Decode(&prs); err != nil {
-			return nil, fmt.Errorf("failed to decode response: %w", err)
+		if err := c.get(ctx, pageURL, token, &prs); err != nil {
+			return nil, err
 		}
 
 		// If no PRs were returned, we've reached the end
@@ -75,147 +96,464 @@ func (c *Client) GetPullRequests(ctx context.Context, owner, repo, token string,
 	return allPRs, nil
 }
 
-// GetPullRequestReviewComments fetches review comments for a specific pull request
-func (c *Client) GetPullRequestReviewComments(ctx context.Context, owner, repo, token string, prNumber int) ([]*types.ReviewComment, error) {
-	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/comments", c.baseURL, owner, repo, prNumber)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// GetPullRequestsUpdatedSince fetches the pull requests of a repository that were updated after since,
+// most recently updated first. Pages are fetched until a pull request older than since is found, a nil
+// since fetches every pull request of the repository.
+func (c *Client) GetPullRequestsUpdatedSince(ctx context.Context, owner, repo, token string, since *time.Time) ([]*types.PullRequest, error) {
+	var allPRs []*types.PullRequest
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls?state=all&sort=updated&direction=desc&per_page=100", c.baseURL, owner, repo)
+
+	for page := 1; ; page++ {
+		pageURL := fmt.Sprintf("%s&page=%d", url, page)
+
+		var prs []*types.PullRequest
+		if err := c.get(ctx, pageURL, token, &prs); err != nil {
+			return nil, err
+		}
+
+		// If no PRs were returned, we've reached the end
+		if len(prs) == 0 {
+			return allPRs, nil
+		}
+
+		for _, pr := range prs {
+			// PRs are sorted by update time, everything from here on was already synced
+			if since != nil && pr.UpdatedAt.Before(*since) {
+				return allPRs, nil
+			}
+			allPRs = append(allPRs, pr)
+		}
 	}
+}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
+// maxSearchResults is the number of results the search API returns at most for a query
+const maxSearchResults = 1000
+
+// SearchPullRequestNumbers returns the numbers of the pull requests of a repository created from from
+// (inclusive) to to (exclusive), oldest first. The search API has a second precision and returns at most
+// 1000 results per query: truncated is true when the range has more or the search timed out, callers
+// should split it.
+func (c *Client) SearchPullRequestNumbers(ctx context.Context, owner, repo, token string, from, to time.Time) ([]int, bool, error) {
+	// Created ranges are inclusive on both ends
+	query := fmt.Sprintf("repo:%s/%s is:pr created:%s..%s", owner, repo,
+		from.UTC().Format("2006-01-02T15:04:05Z"), to.Add(-time.Second).UTC().Format("2006-01-02T15:04:05Z"))
+	searchURL := fmt.Sprintf("%s/search/issues?q=%s&sort=created&order=asc&per_page=100", c.baseURL, url.QueryEscape(query))
+
+	var numbers []int
+	for page := 1; ; page++ {
+		pageURL := fmt.Sprintf("%s&page=%d", searchURL, page)
+
+		var result types.SearchIssuesResult
+		if err := c.get(ctx, pageURL, token, &result); err != nil {
+			return nil, false, err
+		}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+		// Searches which timed out return incomplete results, a smaller range is more likely to complete
+		if result.TotalCount > maxSearchResults || result.IncompleteResults {
+			return nil, true, nil
+		}
+
+		for _, issue := range result.Items {
+			numbers = append(numbers, issue.Number)
+		}
+
+		if len(result.Items) == 0 || len(numbers) >= result.TotalCount {
+			return numbers, false, nil
+		}
 	}
-	defer resp.Body.Close()
+}
+
+// GetPullRequestReviewComments fetches review comments for a specific pull request
+func (c *Client) GetPullRequestReviewComments(ctx context.Context, owner, repo, token string, prNumber int) ([]*types.ReviewComment, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/comments?per_page=100", c.baseURL, owner, repo, prNumber)
+
+	comments := []*types.ReviewComment{}
+	for page := 1; ; page++ {
+		pageURL := fmt.Sprintf("%s&page=%d", url, page)
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		var pageComments []*types.ReviewComment
+		if err := c.get(ctx, pageURL, token, &pageComments); err != nil {
+			return nil, err
+		}
+
+		// If no comments were returned, we've reached the end
+		if len(pageComments) == 0 {
+			return comments, nil
+		}
+
+		comments = append(comments, pageComments...)
 	}
+}
 
-	var comments []*types.ReviewComment
-	if err := json.NewDecoder(resp.Body).Decode(&comments); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+// GetRepository fetches the details of a repository
+func (c *Client) GetRepository(ctx context.Context, owner, repo, token string) (*types.Repo, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s", c.baseURL, owner, repo)
+
+	var repository types.Repo
+	if err := c.get(ctx, url, token, &repository); err != nil {
+		return nil, err
 	}
 
-	return comments, nil
+	return &repository, nil
 }
 
 // GetPullRequest fetches details of a single pull request
 func (c *Client) GetPullRequest(ctx context.Context, owner, repo, token string, prNumber int) (*types.PullRequest, error) {
 	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d", c.baseURL, owner, repo, prNumber)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+
+	var pr types.PullRequest
+	if err := c.get(ctx, url, token, &pr); err != nil {
+		return nil, err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	return &pr, nil
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+// GetPullRequestComments fetches regular comments for a specific pull request
+func (c *Client) GetPullRequestComments(ctx context.Context, owner, repo, token string, prNumber int) ([]*types.ReviewComment, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d/comments?per_page=100", c.baseURL, owner, repo, prNumber)
+
+	comments := []*types.ReviewComment{}
+	for page := 1; ; page++ {
+		pageURL := fmt.Sprintf("%s&page=%d", url, page)
+
+		var pageComments []*types.ReviewComment
+		if err := c.get(ctx, pageURL, token, &pageComments); err != nil {
+			return nil, err
+		}
+
+		// If no comments were returned, we've reached the end
+		if len(pageComments) == 0 {
+			return comments, nil
+		}
+
+		comments = append(comments, pageComments...)
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+// GetPullRequestReviews fetches reviews for a specific pull request
+func (c *Client) GetPullRequestReviews(ctx context.Context, owner, repo, token string, prNumber int) ([]*types.Review, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/reviews?per_page=100", c.baseURL, owner, repo, prNumber)
+
+	reviews := []*types.Review{}
+	for page := 1; ; page++ {
+		pageURL := fmt.Sprintf("%s&page=%d", url, page)
+
+		var pageReviews []*types.Review
+		if err := c.get(ctx, pageURL, token, &pageReviews); err != nil {
+			return nil, err
+		}
+
+		// If no reviews were returned, we've reached the end
+		if len(pageReviews) == 0 {
+			return reviews, nil
+		}
+
+		reviews = append(reviews, pageReviews...)
 	}
+}
 
-	var pr types.PullRequest
-	if err := json.NewDecoder(resp.Body).Decode(&pr); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+// GetPullRequestCommits fetches commits for a specific pull request, GitHub lists at most 250 commits
+func (c *Client) GetPullRequestCommits(ctx context.Context, owner, repo, token string, prNumber int) ([]*types.Commit, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/commits?per_page=100", c.baseURL, owner, repo, prNumber)
+
+	commits := []*types.Commit{}
+	for page := 1; ; page++ {
+		pageURL := fmt.Sprintf("%s&page=%d", url, page)
+
+		var pageCommits []*types.Commit
+		if err := c.get(ctx, pageURL, token, &pageCommits); err != nil {
+			return nil, err
+		}
+
+		// If no commits were returned, we've reached the end
+		if len(pageCommits) == 0 {
+			return commits, nil
+		}
+
+		commits = append(commits, pageCommits...)
 	}
+}
 
-	return &pr, nil
+// GetPullRequestTimeline fetches the timeline events of a specific pull request which change its state or
+// reviewers, oldest first. Comments, reviews and commits are left out, they are fetched separately.
+func (c *Client) GetPullRequestTimeline(ctx context.Context, owner, repo, token string, prNumber int) ([]*types.TimelineEvent, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d/timeline?per_page=100", c.baseURL, owner, repo, prNumber)
+
+	events := []*types.TimelineEvent{}
+	for page := 1; ; page++ {
+		pageURL := fmt.Sprintf("%s&page=%d", url, page)
+
+		var pageEvents []*types.TimelineEvent
+		if err := c.get(ctx, pageURL, token, &pageEvents); err != nil {
+			return nil, err
+		}
+
+		// If no events were returned, we've reached the end
+		if len(pageEvents) == 0 {
+			return events, nil
+		}
+
+		for _, event := range pageEvents {
+			if isTrackedTimelineEvent(event.Event) {
+				events = append(events, event)
+			}
+		}
+	}
 }
 
-// GetPullRequestComments fetches regular comments for a specific pull request
-func (c *Client) GetPullRequestComments(ctx context.Context, owner, repo, token string, prNumber int) ([]*types.ReviewComment, error) {
-	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d/comments", c.baseURL, owner, repo, prNumber)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// GetPullRequestFiles fetches the files changed by a specific pull request. GitHub lists at most 3000 files.
+func (c *Client) GetPullRequestFiles(ctx context.Context, owner, repo, token string, prNumber int) ([]*types.PullRequestFile, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/files?per_page=100", c.baseURL, owner, repo, prNumber)
+
+	files := []*types.PullRequestFile{}
+	for page := 1; ; page++ {
+		pageURL := fmt.Sprintf("%s&page=%d", url, page)
+
+		var pageFiles []*types.PullRequestFile
+		if err := c.get(ctx, pageURL, token, &pageFiles); err != nil {
+			return nil, err
+		}
+
+		// If no files were returned, we've reached the end
+		if len(pageFiles) == 0 {
+			return files, nil
+		}
+
+		files = append(files, pageFiles...)
 	}
+}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
+// GetFileContent fetches the content of a file on the default branch of a repository. Returns ErrNotFound if
+// the file doesn't exist.
+func (c *Client) GetFileContent(ctx context.Context, owner, repo, token, path string) ([]byte, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/contents/%s", c.baseURL, owner, repo, path)
 
-	resp, err := c.httpClient.Do(req)
+	var file types.FileContent
+	if err := c.get(ctx, url, token, &file); err != nil {
+		return nil, err
+	}
+
+	if file.Encoding != "base64" {
+		return nil, fmt.Errorf("unexpected encoding of %s: %s", path, file.Encoding)
+	}
+
+	// GitHub wraps the base64 content in lines of 60 characters
+	content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+		return nil, fmt.Errorf("failed to decode content of %s: %w", path, err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	return content, nil
+}
+
+// GetCodeOwners fetches and parses the CODEOWNERS file of a repository from the first location GitHub looks it
+// up in. Returns nil if the repository has no CODEOWNERS file.
+func (c *Client) GetCodeOwners(ctx context.Context, owner, repo, token string) (*CodeOwners, error) {
+	for _, path := range codeOwnersPaths {
+		content, err := c.GetFileContent(ctx, owner, repo, token, path)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return ParseCodeOwners(string(content)), nil
 	}
 
-	var comments []*types.ReviewComment
-	if err := json.NewDecoder(resp.Body).Decode(&comments); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	return nil, nil
+}
+
+// GetTeamMembers fetches the members of a team of an organization, including the members of its child teams
+func (c *Client) GetTeamMembers(ctx context.Context, org, teamSlug, token string) ([]*types.User, error) {
+	url := fmt.Sprintf("%s/orgs/%s/teams/%s/members?per_page=100", c.baseURL, org, teamSlug)
+
+	members := []*types.User{}
+	for page := 1; ; page++ {
+		pageURL := fmt.Sprintf("%s&page=%d", url, page)
+
+		var pageMembers []*types.User
+		if err := c.get(ctx, pageURL, token, &pageMembers); err != nil {
+			return nil, err
+		}
+
+		// If no members were returned, we've reached the end
+		if len(pageMembers) == 0 {
+			return members, nil
+		}
+
+		members = append(members, pageMembers...)
 	}
+}
+
+// GetDeployments fetches the deployments of a repository created since the given time, newest first. All
+// deployments are fetched if since is nil.
+func (c *Client) GetDeployments(ctx context.Context, owner, repo, token string, since *time.Time) ([]*types.Deployment, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/deployments?per_page=100", c.baseURL, owner, repo)
+
+	deployments := []*types.Deployment{}
+	for page := 1; ; page++ {
+		pageURL := fmt.Sprintf("%s&page=%d", url, page)
+
+		var pageDeployments []*types.Deployment
+		if err := c.get(ctx, pageURL, token, &pageDeployments); err != nil {
+			return nil, err
+		}
+
+		// If no deployments were returned, we've reached the end
+		if len(pageDeployments) == 0 {
+			return deployments, nil
+		}
 
-	return comments, nil
+		// Deployments are listed newest first, stop at the first one created before since
+		for _, deployment := range pageDeployments {
+			if since != nil && deployment.CreatedAt.Before(*since) {
+				return deployments, nil
+			}
+			deployments = append(deployments, deployment)
+		}
+	}
 }
 
-// GetPullRequestReviewComments fetches review comments for a specific pull request
-func (c *Client) GetPullRequestReviews(ctx context.Context, owner, repo, token string, prNumber int) ([]*types.Review, error) {
-	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/reviews", c.baseURL, owner, repo, prNumber)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// GetDeploymentStatuses fetches the statuses of a specific deployment, newest first
+func (c *Client) GetDeploymentStatuses(ctx context.Context, owner, repo, token string, deploymentID int) ([]*types.DeploymentStatus, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/deployments/%d/statuses?per_page=100", c.baseURL, owner, repo, deploymentID)
+
+	statuses := []*types.DeploymentStatus{}
+	for page := 1; ; page++ {
+		pageURL := fmt.Sprintf("%s&page=%d", url, page)
+
+		var pageStatuses []*types.DeploymentStatus
+		if err := c.get(ctx, pageURL, token, &pageStatuses); err != nil {
+			return nil, err
+		}
+
+		// If no statuses were returned, we've reached the end
+		if len(pageStatuses) == 0 {
+			return statuses, nil
+		}
+
+		statuses = append(statuses, pageStatuses...)
 	}
+}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
+// GetReleases fetches the releases of a repository created since the given time, newest first. All releases are
+// fetched if since is nil.
+func (c *Client) GetReleases(ctx context.Context, owner, repo, token string, since *time.Time) ([]*types.Release, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/releases?per_page=100", c.baseURL, owner, repo)
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+	releases := []*types.Release{}
+	for page := 1; ; page++ {
+		pageURL := fmt.Sprintf("%s&page=%d", url, page)
+
+		var pageReleases []*types.Release
+		if err := c.get(ctx, pageURL, token, &pageReleases); err != nil {
+			return nil, err
+		}
+
+		// If no releases were returned, we've reached the end
+		if len(pageReleases) == 0 {
+			return releases, nil
+		}
+
+		// Releases are listed newest first, stop at the first one created before since
+		for _, release := range pageReleases {
+			if since != nil && release.CreatedAt.Before(*since) {
+				return releases, nil
+			}
+			releases = append(releases, release)
+		}
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+// maxWorkflowRunPages is the number of pages of workflow runs fetched at most. GitHub returns at most 1000 runs for
+// a filtered list, without a lower bound the runs of busy repositories would be paged through their whole history.
+const maxWorkflowRunPages = 10
+
+// GetWorkflowRuns fetches the GitHub Actions workflow runs of a repository created since the given time, newest
+// first. At most maxWorkflowRunPages pages are fetched, only the most recent runs are fetched if since is nil.
+// Runs are listed with their latest attempt.
+func (c *Client) GetWorkflowRuns(ctx context.Context, owner, repo, token string, since *time.Time) ([]*types.WorkflowRun, error) {
+	runsURL := fmt.Sprintf("%s/repos/%s/%s/actions/runs?per_page=100", c.baseURL, owner, repo)
+	if since != nil {
+		runsURL += "&created=" + url.QueryEscape(">="+since.UTC().Format(time.RFC3339))
 	}
 
-	var reviews []*types.Review
-	if err := json.NewDecoder(resp.Body).Decode(&reviews); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	runs := []*types.WorkflowRun{}
+	for page := 1; page <= maxWorkflowRunPages; page++ {
+		pageURL := fmt.Sprintf("%s&page=%d", runsURL, page)
+
+		var pageRuns types.WorkflowRunsPage
+		if err := c.get(ctx, pageURL, token, &pageRuns); err != nil {
+			return nil, err
+		}
+
+		// If no runs were returned, we've reached the end
+		if len(pageRuns.WorkflowRuns) == 0 {
+			return runs, nil
+		}
+
+		runs = append(runs, pageRuns.WorkflowRuns...)
 	}
 
-	return reviews, nil
+	return runs, nil
 }
 
-// GetPullRequestCommits fetches commits for a specific pull request
-func (c *Client) GetPullRequestCommits(ctx context.Context, owner, repo, token string, prNumber int) ([]*types.Commit, error) {
-	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/commits", c.baseURL, owner, repo, prNumber)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// GetWorkflowRunAttempt fetches an attempt of a GitHub Actions workflow run
+func (c *Client) GetWorkflowRunAttempt(ctx context.Context, owner, repo, token string, runID, attempt int) (*types.WorkflowRun, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/actions/runs/%d/attempts/%d", c.baseURL, owner, repo, runID, attempt)
+
+	var run types.WorkflowRun
+	if err := c.get(ctx, url, token, &run); err != nil {
+		return nil, err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	return &run, nil
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+// GetCheckRuns fetches the check runs of a commit
+func (c *Client) GetCheckRuns(ctx context.Context, owner, repo, token, ref string) ([]*types.CheckRun, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/commits/%s/check-runs?per_page=100", c.baseURL, owner, repo, ref)
+
+	checkRuns := []*types.CheckRun{}
+	for page := 1; ; page++ {
+		pageURL := fmt.Sprintf("%s&page=%d", url, page)
+
+		var pageCheckRuns types.CheckRunsPage
+		if err := c.get(ctx, pageURL, token, &pageCheckRuns); err != nil {
+			return nil, err
+		}
+
+		// If no check runs were returned, we've reached the end
+		if len(pageCheckRuns.CheckRuns) == 0 {
+			return checkRuns, nil
+		}
+
+		checkRuns = append(checkRuns, pageCheckRuns.CheckRuns...)
+	}
+}
+
+// isTrackedTimelineEvent reports whether a timeline event is one of the events fetched with the pull requests
+func isTrackedTimelineEvent(event string) bool {
+	for _, tracked := range timelineEventTypes {
+		if tracked == event {
+			return true
+		}
 	}
-	defer resp.Body.Close()
+	return false
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+// get performs a GET request through fetch and decodes the JSON response into out
+func (c *Client) get(ctx context.Context, url, token string, out interface{}) error {
+	body, err := c.fetch(ctx, url, token)
+	if err != nil {
+		return err
 	}
 
-	var commits []*types.Commit
-	if err := json.NewDecoder(resp.Body).Decode(&commits); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return commits, nil
+	return nil
 }
@@ -0,0 +1,129 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ems.dev/backend/libraries/github/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPullRequestTimeline(t *testing.T) {
+	pages := map[string]string{
+		"1": `[
+			{"event": "commented", "node_id": "C_1"},
+			{"event": "ready_for_review", "node_id": "RFR_1", "actor": {"login": "alice"}},
+			{"event": "review_requested", "node_id": "RR_1", "requested_reviewer": {"login": "bob"}}
+		]`,
+		"2": `[
+			{"event": "committed"},
+			{"event": "closed", "node_id": "CE_1"},
+			{"event": "reopened", "node_id": "RE_1"}
+		]`,
+	}
+
+	var requestedPages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/owner/repo/issues/7/timeline", r.URL.Path)
+		page := r.URL.Query().Get("page")
+		requestedPages = append(requestedPages, page)
+
+		body, ok := pages[page]
+		if !ok {
+			body = `[]`
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	events, err := newTestClient(server.URL).GetPullRequestTimeline(context.Background(), "owner", "repo", "token", 7)
+	require.NoError(t, err)
+
+	nodeIDs := make([]string, 0, len(events))
+	for _, event := range events {
+		nodeIDs = append(nodeIDs, event.NodeID)
+	}
+	assert.Equal(t, []string{"RFR_1", "RR_1", "CE_1", "RE_1"}, nodeIDs)
+	assert.Equal(t, "alice", events[0].Actor.Login)
+	require.NotNil(t, events[1].RequestedReviewer)
+	assert.Equal(t, "bob", events[1].RequestedReviewer.Login)
+	assert.Equal(t, []string{"1", "2", "3"}, requestedPages)
+}
+
+func TestGetWorkflowRuns(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		since            *time.Time
+		pages            int // pages with runs, the server keeps returning runs if negative
+		expectedRuns     int
+		expectedRequests int
+		expectedCreated  string
+	}{
+		{
+			name:             "runs since a time",
+			since:            &since,
+			pages:            2,
+			expectedRuns:     2,
+			expectedRequests: 3,
+			expectedCreated:  ">=2024-01-01T00:00:00Z",
+		},
+		{
+			name:             "most recent runs",
+			pages:            1,
+			expectedRuns:     1,
+			expectedRequests: 2,
+		},
+		{
+			name:             "pages are capped",
+			pages:            -1,
+			expectedRuns:     maxWorkflowRunPages,
+			expectedRequests: maxWorkflowRunPages,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/repos/owner/repo/actions/runs", r.URL.Path)
+				assert.Equal(t, tt.expectedCreated, r.URL.Query().Get("created"))
+				requests++
+
+				if tt.pages >= 0 && requests > tt.pages {
+					w.Write([]byte(`{"total_count": 0, "workflow_runs": []}`))
+					return
+				}
+				fmt.Fprintf(w, `{"total_count": 1, "workflow_runs": [{"id": %d, "run_attempt": 1}]}`, requests)
+			}))
+			defer server.Close()
+
+			runs, err := newTestClient(server.URL).GetWorkflowRuns(context.Background(), "owner", "repo", "token", tt.since)
+			require.NoError(t, err)
+			assert.Len(t, runs, tt.expectedRuns)
+			assert.Equal(t, tt.expectedRequests, requests)
+		})
+	}
+}
+
+func TestGetWorkflowRunAttempt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/owner/repo/actions/runs/42/attempts/2", r.URL.Path)
+		w.Write([]byte(`{"id": 42, "status": "completed", "conclusion": "failure", "run_attempt": 2, "pull_requests": [{"id": 7, "number": 3}]}`))
+	}))
+	defer server.Close()
+
+	run, err := newTestClient(server.URL).GetWorkflowRunAttempt(context.Background(), "owner", "repo", "token", 42, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 42, run.ID)
+	assert.Equal(t, 2, run.RunAttempt)
+	require.NotNil(t, run.Conclusion)
+	assert.Equal(t, "failure", *run.Conclusion)
+	assert.Equal(t, []types.WorkflowRunPullRequest{{ID: 7, Number: 3}}, run.PullRequests)
+}
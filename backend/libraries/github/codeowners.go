@@ -0,0 +1,60 @@
+package github
+
+import (
+	"regexp"
+	"strings"
+
+	"ems.dev/backend/libraries/pathglob"
+)
+
+// codeOwnersPaths are the locations GitHub looks up the CODEOWNERS file of a repository in, in order
+var codeOwnersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// CodeOwners represents the rules of a CODEOWNERS file
+type CodeOwners struct {
+	rules []codeOwnersRule
+}
+
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// ParseCodeOwners parses the content of a CODEOWNERS file. Lines with invalid patterns are skipped, as GitHub
+// does.
+func ParseCodeOwners(content string) *CodeOwners {
+	codeOwners := &CodeOwners{}
+	for _, line := range strings.Split(content, "\n") {
+		// Comments can also follow a rule
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		pattern, err := pathglob.CompileGitignore(fields[0])
+		if err != nil {
+			continue
+		}
+
+		codeOwners.rules = append(codeOwners.rules, codeOwnersRule{
+			pattern: pattern,
+			owners:  fields[1:],
+		})
+	}
+	return codeOwners
+}
+
+// Owners returns the owners of a file, @user or @org/team handles or emails. The last rule matching the path
+// takes precedence, a matching rule without owners leaves the file without owners.
+func (co *CodeOwners) Owners(path string) []string {
+	for i := len(co.rules) - 1; i >= 0; i-- {
+		if co.rules[i].pattern.MatchString(path) {
+			return co.rules[i].owners
+		}
+	}
+	return nil
+}
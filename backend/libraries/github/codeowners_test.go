@@ -0,0 +1,46 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeOwnersOwners(t *testing.T) {
+	content := `# Default owners
+*                   @org/everyone
+*.go                @org/backend    # Go files
+
+/docs/              @org/writers
+docs/internal/*     @alice
+apps/**/tests       @org/qa
+/vendor/
+`
+
+	tests := []struct {
+		name     string
+		path     string
+		expected []string
+	}{
+		{name: "falls back on the first rule", path: "README.md", expected: []string{"@org/everyone"}},
+		{name: "later rule wins", path: "cmd/main.go", expected: []string{"@org/backend"}},
+		{name: "directory rule covers nested files", path: "docs/guides/setup.md", expected: []string{"@org/writers"}},
+		{name: "later directory rule wins over an extension rule", path: "docs/example.go", expected: []string{"@org/writers"}},
+		{name: "trailing star covers direct children", path: "docs/internal/plan.md", expected: []string{"@alice"}},
+		{name: "trailing star doesn't cover nested files", path: "docs/internal/old/plan.md", expected: []string{"@org/writers"}},
+		{name: "double star", path: "apps/web/src/tests/app_test.go", expected: []string{"@org/qa"}},
+		{name: "rule without owners removes the owners", path: "vendor/lib/lib.go", expected: []string{}},
+	}
+
+	codeOwners := ParseCodeOwners(content)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, codeOwners.Owners(tt.path))
+		})
+	}
+}
+
+func TestCodeOwnersWithoutRules(t *testing.T) {
+	codeOwners := ParseCodeOwners("# no rules\n\n")
+	assert.Nil(t, codeOwners.Owners("main.go"))
+}
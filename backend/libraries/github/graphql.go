@@ -0,0 +1,502 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"ems.dev/backend/libraries/github/types"
+)
+
+// pullRequestsQuery fetches a page of pull requests, most recently updated first, with their reviews,
+// comments, commits, changed files and timeline. Nested connections are limited to keep the query within GitHub's
+// node limit, pull requests with more items are completed with the REST API.
+const pullRequestsQuery = `
+query($owner: String!, $name: String!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    pullRequests(first: 20, after: $cursor, orderBy: {field: UPDATED_AT, direction: DESC}) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        databaseId
+        number
+        title
+        body
+        url
+        state
+        isDraft
+        createdAt
+        updatedAt
+        mergedAt
+        closedAt
+        additions
+        deletions
+        changedFiles
+        baseRefName
+        headRefName
+        headRefOid
+        mergeCommit { oid }
+        author { ...actor }
+        labels(first: 20) { nodes { name } }
+        comments(first: 100) {
+          pageInfo { hasNextPage }
+          nodes { databaseId body createdAt updatedAt author { ...actor } }
+        }
+        reviews(first: 50) {
+          pageInfo { hasNextPage }
+          nodes {
+            databaseId
+            body
+            state
+            submittedAt
+            author { ...actor }
+            comments(first: 50) {
+              pageInfo { hasNextPage }
+              nodes { databaseId body createdAt updatedAt author { ...actor } }
+            }
+          }
+        }
+        commits(first: 100) {
+          totalCount
+          pageInfo { hasNextPage }
+          nodes {
+            commit {
+              oid
+              message
+              additions
+              deletions
+              author { name email date user { ...actor } }
+              committer { name email date }
+            }
+          }
+        }
+        files(first: 100) {
+          pageInfo { hasNextPage }
+          nodes { path additions deletions changeType }
+        }
+        timelineItems(first: 100, itemTypes: [READY_FOR_REVIEW_EVENT, CONVERT_TO_DRAFT_EVENT, REVIEW_REQUESTED_EVENT, MERGED_EVENT, CLOSED_EVENT, REOPENED_EVENT]) {
+          pageInfo { hasNextPage }
+          nodes {
+            __typename
+            ... on ReadyForReviewEvent { id createdAt actor { ...actor } }
+            ... on ConvertToDraftEvent { id createdAt actor { ...actor } }
+            ... on ReviewRequestedEvent {
+              id
+              createdAt
+              actor { ...actor }
+              requestedReviewer { ... on User { ...actor } ... on Bot { ...actor } }
+            }
+            ... on MergedEvent { id createdAt actor { ...actor } }
+            ... on ClosedEvent { id createdAt actor { ...actor } }
+            ... on ReopenedEvent { id createdAt actor { ...actor } }
+          }
+        }
+      }
+    }
+  }
+}
+
+fragment actor on Actor {
+  __typename
+  login
+  avatarUrl
+  ... on User { databaseId name email }
+  ... on Bot { databaseId }
+}`
+
+// timelineEventTypes maps GraphQL timeline item types to the REST timeline event names
+var timelineEventTypes = map[string]string{
+	"ReadyForReviewEvent":  types.TimelineEventReadyForReview,
+	"ConvertToDraftEvent":  types.TimelineEventConvertToDraft,
+	"ReviewRequestedEvent": types.TimelineEventReviewRequested,
+	"MergedEvent":          types.TimelineEventMerged,
+	"ClosedEvent":          types.TimelineEventClosed,
+	"ReopenedEvent":        types.TimelineEventReopened,
+}
+
+// fileStatuses maps GraphQL file change types to the REST file statuses
+var fileStatuses = map[string]string{
+	"ADDED":    "added",
+	"DELETED":  "removed",
+	"MODIFIED": "modified",
+	"RENAMED":  "renamed",
+	"COPIED":   "copied",
+	"CHANGED":  "changed",
+}
+
+type graphQLPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+type graphQLActor struct {
+	Typename   string `json:"__typename"`
+	Login      string `json:"login"`
+	AvatarURL  string `json:"avatarUrl"`
+	DatabaseID int    `json:"databaseId"`
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+}
+
+type graphQLComment struct {
+	DatabaseID int           `json:"databaseId"`
+	Body       string        `json:"body"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	UpdatedAt  time.Time     `json:"updatedAt"`
+	Author     *graphQLActor `json:"author"`
+}
+
+type graphQLComments struct {
+	PageInfo graphQLPageInfo  `json:"pageInfo"`
+	Nodes    []graphQLComment `json:"nodes"`
+}
+
+type graphQLCommitPerson struct {
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	Date  time.Time `json:"date"`
+	// User is only queried for commit authors, it is nil when the email isn't linked to an account
+	User *graphQLActor `json:"user"`
+}
+
+type graphQLPullRequest struct {
+	DatabaseID   int           `json:"databaseId"`
+	Number       int           `json:"number"`
+	Title        string        `json:"title"`
+	Body         string        `json:"body"`
+	URL          string        `json:"url"`
+	State        string        `json:"state"`
+	IsDraft      bool          `json:"isDraft"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	UpdatedAt    time.Time     `json:"updatedAt"`
+	MergedAt     *time.Time    `json:"mergedAt"`
+	ClosedAt     *time.Time    `json:"closedAt"`
+	Additions    int           `json:"additions"`
+	Deletions    int           `json:"deletions"`
+	ChangedFiles int           `json:"changedFiles"`
+	BaseRefName  string        `json:"baseRefName"`
+	HeadRefName  string        `json:"headRefName"`
+	HeadRefOid   string        `json:"headRefOid"`
+	Author       *graphQLActor `json:"author"`
+	MergeCommit  *struct {
+		Oid string `json:"oid"`
+	} `json:"mergeCommit"`
+	Labels struct {
+		Nodes []types.Label `json:"nodes"`
+	} `json:"labels"`
+	Comments graphQLComments `json:"comments"`
+	Reviews  struct {
+		PageInfo graphQLPageInfo `json:"pageInfo"`
+		Nodes    []struct {
+			DatabaseID  int             `json:"databaseId"`
+			Body        string          `json:"body"`
+			State       string          `json:"state"`
+			SubmittedAt *time.Time      `json:"submittedAt"`
+			Author      *graphQLActor   `json:"author"`
+			Comments    graphQLComments `json:"comments"`
+		} `json:"nodes"`
+	} `json:"reviews"`
+	Commits struct {
+		TotalCount int             `json:"totalCount"`
+		PageInfo   graphQLPageInfo `json:"pageInfo"`
+		Nodes      []struct {
+			Commit struct {
+				Oid       string              `json:"oid"`
+				Message   string              `json:"message"`
+				Additions int                 `json:"additions"`
+				Deletions int                 `json:"deletions"`
+				Author    graphQLCommitPerson `json:"author"`
+				Committer graphQLCommitPerson `json:"committer"`
+			} `json:"commit"`
+		} `json:"nodes"`
+	} `json:"commits"`
+	Files struct {
+		PageInfo graphQLPageInfo `json:"pageInfo"`
+		Nodes    []struct {
+			Path       string `json:"path"`
+			Additions  int    `json:"additions"`
+			Deletions  int    `json:"deletions"`
+			ChangeType string `json:"changeType"`
+		} `json:"nodes"`
+	} `json:"files"`
+	TimelineItems struct {
+		PageInfo graphQLPageInfo `json:"pageInfo"`
+		Nodes    []struct {
+			Typename          string        `json:"__typename"`
+			ID                string        `json:"id"`
+			CreatedAt         time.Time     `json:"createdAt"`
+			Actor             *graphQLActor `json:"actor"`
+			RequestedReviewer *graphQLActor `json:"requestedReviewer"`
+		} `json:"nodes"`
+	} `json:"timelineItems"`
+}
+
+type graphQLPullRequestsResponse struct {
+	Data struct {
+		Repository *struct {
+			PullRequests struct {
+				PageInfo graphQLPageInfo      `json:"pageInfo"`
+				Nodes    []graphQLPullRequest `json:"nodes"`
+			} `json:"pullRequests"`
+		} `json:"repository"`
+	} `json:"data"`
+	Errors []struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// GetPullRequestsWithDetails fetches the pull requests of a repository that were updated after since, with
+// their reviews, comments, commits and timeline, using the GraphQL API. It replaces a GetPullRequestsUpdatedSince
+// call followed by five REST calls per pull request with one query per page of pull requests. A nil since
+// fetches every pull request of the repository.
+func (c *Client) GetPullRequestsWithDetails(ctx context.Context, owner, repo, token string, since *time.Time) ([]*types.PullRequestDetails, error) {
+	var allPRs []*types.PullRequestDetails
+	var cursor *string
+
+	for {
+		payload, err := json.Marshal(map[string]interface{}{
+			"query": pullRequestsQuery,
+			"variables": map[string]interface{}{
+				"owner":  owner,
+				"name":   repo,
+				"cursor": cursor,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode query: %w", err)
+		}
+
+		body, err := c.do(ctx, "POST", c.baseURL+"/graphql", token, payload)
+		if err != nil {
+			return nil, err
+		}
+
+		var resp graphQLPullRequestsResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		if len(resp.Errors) > 0 {
+			return nil, c.graphQLError(token, resp.Errors[0].Type, resp.Errors[0].Message)
+		}
+		if resp.Data.Repository == nil {
+			return nil, fmt.Errorf("repository %s/%s not found", owner, repo)
+		}
+
+		pullRequests := resp.Data.Repository.PullRequests
+		for i := range pullRequests.Nodes {
+			node := &pullRequests.Nodes[i]
+
+			// PRs are sorted by update time, everything from here on was already synced
+			if since != nil && node.UpdatedAt.Before(*since) {
+				return allPRs, nil
+			}
+
+			details, err := c.convertGraphQLPullRequest(ctx, owner, repo, token, node)
+			if err != nil {
+				return nil, err
+			}
+			allPRs = append(allPRs, details)
+		}
+
+		if !pullRequests.PageInfo.HasNextPage {
+			return allPRs, nil
+		}
+		cursor = &pullRequests.PageInfo.EndCursor
+	}
+}
+
+// graphQLError converts a GraphQL error. GraphQL rate limits are reported with a 200 response and a
+// RATE_LIMITED error instead of a 403.
+func (c *Client) graphQLError(token, errorType, message string) error {
+	if errorType != "RATE_LIMITED" {
+		return fmt.Errorf("graphql error: %s", message)
+	}
+
+	if resetAt, exhausted := c.rateLimits.exhaustedUntil(token); exhausted {
+		return &RateLimitError{ResetAt: resetAt}
+	}
+	return &RateLimitError{ResetAt: time.Now().Add(secondaryRateLimitWait), Secondary: true}
+}
+
+// convertGraphQLPullRequest converts a GraphQL pull request to the REST types. Connections which didn't
+// fit in the query are fetched with the REST API.
+func (c *Client) convertGraphQLPullRequest(ctx context.Context, owner, repo, token string, node *graphQLPullRequest) (*types.PullRequestDetails, error) {
+	pr := &types.PullRequest{
+		ID:           node.DatabaseID,
+		Number:       node.Number,
+		State:        strings.ToLower(node.State),
+		Title:        node.Title,
+		Body:         node.Body,
+		URL:          node.URL,
+		CreatedAt:    node.CreatedAt,
+		UpdatedAt:    node.UpdatedAt,
+		MergedAt:     node.MergedAt,
+		ClosedAt:     node.ClosedAt,
+		Additions:    node.Additions,
+		Deletions:    node.Deletions,
+		ChangedFiles: node.ChangedFiles,
+		Draft:        node.IsDraft,
+		Commits:      node.Commits.TotalCount,
+		User:         node.Author.toUser(),
+		Head:         types.Ref{Ref: node.HeadRefName, Sha: node.HeadRefOid},
+		Base:         types.Ref{Ref: node.BaseRefName},
+		Labels:       node.Labels.Nodes,
+	}
+	// The REST API reports merged pull requests as closed
+	if pr.State == "merged" {
+		pr.State = "closed"
+	}
+	if node.MergeCommit != nil {
+		pr.MergeCommitSHA = node.MergeCommit.Oid
+	}
+
+	details := &types.PullRequestDetails{
+		PullRequest:    pr,
+		Reviews:        []*types.Review{},
+		ReviewComments: []*types.ReviewComment{},
+		Comments:       []*types.ReviewComment{},
+		Commits:        []*types.Commit{},
+		Timeline:       []*types.TimelineEvent{},
+		Files:          []*types.PullRequestFile{},
+	}
+
+	var err error
+	if node.Comments.PageInfo.HasNextPage {
+		if details.Comments, err = c.GetPullRequestComments(ctx, owner, repo, token, node.Number); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, comment := range node.Comments.Nodes {
+			details.Comments = append(details.Comments, comment.toReviewComment())
+		}
+	}
+
+	reviewCommentsTruncated := false
+	if node.Reviews.PageInfo.HasNextPage {
+		if details.Reviews, err = c.GetPullRequestReviews(ctx, owner, repo, token, node.Number); err != nil {
+			return nil, err
+		}
+		reviewCommentsTruncated = true
+	} else {
+		for _, review := range node.Reviews.Nodes {
+			var submittedAt time.Time
+			if review.SubmittedAt != nil {
+				submittedAt = *review.SubmittedAt
+			}
+			details.Reviews = append(details.Reviews, &types.Review{
+				ID:          review.DatabaseID,
+				User:        review.Author.toUser(),
+				Body:        review.Body,
+				State:       review.State,
+				SubmittedAt: submittedAt,
+			})
+
+			reviewCommentsTruncated = reviewCommentsTruncated || review.Comments.PageInfo.HasNextPage
+			for _, comment := range review.Comments.Nodes {
+				details.ReviewComments = append(details.ReviewComments, comment.toReviewComment())
+			}
+		}
+	}
+
+	if reviewCommentsTruncated {
+		if details.ReviewComments, err = c.GetPullRequestReviewComments(ctx, owner, repo, token, node.Number); err != nil {
+			return nil, err
+		}
+	}
+
+	if node.Commits.PageInfo.HasNextPage {
+		if details.Commits, err = c.GetPullRequestCommits(ctx, owner, repo, token, node.Number); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, commitNode := range node.Commits.Nodes {
+			commit := &types.Commit{Sha: commitNode.Commit.Oid}
+			commit.Commit.Message = commitNode.Commit.Message
+			commit.Commit.Author.Name = commitNode.Commit.Author.Name
+			commit.Commit.Author.Email = commitNode.Commit.Author.Email
+			commit.Commit.Author.Date = commitNode.Commit.Author.Date
+			commit.Commit.Committer.Name = commitNode.Commit.Committer.Name
+			commit.Commit.Committer.Email = commitNode.Commit.Committer.Email
+			commit.Commit.Committer.Date = commitNode.Commit.Committer.Date
+			commit.Stats = &types.CommitStats{
+				Additions: commitNode.Commit.Additions,
+				Deletions: commitNode.Commit.Deletions,
+			}
+			if commitNode.Commit.Author.User != nil {
+				author := commitNode.Commit.Author.User.toUser()
+				commit.Author = &author
+			}
+			details.Commits = append(details.Commits, commit)
+		}
+	}
+
+	if node.Files.PageInfo.HasNextPage {
+		if details.Files, err = c.GetPullRequestFiles(ctx, owner, repo, token, node.Number); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, file := range node.Files.Nodes {
+			details.Files = append(details.Files, &types.PullRequestFile{
+				Filename:  file.Path,
+				Status:    fileStatuses[file.ChangeType],
+				Additions: file.Additions,
+				Deletions: file.Deletions,
+			})
+		}
+	}
+
+	if node.TimelineItems.PageInfo.HasNextPage {
+		if details.Timeline, err = c.GetPullRequestTimeline(ctx, owner, repo, token, node.Number); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, item := range node.TimelineItems.Nodes {
+			event := &types.TimelineEvent{
+				Event:     timelineEventTypes[item.Typename],
+				Actor:     item.Actor.toUser(),
+				CreatedAt: item.CreatedAt,
+				NodeID:    item.ID,
+			}
+			// Team review requests have no login, they are reported without a reviewer like in the REST API
+			if item.RequestedReviewer != nil && item.RequestedReviewer.Login != "" {
+				reviewer := item.RequestedReviewer.toUser()
+				event.RequestedReviewer = &reviewer
+			}
+			details.Timeline = append(details.Timeline, event)
+		}
+	}
+
+	pr.Comments = len(details.Comments)
+	pr.ReviewComments = len(details.ReviewComments)
+
+	return details, nil
+}
+
+// toUser converts a GraphQL actor to a REST user. Deleted accounts have no actor, the REST API
+// reports them as the "ghost" user.
+func (a *graphQLActor) toUser() types.User {
+	if a == nil {
+		return types.User{Login: "ghost", Type: "User"}
+	}
+	return types.User{
+		Login:     a.Login,
+		ID:        a.DatabaseID,
+		AvatarURL: a.AvatarURL,
+		Type:      a.Typename,
+		Name:      a.Name,
+		Email:     a.Email,
+	}
+}
+
+func (c *graphQLComment) toReviewComment() *types.ReviewComment {
+	return &types.ReviewComment{
+		ID:        c.DatabaseID,
+		User:      c.Author.toUser(),
+		Body:      c.Body,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
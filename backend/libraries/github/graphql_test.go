@@ -0,0 +1,132 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// graphQLPullRequestNode returns a pull request of a GraphQL response with one item in each connection, the
+// overflowing connection has more items than the query fetched
+func graphQLPullRequestNode(overflowing string) map[string]interface{} {
+	pageInfo := func(connection string) map[string]interface{} {
+		return map[string]interface{}{"hasNextPage": connection == overflowing}
+	}
+	comment := map[string]interface{}{"databaseId": 1, "body": "comment", "author": map[string]interface{}{"login": "alice"}}
+
+	return map[string]interface{}{
+		"databaseId": 1,
+		"number":     1,
+		"state":      "OPEN",
+		"updatedAt":  "2024-01-01T00:00:00Z",
+		"createdAt":  "2024-01-01T00:00:00Z",
+		"headRefOid": "def",
+		"author":     map[string]interface{}{"login": "alice"},
+		"comments":   map[string]interface{}{"pageInfo": pageInfo("comments"), "nodes": []interface{}{comment}},
+		"reviews": map[string]interface{}{
+			"pageInfo": pageInfo("reviews"),
+			"nodes": []interface{}{map[string]interface{}{
+				"databaseId": 1,
+				"state":      "APPROVED",
+				"author":     map[string]interface{}{"login": "bob"},
+				"comments":   map[string]interface{}{"pageInfo": pageInfo("review comments"), "nodes": []interface{}{comment}},
+			}},
+		},
+		"commits": map[string]interface{}{
+			"totalCount": 1,
+			"pageInfo":   pageInfo("commits"),
+			"nodes":      []interface{}{map[string]interface{}{"commit": map[string]interface{}{"oid": "abc"}}},
+		},
+		"files":         map[string]interface{}{"pageInfo": pageInfo("files"), "nodes": []interface{}{}},
+		"timelineItems": map[string]interface{}{"pageInfo": pageInfo("timeline"), "nodes": []interface{}{}},
+	}
+}
+
+func TestGetPullRequestsWithDetailsOverflow(t *testing.T) {
+	tests := []struct {
+		name        string
+		overflowing string
+		// restPath is where the overflowing connection is fetched from, in pages of 100 items
+		restPath string
+		// expected are the numbers of comments, reviews, review comments and commits of the pull request
+		expected [4]int
+	}{
+		{
+			name:     "connections which fit in the query",
+			expected: [4]int{1, 1, 1, 1},
+		},
+		{
+			name:        "overflowing comments are fetched with every page",
+			overflowing: "comments",
+			restPath:    "/repos/owner/repo/issues/1/comments",
+			expected:    [4]int{105, 1, 1, 1},
+		},
+		{
+			name:        "overflowing reviews are fetched with every page, with their comments",
+			overflowing: "reviews",
+			restPath:    "/repos/owner/repo/pulls/1/reviews",
+			expected:    [4]int{1, 105, 105, 1},
+		},
+		{
+			name:        "overflowing review comments are fetched with every page",
+			overflowing: "review comments",
+			restPath:    "/repos/owner/repo/pulls/1/comments",
+			expected:    [4]int{1, 1, 105, 1},
+		},
+		{
+			name:        "overflowing commits are fetched with every page",
+			overflowing: "commits",
+			restPath:    "/repos/owner/repo/pulls/1/commits",
+			expected:    [4]int{1, 1, 1, 105},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/graphql" {
+					json.NewEncoder(w).Encode(map[string]interface{}{
+						"data": map[string]interface{}{
+							"repository": map[string]interface{}{
+								"pullRequests": map[string]interface{}{
+									"pageInfo": map[string]interface{}{"hasNextPage": false},
+									"nodes":    []interface{}{graphQLPullRequestNode(tt.overflowing)},
+								},
+							},
+						},
+					})
+					return
+				}
+
+				// Reviews overflowing the query are fetched with all review comments
+				if r.URL.Path != tt.restPath && !(tt.overflowing == "reviews" && r.URL.Path == "/repos/owner/repo/pulls/1/comments") {
+					t.Errorf("unexpected request: %s", r.URL)
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				assert.Equal(t, "100", r.URL.Query().Get("per_page"))
+
+				items := map[string]int{"1": 100, "2": 5}[r.URL.Query().Get("page")]
+				page := make([]map[string]interface{}, items)
+				for i := range page {
+					page[i] = map[string]interface{}{"id": i, "sha": "abc"}
+				}
+				json.NewEncoder(w).Encode(page)
+			}))
+			defer server.Close()
+
+			prs, err := newTestClient(server.URL).GetPullRequestsWithDetails(context.Background(), "owner", "repo", "token", nil)
+			require.NoError(t, err)
+			require.Len(t, prs, 1)
+
+			details := prs[0]
+			assert.Equal(t, "def", details.PullRequest.Head.Sha)
+			assert.Equal(t, tt.expected, [4]int{len(details.Comments), len(details.Reviews), len(details.ReviewComments), len(details.Commits)})
+		})
+	}
+}
@@ -0,0 +1,346 @@
+package github
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// secondaryRateLimitWait is how long to wait after a secondary rate limit without a Retry-After header,
+// as recommended by GitHub
+const secondaryRateLimitWait = time.Minute
+
+const (
+	// etagCacheMaxEntries and etagCacheMaxBytes bound the conditional request cache of a client
+	etagCacheMaxEntries = 2000
+	etagCacheMaxBytes   = 64 << 20
+)
+
+// RateLimitError is returned when the GitHub rate limit of a token is exhausted for longer than the
+// client is willing to wait. Callers can retry the request after ResetAt.
+type RateLimitError struct {
+	ResetAt time.Time
+	// Secondary is true for secondary (abuse) rate limits, false for the primary hourly limit
+	Secondary bool
+}
+
+func (e *RateLimitError) Error() string {
+	kind := "rate limit"
+	if e.Secondary {
+		kind = "secondary rate limit"
+	}
+	return fmt.Sprintf("github %s exceeded, resets at %s", kind, e.ResetAt.Format(time.RFC3339))
+}
+
+// ErrNotFound is returned when a resource doesn't exist or the token has no access to it
+var ErrNotFound = errors.New("github resource not found")
+
+// IsRateLimitError returns the RateLimitError wrapped in err, if any
+func IsRateLimitError(err error) (*RateLimitError, bool) {
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return rateLimitErr, true
+	}
+	return nil, false
+}
+
+// fetch performs an authenticated GET request and returns the response body
+func (c *Client) fetch(ctx context.Context, url, token string) ([]byte, error) {
+	return c.do(ctx, "GET", url, token, nil)
+}
+
+// do performs an authenticated request and returns the response body. It
+//   - waits for (or fails fast on) exhausted rate limits, including secondary rate limits
+//   - retries 5xx responses and network errors with exponential backoff
+//   - sends If-None-Match for cached GET responses, 304s are served from the cache and don't count
+//     against the rate limit
+func (c *Client) do(ctx context.Context, method, url, token string, payload []byte) ([]byte, error) {
+	var cached cachedResponse
+	hasCached := false
+	if method == "GET" {
+		cached, hasCached = c.etags.get(token, url)
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := c.waitForRateLimit(ctx, token); err != nil {
+			return nil, err
+		}
+
+		var reqBody io.Reader
+		if payload != nil {
+			reqBody = bytes.NewReader(payload)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		req.Header.Set("Accept", "application/vnd.github.v3+json")
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if hasCached {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			if attempt < c.maxRetries && ctx.Err() == nil {
+				if err := sleep(ctx, c.backoff(attempt)); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			return nil, fmt.Errorf("failed to make request: %w", err)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+
+		c.rateLimits.update(token, resp.Header)
+
+		switch {
+		case resp.StatusCode == http.StatusOK:
+			if etag := resp.Header.Get("ETag"); etag != "" && method == "GET" {
+				c.etags.set(token, url, etag, body)
+			}
+			return body, nil
+
+		case resp.StatusCode == http.StatusNotModified && hasCached:
+			return cached.body, nil
+
+		case isRateLimited(resp, body):
+			rateLimitErr := parseRateLimitError(resp)
+			if wait := time.Until(rateLimitErr.ResetAt); attempt < c.maxRetries && wait <= c.maxRateLimitWait {
+				if err := sleep(ctx, wait); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			return nil, rateLimitErr
+
+		case resp.StatusCode == http.StatusNotFound:
+			return nil, ErrNotFound
+
+		case resp.StatusCode >= http.StatusInternalServerError && attempt < c.maxRetries:
+			if err := sleep(ctx, c.backoff(attempt)); err != nil {
+				return nil, err
+			}
+			continue
+
+		default:
+			return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		}
+	}
+}
+
+// waitForRateLimit blocks until the token's primary rate limit resets if it is exhausted. Returns a
+// RateLimitError instead if the reset is further away than maxRateLimitWait.
+func (c *Client) waitForRateLimit(ctx context.Context, token string) error {
+	resetAt, exhausted := c.rateLimits.exhaustedUntil(token)
+	if !exhausted {
+		return nil
+	}
+
+	wait := time.Until(resetAt)
+	if wait > c.maxRateLimitWait {
+		return &RateLimitError{ResetAt: resetAt}
+	}
+	return sleep(ctx, wait)
+}
+
+func (c *Client) backoff(attempt int) time.Duration {
+	return c.retryBackoff * time.Duration(1<<attempt)
+}
+
+// isRateLimited reports whether a response was rejected by a primary or secondary rate limit.
+// GitHub uses both 403 and 429 for rate limits, a 403 is only a rate limit if the headers or the
+// error message say so.
+func isRateLimited(resp *http.Response, body []byte) bool {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return resp.Header.Get("Retry-After") != "" ||
+		resp.Header.Get("X-RateLimit-Remaining") == "0" ||
+		bytes.Contains(bytes.ToLower(body), []byte("rate limit"))
+}
+
+// parseRateLimitError builds a RateLimitError from the Retry-After or X-RateLimit-Reset headers
+func parseRateLimitError(resp *http.Response) *RateLimitError {
+	if retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return &RateLimitError{ResetAt: time.Now().Add(time.Duration(retryAfter) * time.Second), Secondary: true}
+	}
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return &RateLimitError{ResetAt: time.Unix(reset, 0)}
+		}
+	}
+
+	return &RateLimitError{ResetAt: time.Now().Add(secondaryRateLimitWait), Secondary: true}
+}
+
+// sleep waits for the duration or until the context is cancelled
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// rateLimitTracker keeps the last known primary rate limit of every token
+type rateLimitTracker struct {
+	mu     sync.Mutex
+	limits map[string]rateLimit
+}
+
+type rateLimit struct {
+	remaining int
+	resetAt   time.Time
+}
+
+func newRateLimitTracker() *rateLimitTracker {
+	return &rateLimitTracker{
+		limits: make(map[string]rateLimit),
+	}
+}
+
+// update records the X-RateLimit-Remaining and X-RateLimit-Reset headers of a response
+func (t *rateLimitTracker) update(token string, header http.Header) {
+	// The search API has its own limit resetting every minute, tracking it would hold back the token's
+	// other requests once it is exhausted. Rate limited searches are waited for when they are rejected.
+	if header.Get("X-RateLimit-Resource") == "search" {
+		return
+	}
+
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.limits[tokenKey(token)] = rateLimit{remaining: remaining, resetAt: time.Unix(reset, 0)}
+}
+
+// exhaustedUntil returns the reset time of the token's rate limit if no requests are remaining
+func (t *rateLimitTracker) exhaustedUntil(token string) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	limit, ok := t.limits[tokenKey(token)]
+	if !ok || limit.remaining > 0 || time.Now().After(limit.resetAt) {
+		return time.Time{}, false
+	}
+	return limit.resetAt, true
+}
+
+// tokenKey avoids keeping raw tokens as map keys
+func tokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// etagCache keeps the last response body of a URL per token together with its ETag. Entries are
+// scoped to the token that fetched them so a 304 is never answered with a body another token (and
+// possibly another tenant) fetched. The cache is a bounded LRU on both entry count and total body size.
+type etagCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int
+	size       int
+	order      *list.List
+	entries    map[string]*list.Element
+}
+
+type cachedResponse struct {
+	etag string
+	body []byte
+}
+
+type etagEntry struct {
+	key      string
+	response cachedResponse
+}
+
+func newETagCache(maxEntries, maxBytes int) *etagCache {
+	return &etagCache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+func etagCacheKey(token, url string) string {
+	return tokenKey(token) + " " + url
+}
+
+func (c *etagCache) get(token, url string) (cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[etagCacheKey(token, url)]
+	if !ok {
+		return cachedResponse{}, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*etagEntry).response, true
+}
+
+func (c *etagCache) set(token, url, etag string, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := etagCacheKey(token, url)
+	if elem, ok := c.entries[key]; ok {
+		c.removeElement(elem)
+	}
+	// Bodies larger than the whole cache would evict everything and still not fit
+	if len(body) > c.maxBytes {
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&etagEntry{key: key, response: cachedResponse{etag: etag, body: body}})
+	c.size += len(body)
+
+	for c.order.Len() > c.maxEntries || c.size > c.maxBytes {
+		c.removeElement(c.order.Back())
+	}
+}
+
+func (c *etagCache) removeElement(elem *list.Element) {
+	entry := c.order.Remove(elem).(*etagEntry)
+	delete(c.entries, entry.key)
+	c.size -= len(entry.response.body)
+}
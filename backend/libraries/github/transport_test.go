@@ -0,0 +1,162 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient returns a client talking to the test server without retry waits
+func newTestClient(serverURL string) *Client {
+	client := NewClient()
+	client.baseURL = serverURL
+	client.retryBackoff = 0
+	return client
+}
+
+func TestConditionalRequests(t *testing.T) {
+	tests := []struct {
+		name string
+		// firstToken and secondToken fetch the same URL one after the other
+		firstToken  string
+		secondToken string
+		// expectedIfNoneMatch is the If-None-Match header expected on the second request
+		expectedIfNoneMatch string
+		expectedBody        string
+	}{
+		{
+			name:                "same token is served from the cache on 304",
+			firstToken:          "token-a",
+			secondToken:         "token-a",
+			expectedIfNoneMatch: `"v1"`,
+			expectedBody:        `{"body":"token-a"}`,
+		},
+		{
+			name:                "other token does not reuse the cached response",
+			firstToken:          "token-a",
+			secondToken:         "token-b",
+			expectedIfNoneMatch: "",
+			expectedBody:        `{"body":"token-b"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ifNoneMatch []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+				if r.Header.Get("If-None-Match") == `"v1"` {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				w.Header().Set("ETag", `"v1"`)
+				w.Write([]byte(`{"body":"` + r.Header.Get("Authorization")[len("Bearer "):] + `"}`))
+			}))
+			defer server.Close()
+
+			client := newTestClient(server.URL)
+
+			body, err := client.fetch(context.Background(), server.URL+"/repos/o/r", tt.firstToken)
+			require.NoError(t, err)
+			assert.Equal(t, `{"body":"`+tt.firstToken+`"}`, string(body))
+
+			body, err = client.fetch(context.Background(), server.URL+"/repos/o/r", tt.secondToken)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedBody, string(body))
+
+			require.Len(t, ifNoneMatch, 2)
+			assert.Equal(t, "", ifNoneMatch[0])
+			assert.Equal(t, tt.expectedIfNoneMatch, ifNoneMatch[1])
+		})
+	}
+}
+
+func TestConditionalRequestsSkipNonGET(t *testing.T) {
+	var ifNoneMatch []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+
+	_, err := client.do(context.Background(), "POST", server.URL+"/graphql", "token", []byte(`{}`))
+	require.NoError(t, err)
+	_, err = client.do(context.Background(), "POST", server.URL+"/graphql", "token", []byte(`{}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"", ""}, ifNoneMatch)
+}
+
+func TestETagCache(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxEntries int
+		maxBytes   int
+		run        func(cache *etagCache)
+		expected   map[string]bool
+	}{
+		{
+			name:       "evicts the least recently used entry",
+			maxEntries: 2,
+			maxBytes:   1024,
+			run: func(cache *etagCache) {
+				cache.set("t", "/a", "a", []byte("a"))
+				cache.set("t", "/b", "b", []byte("b"))
+				cache.get("t", "/a")
+				cache.set("t", "/c", "c", []byte("c"))
+			},
+			expected: map[string]bool{"/a": true, "/b": false, "/c": true},
+		},
+		{
+			name:       "evicts until the bodies fit the byte bound",
+			maxEntries: 10,
+			maxBytes:   4,
+			run: func(cache *etagCache) {
+				cache.set("t", "/a", "a", []byte("aa"))
+				cache.set("t", "/b", "b", []byte("bb"))
+				cache.set("t", "/c", "c", []byte("cc"))
+			},
+			expected: map[string]bool{"/a": false, "/b": true, "/c": true},
+		},
+		{
+			name:       "skips bodies larger than the cache",
+			maxEntries: 10,
+			maxBytes:   4,
+			run: func(cache *etagCache) {
+				cache.set("t", "/a", "a", []byte("aa"))
+				cache.set("t", "/b", "b", []byte("bbbbb"))
+			},
+			expected: map[string]bool{"/a": true, "/b": false},
+		},
+		{
+			name:       "replacing an entry keeps the cache size",
+			maxEntries: 2,
+			maxBytes:   4,
+			run: func(cache *etagCache) {
+				cache.set("t", "/a", "a", []byte("aa"))
+				cache.set("t", "/a", "a2", []byte("aa"))
+				cache.set("t", "/b", "b", []byte("bb"))
+			},
+			expected: map[string]bool{"/a": true, "/b": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newETagCache(tt.maxEntries, tt.maxBytes)
+			tt.run(cache)
+
+			for url, expected := range tt.expected {
+				_, ok := cache.get("t", url)
+				assert.Equal(t, expected, ok, url)
+			}
+		})
+	}
+}
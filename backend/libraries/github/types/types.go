@@ -27,9 +27,15 @@ type PullRequest struct {
 	Commits        int        `json:"commits"`          // Number of commits
 	Head           Ref        `json:"head"`             // The head ref
 	Base           Ref        `json:"base"`             // The base ref
+	Labels         []Label    `json:"labels"`           // Labels
 	Links          Links      `json:"_links"`           // Hypermedia links
 }
 
+// Label represents a GitHub issue or pull request label
+type Label struct {
+	Name string `json:"name"`
+}
+
 // Commnent types
 type CommentType string
 
@@ -47,6 +53,8 @@ type ReviewComment struct {
 	Type      string    `json:"type"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// ReviewState is only set on the reviews converted to comments, in uppercase
+	ReviewState string `json:"review_state,omitempty"`
 }
 
 // Review represents a GitHub pull request review
@@ -64,6 +72,9 @@ type User struct {
 	ID        int    `json:"id"`
 	AvatarURL string `json:"avatar_url"`
 	Type      string `json:"type"`
+	// Name and Email are the public profile of the user, only returned by the GraphQL API
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
 }
 
 // Ref represents a Git reference
@@ -77,10 +88,14 @@ type Ref struct {
 
 // Repo represents a GitHub repository
 type Repo struct {
-	ID       int    `json:"id"`
-	Name     string `json:"name"`
-	FullName string `json:"full_name"`
-	Private  bool   `json:"private"`
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	FullName      string `json:"full_name"`
+	Owner         User   `json:"owner"`
+	Private       bool   `json:"private"`
+	Visibility    string `json:"visibility"` // public, private or internal
+	DefaultBranch string `json:"default_branch"`
+	Archived      bool   `json:"archived"`
 }
 
 // Links represents hypermedia links for a pull request
@@ -103,6 +118,25 @@ type Link struct {
 type Commit struct {
 	Sha    string       `json:"sha"`
 	Commit CommitDetail `json:"commit"`
+	// Author is the GitHub account of the commit author, nil when the author email isn't linked to an account
+	Author *User `json:"author"`
+	// Stats is only returned when fetching a single commit, and by the GraphQL API
+	Stats *CommitStats `json:"stats,omitempty"`
+}
+
+// PullRequestFile represents a file changed by a pull request
+type PullRequestFile struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename,omitempty"` // Only set on renamed files
+	Status           string `json:"status"`                      // added, removed, modified, renamed, copied, changed or unchanged
+	Additions        int    `json:"additions"`
+	Deletions        int    `json:"deletions"`
+}
+
+// CommitStats represents the lines changed by a commit
+type CommitStats struct {
+	Additions int `json:"additions"`
+	Deletions int `json:"deletions"`
 }
 
 // CommitDetail represents the commit details
@@ -119,3 +153,172 @@ type CommitDetail struct {
 		Date  time.Time `json:"date"`
 	} `json:"committer"`
 }
+
+// PullRequestDetails represents a pull request together with its reviews, comments, commits, timeline and files
+type PullRequestDetails struct {
+	PullRequest    *PullRequest
+	Reviews        []*Review
+	ReviewComments []*ReviewComment
+	Comments       []*ReviewComment
+	Commits        []*Commit
+	Timeline       []*TimelineEvent
+	Files          []*PullRequestFile
+}
+
+// Timeline event types
+const (
+	TimelineEventReadyForReview  = "ready_for_review"
+	TimelineEventConvertToDraft  = "convert_to_draft"
+	TimelineEventReviewRequested = "review_requested"
+	TimelineEventMerged          = "merged"
+	TimelineEventClosed          = "closed"
+	TimelineEventReopened        = "reopened"
+)
+
+// TimelineEvent represents an event of a pull request timeline
+type TimelineEvent struct {
+	Event     string    `json:"event"`
+	Actor     User      `json:"actor"`
+	CreatedAt time.Time `json:"created_at"`
+	// NodeID is the global ID of the event, the same in the REST and GraphQL APIs
+	NodeID string `json:"node_id"`
+	// RequestedReviewer is only set on review_requested events for users, team requests have none
+	RequestedReviewer *User `json:"requested_reviewer,omitempty"`
+}
+
+// PullRequestEvent represents the payload of a pull_request webhook event
+type PullRequestEvent struct {
+	Action      string      `json:"action"`
+	Number      int         `json:"number"`
+	PullRequest PullRequest `json:"pull_request"`
+	Repository  Repo        `json:"repository"`
+}
+
+// PullRequestReviewEvent represents the payload of a pull_request_review webhook event
+type PullRequestReviewEvent struct {
+	Action      string      `json:"action"`
+	Review      Review      `json:"review"`
+	PullRequest PullRequest `json:"pull_request"`
+	Repository  Repo        `json:"repository"`
+}
+
+// IssueCommentEvent represents the payload of an issue_comment webhook event.
+// Pull requests are issues on GitHub, their comments are delivered as issue comments.
+type IssueCommentEvent struct {
+	Action     string        `json:"action"`
+	Issue      Issue         `json:"issue"`
+	Comment    ReviewComment `json:"comment"`
+	Repository Repo          `json:"repository"`
+}
+
+// Issue represents a GitHub issue
+type Issue struct {
+	ID          int    `json:"id"`
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	PullRequest *struct {
+		URL string `json:"url"`
+	} `json:"pull_request"` // Only set when the issue is a pull request
+}
+
+// SearchIssuesResult represents a page of the issue search API. Pull requests are returned as issues.
+type SearchIssuesResult struct {
+	TotalCount        int      `json:"total_count"`
+	IncompleteResults bool     `json:"incomplete_results"`
+	Items             []*Issue `json:"items"`
+}
+
+// FileContent represents a file returned by the repository contents API
+type FileContent struct {
+	Path     string `json:"path"`
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
+}
+
+// Deployment represents a GitHub deployment
+type Deployment struct {
+	ID                    int       `json:"id"`
+	Sha                   string    `json:"sha"`
+	Ref                   string    `json:"ref"`
+	Task                  string    `json:"task"`
+	Environment           string    `json:"environment"`
+	ProductionEnvironment bool      `json:"production_environment"`
+	Creator               *User     `json:"creator"`
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+// DeploymentStatus represents a status of a GitHub deployment
+type DeploymentStatus struct {
+	ID          int       `json:"id"`
+	State       string    `json:"state"` // error, failure, inactive, in_progress, queued, pending or success
+	Description string    `json:"description"`
+	Environment string    `json:"environment"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Release represents a GitHub release
+type Release struct {
+	ID              int        `json:"id"`
+	TagName         string     `json:"tag_name"`
+	TargetCommitish string     `json:"target_commitish"`
+	Name            string     `json:"name"`
+	Draft           bool       `json:"draft"`
+	Prerelease      bool       `json:"prerelease"`
+	Author          *User      `json:"author"`
+	CreatedAt       time.Time  `json:"created_at"`
+	PublishedAt     *time.Time `json:"published_at"` // Nil for drafts
+}
+
+// WorkflowRun represents a GitHub Actions workflow run. Re-runs keep the ID of the run, the list returns the
+// latest attempt.
+type WorkflowRun struct {
+	ID           int                      `json:"id"`
+	Name         string                   `json:"name"`
+	HeadSha      string                   `json:"head_sha"`
+	HeadBranch   string                   `json:"head_branch"`
+	Event        string                   `json:"event"`
+	Status       string                   `json:"status"`     // queued, in_progress or completed
+	Conclusion   *string                  `json:"conclusion"` // Nil until the run is completed
+	RunAttempt   int                      `json:"run_attempt"`
+	PullRequests []WorkflowRunPullRequest `json:"pull_requests"` // Empty for pull requests from forks
+	RunStartedAt *time.Time               `json:"run_started_at"`
+	CreatedAt    time.Time                `json:"created_at"`
+	UpdatedAt    time.Time                `json:"updated_at"`
+}
+
+// WorkflowRunPullRequest references a pull request which triggered a workflow run
+type WorkflowRunPullRequest struct {
+	ID     int `json:"id"`
+	Number int `json:"number"`
+}
+
+// WorkflowRunsPage represents a page of the workflow runs API
+type WorkflowRunsPage struct {
+	TotalCount   int            `json:"total_count"`
+	WorkflowRuns []*WorkflowRun `json:"workflow_runs"`
+}
+
+// CheckRun represents a check run reported on a commit by a GitHub App
+type CheckRun struct {
+	ID          int          `json:"id"`
+	Name        string       `json:"name"`
+	HeadSha     string       `json:"head_sha"`
+	Status      string       `json:"status"`     // queued, in_progress or completed
+	Conclusion  *string      `json:"conclusion"` // Nil until the check run is completed
+	StartedAt   *time.Time   `json:"started_at"`
+	CompletedAt *time.Time   `json:"completed_at"`
+	App         *CheckRunApp `json:"app"`
+}
+
+// CheckRunApp represents the GitHub App which reported a check run
+type CheckRunApp struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// CheckRunsPage represents a page of the check runs API
+type CheckRunsPage struct {
+	TotalCount int         `json:"total_count"`
+	CheckRuns  []*CheckRun `json:"check_runs"`
+}
@@ -0,0 +1,40 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Webhook headers
+const (
+	WebhookEventHeader     = "X-GitHub-Event"
+	WebhookSignatureHeader = "X-Hub-Signature-256"
+)
+
+// Webhook events
+const (
+	WebhookEventPing              = "ping"
+	WebhookEventPullRequest       = "pull_request"
+	WebhookEventPullRequestReview = "pull_request_review"
+	WebhookEventIssueComment      = "issue_comment"
+)
+
+// VerifyWebhookSignature checks the X-Hub-Signature-256 header ("sha256=<hex digest>") of a webhook
+// delivery against the HMAC-SHA256 of the raw payload computed with the webhook secret
+func VerifyWebhookSignature(secret string, payload []byte, signature string) bool {
+	digest, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+
+	expected, err := hex.DecodeString(digest)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
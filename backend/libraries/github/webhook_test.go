@@ -0,0 +1,93 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	payload := []byte(`{"action":"opened","number":1}`)
+	secret := "webhook-secret"
+	validSignature := sign(secret, payload)
+
+	tests := []struct {
+		name      string
+		secret    string
+		payload   []byte
+		signature string
+		expected  bool
+	}{
+		{
+			name:      "valid signature",
+			secret:    secret,
+			payload:   payload,
+			signature: validSignature,
+			expected:  true,
+		},
+		{
+			name:      "wrong secret",
+			secret:    "another-secret",
+			payload:   payload,
+			signature: validSignature,
+			expected:  false,
+		},
+		{
+			name:      "tampered payload",
+			secret:    secret,
+			payload:   []byte(`{"action":"closed","number":1}`),
+			signature: validSignature,
+			expected:  false,
+		},
+		{
+			name:      "missing sha256 prefix",
+			secret:    secret,
+			payload:   payload,
+			signature: validSignature[len("sha256="):],
+			expected:  false,
+		},
+		{
+			name:      "sha1 signature",
+			secret:    secret,
+			payload:   payload,
+			signature: "sha1=" + validSignature[len("sha256="):],
+			expected:  false,
+		},
+		{
+			name:      "invalid hex digest",
+			secret:    secret,
+			payload:   payload,
+			signature: "sha256=not-hex",
+			expected:  false,
+		},
+		{
+			name:      "empty header",
+			secret:    secret,
+			payload:   payload,
+			signature: "",
+			expected:  false,
+		},
+		{
+			name:      "empty digest",
+			secret:    secret,
+			payload:   payload,
+			signature: "sha256=",
+			expected:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, VerifyWebhookSignature(tt.secret, tt.payload, tt.signature))
+		})
+	}
+}
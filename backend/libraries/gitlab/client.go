@@ -0,0 +1,257 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"ems.dev/backend/libraries/gitlab/types"
+)
+
+// DefaultBaseURL is the base URL of gitlab.com. Self-managed instances pass their own base URL.
+const DefaultBaseURL = "https://gitlab.com"
+
+type GitlabClient interface {
+	GetProject(ctx context.Context, baseURL, project, token string) (*types.Project, error)
+	GetMergeRequestsUpdatedSince(ctx context.Context, baseURL, project, token string, since *time.Time) ([]*types.MergeRequest, error)
+	GetMergeRequest(ctx context.Context, baseURL, project, token string, mrIID int) (*types.MergeRequest, error)
+	GetMergeRequestApprovals(ctx context.Context, baseURL, project, token string, mrIID int) (*types.Approvals, error)
+	GetMergeRequestDiscussions(ctx context.Context, baseURL, project, token string, mrIID int) ([]*types.Discussion, error)
+	GetMergeRequestCommits(ctx context.Context, baseURL, project, token string, mrIID int) ([]*types.Commit, error)
+	GetMergeRequestDiffs(ctx context.Context, baseURL, project, token string, mrIID int) ([]*types.Diff, error)
+}
+
+// Client represents a GitLab API client
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient creates a new GitLab client
+func NewClient() *Client {
+	return &Client{
+		httpClient: &http.Client{},
+	}
+}
+
+// GetProject fetches the details of a project
+func (c *Client) GetProject(ctx context.Context, baseURL, project, token string) (*types.Project, error) {
+	var p types.Project
+	if err := c.get(ctx, strings.TrimSuffix(c.projectURL(baseURL, project, ""), "/"), token, &p); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// GetMergeRequestsUpdatedSince fetches the merge requests of a project that were updated after since, most
+// recently updated first. Pages are fetched until a merge request older than since is found, a nil since fetches
+// every merge request of the project.
+func (c *Client) GetMergeRequestsUpdatedSince(ctx context.Context, baseURL, project, token string, since *time.Time) ([]*types.MergeRequest, error) {
+	var allMRs []*types.MergeRequest
+	mrsURL := c.projectURL(baseURL, project, "merge_requests") + "?state=all&order_by=updated_at&sort=desc&per_page=100"
+	if since != nil {
+		mrsURL += "&updated_after=" + url.QueryEscape(since.UTC().Format(time.RFC3339))
+	}
+
+	for page := 1; ; page++ {
+		pageURL := fmt.Sprintf("%s&page=%d", mrsURL, page)
+
+		var mrs []*types.MergeRequest
+		if err := c.get(ctx, pageURL, token, &mrs); err != nil {
+			return nil, err
+		}
+
+		// If no MRs were returned, we've reached the end
+		if len(mrs) == 0 {
+			return allMRs, nil
+		}
+
+		for _, mr := range mrs {
+			// MRs are sorted by update time, everything from here on was already synced
+			if since != nil && mr.UpdatedAt.Before(*since) {
+				return allMRs, nil
+			}
+			allMRs = append(allMRs, mr)
+		}
+	}
+}
+
+// GetMergeRequest fetches details of a single merge request
+func (c *Client) GetMergeRequest(ctx context.Context, baseURL, project, token string, mrIID int) (*types.MergeRequest, error) {
+	var mr types.MergeRequest
+	if err := c.get(ctx, c.projectURL(baseURL, project, fmt.Sprintf("merge_requests/%d", mrIID)), token, &mr); err != nil {
+		return nil, err
+	}
+
+	return &mr, nil
+}
+
+// GetMergeRequestApprovals fetches the current approval state of a merge request
+func (c *Client) GetMergeRequestApprovals(ctx context.Context, baseURL, project, token string, mrIID int) (*types.Approvals, error) {
+	var approvals types.Approvals
+	if err := c.get(ctx, c.projectURL(baseURL, project, fmt.Sprintf("merge_requests/%d/approvals", mrIID)), token, &approvals); err != nil {
+		return nil, err
+	}
+
+	return &approvals, nil
+}
+
+// GetMergeRequestDiscussions fetches all discussion threads (and their notes) for a merge request
+func (c *Client) GetMergeRequestDiscussions(ctx context.Context, baseURL, project, token string, mrIID int) ([]*types.Discussion, error) {
+	var allDiscussions []*types.Discussion
+	err := c.getAllPages(ctx, c.projectURL(baseURL, project, fmt.Sprintf("merge_requests/%d/discussions", mrIID)), token, func(resp *json.Decoder) error {
+		var discussions []*types.Discussion
+		if err := resp.Decode(&discussions); err != nil {
+			return err
+		}
+		allDiscussions = append(allDiscussions, discussions...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return allDiscussions, nil
+}
+
+// GetMergeRequestCommits fetches commits for a merge request
+func (c *Client) GetMergeRequestCommits(ctx context.Context, baseURL, project, token string, mrIID int) ([]*types.Commit, error) {
+	var allCommits []*types.Commit
+	err := c.getAllPages(ctx, c.projectURL(baseURL, project, fmt.Sprintf("merge_requests/%d/commits", mrIID)), token, func(resp *json.Decoder) error {
+		var commits []*types.Commit
+		if err := resp.Decode(&commits); err != nil {
+			return err
+		}
+		allCommits = append(allCommits, commits...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return allCommits, nil
+}
+
+// GetMergeRequestDiffs fetches the file diffs of a merge request
+func (c *Client) GetMergeRequestDiffs(ctx context.Context, baseURL, project, token string, mrIID int) ([]*types.Diff, error) {
+	var allDiffs []*types.Diff
+	err := c.getAllPages(ctx, c.projectURL(baseURL, project, fmt.Sprintf("merge_requests/%d/diffs", mrIID)), token, func(resp *json.Decoder) error {
+		var diffs []*types.Diff
+		if err := resp.Decode(&diffs); err != nil {
+			return err
+		}
+		allDiffs = append(allDiffs, diffs...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return allDiffs, nil
+}
+
+// projectURL builds the API URL for a project resource. The project can be its numeric ID or its
+// full path (e.g. "group/subgroup/project"), which GitLab expects URL-encoded.
+func (c *Client) projectURL(baseURL, project, resource string) string {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return fmt.Sprintf("%s/api/v4/projects/%s/%s", strings.TrimSuffix(baseURL, "/"), url.PathEscape(project), resource)
+}
+
+// getAllPages follows GitLab's X-Next-Page header until every page has been decoded
+func (c *Client) getAllPages(ctx context.Context, resourceURL, token string, decodePage func(*json.Decoder) error) error {
+	page := "1"
+	for page != "" {
+		pageURL := fmt.Sprintf("%s?per_page=100&page=%s", resourceURL, page)
+
+		req, err := c.newRequest(ctx, pageURL, token)
+		if err != nil {
+			return err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to make request: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		}
+
+		err = decodePage(json.NewDecoder(resp.Body))
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		page = resp.Header.Get("X-Next-Page")
+	}
+
+	return nil
+}
+
+// get performs a GET request and decodes the JSON response into out
+func (c *Client) get(ctx context.Context, resourceURL, token string, out interface{}) error {
+	req, err := c.newRequest(ctx, resourceURL, token)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) newRequest(ctx context.Context, resourceURL, token string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", resourceURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", token)
+	req.Header.Set("Accept", "application/json")
+
+	return req, nil
+}
+
+// CountDiffLines returns the number of added and removed lines in a GitLab diff.
+// GitLab diffs start at the first hunk header, so there are no "---"/"+++" file headers to skip.
+func CountDiffLines(diff string) (additions int, deletions int) {
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			additions++
+		case strings.HasPrefix(line, "-"):
+			deletions++
+		}
+	}
+	return additions, deletions
+}
+
+// ParseChangesCount parses the changes_count field which GitLab caps as a string like "1000+"
+func ParseChangesCount(changesCount string) int {
+	count, err := strconv.Atoi(strings.TrimSuffix(changesCount, "+"))
+	if err != nil {
+		return 0
+	}
+	return count
+}
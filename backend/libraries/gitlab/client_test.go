@@ -0,0 +1,251 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ems.dev/backend/libraries/gitlab/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMergeRequestsUpdatedSince(t *testing.T) {
+	since := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	mr := func(id int, updatedAt time.Time) *types.MergeRequest {
+		return &types.MergeRequest{ID: id, UpdatedAt: updatedAt}
+	}
+	// pages are the MRs of the project, most recently updated first
+	pages := [][]*types.MergeRequest{
+		{mr(1, since.Add(48*time.Hour)), mr(2, since.Add(24*time.Hour))},
+		{mr(3, since.Add(time.Hour)), mr(4, since.Add(-time.Hour))},
+		{mr(5, since.Add(-48*time.Hour))},
+	}
+
+	tests := []struct {
+		name                 string
+		since                *time.Time
+		expectedIDs          []int
+		expectedPages        int
+		expectedUpdatedAfter string
+	}{
+		{
+			name:          "every merge request without cursor",
+			expectedIDs:   []int{1, 2, 3, 4, 5},
+			expectedPages: 4,
+		},
+		{
+			name:                 "paging stops at the cursor",
+			since:                &since,
+			expectedIDs:          []int{1, 2, 3},
+			expectedPages:        2,
+			expectedUpdatedAfter: "2024-01-10T00:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				query := r.URL.Query()
+				assert.Equal(t, "/api/v4/projects/group%2Fproject/merge_requests", r.URL.EscapedPath())
+				assert.Equal(t, "updated_at", query.Get("order_by"))
+				assert.Equal(t, "desc", query.Get("sort"))
+				assert.Equal(t, "all", query.Get("state"))
+				assert.Equal(t, tt.expectedUpdatedAfter, query.Get("updated_after"))
+
+				page := []*types.MergeRequest{}
+				if index := requests - 1; index < len(pages) {
+					page = pages[index]
+				}
+				json.NewEncoder(w).Encode(page)
+			}))
+			defer server.Close()
+
+			mrs, err := NewClient().GetMergeRequestsUpdatedSince(context.Background(), server.URL, "group/project", "token", tt.since)
+			require.NoError(t, err)
+
+			ids := []int{}
+			for _, mr := range mrs {
+				ids = append(ids, mr.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+			assert.Equal(t, tt.expectedPages, requests)
+		})
+	}
+}
+
+func TestGetAllPages(t *testing.T) {
+	tests := []struct {
+		name          string
+		pages         map[string][]int
+		nextPages     map[string]string
+		status        int
+		expected      []int
+		expectedPages []string
+		expectedError string
+	}{
+		{
+			name:          "single page",
+			pages:         map[string][]int{"1": {1, 2}},
+			expected:      []int{1, 2},
+			expectedPages: []string{"1"},
+		},
+		{
+			name:          "next pages are followed until the header is empty",
+			pages:         map[string][]int{"1": {1, 2}, "2": {3}, "3": {4}},
+			nextPages:     map[string]string{"1": "2", "2": "3"},
+			expected:      []int{1, 2, 3, 4},
+			expectedPages: []string{"1", "2", "3"},
+		},
+		{
+			name:          "unexpected status",
+			status:        http.StatusUnauthorized,
+			expectedPages: []string{"1"},
+			expectedError: "unexpected status code: 401",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestedPages := []string{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				page := r.URL.Query().Get("page")
+				requestedPages = append(requestedPages, page)
+				assert.Equal(t, "100", r.URL.Query().Get("per_page"))
+				assert.Equal(t, "token", r.Header.Get("PRIVATE-TOKEN"))
+
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+					return
+				}
+				w.Header().Set("X-Next-Page", tt.nextPages[page])
+				json.NewEncoder(w).Encode(tt.pages[page])
+			}))
+			defer server.Close()
+
+			items := []int{}
+			err := NewClient().getAllPages(context.Background(), server.URL+"/items", "token", func(decoder *json.Decoder) error {
+				var page []int
+				if err := decoder.Decode(&page); err != nil {
+					return err
+				}
+				items = append(items, page...)
+				return nil
+			})
+			assert.Equal(t, tt.expectedPages, requestedPages)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, items)
+		})
+	}
+}
+
+func TestCountDiffLines(t *testing.T) {
+	tests := []struct {
+		name              string
+		diff              string
+		expectedAdditions int
+		expectedDeletions int
+	}{
+		{
+			name: "empty diff",
+		},
+		{
+			name:              "added and removed lines",
+			diff:              "@@ -1,3 +1,3 @@\n context\n-old\n+new\n+added\n",
+			expectedAdditions: 2,
+			expectedDeletions: 1,
+		},
+		{
+			name:              "every hunk is counted",
+			diff:              "@@ -1,1 +1,1 @@\n-a\n+b\n@@ -10,1 +10,0 @@\n-c\n",
+			expectedAdditions: 1,
+			expectedDeletions: 2,
+		},
+		{
+			name:              "lines which only look like file headers are changes",
+			diff:              "@@ -1,1 +1,1 @@\n--- old\n+++ new\n",
+			expectedAdditions: 1,
+			expectedDeletions: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			additions, deletions := CountDiffLines(tt.diff)
+			assert.Equal(t, tt.expectedAdditions, additions)
+			assert.Equal(t, tt.expectedDeletions, deletions)
+		})
+	}
+}
+
+func TestParseChangesCount(t *testing.T) {
+	tests := []struct {
+		changesCount string
+		expected     int
+	}{
+		{changesCount: "12", expected: 12},
+		{changesCount: "1000+", expected: 1000},
+		{changesCount: "", expected: 0},
+		{changesCount: "many", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.changesCount, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParseChangesCount(tt.changesCount))
+		})
+	}
+}
+
+func TestProjectURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseURL  string
+		project  string
+		expected string
+	}{
+		{
+			name:     "project path",
+			baseURL:  "https://gitlab.example.com",
+			project:  "group/project",
+			expected: "https://gitlab.example.com/api/v4/projects/group%2Fproject/merge_requests",
+		},
+		{
+			name:     "project in nested groups",
+			baseURL:  "https://gitlab.example.com",
+			project:  "group/subgroup/project",
+			expected: "https://gitlab.example.com/api/v4/projects/group%2Fsubgroup%2Fproject/merge_requests",
+		},
+		{
+			name:     "project ID",
+			baseURL:  "https://gitlab.example.com",
+			project:  "42",
+			expected: "https://gitlab.example.com/api/v4/projects/42/merge_requests",
+		},
+		{
+			name:     "base URL with a trailing slash",
+			baseURL:  "https://gitlab.example.com/",
+			project:  "group/project",
+			expected: "https://gitlab.example.com/api/v4/projects/group%2Fproject/merge_requests",
+		},
+		{
+			name:     "default base URL",
+			project:  "group/project",
+			expected: "https://gitlab.com/api/v4/projects/group%2Fproject/merge_requests",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NewClient().projectURL(tt.baseURL, tt.project, "merge_requests"))
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package types
+
+import (
+	"strings"
+	"time"
+)
+
+// Project represents a GitLab project
+type Project struct {
+	ID                int       `json:"id"`
+	Path              string    `json:"path"`                // Project slug
+	PathWithNamespace string    `json:"path_with_namespace"` // Full path, e.g. "group/subgroup/project"
+	Namespace         Namespace `json:"namespace"`           // Group or user owning the project
+	DefaultBranch     string    `json:"default_branch"`      // Default branch
+	Archived          bool      `json:"archived"`            // Whether the project is archived
+	Visibility        string    `json:"visibility"`          // public, internal or private
+}
+
+// Namespace represents the group or user a GitLab project belongs to
+type Namespace struct {
+	ID       int    `json:"id"`
+	Path     string `json:"path"`
+	FullPath string `json:"full_path"`
+}
+
+// MergeRequest represents a GitLab merge request
+type MergeRequest struct {
+	ID             int        `json:"id"`               // ProviderID
+	IID            int        `json:"iid"`              // MR number within the project
+	ProjectID      int        `json:"project_id"`       // Project ID
+	State          string     `json:"state"`            // opened, closed, locked, merged
+	Title          string     `json:"title"`            // Title
+	Description    string     `json:"description"`      // Description
+	URL            string     `json:"web_url"`          // URL
+	CreatedAt      time.Time  `json:"created_at"`       // Created at
+	UpdatedAt      time.Time  `json:"updated_at"`       // Updated at
+	MergedAt       *time.Time `json:"merged_at"`        // Merged at
+	ClosedAt       *time.Time `json:"closed_at"`        // When MR was closed
+	UserNotesCount int        `json:"user_notes_count"` // Number of user comments
+	ChangesCount   string     `json:"changes_count"`    // Number of changed files (may be "1000+")
+	Author         User       `json:"author"`           // Author
+	SourceBranch   string     `json:"source_branch"`    // The source branch
+	TargetBranch   string     `json:"target_branch"`    // The target branch
+	MergeCommitSHA string     `json:"merge_commit_sha"` // SHA of the merge commit
+	SHA            string     `json:"sha"`              // Head SHA
+	Draft          bool       `json:"draft"`            // Whether MR is a draft
+	Labels         []string   `json:"labels"`           // Labels
+
+	// Additions and Deletions are not returned by the GitLab API, they are computed from the MR diffs
+	Additions int `json:"additions"`
+	Deletions int `json:"deletions"`
+}
+
+// Comment types
+type CommentType string
+
+const (
+	CommentTypeComment       CommentType = "COMMENT"
+	CommentTypeReview        CommentType = "REVIEW"
+	CommentTypeReviewComment CommentType = "REVIEW_COMMENT"
+)
+
+// Note types
+const (
+	NoteTypeDiffNote       = "DiffNote"
+	NoteTypeDiscussionNote = "DiscussionNote"
+)
+
+// ApprovedNoteBody is the body of the system note GitLab adds when a user approves a merge request
+const ApprovedNoteBody = "approved this merge request"
+
+// Discussion represents a GitLab merge request discussion thread
+type Discussion struct {
+	ID             string  `json:"id"`
+	IndividualNote bool    `json:"individual_note"`
+	Notes          []*Note `json:"notes"`
+}
+
+// Note represents a single note (comment) on a GitLab merge request
+type Note struct {
+	ID        int       `json:"id"`
+	Type      *string   `json:"type"`
+	Body      string    `json:"body"`
+	Author    User      `json:"author"`
+	System    bool      `json:"system"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Approvals represents the approval state of a GitLab merge request
+type Approvals struct {
+	Approved   bool       `json:"approved"`
+	ApprovedBy []Approver `json:"approved_by"`
+}
+
+// Approver represents a user who approved a merge request
+type Approver struct {
+	User User `json:"user"`
+}
+
+// User represents a GitLab user
+type User struct {
+	ID        int    `json:"id"`
+	Username  string `json:"username"`
+	Name      string `json:"name"`
+	State     string `json:"state"`
+	AvatarURL string `json:"avatar_url"`
+	WebURL    string `json:"web_url"`
+	Bot       bool   `json:"bot"`
+}
+
+// IsBot reports whether the user is a bot. GitLab only exposes the bot flag on some endpoints,
+// so project and group access token users are also detected by their generated username.
+func (u User) IsBot() bool {
+	if u.Bot {
+		return true
+	}
+	isTokenUser := strings.HasPrefix(u.Username, "project_") || strings.HasPrefix(u.Username, "group_")
+	return isTokenUser && strings.Contains(u.Username, "_bot")
+}
+
+// Commit represents a GitLab commit
+type Commit struct {
+	ID             string    `json:"id"`
+	ShortID        string    `json:"short_id"`
+	Title          string    `json:"title"`
+	Message        string    `json:"message"`
+	AuthorName     string    `json:"author_name"`
+	AuthorEmail    string    `json:"author_email"`
+	AuthoredDate   time.Time `json:"authored_date"`
+	CommitterName  string    `json:"committer_name"`
+	CommitterEmail string    `json:"committer_email"`
+	CommittedDate  time.Time `json:"committed_date"`
+}
+
+// Diff represents a single file diff of a GitLab merge request
+type Diff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	Diff        string `json:"diff"`
+	NewFile     bool   `json:"new_file"`
+	RenamedFile bool   `json:"renamed_file"`
+	DeletedFile bool   `json:"deleted_file"`
+}
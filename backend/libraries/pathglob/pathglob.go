@@ -0,0 +1,70 @@
+package pathglob
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Compile converts a path glob to a regular expression matching whole paths, relative to the repository root.
+// * and ? don't match slashes, ** matches any number of directories, a leading slash is ignored and a trailing
+// slash matches everything in the directory.
+func Compile(glob string) (*regexp.Regexp, error) {
+	glob = strings.TrimPrefix(glob, "/")
+	if strings.HasSuffix(glob, "/") {
+		glob += "**"
+	}
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	writeGlob(&expr, glob)
+	expr.WriteString("$")
+
+	return regexp.Compile(expr.String())
+}
+
+// CompileGitignore converts a gitignore style pattern, as used by CODEOWNERS files, to a regular expression matching
+// the paths of the files it covers. Patterns with a leading or middle slash are relative to the repository root,
+// others match at any depth. Patterns also match the files inside the directories they match, except for a
+// trailing /* which only matches the files directly inside the directory.
+func CompileGitignore(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.Trim(pattern, "/")
+
+	var expr strings.Builder
+	if anchored {
+		expr.WriteString("^")
+	} else {
+		expr.WriteString("^(?:.*/)?")
+	}
+
+	writeGlob(&expr, pattern)
+
+	if strings.HasSuffix(pattern, "/*") {
+		expr.WriteString("$")
+	} else {
+		expr.WriteString("(?:/.*)?$")
+	}
+
+	return regexp.Compile(expr.String())
+}
+
+// writeGlob writes the regular expression of the wildcards and literal characters of a glob
+func writeGlob(expr *strings.Builder, glob string) {
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			// "**/" matches zero or more directories
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			expr.WriteString("[^/]*")
+		case glob[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+}
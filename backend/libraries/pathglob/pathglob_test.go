@@ -0,0 +1,77 @@
+package pathglob
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name     string
+		glob     string
+		path     string
+		expected bool
+	}{
+		{name: "exact path", glob: "README.md", path: "README.md", expected: true},
+		{name: "anchored at the root", glob: "README.md", path: "docs/README.md", expected: false},
+		{name: "leading slash is ignored", glob: "/README.md", path: "README.md", expected: true},
+		{name: "star matches within a directory", glob: "src/*.go", path: "src/main.go", expected: true},
+		{name: "star doesn't match slashes", glob: "src/*.go", path: "src/pkg/main.go", expected: false},
+		{name: "question mark matches one character", glob: "v?.txt", path: "v1.txt", expected: true},
+		{name: "question mark doesn't match slashes", glob: "a?b", path: "a/b", expected: false},
+		{name: "double star matches any depth", glob: "src/**/*.go", path: "src/a/b/main.go", expected: true},
+		{name: "double star slash matches no directory", glob: "src/**/*.go", path: "src/main.go", expected: true},
+		{name: "leading double star matches any directory", glob: "**/test/*", path: "a/b/test/x.go", expected: true},
+		{name: "trailing double star matches everything", glob: "docs/**", path: "docs/a/b.md", expected: true},
+		{name: "trailing slash matches everything in the directory", glob: "docs/", path: "docs/a/b.md", expected: true},
+		{name: "trailing slash doesn't match a sibling prefix", glob: "docs/", path: "docs-old/a.md", expected: false},
+		{name: "directory without trailing slash only matches the path", glob: "docs", path: "docs/a.md", expected: false},
+		{name: "regexp characters are literal", glob: "a+b.(c)", path: "a+b.(c)", expected: true},
+		{name: "dot is literal", glob: "*.go", path: "mainxgo", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := Compile(tt.glob)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, re.MatchString(tt.path))
+		})
+	}
+}
+
+func TestCompileGitignore(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{name: "unanchored file matches at the root", pattern: "Makefile", path: "Makefile", expected: true},
+		{name: "unanchored file matches at any depth", pattern: "Makefile", path: "a/b/Makefile", expected: true},
+		{name: "unanchored extension matches at any depth", pattern: "*.js", path: "web/src/app.js", expected: true},
+		{name: "unanchored doesn't match a suffix of a name", pattern: "file", path: "a/myfile", expected: false},
+		{name: "leading slash anchors at the root", pattern: "/build", path: "build/out.txt", expected: true},
+		{name: "leading slash doesn't match deeper", pattern: "/build", path: "src/build/out.txt", expected: false},
+		{name: "middle slash anchors at the root", pattern: "docs/api", path: "docs/api/index.md", expected: true},
+		{name: "middle slash doesn't match deeper", pattern: "docs/api", path: "web/docs/api/index.md", expected: false},
+		{name: "trailing slash alone doesn't anchor", pattern: "logs/", path: "a/logs/today.log", expected: true},
+		{name: "directory matches nested files", pattern: "apps/", path: "apps/web/src/index.ts", expected: true},
+		{name: "trailing star matches direct children", pattern: "docs/*", path: "docs/a.md", expected: true},
+		{name: "trailing star doesn't match nested files", pattern: "docs/*", path: "docs/api/a.md", expected: false},
+		{name: "double star matches any depth", pattern: "src/**/test", path: "src/a/b/test/x.go", expected: true},
+		{name: "double star matches no directory", pattern: "src/**/test", path: "src/test/x.go", expected: true},
+		{name: "leading double star", pattern: "**/logs", path: "deep/down/logs/a.log", expected: true},
+		{name: "trailing double star", pattern: "/assets/**", path: "assets/img/logo.png", expected: true},
+		{name: "star doesn't cross directories when anchored", pattern: "/src/*.go", path: "src/pkg/main.go", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := CompileGitignore(tt.pattern)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, re.MatchString(tt.path))
+		})
+	}
+}
@@ -2,12 +2,16 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 	"time"
 
 	"ems.dev/backend/database"
+	"ems.dev/backend/http/handlers"
 	"ems.dev/backend/http/server"
 	"ems.dev/backend/jobs/aicodeassistant"
 	aicodeassistantprovider "ems.dev/backend/jobs/aicodeassistant/providers"
@@ -15,10 +19,17 @@ import (
 	"ems.dev/backend/jobs/scheduler"
 	"ems.dev/backend/jobs/sourcecontrol"
 	scprovider "ems.dev/backend/jobs/sourcecontrol/providers"
+	bitbucketprovider "ems.dev/backend/jobs/sourcecontrol/providers/bitbucket"
 	githubprovider "ems.dev/backend/jobs/sourcecontrol/providers/github"
+	gitlabprovider "ems.dev/backend/jobs/sourcecontrol/providers/gitlab"
+	"ems.dev/backend/jobs/synclock"
+	"ems.dev/backend/jobs/workerpool"
 	auth0client "ems.dev/backend/libraries/auth0"
+	"ems.dev/backend/libraries/bitbucket"
+	"ems.dev/backend/libraries/cron"
 	"ems.dev/backend/libraries/cursor"
 	"ems.dev/backend/libraries/github"
+	"ems.dev/backend/libraries/gitlab"
 	apiai "ems.dev/backend/services/ai/api"
 	aidb "ems.dev/backend/services/ai/database"
 	"ems.dev/backend/services/ai/providers"
@@ -35,8 +46,11 @@ import (
 	conversationtemplatedb "ems.dev/backend/services/conversationtemplate/database"
 	directsapi "ems.dev/backend/services/directs/api"
 	directsdb "ems.dev/backend/services/directs/database"
+	identityapi "ems.dev/backend/services/identity/api"
+	identitydb "ems.dev/backend/services/identity/database"
 	integrationapi "ems.dev/backend/services/integration/api"
 	integrationdb "ems.dev/backend/services/integration/database"
+	inttypes "ems.dev/backend/services/integration/types"
 	memberapi "ems.dev/backend/services/member/api"
 	memberdb "ems.dev/backend/services/member/database"
 	metricsapi "ems.dev/backend/services/metrics/api"
@@ -85,8 +99,10 @@ func main() {
 	directsApi := directsapi.NewDirectReportsAPI(directsDb)
 	memberDb := memberdb.NewMemberDB(database.DB)
 	memberApi := memberapi.NewApi(memberDb, userApi, sourcecontrolApi, titleApi, directsApi)
+	identityDb := identitydb.NewIdentityDB(database.DB)
+	identityApi := identityapi.NewApi(identityDb, memberApi)
 	teamDb := teamdb.NewTeamDB(database.DB)
-	teamApi := teamapi.NewApi(teamDb, orgApi)
+	teamApi := teamapi.NewApi(teamDb, orgApi, sourcecontrolApi)
 	aiCodeAssistantDb := aicodeassistantdb.NewAICodeAssistantDB(database.DB)
 	aiCodeAssistantApi := aicodeassistantapi.NewApi(aiCodeAssistantDb, memberApi)
 	metricsApi := metricsapi.NewApi(memberApi, teamApi, sourcecontrolApi, aiCodeAssistantApi)
@@ -111,35 +127,91 @@ func main() {
 	aiProvider := initializeAIProvider(aiConfig)
 	aiApi := apiai.NewAIService(aiDb, memberApi, teamApi, conversationApi, sourcecontrolApi, orgApi, userApi, aiConfig, aiProvider)
 
+	// The GitHub provider also ingests webhook deliveries, so it is needed even when jobs are disabled
+	githubProvider := githubprovider.NewProvider(github.NewClient(), integrationApi, sourcecontrolApi, memberApi, teamApi)
+
+	// Integrations are synced by one replica at a time, the replicas coordinate through sync leases
+	syncLocker := synclock.NewLocker(integrationApi, synclock.NewHolder(), getSyncLeaseTTL())
+	syncJitter := time.Duration(getEnvIntOrDefault("SYNC_JITTER_SECONDS", 300)) * time.Second
+
+	// Source control sync job
+	gitlabProvider := gitlabprovider.NewProvider(gitlab.NewClient(), integrationApi, sourcecontrolApi, memberApi, teamApi)
+	bitbucketProvider := bitbucketprovider.NewProvider(bitbucket.NewCloudClient(), bitbucket.NewDataCenterClient(), integrationApi, sourcecontrolApi, memberApi, teamApi)
+	scProviderFactory := scprovider.NewFactory([]scprovider.SourceControlProvider{githubProvider, gitlabProvider, bitbucketProvider})
+	syncSchedules := scheduler.NewIntegrationSchedules(integrationApi, getSyncSchedule("SYNC_SCHEDULE", "SYNC_INTERVAL_HOURS", 4), syncJitter)
+	syncJob := sourcecontrol.NewSyncJob(integrationApi, orgApi, scProviderFactory, getSyncPoolConfig(), syncLocker, syncSchedules)
+
+	// AI code assistant sync job
+	cursorClient := cursor.NewClient()
+	cursorProvider := cursorprovider.NewProvider(cursorClient, integrationApi, aiCodeAssistantApi, memberApi)
+	aiCodeAssistantProviderFactory := aicodeassistantprovider.NewFactory([]aicodeassistantprovider.AICodeAssistantProvider{cursorProvider})
+	aiCodeAssistantSyncSchedules := scheduler.NewIntegrationSchedules(integrationApi, getSyncSchedule("AI_CODE_ASSISTANT_SYNC_SCHEDULE", "AI_CODE_ASSISTANT_SYNC_INTERVAL_HOURS", 24), syncJitter)
+	aiCodeAssistantSyncJob := aicodeassistant.NewSyncJob(integrationApi, orgApi, aiCodeAssistantProviderFactory, getAICodeAssistantSyncPoolConfig(), syncLocker, aiCodeAssistantSyncSchedules)
+
+	// Recomputes of stored pull request metrics are queued by any instance and run by the scheduled jobs
+	metricsRecomputeJob := sourcecontrol.NewMetricsRecomputeJob(integrationApi, orgApi, sourcecontrolApi, syncLocker, getEnvIntOrDefault("METRICS_RECOMPUTE_BATCH_SIZE", 500))
+
+	// Manual syncs run on the instance which receives the request, even when scheduled jobs are disabled
+	integrationSyncers := map[inttypes.IntegrationProviderType]handlers.IntegrationSyncer{
+		inttypes.IntegrationProviderTypeSourceControl:   syncJob,
+		inttypes.IntegrationProviderTypeAICodeAssistant: aiCodeAssistantSyncJob,
+	}
+
+	// Stop on SIGTERM, e.g. on deploys, letting in-flight syncs finish
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Shut down in order: requests first, then the scheduled and the manually triggered syncs
+	shutdowns := []func(context.Context) error{}
+
 	// Initialize and start sync job scheduler
 	// Check if jobs are enabled
 	if os.Getenv("JOBS_ENABLED") == "true" {
 		log.Println("Jobs are enabled")
-		syncInterval := getSyncInterval()
-
-		// Source control sync job
-		githubProvider := githubprovider.NewProvider(github.NewClient(), integrationApi, sourcecontrolApi, memberApi, teamApi)
-		scProviderFactory := scprovider.NewFactory([]scprovider.SourceControlProvider{githubProvider})
-		syncJob := sourcecontrol.NewSyncJob(integrationApi, orgApi, scProviderFactory)
-		//go syncJob.Run(context.Background())
-		scScheduler := scheduler.NewScheduler(syncJob, syncInterval)
-		go scScheduler.Start(context.Background())
-
-		// AI code assistant sync job
-		aiCodeAssistantSyncInterval := getAICodeAssistantSyncInterval()
-		cursorClient := cursor.NewClient()
-		cursorProvider := cursorprovider.NewProvider(cursorClient, integrationApi, aiCodeAssistantApi, memberApi)
-		aiCodeAssistantProviderFactory := aicodeassistantprovider.NewFactory([]aicodeassistantprovider.AICodeAssistantProvider{cursorProvider})
-		aiCodeAssistantSyncJob := aicodeassistant.NewSyncJob(integrationApi, orgApi, aiCodeAssistantProviderFactory)
-		aiCodeAssistantScheduler := scheduler.NewScheduler(aiCodeAssistantSyncJob, aiCodeAssistantSyncInterval)
-		go aiCodeAssistantScheduler.Start(context.Background())
+
+		// Each job checks which of its integrations are due at every tick
+		tick := time.Duration(getEnvIntOrDefault("SYNC_SCHEDULER_TICK_SECONDS", 60)) * time.Second
+
+		scScheduler := scheduler.NewScheduler("source control sync", syncJob, tick)
+		go scScheduler.Start(ctx)
+
+		aiCodeAssistantScheduler := scheduler.NewScheduler("AI code assistant sync", aiCodeAssistantSyncJob, tick)
+		go aiCodeAssistantScheduler.Start(ctx)
+
+		// Backfills continue from their checkpoints at every tick
+		backfillJob := sourcecontrol.NewBackfillJob(integrationApi, scProviderFactory, getBackfillConfig(), syncLocker)
+		backfillScheduler := scheduler.NewScheduler("source control backfill", backfillJob, tick)
+		go backfillScheduler.Start(ctx)
+
+		// Pull requests stored with an older version of the metrics calculation are recomputed in batches
+		metricsRecomputeScheduler := scheduler.NewScheduler("pull request metrics recompute", metricsRecomputeJob, tick)
+		go metricsRecomputeScheduler.Start(ctx)
+
+		shutdowns = append(shutdowns, scScheduler.Shutdown, aiCodeAssistantScheduler.Shutdown, backfillScheduler.Shutdown, metricsRecomputeScheduler.Shutdown)
 	}
+	shutdowns = append(shutdowns, syncJob.Shutdown, aiCodeAssistantSyncJob.Shutdown)
 
 	// Initialize and run server
-	srv := server.New(database.DB, userApi, orgApi, teamApi, titleApi, authApi, integrationApi, sourcecontrolApi, memberApi, metricsApi, directsApi, conversationTemplateApi, conversationApi, aiApi, aiCodeAssistantApi)
-	if err := srv.Run(":8080"); err != nil {
-		log.Fatal("Failed to start server:", err)
+	srv := server.New(database.DB, userApi, orgApi, teamApi, titleApi, authApi, integrationApi, sourcecontrolApi, memberApi, metricsApi, directsApi, conversationTemplateApi, conversationApi, aiApi, aiCodeAssistantApi, identityApi, githubProvider, integrationSyncers, metricsRecomputeJob)
+	go func() {
+		if err := srv.Run(":8080"); err != nil {
+			log.Fatal("Failed to start server:", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(getEnvIntOrDefault("SHUTDOWN_TIMEOUT_SECONDS", 60))*time.Second)
+	defer cancel()
+
+	shutdowns = append([]func(context.Context) error{srv.Shutdown}, shutdowns...)
+	for _, shutdown := range shutdowns {
+		if err := shutdown(shutdownCtx); err != nil {
+			log.Printf("Failed to shut down gracefully: %v", err)
+		}
 	}
+	log.Println("Shut down")
 }
 
 // initializeAIProvider initializes the appropriate AI provider based on configuration
@@ -166,34 +238,69 @@ func initializeAIProvider(config *aitypes.AIServiceConfig) providers.AIProviderI
 	}
 }
 
-func getSyncInterval() time.Duration {
-	intervalStr := os.Getenv("SYNC_INTERVAL_HOURS")
-	if intervalStr == "" {
-		intervalStr = "4" // Default to 4 hours
+// getSyncSchedule returns the default sync schedule of a job, the cron expression of scheduleKey or else
+// an interval of intervalKey hours
+func getSyncSchedule(scheduleKey, intervalKey string, defaultHours int) cron.Schedule {
+	if expression := os.Getenv(scheduleKey); expression != "" {
+		schedule, err := cron.Parse(expression)
+		if err == nil {
+			return schedule
+		}
+		log.Printf("Invalid %s value, using %s: %v", scheduleKey, intervalKey, err)
 	}
 
-	interval, err := strconv.Atoi(intervalStr)
+	schedule, err := cron.Parse(fmt.Sprintf("@every %dh", getEnvIntOrDefault(intervalKey, defaultHours)))
 	if err != nil {
-		log.Printf("Invalid SYNC_INTERVAL_HOURS value, using default 4 hours")
-		interval = 4
+		log.Fatalf("Invalid %s value: %v", intervalKey, err)
 	}
+	return schedule
+}
 
-	return time.Duration(interval) * time.Hour
+// getSyncPoolConfig returns the concurrency of the source control sync job
+func getSyncPoolConfig() workerpool.Config {
+	return workerpool.Config{
+		Integrations:               getEnvIntOrDefault("SYNC_WORKERS", 4),
+		RepositoriesPerIntegration: getEnvIntOrDefault("SYNC_REPOSITORY_WORKERS", 2),
+		Timeout:                    time.Duration(getEnvIntOrDefault("SYNC_REPOSITORY_TIMEOUT_MINUTES", 30)) * time.Minute,
+	}
 }
 
-func getAICodeAssistantSyncInterval() time.Duration {
-	intervalStr := os.Getenv("AI_CODE_ASSISTANT_SYNC_INTERVAL_HOURS")
-	if intervalStr == "" {
-		intervalStr = "24" // Default to 24 hours (daily sync)
+// getAICodeAssistantSyncPoolConfig returns the concurrency of the AI code assistant sync job
+func getAICodeAssistantSyncPoolConfig() workerpool.Config {
+	return workerpool.Config{
+		Integrations: getEnvIntOrDefault("AI_CODE_ASSISTANT_SYNC_WORKERS", 4),
+		Timeout:      time.Duration(getEnvIntOrDefault("AI_CODE_ASSISTANT_SYNC_TIMEOUT_MINUTES", 30)) * time.Minute,
 	}
+}
 
-	interval, err := strconv.Atoi(intervalStr)
-	if err != nil {
-		log.Printf("Invalid AI_CODE_ASSISTANT_SYNC_INTERVAL_HOURS value, using default 24 hours")
-		interval = 24
+// getBackfillConfig returns the concurrency and chunking of the source control backfill job
+func getBackfillConfig() sourcecontrol.BackfillConfig {
+	return sourcecontrol.BackfillConfig{
+		Integrations: getEnvIntOrDefault("BACKFILL_WORKERS", 2),
+		ChunkSize:    time.Duration(getEnvIntOrDefault("BACKFILL_CHUNK_DAYS", 7)) * 24 * time.Hour,
+		MaxRunTime:   time.Duration(getEnvIntOrDefault("BACKFILL_MAX_RUN_MINUTES", 10)) * time.Minute,
 	}
+}
+
+// getSyncLeaseTTL returns how long a replica holds an integration's sync lease without renewing it. A
+// replica dying mid-run blocks the integration's syncs for at most this long.
+func getSyncLeaseTTL() time.Duration {
+	return time.Duration(getEnvIntOrDefault("SYNC_LEASE_TTL_SECONDS", 120)) * time.Second
+}
 
-	return time.Duration(interval) * time.Hour
+// Helper function to get a positive integer environment variable with default
+func getEnvIntOrDefault(key string, defaultValue int) int {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.Atoi(valueStr)
+	if err != nil || value < 1 {
+		log.Printf("Invalid %s value, using default %d", key, defaultValue)
+		return defaultValue
+	}
+	return value
 }
 
 // Helper function to get environment variable with default
@@ -728,6 +728,7 @@ func (s *AIService) gatherSourceControlData(ctx context.Context, req *types.AIQu
 			ProviderID:     extAccount.ProviderID,
 			Username:       extAccount.Username,
 			Metadata:       extAccount.Metadata,
+			IsBot:          extAccount.IsBot,
 			LastSyncedAt:   extAccount.LastSyncedAt,
 		}
 	}
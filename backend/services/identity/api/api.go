@@ -0,0 +1,32 @@
+package api
+
+import (
+	"context"
+
+	"ems.dev/backend/services/identity/database"
+	"ems.dev/backend/services/identity/types"
+	memberapi "ems.dev/backend/services/member/api"
+)
+
+// IdentityAPI defines the interface for identity resolution operations
+type IdentityAPI interface {
+	// ResolveIdentities proposes members for the external accounts of an organization which aren't linked to one
+	ResolveIdentities(ctx context.Context, organizationID string, params types.ResolveIdentitiesParams) ([]*types.IdentityMatch, error)
+	ListIdentityMatches(ctx context.Context, params *types.IdentityMatchParams) ([]*types.IdentityMatch, error)
+	// AcceptIdentityMatch links the external account of a pending match to its member
+	AcceptIdentityMatch(ctx context.Context, organizationID string, matchID string, userID string) (*types.IdentityMatch, error)
+	// RejectIdentityMatch rejects a pending match, it isn't proposed again
+	RejectIdentityMatch(ctx context.Context, organizationID string, matchID string, userID string) (*types.IdentityMatch, error)
+}
+
+type Api struct {
+	db        database.DB
+	memberApi memberapi.MemberAPI
+}
+
+func NewApi(identityDb database.DB, memberApi memberapi.MemberAPI) *Api {
+	return &Api{
+		db:        identityDb,
+		memberApi: memberApi,
+	}
+}
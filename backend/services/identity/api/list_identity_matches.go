@@ -0,0 +1,17 @@
+package api
+
+import (
+	"context"
+
+	"ems.dev/backend/libraries/errors"
+	"ems.dev/backend/services/identity/types"
+)
+
+// ListIdentityMatches retrieves the identity matches of an organization, most confident first
+func (a *Api) ListIdentityMatches(ctx context.Context, params *types.IdentityMatchParams) ([]*types.IdentityMatch, error) {
+	if params == nil || params.OrganizationID == "" {
+		return nil, errors.NewBadRequestError("organization ID is required")
+	}
+
+	return a.db.GetIdentityMatches(ctx, params)
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	liberrors "ems.dev/backend/libraries/errors"
+	"ems.dev/backend/services/identity/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListIdentityMatches(t *testing.T) {
+	ctx := context.Background()
+	orgID := "org-1"
+
+	tests := []struct {
+		name            string
+		params          *types.IdentityMatchParams
+		mockMatches     []*types.IdentityMatch
+		mockError       error
+		expectedMatches []*types.IdentityMatch
+		expectedError   error
+	}{
+		{
+			name:   "success - pending matches",
+			params: &types.IdentityMatchParams{OrganizationID: orgID, Statuses: []string{types.IdentityMatchStatusPending}},
+			mockMatches: []*types.IdentityMatch{
+				{ID: "match-1", OrganizationID: orgID, Confidence: 0.95, Status: types.IdentityMatchStatusPending},
+			},
+			expectedMatches: []*types.IdentityMatch{
+				{ID: "match-1", OrganizationID: orgID, Confidence: 0.95, Status: types.IdentityMatchStatusPending},
+			},
+		},
+		{
+			name:          "error - missing organization ID",
+			params:        &types.IdentityMatchParams{},
+			expectedError: liberrors.NewBadRequestError("organization ID is required"),
+		},
+		{
+			name:          "error - database error",
+			params:        &types.IdentityMatchParams{OrganizationID: orgID},
+			mockError:     errors.New("database error"),
+			expectedError: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockIdentityDB)
+			mockMemberAPI := new(MockMemberAPI)
+
+			api := NewApi(mockDB, mockMemberAPI)
+
+			mockDB.On("GetIdentityMatches", ctx, tt.params).Return(tt.mockMatches, tt.mockError)
+
+			matches, err := api.ListIdentityMatches(ctx, tt.params)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
+				assert.Nil(t, matches)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedMatches, matches)
+			mockDB.AssertCalled(t, "GetIdentityMatches", ctx, tt.params)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+
+	"ems.dev/backend/services/identity/types"
+	membertypes "ems.dev/backend/services/member/types"
+	sourcecontroltypes "ems.dev/backend/services/sourcecontrol/types"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockIdentityDB is a mock implementation of the identity database interface
+type MockIdentityDB struct {
+	mock.Mock
+}
+
+func (m *MockIdentityDB) GetCommitEmails(ctx context.Context, organizationID string) ([]types.AccountEmail, error) {
+	args := m.Called(ctx, organizationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.AccountEmail), args.Error(1)
+}
+
+func (m *MockIdentityDB) GetIdentityMatches(ctx context.Context, params *types.IdentityMatchParams) ([]*types.IdentityMatch, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*types.IdentityMatch), args.Error(1)
+}
+
+func (m *MockIdentityDB) GetIdentityMatch(ctx context.Context, id string) (*types.IdentityMatch, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.IdentityMatch), args.Error(1)
+}
+
+func (m *MockIdentityDB) ReplacePendingIdentityMatches(ctx context.Context, organizationID string, matches []*types.IdentityMatch) error {
+	args := m.Called(ctx, organizationID, matches)
+	return args.Error(0)
+}
+
+func (m *MockIdentityDB) UpdateIdentityMatch(ctx context.Context, match *types.IdentityMatch) error {
+	args := m.Called(ctx, match)
+	return args.Error(0)
+}
+
+func (m *MockIdentityDB) DeletePendingIdentityMatches(ctx context.Context, externalAccountID string) error {
+	args := m.Called(ctx, externalAccountID)
+	return args.Error(0)
+}
+
+// MockMemberAPI is a mock implementation of the member API interface
+type MockMemberAPI struct {
+	mock.Mock
+}
+
+func (m *MockMemberAPI) AddOrganizationMember(ctx context.Context, req membertypes.AddMemberRequest, member *membertypes.OrganizationMember) (*membertypes.OrganizationMember, error) {
+	args := m.Called(ctx, req, member)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*membertypes.OrganizationMember), args.Error(1)
+}
+
+func (m *MockMemberAPI) RemoveOrganizationMember(ctx context.Context, orgID string, userID string) error {
+	args := m.Called(ctx, orgID, userID)
+	return args.Error(0)
+}
+
+func (m *MockMemberAPI) GetOrganizationMembers(ctx context.Context, orgID string, params *membertypes.OrganizationMemberParams) ([]membertypes.OrganizationMember, error) {
+	args := m.Called(ctx, orgID, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]membertypes.OrganizationMember), args.Error(1)
+}
+
+func (m *MockMemberAPI) GetOrganizationMemberByID(ctx context.Context, memberID string) (*membertypes.OrganizationMember, error) {
+	args := m.Called(ctx, memberID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*membertypes.OrganizationMember), args.Error(1)
+}
+
+func (m *MockMemberAPI) IsOrganizationOwner(ctx context.Context, orgID string, userID string) (bool, error) {
+	args := m.Called(ctx, orgID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMemberAPI) UpdateOrganizationMember(ctx context.Context, orgID string, memberID string, req membertypes.UpdateMemberRequest) error {
+	args := m.Called(ctx, orgID, memberID, req)
+	return args.Error(0)
+}
+
+func (m *MockMemberAPI) CalculateSourceControlMemberMetrics(ctx context.Context, organizationID string, memberID string, params sourcecontroltypes.MemberMetricsParams) (*sourcecontroltypes.MetricsResponse, error) {
+	args := m.Called(ctx, organizationID, memberID, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sourcecontroltypes.MetricsResponse), args.Error(1)
+}
+
+func (m *MockMemberAPI) GetExternalAccounts(ctx context.Context, params *membertypes.ExternalAccountParams) ([]membertypes.ExternalAccount, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]membertypes.ExternalAccount), args.Error(1)
+}
+
+func (m *MockMemberAPI) CreateExternalAccounts(ctx context.Context, accounts []*membertypes.ExternalAccount) error {
+	args := m.Called(ctx, accounts)
+	return args.Error(0)
+}
+
+func (m *MockMemberAPI) GetExternalAccount(ctx context.Context, id string) (*membertypes.ExternalAccount, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*membertypes.ExternalAccount), args.Error(1)
+}
+
+func (m *MockMemberAPI) UpdateExternalAccount(ctx context.Context, account *membertypes.ExternalAccount) error {
+	args := m.Called(ctx, account)
+	return args.Error(0)
+}
+
+func (m *MockMemberAPI) UpdateExternalAccountMemberID(ctx context.Context, organizationID string, accountID string, memberID *string) (*membertypes.ExternalAccount, error) {
+	args := m.Called(ctx, organizationID, accountID, memberID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*membertypes.ExternalAccount), args.Error(1)
+}
+
+func (m *MockMemberAPI) GetBotRules(ctx context.Context, organizationID string) ([]membertypes.BotRule, error) {
+	args := m.Called(ctx, organizationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]membertypes.BotRule), args.Error(1)
+}
+
+func (m *MockMemberAPI) CreateBotRule(ctx context.Context, organizationID string, req membertypes.CreateBotRuleRequest) (*membertypes.BotRule, error) {
+	args := m.Called(ctx, organizationID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*membertypes.BotRule), args.Error(1)
+}
+
+func (m *MockMemberAPI) DeleteBotRule(ctx context.Context, organizationID string, ruleID string) error {
+	args := m.Called(ctx, organizationID, ruleID)
+	return args.Error(0)
+}
+
+func (m *MockMemberAPI) MarkBotAccounts(ctx context.Context, organizationID string) error {
+	args := m.Called(ctx, organizationID)
+	return args.Error(0)
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
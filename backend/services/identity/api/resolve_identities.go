@@ -0,0 +1,342 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+
+	"ems.dev/backend/libraries/errors"
+	"ems.dev/backend/services/identity/types"
+	membertypes "ems.dev/backend/services/member/types"
+	"gorm.io/datatypes"
+)
+
+// Scores of the match signals, an email is close to proof while names and usernames can be shared
+const (
+	emailSignalScore    = 0.95
+	nameSignalScore     = 0.6
+	usernameSignalScore = 0.8
+	// minUsernameSimilarity is the similarity from which two usernames are considered the same person
+	minUsernameSimilarity = 0.8
+	// minHandleLength keeps short handles like "js" from matching by chance
+	minHandleLength = 3
+)
+
+// accountProfile is what an external account is known by
+type accountProfile struct {
+	// emails maps the emails of the account to the MatchSignal type they were found by
+	emails  map[string]string
+	name    string
+	handles []string
+}
+
+// ResolveIdentities proposes members for the external accounts of an organization which aren't linked to one. An
+// account is matched by the emails of its commits, its GitHub profile or its Cursor email, and by the similarity of
+// its name and username. Members are known by their email and by the emails of the accounts already linked to them.
+// The pending matches of the organization are replaced, and with AutoApply the accounts with a single match reaching
+// the auto apply confidence are linked right away.
+func (a *Api) ResolveIdentities(ctx context.Context, organizationID string, params types.ResolveIdentitiesParams) ([]*types.IdentityMatch, error) {
+	if organizationID == "" {
+		return nil, errors.NewBadRequestError("organization ID is required")
+	}
+
+	autoApplyConfidence := types.DefaultAutoApplyConfidence
+	if params.AutoApplyConfidence != nil {
+		if *params.AutoApplyConfidence < types.MinMatchConfidence || *params.AutoApplyConfidence > 1 {
+			return nil, errors.NewBadRequestError("auto apply confidence must be between 0.5 and 1")
+		}
+		autoApplyConfidence = *params.AutoApplyConfidence
+	}
+
+	accounts, err := a.memberApi.GetExternalAccounts(ctx, &membertypes.ExternalAccountParams{
+		OrganizationID: organizationID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := a.memberApi.GetOrganizationMembers(ctx, organizationID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	commitEmails, err := a.db.GetCommitEmails(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	rejectedMatches, err := a.db.GetIdentityMatches(ctx, &types.IdentityMatchParams{
+		OrganizationID: organizationID,
+		Statuses:       []string{types.IdentityMatchStatusRejected},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rejected := make(map[string]bool, len(rejectedMatches))
+	for _, match := range rejectedMatches {
+		rejected[match.ExternalAccountID+":"+match.MemberID] = true
+	}
+
+	profiles := accountProfiles(accounts, commitEmails)
+
+	// Members are known by their email and by the emails of the accounts already linked to them
+	memberEmails := make(map[string]map[string]bool, len(members))
+	for _, member := range members {
+		memberEmails[member.ID] = map[string]bool{}
+		if member.Email != "" {
+			memberEmails[member.ID][strings.ToLower(member.Email)] = true
+		}
+	}
+	for _, account := range accounts {
+		if account.MemberID == nil || memberEmails[*account.MemberID] == nil {
+			continue
+		}
+		for email := range profiles[account.ID].emails {
+			memberEmails[*account.MemberID][email] = true
+		}
+	}
+
+	matches := []*types.IdentityMatch{}
+	for _, account := range accounts {
+		if account.MemberID != nil || isBotAccount(account) {
+			continue
+		}
+
+		candidates := []*types.IdentityMatch{}
+		for _, member := range members {
+			if rejected[account.ID+":"+member.ID] {
+				continue
+			}
+
+			signals := matchSignals(profiles[account.ID], member, memberEmails[member.ID])
+			confidence := combineScores(signals)
+			if confidence < types.MinMatchConfidence {
+				continue
+			}
+
+			signalsJSON, _ := json.Marshal(signals)
+			candidates = append(candidates, &types.IdentityMatch{
+				OrganizationID:    organizationID,
+				ExternalAccountID: account.ID,
+				MemberID:          member.ID,
+				Confidence:        confidence,
+				Signals:           datatypes.JSON(signalsJSON),
+				Status:            types.IdentityMatchStatusPending,
+			})
+		}
+
+		if params.AutoApply {
+			if match := autoApplicableMatch(candidates, autoApplyConfidence); match != nil {
+				if _, err := a.memberApi.UpdateExternalAccountMemberID(ctx, organizationID, account.ID, &match.MemberID); err != nil {
+					return nil, err
+				}
+				match.Status = types.IdentityMatchStatusAutoApplied
+				matches = append(matches, match)
+				continue
+			}
+		}
+
+		matches = append(matches, candidates...)
+	}
+
+	if err := a.db.ReplacePendingIdentityMatches(ctx, organizationID, matches); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Confidence > matches[j].Confidence
+	})
+
+	return matches, nil
+}
+
+// accountProfiles collects the emails, names and usernames of external accounts from their metadata and commits
+func accountProfiles(accounts []membertypes.ExternalAccount, commitEmails []types.AccountEmail) map[string]*accountProfile {
+	profiles := make(map[string]*accountProfile, len(accounts))
+	for _, account := range accounts {
+		profile := &accountProfile{emails: map[string]string{}}
+
+		var metadata map[string]interface{}
+		_ = json.Unmarshal(account.Metadata, &metadata)
+
+		if account.ProviderName == "cursor" {
+			// Cursor accounts are identified by their email, team members are named by their display name
+			for _, value := range []string{account.ProviderID, account.Username, metadataString(metadata, "cursor_email")} {
+				if isEmail(value) {
+					profile.emails[strings.ToLower(value)] = types.MatchSignalCursorEmail
+				}
+			}
+			if !isEmail(account.Username) {
+				profile.name = account.Username
+			}
+		} else {
+			if email := metadataString(metadata, "email"); isEmail(email) {
+				profile.emails[strings.ToLower(email)] = types.MatchSignalProfileEmail
+			}
+			profile.name = metadataString(metadata, "name")
+			profile.handles = append(profile.handles, normalizeHandle(account.Username))
+		}
+
+		profiles[account.ID] = profile
+	}
+
+	for _, commitEmail := range commitEmails {
+		profile, exists := profiles[commitEmail.ExternalAccountID]
+		if !exists {
+			continue
+		}
+		if _, known := profile.emails[commitEmail.Email]; !known {
+			profile.emails[commitEmail.Email] = commitEmail.Source
+		}
+	}
+
+	return profiles
+}
+
+// matchSignals returns the signals that an external account belongs to a member, at most one of each type
+func matchSignals(profile *accountProfile, member membertypes.OrganizationMember, memberEmails map[string]bool) []types.MatchSignal {
+	signals := []types.MatchSignal{}
+	seen := map[string]bool{}
+
+	// Sorted so that the same email is reported on every run
+	emails := make([]string, 0, len(profile.emails))
+	for email := range profile.emails {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+
+	for _, email := range emails {
+		signalType := profile.emails[email]
+		if memberEmails[email] && !seen[signalType] {
+			seen[signalType] = true
+			signals = append(signals, types.MatchSignal{Type: signalType, Value: email, Score: emailSignalScore})
+		}
+	}
+
+	memberUsername := normalizeHandle(member.Username)
+	if name := normalizeHandle(profile.name); len(name) >= minHandleLength && name == memberUsername {
+		signals = append(signals, types.MatchSignal{Type: types.MatchSignalProfileName, Value: profile.name, Score: nameSignalScore})
+	}
+
+	memberHandles := []string{memberUsername, normalizeHandle(emailLocalPart(member.Email))}
+	bestSimilarity, bestHandle := 0.0, ""
+	for _, handle := range profile.handles {
+		for _, memberHandle := range memberHandles {
+			if len(handle) < minHandleLength || len(memberHandle) < minHandleLength {
+				continue
+			}
+			if similarity := handleSimilarity(handle, memberHandle); similarity > bestSimilarity {
+				bestSimilarity, bestHandle = similarity, handle
+			}
+		}
+	}
+	if bestSimilarity >= minUsernameSimilarity {
+		signals = append(signals, types.MatchSignal{Type: types.MatchSignalUsername, Value: bestHandle, Score: roundScore(usernameSignalScore * bestSimilarity)})
+	}
+
+	return signals
+}
+
+// combineScores combines the scores of independent signals, each signal reduces the chance of a wrong match
+func combineScores(signals []types.MatchSignal) float64 {
+	mismatch := 1.0
+	for _, signal := range signals {
+		mismatch *= 1 - signal.Score
+	}
+	return roundScore(1 - mismatch)
+}
+
+// autoApplicableMatch returns the match to apply without review, only when a single candidate reaches the confidence
+func autoApplicableMatch(candidates []*types.IdentityMatch, confidence float64) *types.IdentityMatch {
+	var applicable *types.IdentityMatch
+	for _, candidate := range candidates {
+		if candidate.Confidence < confidence {
+			continue
+		}
+		if applicable != nil {
+			return nil
+		}
+		applicable = candidate
+	}
+	return applicable
+}
+
+// isBotAccount reports whether an external account belongs to a bot, flagged or not yet, bots have no member
+func isBotAccount(account membertypes.ExternalAccount) bool {
+	var metadata map[string]interface{}
+	_ = json.Unmarshal(account.Metadata, &metadata)
+	return account.IsBot || metadataString(metadata, "type") == "Bot" || strings.HasSuffix(account.Username, "[bot]")
+}
+
+// handleSimilarity is the edit distance similarity of two handles, 1 when they are equal
+func handleSimilarity(a, b string) float64 {
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	if longest == 0 {
+		return 0
+	}
+	return 1 - float64(levenshtein(a, b))/float64(longest)
+}
+
+// levenshtein is the number of single character edits turning a into b
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+// normalizeHandle lowercases a username or name and drops everything but letters and digits, an email is reduced
+// to its local part
+func normalizeHandle(handle string) string {
+	if isEmail(handle) {
+		handle = emailLocalPart(handle)
+	}
+
+	var normalized strings.Builder
+	for _, r := range strings.ToLower(handle) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			normalized.WriteRune(r)
+		}
+	}
+	return normalized.String()
+}
+
+func emailLocalPart(email string) string {
+	localPart, _, _ := strings.Cut(email, "@")
+	return localPart
+}
+
+func isEmail(value string) bool {
+	localPart, domain, found := strings.Cut(value, "@")
+	return found && localPart != "" && strings.Contains(domain, ".")
+}
+
+func metadataString(metadata map[string]interface{}, key string) string {
+	value, _ := metadata[key].(string)
+	return value
+}
+
+func roundScore(score float64) float64 {
+	return math.Round(score*1000) / 1000
+}
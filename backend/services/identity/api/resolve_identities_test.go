@@ -0,0 +1,183 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	liberrors "ems.dev/backend/libraries/errors"
+	"ems.dev/backend/services/identity/types"
+	membertypes "ems.dev/backend/services/member/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/datatypes"
+)
+
+func TestResolveIdentities(t *testing.T) {
+	ctx := context.Background()
+	orgID := "org-1"
+
+	members := []membertypes.OrganizationMember{
+		{ID: "member-1", Email: "jane.doe@example.com", Username: "Jane Doe"},
+		{ID: "member-2", Email: "sam@example.com", Username: "samsmith"},
+	}
+
+	tests := []struct {
+		name                 string
+		params               types.ResolveIdentitiesParams
+		accounts             []membertypes.ExternalAccount
+		commitEmails         []types.AccountEmail
+		rejectedMatches      []*types.IdentityMatch
+		expectedMatches      []*types.IdentityMatch
+		expectedSignals      [][]string
+		expectedAutoApplied  []string
+		mockGetAccountsError error
+		expectedError        error
+	}{
+		{
+			name: "success - commit email matches member email",
+			accounts: []membertypes.ExternalAccount{
+				{ID: "account-1", ProviderName: "github", Username: "octocat"},
+			},
+			commitEmails: []types.AccountEmail{
+				{ExternalAccountID: "account-1", Email: "jane.doe@example.com", Source: types.MatchSignalCommitEmail},
+			},
+			expectedMatches: []*types.IdentityMatch{
+				{ExternalAccountID: "account-1", MemberID: "member-1", Confidence: 0.95, Status: types.IdentityMatchStatusPending},
+			},
+			expectedSignals: [][]string{{types.MatchSignalCommitEmail}},
+		},
+		{
+			name: "success - commit email matches the Cursor email of a linked account",
+			accounts: []membertypes.ExternalAccount{
+				{ID: "account-1", ProviderName: "github", Username: "octocat"},
+				{ID: "account-2", ProviderName: "cursor", ProviderID: "sam.personal@example.org", Username: "Sam", MemberID: stringPtr("member-2")},
+			},
+			commitEmails: []types.AccountEmail{
+				{ExternalAccountID: "account-1", Email: "sam.personal@example.org", Source: types.MatchSignalCommitEmail},
+			},
+			expectedMatches: []*types.IdentityMatch{
+				{ExternalAccountID: "account-1", MemberID: "member-2", Confidence: 0.95, Status: types.IdentityMatchStatusPending},
+			},
+			expectedSignals: [][]string{{types.MatchSignalCommitEmail}},
+		},
+		{
+			name: "success - Cursor email matches member email",
+			accounts: []membertypes.ExternalAccount{
+				{ID: "account-1", ProviderName: "cursor", ProviderID: "42", Username: "Sam@Example.com"},
+			},
+			expectedMatches: []*types.IdentityMatch{
+				{ExternalAccountID: "account-1", MemberID: "member-2", Confidence: 0.95, Status: types.IdentityMatchStatusPending},
+			},
+			expectedSignals: [][]string{{types.MatchSignalCursorEmail}},
+		},
+		{
+			name: "success - profile name and similar username",
+			accounts: []membertypes.ExternalAccount{
+				{ID: "account-1", ProviderName: "github", Username: "jane-doe", Metadata: datatypes.JSON(`{"name": "Jane Doe"}`)},
+			},
+			expectedMatches: []*types.IdentityMatch{
+				{ExternalAccountID: "account-1", MemberID: "member-1", Confidence: 0.92, Status: types.IdentityMatchStatusPending},
+			},
+			expectedSignals: [][]string{{types.MatchSignalProfileName, types.MatchSignalUsername}},
+		},
+		{
+			name: "success - dissimilar accounts are not proposed",
+			accounts: []membertypes.ExternalAccount{
+				{ID: "account-1", ProviderName: "github", Username: "octocat"},
+			},
+			expectedMatches: []*types.IdentityMatch{},
+		},
+		{
+			name: "success - linked, bot and rejected accounts are skipped",
+			accounts: []membertypes.ExternalAccount{
+				{ID: "account-1", ProviderName: "github", Username: "samsmith", MemberID: stringPtr("member-2")},
+				{ID: "account-2", ProviderName: "github", Username: "samsmith[bot]"},
+				{ID: "account-3", ProviderName: "github", Username: "samsmith2", Metadata: datatypes.JSON(`{"type": "Bot"}`)},
+				{ID: "account-4", ProviderName: "github", Username: "sam-smith"},
+			},
+			rejectedMatches: []*types.IdentityMatch{
+				{ExternalAccountID: "account-4", MemberID: "member-2", Status: types.IdentityMatchStatusRejected},
+			},
+			expectedMatches: []*types.IdentityMatch{},
+		},
+		{
+			name:   "success - auto apply a single confident match",
+			params: types.ResolveIdentitiesParams{AutoApply: true},
+			accounts: []membertypes.ExternalAccount{
+				{ID: "account-1", ProviderName: "github", Username: "samsmith", Metadata: datatypes.JSON(`{"email": "sam@example.com"}`)},
+				{ID: "account-2", ProviderName: "github", Username: "janedoe"},
+			},
+			expectedMatches: []*types.IdentityMatch{
+				{ExternalAccountID: "account-1", MemberID: "member-2", Confidence: 0.99, Status: types.IdentityMatchStatusAutoApplied},
+				{ExternalAccountID: "account-2", MemberID: "member-1", Confidence: 0.8, Status: types.IdentityMatchStatusPending},
+			},
+			expectedSignals:     [][]string{{types.MatchSignalProfileEmail, types.MatchSignalUsername}, {types.MatchSignalUsername}},
+			expectedAutoApplied: []string{"account-1"},
+		},
+		{
+			name:          "error - invalid auto apply confidence",
+			params:        types.ResolveIdentitiesParams{AutoApply: true, AutoApplyConfidence: func() *float64 { v := 0.2; return &v }()},
+			expectedError: liberrors.NewBadRequestError("auto apply confidence must be between 0.5 and 1"),
+		},
+		{
+			name:                 "error - database error on get accounts",
+			mockGetAccountsError: errors.New("database error"),
+			expectedError:        errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockIdentityDB)
+			mockMemberAPI := new(MockMemberAPI)
+
+			api := NewApi(mockDB, mockMemberAPI)
+
+			mockMemberAPI.On("GetExternalAccounts", ctx, &membertypes.ExternalAccountParams{OrganizationID: orgID}).Return(tt.accounts, tt.mockGetAccountsError)
+			mockMemberAPI.On("GetOrganizationMembers", ctx, orgID, (*membertypes.OrganizationMemberParams)(nil)).Return(members, nil)
+			mockDB.On("GetCommitEmails", ctx, orgID).Return(tt.commitEmails, nil)
+			mockDB.On("GetIdentityMatches", ctx, &types.IdentityMatchParams{
+				OrganizationID: orgID,
+				Statuses:       []string{types.IdentityMatchStatusRejected},
+			}).Return(tt.rejectedMatches, nil)
+			mockMemberAPI.On("UpdateExternalAccountMemberID", ctx, orgID, mock.Anything, mock.Anything).Return(&membertypes.ExternalAccount{}, nil)
+			mockDB.On("ReplacePendingIdentityMatches", ctx, orgID, mock.Anything).Return(nil)
+
+			matches, err := api.ResolveIdentities(ctx, orgID, tt.params)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
+				assert.Nil(t, matches)
+				mockDB.AssertNotCalled(t, "ReplacePendingIdentityMatches", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, matches, len(tt.expectedMatches))
+			for i, expected := range tt.expectedMatches {
+				assert.Equal(t, orgID, matches[i].OrganizationID)
+				assert.Equal(t, expected.ExternalAccountID, matches[i].ExternalAccountID)
+				assert.Equal(t, expected.MemberID, matches[i].MemberID)
+				assert.Equal(t, expected.Confidence, matches[i].Confidence)
+				assert.Equal(t, expected.Status, matches[i].Status)
+
+				var signals []types.MatchSignal
+				assert.NoError(t, json.Unmarshal(matches[i].Signals, &signals))
+				signalTypes := make([]string, 0, len(signals))
+				for _, signal := range signals {
+					signalTypes = append(signalTypes, signal.Type)
+				}
+				assert.Equal(t, tt.expectedSignals[i], signalTypes)
+			}
+
+			mockDB.AssertCalled(t, "ReplacePendingIdentityMatches", ctx, orgID, matches)
+			mockMemberAPI.AssertNumberOfCalls(t, "UpdateExternalAccountMemberID", len(tt.expectedAutoApplied))
+			for _, accountID := range tt.expectedAutoApplied {
+				mockMemberAPI.AssertCalled(t, "UpdateExternalAccountMemberID", ctx, orgID, accountID, mock.Anything)
+			}
+		})
+	}
+}
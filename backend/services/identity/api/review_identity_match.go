@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"ems.dev/backend/libraries/errors"
+	"ems.dev/backend/services/identity/types"
+)
+
+// AcceptIdentityMatch links the external account of a pending match to its member. The other pending matches of the
+// account are dropped.
+func (a *Api) AcceptIdentityMatch(ctx context.Context, organizationID string, matchID string, userID string) (*types.IdentityMatch, error) {
+	match, err := a.getPendingIdentityMatch(ctx, organizationID, matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := a.memberApi.UpdateExternalAccountMemberID(ctx, organizationID, match.ExternalAccountID, &match.MemberID); err != nil {
+		return nil, err
+	}
+
+	if err := a.reviewIdentityMatch(ctx, match, types.IdentityMatchStatusAccepted, userID); err != nil {
+		return nil, err
+	}
+
+	if err := a.db.DeletePendingIdentityMatches(ctx, match.ExternalAccountID); err != nil {
+		return nil, err
+	}
+
+	return match, nil
+}
+
+// RejectIdentityMatch rejects a pending match, it isn't proposed again
+func (a *Api) RejectIdentityMatch(ctx context.Context, organizationID string, matchID string, userID string) (*types.IdentityMatch, error) {
+	match, err := a.getPendingIdentityMatch(ctx, organizationID, matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.reviewIdentityMatch(ctx, match, types.IdentityMatchStatusRejected, userID); err != nil {
+		return nil, err
+	}
+
+	return match, nil
+}
+
+// getPendingIdentityMatch retrieves a match of the organization which wasn't reviewed yet
+func (a *Api) getPendingIdentityMatch(ctx context.Context, organizationID string, matchID string) (*types.IdentityMatch, error) {
+	match, err := a.db.GetIdentityMatch(ctx, matchID)
+	if err != nil {
+		return nil, err
+	}
+	if match == nil || match.OrganizationID != organizationID {
+		return nil, errors.NewNotFoundError("identity match not found")
+	}
+	if match.Status != types.IdentityMatchStatusPending {
+		return nil, errors.NewConflictError("identity match was already reviewed")
+	}
+	return match, nil
+}
+
+// reviewIdentityMatch saves the status of a match with the user who reviewed it
+func (a *Api) reviewIdentityMatch(ctx context.Context, match *types.IdentityMatch, status string, userID string) error {
+	reviewedAt := time.Now()
+	match.Status = status
+	match.ReviewedBy = &userID
+	match.ReviewedAt = &reviewedAt
+
+	return a.db.UpdateIdentityMatch(ctx, match)
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	liberrors "ems.dev/backend/libraries/errors"
+	"ems.dev/backend/services/identity/types"
+	membertypes "ems.dev/backend/services/member/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAcceptIdentityMatch(t *testing.T) {
+	ctx := context.Background()
+	orgID := "org-1"
+	matchID := "match-1"
+	userID := "user-1"
+
+	tests := []struct {
+		name            string
+		mockMatch       *types.IdentityMatch
+		mockLinkError   error
+		expectedError   error
+		expectedLinked  bool
+		expectedUpdated bool
+	}{
+		{
+			name:            "success - links the account and drops its other matches",
+			mockMatch:       &types.IdentityMatch{ID: matchID, OrganizationID: orgID, ExternalAccountID: "account-1", MemberID: "member-1", Status: types.IdentityMatchStatusPending},
+			expectedLinked:  true,
+			expectedUpdated: true,
+		},
+		{
+			name:          "error - match not found",
+			expectedError: liberrors.NewNotFoundError("identity match not found"),
+		},
+		{
+			name:          "error - match of another organization",
+			mockMatch:     &types.IdentityMatch{ID: matchID, OrganizationID: "org-2", Status: types.IdentityMatchStatusPending},
+			expectedError: liberrors.NewNotFoundError("identity match not found"),
+		},
+		{
+			name:          "error - match already reviewed",
+			mockMatch:     &types.IdentityMatch{ID: matchID, OrganizationID: orgID, Status: types.IdentityMatchStatusRejected},
+			expectedError: liberrors.NewConflictError("identity match was already reviewed"),
+		},
+		{
+			name:           "error - account can't be linked",
+			mockMatch:      &types.IdentityMatch{ID: matchID, OrganizationID: orgID, ExternalAccountID: "account-1", MemberID: "member-1", Status: types.IdentityMatchStatusPending},
+			mockLinkError:  liberrors.NewNotFoundError("external account not found"),
+			expectedError:  liberrors.NewNotFoundError("external account not found"),
+			expectedLinked: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockIdentityDB)
+			mockMemberAPI := new(MockMemberAPI)
+
+			api := NewApi(mockDB, mockMemberAPI)
+
+			mockDB.On("GetIdentityMatch", ctx, matchID).Return(tt.mockMatch, nil)
+			mockMemberAPI.On("UpdateExternalAccountMemberID", ctx, orgID, "account-1", stringPtr("member-1")).Return(&membertypes.ExternalAccount{}, tt.mockLinkError)
+			mockDB.On("UpdateIdentityMatch", ctx, mock.Anything).Return(nil)
+			mockDB.On("DeletePendingIdentityMatches", ctx, "account-1").Return(nil)
+
+			match, err := api.AcceptIdentityMatch(ctx, orgID, matchID, userID)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
+				assert.IsType(t, tt.expectedError, err)
+				assert.Nil(t, match)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, types.IdentityMatchStatusAccepted, match.Status)
+				assert.Equal(t, userID, *match.ReviewedBy)
+				assert.NotNil(t, match.ReviewedAt)
+			}
+
+			if tt.expectedLinked {
+				mockMemberAPI.AssertCalled(t, "UpdateExternalAccountMemberID", ctx, orgID, "account-1", stringPtr("member-1"))
+			} else {
+				mockMemberAPI.AssertNotCalled(t, "UpdateExternalAccountMemberID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+			if tt.expectedUpdated {
+				mockDB.AssertCalled(t, "UpdateIdentityMatch", ctx, match)
+				mockDB.AssertCalled(t, "DeletePendingIdentityMatches", ctx, "account-1")
+			} else {
+				mockDB.AssertNotCalled(t, "UpdateIdentityMatch", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestRejectIdentityMatch(t *testing.T) {
+	ctx := context.Background()
+	orgID := "org-1"
+	matchID := "match-1"
+	userID := "user-1"
+
+	tests := []struct {
+		name          string
+		mockMatch     *types.IdentityMatch
+		mockGetError  error
+		expectedError error
+	}{
+		{
+			name:      "success - rejects the match",
+			mockMatch: &types.IdentityMatch{ID: matchID, OrganizationID: orgID, ExternalAccountID: "account-1", MemberID: "member-1", Status: types.IdentityMatchStatusPending},
+		},
+		{
+			name:          "error - match already reviewed",
+			mockMatch:     &types.IdentityMatch{ID: matchID, OrganizationID: orgID, Status: types.IdentityMatchStatusAccepted},
+			expectedError: liberrors.NewConflictError("identity match was already reviewed"),
+		},
+		{
+			name:          "error - database error on get",
+			mockGetError:  errors.New("database error"),
+			expectedError: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockIdentityDB)
+			mockMemberAPI := new(MockMemberAPI)
+
+			api := NewApi(mockDB, mockMemberAPI)
+
+			mockDB.On("GetIdentityMatch", ctx, matchID).Return(tt.mockMatch, tt.mockGetError)
+			mockDB.On("UpdateIdentityMatch", ctx, mock.Anything).Return(nil)
+
+			match, err := api.RejectIdentityMatch(ctx, orgID, matchID, userID)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
+				assert.Nil(t, match)
+				mockDB.AssertNotCalled(t, "UpdateIdentityMatch", mock.Anything, mock.Anything)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, types.IdentityMatchStatusRejected, match.Status)
+			assert.Equal(t, userID, *match.ReviewedBy)
+			mockDB.AssertCalled(t, "UpdateIdentityMatch", ctx, match)
+			mockMemberAPI.AssertNotCalled(t, "UpdateExternalAccountMemberID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
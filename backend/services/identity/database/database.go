@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"ems.dev/backend/services/identity/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DB defines the interface for identity resolution database operations
+type DB interface {
+	// Signals
+	GetCommitEmails(ctx context.Context, organizationID string) ([]types.AccountEmail, error)
+
+	// Identity matches
+	GetIdentityMatches(ctx context.Context, params *types.IdentityMatchParams) ([]*types.IdentityMatch, error)
+	GetIdentityMatch(ctx context.Context, id string) (*types.IdentityMatch, error)
+	ReplacePendingIdentityMatches(ctx context.Context, organizationID string, matches []*types.IdentityMatch) error
+	UpdateIdentityMatch(ctx context.Context, match *types.IdentityMatch) error
+	DeletePendingIdentityMatches(ctx context.Context, externalAccountID string) error
+}
+
+type IdentityDB struct {
+	db *gorm.DB
+}
+
+func NewIdentityDB(db *gorm.DB) *IdentityDB {
+	return &IdentityDB{
+		db: db,
+	}
+}
+
+// GetCommitEmails retrieves the author emails of the pull request commits linked to the external accounts of an
+// organization. GitHub noreply emails are skipped, they only match the account they belong to.
+func (d *IdentityDB) GetCommitEmails(ctx context.Context, organizationID string) ([]types.AccountEmail, error) {
+	var emails []types.AccountEmail
+	err := d.db.WithContext(ctx).Raw(`
+		SELECT DISTINCT c.external_account_id, LOWER(c.author_email) as email, ? as source
+		FROM pr_commits c
+		JOIN member_external_accounts mea ON c.external_account_id = mea.id
+		WHERE mea.organization_id = ?
+		AND c.author_email != ''
+		AND LOWER(c.author_email) NOT LIKE '%@users.noreply.github.com'
+	`, types.MatchSignalCommitEmail, organizationID).Scan(&emails).Error
+	if err != nil {
+		return nil, err
+	}
+	return emails, nil
+}
+
+// GetIdentityMatches retrieves identity matches based on the given parameters, most confident first
+func (d *IdentityDB) GetIdentityMatches(ctx context.Context, params *types.IdentityMatchParams) ([]*types.IdentityMatch, error) {
+	query := d.db.WithContext(ctx).Where("organization_id = ?", params.OrganizationID)
+
+	if len(params.Statuses) > 0 {
+		query = query.Where("status IN ?", params.Statuses)
+	}
+
+	if params.ExternalAccountID != "" {
+		query = query.Where("external_account_id = ?", params.ExternalAccountID)
+	}
+
+	var matches []*types.IdentityMatch
+	if err := query.Order("confidence DESC, created_at ASC").Find(&matches).Error; err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// GetIdentityMatch retrieves an identity match by its ID. Returns nil if it doesn't exist.
+func (d *IdentityDB) GetIdentityMatch(ctx context.Context, id string) (*types.IdentityMatch, error) {
+	var match types.IdentityMatch
+	if err := d.db.WithContext(ctx).First(&match, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &match, nil
+}
+
+// ReplacePendingIdentityMatches replaces the pending matches of an organization with the given matches. Matches
+// which were already reviewed are kept, a rejected match isn't proposed again.
+func (d *IdentityDB) ReplacePendingIdentityMatches(ctx context.Context, organizationID string, matches []*types.IdentityMatch) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("organization_id = ? AND status = ?", organizationID, types.IdentityMatchStatusPending).Delete(&types.IdentityMatch{}).Error; err != nil {
+			return err
+		}
+
+		if len(matches) == 0 {
+			return nil
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: "external_account_id"},
+				{Name: "member_id"},
+			},
+			DoNothing: true,
+		}).Create(matches).Error
+	})
+}
+
+// UpdateIdentityMatch saves the review of an identity match
+func (d *IdentityDB) UpdateIdentityMatch(ctx context.Context, match *types.IdentityMatch) error {
+	return d.db.WithContext(ctx).Model(match).Updates(map[string]interface{}{
+		"status":      match.Status,
+		"reviewed_by": match.ReviewedBy,
+		"reviewed_at": match.ReviewedAt,
+		"updated_at":  gorm.Expr("CURRENT_TIMESTAMP"),
+	}).Error
+}
+
+// DeletePendingIdentityMatches deletes the pending matches of an external account, once it is linked to a member
+func (d *IdentityDB) DeletePendingIdentityMatches(ctx context.Context, externalAccountID string) error {
+	return d.db.WithContext(ctx).
+		Where("external_account_id = ? AND status = ?", externalAccountID, types.IdentityMatchStatusPending).
+		Delete(&types.IdentityMatch{}).
+		Error
+}
@@ -0,0 +1,76 @@
+package types
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// IdentityMatch represents a proposed link between an external account and an organization member
+type IdentityMatch struct {
+	ID                string         `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	OrganizationID    string         `json:"organization_id"`
+	ExternalAccountID string         `json:"external_account_id"`
+	MemberID          string         `json:"member_id"`
+	Confidence        float64        `json:"confidence"`                // Between 0 and 1, combined from the signals
+	Signals           datatypes.JSON `gorm:"type:jsonb" json:"signals"` // The MatchSignals the match was found by
+	Status            string         `json:"status"`                    // One of the IdentityMatchStatus constants
+	ReviewedBy        *string        `json:"reviewed_by,omitempty"`     // User who accepted or rejected the match
+	ReviewedAt        *time.Time     `json:"reviewed_at,omitempty"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+}
+
+// Identity match statuses
+const (
+	IdentityMatchStatusPending     = "pending"
+	IdentityMatchStatusAccepted    = "accepted"
+	IdentityMatchStatusRejected    = "rejected"
+	IdentityMatchStatusAutoApplied = "auto_applied"
+)
+
+// MatchSignal is a piece of evidence that an external account belongs to a member
+type MatchSignal struct {
+	Type  string  `json:"type"`  // One of the MatchSignal constants
+	Value string  `json:"value"` // The email, name or username which matched
+	Score float64 `json:"score"` // Between 0 and 1
+}
+
+// Match signal types
+const (
+	MatchSignalCommitEmail  = "commit_email"
+	MatchSignalProfileEmail = "profile_email"
+	MatchSignalCursorEmail  = "cursor_email"
+	MatchSignalProfileName  = "profile_name"
+	MatchSignalUsername     = "username"
+)
+
+// AccountEmail is an email an external account is known by
+type AccountEmail struct {
+	ExternalAccountID string `json:"external_account_id"`
+	Email             string `json:"email"`
+	Source            string `json:"source"` // The MatchSignal type of the email
+}
+
+// IdentityMatchParams represents parameters for querying identity matches
+type IdentityMatchParams struct {
+	OrganizationID    string   `json:"organization_id"`
+	Statuses          []string `json:"statuses"`
+	ExternalAccountID string   `json:"external_account_id"`
+}
+
+// ResolveIdentitiesParams represents the parameters of an identity resolution run
+type ResolveIdentitiesParams struct {
+	// AutoApply links the accounts whose best match reaches AutoApplyConfidence instead of queueing it for review
+	AutoApply bool `json:"auto_apply"`
+	// AutoApplyConfidence defaults to DefaultAutoApplyConfidence
+	AutoApplyConfidence *float64 `json:"auto_apply_confidence,omitempty"`
+}
+
+// Confidence thresholds of identity resolution
+const (
+	// MinMatchConfidence is the confidence below which candidates aren't proposed
+	MinMatchConfidence = 0.5
+	// DefaultAutoApplyConfidence is the confidence from which matches are applied without review
+	DefaultAutoApplyConfidence = 0.9
+)
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"time"
 
 	"ems.dev/backend/services/integration/database"
 	"ems.dev/backend/services/integration/types"
@@ -14,6 +15,19 @@ type IntegrationAPI interface {
 	UpdateIntegrationConfig(ctx context.Context, id string, req *types.UpdateIntegrationConfigRequest) (*types.IntegrationConfig, error)
 	DeleteIntegrationConfig(ctx context.Context, id string) error
 	DecryptToken(encryptedToken string) (string, error)
+	UpdateLastSyncedAt(ctx context.Context, id string, syncedAt time.Time) error
+	GetRepositorySyncCursor(ctx context.Context, integrationConfigID, repository string) (*types.RepositorySyncCursor, error)
+	UpdateRepositorySyncCursor(ctx context.Context, integrationConfigID, repository string, lastUpdatedAt time.Time) error
+	StartSyncRun(ctx context.Context, integrationConfigID string, repository *string) (*types.SyncRun, error)
+	FinishSyncRun(ctx context.Context, run *types.SyncRun, status types.SyncRunStatus, counts types.SyncCounts, syncErr error) error
+	GetSyncRuns(ctx context.Context, integrationConfigID string, params *types.SyncRunParams) ([]types.SyncRun, error)
+	AcquireSyncLease(ctx context.Context, integrationConfigID, holder string, ttl time.Duration) (bool, error)
+	RenewSyncLease(ctx context.Context, integrationConfigID, holder string, ttl time.Duration) (bool, error)
+	ReleaseSyncLease(ctx context.Context, integrationConfigID, holder string) error
+	CreateRepositoryBackfills(ctx context.Context, integrationConfigID string, req *types.CreateRepositoryBackfillRequest) ([]types.RepositoryBackfill, error)
+	GetRepositoryBackfills(ctx context.Context, integrationConfigID string) ([]types.RepositoryBackfill, error)
+	GetActiveRepositoryBackfills(ctx context.Context) ([]types.RepositoryBackfill, error)
+	UpdateRepositoryBackfill(ctx context.Context, backfill *types.RepositoryBackfill) error
 }
 
 type Api struct {
@@ -3,9 +3,13 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"net/url"
 
+	"ems.dev/backend/libraries/cron"
 	liberrors "ems.dev/backend/libraries/errors"
+	"ems.dev/backend/libraries/github"
 	"ems.dev/backend/services/integration/types"
+	"gorm.io/datatypes"
 )
 
 // CreateIntegrationConfig creates a new integration config
@@ -19,7 +23,7 @@ func (a *Api) CreateIntegrationConfig(ctx context.Context, orgID string, req *ty
 	// Determine provider type based on provider name
 	var providerType types.IntegrationProviderType
 	switch req.ProviderName {
-	case "github":
+	case "github", "gitlab", "bitbucket":
 		providerType = types.IntegrationProviderTypeSourceControl
 	case "cursor":
 		providerType = types.IntegrationProviderTypeAICodeAssistant
@@ -38,9 +42,22 @@ func (a *Api) CreateIntegrationConfig(ctx context.Context, orgID string, req *ty
 			return nil, err
 		}
 
-		repos, ok := metadata["repositories"].(string)
-		if !ok || repos == "" {
-			return nil, liberrors.NewBadRequestError("repositories is required for source control providers")
+		if err := validateRepositories(req.Metadata); err != nil {
+			return nil, err
+		}
+
+		// Self-managed instances (e.g. GitLab, Bitbucket Data Center) can configure the base URL of their API
+		if err := validateMetadataBaseURL(metadata); err != nil {
+			return nil, err
+		}
+
+		if req.ProviderName == types.IntegrationProviderGithub {
+			if err := validateGithubAuth(req.Metadata, req.Token); err != nil {
+				return nil, err
+			}
+			if _, err := types.ParseGithubFetchMode(req.Metadata); err != nil {
+				return nil, liberrors.NewBadRequestError(err.Error())
+			}
 		}
 	}
 
@@ -52,9 +69,92 @@ func (a *Api) CreateIntegrationConfig(ctx context.Context, orgID string, req *ty
 		Metadata:       req.Metadata,
 	}
 
+	if req.SyncSchedule != "" {
+		if err := validateSyncSchedule(req.SyncSchedule); err != nil {
+			return nil, err
+		}
+		config.SyncSchedule = &req.SyncSchedule
+	}
+
+	// The webhook secret is optional, integrations without one are only synced by polling
+	if req.WebhookSecret != "" {
+		encryptedSecret, err := a.encryptToken(req.WebhookSecret)
+		if err != nil {
+			return nil, err
+		}
+		config.EncryptedWebhookSecret = &encryptedSecret
+	}
+
 	if err := a.db.CreateIntegrationConfig(config); err != nil {
 		return nil, err
 	}
 
 	return config, nil
 }
+
+// validateRepositories checks the "repositories" metadata of a source control integration, either a
+// comma-separated string of repository names or a list of repository configs with sync rules
+func validateRepositories(metadata datatypes.JSON) error {
+	repositories, err := types.ParseRepositoryConfigs(metadata)
+	if err != nil {
+		return liberrors.NewBadRequestError(err.Error())
+	}
+	if len(repositories) == 0 {
+		return liberrors.NewBadRequestError("repositories is required for source control providers")
+	}
+
+	if err := types.ValidateRepositoryConfigs(repositories); err != nil {
+		return liberrors.NewBadRequestError(err.Error())
+	}
+
+	return nil
+}
+
+// validateSyncSchedule checks that a sync schedule is a valid cron expression
+func validateSyncSchedule(schedule string) error {
+	if _, err := cron.Parse(schedule); err != nil {
+		return liberrors.NewBadRequestError("invalid sync_schedule: " + err.Error())
+	}
+	return nil
+}
+
+// validateMetadataBaseURL checks the "base_url" metadata of a source control integration, if it is configured
+func validateMetadataBaseURL(metadata map[string]interface{}) error {
+	baseURL, exists := metadata["base_url"]
+	if !exists {
+		return nil
+	}
+	return validateBaseURL(baseURL)
+}
+
+// validateBaseURL checks that a configured provider base URL is an absolute http(s) URL
+func validateBaseURL(baseURL interface{}) error {
+	baseURLStr, ok := baseURL.(string)
+	if !ok || baseURLStr == "" {
+		return liberrors.NewBadRequestError("base_url must be a non empty string")
+	}
+
+	parsed, err := url.Parse(baseURLStr)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return liberrors.NewBadRequestError("base_url must be a valid http or https URL")
+	}
+
+	return nil
+}
+
+// validateGithubAuth checks the GitHub App configuration of a GitHub integration. Integrations using a
+// personal access token need no extra configuration.
+func validateGithubAuth(metadata datatypes.JSON, token string) error {
+	appAuth, err := types.ParseGithubAppAuth(metadata)
+	if err != nil {
+		return liberrors.NewBadRequestError(err.Error())
+	}
+
+	if appAuth != nil {
+		if _, err := github.ParseAppPrivateKey(token); err != nil {
+			return liberrors.NewBadRequestError("token must be the PEM encoded private key of the github app")
+		}
+	}
+
+	return nil
+}
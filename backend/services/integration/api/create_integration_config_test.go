@@ -2,8 +2,13 @@ package api
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"testing"
+	"time"
 
 	liberrors "ems.dev/backend/libraries/errors"
 	"ems.dev/backend/services/integration/types"
@@ -48,6 +53,75 @@ func (m *MockDB) DeleteIntegrationConfig(id string) error {
 	return args.Error(0)
 }
 
+func (m *MockDB) UpdateLastSyncedAt(id string, syncedAt time.Time) error {
+	args := m.Called(id, syncedAt)
+	return args.Error(0)
+}
+
+func (m *MockDB) GetRepositorySyncCursor(integrationConfigID, repository string) (*types.RepositorySyncCursor, error) {
+	args := m.Called(integrationConfigID, repository)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.RepositorySyncCursor), args.Error(1)
+}
+
+func (m *MockDB) UpsertRepositorySyncCursor(cursor *types.RepositorySyncCursor) error {
+	args := m.Called(cursor)
+	return args.Error(0)
+}
+
+func (m *MockDB) CreateSyncRun(run *types.SyncRun) error {
+	args := m.Called(run)
+	return args.Error(0)
+}
+
+func (m *MockDB) UpdateSyncRun(run *types.SyncRun) error {
+	args := m.Called(run)
+	return args.Error(0)
+}
+
+func (m *MockDB) GetSyncRuns(integrationConfigID string, params *types.SyncRunParams) ([]types.SyncRun, error) {
+	args := m.Called(integrationConfigID, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.SyncRun), args.Error(1)
+}
+
+func (m *MockDB) AcquireSyncLease(integrationConfigID, holder string, ttl time.Duration) (bool, error) {
+	args := m.Called(integrationConfigID, holder, ttl)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDB) RenewSyncLease(integrationConfigID, holder string, ttl time.Duration) (bool, error) {
+	args := m.Called(integrationConfigID, holder, ttl)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDB) ReleaseSyncLease(integrationConfigID, holder string) error {
+	args := m.Called(integrationConfigID, holder)
+	return args.Error(0)
+}
+
+func (m *MockDB) CreateRepositoryBackfills(backfills []*types.RepositoryBackfill) error {
+	args := m.Called(backfills)
+	return args.Error(0)
+}
+
+func (m *MockDB) GetRepositoryBackfills(params *types.RepositoryBackfillParams) ([]types.RepositoryBackfill, error) {
+	args := m.Called(params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.RepositoryBackfill), args.Error(1)
+}
+
+func (m *MockDB) UpdateRepositoryBackfill(backfill *types.RepositoryBackfill) error {
+	args := m.Called(backfill)
+	return args.Error(0)
+}
+
 func TestCreateIntegrationConfig(t *testing.T) {
 	// Generate a valid AES-256 key (32 bytes)
 	validKey := make([]byte, 32)
@@ -55,6 +129,8 @@ func TestCreateIntegrationConfig(t *testing.T) {
 		validKey[i] = byte(i)
 	}
 
+	appPrivateKey := createGithubAppPrivateKey(t)
+
 	tests := []struct {
 		name          string
 		orgID         string
@@ -79,6 +155,177 @@ func TestCreateIntegrationConfig(t *testing.T) {
 				assert.NotEqual(t, "test-token", config.EncryptedToken) // Should be encrypted
 			},
 		},
+		{
+			name:  "success - gitlab provider with base url",
+			orgID: "org-1",
+			req: &types.CreateIntegrationConfigRequest{
+				ProviderName: types.IntegrationProviderGitlab,
+				Token:        "test-token",
+				Metadata:     createMetadataJSON(`{"repositories": "group/project", "base_url": "https://gitlab.example.com"}`),
+			},
+			validateFunc: func(t *testing.T, config *types.IntegrationConfig) {
+				assert.Equal(t, types.IntegrationProviderGitlab, config.ProviderName)
+				assert.Equal(t, types.IntegrationProviderTypeSourceControl, config.ProviderType)
+			},
+		},
+		{
+			name:  "error - invalid gitlab base url",
+			orgID: "org-1",
+			req: &types.CreateIntegrationConfigRequest{
+				ProviderName: types.IntegrationProviderGitlab,
+				Token:        "test-token",
+				Metadata:     createMetadataJSON(`{"repositories": "group/project", "base_url": "gitlab.example.com"}`),
+			},
+			expectedError: liberrors.NewBadRequestError("base_url must be a valid http or https URL"),
+		},
+		{
+			name:  "success - github provider with webhook secret",
+			orgID: "org-1",
+			req: &types.CreateIntegrationConfigRequest{
+				ProviderName:  types.IntegrationProviderGithub,
+				Token:         "test-token",
+				Metadata:      createMetadataJSON(`{"repositories": "owner/repo"}`),
+				WebhookSecret: "webhook-secret",
+			},
+			validateFunc: func(t *testing.T, config *types.IntegrationConfig) {
+				assert.NotNil(t, config.EncryptedWebhookSecret)
+				assert.NotEqual(t, "webhook-secret", *config.EncryptedWebhookSecret) // Should be encrypted
+			},
+		},
+		{
+			name:  "success - github app",
+			orgID: "org-1",
+			req: &types.CreateIntegrationConfigRequest{
+				ProviderName: types.IntegrationProviderGithub,
+				Token:        appPrivateKey,
+				Metadata:     createMetadataJSON(`{"repositories": "owner/repo", "auth_type": "app", "app_id": 12345, "installation_id": "67890"}`),
+			},
+			validateFunc: func(t *testing.T, config *types.IntegrationConfig) {
+				assert.NotEmpty(t, config.EncryptedToken)
+				assert.NotEqual(t, appPrivateKey, config.EncryptedToken) // Should be encrypted
+			},
+		},
+		{
+			name:  "error - github app without installation id",
+			orgID: "org-1",
+			req: &types.CreateIntegrationConfigRequest{
+				ProviderName: types.IntegrationProviderGithub,
+				Token:        appPrivateKey,
+				Metadata:     createMetadataJSON(`{"repositories": "owner/repo", "auth_type": "app", "app_id": 12345}`),
+			},
+			expectedError: liberrors.NewBadRequestError("app_id and installation_id are required for github app authentication"),
+		},
+		{
+			name:  "error - github app with invalid private key",
+			orgID: "org-1",
+			req: &types.CreateIntegrationConfigRequest{
+				ProviderName: types.IntegrationProviderGithub,
+				Token:        "test-token",
+				Metadata:     createMetadataJSON(`{"repositories": "owner/repo", "auth_type": "app", "app_id": 12345, "installation_id": 67890}`),
+			},
+			expectedError: liberrors.NewBadRequestError("token must be the PEM encoded private key of the github app"),
+		},
+		{
+			name:  "error - unsupported github auth type",
+			orgID: "org-1",
+			req: &types.CreateIntegrationConfigRequest{
+				ProviderName: types.IntegrationProviderGithub,
+				Token:        "test-token",
+				Metadata:     createMetadataJSON(`{"repositories": "owner/repo", "auth_type": "oauth"}`),
+			},
+			expectedError: liberrors.NewBadRequestError(`unsupported auth_type "oauth"`),
+		},
+		{
+			name:  "success - github graphql fetch mode",
+			orgID: "org-1",
+			req: &types.CreateIntegrationConfigRequest{
+				ProviderName: types.IntegrationProviderGithub,
+				Token:        "test-token",
+				Metadata:     createMetadataJSON(`{"repositories": "owner/repo", "fetch_mode": "graphql"}`),
+			},
+			validateFunc: func(t *testing.T, config *types.IntegrationConfig) {
+				assert.Equal(t, types.IntegrationProviderGithub, config.ProviderName)
+			},
+		},
+		{
+			name:  "error - unsupported github fetch mode",
+			orgID: "org-1",
+			req: &types.CreateIntegrationConfigRequest{
+				ProviderName: types.IntegrationProviderGithub,
+				Token:        "test-token",
+				Metadata:     createMetadataJSON(`{"repositories": "owner/repo", "fetch_mode": "soap"}`),
+			},
+			expectedError: liberrors.NewBadRequestError(`unsupported fetch_mode "soap"`),
+		},
+		{
+			name:  "success - github repository sync rules",
+			orgID: "org-1",
+			req: &types.CreateIntegrationConfigRequest{
+				ProviderName: types.IntegrationProviderGithub,
+				Token:        "test-token",
+				Metadata: createMetadataJSON(`{"repositories": [{"name": "owner/repo", "include_base_branches": ["main", "release/*"], "exclude_base_branches": ["prod"], "ignore_drafts": true, "ignore_labels": ["wip"], "ignore_authors": ["dependabot[bot]"], "max_lookback_days": 90}]}`),
+			},
+			validateFunc: func(t *testing.T, config *types.IntegrationConfig) {
+				repositories, err := types.ParseRepositoryConfigs(config.Metadata)
+				assert.NoError(t, err)
+				assert.Len(t, repositories, 1)
+				assert.Equal(t, []string{"main", "release/*"}, repositories[0].IncludeBaseBranches)
+				assert.Equal(t, 90, repositories[0].MaxLookbackDays)
+			},
+		},
+		{
+			name:  "error - invalid base branch pattern",
+			orgID: "org-1",
+			req: &types.CreateIntegrationConfigRequest{
+				ProviderName: types.IntegrationProviderGithub,
+				Token:        "test-token",
+				Metadata:     createMetadataJSON(`{"repositories": [{"name": "owner/repo", "exclude_base_branches": ["release/["]}]}`),
+			},
+			expectedError: liberrors.NewBadRequestError(`invalid base branch pattern "release/[" for repository owner/repo`),
+		},
+		{
+			name:  "error - negative max lookback",
+			orgID: "org-1",
+			req: &types.CreateIntegrationConfigRequest{
+				ProviderName: types.IntegrationProviderGitlab,
+				Token:        "test-token",
+				Metadata:     createMetadataJSON(`{"repositories": [{"name": "group/project", "max_lookback_days": -1}]}`),
+			},
+			expectedError: liberrors.NewBadRequestError("max_lookback_days must not be negative for repository group/project"),
+		},
+		{
+			name:  "error - duplicate repository",
+			orgID: "org-1",
+			req: &types.CreateIntegrationConfigRequest{
+				ProviderName: types.IntegrationProviderGithub,
+				Token:        "test-token",
+				Metadata:     createMetadataJSON(`{"repositories": [{"name": "owner/repo"}, {"name": "Owner/Repo"}]}`),
+			},
+			expectedError: liberrors.NewBadRequestError("repository Owner/Repo is configured more than once"),
+		},
+		{
+			name:  "error - empty repositories list",
+			orgID: "org-1",
+			req: &types.CreateIntegrationConfigRequest{
+				ProviderName: types.IntegrationProviderGithub,
+				Token:        "test-token",
+				Metadata:     createMetadataJSON(`{"repositories": []}`),
+			},
+			expectedError: liberrors.NewBadRequestError("repositories is required for source control providers"),
+		},
+		{
+			name:  "success - bitbucket provider",
+			orgID: "org-1",
+			req: &types.CreateIntegrationConfigRequest{
+				ProviderName: types.IntegrationProviderBitbucket,
+				Token:        "test-token",
+				Metadata:     createMetadataJSON(`{"repositories": "workspace/repo"}`),
+			},
+			validateFunc: func(t *testing.T, config *types.IntegrationConfig) {
+				assert.Equal(t, types.IntegrationProviderBitbucket, config.ProviderName)
+				assert.Equal(t, types.IntegrationProviderTypeSourceControl, config.ProviderType)
+			},
+		},
 		{
 			name:  "success - cursor provider",
 			orgID: "org-1",
@@ -92,6 +339,30 @@ func TestCreateIntegrationConfig(t *testing.T) {
 				assert.Equal(t, types.IntegrationProviderTypeAICodeAssistant, config.ProviderType)
 			},
 		},
+		{
+			name:  "success - cursor provider with sync schedule",
+			orgID: "org-1",
+			req: &types.CreateIntegrationConfigRequest{
+				ProviderName: types.IntegrationProviderCursor,
+				Token:        "test-token",
+				SyncSchedule: "0 2 * * *",
+			},
+			validateFunc: func(t *testing.T, config *types.IntegrationConfig) {
+				assert.NotNil(t, config.SyncSchedule)
+				assert.Equal(t, "0 2 * * *", *config.SyncSchedule)
+			},
+		},
+		{
+			name:  "error - invalid sync schedule",
+			orgID: "org-1",
+			req: &types.CreateIntegrationConfigRequest{
+				ProviderName: types.IntegrationProviderGithub,
+				Token:        "test-token",
+				Metadata:     createMetadataJSON(`{"repositories": "owner/repo"}`),
+				SyncSchedule: "*/15 * * *",
+			},
+			expectedError: liberrors.NewBadRequestError(`invalid sync_schedule: expected 5 fields in cron expression "*/15 * * *", got 4`),
+		},
 		{
 			name:  "success - default provider type",
 			orgID: "org-1",
@@ -198,6 +469,15 @@ func TestCreateIntegrationConfig(t *testing.T) {
 	}
 }
 
+// Helper function to create the PEM encoded private key of a GitHub App
+func createGithubAppPrivateKey(t *testing.T) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate private key: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+}
+
 // Helper function to create datatypes.JSON from JSON string
 func createMetadataJSON(jsonStr string) datatypes.JSON {
 	return datatypes.JSON(jsonStr)
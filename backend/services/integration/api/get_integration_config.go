@@ -3,10 +3,18 @@ package api
 import (
 	"context"
 
+	liberrors "ems.dev/backend/libraries/errors"
 	"ems.dev/backend/services/integration/types"
 )
 
 // GetIntegrationConfig retrieves an integration config by ID
 func (a *Api) GetIntegrationConfig(ctx context.Context, id string) (*types.IntegrationConfig, error) {
-	return a.db.GetIntegrationConfig(id)
+	config, err := a.db.GetIntegrationConfig(id)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, liberrors.NewNotFoundError("integration not found")
+	}
+	return config, nil
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	liberrors "ems.dev/backend/libraries/errors"
 	"ems.dev/backend/services/integration/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -47,8 +48,7 @@ func TestGetIntegrationConfig(t *testing.T) {
 		{
 			name:          "error - config not found",
 			id:            "non-existent",
-			mockError:     errors.New("record not found"),
-			expectedError: errors.New("record not found"),
+			expectedError: liberrors.NewNotFoundError("integration not found"),
 		},
 		{
 			name:          "error - database error",
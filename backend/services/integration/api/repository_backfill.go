@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	liberrors "ems.dev/backend/libraries/errors"
+	"ems.dev/backend/services/integration/types"
+)
+
+// CreateRepositoryBackfills starts backfills of the pull requests created between the request's dates, for
+// one of the integration's repositories or all of them. Fails if one of them already has an active backfill.
+func (a *Api) CreateRepositoryBackfills(ctx context.Context, integrationConfigID string, req *types.CreateRepositoryBackfillRequest) ([]types.RepositoryBackfill, error) {
+	config, err := a.GetIntegrationConfig(ctx, integrationConfigID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the GitHub provider can search pull requests by creation date
+	if config.ProviderName != types.IntegrationProviderGithub {
+		return nil, liberrors.NewBadRequestError(fmt.Sprintf("backfill is not supported for %s integrations", config.ProviderName))
+	}
+
+	now := time.Now()
+	endDate := now
+	if req.EndDate != nil && req.EndDate.Before(now) {
+		endDate = *req.EndDate
+	}
+	if req.StartDate.IsZero() || !req.StartDate.Before(endDate) {
+		return nil, liberrors.NewBadRequestError("start_date must be before end_date and in the past")
+	}
+
+	repositories, err := types.ParseRepositoryConfigs(config.Metadata)
+	if err != nil {
+		return nil, liberrors.NewBadRequestError(err.Error())
+	}
+
+	names := []string{}
+	for _, repository := range repositories {
+		if req.Repository == "" || repository.Name == req.Repository {
+			names = append(names, repository.Name)
+		}
+	}
+	if len(names) == 0 {
+		if req.Repository != "" {
+			return nil, liberrors.NewBadRequestError(fmt.Sprintf("repository %s is not synced by this integration", req.Repository))
+		}
+		return nil, liberrors.NewBadRequestError("integration has no repositories to backfill")
+	}
+
+	active, err := a.db.GetRepositoryBackfills(&types.RepositoryBackfillParams{
+		IntegrationConfigID: &integrationConfigID,
+		Statuses:            types.ActiveBackfillStatuses,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, backfill := range active {
+		for _, name := range names {
+			if backfill.Repository == name {
+				return nil, liberrors.NewConflictError(fmt.Sprintf("repository %s is already being backfilled", name))
+			}
+		}
+	}
+
+	backfills := make([]*types.RepositoryBackfill, 0, len(names))
+	for _, name := range names {
+		backfills = append(backfills, &types.RepositoryBackfill{
+			IntegrationConfigID: integrationConfigID,
+			Repository:          name,
+			StartDate:           req.StartDate,
+			EndDate:             endDate,
+			Checkpoint:          req.StartDate,
+			Status:              types.BackfillStatusPending,
+		})
+	}
+
+	if err := a.db.CreateRepositoryBackfills(backfills); err != nil {
+		return nil, err
+	}
+
+	created := make([]types.RepositoryBackfill, 0, len(backfills))
+	for _, backfill := range backfills {
+		backfill.ProgressPercent = backfill.Progress()
+		created = append(created, *backfill)
+	}
+	return created, nil
+}
+
+// GetRepositoryBackfills retrieves the backfills of an integration config with their progress, most recent first
+func (a *Api) GetRepositoryBackfills(ctx context.Context, integrationConfigID string) ([]types.RepositoryBackfill, error) {
+	return a.getRepositoryBackfills(&types.RepositoryBackfillParams{IntegrationConfigID: &integrationConfigID})
+}
+
+// GetActiveRepositoryBackfills retrieves the backfills of every integration which still have pull requests to import
+func (a *Api) GetActiveRepositoryBackfills(ctx context.Context) ([]types.RepositoryBackfill, error) {
+	return a.getRepositoryBackfills(&types.RepositoryBackfillParams{Statuses: types.ActiveBackfillStatuses})
+}
+
+// UpdateRepositoryBackfill saves the checkpoint, status and counts of a repository backfill
+func (a *Api) UpdateRepositoryBackfill(ctx context.Context, backfill *types.RepositoryBackfill) error {
+	if err := a.db.UpdateRepositoryBackfill(backfill); err != nil {
+		return err
+	}
+	backfill.ProgressPercent = backfill.Progress()
+	return nil
+}
+
+func (a *Api) getRepositoryBackfills(params *types.RepositoryBackfillParams) ([]types.RepositoryBackfill, error) {
+	backfills, err := a.db.GetRepositoryBackfills(params)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range backfills {
+		backfills[i].ProgressPercent = backfills[i].Progress()
+	}
+	return backfills, nil
+}
@@ -0,0 +1,284 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	liberrors "ems.dev/backend/libraries/errors"
+	"ems.dev/backend/services/integration/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/datatypes"
+)
+
+func TestCreateRepositoryBackfills(t *testing.T) {
+	validKey := make([]byte, 32)
+	for i := range validKey {
+		validKey[i] = byte(i)
+	}
+
+	githubConfig := &types.IntegrationConfig{
+		ID:           "config-1",
+		ProviderName: types.IntegrationProviderGithub,
+		ProviderType: types.IntegrationProviderTypeSourceControl,
+		Metadata:     datatypes.JSON(`{"repositories": [{"name": "owner/repo1"}, {"name": "owner/repo2"}]}`),
+	}
+
+	startDate := time.Now().AddDate(-1, 0, 0)
+	endDate := time.Now().AddDate(0, -1, 0)
+	futureDate := time.Now().AddDate(0, 1, 0)
+
+	tests := []struct {
+		name                  string
+		config                *types.IntegrationConfig
+		request               *types.CreateRepositoryBackfillRequest
+		activeBackfills       []types.RepositoryBackfill
+		mockCreateError       error
+		expectedRepositories  []string
+		expectedEndDateIsNow  bool
+		expectedError         error
+		expectGetActive       bool
+		expectCreateBackfills bool
+	}{
+		{
+			name:                  "success - all repositories",
+			config:                githubConfig,
+			request:               &types.CreateRepositoryBackfillRequest{StartDate: startDate, EndDate: &endDate},
+			expectedRepositories:  []string{"owner/repo1", "owner/repo2"},
+			expectGetActive:       true,
+			expectCreateBackfills: true,
+		},
+		{
+			name:                  "success - single repository until now",
+			config:                githubConfig,
+			request:               &types.CreateRepositoryBackfillRequest{Repository: "owner/repo2", StartDate: startDate},
+			activeBackfills:       []types.RepositoryBackfill{{Repository: "owner/repo1", Status: types.BackfillStatusRunning}},
+			expectedRepositories:  []string{"owner/repo2"},
+			expectedEndDateIsNow:  true,
+			expectGetActive:       true,
+			expectCreateBackfills: true,
+		},
+		{
+			name:                  "success - end date in the future is capped to now",
+			config:                githubConfig,
+			request:               &types.CreateRepositoryBackfillRequest{Repository: "owner/repo1", StartDate: startDate, EndDate: &futureDate},
+			expectedRepositories:  []string{"owner/repo1"},
+			expectedEndDateIsNow:  true,
+			expectGetActive:       true,
+			expectCreateBackfills: true,
+		},
+		{
+			name: "error - provider not supported",
+			config: &types.IntegrationConfig{
+				ID:           "config-1",
+				ProviderName: types.IntegrationProviderGitlab,
+				ProviderType: types.IntegrationProviderTypeSourceControl,
+				Metadata:     datatypes.JSON(`{"repositories": [{"name": "group/project"}]}`),
+			},
+			request:       &types.CreateRepositoryBackfillRequest{StartDate: startDate},
+			expectedError: liberrors.NewBadRequestError("backfill is not supported for gitlab integrations"),
+		},
+		{
+			name:          "error - start date after end date",
+			config:        githubConfig,
+			request:       &types.CreateRepositoryBackfillRequest{StartDate: endDate, EndDate: &startDate},
+			expectedError: liberrors.NewBadRequestError("start_date must be before end_date and in the past"),
+		},
+		{
+			name:          "error - start date in the future",
+			config:        githubConfig,
+			request:       &types.CreateRepositoryBackfillRequest{StartDate: futureDate},
+			expectedError: liberrors.NewBadRequestError("start_date must be before end_date and in the past"),
+		},
+		{
+			name:          "error - repository not synced by the integration",
+			config:        githubConfig,
+			request:       &types.CreateRepositoryBackfillRequest{Repository: "owner/other", StartDate: startDate},
+			expectedError: liberrors.NewBadRequestError("repository owner/other is not synced by this integration"),
+		},
+		{
+			name:            "error - repository already being backfilled",
+			config:          githubConfig,
+			request:         &types.CreateRepositoryBackfillRequest{StartDate: startDate},
+			activeBackfills: []types.RepositoryBackfill{{Repository: "owner/repo2", Status: types.BackfillStatusPaused}},
+			expectedError:   liberrors.NewConflictError("repository owner/repo2 is already being backfilled"),
+			expectGetActive: true,
+		},
+		{
+			name:                  "error - database error",
+			config:                githubConfig,
+			request:               &types.CreateRepositoryBackfillRequest{StartDate: startDate},
+			mockCreateError:       errors.New("database connection failed"),
+			expectedRepositories:  []string{"owner/repo1", "owner/repo2"},
+			expectedError:         errors.New("database connection failed"),
+			expectGetActive:       true,
+			expectCreateBackfills: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDB)
+			api := NewApi(mockDB, validKey)
+
+			mockDB.On("GetIntegrationConfig", "config-1").Return(tt.config, nil)
+			if tt.expectGetActive {
+				mockDB.On("GetRepositoryBackfills", mock.MatchedBy(func(params *types.RepositoryBackfillParams) bool {
+					return *params.IntegrationConfigID == "config-1" && len(params.Statuses) == len(types.ActiveBackfillStatuses)
+				})).Return(tt.activeBackfills, nil)
+			}
+			if tt.expectCreateBackfills {
+				mockDB.On("CreateRepositoryBackfills", mock.MatchedBy(func(backfills []*types.RepositoryBackfill) bool {
+					return len(backfills) == len(tt.expectedRepositories)
+				})).Return(tt.mockCreateError)
+			}
+
+			backfills, err := api.CreateRepositoryBackfills(context.Background(), "config-1", tt.request)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
+				assert.Nil(t, backfills)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, backfills, len(tt.expectedRepositories))
+				for i, backfill := range backfills {
+					assert.Equal(t, tt.expectedRepositories[i], backfill.Repository)
+					assert.Equal(t, types.BackfillStatusPending, backfill.Status)
+					assert.Equal(t, tt.request.StartDate, backfill.Checkpoint)
+					assert.Equal(t, float64(0), backfill.ProgressPercent)
+					if tt.expectedEndDateIsNow {
+						assert.WithinDuration(t, time.Now(), backfill.EndDate, time.Minute)
+					} else {
+						assert.Equal(t, *tt.request.EndDate, backfill.EndDate)
+					}
+				}
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetRepositoryBackfills(t *testing.T) {
+	validKey := make([]byte, 32)
+	for i := range validKey {
+		validKey[i] = byte(i)
+	}
+
+	startDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		mockBackfills    []types.RepositoryBackfill
+		mockError        error
+		expectedProgress []float64
+		expectedError    error
+	}{
+		{
+			name: "success - progress computed from the checkpoint",
+			mockBackfills: []types.RepositoryBackfill{
+				{Repository: "owner/repo1", StartDate: startDate, EndDate: endDate, Checkpoint: startDate, Status: types.BackfillStatusPending},
+				{Repository: "owner/repo2", StartDate: startDate, EndDate: endDate, Checkpoint: startDate.AddDate(0, 0, 3), Status: types.BackfillStatusPaused},
+				{Repository: "owner/repo3", StartDate: startDate, EndDate: endDate, Checkpoint: endDate, Status: types.BackfillStatusCompleted},
+				{Repository: "owner/repo4", StartDate: startDate, EndDate: endDate, Checkpoint: startDate.Add(17 * time.Hour), Status: types.BackfillStatusFailed},
+			},
+			expectedProgress: []float64{0, 30, 100, 7.1},
+		},
+		{
+			name:             "success - no backfills",
+			mockBackfills:    []types.RepositoryBackfill{},
+			expectedProgress: []float64{},
+		},
+		{
+			name:          "error - database error",
+			mockError:     errors.New("database connection failed"),
+			expectedError: errors.New("database connection failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDB)
+			api := NewApi(mockDB, validKey)
+
+			mockDB.On("GetRepositoryBackfills", mock.MatchedBy(func(params *types.RepositoryBackfillParams) bool {
+				return *params.IntegrationConfigID == "config-1" && params.Statuses == nil
+			})).Return(tt.mockBackfills, tt.mockError)
+
+			backfills, err := api.GetRepositoryBackfills(context.Background(), "config-1")
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
+				assert.Nil(t, backfills)
+			} else {
+				assert.NoError(t, err)
+				progress := []float64{}
+				for _, backfill := range backfills {
+					progress = append(progress, backfill.ProgressPercent)
+				}
+				assert.Equal(t, tt.expectedProgress, progress)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateRepositoryBackfill(t *testing.T) {
+	validKey := make([]byte, 32)
+	for i := range validKey {
+		validKey[i] = byte(i)
+	}
+
+	startDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		mockError        error
+		expectedProgress float64
+		expectedError    error
+	}{
+		{
+			name:             "success",
+			expectedProgress: 50,
+		},
+		{
+			name:          "error - database error",
+			mockError:     errors.New("database connection failed"),
+			expectedError: errors.New("database connection failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDB)
+			api := NewApi(mockDB, validKey)
+
+			backfill := &types.RepositoryBackfill{
+				ID:         "backfill-1",
+				StartDate:  startDate,
+				EndDate:    startDate.AddDate(0, 0, 14),
+				Checkpoint: startDate.AddDate(0, 0, 7),
+				Status:     types.BackfillStatusRunning,
+			}
+			mockDB.On("UpdateRepositoryBackfill", backfill).Return(tt.mockError)
+
+			err := api.UpdateRepositoryBackfill(context.Background(), backfill)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedProgress, backfill.ProgressPercent)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
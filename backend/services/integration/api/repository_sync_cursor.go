@@ -0,0 +1,22 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"ems.dev/backend/services/integration/types"
+)
+
+// GetRepositorySyncCursor retrieves the sync cursor of a repository. Returns nil if the repository was never synced.
+func (a *Api) GetRepositorySyncCursor(ctx context.Context, integrationConfigID, repository string) (*types.RepositorySyncCursor, error) {
+	return a.db.GetRepositorySyncCursor(integrationConfigID, repository)
+}
+
+// UpdateRepositorySyncCursor moves the sync cursor of a repository to the given pull request update time
+func (a *Api) UpdateRepositorySyncCursor(ctx context.Context, integrationConfigID, repository string, lastUpdatedAt time.Time) error {
+	return a.db.UpsertRepositorySyncCursor(&types.RepositorySyncCursor{
+		IntegrationConfigID: integrationConfigID,
+		Repository:          repository,
+		LastUpdatedAt:       lastUpdatedAt,
+	})
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ems.dev/backend/services/integration/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetRepositorySyncCursor(t *testing.T) {
+	validKey := make([]byte, 32)
+	for i := range validKey {
+		validKey[i] = byte(i)
+	}
+
+	lastUpdatedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		mockCursor     *types.RepositorySyncCursor
+		mockError      error
+		expectedCursor *types.RepositorySyncCursor
+		expectedError  error
+	}{
+		{
+			name: "success",
+			mockCursor: &types.RepositorySyncCursor{
+				IntegrationConfigID: "config-1",
+				Repository:          "owner/repo",
+				LastUpdatedAt:       lastUpdatedAt,
+			},
+			expectedCursor: &types.RepositorySyncCursor{
+				IntegrationConfigID: "config-1",
+				Repository:          "owner/repo",
+				LastUpdatedAt:       lastUpdatedAt,
+			},
+		},
+		{
+			name: "success - repository never synced",
+		},
+		{
+			name:          "error - database error",
+			mockError:     errors.New("database connection failed"),
+			expectedError: errors.New("database connection failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDB)
+			api := NewApi(mockDB, validKey)
+
+			mockDB.On("GetRepositorySyncCursor", "config-1", "owner/repo").Return(tt.mockCursor, tt.mockError)
+
+			cursor, err := api.GetRepositorySyncCursor(context.Background(), "config-1", "owner/repo")
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
+				assert.Nil(t, cursor)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedCursor, cursor)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateRepositorySyncCursor(t *testing.T) {
+	validKey := make([]byte, 32)
+	for i := range validKey {
+		validKey[i] = byte(i)
+	}
+
+	lastUpdatedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		mockError     error
+		expectedError error
+	}{
+		{
+			name: "success",
+		},
+		{
+			name:          "error - database error",
+			mockError:     errors.New("database connection failed"),
+			expectedError: errors.New("database connection failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDB)
+			api := NewApi(mockDB, validKey)
+
+			mockDB.On("UpsertRepositorySyncCursor", mock.MatchedBy(func(cursor *types.RepositorySyncCursor) bool {
+				return cursor.IntegrationConfigID == "config-1" &&
+					cursor.Repository == "owner/repo" &&
+					cursor.LastUpdatedAt.Equal(lastUpdatedAt)
+			})).Return(tt.mockError)
+
+			err := api.UpdateRepositorySyncCursor(context.Background(), "config-1", "owner/repo", lastUpdatedAt)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"context"
+	"time"
+)
+
+// AcquireSyncLease takes the sync lease of an integration config for the holder. Returns false if another
+// holder has the lease and it didn't expire yet.
+func (a *Api) AcquireSyncLease(ctx context.Context, integrationConfigID, holder string, ttl time.Duration) (bool, error) {
+	return a.db.AcquireSyncLease(integrationConfigID, holder, ttl)
+}
+
+// RenewSyncLease extends the sync lease of an integration config by the ttl. Returns false if the holder
+// lost the lease.
+func (a *Api) RenewSyncLease(ctx context.Context, integrationConfigID, holder string, ttl time.Duration) (bool, error) {
+	return a.db.RenewSyncLease(integrationConfigID, holder, ttl)
+}
+
+// ReleaseSyncLease gives up the sync lease of an integration config
+func (a *Api) ReleaseSyncLease(ctx context.Context, integrationConfigID, holder string) error {
+	return a.db.ReleaseSyncLease(integrationConfigID, holder)
+}
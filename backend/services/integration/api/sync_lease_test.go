@@ -0,0 +1,156 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcquireSyncLease(t *testing.T) {
+	validKey := make([]byte, 32)
+	for i := range validKey {
+		validKey[i] = byte(i)
+	}
+
+	tests := []struct {
+		name             string
+		mockAcquired     bool
+		mockError        error
+		expectedAcquired bool
+		expectedError    error
+	}{
+		{
+			name:             "success - lease acquired",
+			mockAcquired:     true,
+			expectedAcquired: true,
+		},
+		{
+			name:             "success - lease held by another replica",
+			mockAcquired:     false,
+			expectedAcquired: false,
+		},
+		{
+			name:          "error - database error",
+			mockError:     errors.New("database connection failed"),
+			expectedError: errors.New("database connection failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDB)
+			api := NewApi(mockDB, validKey)
+
+			mockDB.On("AcquireSyncLease", "config-1", "replica-1", 2*time.Minute).Return(tt.mockAcquired, tt.mockError)
+
+			acquired, err := api.AcquireSyncLease(context.Background(), "config-1", "replica-1", 2*time.Minute)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedAcquired, acquired)
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRenewSyncLease(t *testing.T) {
+	validKey := make([]byte, 32)
+	for i := range validKey {
+		validKey[i] = byte(i)
+	}
+
+	tests := []struct {
+		name            string
+		mockRenewed     bool
+		mockError       error
+		expectedRenewed bool
+		expectedError   error
+	}{
+		{
+			name:            "success - lease renewed",
+			mockRenewed:     true,
+			expectedRenewed: true,
+		},
+		{
+			name:            "success - lease lost",
+			mockRenewed:     false,
+			expectedRenewed: false,
+		},
+		{
+			name:          "error - database error",
+			mockError:     errors.New("database connection failed"),
+			expectedError: errors.New("database connection failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDB)
+			api := NewApi(mockDB, validKey)
+
+			mockDB.On("RenewSyncLease", "config-1", "replica-1", 2*time.Minute).Return(tt.mockRenewed, tt.mockError)
+
+			renewed, err := api.RenewSyncLease(context.Background(), "config-1", "replica-1", 2*time.Minute)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedRenewed, renewed)
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReleaseSyncLease(t *testing.T) {
+	validKey := make([]byte, 32)
+	for i := range validKey {
+		validKey[i] = byte(i)
+	}
+
+	tests := []struct {
+		name          string
+		mockError     error
+		expectedError error
+	}{
+		{
+			name: "success",
+		},
+		{
+			name:          "error - database error",
+			mockError:     errors.New("database connection failed"),
+			expectedError: errors.New("database connection failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDB)
+			api := NewApi(mockDB, validKey)
+
+			mockDB.On("ReleaseSyncLease", "config-1", "replica-1").Return(tt.mockError)
+
+			err := api.ReleaseSyncLease(context.Background(), "config-1", "replica-1")
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"ems.dev/backend/services/integration/types"
+)
+
+const (
+	defaultSyncRunsLimit = 50
+	maxSyncRunsLimit     = 200
+)
+
+// StartSyncRun records the start of a sync of an integration, or of one of its repositories
+func (a *Api) StartSyncRun(ctx context.Context, integrationConfigID string, repository *string) (*types.SyncRun, error) {
+	run := &types.SyncRun{
+		IntegrationConfigID: integrationConfigID,
+		Repository:          repository,
+		Status:              types.SyncRunStatusRunning,
+		StartedAt:           time.Now(),
+	}
+
+	if err := a.db.CreateSyncRun(run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// FinishSyncRun records the outcome of a sync run with the number of records it upserted
+func (a *Api) FinishSyncRun(ctx context.Context, run *types.SyncRun, status types.SyncRunStatus, counts types.SyncCounts, syncErr error) error {
+	finishedAt := time.Now()
+	run.Status = status
+	run.FinishedAt = &finishedAt
+	run.PullRequestsUpserted = counts.PullRequests
+	run.CommentsUpserted = counts.Comments
+	run.ErrorMessage = nil
+	if syncErr != nil {
+		message := syncErr.Error()
+		run.ErrorMessage = &message
+	}
+
+	return a.db.UpdateSyncRun(run)
+}
+
+// GetSyncRuns retrieves the sync runs of an integration config, most recent first
+func (a *Api) GetSyncRuns(ctx context.Context, integrationConfigID string, params *types.SyncRunParams) ([]types.SyncRun, error) {
+	if params == nil {
+		params = &types.SyncRunParams{}
+	}
+	if params.Limit <= 0 {
+		params.Limit = defaultSyncRunsLimit
+	}
+	if params.Limit > maxSyncRunsLimit {
+		params.Limit = maxSyncRunsLimit
+	}
+
+	return a.db.GetSyncRuns(integrationConfigID, params)
+}
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ems.dev/backend/services/integration/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStartSyncRun(t *testing.T) {
+	validKey := make([]byte, 32)
+	for i := range validKey {
+		validKey[i] = byte(i)
+	}
+
+	repository := "owner/repo"
+
+	tests := []struct {
+		name          string
+		repository    *string
+		mockError     error
+		expectedError error
+	}{
+		{
+			name:       "success - repository run",
+			repository: &repository,
+		},
+		{
+			name: "success - integration run",
+		},
+		{
+			name:          "error - database error",
+			repository:    &repository,
+			mockError:     errors.New("database connection failed"),
+			expectedError: errors.New("database connection failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDB)
+			api := NewApi(mockDB, validKey)
+
+			mockDB.On("CreateSyncRun", mock.MatchedBy(func(run *types.SyncRun) bool {
+				return run.IntegrationConfigID == "config-1" &&
+					run.Repository == tt.repository &&
+					run.Status == types.SyncRunStatusRunning &&
+					!run.StartedAt.IsZero() &&
+					run.FinishedAt == nil
+			})).Return(tt.mockError)
+
+			run, err := api.StartSyncRun(context.Background(), "config-1", tt.repository)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
+				assert.Nil(t, run)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, types.SyncRunStatusRunning, run.Status)
+				assert.Equal(t, tt.repository, run.Repository)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFinishSyncRun(t *testing.T) {
+	validKey := make([]byte, 32)
+	for i := range validKey {
+		validKey[i] = byte(i)
+	}
+
+	errorMessage := "failed to fetch pull requests"
+
+	tests := []struct {
+		name                 string
+		status               types.SyncRunStatus
+		syncErr              error
+		mockError            error
+		expectedErrorMessage *string
+		expectedError        error
+	}{
+		{
+			name:   "success - succeeded run",
+			status: types.SyncRunStatusSucceeded,
+		},
+		{
+			name:                 "success - failed run records the error",
+			status:               types.SyncRunStatusFailed,
+			syncErr:              errors.New("failed to fetch pull requests"),
+			expectedErrorMessage: &errorMessage,
+		},
+		{
+			name:          "error - database error",
+			status:        types.SyncRunStatusSucceeded,
+			mockError:     errors.New("database connection failed"),
+			expectedError: errors.New("database connection failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDB)
+			api := NewApi(mockDB, validKey)
+
+			run := &types.SyncRun{ID: "run-1", IntegrationConfigID: "config-1", Status: types.SyncRunStatusRunning}
+			counts := types.SyncCounts{PullRequests: 3, Comments: 7}
+
+			mockDB.On("UpdateSyncRun", mock.MatchedBy(func(run *types.SyncRun) bool {
+				return run.ID == "run-1" &&
+					run.Status == tt.status &&
+					run.FinishedAt != nil &&
+					run.PullRequestsUpserted == 3 &&
+					run.CommentsUpserted == 7 &&
+					assert.ObjectsAreEqual(tt.expectedErrorMessage, run.ErrorMessage)
+			})).Return(tt.mockError)
+
+			err := api.FinishSyncRun(context.Background(), run, tt.status, counts, tt.syncErr)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetSyncRuns(t *testing.T) {
+	validKey := make([]byte, 32)
+	for i := range validKey {
+		validKey[i] = byte(i)
+	}
+
+	runs := []types.SyncRun{
+		{ID: "run-2", IntegrationConfigID: "config-1", Status: types.SyncRunStatusFailed},
+		{ID: "run-1", IntegrationConfigID: "config-1", Status: types.SyncRunStatusSucceeded},
+	}
+
+	tests := []struct {
+		name          string
+		params        *types.SyncRunParams
+		expectedLimit int
+		mockRuns      []types.SyncRun
+		mockError     error
+		expectedRuns  []types.SyncRun
+		expectedError error
+	}{
+		{
+			name:          "success - default limit",
+			expectedLimit: 50,
+			mockRuns:      runs,
+			expectedRuns:  runs,
+		},
+		{
+			name:          "success - custom limit",
+			params:        &types.SyncRunParams{Limit: 10},
+			expectedLimit: 10,
+			mockRuns:      runs,
+			expectedRuns:  runs,
+		},
+		{
+			name:          "success - limit is capped",
+			params:        &types.SyncRunParams{Limit: 1000},
+			expectedLimit: 200,
+			mockRuns:      runs,
+			expectedRuns:  runs,
+		},
+		{
+			name:          "error - database error",
+			expectedLimit: 50,
+			mockError:     errors.New("database connection failed"),
+			expectedError: errors.New("database connection failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDB)
+			api := NewApi(mockDB, validKey)
+
+			mockDB.On("GetSyncRuns", "config-1", mock.MatchedBy(func(params *types.SyncRunParams) bool {
+				return params.Limit == tt.expectedLimit
+			})).Return(tt.mockRuns, tt.mockError)
+
+			result, err := api.GetSyncRuns(context.Background(), "config-1", tt.params)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedRuns, result)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
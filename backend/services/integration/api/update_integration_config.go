@@ -2,13 +2,15 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 
+	liberrors "ems.dev/backend/libraries/errors"
 	"ems.dev/backend/services/integration/types"
 )
 
 // UpdateIntegrationConfig updates an existing integration config
 func (a *Api) UpdateIntegrationConfig(ctx context.Context, id string, req *types.UpdateIntegrationConfigRequest) (*types.IntegrationConfig, error) {
-	config, err := a.db.GetIntegrationConfig(id)
+	config, err := a.GetIntegrationConfig(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -21,10 +23,58 @@ func (a *Api) UpdateIntegrationConfig(ctx context.Context, id string, req *types
 		config.EncryptedToken = encryptedToken
 	}
 
+	if req.WebhookSecret != "" {
+		encryptedSecret, err := a.encryptToken(req.WebhookSecret)
+		if err != nil {
+			return nil, err
+		}
+		config.EncryptedWebhookSecret = &encryptedSecret
+	}
+
+	if req.SyncSchedule != nil {
+		config.SyncSchedule = nil
+		if *req.SyncSchedule != "" {
+			if err := validateSyncSchedule(*req.SyncSchedule); err != nil {
+				return nil, err
+			}
+			config.SyncSchedule = req.SyncSchedule
+		}
+	}
+
 	if req.Metadata != nil {
+		if config.ProviderType == types.IntegrationProviderTypeSourceControl {
+			if err := validateRepositories(req.Metadata); err != nil {
+				return nil, err
+			}
+
+			var metadata map[string]interface{}
+			if err := json.Unmarshal(req.Metadata, &metadata); err != nil {
+				return nil, liberrors.NewBadRequestError("invalid metadata format")
+			}
+			if err := validateMetadataBaseURL(metadata); err != nil {
+				return nil, err
+			}
+		}
 		config.Metadata = req.Metadata
 	}
 
+	// A new token or metadata can switch a GitHub integration between token and app authentication
+	if config.ProviderName == types.IntegrationProviderGithub && (req.Token != "" || req.Metadata != nil) {
+		token := req.Token
+		// Only app authentication needs the token to be validated, a kept token is then the app's private key
+		if appAuth, err := types.ParseGithubAppAuth(config.Metadata); err == nil && appAuth != nil && token == "" {
+			if token, err = a.DecryptToken(config.EncryptedToken); err != nil {
+				return nil, err
+			}
+		}
+		if err := validateGithubAuth(config.Metadata, token); err != nil {
+			return nil, err
+		}
+		if _, err := types.ParseGithubFetchMode(config.Metadata); err != nil {
+			return nil, liberrors.NewBadRequestError(err.Error())
+		}
+	}
+
 	if err := a.db.UpdateIntegrationConfig(config); err != nil {
 		return nil, err
 	}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	liberrors "ems.dev/backend/libraries/errors"
 	"ems.dev/backend/services/integration/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -17,6 +18,8 @@ func TestUpdateIntegrationConfig(t *testing.T) {
 		validKey[i] = byte(i)
 	}
 
+	appPrivateKey := createGithubAppPrivateKey(t)
+
 	tests := []struct {
 		name          string
 		id            string
@@ -89,6 +92,193 @@ func TestUpdateIntegrationConfig(t *testing.T) {
 				assert.NotNil(t, config.Metadata)
 			},
 		},
+		{
+			name: "success - update webhook secret only",
+			id:   "config-1",
+			req: &types.UpdateIntegrationConfigRequest{
+				WebhookSecret: "webhook-secret",
+			},
+			mockConfig: &types.IntegrationConfig{
+				ID:             "config-1",
+				OrganizationID: "org-1",
+				ProviderName:   types.IntegrationProviderGithub,
+				ProviderType:   types.IntegrationProviderTypeSourceControl,
+				EncryptedToken: "encrypted-token",
+				CreatedAt:      time.Now(),
+				UpdatedAt:      time.Now(),
+			},
+			validateFunc: func(t *testing.T, config *types.IntegrationConfig) {
+				assert.Equal(t, "encrypted-token", config.EncryptedToken) // Should remain unchanged
+				assert.NotNil(t, config.EncryptedWebhookSecret)
+				assert.NotEqual(t, "webhook-secret", *config.EncryptedWebhookSecret) // Should be encrypted
+			},
+		},
+		{
+			name: "success - switch to github app",
+			id:   "config-1",
+			req: &types.UpdateIntegrationConfigRequest{
+				Token:    appPrivateKey,
+				Metadata: createMetadataJSON(`{"repositories": "owner/repo", "auth_type": "app", "app_id": 12345, "installation_id": 67890}`),
+			},
+			mockConfig: &types.IntegrationConfig{
+				ID:             "config-1",
+				OrganizationID: "org-1",
+				ProviderName:   types.IntegrationProviderGithub,
+				ProviderType:   types.IntegrationProviderTypeSourceControl,
+				EncryptedToken: "old-encrypted-token",
+				CreatedAt:      time.Now(),
+				UpdatedAt:      time.Now(),
+			},
+			validateFunc: func(t *testing.T, config *types.IntegrationConfig) {
+				assert.NotEqual(t, "old-encrypted-token", config.EncryptedToken)
+				assert.NotEqual(t, appPrivateKey, config.EncryptedToken) // Should be encrypted
+			},
+		},
+		{
+			name: "error - github app with personal access token",
+			id:   "config-1",
+			req: &types.UpdateIntegrationConfigRequest{
+				Token:    "new-token",
+				Metadata: createMetadataJSON(`{"repositories": "owner/repo", "auth_type": "app", "app_id": 12345, "installation_id": 67890}`),
+			},
+			mockConfig: &types.IntegrationConfig{
+				ID:             "config-1",
+				OrganizationID: "org-1",
+				ProviderName:   types.IntegrationProviderGithub,
+				ProviderType:   types.IntegrationProviderTypeSourceControl,
+				EncryptedToken: "old-encrypted-token",
+				CreatedAt:      time.Now(),
+				UpdatedAt:      time.Now(),
+			},
+			expectedError: liberrors.NewBadRequestError("token must be the PEM encoded private key of the github app"),
+		},
+		{
+			name: "error - invalid repository sync rules",
+			id:   "config-1",
+			req: &types.UpdateIntegrationConfigRequest{
+				Metadata: createMetadataJSON(`{"repositories": [{"name": ""}]}`),
+			},
+			mockConfig: &types.IntegrationConfig{
+				ID:             "config-1",
+				OrganizationID: "org-1",
+				ProviderName:   types.IntegrationProviderGithub,
+				ProviderType:   types.IntegrationProviderTypeSourceControl,
+				EncryptedToken: "encrypted-token",
+				CreatedAt:      time.Now(),
+				UpdatedAt:      time.Now(),
+			},
+			expectedError: liberrors.NewBadRequestError("repository name is required"),
+		},
+		{
+			name: "success - update base url",
+			id:   "config-1",
+			req: &types.UpdateIntegrationConfigRequest{
+				Metadata: createMetadataJSON(`{"repositories": "group/project", "base_url": "https://gitlab.example.com"}`),
+			},
+			mockConfig: &types.IntegrationConfig{
+				ID:             "config-1",
+				OrganizationID: "org-1",
+				ProviderName:   types.IntegrationProviderGitlab,
+				ProviderType:   types.IntegrationProviderTypeSourceControl,
+				EncryptedToken: "encrypted-token",
+				CreatedAt:      time.Now(),
+				UpdatedAt:      time.Now(),
+			},
+			validateFunc: func(t *testing.T, config *types.IntegrationConfig) {
+				assert.JSONEq(t, `{"repositories": "group/project", "base_url": "https://gitlab.example.com"}`, string(config.Metadata))
+			},
+		},
+		{
+			name: "error - invalid base url",
+			id:   "config-1",
+			req: &types.UpdateIntegrationConfigRequest{
+				Metadata: createMetadataJSON(`{"repositories": "group/project", "base_url": "file:///etc/passwd"}`),
+			},
+			mockConfig: &types.IntegrationConfig{
+				ID:             "config-1",
+				OrganizationID: "org-1",
+				ProviderName:   types.IntegrationProviderGitlab,
+				ProviderType:   types.IntegrationProviderTypeSourceControl,
+				EncryptedToken: "encrypted-token",
+				CreatedAt:      time.Now(),
+				UpdatedAt:      time.Now(),
+			},
+			expectedError: liberrors.NewBadRequestError("base_url must be a valid http or https URL"),
+		},
+		{
+			name: "error - empty base url",
+			id:   "config-1",
+			req: &types.UpdateIntegrationConfigRequest{
+				Metadata: createMetadataJSON(`{"repositories": "group/project", "base_url": ""}`),
+			},
+			mockConfig: &types.IntegrationConfig{
+				ID:             "config-1",
+				OrganizationID: "org-1",
+				ProviderName:   types.IntegrationProviderGitlab,
+				ProviderType:   types.IntegrationProviderTypeSourceControl,
+				EncryptedToken: "encrypted-token",
+				CreatedAt:      time.Now(),
+				UpdatedAt:      time.Now(),
+			},
+			expectedError: liberrors.NewBadRequestError("base_url must be a non empty string"),
+		},
+		{
+			name: "success - set sync schedule",
+			id:   "config-1",
+			req: &types.UpdateIntegrationConfigRequest{
+				SyncSchedule: stringPtr("*/15 * * * *"),
+			},
+			mockConfig: &types.IntegrationConfig{
+				ID:             "config-1",
+				OrganizationID: "org-1",
+				ProviderName:   types.IntegrationProviderGithub,
+				ProviderType:   types.IntegrationProviderTypeSourceControl,
+				EncryptedToken: "encrypted-token",
+				CreatedAt:      time.Now(),
+				UpdatedAt:      time.Now(),
+			},
+			validateFunc: func(t *testing.T, config *types.IntegrationConfig) {
+				assert.NotNil(t, config.SyncSchedule)
+				assert.Equal(t, "*/15 * * * *", *config.SyncSchedule)
+			},
+		},
+		{
+			name: "success - reset sync schedule to the default",
+			id:   "config-1",
+			req: &types.UpdateIntegrationConfigRequest{
+				SyncSchedule: stringPtr(""),
+			},
+			mockConfig: &types.IntegrationConfig{
+				ID:             "config-1",
+				OrganizationID: "org-1",
+				ProviderName:   types.IntegrationProviderCursor,
+				ProviderType:   types.IntegrationProviderTypeAICodeAssistant,
+				EncryptedToken: "encrypted-token",
+				SyncSchedule:   stringPtr("@daily"),
+				CreatedAt:      time.Now(),
+				UpdatedAt:      time.Now(),
+			},
+			validateFunc: func(t *testing.T, config *types.IntegrationConfig) {
+				assert.Nil(t, config.SyncSchedule)
+			},
+		},
+		{
+			name: "error - invalid sync schedule",
+			id:   "config-1",
+			req: &types.UpdateIntegrationConfigRequest{
+				SyncSchedule: stringPtr("0 25 * * *"),
+			},
+			mockConfig: &types.IntegrationConfig{
+				ID:             "config-1",
+				OrganizationID: "org-1",
+				ProviderName:   types.IntegrationProviderCursor,
+				ProviderType:   types.IntegrationProviderTypeAICodeAssistant,
+				EncryptedToken: "encrypted-token",
+				CreatedAt:      time.Now(),
+				UpdatedAt:      time.Now(),
+			},
+			expectedError: liberrors.NewBadRequestError(`invalid sync_schedule: invalid hour field: "25" is out of range 0-23`),
+		},
 		{
 			name: "success - no updates (empty request)",
 			id:   "config-1",
@@ -113,8 +303,14 @@ func TestUpdateIntegrationConfig(t *testing.T) {
 			name:          "error - config not found",
 			id:            "non-existent",
 			req:           &types.UpdateIntegrationConfigRequest{Token: "new-token"},
-			mockGetError:  errors.New("record not found"),
-			expectedError: errors.New("record not found"),
+			expectedError: errors.New("integration not found"),
+		},
+		{
+			name:          "error - database get error",
+			id:            "config-1",
+			req:           &types.UpdateIntegrationConfigRequest{Token: "new-token"},
+			mockGetError:  errors.New("database connection failed"),
+			expectedError: errors.New("database connection failed"),
 		},
 		{
 			name: "error - database update error",
@@ -143,7 +339,7 @@ func TestUpdateIntegrationConfig(t *testing.T) {
 
 			mockDB.On("GetIntegrationConfig", tt.id).Return(tt.mockConfig, tt.mockGetError)
 
-			if tt.mockGetError == nil {
+			if tt.mockGetError == nil && (tt.expectedError == nil || tt.mockUpdateError != nil) {
 				mockDB.On("UpdateIntegrationConfig", mock.AnythingOfType("*types.IntegrationConfig")).Return(tt.mockUpdateError).Run(func(args mock.Arguments) {
 					config := args.Get(0).(*types.IntegrationConfig)
 					if tt.validateFunc != nil && tt.mockUpdateError == nil {
@@ -170,3 +366,8 @@ func TestUpdateIntegrationConfig(t *testing.T) {
 		})
 	}
 }
+
+// Helper function to create a string pointer
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,11 @@
+package api
+
+import (
+	"context"
+	"time"
+)
+
+// UpdateLastSyncedAt records the time of the last successful sync of an integration config
+func (a *Api) UpdateLastSyncedAt(ctx context.Context, id string, syncedAt time.Time) error {
+	return a.db.UpdateLastSyncedAt(id, syncedAt)
+}
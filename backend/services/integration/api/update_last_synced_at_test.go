@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateLastSyncedAt(t *testing.T) {
+	validKey := make([]byte, 32)
+	for i := range validKey {
+		validKey[i] = byte(i)
+	}
+
+	syncedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		id            string
+		mockError     error
+		expectedError error
+	}{
+		{
+			name: "success",
+			id:   "config-1",
+		},
+		{
+			name:          "error - database error",
+			id:            "config-1",
+			mockError:     errors.New("database connection failed"),
+			expectedError: errors.New("database connection failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockDB)
+			api := NewApi(mockDB, validKey)
+
+			mockDB.On("UpdateLastSyncedAt", tt.id, syncedAt).Return(tt.mockError)
+
+			err := api.UpdateLastSyncedAt(context.Background(), tt.id, syncedAt)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
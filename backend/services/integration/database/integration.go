@@ -1,8 +1,12 @@
 package database
 
 import (
+	"errors"
+	"time"
+
 	"ems.dev/backend/services/integration/types"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // DB defines the interface for integration database operations
@@ -12,6 +16,26 @@ type DB interface {
 	GetOrganizationIntegrationConfigs(orgID string) ([]types.IntegrationConfig, error)
 	UpdateIntegrationConfig(config *types.IntegrationConfig) error
 	DeleteIntegrationConfig(id string) error
+	UpdateLastSyncedAt(id string, syncedAt time.Time) error
+
+	// Repository sync cursors
+	GetRepositorySyncCursor(integrationConfigID, repository string) (*types.RepositorySyncCursor, error)
+	UpsertRepositorySyncCursor(cursor *types.RepositorySyncCursor) error
+
+	// Sync runs
+	CreateSyncRun(run *types.SyncRun) error
+	UpdateSyncRun(run *types.SyncRun) error
+	GetSyncRuns(integrationConfigID string, params *types.SyncRunParams) ([]types.SyncRun, error)
+
+	// Sync leases
+	AcquireSyncLease(integrationConfigID, holder string, ttl time.Duration) (bool, error)
+	RenewSyncLease(integrationConfigID, holder string, ttl time.Duration) (bool, error)
+	ReleaseSyncLease(integrationConfigID, holder string) error
+
+	// Repository backfills
+	CreateRepositoryBackfills(backfills []*types.RepositoryBackfill) error
+	GetRepositoryBackfills(params *types.RepositoryBackfillParams) ([]types.RepositoryBackfill, error)
+	UpdateRepositoryBackfill(backfill *types.RepositoryBackfill) error
 }
 
 type IntegrationDB struct {
@@ -29,11 +53,14 @@ func (d *IntegrationDB) CreateIntegrationConfig(config *types.IntegrationConfig)
 	return d.db.Create(config).Error
 }
 
-// GetIntegrationConfig retrieves an integration config by ID
+// GetIntegrationConfig retrieves an integration config by ID. Returns nil if it doesn't exist.
 func (d *IntegrationDB) GetIntegrationConfig(id string) (*types.IntegrationConfig, error) {
 	var config types.IntegrationConfig
 	err := d.db.First(&config, "id = ?", id).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
 		return nil, err
 	}
 	return &config, nil
@@ -58,3 +85,151 @@ func (d *IntegrationDB) UpdateIntegrationConfig(config *types.IntegrationConfig)
 func (d *IntegrationDB) DeleteIntegrationConfig(id string) error {
 	return d.db.Delete(&types.IntegrationConfig{}, "id = ?", id).Error
 }
+
+// UpdateLastSyncedAt sets the time of the last successful sync of an integration config
+func (d *IntegrationDB) UpdateLastSyncedAt(id string, syncedAt time.Time) error {
+	return d.db.Model(&types.IntegrationConfig{}).Where("id = ?", id).Update("last_synced_at", syncedAt).Error
+}
+
+// GetRepositorySyncCursor retrieves the sync cursor of a repository. Returns nil if the repository was never synced.
+func (d *IntegrationDB) GetRepositorySyncCursor(integrationConfigID, repository string) (*types.RepositorySyncCursor, error) {
+	var cursor types.RepositorySyncCursor
+	err := d.db.Where("integration_config_id = ? AND repository = ?", integrationConfigID, repository).First(&cursor).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// UpsertRepositorySyncCursor creates or moves the sync cursor of a repository
+func (d *IntegrationDB) UpsertRepositorySyncCursor(cursor *types.RepositorySyncCursor) error {
+	return d.db.
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: "integration_config_id"},
+				{Name: "repository"},
+			},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"last_updated_at",
+				"updated_at",
+			}),
+		}).
+		Create(cursor).
+		Error
+}
+
+// CreateSyncRun creates a new sync run
+func (d *IntegrationDB) CreateSyncRun(run *types.SyncRun) error {
+	return d.db.Create(run).Error
+}
+
+// UpdateSyncRun saves the status, counts and error of a sync run
+func (d *IntegrationDB) UpdateSyncRun(run *types.SyncRun) error {
+	return d.db.Model(&types.SyncRun{}).Where("id = ?", run.ID).Updates(map[string]interface{}{
+		"status":                 run.Status,
+		"finished_at":            run.FinishedAt,
+		"pull_requests_upserted": run.PullRequestsUpserted,
+		"comments_upserted":      run.CommentsUpserted,
+		"error_message":          run.ErrorMessage,
+	}).Error
+}
+
+// GetSyncRuns retrieves the sync runs of an integration config, most recent first
+func (d *IntegrationDB) GetSyncRuns(integrationConfigID string, params *types.SyncRunParams) ([]types.SyncRun, error) {
+	query := d.db.Where("integration_config_id = ?", integrationConfigID)
+
+	if params.Repository != nil {
+		query = query.Where("repository = ?", *params.Repository)
+	}
+	if params.Status != nil {
+		query = query.Where("status = ?", *params.Status)
+	}
+	if params.Limit > 0 {
+		query = query.Limit(params.Limit)
+	}
+
+	var runs []types.SyncRun
+	if err := query.Order("started_at DESC").Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// AcquireSyncLease takes the sync lease of an integration config for the holder, unless another holder has
+// a lease which didn't expire. Expiry is computed with the database clock, so replicas don't need synced clocks.
+func (d *IntegrationDB) AcquireSyncLease(integrationConfigID, holder string, ttl time.Duration) (bool, error) {
+	result := d.db.Exec(`
+		INSERT INTO integration_sync_leases (integration_config_id, holder, acquired_at, expires_at)
+		VALUES (?, ?, now(), now() + ? * interval '1 millisecond')
+		ON CONFLICT (integration_config_id) DO UPDATE
+		SET holder = EXCLUDED.holder, acquired_at = EXCLUDED.acquired_at, expires_at = EXCLUDED.expires_at
+		WHERE integration_sync_leases.expires_at < now()`,
+		integrationConfigID, holder, ttl.Milliseconds())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RenewSyncLease extends the sync lease of an integration config. Returns false if the holder lost the lease.
+func (d *IntegrationDB) RenewSyncLease(integrationConfigID, holder string, ttl time.Duration) (bool, error) {
+	result := d.db.Exec(`
+		UPDATE integration_sync_leases
+		SET expires_at = now() + ? * interval '1 millisecond'
+		WHERE integration_config_id = ? AND holder = ? AND expires_at >= now()`,
+		ttl.Milliseconds(), integrationConfigID, holder)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ReleaseSyncLease gives up the sync lease of an integration config if the holder still has it
+func (d *IntegrationDB) ReleaseSyncLease(integrationConfigID, holder string) error {
+	return d.db.Exec(
+		"DELETE FROM integration_sync_leases WHERE integration_config_id = ? AND holder = ?",
+		integrationConfigID, holder).Error
+}
+
+// CreateRepositoryBackfills creates repository backfills
+func (d *IntegrationDB) CreateRepositoryBackfills(backfills []*types.RepositoryBackfill) error {
+	return d.db.Create(&backfills).Error
+}
+
+// GetRepositoryBackfills retrieves repository backfills, most recent first
+func (d *IntegrationDB) GetRepositoryBackfills(params *types.RepositoryBackfillParams) ([]types.RepositoryBackfill, error) {
+	query := d.db.Model(&types.RepositoryBackfill{})
+
+	if params.IntegrationConfigID != nil {
+		query = query.Where("integration_config_id = ?", *params.IntegrationConfigID)
+	}
+	if params.Repository != nil {
+		query = query.Where("repository = ?", *params.Repository)
+	}
+	if len(params.Statuses) > 0 {
+		query = query.Where("status IN ?", params.Statuses)
+	}
+
+	var backfills []types.RepositoryBackfill
+	if err := query.Order("created_at DESC").Find(&backfills).Error; err != nil {
+		return nil, err
+	}
+	return backfills, nil
+}
+
+// UpdateRepositoryBackfill saves the checkpoint, status and counts of a repository backfill
+func (d *IntegrationDB) UpdateRepositoryBackfill(backfill *types.RepositoryBackfill) error {
+	return d.db.Model(&types.RepositoryBackfill{}).Where("id = ?", backfill.ID).Updates(map[string]interface{}{
+		"checkpoint":           backfill.Checkpoint,
+		"status":               backfill.Status,
+		"paused_until":         backfill.PausedUntil,
+		"pull_requests_synced": backfill.PullRequestsSynced,
+		"comments_synced":      backfill.CommentsSynced,
+		"error_message":        backfill.ErrorMessage,
+		"completed_at":         backfill.CompletedAt,
+		"updated_at":           time.Now(),
+	}).Error
+}
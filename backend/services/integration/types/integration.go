@@ -1,6 +1,8 @@
 package types
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"gorm.io/datatypes"
@@ -9,8 +11,10 @@ import (
 type IntegrationProvider string
 
 const (
-	IntegrationProviderGithub IntegrationProvider = "github"
-	IntegrationProviderCursor IntegrationProvider = "cursor"
+	IntegrationProviderGithub    IntegrationProvider = "github"
+	IntegrationProviderGitlab    IntegrationProvider = "gitlab"
+	IntegrationProviderBitbucket IntegrationProvider = "bitbucket"
+	IntegrationProviderCursor    IntegrationProvider = "cursor"
 )
 
 type IntegrationProviderType string
@@ -21,25 +25,117 @@ const (
 	IntegrationProviderTypeAICodeAssistant    IntegrationProviderType = "AICodeAssistant"
 )
 
+// GitHub integrations authenticate with a personal access token unless the "auth_type" metadata key is
+// "app". GitHub App integrations store the app's private key as their token and set "app_id" and
+// "installation_id" in their metadata.
+const (
+	GithubAuthTypeToken = "token"
+	GithubAuthTypeApp   = "app"
+)
+
+// GithubAppAuth is the GitHub App installation an integration authenticates as
+type GithubAppAuth struct {
+	AppID          string
+	InstallationID string
+}
+
+// ParseGithubAppAuth returns the GitHub App installation configured in an integration's metadata, or
+// nil if the integration authenticates with a personal access token
+func ParseGithubAppAuth(metadata datatypes.JSON) (*GithubAppAuth, error) {
+	if metadata == nil {
+		return nil, nil
+	}
+
+	var auth struct {
+		AuthType       string      `json:"auth_type"`
+		AppID          json.Number `json:"app_id"`
+		InstallationID json.Number `json:"installation_id"`
+	}
+	if err := json.Unmarshal(metadata, &auth); err != nil {
+		return nil, fmt.Errorf("invalid github app metadata: %w", err)
+	}
+
+	switch auth.AuthType {
+	case "", GithubAuthTypeToken:
+		return nil, nil
+	case GithubAuthTypeApp:
+		if auth.AppID == "" || auth.InstallationID == "" {
+			return nil, fmt.Errorf("app_id and installation_id are required for github app authentication")
+		}
+		return &GithubAppAuth{AppID: auth.AppID.String(), InstallationID: auth.InstallationID.String()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported auth_type %q", auth.AuthType)
+	}
+}
+
+// GitHub integrations fetch pull requests with the REST API unless the "fetch_mode" metadata key is
+// "graphql", which fetches pull requests with their reviews, comments and commits in batches.
+const (
+	GithubFetchModeREST    = "rest"
+	GithubFetchModeGraphQL = "graphql"
+)
+
+// ParseGithubFetchMode returns the fetch mode configured in an integration's metadata
+func ParseGithubFetchMode(metadata datatypes.JSON) (string, error) {
+	if metadata == nil {
+		return GithubFetchModeREST, nil
+	}
+
+	var mode struct {
+		FetchMode string `json:"fetch_mode"`
+	}
+	if err := json.Unmarshal(metadata, &mode); err != nil {
+		return "", fmt.Errorf("invalid github fetch mode metadata: %w", err)
+	}
+
+	switch mode.FetchMode {
+	case "", GithubFetchModeREST:
+		return GithubFetchModeREST, nil
+	case GithubFetchModeGraphQL:
+		return GithubFetchModeGraphQL, nil
+	default:
+		return "", fmt.Errorf("unsupported fetch_mode %q", mode.FetchMode)
+	}
+}
+
 type IntegrationConfig struct {
-	ID             string                  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	OrganizationID string                  `json:"organization_id"`
-	ProviderName   IntegrationProvider     `json:"provider_name"`
-	ProviderType   IntegrationProviderType `json:"provider_type"`
-	EncryptedToken string                  `json:"encrypted_token"`
-	Metadata       datatypes.JSON          `json:"metadata"`
-	LastSyncedAt   *time.Time              `json:"last_synced_at"`
-	CreatedAt      time.Time               `json:"created_at" gorm:"default:now()"`
-	UpdatedAt      time.Time               `json:"updated_at"`
+	ID                     string                  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	OrganizationID         string                  `json:"organization_id"`
+	ProviderName           IntegrationProvider     `json:"provider_name"`
+	ProviderType           IntegrationProviderType `json:"provider_type"`
+	EncryptedToken         string                  `json:"encrypted_token"`
+	EncryptedWebhookSecret *string                 `json:"-"`
+	Metadata               datatypes.JSON          `json:"metadata"`
+	SyncSchedule           *string                 `json:"sync_schedule"`
+	LastSyncedAt           *time.Time              `json:"last_synced_at"`
+	CreatedAt              time.Time               `json:"created_at" gorm:"default:now()"`
+	UpdatedAt              time.Time               `json:"updated_at"`
 }
 
 type CreateIntegrationConfigRequest struct {
-	ProviderName IntegrationProvider `json:"provider_name" binding:"required"`
-	Token        string              `json:"token" binding:"required"`
-	Metadata     datatypes.JSON      `json:"metadata"`
+	ProviderName  IntegrationProvider `json:"provider_name" binding:"required"`
+	Token         string              `json:"token" binding:"required"`
+	Metadata      datatypes.JSON      `json:"metadata"`
+	WebhookSecret string              `json:"webhook_secret"`
+	// SyncSchedule is a cron expression, the provider type's default schedule is used when empty
+	SyncSchedule string `json:"sync_schedule"`
 }
 
 type UpdateIntegrationConfigRequest struct {
-	Token    string         `json:"token"`
-	Metadata datatypes.JSON `json:"metadata"`
+	Token         string         `json:"token"`
+	Metadata      datatypes.JSON `json:"metadata"`
+	WebhookSecret string         `json:"webhook_secret"`
+	// SyncSchedule replaces the cron expression of the sync schedule when set, an empty string restores the default
+	SyncSchedule *string `json:"sync_schedule"`
+}
+
+// RepositorySyncCursor is the watermark of a repository sync: the most recent pull request update
+// that was imported. The next sync only fetches pull requests updated after it.
+type RepositorySyncCursor struct {
+	ID                  string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	IntegrationConfigID string    `json:"integration_config_id"`
+	Repository          string    `json:"repository"`
+	LastUpdatedAt       time.Time `json:"last_updated_at"`
+	CreatedAt           time.Time `json:"created_at" gorm:"default:now()"`
+	UpdatedAt           time.Time `json:"updated_at"`
 }
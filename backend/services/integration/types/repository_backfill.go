@@ -0,0 +1,91 @@
+package types
+
+import (
+	"math"
+	"time"
+)
+
+type BackfillStatus string
+
+const (
+	BackfillStatusPending   BackfillStatus = "pending"
+	BackfillStatusRunning   BackfillStatus = "running"
+	BackfillStatusPaused    BackfillStatus = "paused"
+	BackfillStatusCompleted BackfillStatus = "completed"
+	BackfillStatusFailed    BackfillStatus = "failed"
+)
+
+// ActiveBackfillStatuses are the statuses of the backfills which still have pull requests to import
+var ActiveBackfillStatuses = []BackfillStatus{BackfillStatusPending, BackfillStatusRunning, BackfillStatusPaused}
+
+// RepositoryBackfill imports the pull requests of a repository created between two dates, in chunks
+// from the oldest to the most recent. The checkpoint is the end of the last chunk that was imported, a
+// backfill interrupted by a crash or a rate limit resumes from it.
+type RepositoryBackfill struct {
+	ID                  string         `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	IntegrationConfigID string         `json:"integration_config_id"`
+	Repository          string         `json:"repository"`
+	StartDate           time.Time      `json:"start_date"`
+	EndDate             time.Time      `json:"end_date"`
+	Checkpoint          time.Time      `json:"checkpoint"`
+	Status              BackfillStatus `json:"status"`
+	// PausedUntil is when the provider's rate limit resets, for paused backfills
+	PausedUntil        *time.Time `json:"paused_until"`
+	PullRequestsSynced int        `json:"pull_requests_synced"`
+	CommentsSynced     int        `json:"comments_synced"`
+	ErrorMessage       *string    `json:"error_message"`
+	CreatedAt          time.Time  `json:"created_at" gorm:"default:now()"`
+	UpdatedAt          time.Time  `json:"updated_at" gorm:"default:now()"`
+	CompletedAt        *time.Time `json:"completed_at"`
+
+	// ProgressPercent is computed by Progress when the backfill is returned by the API
+	ProgressPercent float64 `json:"progress_percent" gorm:"-"`
+}
+
+// Progress returns the share of the backfill's date range which was imported, as a percentage rounded
+// to one decimal
+func (b *RepositoryBackfill) Progress() float64 {
+	if b.Status == BackfillStatusCompleted {
+		return 100
+	}
+
+	total := b.EndDate.Sub(b.StartDate)
+	if total <= 0 {
+		return 0
+	}
+
+	done := b.Checkpoint.Sub(b.StartDate)
+	if done <= 0 {
+		return 0
+	}
+	if done > total {
+		done = total
+	}
+	return math.Round(float64(done)/float64(total)*1000) / 10
+}
+
+// Active reports whether the backfill still has pull requests to import
+func (b *RepositoryBackfill) Active() bool {
+	for _, status := range ActiveBackfillStatuses {
+		if b.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateRepositoryBackfillRequest starts backfills of the pull requests created between two dates
+type CreateRepositoryBackfillRequest struct {
+	// Repository is the repository to backfill, every repository of the integration when empty
+	Repository string    `json:"repository"`
+	StartDate  time.Time `json:"start_date" binding:"required"`
+	// EndDate defaults to now
+	EndDate *time.Time `json:"end_date"`
+}
+
+// RepositoryBackfillParams filters the repository backfills
+type RepositoryBackfillParams struct {
+	IntegrationConfigID *string
+	Repository          *string
+	Statuses            []BackfillStatus
+}
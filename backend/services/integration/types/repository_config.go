@@ -0,0 +1,180 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// RepositoryConfig is the sync configuration of a repository of a source control integration. The
+// "repositories" metadata key is either a list of repository configs or a comma-separated string of
+// repository names, which syncs every pull request of the repositories.
+type RepositoryConfig struct {
+	// Name is the full name of the repository (e.g. "owner/repo" or "group/subgroup/project")
+	Name string `json:"name"`
+	// IncludeBaseBranches only syncs pull requests whose base branch matches one of the globs, all when empty
+	IncludeBaseBranches []string `json:"include_base_branches,omitempty"`
+	// ExcludeBaseBranches skips pull requests whose base branch matches one of the globs
+	ExcludeBaseBranches []string `json:"exclude_base_branches,omitempty"`
+	// IgnoreDrafts skips draft pull requests
+	IgnoreDrafts bool `json:"ignore_drafts,omitempty"`
+	// IgnoreLabels skips pull requests with one of the labels (case-insensitive)
+	IgnoreLabels []string `json:"ignore_labels,omitempty"`
+	// IgnoreAuthors skips pull requests opened by one of the usernames (case-insensitive)
+	IgnoreAuthors []string `json:"ignore_authors,omitempty"`
+	// MaxLookbackDays skips pull requests which weren't updated in the last days, no limit when 0
+	MaxLookbackDays int `json:"max_lookback_days,omitempty"`
+}
+
+// RepositoryPullRequest holds the pull request attributes the repository sync rules apply to
+type RepositoryPullRequest struct {
+	BaseBranch string
+	Draft      bool
+	Labels     []string
+	Author     string
+	UpdatedAt  time.Time
+}
+
+// ParseRepositoryConfigs returns the repositories configured in an integration's metadata. Returns nil
+// when no repositories are configured.
+func ParseRepositoryConfigs(metadata datatypes.JSON) ([]RepositoryConfig, error) {
+	if metadata == nil {
+		return nil, nil
+	}
+
+	var raw struct {
+		Repositories json.RawMessage `json:"repositories"`
+	}
+	if err := json.Unmarshal(metadata, &raw); err != nil {
+		return nil, err
+	}
+
+	repositories := bytes.TrimSpace(raw.Repositories)
+	if len(repositories) == 0 || bytes.Equal(repositories, []byte("null")) {
+		return nil, nil
+	}
+
+	switch repositories[0] {
+	case '"':
+		var names string
+		if err := json.Unmarshal(repositories, &names); err != nil {
+			return nil, err
+		}
+
+		configs := []RepositoryConfig{}
+		for _, name := range strings.Split(names, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				configs = append(configs, RepositoryConfig{Name: name})
+			}
+		}
+		return configs, nil
+	case '[':
+		var configs []RepositoryConfig
+		if err := json.Unmarshal(repositories, &configs); err != nil {
+			return nil, fmt.Errorf("invalid repositories: %w", err)
+		}
+		return configs, nil
+	default:
+		return nil, fmt.Errorf("repositories must be a comma-separated string or a list of repositories")
+	}
+}
+
+// ValidateRepositoryConfigs checks the names, branch globs and lookback of the repository configs
+func ValidateRepositoryConfigs(configs []RepositoryConfig) error {
+	seen := make(map[string]bool)
+	for _, config := range configs {
+		name := strings.TrimSpace(config.Name)
+		if name == "" {
+			return fmt.Errorf("repository name is required")
+		}
+		if seen[strings.ToLower(name)] {
+			return fmt.Errorf("repository %s is configured more than once", name)
+		}
+		seen[strings.ToLower(name)] = true
+
+		for _, pattern := range append(append([]string{}, config.IncludeBaseBranches...), config.ExcludeBaseBranches...) {
+			if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+				return fmt.Errorf("invalid base branch pattern %q for repository %s", pattern, name)
+			}
+		}
+
+		if config.MaxLookbackDays < 0 {
+			return fmt.Errorf("max_lookback_days must not be negative for repository %s", name)
+		}
+	}
+	return nil
+}
+
+// FindRepositoryConfig returns the config of the repository with the given full name, or nil if the
+// repository is not configured
+func FindRepositoryConfig(configs []RepositoryConfig, name string) *RepositoryConfig {
+	for i := range configs {
+		if strings.EqualFold(strings.TrimSpace(configs[i].Name), name) {
+			return &configs[i]
+		}
+	}
+	return nil
+}
+
+// ShouldSync reports whether a pull request passes the repository's sync rules
+func (c *RepositoryConfig) ShouldSync(pr RepositoryPullRequest, now time.Time) bool {
+	if len(c.IncludeBaseBranches) > 0 && !matchesAny(c.IncludeBaseBranches, pr.BaseBranch) {
+		return false
+	}
+	if matchesAny(c.ExcludeBaseBranches, pr.BaseBranch) {
+		return false
+	}
+
+	if c.IgnoreDrafts && pr.Draft {
+		return false
+	}
+
+	for _, label := range pr.Labels {
+		if containsFold(c.IgnoreLabels, label) {
+			return false
+		}
+	}
+
+	if containsFold(c.IgnoreAuthors, pr.Author) {
+		return false
+	}
+
+	if lookbackStart := c.LookbackStart(now); lookbackStart != nil && pr.UpdatedAt.Before(*lookbackStart) {
+		return false
+	}
+
+	return true
+}
+
+// LookbackStart returns the oldest update time of the pull requests to sync, or nil without a max lookback
+func (c *RepositoryConfig) LookbackStart(now time.Time) *time.Time {
+	if c.MaxLookbackDays <= 0 {
+		return nil
+	}
+	start := now.AddDate(0, 0, -c.MaxLookbackDays)
+	return &start
+}
+
+// matchesAny reports whether the branch matches one of the globs
+func matchesAny(patterns []string, branch string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, branch); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,259 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/datatypes"
+)
+
+func TestParseRepositoryConfigs(t *testing.T) {
+	tests := []struct {
+		name          string
+		metadata      datatypes.JSON
+		expected      []RepositoryConfig
+		expectedError string
+	}{
+		{
+			name: "no metadata",
+		},
+		{
+			name:     "no repositories",
+			metadata: datatypes.JSON(`{"base_url": "https://gitlab.example.com"}`),
+		},
+		{
+			name:     "null repositories",
+			metadata: datatypes.JSON(`{"repositories": null}`),
+		},
+		{
+			name:     "legacy comma-separated repositories",
+			metadata: datatypes.JSON(`{"repositories": "acme/api, acme/web,,"}`),
+			expected: []RepositoryConfig{{Name: "acme/api"}, {Name: "acme/web"}},
+		},
+		{
+			name:     "empty legacy repositories",
+			metadata: datatypes.JSON(`{"repositories": ""}`),
+			expected: []RepositoryConfig{},
+		},
+		{
+			// Migration 000036 converts the legacy GitHub repositories to this form
+			name:     "repository configs",
+			metadata: datatypes.JSON(`{"repositories": [{"name": "acme/api", "exclude_base_branches": ["prod"]}, {"name": "acme/web", "ignore_drafts": true, "max_lookback_days": 30}]}`),
+			expected: []RepositoryConfig{
+				{Name: "acme/api", ExcludeBaseBranches: []string{"prod"}},
+				{Name: "acme/web", IgnoreDrafts: true, MaxLookbackDays: 30},
+			},
+		},
+		{
+			name:          "invalid repository configs",
+			metadata:      datatypes.JSON(`{"repositories": [{"name": 1}]}`),
+			expectedError: "invalid repositories: json: cannot unmarshal number",
+		},
+		{
+			name:          "repositories of another type",
+			metadata:      datatypes.JSON(`{"repositories": 1}`),
+			expectedError: "repositories must be a comma-separated string or a list of repositories",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs, err := ParseRepositoryConfigs(tt.metadata)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, configs)
+		})
+	}
+}
+
+func TestValidateRepositoryConfigs(t *testing.T) {
+	tests := []struct {
+		name          string
+		configs       []RepositoryConfig
+		expectedError string
+	}{
+		{
+			name: "valid repositories",
+			configs: []RepositoryConfig{
+				{Name: "acme/api", IncludeBaseBranches: []string{"main", "release/*"}, MaxLookbackDays: 30},
+				{Name: "acme/web", ExcludeBaseBranches: []string{"prod"}},
+			},
+		},
+		{
+			name:          "missing name",
+			configs:       []RepositoryConfig{{Name: " "}},
+			expectedError: "repository name is required",
+		},
+		{
+			name:          "repository configured twice",
+			configs:       []RepositoryConfig{{Name: "acme/api"}, {Name: "ACME/api"}},
+			expectedError: "repository ACME/api is configured more than once",
+		},
+		{
+			name:          "malformed base branch glob",
+			configs:       []RepositoryConfig{{Name: "acme/api", IncludeBaseBranches: []string{"release/["}}},
+			expectedError: `invalid base branch pattern "release/[" for repository acme/api`,
+		},
+		{
+			name:          "empty base branch glob",
+			configs:       []RepositoryConfig{{Name: "acme/api", ExcludeBaseBranches: []string{""}}},
+			expectedError: `invalid base branch pattern "" for repository acme/api`,
+		},
+		{
+			name:          "negative lookback",
+			configs:       []RepositoryConfig{{Name: "acme/api", MaxLookbackDays: -1}},
+			expectedError: "max_lookback_days must not be negative for repository acme/api",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRepositoryConfigs(tt.configs)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestShouldSync(t *testing.T) {
+	now := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+	pr := func(modify func(*RepositoryPullRequest)) RepositoryPullRequest {
+		pr := RepositoryPullRequest{BaseBranch: "main", Labels: []string{"backend"}, Author: "alice", UpdatedAt: now}
+		if modify != nil {
+			modify(&pr)
+		}
+		return pr
+	}
+
+	tests := []struct {
+		name     string
+		config   RepositoryConfig
+		pr       RepositoryPullRequest
+		expected bool
+	}{
+		{
+			name:     "no rules",
+			pr:       pr(nil),
+			expected: true,
+		},
+		{
+			name:     "included base branch",
+			config:   RepositoryConfig{IncludeBaseBranches: []string{"develop", "main"}},
+			pr:       pr(nil),
+			expected: true,
+		},
+		{
+			name:     "included base branch glob",
+			config:   RepositoryConfig{IncludeBaseBranches: []string{"release/*"}},
+			pr:       pr(func(pr *RepositoryPullRequest) { pr.BaseBranch = "release/1.2" }),
+			expected: true,
+		},
+		{
+			name:   "base branch which isn't included",
+			config: RepositoryConfig{IncludeBaseBranches: []string{"release/*"}},
+			pr:     pr(nil),
+		},
+		{
+			name:   "globs don't match across slashes",
+			config: RepositoryConfig{IncludeBaseBranches: []string{"release/*"}},
+			pr:     pr(func(pr *RepositoryPullRequest) { pr.BaseBranch = "release/1.2/hotfix" }),
+		},
+		{
+			name:   "excluded base branch",
+			config: RepositoryConfig{ExcludeBaseBranches: []string{"prod"}},
+			pr:     pr(func(pr *RepositoryPullRequest) { pr.BaseBranch = "prod" }),
+		},
+		{
+			name:   "excludes win over includes",
+			config: RepositoryConfig{IncludeBaseBranches: []string{"*"}, ExcludeBaseBranches: []string{"main"}},
+			pr:     pr(nil),
+		},
+		{
+			name:   "ignored draft",
+			config: RepositoryConfig{IgnoreDrafts: true},
+			pr:     pr(func(pr *RepositoryPullRequest) { pr.Draft = true }),
+		},
+		{
+			name:     "drafts are synced by default",
+			pr:       pr(func(pr *RepositoryPullRequest) { pr.Draft = true }),
+			expected: true,
+		},
+		{
+			name:   "ignored label",
+			config: RepositoryConfig{IgnoreLabels: []string{"Dependencies"}},
+			pr:     pr(func(pr *RepositoryPullRequest) { pr.Labels = []string{"backend", "dependencies"} }),
+		},
+		{
+			name:     "other labels",
+			config:   RepositoryConfig{IgnoreLabels: []string{"dependencies"}},
+			pr:       pr(nil),
+			expected: true,
+		},
+		{
+			name:   "ignored author",
+			config: RepositoryConfig{IgnoreAuthors: []string{"Dependabot[bot]"}},
+			pr:     pr(func(pr *RepositoryPullRequest) { pr.Author = "dependabot[bot]" }),
+		},
+		{
+			name:     "other authors",
+			config:   RepositoryConfig{IgnoreAuthors: []string{"dependabot[bot]"}},
+			pr:       pr(nil),
+			expected: true,
+		},
+		{
+			name:     "updated within the lookback",
+			config:   RepositoryConfig{MaxLookbackDays: 7},
+			pr:       pr(func(pr *RepositoryPullRequest) { pr.UpdatedAt = now.AddDate(0, 0, -7) }),
+			expected: true,
+		},
+		{
+			name:   "updated before the lookback",
+			config: RepositoryConfig{MaxLookbackDays: 7},
+			pr:     pr(func(pr *RepositoryPullRequest) { pr.UpdatedAt = now.AddDate(0, 0, -7).Add(-time.Second) }),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.config.ShouldSync(tt.pr, now))
+		})
+	}
+}
+
+func TestLookbackStart(t *testing.T) {
+	now := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		maxLookbackDays int
+		expected        *time.Time
+	}{
+		{
+			name: "no max lookback",
+		},
+		{
+			name:            "negative max lookback",
+			maxLookbackDays: -1,
+		},
+		{
+			name:            "max lookback",
+			maxLookbackDays: 30,
+			expected:        &start,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := RepositoryConfig{MaxLookbackDays: tt.maxLookbackDays}
+			assert.Equal(t, tt.expected, config.LookbackStart(now))
+		})
+	}
+}
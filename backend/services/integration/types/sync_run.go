@@ -0,0 +1,40 @@
+package types
+
+import "time"
+
+type SyncRunStatus string
+
+const (
+	SyncRunStatusRunning     SyncRunStatus = "running"
+	SyncRunStatusSucceeded   SyncRunStatus = "succeeded"
+	SyncRunStatusFailed      SyncRunStatus = "failed"
+	SyncRunStatusRateLimited SyncRunStatus = "rate_limited"
+)
+
+// SyncRun records a sync of an integration. Source control integrations record a run per repository,
+// integrations without repositories record a run per sync with a nil repository.
+type SyncRun struct {
+	ID                   string        `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	IntegrationConfigID  string        `json:"integration_config_id"`
+	Repository           *string       `json:"repository"`
+	Status               SyncRunStatus `json:"status"`
+	StartedAt            time.Time     `json:"started_at"`
+	FinishedAt           *time.Time    `json:"finished_at"`
+	PullRequestsUpserted int           `json:"pull_requests_upserted"`
+	CommentsUpserted     int           `json:"comments_upserted"`
+	ErrorMessage         *string       `json:"error_message"`
+	CreatedAt            time.Time     `json:"created_at" gorm:"default:now()"`
+}
+
+// SyncCounts counts the records a sync upserted
+type SyncCounts struct {
+	PullRequests int
+	Comments     int
+}
+
+// SyncRunParams filters the sync runs of an integration
+type SyncRunParams struct {
+	Repository *string
+	Status     *SyncRunStatus
+	Limit      int
+}
@@ -30,6 +30,13 @@ type MemberAPI interface {
 	// UpdateExternalAccountMemberID updates the member_id association for an external account
 	// Validates that the account belongs to the specified organization
 	UpdateExternalAccountMemberID(ctx context.Context, organizationID string, accountID string, memberID *string) (*types.ExternalAccount, error)
+
+	// Bot Rules
+	GetBotRules(ctx context.Context, organizationID string) ([]types.BotRule, error)
+	CreateBotRule(ctx context.Context, organizationID string, req types.CreateBotRuleRequest) (*types.BotRule, error)
+	DeleteBotRule(ctx context.Context, organizationID string, ruleID string) error
+	// MarkBotAccounts updates the bot flag of the external accounts of an organization from its bot rules
+	MarkBotAccounts(ctx context.Context, organizationID string) error
 }
 
 type Api struct {
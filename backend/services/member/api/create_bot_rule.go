@@ -0,0 +1,52 @@
+package api
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"ems.dev/backend/libraries/errors"
+	"ems.dev/backend/services/member/types"
+)
+
+// CreateBotRule registers a bot account of an organization by username or pattern, and marks the matching accounts
+func (a *Api) CreateBotRule(ctx context.Context, organizationID string, req types.CreateBotRuleRequest) (*types.BotRule, error) {
+	username := trimmedOrNil(req.Username)
+	pattern := trimmedOrNil(req.Pattern)
+	if (username == nil) == (pattern == nil) {
+		return nil, errors.NewBadRequestError("either a username or a pattern is required")
+	}
+
+	if pattern != nil {
+		if _, err := regexp.Compile(*pattern); err != nil {
+			return nil, errors.NewBadRequestError("invalid pattern: " + err.Error())
+		}
+	}
+
+	rule := &types.BotRule{
+		OrganizationID: organizationID,
+		Username:       username,
+		Pattern:        pattern,
+		Description:    strings.TrimSpace(req.Description),
+	}
+	if err := a.db.CreateBotRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	if err := a.MarkBotAccounts(ctx, organizationID); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func trimmedOrNil(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
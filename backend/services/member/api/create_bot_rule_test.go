@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	liberrors "ems.dev/backend/libraries/errors"
+	"ems.dev/backend/services/member/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateBotRule(t *testing.T) {
+	ctx := context.Background()
+	orgID := "org-1"
+
+	tests := []struct {
+		name          string
+		req           types.CreateBotRuleRequest
+		expectedRule  *types.BotRule
+		expectedError error
+	}{
+		{
+			name:         "success - username",
+			req:          types.CreateBotRuleRequest{Username: stringPtr(" ci-user "), Description: "CI deploys"},
+			expectedRule: &types.BotRule{OrganizationID: orgID, Username: stringPtr("ci-user"), Description: "CI deploys"},
+		},
+		{
+			name:         "success - pattern",
+			req:          types.CreateBotRuleRequest{Pattern: stringPtr("^svc-"), Username: stringPtr("")},
+			expectedRule: &types.BotRule{OrganizationID: orgID, Pattern: stringPtr("^svc-")},
+		},
+		{
+			name:          "error - neither username nor pattern",
+			req:           types.CreateBotRuleRequest{Description: "nothing"},
+			expectedError: liberrors.NewBadRequestError("either a username or a pattern is required"),
+		},
+		{
+			name:          "error - both username and pattern",
+			req:           types.CreateBotRuleRequest{Username: stringPtr("ci-user"), Pattern: stringPtr("^svc-")},
+			expectedError: liberrors.NewBadRequestError("either a username or a pattern is required"),
+		},
+		{
+			name:          "error - invalid pattern",
+			req:           types.CreateBotRuleRequest{Pattern: stringPtr("svc-(")},
+			expectedError: liberrors.NewBadRequestError("invalid pattern: error parsing regexp: missing closing ): `svc-(`"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockMemberDB)
+			mockUserAPI := new(MockUserAPI)
+			mockDirectsAPI := new(MockDirectReportsAPI)
+			mockSourceControlAPI := new(MockSourceControlAPI)
+			mockTitleAPI := new(MockTitleAPI)
+
+			api := NewApi(mockDB, mockUserAPI, mockSourceControlAPI, mockTitleAPI, mockDirectsAPI)
+
+			mockDB.On("CreateBotRule", ctx, mock.Anything).Return(nil)
+			mockDB.On("GetBotRules", ctx, orgID).Return([]types.BotRule{}, nil)
+			mockDB.On("GetExternalAccounts", ctx, &types.ExternalAccountParams{OrganizationID: orgID}).Return([]types.ExternalAccount{}, nil)
+
+			rule, err := api.CreateBotRule(ctx, orgID, tt.req)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
+				assert.IsType(t, tt.expectedError, err)
+				assert.Nil(t, rule)
+				mockDB.AssertNotCalled(t, "CreateBotRule", mock.Anything, mock.Anything)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedRule, rule)
+			mockDB.AssertCalled(t, "CreateBotRule", ctx, rule)
+			mockDB.AssertCalled(t, "GetBotRules", ctx, orgID)
+		})
+	}
+}
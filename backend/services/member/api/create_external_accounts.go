@@ -6,7 +6,30 @@ import (
 	"ems.dev/backend/services/member/types"
 )
 
-// CreateExternalAccounts creates multiple external accounts
+// CreateExternalAccounts creates multiple external accounts, flagging the bots among them
 func (a *Api) CreateExternalAccounts(ctx context.Context, accounts []*types.ExternalAccount) error {
+	matchers := make(map[string]*botMatcher)
+	for _, account := range accounts {
+		organizationID := ""
+		if account.OrganizationID != nil {
+			organizationID = *account.OrganizationID
+		}
+
+		matcher, exists := matchers[organizationID]
+		if !exists {
+			var rules []types.BotRule
+			if organizationID != "" {
+				var err error
+				if rules, err = a.db.GetBotRules(ctx, organizationID); err != nil {
+					return err
+				}
+			}
+			matcher = newBotMatcher(rules)
+			matchers[organizationID] = matcher
+		}
+
+		account.IsBot = matcher.isBot(account)
+	}
+
 	return a.db.CreateExternalAccounts(ctx, accounts)
 }
@@ -7,6 +7,7 @@ import (
 
 	"ems.dev/backend/services/member/types"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/datatypes"
 )
 
 func TestCreateExternalAccounts(t *testing.T) {
@@ -16,7 +17,9 @@ func TestCreateExternalAccounts(t *testing.T) {
 	tests := []struct {
 		name          string
 		accounts      []*types.ExternalAccount
+		mockRules     []types.BotRule
 		mockError     error
+		expectedBots  []bool
 		expectedError error
 	}{
 		{
@@ -24,7 +27,22 @@ func TestCreateExternalAccounts(t *testing.T) {
 			accounts: []*types.ExternalAccount{
 				{ID: "account-1", OrganizationID: &orgID},
 			},
-			mockError: nil,
+			mockError:    nil,
+			expectedBots: []bool{false},
+		},
+		{
+			name: "success - flags provider bots and registered bots",
+			accounts: []*types.ExternalAccount{
+				{ID: "account-1", OrganizationID: &orgID, Username: "dependabot[bot]", Metadata: datatypes.JSON(`{"type": "Bot"}`)},
+				{ID: "account-2", OrganizationID: &orgID, Username: "Deploy-User"},
+				{ID: "account-3", OrganizationID: &orgID, Username: "svc-release"},
+				{ID: "account-4", OrganizationID: &orgID, Username: "octocat", Metadata: datatypes.JSON(`{"type": "User"}`)},
+			},
+			mockRules: []types.BotRule{
+				{ID: "rule-1", OrganizationID: orgID, Username: stringPtr("deploy-user")},
+				{ID: "rule-2", OrganizationID: orgID, Pattern: stringPtr("^svc-")},
+			},
+			expectedBots: []bool{true, true, true, false},
 		},
 		{
 			name: "database error",
@@ -46,6 +64,7 @@ func TestCreateExternalAccounts(t *testing.T) {
 
 			api := NewApi(mockDB, mockUserAPI, mockSourceControlAPI, mockTitleAPI, mockDirectsAPI)
 
+			mockDB.On("GetBotRules", ctx, orgID).Return(tt.mockRules, nil)
 			mockDB.On("CreateExternalAccounts", ctx, tt.accounts).Return(tt.mockError)
 
 			err := api.CreateExternalAccounts(ctx, tt.accounts)
@@ -55,9 +74,13 @@ func TestCreateExternalAccounts(t *testing.T) {
 				assert.Equal(t, tt.expectedError.Error(), err.Error())
 			} else {
 				assert.NoError(t, err)
+				for i, account := range tt.accounts {
+					assert.Equal(t, tt.expectedBots[i], account.IsBot, account.Username)
+				}
 			}
 
 			mockDB.AssertExpectations(t)
+			mockDB.AssertNumberOfCalls(t, "GetBotRules", 1)
 		})
 	}
 }
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"context"
+
+	"ems.dev/backend/libraries/errors"
+)
+
+// DeleteBotRule deletes a bot rule of an organization, and unmarks the accounts which no longer match a rule
+func (a *Api) DeleteBotRule(ctx context.Context, organizationID string, ruleID string) error {
+	rule, err := a.db.GetBotRule(ctx, ruleID)
+	if err != nil {
+		return err
+	}
+	if rule == nil || rule.OrganizationID != organizationID {
+		return errors.NewNotFoundError("bot rule not found")
+	}
+
+	if err := a.db.DeleteBotRule(ctx, ruleID); err != nil {
+		return err
+	}
+
+	return a.MarkBotAccounts(ctx, organizationID)
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	liberrors "ems.dev/backend/libraries/errors"
+	"ems.dev/backend/services/member/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeleteBotRule(t *testing.T) {
+	ctx := context.Background()
+	orgID := "org-1"
+	ruleID := "rule-1"
+
+	tests := []struct {
+		name          string
+		mockRule      *types.BotRule
+		mockGetError  error
+		expectedError error
+	}{
+		{
+			name:     "success",
+			mockRule: &types.BotRule{ID: ruleID, OrganizationID: orgID, Username: stringPtr("ci-user")},
+		},
+		{
+			name:          "error - rule not found",
+			expectedError: liberrors.NewNotFoundError("bot rule not found"),
+		},
+		{
+			name:          "error - rule of another organization",
+			mockRule:      &types.BotRule{ID: ruleID, OrganizationID: "org-2", Username: stringPtr("ci-user")},
+			expectedError: liberrors.NewNotFoundError("bot rule not found"),
+		},
+		{
+			name:          "error - database error",
+			mockGetError:  errors.New("database error"),
+			expectedError: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockMemberDB)
+			mockUserAPI := new(MockUserAPI)
+			mockDirectsAPI := new(MockDirectReportsAPI)
+			mockSourceControlAPI := new(MockSourceControlAPI)
+			mockTitleAPI := new(MockTitleAPI)
+
+			api := NewApi(mockDB, mockUserAPI, mockSourceControlAPI, mockTitleAPI, mockDirectsAPI)
+
+			mockDB.On("GetBotRule", ctx, ruleID).Return(tt.mockRule, tt.mockGetError)
+			mockDB.On("DeleteBotRule", ctx, ruleID).Return(nil)
+			mockDB.On("GetBotRules", ctx, orgID).Return([]types.BotRule{}, nil)
+			mockDB.On("GetExternalAccounts", ctx, &types.ExternalAccountParams{OrganizationID: orgID}).Return([]types.ExternalAccount{}, nil)
+
+			err := api.DeleteBotRule(ctx, orgID, ruleID)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
+				mockDB.AssertNotCalled(t, "DeleteBotRule", mock.Anything, mock.Anything)
+				return
+			}
+
+			assert.NoError(t, err)
+			mockDB.AssertCalled(t, "DeleteBotRule", ctx, ruleID)
+			mockDB.AssertCalled(t, "GetBotRules", ctx, orgID)
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package api
+
+import (
+	"context"
+
+	"ems.dev/backend/services/member/types"
+)
+
+// GetBotRules retrieves the bot rules of an organization
+func (a *Api) GetBotRules(ctx context.Context, organizationID string) ([]types.BotRule, error) {
+	return a.db.GetBotRules(ctx, organizationID)
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ems.dev/backend/services/member/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBotRules(t *testing.T) {
+	ctx := context.Background()
+	orgID := "org-1"
+
+	tests := []struct {
+		name          string
+		mockRules     []types.BotRule
+		mockError     error
+		expectedRules []types.BotRule
+		expectedError error
+	}{
+		{
+			name: "success",
+			mockRules: []types.BotRule{
+				{ID: "rule-1", OrganizationID: orgID, Username: stringPtr("ci-user")},
+			},
+			expectedRules: []types.BotRule{
+				{ID: "rule-1", OrganizationID: orgID, Username: stringPtr("ci-user")},
+			},
+		},
+		{
+			name:          "database error",
+			mockError:     errors.New("database error"),
+			expectedError: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockMemberDB)
+			mockUserAPI := new(MockUserAPI)
+			mockDirectsAPI := new(MockDirectReportsAPI)
+			mockSourceControlAPI := new(MockSourceControlAPI)
+			mockTitleAPI := new(MockTitleAPI)
+
+			api := NewApi(mockDB, mockUserAPI, mockSourceControlAPI, mockTitleAPI, mockDirectsAPI)
+
+			mockDB.On("GetBotRules", ctx, orgID).Return(tt.mockRules, tt.mockError)
+
+			rules, err := api.GetBotRules(ctx, orgID)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
+				assert.Nil(t, rules)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedRules, rules)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"ems.dev/backend/services/member/types"
+)
+
+// botMatcher tells bot accounts apart, from the user type reported by the provider and the bot rules of an organization
+type botMatcher struct {
+	usernames map[string]bool
+	patterns  []*regexp.Regexp
+}
+
+// newBotMatcher builds a botMatcher from bot rules, patterns are validated when the rules are created
+func newBotMatcher(rules []types.BotRule) *botMatcher {
+	matcher := &botMatcher{usernames: make(map[string]bool)}
+	for _, rule := range rules {
+		if rule.Username != nil {
+			matcher.usernames[strings.ToLower(*rule.Username)] = true
+		}
+		if rule.Pattern != nil {
+			if pattern, err := regexp.Compile(*rule.Pattern); err == nil {
+				matcher.patterns = append(matcher.patterns, pattern)
+			}
+		}
+	}
+	return matcher
+}
+
+// isBot reports whether an account is a bot. Providers store their user in the account metadata, with a "type"
+// of "Bot" for bots. Usernames are compared case insensitively, patterns match anywhere in the username unless anchored.
+func (m *botMatcher) isBot(account *types.ExternalAccount) bool {
+	var metadata struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(account.Metadata, &metadata); err == nil && metadata.Type == "Bot" {
+		return true
+	}
+
+	if m.usernames[strings.ToLower(account.Username)] {
+		return true
+	}
+	for _, pattern := range m.patterns {
+		if pattern.MatchString(account.Username) {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkBotAccounts updates the bot flag of the external accounts of an organization from its bot rules
+func (a *Api) MarkBotAccounts(ctx context.Context, organizationID string) error {
+	rules, err := a.db.GetBotRules(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+
+	accounts, err := a.db.GetExternalAccounts(ctx, &types.ExternalAccountParams{
+		OrganizationID: organizationID,
+	})
+	if err != nil {
+		return err
+	}
+
+	matcher := newBotMatcher(rules)
+	var marked, unmarked []string
+	for i := range accounts {
+		isBot := matcher.isBot(&accounts[i])
+		if isBot == accounts[i].IsBot {
+			continue
+		}
+		if isBot {
+			marked = append(marked, accounts[i].ID)
+		} else {
+			unmarked = append(unmarked, accounts[i].ID)
+		}
+	}
+
+	if len(marked) > 0 {
+		if err := a.db.SetExternalAccountsBot(ctx, marked, true); err != nil {
+			return err
+		}
+	}
+	if len(unmarked) > 0 {
+		if err := a.db.SetExternalAccountsBot(ctx, unmarked, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
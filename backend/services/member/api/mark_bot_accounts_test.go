@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ems.dev/backend/services/member/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/datatypes"
+)
+
+func TestMarkBotAccounts(t *testing.T) {
+	ctx := context.Background()
+	orgID := "org-1"
+
+	tests := []struct {
+		name             string
+		mockRules        []types.BotRule
+		mockAccounts     []types.ExternalAccount
+		mockGetError     error
+		expectedMarked   []string
+		expectedUnmarked []string
+		expectedSetCalls int
+		expectedError    error
+	}{
+		{
+			name: "success - marks and unmarks the changed accounts",
+			mockRules: []types.BotRule{
+				{ID: "rule-1", OrganizationID: orgID, Username: stringPtr("ci-user")},
+				{ID: "rule-2", OrganizationID: orgID, Pattern: stringPtr(`(?i)-automation$`)},
+			},
+			mockAccounts: []types.ExternalAccount{
+				{ID: "account-1", Username: "CI-User"},
+				{ID: "account-2", Username: "Release-Automation"},
+				{ID: "account-3", Username: "former-bot", IsBot: true},
+				{ID: "account-4", Username: "renovate[bot]", Metadata: datatypes.JSON(`{"type": "Bot"}`), IsBot: true},
+				{ID: "account-5", Username: "octocat"},
+			},
+			expectedMarked:   []string{"account-1", "account-2"},
+			expectedUnmarked: []string{"account-3"},
+			expectedSetCalls: 2,
+		},
+		{
+			name: "success - nothing changed",
+			mockAccounts: []types.ExternalAccount{
+				{ID: "account-1", Username: "octocat"},
+			},
+		},
+		{
+			name:          "error - database error",
+			mockGetError:  errors.New("database error"),
+			expectedError: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(MockMemberDB)
+			mockUserAPI := new(MockUserAPI)
+			mockDirectsAPI := new(MockDirectReportsAPI)
+			mockSourceControlAPI := new(MockSourceControlAPI)
+			mockTitleAPI := new(MockTitleAPI)
+
+			api := NewApi(mockDB, mockUserAPI, mockSourceControlAPI, mockTitleAPI, mockDirectsAPI)
+
+			mockDB.On("GetBotRules", ctx, orgID).Return(tt.mockRules, tt.mockGetError)
+			mockDB.On("GetExternalAccounts", ctx, &types.ExternalAccountParams{OrganizationID: orgID}).Return(tt.mockAccounts, nil)
+			mockDB.On("SetExternalAccountsBot", ctx, mock.Anything, mock.Anything).Return(nil)
+
+			err := api.MarkBotAccounts(ctx, orgID)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
+				mockDB.AssertNotCalled(t, "SetExternalAccountsBot", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			assert.NoError(t, err)
+			if len(tt.expectedMarked) > 0 {
+				mockDB.AssertCalled(t, "SetExternalAccountsBot", ctx, tt.expectedMarked, true)
+			}
+			if len(tt.expectedUnmarked) > 0 {
+				mockDB.AssertCalled(t, "SetExternalAccountsBot", ctx, tt.expectedUnmarked, false)
+			}
+			mockDB.AssertNumberOfCalls(t, "SetExternalAccountsBot", tt.expectedSetCalls)
+		})
+	}
+}
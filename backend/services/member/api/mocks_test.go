@@ -86,6 +86,37 @@ func (m *MockMemberDB) UpdateExternalAccount(ctx context.Context, account *types
 	return args.Error(0)
 }
 
+func (m *MockMemberDB) SetExternalAccountsBot(ctx context.Context, accountIDs []string, isBot bool) error {
+	args := m.Called(ctx, accountIDs, isBot)
+	return args.Error(0)
+}
+
+func (m *MockMemberDB) GetBotRules(ctx context.Context, organizationID string) ([]types.BotRule, error) {
+	args := m.Called(ctx, organizationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.BotRule), args.Error(1)
+}
+
+func (m *MockMemberDB) GetBotRule(ctx context.Context, id string) (*types.BotRule, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.BotRule), args.Error(1)
+}
+
+func (m *MockMemberDB) CreateBotRule(ctx context.Context, rule *types.BotRule) error {
+	args := m.Called(ctx, rule)
+	return args.Error(0)
+}
+
+func (m *MockMemberDB) DeleteBotRule(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 // MockUserAPI is a mock implementation of the UserAPI interface
 type MockUserAPI struct {
 	mock.Mock
@@ -233,6 +264,11 @@ func (m *MockSourceControlAPI) CreatePRComments(ctx context.Context, comments []
 	return args.Error(0)
 }
 
+func (m *MockSourceControlAPI) UpdatePRComment(ctx context.Context, comment *sourcecontroltypes.PRComment) error {
+	args := m.Called(ctx, comment)
+	return args.Error(0)
+}
+
 func (m *MockSourceControlAPI) GetPullRequestComments(ctx context.Context, prID string) ([]*sourcecontroltypes.PRComment, error) {
 	args := m.Called(ctx, prID)
 	if args.Get(0) == nil {
@@ -241,6 +277,152 @@ func (m *MockSourceControlAPI) GetPullRequestComments(ctx context.Context, prID
 	return args.Get(0).([]*sourcecontroltypes.PRComment), args.Error(1)
 }
 
+func (m *MockSourceControlAPI) CreatePRCommits(ctx context.Context, commits []*sourcecontroltypes.PRCommit) error {
+	args := m.Called(ctx, commits)
+	return args.Error(0)
+}
+
+func (m *MockSourceControlAPI) GetPullRequestCommits(ctx context.Context, prID string) ([]*sourcecontroltypes.PRCommit, error) {
+	args := m.Called(ctx, prID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*sourcecontroltypes.PRCommit), args.Error(1)
+}
+
+func (m *MockSourceControlAPI) UpsertRepository(ctx context.Context, repository *sourcecontroltypes.Repository) error {
+	args := m.Called(ctx, repository)
+	return args.Error(0)
+}
+
+func (m *MockSourceControlAPI) GetRepositories(ctx context.Context, params *sourcecontroltypes.RepositoryParams) ([]*sourcecontroltypes.Repository, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*sourcecontroltypes.Repository), args.Error(1)
+}
+
+func (m *MockSourceControlAPI) GetRepository(ctx context.Context, organizationID, repositoryID string) (*sourcecontroltypes.Repository, error) {
+	args := m.Called(ctx, organizationID, repositoryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sourcecontroltypes.Repository), args.Error(1)
+}
+
+func (m *MockSourceControlAPI) UpdateRepositoryTeam(ctx context.Context, organizationID, repositoryID string, teamID *string) (*sourcecontroltypes.Repository, error) {
+	args := m.Called(ctx, organizationID, repositoryID, teamID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sourcecontroltypes.Repository), args.Error(1)
+}
+
+func (m *MockSourceControlAPI) ReplacePRFiles(ctx context.Context, prID string, files []*sourcecontroltypes.PRFile) error {
+	args := m.Called(ctx, prID, files)
+	return args.Error(0)
+}
+
+func (m *MockSourceControlAPI) GetPullRequestFiles(ctx context.Context, prID string) ([]*sourcecontroltypes.PRFile, error) {
+	args := m.Called(ctx, prID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*sourcecontroltypes.PRFile), args.Error(1)
+}
+
+func (m *MockSourceControlAPI) RefreshPullRequestMetrics(ctx context.Context, pr *sourcecontroltypes.PullRequest) error {
+	args := m.Called(ctx, pr)
+	return args.Error(0)
+}
+
+func (m *MockSourceControlAPI) RecomputePullRequestMetrics(ctx context.Context, params *sourcecontroltypes.RecomputeMetricsParams) (*sourcecontroltypes.RecomputeMetricsResult, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sourcecontroltypes.RecomputeMetricsResult), args.Error(1)
+}
+
+func (m *MockSourceControlAPI) MarkPullRequestMetricsOutdated(ctx context.Context, params *sourcecontroltypes.RecomputeMetricsParams) (*sourcecontroltypes.RecomputeMetricsResult, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sourcecontroltypes.RecomputeMetricsResult), args.Error(1)
+}
+
+func (m *MockSourceControlAPI) GetPullRequestAttributions(ctx context.Context, params *sourcecontroltypes.PullRequestAttributionParams) ([]*sourcecontroltypes.PullRequestAttribution, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*sourcecontroltypes.PullRequestAttribution), args.Error(1)
+}
+
+func (m *MockSourceControlAPI) UpdatePullRequestTeams(ctx context.Context, teams []*sourcecontroltypes.PullRequestTeam) error {
+	args := m.Called(ctx, teams)
+	return args.Error(0)
+}
+
+func (m *MockSourceControlAPI) GetFileHotspots(ctx context.Context, params *sourcecontroltypes.FileHotspotParams) ([]*sourcecontroltypes.RepositoryHotspots, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*sourcecontroltypes.RepositoryHotspots), args.Error(1)
+}
+
+func (m *MockSourceControlAPI) ReplacePRCodeOwners(ctx context.Context, prID string, owners []*sourcecontroltypes.PRCodeOwner) error {
+	args := m.Called(ctx, prID, owners)
+	return args.Error(0)
+}
+
+func (m *MockSourceControlAPI) GetPullRequestCodeOwners(ctx context.Context, prID string) ([]*sourcecontroltypes.PRCodeOwner, error) {
+	args := m.Called(ctx, prID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*sourcecontroltypes.PRCodeOwner), args.Error(1)
+}
+
+func (m *MockSourceControlAPI) CreatePullRequestEvents(ctx context.Context, events []*sourcecontroltypes.PullRequestEvent) error {
+	args := m.Called(ctx, events)
+	return args.Error(0)
+}
+
+func (m *MockSourceControlAPI) GetPullRequestEvents(ctx context.Context, prID string) ([]*sourcecontroltypes.PullRequestEvent, error) {
+	args := m.Called(ctx, prID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*sourcecontroltypes.PullRequestEvent), args.Error(1)
+}
+
+func (m *MockSourceControlAPI) UpsertDeployment(ctx context.Context, deployment *sourcecontroltypes.Deployment) error {
+	args := m.Called(ctx, deployment)
+	return args.Error(0)
+}
+
+func (m *MockSourceControlAPI) CreateDeploymentStatuses(ctx context.Context, statuses []*sourcecontroltypes.DeploymentStatus) error {
+	args := m.Called(ctx, statuses)
+	return args.Error(0)
+}
+
+func (m *MockSourceControlAPI) UpsertCIRuns(ctx context.Context, runs []*sourcecontroltypes.CIRun) error {
+	args := m.Called(ctx, runs)
+	return args.Error(0)
+}
+
+func (m *MockSourceControlAPI) GetPullRequestCIRuns(ctx context.Context, prID string) ([]*sourcecontroltypes.CIRun, error) {
+	args := m.Called(ctx, prID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*sourcecontroltypes.CIRun), args.Error(1)
+}
+
 func (m *MockSourceControlAPI) GetMemberPullRequests(ctx context.Context, params *sourcecontroltypes.MemberPullRequestParams) ([]*sourcecontroltypes.PullRequestWithComments, error) {
 	args := m.Called(ctx, params)
 	if args.Get(0) == nil {
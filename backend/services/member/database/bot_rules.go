@@ -0,0 +1,50 @@
+package database
+
+import (
+	"context"
+
+	"ems.dev/backend/services/member/types"
+	"gorm.io/gorm"
+)
+
+// GetBotRules retrieves the bot rules of an organization, oldest first
+func (d *MemberDB) GetBotRules(ctx context.Context, organizationID string) ([]types.BotRule, error) {
+	var rules []types.BotRule
+	if err := d.db.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Order("created_at ASC").
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetBotRule retrieves a bot rule by ID
+func (d *MemberDB) GetBotRule(ctx context.Context, id string) (*types.BotRule, error) {
+	var rule types.BotRule
+	if err := d.db.WithContext(ctx).Where("id = ?", id).First(&rule).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// CreateBotRule creates a bot rule
+func (d *MemberDB) CreateBotRule(ctx context.Context, rule *types.BotRule) error {
+	return d.db.WithContext(ctx).Create(rule).Error
+}
+
+// DeleteBotRule deletes a bot rule by ID
+func (d *MemberDB) DeleteBotRule(ctx context.Context, id string) error {
+	return d.db.WithContext(ctx).Where("id = ?", id).Delete(&types.BotRule{}).Error
+}
+
+// SetExternalAccountsBot sets the bot flag of the given external accounts
+func (d *MemberDB) SetExternalAccountsBot(ctx context.Context, accountIDs []string, isBot bool) error {
+	return d.db.WithContext(ctx).
+		Model(&types.ExternalAccount{}).
+		Where("id IN ?", accountIDs).
+		Update("is_bot", isBot).Error
+}
@@ -22,6 +22,13 @@ type DB interface {
 	CreateExternalAccounts(ctx context.Context, accounts []*types.ExternalAccount) error
 	GetExternalAccount(ctx context.Context, id string) (*types.ExternalAccount, error)
 	UpdateExternalAccount(ctx context.Context, account *types.ExternalAccount) error
+	SetExternalAccountsBot(ctx context.Context, accountIDs []string, isBot bool) error
+
+	// Bot Rules
+	GetBotRules(ctx context.Context, organizationID string) ([]types.BotRule, error)
+	GetBotRule(ctx context.Context, id string) (*types.BotRule, error)
+	CreateBotRule(ctx context.Context, rule *types.BotRule) error
+	DeleteBotRule(ctx context.Context, id string) error
 }
 
 type MemberDB struct {
@@ -0,0 +1,26 @@
+package types
+
+import "time"
+
+// BotRule registers bot or service accounts of an organization, either by an exact username or by a regular
+// expression matched against usernames. External accounts matching a rule are left out of the metrics.
+type BotRule struct {
+	ID             string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	OrganizationID string    `gorm:"type:uuid;not null" json:"organization_id"`
+	Username       *string   `gorm:"type:varchar(255)" json:"username,omitempty"`
+	Pattern        *string   `gorm:"type:varchar(255)" json:"pattern,omitempty"`
+	Description    string    `gorm:"type:text" json:"description,omitempty"`
+	CreatedAt      time.Time `gorm:"type:timestamp with time zone;default:current_timestamp" json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (BotRule) TableName() string {
+	return "bot_rules"
+}
+
+// CreateBotRuleRequest represents the request to register a bot account, exactly one of username and pattern is set
+type CreateBotRuleRequest struct {
+	Username    *string `json:"username,omitempty"`
+	Pattern     *string `json:"pattern,omitempty"`
+	Description string  `json:"description,omitempty"`
+}
@@ -27,6 +27,7 @@ type ExternalAccount struct {
 	ProviderID     string         `gorm:"type:varchar(255)" json:"provider_id"`
 	Username       string         `gorm:"type:varchar(255)" json:"username"`
 	Metadata       datatypes.JSON `gorm:"type:jsonb" json:"metadata,omitempty"`
+	IsBot          bool           `gorm:"not null;default:false" json:"is_bot"` // Set from the provider user type and the organization bot rules
 	LastSyncedAt   *time.Time     `gorm:"type:timestamp with time zone" json:"last_synced_at,omitempty"`
 	CreatedAt      time.Time      `gorm:"type:timestamp with time zone;default:current_timestamp" json:"created_at"`
 	UpdatedAt      time.Time      `gorm:"type:timestamp with time zone;default:current_timestamp" json:"updated_at"`
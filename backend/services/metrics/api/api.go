@@ -8,6 +8,7 @@ import (
 	aicodeassistantapi "ems.dev/backend/services/aicodeassistant/api"
 	aicodeassistanttypes "ems.dev/backend/services/aicodeassistant/types"
 	sourcecontrolapi "ems.dev/backend/services/sourcecontrol/api"
+	sourcecontroltypes "ems.dev/backend/services/sourcecontrol/types"
 	teamapi "ems.dev/backend/services/team/api"
 )
 
@@ -16,6 +17,8 @@ type MetricsAPI interface {
 	// CalculateOrganizationSourceControlMetrics calculates aggregated source control metrics for an organization
 	// Returns cumulative metrics and optionally a breakdown by team
 	CalculateOrganizationSourceControlMetrics(ctx context.Context, params types.OrganizationMetricsParams) (*types.OrganizationMetricsResponse, error)
+	// CalculateRepositorySourceControlMetrics calculates source control metrics for the pull requests of a repository
+	CalculateRepositorySourceControlMetrics(ctx context.Context, repositoryID string, params types.OrganizationMetricsParams) (*sourcecontroltypes.MetricsResponse, error)
 	// CalculateOrganizationAICodeAssistantMetrics calculates aggregated AI code assistant metrics for an organization
 	CalculateOrganizationAICodeAssistantMetrics(ctx context.Context, params types.OrganizationMetricsParams) (*aicodeassistanttypes.MetricsResponse, error)
 	// CalculateTeamAICodeAssistantMetrics calculates AI code assistant metrics for a specific team
@@ -54,29 +54,18 @@ func (a *Api) CalculateOrganizationSourceControlMetrics(ctx context.Context, par
 		// Calculate per-team breakdown for the specified teams
 		teamsBreakdown = make([]types.TeamMetricsBreakdown, 0, len(filteredTeams))
 		for _, team := range filteredTeams {
-			// Always use team prefix for filtering
-			if team.PRPrefix != nil && *team.PRPrefix != "" {
-				// Calculate metrics for this team using prefix
-				teamMetrics, err := a.calculateMetricsForPrefix(ctx, params, *team.PRPrefix)
-				if err != nil {
-					return nil, err
-				}
-
-				teamsBreakdown = append(teamsBreakdown, types.TeamMetricsBreakdown{
-					TeamID:          team.ID,
-					TeamName:        team.Name,
-					SnapshotMetrics: teamMetrics.SnapshotMetrics,
-					GraphMetrics:    teamMetrics.GraphMetrics,
-				})
-			} else {
-				// Team has no prefix, add empty metrics
-				teamsBreakdown = append(teamsBreakdown, types.TeamMetricsBreakdown{
-					TeamID:          team.ID,
-					TeamName:        team.Name,
-					SnapshotMetrics: []*sourcecontroltypes.SnapshotCategory{},
-					GraphMetrics:    []*sourcecontroltypes.GraphCategory{},
-				})
+			// Calculate metrics for the pull requests attributed to this team
+			teamMetrics, err := a.calculateMetricsForTeam(ctx, params, team.ID)
+			if err != nil {
+				return nil, err
 			}
+
+			teamsBreakdown = append(teamsBreakdown, types.TeamMetricsBreakdown{
+				TeamID:          team.ID,
+				TeamName:        team.Name,
+				SnapshotMetrics: teamMetrics.SnapshotMetrics,
+				GraphMetrics:    teamMetrics.GraphMetrics,
+			})
 		}
 	}
 
@@ -90,7 +79,7 @@ func (a *Api) CalculateOrganizationSourceControlMetrics(ctx context.Context, par
 
 // calculateMetricsForOrganization calculates metrics for the entire organization without filtering by members
 func (a *Api) calculateMetricsForOrganization(ctx context.Context, params types.OrganizationMetricsParams) (*sourcecontroltypes.MetricsResponse, error) {
-	// Create the metric params with only organization ID (no sourceControlAccountIDs or pr_prefixes)
+	// Create the metric params with only organization ID (no sourceControlAccountIDs or team_ids)
 	// This will make the metrics engine calculate metrics for all PRs in the organization
 	metricParamsMap := map[string]interface{}{
 		"organizationId": params.OrganizationID,
@@ -117,12 +106,47 @@ func (a *Api) calculateMetricsForOrganization(ctx context.Context, params types.
 	return a.sourceControlApi.CalculateMetrics(ctx, metricParams)
 }
 
-// calculateMetricsForPrefix is a helper function that calculates metrics for a team prefix
-func (a *Api) calculateMetricsForPrefix(ctx context.Context, params types.OrganizationMetricsParams, prefix string) (*sourcecontroltypes.MetricsResponse, error) {
-	// Create the metric params with the pr_prefixes
+// calculateMetricsForTeam is a helper function that calculates metrics for the pull requests attributed to a team
+func (a *Api) calculateMetricsForTeam(ctx context.Context, params types.OrganizationMetricsParams, teamID string) (*sourcecontroltypes.MetricsResponse, error) {
+	// Create the metric params with the team_ids
+	metricParamsMap := map[string]interface{}{
+		"organizationId": params.OrganizationID,
+		"team_ids":       []string{teamID},
+	}
+
+	// Marshal to JSON bytes
+	metricParamsJSON, err := json.Marshal(metricParamsMap)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := params.Interval
+	if interval == "" {
+		interval = "monthly" // default
+	}
+
+	metricParams := sourcecontroltypes.MetricRuleParams{
+		MetricParams: datatypes.JSON(metricParamsJSON),
+		StartDate:    params.StartDate,
+		EndDate:      params.EndDate,
+		Interval:     interval,
+	}
+
+	return a.sourceControlApi.CalculateMetrics(ctx, metricParams)
+}
+
+// CalculateRepositorySourceControlMetrics calculates source control metrics for the pull requests of a repository
+// of the organization
+func (a *Api) CalculateRepositorySourceControlMetrics(ctx context.Context, repositoryID string, params types.OrganizationMetricsParams) (*sourcecontroltypes.MetricsResponse, error) {
+	// Make sure the repository belongs to the organization
+	if _, err := a.sourceControlApi.GetRepository(ctx, params.OrganizationID, repositoryID); err != nil {
+		return nil, err
+	}
+
+	// Create the metric params with the repository_ids
 	metricParamsMap := map[string]interface{}{
 		"organizationId": params.OrganizationID,
-		"pr_prefixes":    []string{prefix},
+		"repository_ids": []string{repositoryID},
 	}
 
 	// Marshal to JSON bytes
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+
+	"ems.dev/backend/services/sourcecontrol/database"
+	"ems.dev/backend/services/sourcecontrol/types"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/datatypes"
+)
+
+// MockSourceControlDB is a mock of the source control database, only the methods used by the tested API calls
+// are implemented
+type MockSourceControlDB struct {
+	database.DB
+	mock.Mock
+}
+
+func (m *MockSourceControlDB) GetFileHotspots(ctx context.Context, params *types.FileHotspotParams) ([]*types.FileHotspot, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*types.FileHotspot), args.Error(1)
+}
+
+func (m *MockSourceControlDB) GetPullRequestsForMetrics(ctx context.Context, params *types.RecomputeMetricsParams) ([]*types.PullRequest, error) {
+	// Params are copied, the caller reuses them for the next page
+	page := *params
+	args := m.Called(ctx, &page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*types.PullRequest), args.Error(1)
+}
+
+func (m *MockSourceControlDB) MarkPullRequestMetricsOutdated(ctx context.Context, params *types.RecomputeMetricsParams) (int, error) {
+	args := m.Called(ctx, params)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockSourceControlDB) GetPullRequestNonBotComments(ctx context.Context, prID string) ([]*types.PRComment, error) {
+	args := m.Called(ctx, prID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*types.PRComment), args.Error(1)
+}
+
+func (m *MockSourceControlDB) GetPullRequestEvents(ctx context.Context, prID string) ([]*types.PullRequestEvent, error) {
+	args := m.Called(ctx, prID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*types.PullRequestEvent), args.Error(1)
+}
+
+func (m *MockSourceControlDB) GetPullRequestCommits(ctx context.Context, prID string) ([]*types.PRCommit, error) {
+	args := m.Called(ctx, prID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*types.PRCommit), args.Error(1)
+}
+
+func (m *MockSourceControlDB) UpdatePullRequestMetrics(ctx context.Context, prID string, metrics datatypes.JSON, version int) error {
+	args := m.Called(ctx, prID, metrics, version)
+	return args.Error(0)
+}
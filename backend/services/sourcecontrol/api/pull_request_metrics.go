@@ -0,0 +1,189 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	liberrors "ems.dev/backend/libraries/errors"
+	"ems.dev/backend/services/sourcecontrol/types"
+	"gorm.io/datatypes"
+)
+
+// recomputeBatchSize is the number of pull requests loaded at once when recomputing metrics
+const recomputeBatchSize = 200
+
+// RefreshPullRequestMetrics calculates the metrics of a pull request from its stored comments, reviews, timeline
+// events and commits, and stores them with the current PullRequestMetricsVersion. Comments and reviews of accounts
+// flagged as bots, by their provider or by the organization bot rules, are left out.
+func (a *Api) RefreshPullRequestMetrics(ctx context.Context, pr *types.PullRequest) error {
+	comments, err := a.db.GetPullRequestNonBotComments(ctx, pr.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch comments for PR %s: %w", pr.ID, err)
+	}
+
+	events, err := a.db.GetPullRequestEvents(ctx, pr.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch timeline events for PR %s: %w", pr.ID, err)
+	}
+
+	commits, err := a.db.GetPullRequestCommits(ctx, pr.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch commits for PR %s: %w", pr.ID, err)
+	}
+
+	metricsBytes, _ := json.Marshal(calculatePullRequestMetrics(pr, comments, events, commits))
+	if err := a.db.UpdatePullRequestMetrics(ctx, pr.ID, datatypes.JSON(metricsBytes), types.PullRequestMetricsVersion); err != nil {
+		return fmt.Errorf("failed to save metrics for PR %s: %w", pr.ID, err)
+	}
+
+	pr.Metrics = datatypes.JSON(metricsBytes)
+	pr.MetricsVersion = types.PullRequestMetricsVersion
+	return nil
+}
+
+// RecomputePullRequestMetrics rebuilds the stored metrics of the selected pull requests, without calling the
+// source control providers
+func (a *Api) RecomputePullRequestMetrics(ctx context.Context, params *types.RecomputeMetricsParams) (*types.RecomputeMetricsResult, error) {
+	if err := validateRecomputeMetricsParams(params); err != nil {
+		return nil, err
+	}
+
+	result := &types.RecomputeMetricsResult{}
+	page := *params
+	for params.Limit == 0 || result.PullRequests < params.Limit {
+		page.Limit = recomputeBatchSize
+		if params.Limit > 0 && params.Limit-result.PullRequests < page.Limit {
+			page.Limit = params.Limit - result.PullRequests
+		}
+
+		prs, err := a.db.GetPullRequestsForMetrics(ctx, &page)
+		if err != nil {
+			return nil, err
+		}
+		if len(prs) == 0 {
+			break
+		}
+
+		for _, pr := range prs {
+			if err := a.RefreshPullRequestMetrics(ctx, pr); err != nil {
+				return nil, err
+			}
+			result.PullRequests++
+		}
+		page.AfterID = prs[len(prs)-1].ID
+	}
+
+	return result, nil
+}
+
+// MarkPullRequestMetricsOutdated marks the metrics of the selected pull requests as stored with an older version of
+// the calculation, so that the recompute job rebuilds them
+func (a *Api) MarkPullRequestMetricsOutdated(ctx context.Context, params *types.RecomputeMetricsParams) (*types.RecomputeMetricsResult, error) {
+	if err := validateRecomputeMetricsParams(params); err != nil {
+		return nil, err
+	}
+
+	marked, err := a.db.MarkPullRequestMetricsOutdated(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark pull request metrics as outdated: %w", err)
+	}
+
+	return &types.RecomputeMetricsResult{PullRequests: marked}, nil
+}
+
+func validateRecomputeMetricsParams(params *types.RecomputeMetricsParams) error {
+	if params.StartDate != nil && params.EndDate != nil && params.StartDate.After(*params.EndDate) {
+		return liberrors.NewBadRequestError("start date must be before end date")
+	}
+	return nil
+}
+
+// calculatePullRequestMetrics calculates the metrics of a pull request from its non-bot comments and reviews, its
+// timeline events and its commits
+func calculatePullRequestMetrics(pr *types.PullRequest, comments []*types.PRComment, events []*types.PullRequestEvent, commits []*types.PRCommit) map[string]interface{} {
+	metrics := make(map[string]interface{})
+
+	metrics["number_of_non_bot_comments"] = len(comments)
+
+	// Calculate time to merge if the PR is merged
+	if pr.MergedAt != nil {
+		metrics["time_to_merge_seconds"] = int64(pr.MergedAt.Sub(pr.CreatedAt).Seconds())
+	}
+
+	// Calculate time to first review, which is the first non-bot review or comment
+	var firstReviewedAt *time.Time
+	for _, comment := range comments {
+		if firstReviewedAt == nil || comment.CreatedAt.Before(*firstReviewedAt) {
+			createdAt := comment.CreatedAt
+			firstReviewedAt = &createdAt
+		}
+	}
+	if firstReviewedAt != nil {
+		metrics["time_to_first_non_bot_review_seconds"] = int64(firstReviewedAt.Sub(pr.CreatedAt).Seconds())
+	}
+
+	addTimelineMetrics(metrics, pr.CreatedAt, firstReviewedAt, events)
+
+	// Commits are only imported by providers which expose them, every PR has at least a commit
+	if len(commits) > 0 {
+		addCommitMetrics(metrics, pr.CreatedAt, firstReviewedAt, commits)
+	}
+
+	return metrics
+}
+
+// addTimelineMetrics adds the metrics calculated from the timeline events of a PR. Drafts aren't waiting for
+// reviews, so the wait for the first review starts when the PR was last marked ready for review before it. PRs of
+// providers which don't expose their timeline have no events, they count as never reopened or drafted.
+func addTimelineMetrics(metrics map[string]interface{}, createdAt time.Time, firstReviewedAt *time.Time, events []*types.PullRequestEvent) {
+	reopened := 0
+	for _, event := range events {
+		if event.Event == types.PullRequestEventReopened {
+			reopened++
+		}
+	}
+	metrics["reopened_count"] = reopened
+
+	if firstReviewedAt == nil {
+		return
+	}
+
+	readyAt := createdAt
+	for _, event := range events {
+		if event.Event == types.PullRequestEventReadyForReview && !event.CreatedAt.After(*firstReviewedAt) && event.CreatedAt.After(readyAt) {
+			readyAt = event.CreatedAt
+		}
+	}
+	metrics["time_from_ready_for_review_to_first_review_seconds"] = int64(firstReviewedAt.Sub(readyAt).Seconds())
+}
+
+// addCommitMetrics adds the metrics calculated from the commits of a PR: the coding time between the first
+// commit and the PR creation, and the number of commits pushed after the first review.
+func addCommitMetrics(metrics map[string]interface{}, createdAt time.Time, firstReviewedAt *time.Time, commits []*types.PRCommit) {
+	metrics["commits_count"] = len(commits)
+	if len(commits) == 0 {
+		return
+	}
+
+	firstAuthoredAt := commits[0].AuthoredAt
+	commitsAfterFirstReview := 0
+	for _, commit := range commits {
+		if commit.AuthoredAt.Before(firstAuthoredAt) {
+			firstAuthoredAt = commit.AuthoredAt
+		}
+		if firstReviewedAt != nil && commit.CommittedAt.After(*firstReviewedAt) {
+			commitsAfterFirstReview++
+		}
+	}
+
+	// Commits can be authored after the PR was opened, e.g. when it was opened from an empty branch
+	if firstAuthoredAt.Before(createdAt) {
+		metrics["coding_time_seconds"] = int64(createdAt.Sub(firstAuthoredAt).Seconds())
+	}
+
+	if firstReviewedAt != nil {
+		metrics["commits_after_first_review"] = commitsAfterFirstReview
+	}
+}
@@ -0,0 +1,321 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ems.dev/backend/services/sourcecontrol/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/datatypes"
+)
+
+// hour returns the time the given number of hours after the creation of the test pull requests
+func hour(h int) time.Time {
+	return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(h) * time.Hour)
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestAddTimelineMetrics(t *testing.T) {
+	event := func(name string, h int) *types.PullRequestEvent {
+		return &types.PullRequestEvent{Event: name, CreatedAt: hour(h)}
+	}
+
+	tests := []struct {
+		name            string
+		firstReviewedAt *time.Time
+		events          []*types.PullRequestEvent
+		expected        map[string]interface{}
+	}{
+		{
+			name:     "no events",
+			expected: map[string]interface{}{"reopened_count": 0},
+		},
+		{
+			name:   "reopened pull requests",
+			events: []*types.PullRequestEvent{event(types.PullRequestEventClosed, 1), event(types.PullRequestEventReopened, 2), event(types.PullRequestEventClosed, 3), event(types.PullRequestEventReopened, 4)},
+			expected: map[string]interface{}{
+				"reopened_count": 2,
+			},
+		},
+		{
+			name:            "ready for review at creation",
+			firstReviewedAt: timePtr(hour(5)),
+			events:          []*types.PullRequestEvent{event(types.PullRequestEventReviewRequested, 1)},
+			expected: map[string]interface{}{
+				"reopened_count": 0,
+				"time_from_ready_for_review_to_first_review_seconds": int64(5 * 3600),
+			},
+		},
+		{
+			name:            "drafts wait from the last ready for review before the first review",
+			firstReviewedAt: timePtr(hour(10)),
+			events: []*types.PullRequestEvent{
+				event(types.PullRequestEventReadyForReview, 2),
+				event(types.PullRequestEventConvertToDraft, 3),
+				event(types.PullRequestEventReadyForReview, 6),
+				event(types.PullRequestEventReadyForReview, 12),
+			},
+			expected: map[string]interface{}{
+				"reopened_count": 0,
+				"time_from_ready_for_review_to_first_review_seconds": int64(4 * 3600),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := map[string]interface{}{}
+			addTimelineMetrics(metrics, hour(0), tt.firstReviewedAt, tt.events)
+			assert.Equal(t, tt.expected, metrics)
+		})
+	}
+}
+
+func TestAddCommitMetrics(t *testing.T) {
+	commit := func(authored, committed int) *types.PRCommit {
+		return &types.PRCommit{AuthoredAt: hour(authored), CommittedAt: hour(committed)}
+	}
+
+	tests := []struct {
+		name            string
+		firstReviewedAt *time.Time
+		commits         []*types.PRCommit
+		expected        map[string]interface{}
+	}{
+		{
+			name:     "no commits",
+			expected: map[string]interface{}{"commits_count": 0},
+		},
+		{
+			name:    "coding time from the first authored commit",
+			commits: []*types.PRCommit{commit(-2, 1), commit(-5, -1), commit(3, 3)},
+			expected: map[string]interface{}{
+				"commits_count":       3,
+				"coding_time_seconds": int64(5 * 3600),
+			},
+		},
+		{
+			name:    "pull requests opened before their first commit have no coding time",
+			commits: []*types.PRCommit{commit(1, 1), commit(2, 2)},
+			expected: map[string]interface{}{
+				"commits_count": 2,
+			},
+		},
+		{
+			name:            "commits committed after the first review",
+			firstReviewedAt: timePtr(hour(4)),
+			commits:         []*types.PRCommit{commit(-1, -1), commit(3, 4), commit(2, 5), commit(6, 6)},
+			expected: map[string]interface{}{
+				"commits_count":              4,
+				"coding_time_seconds":        int64(3600),
+				"commits_after_first_review": 2,
+			},
+		},
+		{
+			name:            "reviewed pull requests without commits after the review",
+			firstReviewedAt: timePtr(hour(4)),
+			commits:         []*types.PRCommit{commit(-1, -1)},
+			expected: map[string]interface{}{
+				"commits_count":              1,
+				"coding_time_seconds":        int64(3600),
+				"commits_after_first_review": 0,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := map[string]interface{}{}
+			addCommitMetrics(metrics, hour(0), tt.firstReviewedAt, tt.commits)
+			assert.Equal(t, tt.expected, metrics)
+		})
+	}
+}
+
+func TestCalculatePullRequestMetrics(t *testing.T) {
+	comments := []*types.PRComment{{CreatedAt: hour(6)}, {CreatedAt: hour(3)}}
+	events := []*types.PullRequestEvent{{Event: types.PullRequestEventReadyForReview, CreatedAt: hour(1)}}
+	commits := []*types.PRCommit{{AuthoredAt: hour(-2), CommittedAt: hour(-2)}, {AuthoredAt: hour(4), CommittedAt: hour(4)}}
+
+	tests := []struct {
+		name     string
+		pr       *types.PullRequest
+		comments []*types.PRComment
+		events   []*types.PullRequestEvent
+		commits  []*types.PRCommit
+		expected map[string]interface{}
+	}{
+		{
+			name: "open pull request without reviews",
+			pr:   &types.PullRequest{CreatedAt: hour(0)},
+			expected: map[string]interface{}{
+				"number_of_non_bot_comments": 0,
+				"reopened_count":             0,
+			},
+		},
+		{
+			name:   "timeline metrics of pull requests without commits",
+			pr:     &types.PullRequest{CreatedAt: hour(0)},
+			events: []*types.PullRequestEvent{{Event: types.PullRequestEventClosed, CreatedAt: hour(1)}, {Event: types.PullRequestEventReopened, CreatedAt: hour(2)}},
+			expected: map[string]interface{}{
+				"number_of_non_bot_comments": 0,
+				"reopened_count":             1,
+			},
+		},
+		{
+			name:     "merged pull request with reviews, timeline events and commits",
+			pr:       &types.PullRequest{CreatedAt: hour(0), MergedAt: timePtr(hour(8))},
+			comments: comments,
+			events:   events,
+			commits:  commits,
+			expected: map[string]interface{}{
+				"number_of_non_bot_comments":                         2,
+				"time_to_merge_seconds":                              int64(8 * 3600),
+				"time_to_first_non_bot_review_seconds":               int64(3 * 3600),
+				"reopened_count":                                     0,
+				"time_from_ready_for_review_to_first_review_seconds": int64(2 * 3600),
+				"commits_count":                                      2,
+				"coding_time_seconds":                                int64(2 * 3600),
+				"commits_after_first_review":                         1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, calculatePullRequestMetrics(tt.pr, tt.comments, tt.events, tt.commits))
+		})
+	}
+}
+
+func TestRecomputePullRequestMetrics(t *testing.T) {
+	orgID := "org-1"
+	pr := func(id string) *types.PullRequest {
+		return &types.PullRequest{ID: id, CreatedAt: hour(0), MergedAt: timePtr(hour(2))}
+	}
+	// page matches the params of the page starting after the given pull request
+	page := func(afterID string, limit int) interface{} {
+		return mock.MatchedBy(func(params *types.RecomputeMetricsParams) bool {
+			return params.AfterID == afterID && params.Limit == limit && params.OutdatedOnly && params.OrganizationID == &orgID
+		})
+	}
+	metrics := datatypes.JSON(`{"number_of_non_bot_comments":0,"reopened_count":0,"time_to_merge_seconds":7200}`)
+
+	tests := []struct {
+		name          string
+		params        *types.RecomputeMetricsParams
+		setupMock     func(db *MockSourceControlDB)
+		expected      int
+		expectedError string
+	}{
+		{
+			name:   "recomputes the pull requests of every page with the current version",
+			params: &types.RecomputeMetricsParams{OrganizationID: &orgID, OutdatedOnly: true},
+			setupMock: func(db *MockSourceControlDB) {
+				db.On("GetPullRequestsForMetrics", mock.Anything, page("", recomputeBatchSize)).Return([]*types.PullRequest{pr("pr-1"), pr("pr-2")}, nil).Once()
+				db.On("GetPullRequestsForMetrics", mock.Anything, page("pr-2", recomputeBatchSize)).Return([]*types.PullRequest{}, nil).Once()
+				db.On("UpdatePullRequestMetrics", mock.Anything, "pr-1", metrics, types.PullRequestMetricsVersion).Return(nil).Once()
+				db.On("UpdatePullRequestMetrics", mock.Anything, "pr-2", metrics, types.PullRequestMetricsVersion).Return(nil).Once()
+			},
+			expected: 2,
+		},
+		{
+			name:   "stops at the limit",
+			params: &types.RecomputeMetricsParams{OrganizationID: &orgID, OutdatedOnly: true, Limit: 1},
+			setupMock: func(db *MockSourceControlDB) {
+				db.On("GetPullRequestsForMetrics", mock.Anything, page("", 1)).Return([]*types.PullRequest{pr("pr-1")}, nil).Once()
+				db.On("UpdatePullRequestMetrics", mock.Anything, "pr-1", metrics, types.PullRequestMetricsVersion).Return(nil).Once()
+			},
+			expected: 1,
+		},
+		{
+			name:          "start date after end date",
+			params:        &types.RecomputeMetricsParams{OrganizationID: &orgID, StartDate: timePtr(hour(2)), EndDate: timePtr(hour(1))},
+			setupMock:     func(db *MockSourceControlDB) {},
+			expectedError: "start date must be before end date",
+		},
+		{
+			name:   "failed metrics update",
+			params: &types.RecomputeMetricsParams{OrganizationID: &orgID, OutdatedOnly: true},
+			setupMock: func(db *MockSourceControlDB) {
+				db.On("GetPullRequestsForMetrics", mock.Anything, page("", recomputeBatchSize)).Return([]*types.PullRequest{pr("pr-1")}, nil).Once()
+				db.On("UpdatePullRequestMetrics", mock.Anything, "pr-1", metrics, types.PullRequestMetricsVersion).Return(errors.New("db error")).Once()
+			},
+			expectedError: "failed to save metrics for PR pr-1: db error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := new(MockSourceControlDB)
+			db.On("GetPullRequestNonBotComments", mock.Anything, mock.Anything).Return([]*types.PRComment{}, nil)
+			db.On("GetPullRequestEvents", mock.Anything, mock.Anything).Return([]*types.PullRequestEvent{}, nil)
+			db.On("GetPullRequestCommits", mock.Anything, mock.Anything).Return([]*types.PRCommit{}, nil)
+			tt.setupMock(db)
+
+			result, err := (&Api{db: db}).RecomputePullRequestMetrics(context.Background(), tt.params)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result.PullRequests)
+			db.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMarkPullRequestMetricsOutdated(t *testing.T) {
+	orgID := "org-1"
+
+	tests := []struct {
+		name          string
+		params        *types.RecomputeMetricsParams
+		marked        int
+		dbError       error
+		expected      int
+		expectedError string
+	}{
+		{
+			name:     "marks the pull requests of an organization",
+			params:   &types.RecomputeMetricsParams{OrganizationID: &orgID, StartDate: timePtr(hour(1)), EndDate: timePtr(hour(2))},
+			marked:   3,
+			expected: 3,
+		},
+		{
+			name:          "start date after end date",
+			params:        &types.RecomputeMetricsParams{OrganizationID: &orgID, StartDate: timePtr(hour(2)), EndDate: timePtr(hour(1))},
+			expectedError: "start date must be before end date",
+		},
+		{
+			name:          "database error",
+			params:        &types.RecomputeMetricsParams{OrganizationID: &orgID},
+			dbError:       errors.New("db error"),
+			expectedError: "failed to mark pull request metrics as outdated: db error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := new(MockSourceControlDB)
+			db.On("MarkPullRequestMetricsOutdated", mock.Anything, tt.params).Return(tt.marked, tt.dbError).Maybe()
+
+			result, err := (&Api{db: db}).MarkPullRequestMetricsOutdated(context.Background(), tt.params)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				if tt.dbError == nil {
+					db.AssertNotCalled(t, "MarkPullRequestMetricsOutdated", mock.Anything, mock.Anything)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result.PullRequests)
+		})
+	}
+}
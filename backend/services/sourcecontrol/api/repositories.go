@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+
+	liberrors "ems.dev/backend/libraries/errors"
+	"ems.dev/backend/services/sourcecontrol/types"
+)
+
+// UpsertRepository saves a repository synced from a provider, the ID of the saved repository is set on it
+func (a *Api) UpsertRepository(ctx context.Context, repository *types.Repository) error {
+	return a.db.UpsertRepository(ctx, repository)
+}
+
+// GetRepositories retrieves the repositories of an organization
+func (a *Api) GetRepositories(ctx context.Context, params *types.RepositoryParams) ([]*types.Repository, error) {
+	return a.db.GetRepositories(ctx, params)
+}
+
+// GetRepository retrieves a repository of an organization
+func (a *Api) GetRepository(ctx context.Context, organizationID, repositoryID string) (*types.Repository, error) {
+	repository, err := a.db.GetRepository(ctx, organizationID, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	if repository == nil {
+		return nil, liberrors.NewNotFoundError("repository not found")
+	}
+	return repository, nil
+}
+
+// UpdateRepositoryTeam sets the team owning a repository, a nil team removes the owner
+func (a *Api) UpdateRepositoryTeam(ctx context.Context, organizationID, repositoryID string, teamID *string) (*types.Repository, error) {
+	if _, err := a.GetRepository(ctx, organizationID, repositoryID); err != nil {
+		return nil, err
+	}
+
+	updated, err := a.db.UpdateRepositoryTeam(ctx, organizationID, repositoryID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	// The repository exists, it was not updated because the team is not one of the organization
+	if !updated {
+		return nil, liberrors.NewBadRequestError("team not found in this organization")
+	}
+
+	return a.GetRepository(ctx, organizationID, repositoryID)
+}
@@ -2,7 +2,9 @@ package api
 
 import (
 	"context"
+	"fmt"
 
+	liberrors "ems.dev/backend/libraries/errors"
 	"ems.dev/backend/services/sourcecontrol/database"
 	"ems.dev/backend/services/sourcecontrol/metrics"
 	"ems.dev/backend/services/sourcecontrol/types"
@@ -15,10 +17,51 @@ type SourceControlAPI interface {
 	CreatePullRequest(ctx context.Context, pr *types.PullRequest) (*types.PullRequest, error)
 	UpdatePullRequest(ctx context.Context, pr *types.PullRequest) error
 
+	// Repositories
+	UpsertRepository(ctx context.Context, repository *types.Repository) error
+	GetRepositories(ctx context.Context, params *types.RepositoryParams) ([]*types.Repository, error)
+	GetRepository(ctx context.Context, organizationID, repositoryID string) (*types.Repository, error)
+	UpdateRepositoryTeam(ctx context.Context, organizationID, repositoryID string, teamID *string) (*types.Repository, error)
+
 	// Comments
 	CreatePRComments(ctx context.Context, comments []*types.PRComment) error
+	UpdatePRComment(ctx context.Context, comment *types.PRComment) error
 	GetPullRequestComments(ctx context.Context, prID string) ([]*types.PRComment, error)
 
+	// Commits
+	CreatePRCommits(ctx context.Context, commits []*types.PRCommit) error
+	GetPullRequestCommits(ctx context.Context, prID string) ([]*types.PRCommit, error)
+
+	// Changed files
+	ReplacePRFiles(ctx context.Context, prID string, files []*types.PRFile) error
+	GetPullRequestFiles(ctx context.Context, prID string) ([]*types.PRFile, error)
+	GetFileHotspots(ctx context.Context, params *types.FileHotspotParams) ([]*types.RepositoryHotspots, error)
+
+	// Stored metrics
+	RefreshPullRequestMetrics(ctx context.Context, pr *types.PullRequest) error
+	RecomputePullRequestMetrics(ctx context.Context, params *types.RecomputeMetricsParams) (*types.RecomputeMetricsResult, error)
+	MarkPullRequestMetricsOutdated(ctx context.Context, params *types.RecomputeMetricsParams) (*types.RecomputeMetricsResult, error)
+
+	// Team attribution
+	GetPullRequestAttributions(ctx context.Context, params *types.PullRequestAttributionParams) ([]*types.PullRequestAttribution, error)
+	UpdatePullRequestTeams(ctx context.Context, teams []*types.PullRequestTeam) error
+
+	// Code owners
+	ReplacePRCodeOwners(ctx context.Context, prID string, owners []*types.PRCodeOwner) error
+	GetPullRequestCodeOwners(ctx context.Context, prID string) ([]*types.PRCodeOwner, error)
+
+	// Timeline events
+	CreatePullRequestEvents(ctx context.Context, events []*types.PullRequestEvent) error
+	GetPullRequestEvents(ctx context.Context, prID string) ([]*types.PullRequestEvent, error)
+
+	// Deployments
+	UpsertDeployment(ctx context.Context, deployment *types.Deployment) error
+	CreateDeploymentStatuses(ctx context.Context, statuses []*types.DeploymentStatus) error
+
+	// CI runs
+	UpsertCIRuns(ctx context.Context, runs []*types.CIRun) error
+	GetPullRequestCIRuns(ctx context.Context, prID string) ([]*types.CIRun, error)
+
 	// Member Activity
 	GetMemberPullRequests(ctx context.Context, params *types.MemberPullRequestParams) ([]*types.PullRequestWithComments, error)
 	GetMemberPullRequestReviews(ctx context.Context, params *types.MemberPullRequestReviewsParams) ([]*types.MemberActivity, error)
@@ -27,6 +70,11 @@ type SourceControlAPI interface {
 	CalculateMetrics(ctx context.Context, params types.MetricRuleParams) (*types.MetricsResponse, error)
 }
 
+const (
+	defaultHotspotsLimit = 10
+	maxHotspotsLimit     = 100
+)
+
 type Api struct {
 	db            database.DB
 	metricsEngine metrics.MetricsEngine
@@ -51,11 +99,137 @@ func (a *Api) CreatePRComments(ctx context.Context, comments []*types.PRComment)
 	return a.db.CreatePRComments(ctx, comments)
 }
 
+// UpdatePRComment updates a comment that was edited, or a review whose state changed, on the provider
+func (a *Api) UpdatePRComment(ctx context.Context, comment *types.PRComment) error {
+	return a.db.UpdatePRComment(ctx, comment)
+}
+
 // GetPullRequestComments retrieves all comments for a specific pull request
 func (a *Api) GetPullRequestComments(ctx context.Context, prID string) ([]*types.PRComment, error) {
 	return a.db.GetPullRequestComments(ctx, prID)
 }
 
+// CreatePRCommits saves the commits of pull requests, commits which were already saved are skipped
+func (a *Api) CreatePRCommits(ctx context.Context, commits []*types.PRCommit) error {
+	if len(commits) == 0 {
+		return nil
+	}
+	return a.db.CreatePRCommits(ctx, commits)
+}
+
+// GetPullRequestCommits retrieves the commits of a specific pull request, oldest first
+func (a *Api) GetPullRequestCommits(ctx context.Context, prID string) ([]*types.PRCommit, error) {
+	return a.db.GetPullRequestCommits(ctx, prID)
+}
+
+// ReplacePRFiles replaces the changed files of a pull request
+func (a *Api) ReplacePRFiles(ctx context.Context, prID string, files []*types.PRFile) error {
+	return a.db.ReplacePRFiles(ctx, prID, files)
+}
+
+// GetPullRequestFiles retrieves the changed files of a specific pull request
+func (a *Api) GetPullRequestFiles(ctx context.Context, prID string) ([]*types.PRFile, error) {
+	return a.db.GetPullRequestFiles(ctx, prID)
+}
+
+// GetPullRequestAttributions retrieves a page of the pull requests of an organization with what they are attributed
+// to a team by
+func (a *Api) GetPullRequestAttributions(ctx context.Context, params *types.PullRequestAttributionParams) ([]*types.PullRequestAttribution, error) {
+	return a.db.GetPullRequestAttributions(ctx, params)
+}
+
+// UpdatePullRequestTeams updates the team attribution of pull requests
+func (a *Api) UpdatePullRequestTeams(ctx context.Context, teams []*types.PullRequestTeam) error {
+	if len(teams) == 0 {
+		return nil
+	}
+	return a.db.UpdatePullRequestTeams(ctx, teams)
+}
+
+// GetFileHotspots retrieves the most frequently changed files of the organization's repositories, with the
+// accounts and teams which change them. Files changed by a single contributor are knowledge silos.
+func (a *Api) GetFileHotspots(ctx context.Context, params *types.FileHotspotParams) ([]*types.RepositoryHotspots, error) {
+	if params.StartDate != nil && params.EndDate != nil && params.StartDate.After(*params.EndDate) {
+		return nil, liberrors.NewBadRequestError("start date must be before end date")
+	}
+	if params.Limit <= 0 {
+		params.Limit = defaultHotspotsLimit
+	}
+	if params.Limit > maxHotspotsLimit {
+		return nil, liberrors.NewBadRequestError(fmt.Sprintf("limit must be at most %d", maxHotspotsLimit))
+	}
+
+	hotspots, err := a.db.GetFileHotspots(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hotspots are ordered by repository
+	repositories := []*types.RepositoryHotspots{}
+	for _, hotspot := range hotspots {
+		if len(repositories) == 0 || repositories[len(repositories)-1].RepositoryID != hotspot.RepositoryID {
+			repositories = append(repositories, &types.RepositoryHotspots{
+				RepositoryID:   hotspot.RepositoryID,
+				RepositoryName: hotspot.RepositoryName,
+				Hotspots:       []*types.FileHotspot{},
+			})
+		}
+		repository := repositories[len(repositories)-1]
+		repository.Hotspots = append(repository.Hotspots, hotspot)
+	}
+
+	return repositories, nil
+}
+
+// ReplacePRCodeOwners replaces the code owners required to review a pull request
+func (a *Api) ReplacePRCodeOwners(ctx context.Context, prID string, owners []*types.PRCodeOwner) error {
+	return a.db.ReplacePRCodeOwners(ctx, prID, owners)
+}
+
+// GetPullRequestCodeOwners retrieves the code owners of a specific pull request
+func (a *Api) GetPullRequestCodeOwners(ctx context.Context, prID string) ([]*types.PRCodeOwner, error) {
+	return a.db.GetPullRequestCodeOwners(ctx, prID)
+}
+
+// CreatePullRequestEvents saves the timeline events of pull requests, events which were already saved are skipped
+func (a *Api) CreatePullRequestEvents(ctx context.Context, events []*types.PullRequestEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return a.db.CreatePullRequestEvents(ctx, events)
+}
+
+// GetPullRequestEvents retrieves the timeline events of a specific pull request, oldest first
+func (a *Api) GetPullRequestEvents(ctx context.Context, prID string) ([]*types.PullRequestEvent, error) {
+	return a.db.GetPullRequestEvents(ctx, prID)
+}
+
+// UpsertDeployment creates a deployment or updates the status of an existing one
+func (a *Api) UpsertDeployment(ctx context.Context, deployment *types.Deployment) error {
+	return a.db.UpsertDeployment(ctx, deployment)
+}
+
+// CreateDeploymentStatuses saves the statuses of deployments, statuses which were already saved are skipped
+func (a *Api) CreateDeploymentStatuses(ctx context.Context, statuses []*types.DeploymentStatus) error {
+	if len(statuses) == 0 {
+		return nil
+	}
+	return a.db.CreateDeploymentStatuses(ctx, statuses)
+}
+
+// UpsertCIRuns creates CI runs or updates the status of existing ones
+func (a *Api) UpsertCIRuns(ctx context.Context, runs []*types.CIRun) error {
+	if len(runs) == 0 {
+		return nil
+	}
+	return a.db.UpsertCIRuns(ctx, runs)
+}
+
+// GetPullRequestCIRuns retrieves the CI runs of a specific pull request, oldest first
+func (a *Api) GetPullRequestCIRuns(ctx context.Context, prID string) ([]*types.CIRun, error) {
+	return a.db.GetPullRequestCIRuns(ctx, prID)
+}
+
 func (a *Api) UpdatePullRequest(ctx context.Context, pr *types.PullRequest) error {
 	return a.db.UpdatePullRequest(ctx, pr)
 }
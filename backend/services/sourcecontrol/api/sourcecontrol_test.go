@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ems.dev/backend/services/sourcecontrol/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFileHotspots(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	// Hotspots are ranked by the database, per repository
+	ranked := []*types.FileHotspot{
+		{RepositoryID: "repo-1", RepositoryName: "acme/api", Path: "main.go", Changes: 9},
+		{RepositoryID: "repo-1", RepositoryName: "acme/api", Path: "go.mod", Changes: 4},
+		{RepositoryID: "repo-2", RepositoryName: "acme/web", Path: "index.ts", Changes: 7},
+	}
+
+	tests := []struct {
+		name          string
+		params        *types.FileHotspotParams
+		hotspots      []*types.FileHotspot
+		dbErr         error
+		expectedLimit int
+		expected      map[string][]string // paths by repository ID and name
+		expectedError string
+	}{
+		{
+			name:          "hotspots are grouped by repository in their rank",
+			params:        &types.FileHotspotParams{OrganizationID: "org-1", Limit: 2},
+			hotspots:      ranked,
+			expectedLimit: 2,
+			expected:      map[string][]string{"repo-1 acme/api": {"main.go", "go.mod"}, "repo-2 acme/web": {"index.ts"}},
+		},
+		{
+			name:   "repositories are grouped by ID",
+			params: &types.FileHotspotParams{OrganizationID: "org-1"},
+			hotspots: []*types.FileHotspot{
+				{RepositoryID: "repo-1", RepositoryName: "acme/api", Path: "main.go", Changes: 9},
+				{RepositoryID: "repo-3", RepositoryName: "acme/api", Path: "main.go", Changes: 3},
+			},
+			expectedLimit: defaultHotspotsLimit,
+			expected:      map[string][]string{"repo-1 acme/api": {"main.go"}, "repo-3 acme/api": {"main.go"}},
+		},
+		{
+			name:          "default limit",
+			params:        &types.FileHotspotParams{OrganizationID: "org-1"},
+			hotspots:      []*types.FileHotspot{},
+			expectedLimit: defaultHotspotsLimit,
+			expected:      map[string][]string{},
+		},
+		{
+			name:          "limit above the maximum",
+			params:        &types.FileHotspotParams{OrganizationID: "org-1", Limit: maxHotspotsLimit + 1},
+			expectedError: "limit must be at most 100",
+		},
+		{
+			name:          "start date after end date",
+			params:        &types.FileHotspotParams{OrganizationID: "org-1", StartDate: &end, EndDate: &start},
+			expectedError: "start date must be before end date",
+		},
+		{
+			name:          "database error",
+			params:        &types.FileHotspotParams{OrganizationID: "org-1"},
+			dbErr:         errors.New("database error"),
+			expectedLimit: defaultHotspotsLimit,
+			expectedError: "database error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &MockSourceControlDB{}
+			db.On("GetFileHotspots", mock.Anything, mock.MatchedBy(func(params *types.FileHotspotParams) bool {
+				return params.Limit == tt.expectedLimit
+			})).Return(tt.hotspots, tt.dbErr).Maybe()
+
+			repositories, err := (&Api{db: db}).GetFileHotspots(context.Background(), tt.params)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			db.AssertExpectations(t)
+
+			actual := map[string][]string{}
+			for _, repository := range repositories {
+				paths := []string{}
+				for _, hotspot := range repository.Hotspots {
+					paths = append(paths, hotspot.Path)
+				}
+				actual[repository.RepositoryID+" "+repository.RepositoryName] = paths
+			}
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
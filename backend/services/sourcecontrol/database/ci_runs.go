@@ -0,0 +1,562 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+	"ems.dev/backend/services/sourcecontrol/types"
+	"gorm.io/gorm/clause"
+)
+
+// UpsertCIRuns creates CI runs or updates the status of existing ones, runs keep changing until they complete and
+// every attempt of a re-run is a separate run
+func (d *SourceControlDB) UpsertCIRuns(ctx context.Context, runs []*types.CIRun) error {
+	return d.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: "pr_id"},
+				{Name: "source"},
+				{Name: "provider_id"},
+				{Name: "attempt"},
+			},
+			DoUpdates: clause.AssignmentColumns([]string{"repository_id", "status", "conclusion", "started_at", "completed_at"}),
+		}).
+		Create(runs).
+		Error
+}
+
+// GetPullRequestCIRuns retrieves the CI runs of a specific pull request, oldest first
+func (d *SourceControlDB) GetPullRequestCIRuns(ctx context.Context, prID string) ([]*types.CIRun, error) {
+	var runs []*types.CIRun
+	err := d.db.WithContext(ctx).Where("pr_id = ?", prID).Order("created_at ASC").Find(&runs).Error
+	if err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// finishedCIRuns filters CI runs which completed with a success or a failure, cancelled and skipped runs don't
+// tell anything about the build health
+const finishedCIRuns = `
+		AND cr.status = 'completed'
+		AND cr.conclusion IN ('success', 'failure', 'timed_out')
+		AND cr.started_at IS NOT NULL
+		AND cr.completed_at IS NOT NULL
+`
+
+// ciRunSeconds is the duration of a CI run in seconds
+const ciRunSeconds = "EXTRACT(EPOCH FROM (cr.completed_at - cr.started_at))"
+
+// ciFailureRate is the percentage of failed CI run attempts, every attempt of a re-run is stored with its own conclusion
+const ciFailureRate = `COALESCE(100.0 * COUNT(*) FILTER (WHERE cr.conclusion != 'success') / NULLIF(COUNT(*), 0), 0)`
+
+// prCheckWaits is the time each pull request waited on checks in seconds. For every pushed commit, the time between
+// the start of its first run and the end of its last run.
+const prCheckWaits = `(
+			SELECT commit_waits.pr_id, SUM(commit_waits.wait_seconds) as wait_seconds
+			FROM (
+				SELECT cr.pr_id, cr.head_sha, EXTRACT(EPOCH FROM (MAX(cr.completed_at) - MIN(cr.started_at))) as wait_seconds
+				FROM ci_runs cr
+				WHERE cr.started_at IS NOT NULL
+				AND cr.completed_at IS NOT NULL
+				GROUP BY cr.pr_id, cr.head_sha
+			) commit_waits
+			GROUP BY commit_waits.pr_id
+		) w`
+
+// CalculateCIDuration calculates the duration of the finished CI runs of the accounts' pull requests
+func (d *SourceControlDB) CalculateCIDuration(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
+	selectStatement, err := secondsAggregate(metricOperation, ciRunSeconds)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + selectStatement + ` as ci_duration_seconds
+		FROM ci_runs cr
+		JOIN pull_requests pr ON cr.pr_id = pr.id
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+		WHERE sca.organization_id = ?
+		AND cr.created_at >= ?
+		AND cr.created_at <= ?
+	` + finishedCIRuns
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND pr.external_account_id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	return d.scanSeconds(ctx, query, args)
+}
+
+// CalculateCIDurationGraph calculates the duration of the finished CI runs per interval and repository, the data
+// points are keyed by the owner and name of the repository
+func (d *SourceControlDB) CalculateCIDurationGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, interval string) ([]types.TimeSeriesEntry, error) {
+	selectStatement, err := secondsAggregate(metricOperation, ciRunSeconds)
+	if err != nil {
+		return nil, err
+	}
+
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', cr.created_at)"
+	query := `
+		SELECT ` + dateTrunc + ` as date, ` + repositoryFullName + ` as repository_name, ` + selectStatement + ` as ci_duration_seconds
+		FROM ci_runs cr
+		JOIN pull_requests pr ON cr.pr_id = pr.id
+		JOIN repositories r ON r.id = cr.repository_id
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+		WHERE sca.organization_id = ?
+		AND cr.created_at >= ?
+		AND cr.created_at <= ?
+	` + finishedCIRuns
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND cr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND pr.external_account_id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	query += " GROUP BY " + dateTrunc + ", r.id, r.owner, r.name ORDER BY date, r.owner, r.name"
+
+	return d.scanRepositoryTimeSeries(ctx, query, args)
+}
+
+// CalculateCIDurationForAccounts calculates the median CI duration across accounts
+func (d *SourceControlDB) CalculateCIDurationForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	query := `
+		SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_duration) as peer_ci_duration
+		FROM (
+			SELECT sca.member_id, PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY ` + ciRunSeconds + `) as member_duration
+			FROM ci_runs cr
+			JOIN pull_requests pr ON cr.pr_id = pr.id
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+			WHERE sca.organization_id = ?
+			AND cr.created_at >= ?
+			AND cr.created_at <= ?
+	` + finishedCIRuns
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND sca.id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	query += `
+			GROUP BY sca.member_id
+		) member_durations
+	`
+
+	return d.scanPeerValue(ctx, query, args)
+}
+
+// CalculateCIDurationGraphForAccounts calculates the median CI duration across peers over time
+func (d *SourceControlDB) CalculateCIDurationGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', cr.created_at)"
+	query := `
+		SELECT date, PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_duration) as peer_value
+		FROM (
+			SELECT ` + dateTrunc + ` as date, sca.member_id, PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY ` + ciRunSeconds + `) as member_duration
+			FROM ci_runs cr
+			JOIN pull_requests pr ON cr.pr_id = pr.id
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+			WHERE sca.organization_id = ?
+			AND cr.created_at >= ?
+			AND cr.created_at <= ?
+	` + finishedCIRuns
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND sca.id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	query += `
+			GROUP BY ` + dateTrunc + `, sca.member_id
+		) member_durations
+		GROUP BY date
+		ORDER BY date
+	`
+
+	return d.scanTimeSeries(ctx, query, args, "Peers")
+}
+
+// CalculateCIFailureRate calculates the percentage of failed CI run attempts of the accounts' pull requests
+func (d *SourceControlDB) CalculateCIFailureRate(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*float64, error) {
+	if metricOperation != metrictypes.MetricOperationAverage {
+		return nil, fmt.Errorf("invalid metric operation for CI failure rate: %s", metricOperation)
+	}
+
+	query := `
+		SELECT ` + ciFailureRate + ` as ci_failure_rate
+		FROM ci_runs cr
+		JOIN pull_requests pr ON cr.pr_id = pr.id
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+		WHERE sca.organization_id = ?
+		AND cr.created_at >= ?
+		AND cr.created_at <= ?
+	` + finishedCIRuns
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND pr.external_account_id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	var rate float64
+	if err := d.db.WithContext(ctx).Raw(query, args...).Scan(&rate).Error; err != nil {
+		return nil, err
+	}
+
+	return &rate, nil
+}
+
+// CalculateCIFailureRateGraph calculates the percentage of failed CI run attempts per interval and repository, the
+// data points are keyed by the owner and name of the repository
+func (d *SourceControlDB) CalculateCIFailureRateGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, interval string) ([]types.TimeSeriesEntry, error) {
+	if metricOperation != metrictypes.MetricOperationAverage {
+		return nil, fmt.Errorf("invalid metric operation for CI failure rate: %s", metricOperation)
+	}
+
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', cr.created_at)"
+	query := `
+		SELECT ` + dateTrunc + ` as date, ` + repositoryFullName + ` as repository_name, ` + ciFailureRate + ` as ci_failure_rate
+		FROM ci_runs cr
+		JOIN pull_requests pr ON cr.pr_id = pr.id
+		JOIN repositories r ON r.id = cr.repository_id
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+		WHERE sca.organization_id = ?
+		AND cr.created_at >= ?
+		AND cr.created_at <= ?
+	` + finishedCIRuns
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND cr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND pr.external_account_id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	query += " GROUP BY " + dateTrunc + ", r.id, r.owner, r.name ORDER BY date, r.owner, r.name"
+
+	return d.scanRepositoryTimeSeries(ctx, query, args)
+}
+
+// CalculateCIFailureRateForAccounts calculates the median CI failure rate across accounts
+func (d *SourceControlDB) CalculateCIFailureRateForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	query := `
+		SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_rate) as peer_ci_failure_rate
+		FROM (
+			SELECT sca.member_id, ` + ciFailureRate + ` as member_rate
+			FROM ci_runs cr
+			JOIN pull_requests pr ON cr.pr_id = pr.id
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+			WHERE sca.organization_id = ?
+			AND cr.created_at >= ?
+			AND cr.created_at <= ?
+	` + finishedCIRuns
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND sca.id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	query += `
+			GROUP BY sca.member_id
+		) member_rates
+	`
+
+	return d.scanPeerValue(ctx, query, args)
+}
+
+// CalculateCIFailureRateGraphForAccounts calculates the median CI failure rate across peers over time
+func (d *SourceControlDB) CalculateCIFailureRateGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', cr.created_at)"
+	query := `
+		SELECT date, PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_rate) as peer_value
+		FROM (
+			SELECT ` + dateTrunc + ` as date, sca.member_id, ` + ciFailureRate + ` as member_rate
+			FROM ci_runs cr
+			JOIN pull_requests pr ON cr.pr_id = pr.id
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+			WHERE sca.organization_id = ?
+			AND cr.created_at >= ?
+			AND cr.created_at <= ?
+	` + finishedCIRuns
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND sca.id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	query += `
+			GROUP BY ` + dateTrunc + `, sca.member_id
+		) member_rates
+		GROUP BY date
+		ORDER BY date
+	`
+
+	return d.scanTimeSeries(ctx, query, args, "Peers")
+}
+
+// CalculateCheckWaitTime calculates the time the accounts' pull requests waited on checks
+func (d *SourceControlDB) CalculateCheckWaitTime(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
+	selectStatement, err := secondsAggregate(metricOperation, "w.wait_seconds")
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + selectStatement + ` as check_wait_seconds
+		FROM pull_requests pr
+		JOIN ` + prCheckWaits + ` ON w.pr_id = pr.id
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+		WHERE sca.organization_id = ?
+		AND pr.created_at >= ?
+		AND pr.created_at <= ?
+	`
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND pr.external_account_id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	return d.scanSeconds(ctx, query, args)
+}
+
+// CalculateCheckWaitTimeGraph calculates the time pull requests waited on checks per interval and repository, the
+// data points are keyed by the owner and name of the repository
+func (d *SourceControlDB) CalculateCheckWaitTimeGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, interval string) ([]types.TimeSeriesEntry, error) {
+	selectStatement, err := secondsAggregate(metricOperation, "w.wait_seconds")
+	if err != nil {
+		return nil, err
+	}
+
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', pr.created_at)"
+	query := `
+		SELECT ` + dateTrunc + ` as date, ` + repositoryFullName + ` as repository_name, ` + selectStatement + ` as check_wait_seconds
+		FROM pull_requests pr
+		JOIN ` + prCheckWaits + ` ON w.pr_id = pr.id
+		JOIN repositories r ON r.id = pr.repository_id
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+		WHERE sca.organization_id = ?
+		AND pr.created_at >= ?
+		AND pr.created_at <= ?
+	`
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND pr.external_account_id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	query += " GROUP BY " + dateTrunc + ", r.id, r.owner, r.name ORDER BY date, r.owner, r.name"
+
+	return d.scanRepositoryTimeSeries(ctx, query, args)
+}
+
+// CalculateCheckWaitTimeForAccounts calculates the median check wait time across accounts
+func (d *SourceControlDB) CalculateCheckWaitTimeForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	query := `
+		SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_wait) as peer_check_wait
+		FROM (
+			SELECT sca.member_id, PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY w.wait_seconds) as member_wait
+			FROM pull_requests pr
+			JOIN ` + prCheckWaits + ` ON w.pr_id = pr.id
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+			WHERE sca.organization_id = ?
+			AND pr.created_at >= ?
+			AND pr.created_at <= ?
+	`
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND sca.id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	query += `
+			GROUP BY sca.member_id
+		) member_waits
+	`
+
+	return d.scanPeerValue(ctx, query, args)
+}
+
+// CalculateCheckWaitTimeGraphForAccounts calculates the median check wait time across peers over time
+func (d *SourceControlDB) CalculateCheckWaitTimeGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', pr.created_at)"
+	query := `
+		SELECT date, PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_wait) as peer_value
+		FROM (
+			SELECT ` + dateTrunc + ` as date, sca.member_id, PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY w.wait_seconds) as member_wait
+			FROM pull_requests pr
+			JOIN ` + prCheckWaits + ` ON w.pr_id = pr.id
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+			WHERE sca.organization_id = ?
+			AND pr.created_at >= ?
+			AND pr.created_at <= ?
+	`
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND sca.id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	query += `
+			GROUP BY ` + dateTrunc + `, sca.member_id
+		) member_waits
+		GROUP BY date
+		ORDER BY date
+	`
+
+	return d.scanTimeSeries(ctx, query, args, "Peers")
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"ems.dev/backend/services/sourcecontrol/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertCIRuns(t *testing.T) {
+	repositoryID := "repo-1"
+
+	tests := []struct {
+		name                 string
+		run                  *types.CIRun
+		expectedRepositoryID *string
+	}{
+		{
+			name:                 "run of a pull request in a repository",
+			run:                  &types.CIRun{PRID: "pr-1", RepositoryID: &repositoryID, ProviderID: "1", Attempt: 1},
+			expectedRepositoryID: &repositoryID,
+		},
+		{
+			name: "run of a pull request without repository",
+			run:  &types.CIRun{PRID: "pr-1", ProviderID: "1", Attempt: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, statements := newDryRunDB(t)
+
+			require.NoError(t, db.UpsertCIRuns(context.Background(), []*types.CIRun{tt.run}))
+			require.Len(t, *statements, 1)
+			statement := (*statements)[0]
+			assert.Contains(t, statement.sql, `ON CONFLICT ("pr_id","source","provider_id","attempt") DO UPDATE SET "repository_id"="excluded"."repository_id",`)
+			assert.Contains(t, statement.vars, tt.expectedRepositoryID)
+		})
+	}
+}
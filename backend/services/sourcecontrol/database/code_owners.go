@@ -0,0 +1,250 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+	"ems.dev/backend/services/sourcecontrol/types"
+	"gorm.io/gorm"
+)
+
+// ReplacePRCodeOwners replaces the code owners of a pull request, its changed files and the CODEOWNERS file can
+// change between syncs
+func (d *SourceControlDB) ReplacePRCodeOwners(ctx context.Context, prID string, owners []*types.PRCodeOwner) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("pr_id = ?", prID).Delete(&types.PRCodeOwner{}).Error; err != nil {
+			return err
+		}
+		if len(owners) == 0 {
+			return nil
+		}
+		return tx.Create(owners).Error
+	})
+}
+
+// GetPullRequestCodeOwners retrieves the code owners of a specific pull request
+func (d *SourceControlDB) GetPullRequestCodeOwners(ctx context.Context, prID string) ([]*types.PRCodeOwner, error) {
+	var owners []*types.PRCodeOwner
+	err := d.db.WithContext(ctx).Where("pr_id = ?", prID).Order("owner ASC").Find(&owners).Error
+	if err != nil {
+		return nil, err
+	}
+	return owners, nil
+}
+
+// ownerReviewCoverage is the percentage of pull requests reviewed by all of their code owners
+const ownerReviewCoverage = `COALESCE(100.0 * COUNT(*) FILTER (WHERE NOT EXISTS (
+	SELECT 1 FROM pr_code_owners co WHERE co.pr_id = pr.id AND NOT co.reviewed
+)) / NULLIF(COUNT(*), 0), 0)`
+
+// withCodeOwners filters merged pull requests which required code owner reviews
+const withCodeOwners = `
+		AND pr.merged_at IS NOT NULL
+		AND EXISTS (
+			SELECT 1 FROM pr_code_owners co WHERE co.pr_id = pr.id
+		)
+`
+
+// scanRepositoryTimeSeries reads (date, repository, value) rows ordered by date into a time series with a data
+// point per repository
+func (d *SourceControlDB) scanRepositoryTimeSeries(ctx context.Context, query string, args []any) ([]types.TimeSeriesEntry, error) {
+	rows, err := d.db.WithContext(ctx).Raw(query, args...).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dataPoints := []types.TimeSeriesEntry{}
+	for rows.Next() {
+		var date time.Time
+		var repositoryName string
+		var value float64
+		if err := rows.Scan(&date, &repositoryName, &value); err != nil {
+			return nil, err
+		}
+
+		dataPoint := types.TimeSeriesDataPoint{
+			Key:   repositoryName,
+			Value: value,
+		}
+
+		formattedDate := date.Format("2006-01-02")
+		if last := len(dataPoints) - 1; last >= 0 && dataPoints[last].Date == formattedDate {
+			dataPoints[last].Data = append(dataPoints[last].Data, dataPoint)
+			continue
+		}
+
+		dataPoints = append(dataPoints, types.TimeSeriesEntry{
+			Date: formattedDate,
+			Data: []types.TimeSeriesDataPoint{dataPoint},
+		})
+	}
+
+	return dataPoints, nil
+}
+
+// CalculateOwnerReviewCoverage calculates the percentage of the accounts' merged pull requests which were reviewed
+// by all of their code owners
+func (d *SourceControlDB) CalculateOwnerReviewCoverage(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*float64, error) {
+	if metricOperation != metrictypes.MetricOperationAverage {
+		return nil, fmt.Errorf("invalid metric operation for owner review coverage: %s", metricOperation)
+	}
+
+	query := `
+		SELECT ` + ownerReviewCoverage + ` as owner_review_coverage
+		FROM pull_requests pr
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+		WHERE sca.organization_id = ?
+		AND pr.created_at >= ?
+		AND pr.created_at <= ?
+	` + withCodeOwners
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND pr.external_account_id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	var coverage float64
+	if err := d.db.WithContext(ctx).Raw(query, args...).Scan(&coverage).Error; err != nil {
+		return nil, err
+	}
+
+	return &coverage, nil
+}
+
+// CalculateOwnerReviewCoverageGraph calculates the owner review coverage of the accounts' merged pull requests per
+// interval and repository, the data points are keyed by the owner and name of the repository
+func (d *SourceControlDB) CalculateOwnerReviewCoverageGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, interval string) ([]types.TimeSeriesEntry, error) {
+	if metricOperation != metrictypes.MetricOperationAverage {
+		return nil, fmt.Errorf("invalid metric operation for owner review coverage: %s", metricOperation)
+	}
+
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', pr.created_at)"
+	query := `
+		SELECT ` + dateTrunc + ` as date, ` + repositoryFullName + ` as repository_name, ` + ownerReviewCoverage + ` as owner_review_coverage
+		FROM pull_requests pr
+		JOIN repositories r ON r.id = pr.repository_id
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+		WHERE sca.organization_id = ?
+		AND pr.created_at >= ?
+		AND pr.created_at <= ?
+	` + withCodeOwners
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND pr.external_account_id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	query += " GROUP BY " + dateTrunc + ", r.id, r.owner, r.name ORDER BY date, r.owner, r.name"
+
+	return d.scanRepositoryTimeSeries(ctx, query, args)
+}
+
+// CalculateOwnerReviewCoverageForAccounts calculates the median owner review coverage across accounts
+func (d *SourceControlDB) CalculateOwnerReviewCoverageForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	query := `
+		SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_coverage) as peer_owner_review_coverage
+		FROM (
+			SELECT sca.member_id, ` + ownerReviewCoverage + ` as member_coverage
+			FROM pull_requests pr
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+			WHERE sca.organization_id = ?
+			AND pr.created_at >= ?
+			AND pr.created_at <= ?
+	` + withCodeOwners
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND sca.id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	query += `
+			GROUP BY sca.member_id
+		) member_coverages
+	`
+
+	return d.scanPeerValue(ctx, query, args)
+}
+
+// CalculateOwnerReviewCoverageGraphForAccounts calculates the median owner review coverage across peers over time
+func (d *SourceControlDB) CalculateOwnerReviewCoverageGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', pr.created_at)"
+	query := `
+		SELECT date, PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_coverage) as peer_value
+		FROM (
+			SELECT ` + dateTrunc + ` as date, sca.member_id, ` + ownerReviewCoverage + ` as member_coverage
+			FROM pull_requests pr
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+			WHERE sca.organization_id = ?
+			AND pr.created_at >= ?
+			AND pr.created_at <= ?
+	` + withCodeOwners
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND sca.id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	query += `
+			GROUP BY ` + dateTrunc + `, sca.member_id
+		) member_coverages
+		GROUP BY date
+		ORDER BY date
+	`
+
+	return d.scanTimeSeries(ctx, query, args, "Peers")
+}
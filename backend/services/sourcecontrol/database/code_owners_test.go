@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+	"ems.dev/backend/services/sourcecontrol/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// repositoryGraph is one of the metric graphs with a data point per repository
+type repositoryGraph func(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, interval string) ([]types.TimeSeriesEntry, error)
+
+func TestRepositoryGraphs(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	repositories := []string{"repo-1"}
+
+	tests := []struct {
+		name               string
+		graph              func(db *SourceControlDB) repositoryGraph
+		operation          metrictypes.MetricOperation
+		expectedJoin       string
+		expectedRepository string
+	}{
+		{
+			name:               "CI duration",
+			graph:              func(db *SourceControlDB) repositoryGraph { return db.CalculateCIDurationGraph },
+			operation:          metrictypes.MetricOperationMedian,
+			expectedJoin:       "JOIN repositories r ON r.id = cr.repository_id",
+			expectedRepository: "cr.repository_id IN ($4)",
+		},
+		{
+			name:               "CI failure rate",
+			graph:              func(db *SourceControlDB) repositoryGraph { return db.CalculateCIFailureRateGraph },
+			operation:          metrictypes.MetricOperationAverage,
+			expectedJoin:       "JOIN repositories r ON r.id = cr.repository_id",
+			expectedRepository: "cr.repository_id IN ($4)",
+		},
+		{
+			name:               "check wait time",
+			graph:              func(db *SourceControlDB) repositoryGraph { return db.CalculateCheckWaitTimeGraph },
+			operation:          metrictypes.MetricOperationMedian,
+			expectedJoin:       "JOIN repositories r ON r.id = pr.repository_id",
+			expectedRepository: "pr.repository_id IN ($4)",
+		},
+		{
+			name:               "owner review coverage",
+			graph:              func(db *SourceControlDB) repositoryGraph { return db.CalculateOwnerReviewCoverageGraph },
+			operation:          metrictypes.MetricOperationAverage,
+			expectedJoin:       "JOIN repositories r ON r.id = pr.repository_id",
+			expectedRepository: "pr.repository_id IN ($4)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, statements := newDryRunDB(t)
+
+			_, err := tt.graph(db)(context.Background(), "org-1", nil, nil, repositories, start, end, tt.operation, "weekly")
+			require.ErrorIs(t, err, gorm.ErrDryRunModeUnsupported)
+			require.Len(t, *statements, 1)
+			statement := (*statements)[0]
+
+			// Data points are keyed by the owner and name of the repositories, which are grouped by ID
+			assert.Contains(t, statement.sql, "as date, r.owner || '/' || r.name as repository_name,")
+			assert.Contains(t, statement.sql, tt.expectedJoin)
+			assert.Contains(t, statement.sql, tt.expectedRepository)
+			assert.Contains(t, statement.sql, "GROUP BY DATE_TRUNC('week', ")
+			assert.Contains(t, statement.sql, ", r.id, r.owner, r.name ORDER BY date, r.owner, r.name")
+			assert.NotContains(t, statement.sql, "pr.repository_name")
+			assert.Equal(t, []interface{}{"org-1", start, end, "repo-1"}, statement.vars)
+		})
+	}
+}
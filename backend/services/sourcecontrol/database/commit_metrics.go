@@ -0,0 +1,244 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+	"ems.dev/backend/services/sourcecontrol/types"
+)
+
+// Keys of the commit metrics stored in the metrics JSON of pull requests. They are only stored on the pull requests
+// of providers which import commits.
+const (
+	commitsCountMetric            = "commits_count"
+	codingTimeMetric              = "coding_time_seconds"
+	commitsAfterFirstReviewMetric = "commits_after_first_review"
+)
+
+// pullRequestMetricAggregate returns the aggregate of a metric stored in the metrics JSON of pull requests
+func pullRequestMetricAggregate(key string, metricOperation metrictypes.MetricOperation) (string, error) {
+	value := "(pr.metrics->>'" + key + "')::numeric"
+	switch metricOperation {
+	case metrictypes.MetricOperationMedian:
+		return "PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY " + value + ")", nil
+	case metrictypes.MetricOperationAverage:
+		return "AVG(" + value + ")", nil
+	}
+	return "", fmt.Errorf("invalid metric operation for %s: %s", key, metricOperation)
+}
+
+// pullRequestMetricFilters filters the pull requests with the metric stored by repository, and by team or by author
+func pullRequestMetricFilters(key string, accountColumn string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string) (string, []any) {
+	query := " AND pr.metrics->>'" + key + "' IS NOT NULL"
+
+	var args []any
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND " + accountColumn + " IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	return query, args
+}
+
+// calculatePullRequestMetric aggregates a metric stored on the accounts' pull requests, 0 when there are none
+func (d *SourceControlDB) calculatePullRequestMetric(ctx context.Context, key string, metricOperation metrictypes.MetricOperation, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	aggregate, err := pullRequestMetricAggregate(key, metricOperation)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + aggregate + ` as value
+		FROM pull_requests pr
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+		WHERE sca.organization_id = ?
+		AND pr.created_at >= ?
+		AND pr.created_at <= ?
+	`
+	filters, filterArgs := pullRequestMetricFilters(key, "pr.external_account_id", sourceControlAccountIDs, teamIDs, repositoryIDs)
+	query += filters
+
+	args := append([]any{organizationID, startDate, endDate}, filterArgs...)
+
+	var result *float64
+	if err := d.db.WithContext(ctx).Raw(query, args...).Scan(&result).Error; err != nil {
+		return nil, err
+	}
+
+	value := 0.0
+	if result != nil {
+		value = *result
+	}
+
+	return &value, nil
+}
+
+// calculatePullRequestMetricGraph aggregates a metric stored on the accounts' pull requests per interval
+func (d *SourceControlDB) calculatePullRequestMetricGraph(ctx context.Context, key string, metricOperation metrictypes.MetricOperation, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+	aggregate, err := pullRequestMetricAggregate(key, metricOperation)
+	if err != nil {
+		return nil, err
+	}
+
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', pr.created_at)"
+	query := `
+		SELECT ` + dateTrunc + ` as date, ` + aggregate + ` as value
+		FROM pull_requests pr
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+		WHERE sca.organization_id = ?
+		AND pr.created_at >= ?
+		AND pr.created_at <= ?
+	`
+	filters, filterArgs := pullRequestMetricFilters(key, "pr.external_account_id", sourceControlAccountIDs, teamIDs, repositoryIDs)
+	query += filters + " GROUP BY " + dateTrunc + " ORDER BY date"
+
+	args := append([]any{organizationID, startDate, endDate}, filterArgs...)
+
+	return d.scanTimeSeries(ctx, query, args, metricLabel)
+}
+
+// calculatePullRequestMetricForAccounts calculates the median across accounts of a metric stored on their pull
+// requests
+func (d *SourceControlDB) calculatePullRequestMetricForAccounts(ctx context.Context, key string, metricOperation metrictypes.MetricOperation, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	aggregate, err := pullRequestMetricAggregate(key, metricOperation)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_value) as peer_value
+		FROM (
+			SELECT sca.member_id, ` + aggregate + ` as member_value
+			FROM pull_requests pr
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+			WHERE sca.organization_id = ?
+			AND pr.created_at >= ?
+			AND pr.created_at <= ?
+	`
+	filters, filterArgs := pullRequestMetricFilters(key, "sca.id", sourceControlAccountIDs, teamIDs, repositoryIDs)
+	query += filters + `
+			GROUP BY sca.member_id
+		) member_values
+	`
+
+	args := append([]any{organizationID, startDate, endDate}, filterArgs...)
+
+	return d.scanPeerValue(ctx, query, args)
+}
+
+// calculatePullRequestMetricGraphForAccounts calculates the median across peers of a metric stored on their pull
+// requests over time
+func (d *SourceControlDB) calculatePullRequestMetricGraphForAccounts(ctx context.Context, key string, metricOperation metrictypes.MetricOperation, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	aggregate, err := pullRequestMetricAggregate(key, metricOperation)
+	if err != nil {
+		return nil, err
+	}
+
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', pr.created_at)"
+	query := `
+		SELECT date, PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_value) as peer_value
+		FROM (
+			SELECT ` + dateTrunc + ` as date, sca.member_id, ` + aggregate + ` as member_value
+			FROM pull_requests pr
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+			WHERE sca.organization_id = ?
+			AND pr.created_at >= ?
+			AND pr.created_at <= ?
+	`
+	filters, filterArgs := pullRequestMetricFilters(key, "sca.id", sourceControlAccountIDs, teamIDs, repositoryIDs)
+	query += filters + `
+			GROUP BY ` + dateTrunc + `, sca.member_id
+		) member_values
+		GROUP BY date
+		ORDER BY date
+	`
+
+	args := append([]any{organizationID, startDate, endDate}, filterArgs...)
+
+	return d.scanTimeSeries(ctx, query, args, "Peers")
+}
+
+// CalculateCommitsPerPR calculates the number of commits of the accounts' pull requests
+func (d *SourceControlDB) CalculateCommitsPerPR(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*float64, error) {
+	return d.calculatePullRequestMetric(ctx, commitsCountMetric, metricOperation, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate)
+}
+
+// CalculateCommitsPerPRGraph calculates the number of commits of the accounts' pull requests per interval
+func (d *SourceControlDB) CalculateCommitsPerPRGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+	return d.calculatePullRequestMetricGraph(ctx, commitsCountMetric, metricOperation, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricLabel, interval)
+}
+
+// CalculateCommitsPerPRForAccounts calculates the median of the average commits per pull request across accounts
+func (d *SourceControlDB) CalculateCommitsPerPRForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	return d.calculatePullRequestMetricForAccounts(ctx, commitsCountMetric, metrictypes.MetricOperationAverage, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate)
+}
+
+// CalculateCommitsPerPRGraphForAccounts calculates the median of the average commits per pull request across peers
+// over time
+func (d *SourceControlDB) CalculateCommitsPerPRGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	return d.calculatePullRequestMetricGraphForAccounts(ctx, commitsCountMetric, metrictypes.MetricOperationAverage, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, interval)
+}
+
+// CalculateCodingTime calculates the time between the first commit and the creation of the accounts' pull requests
+func (d *SourceControlDB) CalculateCodingTime(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
+	result, err := d.calculatePullRequestMetric(ctx, codingTimeMetric, metricOperation, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	value := int(*result)
+	return &value, nil
+}
+
+// CalculateCodingTimeGraph calculates the time between the first commit and the creation of the accounts' pull
+// requests per interval
+func (d *SourceControlDB) CalculateCodingTimeGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+	return d.calculatePullRequestMetricGraph(ctx, codingTimeMetric, metricOperation, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricLabel, interval)
+}
+
+// CalculateCodingTimeForAccounts calculates the median coding time across accounts
+func (d *SourceControlDB) CalculateCodingTimeForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	return d.calculatePullRequestMetricForAccounts(ctx, codingTimeMetric, metrictypes.MetricOperationMedian, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate)
+}
+
+// CalculateCodingTimeGraphForAccounts calculates the median coding time across peers over time
+func (d *SourceControlDB) CalculateCodingTimeGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	return d.calculatePullRequestMetricGraphForAccounts(ctx, codingTimeMetric, metrictypes.MetricOperationMedian, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, interval)
+}
+
+// CalculateCommitsAfterFirstReview calculates the number of commits pushed after the first review of the accounts'
+// reviewed pull requests
+func (d *SourceControlDB) CalculateCommitsAfterFirstReview(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*float64, error) {
+	return d.calculatePullRequestMetric(ctx, commitsAfterFirstReviewMetric, metricOperation, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate)
+}
+
+// CalculateCommitsAfterFirstReviewGraph calculates the number of commits pushed after the first review of the
+// accounts' reviewed pull requests per interval
+func (d *SourceControlDB) CalculateCommitsAfterFirstReviewGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+	return d.calculatePullRequestMetricGraph(ctx, commitsAfterFirstReviewMetric, metricOperation, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricLabel, interval)
+}
+
+// CalculateCommitsAfterFirstReviewForAccounts calculates the median of the average commits pushed after the first
+// review across accounts
+func (d *SourceControlDB) CalculateCommitsAfterFirstReviewForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	return d.calculatePullRequestMetricForAccounts(ctx, commitsAfterFirstReviewMetric, metrictypes.MetricOperationAverage, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate)
+}
+
+// CalculateCommitsAfterFirstReviewGraphForAccounts calculates the median of the average commits pushed after the
+// first review across peers over time
+func (d *SourceControlDB) CalculateCommitsAfterFirstReviewGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	return d.calculatePullRequestMetricGraphForAccounts(ctx, commitsAfterFirstReviewMetric, metrictypes.MetricOperationAverage, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, interval)
+}
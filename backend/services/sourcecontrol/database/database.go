@@ -7,7 +7,9 @@ import (
 
 	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
 	"ems.dev/backend/services/sourcecontrol/types"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // SourceControlDB defines the interface for source control database operations
@@ -17,51 +19,159 @@ type DB interface {
 	CreatePullRequest(ctx context.Context, pr *types.PullRequest) (*types.PullRequest, error)
 	UpdatePullRequest(ctx context.Context, pr *types.PullRequest) error
 
+	// Repositories
+	UpsertRepository(ctx context.Context, repository *types.Repository) error
+	GetRepositories(ctx context.Context, params *types.RepositoryParams) ([]*types.Repository, error)
+	GetRepository(ctx context.Context, organizationID, repositoryID string) (*types.Repository, error)
+	UpdateRepositoryTeam(ctx context.Context, organizationID, repositoryID string, teamID *string) (bool, error)
+
 	// Comments
 	CreatePRComments(ctx context.Context, comments []*types.PRComment) error
+	UpdatePRComment(ctx context.Context, comment *types.PRComment) error
 	GetPullRequestComments(ctx context.Context, prID string) ([]*types.PRComment, error)
 
+	// Commits
+	CreatePRCommits(ctx context.Context, commits []*types.PRCommit) error
+	GetPullRequestCommits(ctx context.Context, prID string) ([]*types.PRCommit, error)
+
+	// Changed files
+	ReplacePRFiles(ctx context.Context, prID string, files []*types.PRFile) error
+	GetPullRequestFiles(ctx context.Context, prID string) ([]*types.PRFile, error)
+	GetFileHotspots(ctx context.Context, params *types.FileHotspotParams) ([]*types.FileHotspot, error)
+
+	// Stored metrics
+	GetPullRequestsForMetrics(ctx context.Context, params *types.RecomputeMetricsParams) ([]*types.PullRequest, error)
+	MarkPullRequestMetricsOutdated(ctx context.Context, params *types.RecomputeMetricsParams) (int, error)
+	GetPullRequestNonBotComments(ctx context.Context, prID string) ([]*types.PRComment, error)
+	UpdatePullRequestMetrics(ctx context.Context, prID string, metrics datatypes.JSON, version int) error
+
+	// Team attribution
+	GetPullRequestAttributions(ctx context.Context, params *types.PullRequestAttributionParams) ([]*types.PullRequestAttribution, error)
+	UpdatePullRequestTeams(ctx context.Context, teams []*types.PullRequestTeam) error
+
+	// Code owners
+	ReplacePRCodeOwners(ctx context.Context, prID string, owners []*types.PRCodeOwner) error
+	GetPullRequestCodeOwners(ctx context.Context, prID string) ([]*types.PRCodeOwner, error)
+
+	// Timeline events
+	CreatePullRequestEvents(ctx context.Context, events []*types.PullRequestEvent) error
+	GetPullRequestEvents(ctx context.Context, prID string) ([]*types.PullRequestEvent, error)
+
+	// Deployments
+	UpsertDeployment(ctx context.Context, deployment *types.Deployment) error
+	CreateDeploymentStatuses(ctx context.Context, statuses []*types.DeploymentStatus) error
+
+	// CI runs
+	UpsertCIRuns(ctx context.Context, runs []*types.CIRun) error
+	GetPullRequestCIRuns(ctx context.Context, prID string) ([]*types.CIRun, error)
+
 	// Member Activity
 	GetMemberPullRequests(ctx context.Context, params *types.MemberPullRequestParams) ([]*types.PullRequestWithComments, error)
 	GetMemberPullRequestReviews(ctx context.Context, params *types.MemberPullRequestReviewsParams) ([]*types.MemberActivity, error)
 
 	// Calculate time to merge metrics
-	CalculateTimeToMerge(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error)
-	CalculateTimeToMergeGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateTimeToMerge(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error)
+	CalculateTimeToMergeGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
 
 	// Calculate PRs merged metrics
-	CalculatePRsMerged(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error)
-	CalculatePRsMergedGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
+	CalculatePRsMerged(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error)
+	CalculatePRsMergedGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
 
 	// Calculate PRs reviewed metrics
-	CalculatePRsReviewed(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error)
-	CalculatePRsReviewedGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
+	CalculatePRsReviewed(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error)
+	CalculatePRsReviewedGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
 
 	// Calculate LOC metrics
-	CalculateLOCAdded(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error)
-	CalculateLOCAddedGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
-	CalculateLOCRemoved(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error)
-	CalculateLOCRemovedGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateLOCAdded(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error)
+	CalculateLOCAddedGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateLOCRemoved(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error)
+	CalculateLOCRemovedGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
 
 	// Calculate PR Review Complexity metrics
-	CalculatePRReviewComplexity(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*float64, error)
-	CalculatePRReviewComplexityGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
+	CalculatePRReviewComplexity(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*float64, error)
+	CalculatePRReviewComplexityGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
 
 	// Calculate peer metrics (median across peers)
-	CalculateLOCAddedForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time) (*float64, error)
-	CalculateLOCRemovedForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time) (*float64, error)
-	CalculatePRsMergedForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time) (*float64, error)
-	CalculatePRsReviewedForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time) (*float64, error)
-	CalculateTimeToMergeForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time) (*float64, error)
-	CalculatePRReviewComplexityForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time) (*float64, error)
+	CalculateLOCAddedForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error)
+	CalculateLOCRemovedForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error)
+	CalculatePRsMergedForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error)
+	CalculatePRsReviewedForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error)
+	CalculateTimeToMergeForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error)
+	CalculatePRReviewComplexityForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error)
 
 	// Calculate peer graph metrics (median across peers over time)
-	CalculateLOCAddedGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
-	CalculateLOCRemovedGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
-	CalculatePRsMergedGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
-	CalculatePRsReviewedGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
-	CalculateTimeToMergeGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
-	CalculatePRReviewComplexityGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateLOCAddedGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateLOCRemovedGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
+	CalculatePRsMergedGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
+	CalculatePRsReviewedGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateTimeToMergeGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
+	CalculatePRReviewComplexityGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
+
+	// Review state metrics
+	CalculateApprovalsGiven(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error)
+	CalculateApprovalsGivenGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateApprovalsGivenForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error)
+	CalculateApprovalsGivenGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateChangesRequestedRate(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*float64, error)
+	CalculateChangesRequestedRateGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateChangesRequestedRateForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error)
+	CalculateChangesRequestedRateGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
+	CalculatePRsMergedWithoutApproval(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error)
+	CalculatePRsMergedWithoutApprovalGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
+	CalculatePRsMergedWithoutApprovalForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error)
+	CalculatePRsMergedWithoutApprovalGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
+
+	// Commit metrics
+	CalculateCommitsPerPR(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*float64, error)
+	CalculateCommitsPerPRGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateCommitsPerPRForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error)
+	CalculateCommitsPerPRGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateCodingTime(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error)
+	CalculateCodingTimeGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateCodingTimeForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error)
+	CalculateCodingTimeGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateCommitsAfterFirstReview(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*float64, error)
+	CalculateCommitsAfterFirstReviewGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateCommitsAfterFirstReviewForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error)
+	CalculateCommitsAfterFirstReviewGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
+
+	// Code owner metrics
+	CalculateOwnerReviewCoverage(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*float64, error)
+	CalculateOwnerReviewCoverageGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateOwnerReviewCoverageForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error)
+	CalculateOwnerReviewCoverageGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
+
+	// DORA metrics
+	CalculateDeploymentFrequency(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error)
+	CalculateDeploymentFrequencyGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateDeploymentFrequencyForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error)
+	CalculateDeploymentFrequencyGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateLeadTimeForChanges(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error)
+	CalculateLeadTimeForChangesGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateLeadTimeForChangesForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error)
+	CalculateLeadTimeForChangesGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateChangeFailureRate(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*float64, error)
+	CalculateChangeFailureRateGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateChangeFailureRateForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error)
+	CalculateChangeFailureRateGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateTimeToRestore(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error)
+	CalculateTimeToRestoreGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateTimeToRestoreForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error)
+	CalculateTimeToRestoreGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
+
+	// CI metrics
+	CalculateCIDuration(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error)
+	CalculateCIDurationGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateCIDurationForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error)
+	CalculateCIDurationGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateCIFailureRate(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*float64, error)
+	CalculateCIFailureRateGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateCIFailureRateForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error)
+	CalculateCIFailureRateGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateCheckWaitTime(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error)
+	CalculateCheckWaitTimeGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, interval string) ([]types.TimeSeriesEntry, error)
+	CalculateCheckWaitTimeForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error)
+	CalculateCheckWaitTimeGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
 }
 
 type SourceControlDB struct {
@@ -111,6 +221,14 @@ func (d *SourceControlDB) GetPullRequests(ctx context.Context, params *types.Pul
 	if params.Prefix != nil && *params.Prefix != "" {
 		query = query.Where("pull_requests.prefix = ?", *params.Prefix)
 	}
+	// Add team filter if provided
+	if params.TeamID != nil && *params.TeamID != "" {
+		query = query.Where("pull_requests.team_id = ?", *params.TeamID)
+	}
+	// Add repository filter if provided
+	if params.RepositoryID != nil && *params.RepositoryID != "" {
+		query = query.Where("pull_requests.repository_id = ?", *params.RepositoryID)
+	}
 	// Add user IDs filter if provided - convert to member IDs
 	if len(params.UserIDs) > 0 {
 		query = query.Where("om.user_id IN ?", params.UserIDs)
@@ -139,6 +257,59 @@ func (d *SourceControlDB) CreatePRComments(ctx context.Context, comments []*type
 	return d.db.WithContext(ctx).Create(comments).Error
 }
 
+// UpdatePRComment updates an existing pull request comment
+func (d *SourceControlDB) UpdatePRComment(ctx context.Context, comment *types.PRComment) error {
+	return d.db.WithContext(ctx).Model(comment).Updates(comment).Error
+}
+
+// CreatePRCommits creates pull request commits, commits which were already stored for the PR are skipped
+func (d *SourceControlDB) CreatePRCommits(ctx context.Context, commits []*types.PRCommit) error {
+	return d.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: "pr_id"},
+				{Name: "sha"},
+			},
+			DoNothing: true,
+		}).
+		Create(commits).
+		Error
+}
+
+// GetPullRequestCommits retrieves the commits of a specific pull request, oldest first
+func (d *SourceControlDB) GetPullRequestCommits(ctx context.Context, prID string) ([]*types.PRCommit, error) {
+	var commits []*types.PRCommit
+	err := d.db.WithContext(ctx).Where("pr_id = ?", prID).Order("committed_at ASC").Find(&commits).Error
+	if err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+// CreatePullRequestEvents creates pull request timeline events, events which were already stored are skipped
+func (d *SourceControlDB) CreatePullRequestEvents(ctx context.Context, events []*types.PullRequestEvent) error {
+	return d.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: "pr_id"},
+				{Name: "provider_id"},
+			},
+			DoNothing: true,
+		}).
+		Create(events).
+		Error
+}
+
+// GetPullRequestEvents retrieves the timeline events of a specific pull request, oldest first
+func (d *SourceControlDB) GetPullRequestEvents(ctx context.Context, prID string) ([]*types.PullRequestEvent, error) {
+	var events []*types.PullRequestEvent
+	err := d.db.WithContext(ctx).Where("pr_id = ?", prID).Order("created_at ASC").Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
 // UpdatePullRequest updates an existing pull request
 func (d *SourceControlDB) UpdatePullRequest(ctx context.Context, pr *types.PullRequest) error {
 	return d.db.WithContext(ctx).Model(pr).Updates(pr).Error
@@ -169,7 +340,7 @@ func (d *SourceControlDB) GetMemberPullRequests(ctx context.Context, params *typ
 			pr.metrics,
 			pr.metadata
 		FROM pull_requests pr
-		JOIN member_external_accounts sca ON pr.external_account_id = sca.id
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
 		WHERE sca.member_id = ?
 	`
 
@@ -224,11 +395,10 @@ func (d *SourceControlDB) GetMemberPullRequests(ctx context.Context, params *typ
 			pc.created_at,
 			pc.updated_at
 		FROM pr_comments pc
-		JOIN member_external_accounts sca ON pc.external_account_id = sca.id
+		JOIN member_external_accounts sca ON pc.external_account_id = sca.id AND NOT sca.is_bot
 		WHERE pc.pr_id IN(?) 
 		AND pc.body IS NOT NULL 
 		AND pc.body != ''
-		AND sca.metadata->>'type' = 'User'
 		ORDER BY pc.pr_id, pc.created_at ASC
 	`
 
@@ -305,7 +475,7 @@ func (d *SourceControlDB) GetMemberPullRequestReviews(ctx context.Context, param
 			NULL as pr_metrics
 		FROM pr_comments pc
 		JOIN pull_requests pr ON pc.pr_id = pr.id
-		JOIN member_external_accounts sca ON pc.external_account_id = sca.id
+		JOIN member_external_accounts sca ON pc.external_account_id = sca.id AND NOT sca.is_bot
 		JOIN member_external_accounts pr_author ON pr.external_account_id = pr_author.id
 		WHERE sca.member_id = ? 
 		AND sca.id != pr.external_account_id` + commentFilter + `
@@ -349,7 +519,7 @@ func (d *SourceControlDB) CreatePullRequest(ctx context.Context, pr *types.PullR
 }
 
 // CalculateTimeToMerge calculates the time to merge metric
-func (d *SourceControlDB) CalculateTimeToMerge(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
+func (d *SourceControlDB) CalculateTimeToMerge(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
 	selectStatement := ""
 	switch metricOperation {
 	case metrictypes.MetricOperationMedian:
@@ -363,7 +533,7 @@ func (d *SourceControlDB) CalculateTimeToMerge(ctx context.Context, organization
 	query := `
 		SELECT ` + selectStatement + ` as time_to_merge_seconds
 		FROM pull_requests pr
-		JOIN member_external_accounts sca ON pr.external_account_id = sca.id
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
 		WHERE sca.organization_id = ?
 		AND pr.created_at >= ?
 		AND pr.created_at <= ?
@@ -374,10 +544,16 @@ func (d *SourceControlDB) CalculateTimeToMerge(ctx context.Context, organization
 	var args []any
 	args = append(args, organizationID, startDate, endDate)
 
-	// Filter by prefix if provided, otherwise filter by source control account IDs
-	if len(prPrefixes) > 0 {
-		query += " AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	} else if len(sourceControlAccountIDs) > 0 {
 		query += " AND pr.external_account_id IN ?"
 		args = append(args, sourceControlAccountIDs)
@@ -401,7 +577,7 @@ func (d *SourceControlDB) CalculateTimeToMerge(ctx context.Context, organization
 }
 
 // CalculateTimeToMergeGraph calculates the time to merge metric for a graph
-func (d *SourceControlDB) CalculateTimeToMergeGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+func (d *SourceControlDB) CalculateTimeToMergeGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
 	selectStatement := ""
 	switch metricOperation {
 	case metrictypes.MetricOperationMedian:
@@ -426,7 +602,7 @@ func (d *SourceControlDB) CalculateTimeToMergeGraph(ctx context.Context, organiz
 			DATE_TRUNC('` + postgresInterval + `', pr.merged_at) as date,
 			` + selectStatement + ` as time_to_merge_seconds
 		FROM pull_requests pr
-		JOIN member_external_accounts sca ON pr.external_account_id = sca.id
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
 		WHERE sca.organization_id = ?
 		AND pr.created_at >= ?
 		AND pr.created_at <= ?
@@ -437,10 +613,16 @@ func (d *SourceControlDB) CalculateTimeToMergeGraph(ctx context.Context, organiz
 	var args []any
 	args = append(args, organizationID, startDate, endDate)
 
-	// Filter by prefix if provided, otherwise filter by source control account IDs
-	if len(prPrefixes) > 0 {
-		query += " AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	} else if len(sourceControlAccountIDs) > 0 {
 		query += " AND pr.external_account_id IN ?"
 		args = append(args, sourceControlAccountIDs)
@@ -483,7 +665,7 @@ func (d *SourceControlDB) CalculateTimeToMergeGraph(ctx context.Context, organiz
 }
 
 // CalculatePRsMerged calculates the PRs merged metric
-func (d *SourceControlDB) CalculatePRsMerged(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
+func (d *SourceControlDB) CalculatePRsMerged(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
 	selectStatement := ""
 	switch metricOperation {
 	case metrictypes.MetricOperationCount:
@@ -495,7 +677,7 @@ func (d *SourceControlDB) CalculatePRsMerged(ctx context.Context, organizationID
 	query := `
 		SELECT ` + selectStatement + ` as prs_merged_count
 		FROM pull_requests pr
-		JOIN member_external_accounts sca ON pr.external_account_id = sca.id
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
 		WHERE sca.organization_id = ?
 		AND pr.created_at >= ?
 		AND pr.created_at <= ?
@@ -506,10 +688,16 @@ func (d *SourceControlDB) CalculatePRsMerged(ctx context.Context, organizationID
 	var args []any
 	args = append(args, organizationID, startDate, endDate)
 
-	// Filter by prefix if provided, otherwise filter by source control account IDs
-	if len(prPrefixes) > 0 {
-		query += " AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	} else if len(sourceControlAccountIDs) > 0 {
 		query += " AND pr.external_account_id IN ?"
 		args = append(args, sourceControlAccountIDs)
@@ -527,7 +715,7 @@ func (d *SourceControlDB) CalculatePRsMerged(ctx context.Context, organizationID
 }
 
 // CalculatePRsMergedGraph calculates the PRs merged metric for a graph
-func (d *SourceControlDB) CalculatePRsMergedGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+func (d *SourceControlDB) CalculatePRsMergedGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
 	selectStatement := ""
 	switch metricOperation {
 	case metrictypes.MetricOperationCount:
@@ -552,7 +740,7 @@ func (d *SourceControlDB) CalculatePRsMergedGraph(ctx context.Context, organizat
 			DATE_TRUNC('` + postgresInterval + `', pr.merged_at) as date,
 			` + selectStatement + ` as prs_merged_count
 		FROM pull_requests pr
-		JOIN member_external_accounts sca ON pr.external_account_id = sca.id
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
 		WHERE sca.organization_id = ?
 		AND pr.created_at >= ?
 		AND pr.created_at <= ?
@@ -563,10 +751,16 @@ func (d *SourceControlDB) CalculatePRsMergedGraph(ctx context.Context, organizat
 	var args []any
 	args = append(args, organizationID, startDate, endDate)
 
-	// Filter by prefix if provided, otherwise filter by source control account IDs
-	if len(prPrefixes) > 0 {
-		query += " AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	} else if len(sourceControlAccountIDs) > 0 {
 		query += " AND pr.external_account_id IN ?"
 		args = append(args, sourceControlAccountIDs)
@@ -609,7 +803,7 @@ func (d *SourceControlDB) CalculatePRsMergedGraph(ctx context.Context, organizat
 }
 
 // CalculatePRsReviewed calculates the PRs reviewed metric
-func (d *SourceControlDB) CalculatePRsReviewed(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
+func (d *SourceControlDB) CalculatePRsReviewed(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
 	selectStatement := ""
 	switch metricOperation {
 	case metrictypes.MetricOperationCount:
@@ -625,7 +819,7 @@ func (d *SourceControlDB) CalculatePRsReviewed(ctx context.Context, organization
 		AND pr.created_at <= ?
 		AND EXISTS (
 			SELECT 1 FROM pr_comments pc 
-			JOIN member_external_accounts sca ON pc.external_account_id = sca.id
+			JOIN member_external_accounts sca ON pc.external_account_id = sca.id AND NOT sca.is_bot
 			WHERE pc.pr_id = pr.id 
 			AND sca.organization_id = ?
 			AND sca.id IN ?
@@ -635,10 +829,16 @@ func (d *SourceControlDB) CalculatePRsReviewed(ctx context.Context, organization
 	var args []any
 	args = append(args, startDate, endDate, organizationID, sourceControlAccountIDs)
 
-	// Filter by prefix if provided
-	if len(prPrefixes) > 0 {
-		query += " AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	}
 
 	var count int64
@@ -653,7 +853,7 @@ func (d *SourceControlDB) CalculatePRsReviewed(ctx context.Context, organization
 }
 
 // CalculatePRsReviewedGraph calculates the PRs reviewed metric for a graph
-func (d *SourceControlDB) CalculatePRsReviewedGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+func (d *SourceControlDB) CalculatePRsReviewedGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
 	selectStatement := ""
 	switch metricOperation {
 	case metrictypes.MetricOperationCount:
@@ -682,7 +882,7 @@ func (d *SourceControlDB) CalculatePRsReviewedGraph(ctx context.Context, organiz
 		AND pr.created_at <= ?
 		AND EXISTS (
 			SELECT 1 FROM pr_comments pc 
-			JOIN member_external_accounts sca ON pc.external_account_id = sca.id
+			JOIN member_external_accounts sca ON pc.external_account_id = sca.id AND NOT sca.is_bot
 			WHERE pc.pr_id = pr.id 
 			AND sca.organization_id = ?
 			AND sca.id IN ?
@@ -692,10 +892,16 @@ func (d *SourceControlDB) CalculatePRsReviewedGraph(ctx context.Context, organiz
 	var args []any
 	args = append(args, startDate, endDate, organizationID, sourceControlAccountIDs)
 
-	// Filter by prefix if provided
-	if len(prPrefixes) > 0 {
-		query += " AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	}
 
 	query += " GROUP BY DATE_TRUNC('" + postgresInterval + "', pr.created_at)"
@@ -732,7 +938,7 @@ func (d *SourceControlDB) CalculatePRsReviewedGraph(ctx context.Context, organiz
 }
 
 // CalculateLOCAdded calculates the lines of code added metric
-func (d *SourceControlDB) CalculateLOCAdded(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
+func (d *SourceControlDB) CalculateLOCAdded(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
 	selectStatement := ""
 	switch metricOperation {
 	case metrictypes.MetricOperationCount:
@@ -744,7 +950,7 @@ func (d *SourceControlDB) CalculateLOCAdded(ctx context.Context, organizationID
 	query := `
 		SELECT ` + selectStatement + ` as loc_added_count
 		FROM pull_requests pr
-		JOIN member_external_accounts sca ON pr.external_account_id = sca.id
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
 		WHERE sca.organization_id = ?
 		AND pr.created_at >= ?
 		AND pr.created_at <= ?
@@ -755,10 +961,16 @@ func (d *SourceControlDB) CalculateLOCAdded(ctx context.Context, organizationID
 	var args []any
 	args = append(args, organizationID, startDate, endDate)
 
-	// Filter by prefix if provided, otherwise filter by source control account IDs
-	if len(prPrefixes) > 0 {
-		query += " AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	} else if len(sourceControlAccountIDs) > 0 {
 		query += " AND pr.external_account_id IN ?"
 		args = append(args, sourceControlAccountIDs)
@@ -776,7 +988,7 @@ func (d *SourceControlDB) CalculateLOCAdded(ctx context.Context, organizationID
 }
 
 // CalculateLOCAddedGraph calculates the lines of code added metric for a graph
-func (d *SourceControlDB) CalculateLOCAddedGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+func (d *SourceControlDB) CalculateLOCAddedGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
 	selectStatement := ""
 	switch metricOperation {
 	case metrictypes.MetricOperationCount:
@@ -801,7 +1013,7 @@ func (d *SourceControlDB) CalculateLOCAddedGraph(ctx context.Context, organizati
 			DATE_TRUNC('` + postgresInterval + `', pr.merged_at) as date,
 			` + selectStatement + ` as loc_added_count
 		FROM pull_requests pr
-		JOIN member_external_accounts sca ON pr.external_account_id = sca.id
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
 		WHERE sca.organization_id = ?
 		AND pr.created_at >= ?
 		AND pr.created_at <= ?
@@ -812,10 +1024,16 @@ func (d *SourceControlDB) CalculateLOCAddedGraph(ctx context.Context, organizati
 	var args []any
 	args = append(args, organizationID, startDate, endDate)
 
-	// Filter by prefix if provided, otherwise filter by source control account IDs
-	if len(prPrefixes) > 0 {
-		query += " AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	} else if len(sourceControlAccountIDs) > 0 {
 		query += " AND pr.external_account_id IN ?"
 		args = append(args, sourceControlAccountIDs)
@@ -858,7 +1076,7 @@ func (d *SourceControlDB) CalculateLOCAddedGraph(ctx context.Context, organizati
 }
 
 // CalculateLOCRemoved calculates the lines of code removed metric
-func (d *SourceControlDB) CalculateLOCRemoved(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
+func (d *SourceControlDB) CalculateLOCRemoved(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
 	selectStatement := ""
 	switch metricOperation {
 	case metrictypes.MetricOperationCount:
@@ -870,7 +1088,7 @@ func (d *SourceControlDB) CalculateLOCRemoved(ctx context.Context, organizationI
 	query := `
 		SELECT ` + selectStatement + ` as loc_removed_count
 		FROM pull_requests pr
-		JOIN member_external_accounts sca ON pr.external_account_id = sca.id
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
 		WHERE sca.organization_id = ?
 		AND pr.created_at >= ?
 		AND pr.created_at <= ?
@@ -881,7 +1099,17 @@ func (d *SourceControlDB) CalculateLOCRemoved(ctx context.Context, organizationI
 	var args []any
 	args = append(args, organizationID, startDate, endDate)
 
-	if len(sourceControlAccountIDs) > 0 {
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
 		query += " AND pr.external_account_id IN ?"
 		args = append(args, sourceControlAccountIDs)
 	}
@@ -898,7 +1126,7 @@ func (d *SourceControlDB) CalculateLOCRemoved(ctx context.Context, organizationI
 }
 
 // CalculateLOCRemovedGraph calculates the lines of code removed metric for a graph
-func (d *SourceControlDB) CalculateLOCRemovedGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+func (d *SourceControlDB) CalculateLOCRemovedGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
 	selectStatement := ""
 	switch metricOperation {
 	case metrictypes.MetricOperationCount:
@@ -923,7 +1151,7 @@ func (d *SourceControlDB) CalculateLOCRemovedGraph(ctx context.Context, organiza
 			DATE_TRUNC('` + postgresInterval + `', pr.merged_at) as date,
 			` + selectStatement + ` as loc_removed_count
 		FROM pull_requests pr
-		JOIN member_external_accounts sca ON pr.external_account_id = sca.id
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
 		WHERE sca.organization_id = ?
 		AND pr.created_at >= ?
 		AND pr.created_at <= ?
@@ -934,10 +1162,16 @@ func (d *SourceControlDB) CalculateLOCRemovedGraph(ctx context.Context, organiza
 	var args []any
 	args = append(args, organizationID, startDate, endDate)
 
-	// Filter by prefix if provided, otherwise filter by source control account IDs
-	if len(prPrefixes) > 0 {
-		query += " AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	} else if len(sourceControlAccountIDs) > 0 {
 		query += " AND pr.external_account_id IN ?"
 		args = append(args, sourceControlAccountIDs)
@@ -980,7 +1214,7 @@ func (d *SourceControlDB) CalculateLOCRemovedGraph(ctx context.Context, organiza
 }
 
 // CalculatePRReviewComplexity calculates the PR review complexity metric
-func (d *SourceControlDB) CalculatePRReviewComplexity(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*float64, error) {
+func (d *SourceControlDB) CalculatePRReviewComplexity(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*float64, error) {
 	selectStatement := ""
 	switch metricOperation {
 	case metrictypes.MetricOperationAverage:
@@ -998,7 +1232,7 @@ func (d *SourceControlDB) CalculatePRReviewComplexity(ctx context.Context, organ
 		AND pr.status = 'closed'
 		AND EXISTS (
 			SELECT 1 FROM pr_comments pc 
-			JOIN member_external_accounts sca ON pc.external_account_id = sca.id
+			JOIN member_external_accounts sca ON pc.external_account_id = sca.id AND NOT sca.is_bot
 			WHERE pc.pr_id = pr.id 
 			AND sca.organization_id = ?
 	`
@@ -1018,10 +1252,16 @@ func (d *SourceControlDB) CalculatePRReviewComplexity(ctx context.Context, organ
 	`
 	}
 
-	// Filter by prefix if provided
-	if len(prPrefixes) > 0 {
-		query += " AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	}
 
 	var result struct {
@@ -1042,7 +1282,7 @@ func (d *SourceControlDB) CalculatePRReviewComplexity(ctx context.Context, organ
 }
 
 // CalculatePRReviewComplexityGraph calculates the PR review complexity metric for a graph
-func (d *SourceControlDB) CalculatePRReviewComplexityGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+func (d *SourceControlDB) CalculatePRReviewComplexityGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
 	selectStatement := ""
 	switch metricOperation {
 	case metrictypes.MetricOperationAverage:
@@ -1073,7 +1313,7 @@ func (d *SourceControlDB) CalculatePRReviewComplexityGraph(ctx context.Context,
 		AND pr.status = 'closed'
 		AND EXISTS (
 			SELECT 1 FROM pr_comments pc 
-			JOIN member_external_accounts sca ON pc.external_account_id = sca.id
+			JOIN member_external_accounts sca ON pc.external_account_id = sca.id AND NOT sca.is_bot
 			WHERE pc.pr_id = pr.id 
 			AND pc.type = 'REVIEW'
 			AND sca.organization_id = ?
@@ -1094,10 +1334,16 @@ func (d *SourceControlDB) CalculatePRReviewComplexityGraph(ctx context.Context,
 	`
 	}
 
-	// Filter by prefix if provided
-	if len(prPrefixes) > 0 {
-		query += " AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	}
 
 	query += " GROUP BY DATE_TRUNC('" + postgresInterval + "', pr.created_at)"
@@ -1134,13 +1380,13 @@ func (d *SourceControlDB) CalculatePRReviewComplexityGraph(ctx context.Context,
 }
 
 // CalculateLOCAddedForAccounts calculates the median LOC added across accounts
-func (d *SourceControlDB) CalculateLOCAddedForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time) (*float64, error) {
+func (d *SourceControlDB) CalculateLOCAddedForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
 	query := `
 		SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_total) as peer_loc_added
 		FROM (
 			SELECT sca.member_id, COALESCE(SUM(CAST(pr.metadata->>'additions' AS BIGINT)), 0) as member_total
 			FROM pull_requests pr
-			JOIN member_external_accounts sca ON pr.external_account_id = sca.id
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
 			WHERE sca.organization_id = ?
 			AND pr.created_at >= ?
 			AND pr.created_at <= ?
@@ -1151,10 +1397,16 @@ func (d *SourceControlDB) CalculateLOCAddedForAccounts(ctx context.Context, orga
 	var args []any
 	args = append(args, organizationID, startDate, endDate)
 
-	// Filter by prefix if provided, otherwise filter by source control account IDs
-	if len(prPrefixes) > 0 {
-		query += "			AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += "			AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += "			AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	} else if len(sourceControlAccountIDs) > 0 {
 		query += "			AND sca.id IN ?"
 		args = append(args, sourceControlAccountIDs)
@@ -1179,13 +1431,13 @@ func (d *SourceControlDB) CalculateLOCAddedForAccounts(ctx context.Context, orga
 }
 
 // CalculateLOCRemovedForAccounts calculates the median LOC removed across accounts
-func (d *SourceControlDB) CalculateLOCRemovedForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time) (*float64, error) {
+func (d *SourceControlDB) CalculateLOCRemovedForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
 	query := `
 		SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_total) as peer_loc_removed
 		FROM (
 			SELECT sca.member_id, COALESCE(SUM(CAST(pr.metadata->>'deletions' AS BIGINT)), 0) as member_total
 			FROM pull_requests pr
-			JOIN member_external_accounts sca ON pr.external_account_id = sca.id
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
 			WHERE sca.organization_id = ?
 			AND pr.created_at >= ?
 			AND pr.created_at <= ?
@@ -1196,10 +1448,16 @@ func (d *SourceControlDB) CalculateLOCRemovedForAccounts(ctx context.Context, or
 	var args []any
 	args = append(args, organizationID, startDate, endDate)
 
-	// Filter by prefix if provided, otherwise filter by source control account IDs
-	if len(prPrefixes) > 0 {
-		query += "			AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += "			AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += "			AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	} else if len(sourceControlAccountIDs) > 0 {
 		query += "			AND sca.id IN ?"
 		args = append(args, sourceControlAccountIDs)
@@ -1224,13 +1482,13 @@ func (d *SourceControlDB) CalculateLOCRemovedForAccounts(ctx context.Context, or
 }
 
 // CalculatePRsMergedForAccounts calculates the median PRs merged across accounts
-func (d *SourceControlDB) CalculatePRsMergedForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time) (*float64, error) {
+func (d *SourceControlDB) CalculatePRsMergedForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
 	query := `
 		SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_total) as peer_prs_merged
 		FROM (
 			SELECT sca.member_id, COUNT(*) as member_total
 			FROM pull_requests pr
-			JOIN member_external_accounts sca ON pr.external_account_id = sca.id
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
 			WHERE sca.organization_id = ?
 			AND pr.created_at >= ?
 			AND pr.created_at <= ?
@@ -1241,10 +1499,16 @@ func (d *SourceControlDB) CalculatePRsMergedForAccounts(ctx context.Context, org
 	var args []any
 	args = append(args, organizationID, startDate, endDate)
 
-	// Filter by prefix if provided, otherwise filter by source control account IDs
-	if len(prPrefixes) > 0 {
-		query += "			AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += "			AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += "			AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	} else if len(sourceControlAccountIDs) > 0 {
 		query += "			AND sca.id IN ?"
 		args = append(args, sourceControlAccountIDs)
@@ -1269,14 +1533,14 @@ func (d *SourceControlDB) CalculatePRsMergedForAccounts(ctx context.Context, org
 }
 
 // CalculatePRsReviewedForAccounts calculates the median PRs reviewed across accounts
-func (d *SourceControlDB) CalculatePRsReviewedForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time) (*float64, error) {
+func (d *SourceControlDB) CalculatePRsReviewedForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
 	query := `
 		SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_total) as peer_prs_reviewed
 		FROM (
 			SELECT sca.member_id, COUNT(DISTINCT pr.id) as member_total
 			FROM pull_requests pr
 			JOIN pr_comments pc ON pc.pr_id = pr.id
-			JOIN member_external_accounts sca ON pc.external_account_id = sca.id
+			JOIN member_external_accounts sca ON pc.external_account_id = sca.id AND NOT sca.is_bot
 			WHERE sca.organization_id = ?
 			AND pr.created_at >= ?
 			AND pr.created_at <= ?
@@ -1285,10 +1549,16 @@ func (d *SourceControlDB) CalculatePRsReviewedForAccounts(ctx context.Context, o
 	var args []any
 	args = append(args, organizationID, startDate, endDate)
 
-	// Filter by prefix if provided, otherwise filter by source control account IDs
-	if len(prPrefixes) > 0 {
-		query += "			AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += "			AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += "			AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	} else if len(sourceControlAccountIDs) > 0 {
 		query += "			AND sca.id IN ?"
 		args = append(args, sourceControlAccountIDs)
@@ -1313,13 +1583,13 @@ func (d *SourceControlDB) CalculatePRsReviewedForAccounts(ctx context.Context, o
 }
 
 // CalculateTimeToMergeForAccounts calculates the median time to merge across accounts
-func (d *SourceControlDB) CalculateTimeToMergeForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time) (*float64, error) {
+func (d *SourceControlDB) CalculateTimeToMergeForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
 	query := `
 		SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_avg) as peer_time_to_merge
 		FROM (
 			SELECT sca.member_id, AVG(EXTRACT(EPOCH FROM (pr.merged_at - pr.created_at))) as member_avg
 			FROM pull_requests pr
-			JOIN member_external_accounts sca ON pr.external_account_id = sca.id
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
 			WHERE sca.organization_id = ?
 			AND pr.created_at >= ?
 			AND pr.created_at <= ?
@@ -1330,10 +1600,16 @@ func (d *SourceControlDB) CalculateTimeToMergeForAccounts(ctx context.Context, o
 	var args []any
 	args = append(args, organizationID, startDate, endDate)
 
-	// Filter by prefix if provided, otherwise filter by source control account IDs
-	if len(prPrefixes) > 0 {
-		query += "			AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += "			AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += "			AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	} else if len(sourceControlAccountIDs) > 0 {
 		query += "			AND sca.id IN ?"
 		args = append(args, sourceControlAccountIDs)
@@ -1358,14 +1634,14 @@ func (d *SourceControlDB) CalculateTimeToMergeForAccounts(ctx context.Context, o
 }
 
 // CalculatePRReviewComplexityForAccounts calculates the median PR review complexity across accounts
-func (d *SourceControlDB) CalculatePRReviewComplexityForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time) (*float64, error) {
+func (d *SourceControlDB) CalculatePRReviewComplexityForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
 	query := `
 		SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_avg) as peer_pr_review_complexity
 		FROM (
 			SELECT sca.member_id, AVG(pr.additions + pr.deletions) as member_avg
 			FROM pull_requests pr
 			JOIN pr_comments pc ON pc.pr_id = pr.id
-			JOIN member_external_accounts sca ON pc.external_account_id = sca.id
+			JOIN member_external_accounts sca ON pc.external_account_id = sca.id AND NOT sca.is_bot
 			WHERE sca.organization_id = ?
 			AND pr.created_at >= ?
 			AND pr.created_at <= ?
@@ -1375,10 +1651,16 @@ func (d *SourceControlDB) CalculatePRReviewComplexityForAccounts(ctx context.Con
 	var args []any
 	args = append(args, organizationID, startDate, endDate)
 
-	// Filter by prefix if provided, otherwise filter by source control account IDs
-	if len(prPrefixes) > 0 {
-		query += "			AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += "			AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += "			AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	} else if len(sourceControlAccountIDs) > 0 {
 		query += "			AND sca.id IN ?"
 		args = append(args, sourceControlAccountIDs)
@@ -1403,7 +1685,7 @@ func (d *SourceControlDB) CalculatePRReviewComplexityForAccounts(ctx context.Con
 }
 
 // CalculatePRsReviewedGraphForAccounts calculates the median PRs reviewed across peers over time
-func (d *SourceControlDB) CalculatePRsReviewedGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+func (d *SourceControlDB) CalculatePRsReviewedGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
 	// Map interval values to PostgreSQL DATE_TRUNC units
 	postgresInterval := interval
 	switch interval {
@@ -1426,7 +1708,7 @@ func (d *SourceControlDB) CalculatePRsReviewedGraphForAccounts(ctx context.Conte
 				COUNT(DISTINCT pr.id) as member_total
 			FROM pull_requests pr
 			JOIN pr_comments pc ON pc.pr_id = pr.id
-			JOIN member_external_accounts sca ON pc.external_account_id = sca.id
+			JOIN member_external_accounts sca ON pc.external_account_id = sca.id AND NOT sca.is_bot
 			WHERE sca.organization_id = ?
 			AND pr.created_at >= ?
 			AND pr.created_at <= ?
@@ -1435,10 +1717,16 @@ func (d *SourceControlDB) CalculatePRsReviewedGraphForAccounts(ctx context.Conte
 	var args []any
 	args = append(args, organizationID, startDate, endDate)
 
-	// Filter by prefix if provided, otherwise filter by source control account IDs
-	if len(prPrefixes) > 0 {
-		query += "			AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += "			AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += "			AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	} else if len(sourceControlAccountIDs) > 0 {
 		query += "			AND sca.id IN ?"
 		args = append(args, sourceControlAccountIDs)
@@ -1482,7 +1770,7 @@ func (d *SourceControlDB) CalculatePRsReviewedGraphForAccounts(ctx context.Conte
 }
 
 // CalculatePRsMergedGraphForAccounts calculates the median PRs merged across peers over time
-func (d *SourceControlDB) CalculatePRsMergedGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+func (d *SourceControlDB) CalculatePRsMergedGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
 	// Map interval values to PostgreSQL DATE_TRUNC units
 	postgresInterval := interval
 	switch interval {
@@ -1504,7 +1792,7 @@ func (d *SourceControlDB) CalculatePRsMergedGraphForAccounts(ctx context.Context
 				sca.member_id,
 				COUNT(DISTINCT pr.id) as member_total
 			FROM pull_requests pr
-			JOIN member_external_accounts sca ON pr.external_account_id = sca.id
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
 			WHERE sca.organization_id = ?
 			AND pr.created_at >= ?
 			AND pr.created_at <= ?
@@ -1515,10 +1803,16 @@ func (d *SourceControlDB) CalculatePRsMergedGraphForAccounts(ctx context.Context
 	var args []any
 	args = append(args, organizationID, startDate, endDate)
 
-	// Filter by prefix if provided, otherwise filter by source control account IDs
-	if len(prPrefixes) > 0 {
-		query += "			AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += "			AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += "			AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	} else if len(sourceControlAccountIDs) > 0 {
 		query += "			AND sca.id IN ?"
 		args = append(args, sourceControlAccountIDs)
@@ -1562,7 +1856,7 @@ func (d *SourceControlDB) CalculatePRsMergedGraphForAccounts(ctx context.Context
 }
 
 // CalculateLOCAddedGraphForAccounts calculates the median LOC added across peers over time
-func (d *SourceControlDB) CalculateLOCAddedGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+func (d *SourceControlDB) CalculateLOCAddedGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
 	// Map interval values to PostgreSQL DATE_TRUNC units
 	postgresInterval := interval
 	switch interval {
@@ -1584,7 +1878,7 @@ func (d *SourceControlDB) CalculateLOCAddedGraphForAccounts(ctx context.Context,
 				sca.member_id,
 				COALESCE(SUM(CAST(pr.metadata->>'additions' AS BIGINT)), 0) as member_total
 			FROM pull_requests pr
-			JOIN member_external_accounts sca ON pr.external_account_id = sca.id
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
 			WHERE sca.organization_id = ?
 			AND pr.created_at >= ?
 			AND pr.created_at <= ?
@@ -1595,10 +1889,16 @@ func (d *SourceControlDB) CalculateLOCAddedGraphForAccounts(ctx context.Context,
 	var args []any
 	args = append(args, organizationID, startDate, endDate)
 
-	// Filter by prefix if provided, otherwise filter by source control account IDs
-	if len(prPrefixes) > 0 {
-		query += "			AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += "			AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += "			AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	} else if len(sourceControlAccountIDs) > 0 {
 		query += "			AND sca.id IN ?"
 		args = append(args, sourceControlAccountIDs)
@@ -1642,7 +1942,7 @@ func (d *SourceControlDB) CalculateLOCAddedGraphForAccounts(ctx context.Context,
 }
 
 // CalculateLOCRemovedGraphForAccounts calculates the median LOC removed across peers over time
-func (d *SourceControlDB) CalculateLOCRemovedGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+func (d *SourceControlDB) CalculateLOCRemovedGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
 	// Map interval values to PostgreSQL DATE_TRUNC units
 	postgresInterval := interval
 	switch interval {
@@ -1664,7 +1964,7 @@ func (d *SourceControlDB) CalculateLOCRemovedGraphForAccounts(ctx context.Contex
 				sca.member_id,
 				COALESCE(SUM(CAST(pr.metadata->>'deletions' AS BIGINT)), 0) as member_total
 			FROM pull_requests pr
-			JOIN member_external_accounts sca ON pr.external_account_id = sca.id
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
 			WHERE sca.organization_id = ?
 			AND pr.created_at >= ?
 			AND pr.created_at <= ?
@@ -1675,10 +1975,16 @@ func (d *SourceControlDB) CalculateLOCRemovedGraphForAccounts(ctx context.Contex
 	var args []any
 	args = append(args, organizationID, startDate, endDate)
 
-	// Filter by prefix if provided, otherwise filter by source control account IDs
-	if len(prPrefixes) > 0 {
-		query += "			AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += "			AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += "			AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	} else if len(sourceControlAccountIDs) > 0 {
 		query += "			AND sca.id IN ?"
 		args = append(args, sourceControlAccountIDs)
@@ -1722,7 +2028,7 @@ func (d *SourceControlDB) CalculateLOCRemovedGraphForAccounts(ctx context.Contex
 }
 
 // CalculateTimeToMergeGraphForAccounts calculates the median time to merge across peers over time
-func (d *SourceControlDB) CalculateTimeToMergeGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+func (d *SourceControlDB) CalculateTimeToMergeGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
 	// Map interval values to PostgreSQL DATE_TRUNC units
 	postgresInterval := interval
 	switch interval {
@@ -1744,7 +2050,7 @@ func (d *SourceControlDB) CalculateTimeToMergeGraphForAccounts(ctx context.Conte
 				sca.member_id,
 				AVG(EXTRACT(EPOCH FROM (pr.merged_at - pr.created_at))) as member_avg
 			FROM pull_requests pr
-			JOIN member_external_accounts sca ON pr.external_account_id = sca.id
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
 			WHERE sca.organization_id = ?
 			AND pr.created_at >= ?
 			AND pr.created_at <= ?
@@ -1755,10 +2061,16 @@ func (d *SourceControlDB) CalculateTimeToMergeGraphForAccounts(ctx context.Conte
 	var args []any
 	args = append(args, organizationID, startDate, endDate)
 
-	// Filter by prefix if provided, otherwise filter by source control account IDs
-	if len(prPrefixes) > 0 {
-		query += "			AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += "			AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += "			AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	} else if len(sourceControlAccountIDs) > 0 {
 		query += "			AND sca.id IN ?"
 		args = append(args, sourceControlAccountIDs)
@@ -1802,7 +2114,7 @@ func (d *SourceControlDB) CalculateTimeToMergeGraphForAccounts(ctx context.Conte
 }
 
 // CalculatePRReviewComplexityGraphForAccounts calculates the median PR review complexity across peers over time
-func (d *SourceControlDB) CalculatePRReviewComplexityGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+func (d *SourceControlDB) CalculatePRReviewComplexityGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
 	// Map interval values to PostgreSQL DATE_TRUNC units
 	postgresInterval := interval
 	switch interval {
@@ -1831,7 +2143,7 @@ func (d *SourceControlDB) CalculatePRReviewComplexityGraphForAccounts(ctx contex
 					pr.additions + pr.deletions as pr_complexity
 				FROM pull_requests pr
 				JOIN pr_comments pc ON pc.pr_id = pr.id
-				JOIN member_external_accounts sca ON pc.external_account_id = sca.id
+				JOIN member_external_accounts sca ON pc.external_account_id = sca.id AND NOT sca.is_bot
 				WHERE sca.organization_id = ?
 				AND pr.created_at >= ?
 				AND pr.created_at <= ?
@@ -1843,10 +2155,16 @@ func (d *SourceControlDB) CalculatePRReviewComplexityGraphForAccounts(ctx contex
 	var args []any
 	args = append(args, organizationID, startDate, endDate)
 
-	// Filter by prefix if provided, otherwise filter by source control account IDs
-	if len(prPrefixes) > 0 {
-		query += "				AND pr.prefix IN ?"
-		args = append(args, prPrefixes)
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += "				AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += "				AND pr.team_id IN ?"
+		args = append(args, teamIDs)
 	} else if len(sourceControlAccountIDs) > 0 {
 		query += "				AND sca.id IN ?"
 		args = append(args, sourceControlAccountIDs)
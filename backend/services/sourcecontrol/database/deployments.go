@@ -0,0 +1,38 @@
+package database
+
+import (
+	"context"
+
+	"ems.dev/backend/services/sourcecontrol/types"
+	"gorm.io/gorm/clause"
+)
+
+// UpsertDeployment creates a deployment or updates the status of an existing one, the ID of the saved deployment
+// is set on it
+func (d *SourceControlDB) UpsertDeployment(ctx context.Context, deployment *types.Deployment) error {
+	return d.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: "organization_id"},
+				{Name: "source"},
+				{Name: "provider_id"},
+			},
+			DoUpdates: clause.AssignmentColumns([]string{"repository_id", "environment", "production", "status", "finished_at"}),
+		}).
+		Create(deployment).
+		Error
+}
+
+// CreateDeploymentStatuses saves the statuses of deployments, statuses which were already saved are skipped
+func (d *SourceControlDB) CreateDeploymentStatuses(ctx context.Context, statuses []*types.DeploymentStatus) error {
+	return d.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: "deployment_id"},
+				{Name: "provider_id"},
+			},
+			DoNothing: true,
+		}).
+		Create(statuses).
+		Error
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"ems.dev/backend/services/sourcecontrol/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertDeployment(t *testing.T) {
+	repositoryID := "repo-1"
+
+	tests := []struct {
+		name                 string
+		deployment           *types.Deployment
+		expectedRepositoryID *string
+	}{
+		{
+			name:                 "deployment of a repository",
+			deployment:           &types.Deployment{OrganizationID: "org-1", RepositoryName: "api", RepositoryID: &repositoryID, ProviderID: "1"},
+			expectedRepositoryID: &repositoryID,
+		},
+		{
+			name:       "deployment of a repository which wasn't synced",
+			deployment: &types.Deployment{OrganizationID: "org-1", RepositoryName: "api", ProviderID: "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, statements := newDryRunDB(t)
+
+			require.NoError(t, db.UpsertDeployment(context.Background(), tt.deployment))
+			require.Len(t, *statements, 1)
+			statement := (*statements)[0]
+			assert.Contains(t, statement.sql, `ON CONFLICT ("organization_id","source","provider_id") DO UPDATE SET "repository_id"="excluded"."repository_id",`)
+			assert.Contains(t, statement.vars, tt.expectedRepositoryID)
+		})
+	}
+}
@@ -0,0 +1,510 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+	"ems.dev/backend/services/sourcecontrol/types"
+)
+
+// productionDeployments selects the finished production deployments of an organization, with the creation time
+// of the previous successful deployment, the finish time of the next one and the status of the previous deployment
+// to the same repository and environment. Releases only count for repositories which don't report deployments.
+// Deployments which aren't linked to a repository yet are left out, they can't be ordered per repository.
+const productionDeployments = `
+	production_deployments AS (
+		SELECT d.id, d.repository_id, d.environment, d.status, d.created_at, d.finished_at,
+			MAX(d.created_at) FILTER (WHERE d.status = 'success') OVER (
+				PARTITION BY d.repository_id, d.environment ORDER BY d.created_at
+				ROWS BETWEEN UNBOUNDED PRECEDING AND 1 PRECEDING
+			) as previous_success_at,
+			MIN(d.finished_at) FILTER (WHERE d.status = 'success') OVER (
+				PARTITION BY d.repository_id, d.environment ORDER BY d.created_at
+				ROWS BETWEEN 1 FOLLOWING AND UNBOUNDED FOLLOWING
+			) as next_success_at,
+			LAG(d.status) OVER (
+				PARTITION BY d.repository_id, d.environment ORDER BY d.created_at
+			) as previous_status
+		FROM deployments d
+		WHERE d.organization_id = ?
+		AND d.repository_id IS NOT NULL
+		AND d.production
+		AND d.status IN ('success', 'failure', 'error')
+		AND (d.source = 'deployment' OR NOT EXISTS (
+			SELECT 1 FROM deployments rd
+			WHERE rd.organization_id = d.organization_id
+			AND rd.repository_id = d.repository_id
+			AND rd.source = 'deployment'
+			AND rd.production
+		))
+	)`
+
+// deploymentChanges selects the pull requests shipped by each production deployment: the ones merged since the
+// previous successful deployment. The first deployment of an environment has no changes, its previous deployment
+// is unknown.
+const deploymentChanges = `
+	deployment_changes AS (
+		SELECT d.id as deployment_id, pr.id as pr_id, pr.merged_at, sca.member_id
+		FROM production_deployments d
+		JOIN pull_requests pr ON pr.repository_id = d.repository_id
+			AND pr.merged_at > d.previous_success_at
+			AND pr.merged_at <= d.created_at
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+		WHERE sca.organization_id = ?`
+
+// shippedChanges filters deployments which shipped at least one of the selected changes
+const shippedChanges = `
+		AND EXISTS (
+			SELECT 1 FROM deployment_changes dc WHERE dc.deployment_id = d.id
+		)
+`
+
+// incidentStart filters failed deployments following a successful one which were restored by a later successful
+// deployment, consecutive failures are a single incident
+const incidentStart = `
+		AND d.status != 'success'
+		AND (d.previous_status IS NULL OR d.previous_status = 'success')
+		AND d.next_success_at IS NOT NULL
+`
+
+// changeFailureRate is the percentage of production deployments which failed
+const changeFailureRate = `COALESCE(100.0 * COUNT(*) FILTER (WHERE d.status != 'success') / NULLIF(COUNT(*), 0), 0)`
+
+// doraQuery returns the common table expressions of the DORA metrics and their args. The deployment changes are
+// filtered by repository if provided, and by team if provided, otherwise by source control account IDs. scoped is
+// false when none is provided, the metrics then cover every deployment of the organization.
+func doraQuery(organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string) (string, []any, bool) {
+	query := "WITH " + productionDeployments + "," + deploymentChanges
+
+	var args []any
+	args = append(args, organizationID, organizationID)
+
+	scoped := true
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND sca.id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	} else if len(repositoryIDs) == 0 {
+		scoped = false
+	}
+
+	return query + "\n\t)", args, scoped
+}
+
+// durationAggregate aggregates an interval in seconds with the metric operation
+func durationAggregate(metricOperation metrictypes.MetricOperation, duration string) (string, error) {
+	return secondsAggregate(metricOperation, "EXTRACT(EPOCH FROM ("+duration+"))")
+}
+
+// secondsAggregate aggregates a number of seconds with the metric operation
+func secondsAggregate(metricOperation metrictypes.MetricOperation, seconds string) (string, error) {
+	switch metricOperation {
+	case metrictypes.MetricOperationMedian:
+		return "PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY " + seconds + ")", nil
+	case metrictypes.MetricOperationAverage:
+		return "AVG(" + seconds + ")", nil
+	}
+	return "", fmt.Errorf("invalid metric operation: %s", metricOperation)
+}
+
+// scanSeconds reads a duration in seconds, 0 when there are no durations
+func (d *SourceControlDB) scanSeconds(ctx context.Context, query string, args []any) (*int, error) {
+	var result *float64
+	if err := d.db.WithContext(ctx).Raw(query, args...).Scan(&result).Error; err != nil {
+		return nil, err
+	}
+
+	value := 0
+	if result != nil {
+		value = int(*result)
+	}
+
+	return &value, nil
+}
+
+// CalculateDeploymentFrequency calculates the number of successful production deployments. Deployments are
+// attributed to the accounts or teams of the pull requests they shipped.
+func (d *SourceControlDB) CalculateDeploymentFrequency(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
+	if metricOperation != metrictypes.MetricOperationCount {
+		return nil, fmt.Errorf("invalid metric operation for deployment frequency: %s", metricOperation)
+	}
+
+	query, args, scoped := doraQuery(organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs)
+	query += `
+		SELECT COUNT(*) as deployments_count
+		FROM production_deployments d
+		WHERE d.status = 'success'
+		AND d.created_at >= ?
+		AND d.created_at <= ?
+	`
+	args = append(args, startDate, endDate)
+
+	if scoped {
+		query += shippedChanges
+	}
+
+	var count int64
+	if err := d.db.WithContext(ctx).Raw(query, args...).Scan(&count).Error; err != nil {
+		return nil, err
+	}
+
+	value := int(count)
+	return &value, nil
+}
+
+// CalculateDeploymentFrequencyGraph calculates the number of successful production deployments per interval
+func (d *SourceControlDB) CalculateDeploymentFrequencyGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+	if metricOperation != metrictypes.MetricOperationCount {
+		return nil, fmt.Errorf("invalid metric operation for deployment frequency: %s", metricOperation)
+	}
+
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', d.created_at)"
+	query, args, scoped := doraQuery(organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs)
+	query += `
+		SELECT ` + dateTrunc + ` as date, COUNT(*) as deployments_count
+		FROM production_deployments d
+		WHERE d.status = 'success'
+		AND d.created_at >= ?
+		AND d.created_at <= ?
+	`
+	args = append(args, startDate, endDate)
+
+	if scoped {
+		query += shippedChanges
+	}
+
+	query += " GROUP BY " + dateTrunc + " ORDER BY date"
+
+	return d.scanTimeSeries(ctx, query, args, metricLabel)
+}
+
+// CalculateDeploymentFrequencyForAccounts calculates the median number of successful production deployments
+// shipping the changes of each account
+func (d *SourceControlDB) CalculateDeploymentFrequencyForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	query, args, _ := doraQuery(organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs)
+	query += `
+		SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_total) as peer_deployments
+		FROM (
+			SELECT dc.member_id, COUNT(DISTINCT d.id) as member_total
+			FROM production_deployments d
+			JOIN deployment_changes dc ON dc.deployment_id = d.id
+			WHERE d.status = 'success'
+			AND d.created_at >= ?
+			AND d.created_at <= ?
+			GROUP BY dc.member_id
+		) member_totals
+	`
+	args = append(args, startDate, endDate)
+
+	return d.scanPeerValue(ctx, query, args)
+}
+
+// CalculateDeploymentFrequencyGraphForAccounts calculates the median number of successful production deployments
+// across peers over time
+func (d *SourceControlDB) CalculateDeploymentFrequencyGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', d.created_at)"
+	query, args, _ := doraQuery(organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs)
+	query += `
+		SELECT date, PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_total) as peer_value
+		FROM (
+			SELECT ` + dateTrunc + ` as date, dc.member_id, COUNT(DISTINCT d.id) as member_total
+			FROM production_deployments d
+			JOIN deployment_changes dc ON dc.deployment_id = d.id
+			WHERE d.status = 'success'
+			AND d.created_at >= ?
+			AND d.created_at <= ?
+			GROUP BY ` + dateTrunc + `, dc.member_id
+		) member_totals
+		GROUP BY date
+		ORDER BY date
+	`
+	args = append(args, startDate, endDate)
+
+	return d.scanTimeSeries(ctx, query, args, "Peers")
+}
+
+// CalculateLeadTimeForChanges calculates the time between the merge of pull requests and the successful
+// production deployment shipping them
+func (d *SourceControlDB) CalculateLeadTimeForChanges(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
+	selectStatement, err := durationAggregate(metricOperation, "d.finished_at - dc.merged_at")
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, _ := doraQuery(organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs)
+	query += `
+		SELECT ` + selectStatement + ` as lead_time_seconds
+		FROM production_deployments d
+		JOIN deployment_changes dc ON dc.deployment_id = d.id
+		WHERE d.status = 'success'
+		AND d.created_at >= ?
+		AND d.created_at <= ?
+	`
+	args = append(args, startDate, endDate)
+
+	return d.scanSeconds(ctx, query, args)
+}
+
+// CalculateLeadTimeForChangesGraph calculates the lead time for changes per interval
+func (d *SourceControlDB) CalculateLeadTimeForChangesGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+	selectStatement, err := durationAggregate(metricOperation, "d.finished_at - dc.merged_at")
+	if err != nil {
+		return nil, err
+	}
+
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', d.created_at)"
+	query, args, _ := doraQuery(organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs)
+	query += `
+		SELECT ` + dateTrunc + ` as date, ` + selectStatement + ` as lead_time_seconds
+		FROM production_deployments d
+		JOIN deployment_changes dc ON dc.deployment_id = d.id
+		WHERE d.status = 'success'
+		AND d.created_at >= ?
+		AND d.created_at <= ?
+		GROUP BY ` + dateTrunc + `
+		ORDER BY date
+	`
+	args = append(args, startDate, endDate)
+
+	return d.scanTimeSeries(ctx, query, args, metricLabel)
+}
+
+// CalculateLeadTimeForChangesForAccounts calculates the median lead time for changes across accounts
+func (d *SourceControlDB) CalculateLeadTimeForChangesForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	query, args, _ := doraQuery(organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs)
+	query += `
+		SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_lead_time) as peer_lead_time
+		FROM (
+			SELECT dc.member_id, PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM (d.finished_at - dc.merged_at))) as member_lead_time
+			FROM production_deployments d
+			JOIN deployment_changes dc ON dc.deployment_id = d.id
+			WHERE d.status = 'success'
+			AND d.created_at >= ?
+			AND d.created_at <= ?
+			GROUP BY dc.member_id
+		) member_lead_times
+	`
+	args = append(args, startDate, endDate)
+
+	return d.scanPeerValue(ctx, query, args)
+}
+
+// CalculateLeadTimeForChangesGraphForAccounts calculates the median lead time for changes across peers over time
+func (d *SourceControlDB) CalculateLeadTimeForChangesGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', d.created_at)"
+	query, args, _ := doraQuery(organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs)
+	query += `
+		SELECT date, PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_lead_time) as peer_value
+		FROM (
+			SELECT ` + dateTrunc + ` as date, dc.member_id, PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM (d.finished_at - dc.merged_at))) as member_lead_time
+			FROM production_deployments d
+			JOIN deployment_changes dc ON dc.deployment_id = d.id
+			WHERE d.status = 'success'
+			AND d.created_at >= ?
+			AND d.created_at <= ?
+			GROUP BY ` + dateTrunc + `, dc.member_id
+		) member_lead_times
+		GROUP BY date
+		ORDER BY date
+	`
+	args = append(args, startDate, endDate)
+
+	return d.scanTimeSeries(ctx, query, args, "Peers")
+}
+
+// CalculateChangeFailureRate calculates the percentage of production deployments which failed
+func (d *SourceControlDB) CalculateChangeFailureRate(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*float64, error) {
+	if metricOperation != metrictypes.MetricOperationAverage {
+		return nil, fmt.Errorf("invalid metric operation for change failure rate: %s", metricOperation)
+	}
+
+	query, args, scoped := doraQuery(organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs)
+	query += `
+		SELECT ` + changeFailureRate + ` as change_failure_rate
+		FROM production_deployments d
+		WHERE d.created_at >= ?
+		AND d.created_at <= ?
+	`
+	args = append(args, startDate, endDate)
+
+	if scoped {
+		query += shippedChanges
+	}
+
+	var rate float64
+	if err := d.db.WithContext(ctx).Raw(query, args...).Scan(&rate).Error; err != nil {
+		return nil, err
+	}
+
+	return &rate, nil
+}
+
+// CalculateChangeFailureRateGraph calculates the percentage of production deployments which failed per interval
+func (d *SourceControlDB) CalculateChangeFailureRateGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+	if metricOperation != metrictypes.MetricOperationAverage {
+		return nil, fmt.Errorf("invalid metric operation for change failure rate: %s", metricOperation)
+	}
+
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', d.created_at)"
+	query, args, scoped := doraQuery(organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs)
+	query += `
+		SELECT ` + dateTrunc + ` as date, ` + changeFailureRate + ` as change_failure_rate
+		FROM production_deployments d
+		WHERE d.created_at >= ?
+		AND d.created_at <= ?
+	`
+	args = append(args, startDate, endDate)
+
+	if scoped {
+		query += shippedChanges
+	}
+
+	query += " GROUP BY " + dateTrunc + " ORDER BY date"
+
+	return d.scanTimeSeries(ctx, query, args, metricLabel)
+}
+
+// CalculateChangeFailureRateForAccounts calculates the median change failure rate of the deployments shipping the
+// changes of each account
+func (d *SourceControlDB) CalculateChangeFailureRateForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	query, args, _ := doraQuery(organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs)
+	query += `
+		SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_rate) as peer_change_failure_rate
+		FROM (
+			SELECT dc.member_id, ` + changeFailureRate + ` as member_rate
+			FROM production_deployments d
+			JOIN (SELECT DISTINCT deployment_id, member_id FROM deployment_changes) dc ON dc.deployment_id = d.id
+			WHERE d.created_at >= ?
+			AND d.created_at <= ?
+			GROUP BY dc.member_id
+		) member_rates
+	`
+	args = append(args, startDate, endDate)
+
+	return d.scanPeerValue(ctx, query, args)
+}
+
+// CalculateChangeFailureRateGraphForAccounts calculates the median change failure rate across peers over time
+func (d *SourceControlDB) CalculateChangeFailureRateGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', d.created_at)"
+	query, args, _ := doraQuery(organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs)
+	query += `
+		SELECT date, PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_rate) as peer_value
+		FROM (
+			SELECT ` + dateTrunc + ` as date, dc.member_id, ` + changeFailureRate + ` as member_rate
+			FROM production_deployments d
+			JOIN (SELECT DISTINCT deployment_id, member_id FROM deployment_changes) dc ON dc.deployment_id = d.id
+			WHERE d.created_at >= ?
+			AND d.created_at <= ?
+			GROUP BY ` + dateTrunc + `, dc.member_id
+		) member_rates
+		GROUP BY date
+		ORDER BY date
+	`
+	args = append(args, startDate, endDate)
+
+	return d.scanTimeSeries(ctx, query, args, "Peers")
+}
+
+// CalculateTimeToRestore calculates the time between a failed production deployment and the next successful
+// deployment to the same repository and environment
+func (d *SourceControlDB) CalculateTimeToRestore(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
+	selectStatement, err := durationAggregate(metricOperation, "d.next_success_at - d.finished_at")
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, scoped := doraQuery(organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs)
+	query += `
+		SELECT ` + selectStatement + ` as time_to_restore_seconds
+		FROM production_deployments d
+		WHERE d.created_at >= ?
+		AND d.created_at <= ?
+	` + incidentStart
+	args = append(args, startDate, endDate)
+
+	if scoped {
+		query += shippedChanges
+	}
+
+	return d.scanSeconds(ctx, query, args)
+}
+
+// CalculateTimeToRestoreGraph calculates the time to restore service per interval
+func (d *SourceControlDB) CalculateTimeToRestoreGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+	selectStatement, err := durationAggregate(metricOperation, "d.next_success_at - d.finished_at")
+	if err != nil {
+		return nil, err
+	}
+
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', d.created_at)"
+	query, args, scoped := doraQuery(organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs)
+	query += `
+		SELECT ` + dateTrunc + ` as date, ` + selectStatement + ` as time_to_restore_seconds
+		FROM production_deployments d
+		WHERE d.created_at >= ?
+		AND d.created_at <= ?
+	` + incidentStart
+	args = append(args, startDate, endDate)
+
+	if scoped {
+		query += shippedChanges
+	}
+
+	query += " GROUP BY " + dateTrunc + " ORDER BY date"
+
+	return d.scanTimeSeries(ctx, query, args, metricLabel)
+}
+
+// CalculateTimeToRestoreForAccounts calculates the median time to restore the failed deployments shipping the
+// changes of each account
+func (d *SourceControlDB) CalculateTimeToRestoreForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	query, args, _ := doraQuery(organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs)
+	query += `
+		SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_time_to_restore) as peer_time_to_restore
+		FROM (
+			SELECT dc.member_id, PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM (d.next_success_at - d.finished_at))) as member_time_to_restore
+			FROM production_deployments d
+			JOIN (SELECT DISTINCT deployment_id, member_id FROM deployment_changes) dc ON dc.deployment_id = d.id
+			WHERE d.created_at >= ?
+			AND d.created_at <= ?
+	` + incidentStart + `
+			GROUP BY dc.member_id
+		) member_times_to_restore
+	`
+	args = append(args, startDate, endDate)
+
+	return d.scanPeerValue(ctx, query, args)
+}
+
+// CalculateTimeToRestoreGraphForAccounts calculates the median time to restore service across peers over time
+func (d *SourceControlDB) CalculateTimeToRestoreGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', d.created_at)"
+	query, args, _ := doraQuery(organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs)
+	query += `
+		SELECT date, PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_time_to_restore) as peer_value
+		FROM (
+			SELECT ` + dateTrunc + ` as date, dc.member_id, PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM (d.next_success_at - d.finished_at))) as member_time_to_restore
+			FROM production_deployments d
+			JOIN (SELECT DISTINCT deployment_id, member_id FROM deployment_changes) dc ON dc.deployment_id = d.id
+			WHERE d.created_at >= ?
+			AND d.created_at <= ?
+	` + incidentStart + `
+			GROUP BY ` + dateTrunc + `, dc.member_id
+		) member_times_to_restore
+		GROUP BY date
+		ORDER BY date
+	`
+	args = append(args, startDate, endDate)
+
+	return d.scanTimeSeries(ctx, query, args, "Peers")
+}
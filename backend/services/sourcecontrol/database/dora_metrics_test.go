@@ -0,0 +1,125 @@
+package database
+
+import (
+	"strings"
+	"testing"
+
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoraQuery(t *testing.T) {
+	accounts := []string{"account-1"}
+	teams := []string{"team-1"}
+	repositories := []string{"repo-1"}
+
+	tests := []struct {
+		name                    string
+		sourceControlAccountIDs []string
+		teamIDs                 []string
+		repositoryIDs           []string
+		expectedFilters         []string
+		expectedArgs            []any
+		expectedScoped          bool
+	}{
+		{
+			name:           "organization deployments",
+			expectedArgs:   []any{"org-1", "org-1"},
+			expectedScoped: false,
+		},
+		{
+			name:                    "deployments of accounts",
+			sourceControlAccountIDs: accounts,
+			expectedFilters:         []string{"sca.id IN ?"},
+			expectedArgs:            []any{"org-1", "org-1", accounts},
+			expectedScoped:          true,
+		},
+		{
+			name:                    "teams take precedence over accounts",
+			sourceControlAccountIDs: accounts,
+			teamIDs:                 teams,
+			expectedFilters:         []string{"pr.team_id IN ?"},
+			expectedArgs:            []any{"org-1", "org-1", teams},
+			expectedScoped:          true,
+		},
+		{
+			name:            "deployments of repositories",
+			repositoryIDs:   repositories,
+			expectedFilters: []string{"pr.repository_id IN ?"},
+			expectedArgs:    []any{"org-1", "org-1", repositories},
+			expectedScoped:  true,
+		},
+		{
+			name:            "deployments of teams in repositories",
+			teamIDs:         teams,
+			repositoryIDs:   repositories,
+			expectedFilters: []string{"pr.repository_id IN ?", "pr.team_id IN ?"},
+			expectedArgs:    []any{"org-1", "org-1", repositories, teams},
+			expectedScoped:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, scoped := doraQuery("org-1", tt.sourceControlAccountIDs, tt.teamIDs, tt.repositoryIDs)
+
+			assert.Equal(t, tt.expectedArgs, args)
+			assert.Equal(t, tt.expectedScoped, scoped)
+			assert.Equal(t, len(args), strings.Count(query, "?"), "every placeholder has an arg")
+			// Deployments are matched with the pull requests and releases of their repository by ID
+			assert.Contains(t, query, "PARTITION BY d.repository_id, d.environment")
+			assert.Contains(t, query, "AND rd.repository_id = d.repository_id")
+			assert.Contains(t, query, "JOIN pull_requests pr ON pr.repository_id = d.repository_id")
+			assert.NotContains(t, query, "repository_name")
+			for _, filter := range []string{"sca.id IN ?", "pr.team_id IN ?", "pr.repository_id IN ?"} {
+				assert.Equal(t, contains(tt.expectedFilters, filter), strings.Contains(query, filter), filter)
+			}
+		})
+	}
+}
+
+func TestDurationAggregate(t *testing.T) {
+	tests := []struct {
+		name          string
+		operation     metrictypes.MetricOperation
+		expected      string
+		expectedError string
+	}{
+		{
+			name:      "median",
+			operation: metrictypes.MetricOperationMedian,
+			expected:  "PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM (d.finished_at - dc.merged_at)))",
+		},
+		{
+			name:      "average",
+			operation: metrictypes.MetricOperationAverage,
+			expected:  "AVG(EXTRACT(EPOCH FROM (d.finished_at - dc.merged_at)))",
+		},
+		{
+			name:          "count",
+			operation:     metrictypes.MetricOperationCount,
+			expectedError: "invalid metric operation: COUNT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggregate, err := durationAggregate(tt.operation, "d.finished_at - dc.merged_at")
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, aggregate)
+		})
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+
+	"ems.dev/backend/services/sourcecontrol/types"
+	"gorm.io/gorm"
+)
+
+// ReplacePRFiles replaces the changed files of a pull request, files can be dropped from a PR by later pushes
+func (d *SourceControlDB) ReplacePRFiles(ctx context.Context, prID string, files []*types.PRFile) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("pr_id = ?", prID).Delete(&types.PRFile{}).Error; err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(files, 500).Error
+	})
+}
+
+// GetPullRequestFiles retrieves the changed files of a specific pull request
+func (d *SourceControlDB) GetPullRequestFiles(ctx context.Context, prID string) ([]*types.PRFile, error) {
+	var files []*types.PRFile
+	err := d.db.WithContext(ctx).Where("pr_id = ?", prID).Order("path ASC").Find(&files).Error
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// GetFileHotspots retrieves the files changed by the most pull requests of each repository, with the accounts
+// and teams which changed them. Pull requests authored by bots are left out. Hotspots are named after the owner and
+// name of their repository.
+func (d *SourceControlDB) GetFileHotspots(ctx context.Context, params *types.FileHotspotParams) ([]*types.FileHotspot, error) {
+	changesQuery := `
+		WITH changes AS (
+			SELECT pr.id as pr_id, pr.repository_id, ` + repositoryFullName + ` as repository_name, pf.path, pf.additions, pf.deletions, pr.created_at,
+				sca.id as external_account_id, sca.username, sca.member_id
+			FROM pr_files pf
+			JOIN pull_requests pr ON pf.pr_id = pr.id
+			JOIN repositories r ON r.id = pr.repository_id
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+			WHERE sca.organization_id = ?
+	`
+
+	var args []any
+	args = append(args, params.OrganizationID)
+
+	if params.RepositoryID != nil {
+		changesQuery += " AND pr.repository_id = ?"
+		args = append(args, *params.RepositoryID)
+	}
+	if params.StartDate != nil {
+		changesQuery += " AND pr.created_at >= ?"
+		args = append(args, *params.StartDate)
+	}
+	if params.EndDate != nil {
+		changesQuery += " AND pr.created_at <= ?"
+		args = append(args, *params.EndDate)
+	}
+
+	changesQuery += `
+		), hotspots AS (
+			SELECT repository_id, repository_name, path, COUNT(DISTINCT pr_id) as changes, SUM(additions) as additions,
+				SUM(deletions) as deletions, MAX(created_at) as last_changed_at,
+				ROW_NUMBER() OVER (PARTITION BY repository_id ORDER BY COUNT(DISTINCT pr_id) DESC, path) as rank
+			FROM changes
+			GROUP BY repository_id, repository_name, path
+		)
+	`
+	args = append(args, params.Limit)
+
+	var hotspots []*types.FileHotspot
+	if err := d.db.WithContext(ctx).Raw(changesQuery+`
+		SELECT repository_id, repository_name, path, changes, additions, deletions, last_changed_at
+		FROM hotspots
+		WHERE rank <= ?
+		ORDER BY repository_name, repository_id, rank
+	`, args...).Scan(&hotspots).Error; err != nil {
+		return nil, err
+	}
+
+	if len(hotspots) == 0 {
+		return hotspots, nil
+	}
+
+	var contributors []types.HotspotContributor
+	if err := d.db.WithContext(ctx).Raw(changesQuery+`
+		SELECT c.repository_id, c.path, c.external_account_id, c.username, c.member_id, COUNT(DISTINCT c.pr_id) as changes
+		FROM changes c
+		JOIN hotspots h ON h.repository_id = c.repository_id AND h.path = c.path
+		WHERE h.rank <= ?
+		GROUP BY c.repository_id, c.path, c.external_account_id, c.username, c.member_id
+		ORDER BY changes DESC, c.username
+	`, args...).Scan(&contributors).Error; err != nil {
+		return nil, err
+	}
+
+	var teams []types.HotspotTeam
+	if err := d.db.WithContext(ctx).Raw(changesQuery+`
+		SELECT c.repository_id, c.path, t.id as team_id, t.name, COUNT(DISTINCT c.pr_id) as changes
+		FROM changes c
+		JOIN hotspots h ON h.repository_id = c.repository_id AND h.path = c.path
+		JOIN team_members tm ON tm.member_id = c.member_id
+		JOIN teams t ON t.id = tm.team_id
+		WHERE h.rank <= ?
+		GROUP BY c.repository_id, c.path, t.id, t.name
+		ORDER BY changes DESC, t.name
+	`, args...).Scan(&teams).Error; err != nil {
+		return nil, err
+	}
+
+	groupHotspotOwners(hotspots, contributors, teams)
+
+	return hotspots, nil
+}
+
+// hotspotKey identifies a hotspot by its repository and path
+type hotspotKey struct {
+	repositoryID string
+	path         string
+}
+
+// groupHotspotOwners sets the contributors and teams of the hotspots they changed, keeping their order
+func groupHotspotOwners(hotspots []*types.FileHotspot, contributors []types.HotspotContributor, teams []types.HotspotTeam) {
+	hotspotsByKey := make(map[hotspotKey]*types.FileHotspot, len(hotspots))
+	for _, hotspot := range hotspots {
+		hotspot.Contributors = []types.HotspotContributor{}
+		hotspot.Teams = []types.HotspotTeam{}
+		hotspotsByKey[hotspotKey{hotspot.RepositoryID, hotspot.Path}] = hotspot
+	}
+	for _, contributor := range contributors {
+		if hotspot, ok := hotspotsByKey[hotspotKey{contributor.RepositoryID, contributor.Path}]; ok {
+			hotspot.Contributors = append(hotspot.Contributors, contributor)
+		}
+	}
+	for _, team := range teams {
+		if hotspot, ok := hotspotsByKey[hotspotKey{team.RepositoryID, team.Path}]; ok {
+			hotspot.Teams = append(hotspot.Teams, team)
+		}
+	}
+}
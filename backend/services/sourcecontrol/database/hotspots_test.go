@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"ems.dev/backend/services/sourcecontrol/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGetFileHotspots(t *testing.T) {
+	repositoryID := "repo-1"
+
+	tests := []struct {
+		name           string
+		params         *types.FileHotspotParams
+		expectedFilter string
+		expectedVars   []interface{}
+	}{
+		{
+			name:         "hotspots of every repository",
+			params:       &types.FileHotspotParams{OrganizationID: "org-1", Limit: 10},
+			expectedVars: []interface{}{"org-1", 10},
+		},
+		{
+			name:           "hotspots of a repository",
+			params:         &types.FileHotspotParams{OrganizationID: "org-1", RepositoryID: &repositoryID, Limit: 10},
+			expectedFilter: "AND pr.repository_id = $2",
+			expectedVars:   []interface{}{"org-1", "repo-1", 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, statements := newDryRunDB(t)
+
+			_, err := db.GetFileHotspots(context.Background(), tt.params)
+			require.ErrorIs(t, err, gorm.ErrDryRunModeUnsupported)
+			require.Len(t, *statements, 1)
+			statement := (*statements)[0]
+
+			// Hotspots are ranked per repository ID and named after the owner and name of the repository
+			assert.Contains(t, statement.sql, "pr.repository_id, r.owner || '/' || r.name as repository_name,")
+			assert.Contains(t, statement.sql, "JOIN repositories r ON r.id = pr.repository_id")
+			assert.Contains(t, statement.sql, "PARTITION BY repository_id ORDER BY")
+			assert.Contains(t, statement.sql, "GROUP BY repository_id, repository_name, path")
+			assert.NotContains(t, statement.sql, "pr.repository_name")
+			if tt.expectedFilter != "" {
+				assert.Contains(t, statement.sql, tt.expectedFilter)
+			} else {
+				assert.NotContains(t, statement.sql, "pr.repository_id =")
+			}
+			assert.Equal(t, tt.expectedVars, statement.vars)
+		})
+	}
+}
+
+func TestGroupHotspotOwners(t *testing.T) {
+	tests := []struct {
+		name         string
+		hotspots     []*types.FileHotspot
+		contributors []types.HotspotContributor
+		teams        []types.HotspotTeam
+		// expected are the usernames and team names of each hotspot
+		expectedContributors [][]string
+		expectedTeams        [][]string
+	}{
+		{
+			name: "hotspots without owners",
+			hotspots: []*types.FileHotspot{
+				{RepositoryID: "repo-1", Path: "main.go"},
+			},
+			expectedContributors: [][]string{{}},
+			expectedTeams:        [][]string{{}},
+		},
+		{
+			name: "owners are grouped by hotspot in their order",
+			hotspots: []*types.FileHotspot{
+				{RepositoryID: "repo-1", Path: "main.go"},
+				{RepositoryID: "repo-2", Path: "main.go"},
+			},
+			contributors: []types.HotspotContributor{
+				{RepositoryID: "repo-1", Path: "main.go", Username: "alice", Changes: 5},
+				{RepositoryID: "repo-2", Path: "main.go", Username: "bob", Changes: 4},
+				{RepositoryID: "repo-1", Path: "main.go", Username: "carol", Changes: 2},
+			},
+			teams: []types.HotspotTeam{
+				{RepositoryID: "repo-2", Path: "main.go", Name: "frontend", Changes: 4},
+				{RepositoryID: "repo-1", Path: "main.go", Name: "backend", Changes: 7},
+				{RepositoryID: "repo-1", Path: "main.go", Name: "platform", Changes: 2},
+			},
+			expectedContributors: [][]string{{"alice", "carol"}, {"bob"}},
+			expectedTeams:        [][]string{{"backend", "platform"}, {"frontend"}},
+		},
+		{
+			name: "repository and path aren't mixed up",
+			hotspots: []*types.FileHotspot{
+				{RepositoryID: "org/api", Path: "main.go"},
+				{RepositoryID: "org", Path: "api/main.go"},
+			},
+			contributors: []types.HotspotContributor{
+				{RepositoryID: "org", Path: "api/main.go", Username: "alice"},
+			},
+			expectedContributors: [][]string{{}, {"alice"}},
+			expectedTeams:        [][]string{{}, {}},
+		},
+		{
+			name: "owners of other files are left out",
+			hotspots: []*types.FileHotspot{
+				{RepositoryID: "repo-1", Path: "main.go"},
+			},
+			contributors: []types.HotspotContributor{
+				{RepositoryID: "repo-1", Path: "go.mod", Username: "alice"},
+			},
+			teams: []types.HotspotTeam{
+				{RepositoryID: "repo-2", Path: "main.go", Name: "frontend"},
+			},
+			expectedContributors: [][]string{{}},
+			expectedTeams:        [][]string{{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groupHotspotOwners(tt.hotspots, tt.contributors, tt.teams)
+
+			contributors := make([][]string, 0, len(tt.hotspots))
+			teams := make([][]string, 0, len(tt.hotspots))
+			for _, hotspot := range tt.hotspots {
+				usernames := []string{}
+				for _, contributor := range hotspot.Contributors {
+					usernames = append(usernames, contributor.Username)
+				}
+				contributors = append(contributors, usernames)
+
+				names := []string{}
+				for _, team := range hotspot.Teams {
+					names = append(names, team.Name)
+				}
+				teams = append(teams, names)
+			}
+			assert.Equal(t, tt.expectedContributors, contributors)
+			assert.Equal(t, tt.expectedTeams, teams)
+		})
+	}
+}
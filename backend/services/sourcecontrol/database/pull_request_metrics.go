@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+
+	"ems.dev/backend/services/sourcecontrol/types"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// GetPullRequestsForMetrics retrieves a page of the pull requests whose metrics are recomputed, ordered by ID
+func (d *SourceControlDB) GetPullRequestsForMetrics(ctx context.Context, params *types.RecomputeMetricsParams) ([]*types.PullRequest, error) {
+	query := d.pullRequestsForMetrics(ctx, params).Order("pull_requests.id")
+	if params.Limit > 0 {
+		query = query.Limit(params.Limit)
+	}
+
+	var prs []*types.PullRequest
+	if err := query.Select("pull_requests.*").Find(&prs).Error; err != nil {
+		return nil, err
+	}
+	return prs, nil
+}
+
+// MarkPullRequestMetricsOutdated resets the metrics version of the selected pull requests to the version of the
+// metrics stored before versioning, the recompute job then rebuilds their metrics. Their update time is left as
+// is. Returns the number of marked pull requests.
+func (d *SourceControlDB) MarkPullRequestMetricsOutdated(ctx context.Context, params *types.RecomputeMetricsParams) (int, error) {
+	selected := d.pullRequestsForMetrics(ctx, params).Select("pull_requests.id")
+
+	result := d.db.WithContext(ctx).Model(&types.PullRequest{}).
+		Where("id IN (?)", selected).
+		UpdateColumn("metrics_version", 0)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
+
+// pullRequestsForMetrics selects the pull requests whose metrics are recomputed
+func (d *SourceControlDB) pullRequestsForMetrics(ctx context.Context, params *types.RecomputeMetricsParams) *gorm.DB {
+	query := d.db.WithContext(ctx).Model(&types.PullRequest{})
+
+	if params.OrganizationID != nil {
+		query = query.Joins("JOIN member_external_accounts sca ON pull_requests.external_account_id = sca.id").
+			Where("sca.organization_id = ?", *params.OrganizationID)
+	}
+	if params.StartDate != nil {
+		query = query.Where("pull_requests.created_at >= ?", *params.StartDate)
+	}
+	if params.EndDate != nil {
+		query = query.Where("pull_requests.created_at <= ?", *params.EndDate)
+	}
+	if params.OutdatedOnly {
+		query = query.Where("pull_requests.metrics_version < ?", types.PullRequestMetricsVersion)
+	}
+	if params.AfterID != "" {
+		query = query.Where("pull_requests.id > ?", params.AfterID)
+	}
+	return query
+}
+
+// GetPullRequestNonBotComments retrieves the comments and reviews of a pull request which weren't written by a bot,
+// ordered by creation
+func (d *SourceControlDB) GetPullRequestNonBotComments(ctx context.Context, prID string) ([]*types.PRComment, error) {
+	var comments []*types.PRComment
+	err := d.db.WithContext(ctx).
+		Joins("JOIN member_external_accounts sca ON pr_comments.external_account_id = sca.id AND NOT sca.is_bot").
+		Where("pr_comments.pr_id = ?", prID).
+		Order("pr_comments.created_at ASC").
+		Select("pr_comments.*").
+		Find(&comments).Error
+	if err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// UpdatePullRequestMetrics stores the metrics of a pull request with the version of their calculation. The update
+// time of the pull request is its provider's and is left as is.
+func (d *SourceControlDB) UpdatePullRequestMetrics(ctx context.Context, prID string, metrics datatypes.JSON, version int) error {
+	return d.db.WithContext(ctx).Model(&types.PullRequest{}).
+		Where("id = ?", prID).
+		UpdateColumns(map[string]interface{}{
+			"metrics":         metrics,
+			"metrics_version": version,
+		}).Error
+}
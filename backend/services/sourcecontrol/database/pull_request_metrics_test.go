@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ems.dev/backend/services/sourcecontrol/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// capturedStatement is the SQL and the args of a statement built without a database
+type capturedStatement struct {
+	sql  string
+	vars []interface{}
+}
+
+// newDryRunDB returns a database which builds its statements without running them, and the statements it built.
+// Subqueries are built as statements of their own, before the statement they are part of. Raw queries reading rows
+// are built but fail with gorm.ErrDryRunModeUnsupported.
+func newDryRunDB(t *testing.T) (*SourceControlDB, *[]capturedStatement) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	require.NoError(t, err)
+
+	statements := []capturedStatement{}
+	capture := func(db *gorm.DB) {
+		statements = append(statements, capturedStatement{sql: db.Statement.SQL.String(), vars: db.Statement.Vars})
+	}
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:capture_query", capture))
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:capture_update", capture))
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:capture_create", capture))
+	require.NoError(t, db.Callback().Row().After("gorm:row").Register("test:capture_row", capture))
+
+	return &SourceControlDB{db: db}, &statements
+}
+
+func TestGetPullRequestsForMetrics(t *testing.T) {
+	orgID := "org-1"
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		params       *types.RecomputeMetricsParams
+		expectedSQL  string
+		expectedVars []interface{}
+	}{
+		{
+			name:        "every pull request",
+			params:      &types.RecomputeMetricsParams{},
+			expectedSQL: `SELECT pull_requests.* FROM "pull_requests" ORDER BY pull_requests.id`,
+		},
+		{
+			name:         "outdated pull requests of a page",
+			params:       &types.RecomputeMetricsParams{OutdatedOnly: true, AfterID: "pr-1", Limit: 50},
+			expectedSQL:  `SELECT pull_requests.* FROM "pull_requests" WHERE pull_requests.metrics_version < $1 AND pull_requests.id > $2 ORDER BY pull_requests.id LIMIT $3`,
+			expectedVars: []interface{}{types.PullRequestMetricsVersion, "pr-1", 50},
+		},
+		{
+			name:         "pull requests of an organization created in a date range",
+			params:       &types.RecomputeMetricsParams{OrganizationID: &orgID, StartDate: &start, EndDate: &end},
+			expectedSQL:  `SELECT pull_requests.* FROM "pull_requests" JOIN member_external_accounts sca ON pull_requests.external_account_id = sca.id WHERE sca.organization_id = $1 AND pull_requests.created_at >= $2 AND pull_requests.created_at <= $3 ORDER BY pull_requests.id`,
+			expectedVars: []interface{}{orgID, start, end},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, statements := newDryRunDB(t)
+
+			_, err := db.GetPullRequestsForMetrics(context.Background(), tt.params)
+			require.NoError(t, err)
+			require.Len(t, *statements, 1)
+			assert.Equal(t, tt.expectedSQL, (*statements)[0].sql)
+			assert.Equal(t, tt.expectedVars, (*statements)[0].vars)
+		})
+	}
+}
+
+func TestMarkPullRequestMetricsOutdated(t *testing.T) {
+	orgID := "org-1"
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	db, statements := newDryRunDB(t)
+
+	_, err := db.MarkPullRequestMetricsOutdated(context.Background(), &types.RecomputeMetricsParams{OrganizationID: &orgID, StartDate: &start})
+	require.NoError(t, err)
+	require.Len(t, *statements, 2)
+	assert.Equal(t, `UPDATE "pull_requests" SET "metrics_version"=$1 WHERE id IN (SELECT pull_requests.id FROM "pull_requests" JOIN member_external_accounts sca ON pull_requests.external_account_id = sca.id WHERE sca.organization_id = $2 AND pull_requests.created_at >= $3)`, (*statements)[1].sql)
+	assert.Equal(t, []interface{}{0, orgID, start}, (*statements)[1].vars)
+}
+
+func TestUpdatePullRequestMetrics(t *testing.T) {
+	db, statements := newDryRunDB(t)
+	metrics := datatypes.JSON(`{"reopened_count":0}`)
+
+	require.NoError(t, db.UpdatePullRequestMetrics(context.Background(), "pr-1", metrics, types.PullRequestMetricsVersion))
+	require.Len(t, *statements, 1)
+	assert.Equal(t, `UPDATE "pull_requests" SET "metrics"=$1,"metrics_version"=$2 WHERE id = $3`, (*statements)[0].sql)
+	assert.Equal(t, []interface{}{string(metrics), types.PullRequestMetricsVersion, "pr-1"}, (*statements)[0].vars)
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"ems.dev/backend/services/sourcecontrol/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// repositoryFullName is the name repository metrics are displayed with, names are only unique per owner
+const repositoryFullName = "r.owner || '/' || r.name"
+
+// UpsertRepository creates a repository or updates the provider metadata of an existing one, the ID of the saved
+// repository is set on it. Repositories saved without their provider metadata, because it could not be fetched,
+// keep the metadata saved before. The owning team is never changed by the sync.
+func (d *SourceControlDB) UpsertRepository(ctx context.Context, repository *types.Repository) error {
+	return d.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: "organization_id"},
+				{Name: "provider"},
+				{Name: "owner"},
+				{Name: "name"},
+			},
+			DoUpdates: clause.Set{
+				{Column: clause.Column{Name: "default_branch"}, Value: gorm.Expr("COALESCE(EXCLUDED.default_branch, repositories.default_branch)")},
+				{Column: clause.Column{Name: "visibility"}, Value: gorm.Expr("COALESCE(EXCLUDED.visibility, repositories.visibility)")},
+				{Column: clause.Column{Name: "archived"}, Value: gorm.Expr("CASE WHEN EXCLUDED.visibility IS NULL THEN repositories.archived ELSE EXCLUDED.archived END")},
+				{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("EXCLUDED.updated_at")},
+			},
+		}).
+		Create(repository).
+		Error
+}
+
+// GetRepositories retrieves the repositories of an organization, ordered by owner and name
+func (d *SourceControlDB) GetRepositories(ctx context.Context, params *types.RepositoryParams) ([]*types.Repository, error) {
+	query := d.db.WithContext(ctx).Where("organization_id = ?", params.OrganizationID)
+	if params.Provider != "" {
+		query = query.Where("provider = ?", params.Provider)
+	}
+	if params.TeamID != nil && *params.TeamID != "" {
+		query = query.Where("team_id = ?", *params.TeamID)
+	}
+	if !params.IncludeArchived {
+		query = query.Where("NOT archived")
+	}
+
+	var repositories []*types.Repository
+	if err := query.Order("owner, name").Find(&repositories).Error; err != nil {
+		return nil, err
+	}
+	return repositories, nil
+}
+
+// GetRepository retrieves a repository of an organization, returns nil if it does not exist
+func (d *SourceControlDB) GetRepository(ctx context.Context, organizationID, repositoryID string) (*types.Repository, error) {
+	var repository types.Repository
+	err := d.db.WithContext(ctx).First(&repository, "id = ? AND organization_id = ?", repositoryID, organizationID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &repository, nil
+}
+
+// UpdateRepositoryTeam sets the team owning a repository, a nil team removes the owner. The team must belong to the
+// organization of the repository, reports whether the repository was updated.
+func (d *SourceControlDB) UpdateRepositoryTeam(ctx context.Context, organizationID, repositoryID string, teamID *string) (bool, error) {
+	query := d.db.WithContext(ctx).
+		Model(&types.Repository{}).
+		Where("id = ? AND organization_id = ?", repositoryID, organizationID)
+	if teamID != nil {
+		query = query.Where("EXISTS (SELECT 1 FROM teams WHERE teams.id = ? AND teams.organization_id = ?)", *teamID, organizationID)
+	}
+
+	result := query.Updates(map[string]interface{}{
+		"team_id":    teamID,
+		"updated_at": gorm.Expr("CURRENT_TIMESTAMP"),
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
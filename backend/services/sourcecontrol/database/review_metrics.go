@@ -0,0 +1,580 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+	"ems.dev/backend/services/sourcecontrol/types"
+)
+
+// postgresDateTrunc maps a metrics interval to its PostgreSQL DATE_TRUNC unit
+func postgresDateTrunc(interval string) string {
+	switch interval {
+	case "daily":
+		return "day"
+	case "weekly":
+		return "week"
+	case "monthly":
+		return "month"
+	}
+	return interval
+}
+
+// scanTimeSeries reads (date, value) rows into a time series with a single data point per date
+func (d *SourceControlDB) scanTimeSeries(ctx context.Context, query string, args []any, key string) ([]types.TimeSeriesEntry, error) {
+	rows, err := d.db.WithContext(ctx).Raw(query, args...).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dataPoints := []types.TimeSeriesEntry{}
+	for rows.Next() {
+		var date time.Time
+		var value float64
+		if err := rows.Scan(&date, &value); err != nil {
+			return nil, err
+		}
+		dataPoints = append(dataPoints, types.TimeSeriesEntry{
+			Date: date.Format("2006-01-02"),
+			Data: []types.TimeSeriesDataPoint{
+				{
+					Key:   key,
+					Value: value,
+				},
+			},
+		})
+	}
+
+	return dataPoints, nil
+}
+
+// scanPeerValue reads the median of the per member values of a query, 0 when there are none
+func (d *SourceControlDB) scanPeerValue(ctx context.Context, query string, args []any) (*float64, error) {
+	var result *float64
+	if err := d.db.WithContext(ctx).Raw(query, args...).Scan(&result).Error; err != nil {
+		return nil, err
+	}
+
+	value := 0.0
+	if result != nil {
+		value = *result
+	}
+
+	return &value, nil
+}
+
+// CalculateApprovalsGiven calculates the number of pull requests the accounts approved
+func (d *SourceControlDB) CalculateApprovalsGiven(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
+	if metricOperation != metrictypes.MetricOperationCount {
+		return nil, fmt.Errorf("invalid metric operation for approvals given: %s", metricOperation)
+	}
+
+	query := `
+		SELECT COUNT(DISTINCT pr.id) as approvals_given_count
+		FROM pull_requests pr
+		WHERE pr.created_at >= ?
+		AND pr.created_at <= ?
+		AND EXISTS (
+			SELECT 1 FROM pr_comments pc
+			JOIN member_external_accounts sca ON pc.external_account_id = sca.id AND NOT sca.is_bot
+			WHERE pc.pr_id = pr.id
+			AND pc.review_state = ?
+			AND sca.organization_id = ?
+			AND sca.id IN ?
+		)
+	`
+
+	var args []any
+	args = append(args, startDate, endDate, types.ReviewStateApproved, organizationID, sourceControlAccountIDs)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	}
+
+	var count int64
+	if err := d.db.WithContext(ctx).Raw(query, args...).Scan(&count).Error; err != nil {
+		return nil, err
+	}
+
+	value := int(count)
+	return &value, nil
+}
+
+// CalculateApprovalsGivenGraph calculates the number of pull requests the accounts approved per interval
+func (d *SourceControlDB) CalculateApprovalsGivenGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+	if metricOperation != metrictypes.MetricOperationCount {
+		return nil, fmt.Errorf("invalid metric operation for approvals given: %s", metricOperation)
+	}
+
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', pr.created_at)"
+	query := `
+		SELECT ` + dateTrunc + ` as date, COUNT(DISTINCT pr.id) as approvals_given_count
+		FROM pull_requests pr
+		WHERE pr.created_at >= ?
+		AND pr.created_at <= ?
+		AND EXISTS (
+			SELECT 1 FROM pr_comments pc
+			JOIN member_external_accounts sca ON pc.external_account_id = sca.id AND NOT sca.is_bot
+			WHERE pc.pr_id = pr.id
+			AND pc.review_state = ?
+			AND sca.organization_id = ?
+			AND sca.id IN ?
+		)
+	`
+
+	var args []any
+	args = append(args, startDate, endDate, types.ReviewStateApproved, organizationID, sourceControlAccountIDs)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	}
+
+	query += " GROUP BY " + dateTrunc + " ORDER BY date"
+
+	return d.scanTimeSeries(ctx, query, args, metricLabel)
+}
+
+// CalculateApprovalsGivenForAccounts calculates the median number of pull requests approved across accounts
+func (d *SourceControlDB) CalculateApprovalsGivenForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	query := `
+		SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_total) as peer_approvals_given
+		FROM (
+			SELECT sca.member_id, COUNT(DISTINCT pr.id) as member_total
+			FROM pull_requests pr
+			JOIN pr_comments pc ON pc.pr_id = pr.id
+			JOIN member_external_accounts sca ON pc.external_account_id = sca.id AND NOT sca.is_bot
+			WHERE sca.organization_id = ?
+			AND pc.review_state = ?
+			AND pr.created_at >= ?
+			AND pr.created_at <= ?
+	`
+
+	var args []any
+	args = append(args, organizationID, types.ReviewStateApproved, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND sca.id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	query += `
+			GROUP BY sca.member_id
+		) member_totals
+	`
+
+	return d.scanPeerValue(ctx, query, args)
+}
+
+// CalculateApprovalsGivenGraphForAccounts calculates the median number of pull requests approved across peers over time
+func (d *SourceControlDB) CalculateApprovalsGivenGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', pr.created_at)"
+	query := `
+		SELECT date, PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_total) as peer_value
+		FROM (
+			SELECT ` + dateTrunc + ` as date, sca.member_id, COUNT(DISTINCT pr.id) as member_total
+			FROM pull_requests pr
+			JOIN pr_comments pc ON pc.pr_id = pr.id
+			JOIN member_external_accounts sca ON pc.external_account_id = sca.id AND NOT sca.is_bot
+			WHERE sca.organization_id = ?
+			AND pc.review_state = ?
+			AND pr.created_at >= ?
+			AND pr.created_at <= ?
+	`
+
+	var args []any
+	args = append(args, organizationID, types.ReviewStateApproved, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND sca.id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	query += `
+			GROUP BY ` + dateTrunc + `, sca.member_id
+		) member_totals
+		GROUP BY date
+		ORDER BY date
+	`
+
+	return d.scanTimeSeries(ctx, query, args, "Peers")
+}
+
+// changesRequestedRate is the percentage of pull requests with at least one "changes requested" review
+const changesRequestedRate = `COALESCE(100.0 * COUNT(*) FILTER (WHERE EXISTS (
+	SELECT 1 FROM pr_comments pc WHERE pc.pr_id = pr.id AND pc.review_state = 'CHANGES_REQUESTED'
+)) / NULLIF(COUNT(*), 0), 0)`
+
+// CalculateChangesRequestedRate calculates the percentage of the accounts' pull requests on which changes were requested
+func (d *SourceControlDB) CalculateChangesRequestedRate(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*float64, error) {
+	if metricOperation != metrictypes.MetricOperationAverage {
+		return nil, fmt.Errorf("invalid metric operation for changes requested rate: %s", metricOperation)
+	}
+
+	query := `
+		SELECT ` + changesRequestedRate + ` as changes_requested_rate
+		FROM pull_requests pr
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+		WHERE sca.organization_id = ?
+		AND pr.created_at >= ?
+		AND pr.created_at <= ?
+	`
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND pr.external_account_id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	var rate float64
+	if err := d.db.WithContext(ctx).Raw(query, args...).Scan(&rate).Error; err != nil {
+		return nil, err
+	}
+
+	return &rate, nil
+}
+
+// CalculateChangesRequestedRateGraph calculates the percentage of the accounts' pull requests on which changes
+// were requested per interval
+func (d *SourceControlDB) CalculateChangesRequestedRateGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+	if metricOperation != metrictypes.MetricOperationAverage {
+		return nil, fmt.Errorf("invalid metric operation for changes requested rate: %s", metricOperation)
+	}
+
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', pr.created_at)"
+	query := `
+		SELECT ` + dateTrunc + ` as date, ` + changesRequestedRate + ` as changes_requested_rate
+		FROM pull_requests pr
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+		WHERE sca.organization_id = ?
+		AND pr.created_at >= ?
+		AND pr.created_at <= ?
+	`
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND pr.external_account_id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	query += " GROUP BY " + dateTrunc + " ORDER BY date"
+
+	return d.scanTimeSeries(ctx, query, args, metricLabel)
+}
+
+// CalculateChangesRequestedRateForAccounts calculates the median changes requested rate across accounts
+func (d *SourceControlDB) CalculateChangesRequestedRateForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	query := `
+		SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_rate) as peer_changes_requested_rate
+		FROM (
+			SELECT sca.member_id, ` + changesRequestedRate + ` as member_rate
+			FROM pull_requests pr
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+			WHERE sca.organization_id = ?
+			AND pr.created_at >= ?
+			AND pr.created_at <= ?
+	`
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND sca.id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	query += `
+			GROUP BY sca.member_id
+		) member_rates
+	`
+
+	return d.scanPeerValue(ctx, query, args)
+}
+
+// CalculateChangesRequestedRateGraphForAccounts calculates the median changes requested rate across peers over time
+func (d *SourceControlDB) CalculateChangesRequestedRateGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', pr.created_at)"
+	query := `
+		SELECT date, PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_rate) as peer_value
+		FROM (
+			SELECT ` + dateTrunc + ` as date, sca.member_id, ` + changesRequestedRate + ` as member_rate
+			FROM pull_requests pr
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+			WHERE sca.organization_id = ?
+			AND pr.created_at >= ?
+			AND pr.created_at <= ?
+	`
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND sca.id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	query += `
+			GROUP BY ` + dateTrunc + `, sca.member_id
+		) member_rates
+		GROUP BY date
+		ORDER BY date
+	`
+
+	return d.scanTimeSeries(ctx, query, args, "Peers")
+}
+
+// mergedWithoutApproval filters merged pull requests without any approval
+const mergedWithoutApproval = `
+		AND pr.merged_at IS NOT NULL
+		AND pr.status = 'closed'
+		AND NOT EXISTS (
+			SELECT 1 FROM pr_comments pc WHERE pc.pr_id = pr.id AND pc.review_state = 'APPROVED'
+		)
+`
+
+// CalculatePRsMergedWithoutApproval calculates the number of the accounts' pull requests merged without any approval
+func (d *SourceControlDB) CalculatePRsMergedWithoutApproval(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
+	if metricOperation != metrictypes.MetricOperationCount {
+		return nil, fmt.Errorf("invalid metric operation for PRs merged without approval: %s", metricOperation)
+	}
+
+	query := `
+		SELECT COUNT(*) as prs_merged_without_approval_count
+		FROM pull_requests pr
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+		WHERE sca.organization_id = ?
+		AND pr.created_at >= ?
+		AND pr.created_at <= ?
+	` + mergedWithoutApproval
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND pr.external_account_id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	var count int64
+	if err := d.db.WithContext(ctx).Raw(query, args...).Scan(&count).Error; err != nil {
+		return nil, err
+	}
+
+	value := int(count)
+	return &value, nil
+}
+
+// CalculatePRsMergedWithoutApprovalGraph calculates the number of the accounts' pull requests merged without any
+// approval per interval
+func (d *SourceControlDB) CalculatePRsMergedWithoutApprovalGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+	if metricOperation != metrictypes.MetricOperationCount {
+		return nil, fmt.Errorf("invalid metric operation for PRs merged without approval: %s", metricOperation)
+	}
+
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', pr.merged_at)"
+	query := `
+		SELECT ` + dateTrunc + ` as date, COUNT(*) as prs_merged_without_approval_count
+		FROM pull_requests pr
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+		WHERE sca.organization_id = ?
+		AND pr.created_at >= ?
+		AND pr.created_at <= ?
+	` + mergedWithoutApproval
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND pr.external_account_id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	query += " GROUP BY " + dateTrunc + " ORDER BY date"
+
+	return d.scanTimeSeries(ctx, query, args, metricLabel)
+}
+
+// CalculatePRsMergedWithoutApprovalForAccounts calculates the median number of pull requests merged without any
+// approval across accounts
+func (d *SourceControlDB) CalculatePRsMergedWithoutApprovalForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	query := `
+		SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_total) as peer_prs_merged_without_approval
+		FROM (
+			SELECT sca.member_id, COUNT(*) as member_total
+			FROM pull_requests pr
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+			WHERE sca.organization_id = ?
+			AND pr.created_at >= ?
+			AND pr.created_at <= ?
+	` + mergedWithoutApproval
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND sca.id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	query += `
+			GROUP BY sca.member_id
+		) member_totals
+	`
+
+	return d.scanPeerValue(ctx, query, args)
+}
+
+// CalculatePRsMergedWithoutApprovalGraphForAccounts calculates the median number of pull requests merged without
+// any approval across peers over time
+func (d *SourceControlDB) CalculatePRsMergedWithoutApprovalGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	dateTrunc := "DATE_TRUNC('" + postgresDateTrunc(interval) + "', pr.merged_at)"
+	query := `
+		SELECT date, PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY member_total) as peer_value
+		FROM (
+			SELECT ` + dateTrunc + ` as date, sca.member_id, COUNT(*) as member_total
+			FROM pull_requests pr
+			JOIN member_external_accounts sca ON pr.external_account_id = sca.id AND NOT sca.is_bot
+			WHERE sca.organization_id = ?
+			AND pr.created_at >= ?
+			AND pr.created_at <= ?
+	` + mergedWithoutApproval
+
+	var args []any
+	args = append(args, organizationID, startDate, endDate)
+
+	// Filter by repository if provided
+	if len(repositoryIDs) > 0 {
+		query += " AND pr.repository_id IN ?"
+		args = append(args, repositoryIDs)
+	}
+
+	// Filter by team if provided, otherwise filter by source control account IDs
+	if len(teamIDs) > 0 {
+		query += " AND pr.team_id IN ?"
+		args = append(args, teamIDs)
+	} else if len(sourceControlAccountIDs) > 0 {
+		query += " AND sca.id IN ?"
+		args = append(args, sourceControlAccountIDs)
+	}
+
+	query += `
+			GROUP BY ` + dateTrunc + `, sca.member_id
+		) member_totals
+		GROUP BY date
+		ORDER BY date
+	`
+
+	return d.scanTimeSeries(ctx, query, args, "Peers")
+}
@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+
+	"ems.dev/backend/services/sourcecontrol/types"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// GetPullRequestAttributions retrieves a page of the pull requests of an organization, ordered by ID, with their
+// changed files and commit messages
+func (d *SourceControlDB) GetPullRequestAttributions(ctx context.Context, params *types.PullRequestAttributionParams) ([]*types.PullRequestAttribution, error) {
+	query := `
+		SELECT pr.id, pr.repository_name, pr.title, COALESCE(pr.head_branch, '') as head_branch, pr.labels, pr.team_id, pr.prefix
+		FROM pull_requests pr
+		JOIN member_external_accounts sca ON pr.external_account_id = sca.id
+		WHERE sca.organization_id = ?
+	`
+
+	args := []interface{}{params.OrganizationID}
+	if params.AfterID != "" {
+		query += " AND pr.id > ?"
+		args = append(args, params.AfterID)
+	}
+	query += " ORDER BY pr.id"
+	if params.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, params.Limit)
+	}
+
+	var rows []struct {
+		ID             string
+		RepositoryName string
+		Title          string
+		HeadBranch     string
+		Labels         datatypes.JSON
+		TeamID         *string
+		Prefix         *string
+	}
+	if err := d.db.WithContext(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return []*types.PullRequestAttribution{}, nil
+	}
+
+	prIDs := make([]string, 0, len(rows))
+	attributions := make([]*types.PullRequestAttribution, 0, len(rows))
+	attributionsByID := make(map[string]*types.PullRequestAttribution, len(rows))
+	for _, row := range rows {
+		attribution := &types.PullRequestAttribution{
+			PRID:           row.ID,
+			RepositoryName: row.RepositoryName,
+			Title:          row.Title,
+			HeadBranch:     row.HeadBranch,
+			TeamID:         row.TeamID,
+			Prefix:         row.Prefix,
+		}
+		if len(row.Labels) > 0 {
+			_ = json.Unmarshal(row.Labels, &attribution.Labels)
+		}
+
+		prIDs = append(prIDs, row.ID)
+		attributions = append(attributions, attribution)
+		attributionsByID[row.ID] = attribution
+	}
+
+	var files []struct {
+		PRID string
+		Path string
+	}
+	if err := d.db.WithContext(ctx).Raw("SELECT pr_id, path FROM pr_files WHERE pr_id IN ?", prIDs).Scan(&files).Error; err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		attributionsByID[file.PRID].Paths = append(attributionsByID[file.PRID].Paths, file.Path)
+	}
+
+	var commits []struct {
+		PRID    string
+		Message string
+	}
+	if err := d.db.WithContext(ctx).Raw("SELECT pr_id, message FROM pr_commits WHERE pr_id IN ? ORDER BY authored_at ASC", prIDs).Scan(&commits).Error; err != nil {
+		return nil, err
+	}
+	for _, commit := range commits {
+		attributionsByID[commit.PRID].CommitMessages = append(attributionsByID[commit.PRID].CommitMessages, commit.Message)
+	}
+
+	return attributions, nil
+}
+
+// UpdatePullRequestTeams writes the team attribution of pull requests, nil teams and prefixes are written as NULL
+func (d *SourceControlDB) UpdatePullRequestTeams(ctx context.Context, teams []*types.PullRequestTeam) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, team := range teams {
+			if err := tx.Model(&types.PullRequest{}).
+				Where("id = ?", team.PRID).
+				Updates(map[string]interface{}{
+					"team_id": team.TeamID,
+					"prefix":  team.Prefix,
+				}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -34,6 +34,18 @@ func NewEngine(sourceControlDB database.DB) *Engine {
 			Name:     "Efficiency",
 			Priority: 3,
 		},
+		"Quality": {
+			Name:     "Quality",
+			Priority: 4,
+		},
+		"DORA": {
+			Name:     "DORA",
+			Priority: 5,
+		},
+		"Build Health": {
+			Name:     "Build Health",
+			Priority: 6,
+		},
 	}
 
 	return &Engine{
@@ -104,6 +116,160 @@ func NewEngine(sourceControlDB database.DB) *Engine {
 				IconIdentifier: "bar-chart-3",
 				IconColor:      "orange",
 			}, sourceControlDB),
+			engine.NewCommitsPerPRRule(metrictypes.BaseMetricRule{
+				ID:             "avg_commits_per_pr",
+				Name:           "Commits per PR",
+				Description:    "Average number of commits of the member's pull requests. Only covers pull requests from providers whose commits are imported. Peer comparison shows the median commits per PR across other organization members.",
+				Unit:           types.UnitCount,
+				Category:       categories["Activity"],
+				Dimension:      metrictypes.MetricDimensionCommitsPerPR,
+				Operation:      metrictypes.MetricOperationAverage,
+				IconIdentifier: "git-commit",
+				IconColor:      "blue",
+			}, sourceControlDB),
+			engine.NewCodingTimeRule(metrictypes.BaseMetricRule{
+				ID:             "median_coding_time",
+				Name:           "Median coding time",
+				Description:    "Median time between the first commit of a pull request and its creation. Pull requests opened before their first commit are left out. Peer comparison shows the median coding time across other organization members.",
+				Unit:           types.UnitSeconds,
+				Category:       categories["Efficiency"],
+				Dimension:      metrictypes.MetricDimensionCodingTime,
+				Operation:      metrictypes.MetricOperationMedian,
+				IconIdentifier: "code",
+				IconColor:      "purple",
+			}, sourceControlDB),
+			engine.NewApprovalsGivenRule(metrictypes.BaseMetricRule{
+				ID:             "approvals_given_count",
+				Name:           "Approvals Given",
+				Description:    "Total number of unique pull requests that the member has approved. This metric counts PRs with at least one review in the APPROVED state from the member. Peer comparison shows the median approvals given across other organization members.",
+				Unit:           types.UnitCount,
+				Category:       categories["Collaboration"],
+				Dimension:      metrictypes.MetricDimensionApprovalsGiven,
+				Operation:      metrictypes.MetricOperationCount,
+				IconIdentifier: "check-circle",
+				IconColor:      "green",
+			}, sourceControlDB),
+			engine.NewChangesRequestedRateRule(metrictypes.BaseMetricRule{
+				ID:             "changes_requested_rate",
+				Name:           "Changes Requested Rate",
+				Description:    "Percentage of the member's pull requests that received at least one review in the CHANGES_REQUESTED state. Peer comparison shows the median changes requested rate across other organization members.",
+				Unit:           types.UnitPercent,
+				Category:       categories["Quality"],
+				Dimension:      metrictypes.MetricDimensionChangesRequested,
+				Operation:      metrictypes.MetricOperationAverage,
+				IconIdentifier: "alert-triangle",
+				IconColor:      "orange",
+			}, sourceControlDB),
+			engine.NewPRsMergedWithoutApprovalRule(metrictypes.BaseMetricRule{
+				ID:             "prs_merged_without_approval_count",
+				Name:           "PRs Merged Without Approval",
+				Description:    "Total number of the member's pull requests that were merged without any review in the APPROVED state. Peer comparison shows the median PRs merged without approval across other organization members.",
+				Unit:           types.UnitCount,
+				Category:       categories["Quality"],
+				Dimension:      metrictypes.MetricDimensionUnapprovedPRs,
+				Operation:      metrictypes.MetricOperationCount,
+				IconIdentifier: "shield-off",
+				IconColor:      "red",
+			}, sourceControlDB),
+			engine.NewCommitsAfterFirstReviewRule(metrictypes.BaseMetricRule{
+				ID:             "avg_commits_after_first_review",
+				Name:           "Commits After First Review",
+				Description:    "Average number of commits pushed to the member's pull requests after their first non-bot review. Only covers reviewed pull requests from providers whose commits are imported. Peer comparison shows the median commits after first review across other organization members.",
+				Unit:           types.UnitCount,
+				Category:       categories["Quality"],
+				Dimension:      metrictypes.MetricDimensionCommitsAfterReview,
+				Operation:      metrictypes.MetricOperationAverage,
+				IconIdentifier: "git-pull-request",
+				IconColor:      "orange",
+			}, sourceControlDB),
+			engine.NewOwnerReviewCoverageRule(metrictypes.BaseMetricRule{
+				ID:             "owner_review_coverage",
+				Name:           "Owner Review Coverage",
+				Description:    "Percentage of the merged pull requests requiring code owner reviews, per the repository's CODEOWNERS file, that were reviewed by all of their code owners. The graph shows the coverage per repository. Peer comparison shows the median owner review coverage across other organization members.",
+				Unit:           types.UnitPercent,
+				Category:       categories["Quality"],
+				Dimension:      metrictypes.MetricDimensionOwnerReviews,
+				Operation:      metrictypes.MetricOperationAverage,
+				IconIdentifier: "shield-check",
+				IconColor:      "green",
+			}, sourceControlDB),
+			engine.NewDeploymentFrequencyRule(metrictypes.BaseMetricRule{
+				ID:             "deployment_frequency",
+				Name:           "Deployment Frequency",
+				Description:    "Total number of successful production deployments, from GitHub deployments or from releases for repositories without deployments. Member and team values count the deployments which shipped their pull requests. Peer comparison shows the median deployments across other organization members.",
+				Unit:           types.UnitCount,
+				Category:       categories["DORA"],
+				Dimension:      metrictypes.MetricDimensionDeployments,
+				Operation:      metrictypes.MetricOperationCount,
+				IconIdentifier: "rocket",
+				IconColor:      "blue",
+			}, sourceControlDB),
+			engine.NewLeadTimeForChangesRule(metrictypes.BaseMetricRule{
+				ID:             "lead_time_for_changes",
+				Name:           "Lead Time for Changes",
+				Description:    "Median time between the merge of a pull request and the successful production deployment shipping it, the first deployment after the merge. Peer comparison shows the median lead time across other organization members.",
+				Unit:           types.UnitSeconds,
+				Category:       categories["DORA"],
+				Dimension:      metrictypes.MetricDimensionLeadTime,
+				Operation:      metrictypes.MetricOperationMedian,
+				IconIdentifier: "clock",
+				IconColor:      "purple",
+			}, sourceControlDB),
+			engine.NewChangeFailureRateRule(metrictypes.BaseMetricRule{
+				ID:             "change_failure_rate",
+				Name:           "Change Failure Rate",
+				Description:    "Percentage of production deployments which ended in a failure or error status. Member and team values cover the deployments which shipped their pull requests. Peer comparison shows the median change failure rate across other organization members.",
+				Unit:           types.UnitPercent,
+				Category:       categories["DORA"],
+				Dimension:      metrictypes.MetricDimensionFailedDeployments,
+				Operation:      metrictypes.MetricOperationAverage,
+				IconIdentifier: "x-circle",
+				IconColor:      "red",
+			}, sourceControlDB),
+			engine.NewTimeToRestoreRule(metrictypes.BaseMetricRule{
+				ID:             "time_to_restore",
+				Name:           "Time to Restore Service",
+				Description:    "Median time between a failed production deployment and the next successful deployment to the same repository and environment. Consecutive failures count as a single incident. Peer comparison shows the median time to restore across other organization members.",
+				Unit:           types.UnitSeconds,
+				Category:       categories["DORA"],
+				Dimension:      metrictypes.MetricDimensionTimeToRestore,
+				Operation:      metrictypes.MetricOperationMedian,
+				IconIdentifier: "refresh-cw",
+				IconColor:      "orange",
+			}, sourceControlDB),
+			engine.NewCIDurationRule(metrictypes.BaseMetricRule{
+				ID:             "ci_duration",
+				Name:           "CI Duration",
+				Description:    "Median duration of the CI runs of pull requests, from GitHub Actions workflow runs and check runs which finished with a success or a failure. The graph shows a series per repository. Peer comparison shows the median CI duration across other organization members.",
+				Unit:           types.UnitSeconds,
+				Category:       categories["Build Health"],
+				Dimension:      metrictypes.MetricDimensionCIDuration,
+				Operation:      metrictypes.MetricOperationMedian,
+				IconIdentifier: "timer",
+				IconColor:      "blue",
+			}, sourceControlDB),
+			engine.NewCIFailureRateRule(metrictypes.BaseMetricRule{
+				ID:             "ci_failure_rate",
+				Name:           "CI Failure Rate",
+				Description:    "Percentage of failed CI run attempts on pull requests, each attempt of a re-run counts with its own conclusion. The graph shows a series per repository. Peer comparison shows the median CI failure rate across other organization members.",
+				Unit:           types.UnitPercent,
+				Category:       categories["Build Health"],
+				Dimension:      metrictypes.MetricDimensionCIFailures,
+				Operation:      metrictypes.MetricOperationAverage,
+				IconIdentifier: "alert-triangle",
+				IconColor:      "red",
+			}, sourceControlDB),
+			engine.NewCheckWaitTimeRule(metrictypes.BaseMetricRule{
+				ID:             "check_wait_time",
+				Name:           "Time Waiting on Checks",
+				Description:    "Median time pull requests spent waiting on checks. For every pushed commit, the time from the start of its first CI run to the end of its last one. The graph shows a series per repository. Peer comparison shows the median wait time across other organization members.",
+				Unit:           types.UnitSeconds,
+				Category:       categories["Build Health"],
+				Dimension:      metrictypes.MetricDimensionCheckWaitTime,
+				Operation:      metrictypes.MetricOperationMedian,
+				IconIdentifier: "hourglass",
+				IconColor:      "orange",
+			}, sourceControlDB),
 		},
 		sourceControlDB: sourceControlDB,
 	}
@@ -0,0 +1,20 @@
+package engine
+
+import (
+	"ems.dev/backend/services/sourcecontrol/database"
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+)
+
+type ApprovalsGivenRule struct {
+	queryMetricRule[int]
+}
+
+func NewApprovalsGivenRule(baseMetricRule metrictypes.BaseMetricRule, sourceControlDB database.DB) *ApprovalsGivenRule {
+	return &ApprovalsGivenRule{queryMetricRule[int]{
+		BaseMetricRule: baseMetricRule,
+		value:          sourceControlDB.CalculateApprovalsGiven,
+		graph:          sourceControlDB.CalculateApprovalsGivenGraph,
+		peersValue:     sourceControlDB.CalculateApprovalsGivenForAccounts,
+		peersGraph:     sourceControlDB.CalculateApprovalsGivenGraphForAccounts,
+	}}
+}
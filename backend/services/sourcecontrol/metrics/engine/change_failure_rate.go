@@ -0,0 +1,20 @@
+package engine
+
+import (
+	"ems.dev/backend/services/sourcecontrol/database"
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+)
+
+type ChangeFailureRateRule struct {
+	queryMetricRule[float64]
+}
+
+func NewChangeFailureRateRule(baseMetricRule metrictypes.BaseMetricRule, sourceControlDB database.DB) *ChangeFailureRateRule {
+	return &ChangeFailureRateRule{queryMetricRule[float64]{
+		BaseMetricRule: baseMetricRule,
+		value:          sourceControlDB.CalculateChangeFailureRate,
+		graph:          sourceControlDB.CalculateChangeFailureRateGraph,
+		peersValue:     sourceControlDB.CalculateChangeFailureRateForAccounts,
+		peersGraph:     sourceControlDB.CalculateChangeFailureRateGraphForAccounts,
+	}}
+}
@@ -0,0 +1,20 @@
+package engine
+
+import (
+	"ems.dev/backend/services/sourcecontrol/database"
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+)
+
+type ChangesRequestedRateRule struct {
+	queryMetricRule[float64]
+}
+
+func NewChangesRequestedRateRule(baseMetricRule metrictypes.BaseMetricRule, sourceControlDB database.DB) *ChangesRequestedRateRule {
+	return &ChangesRequestedRateRule{queryMetricRule[float64]{
+		BaseMetricRule: baseMetricRule,
+		value:          sourceControlDB.CalculateChangesRequestedRate,
+		graph:          sourceControlDB.CalculateChangesRequestedRateGraph,
+		peersValue:     sourceControlDB.CalculateChangesRequestedRateForAccounts,
+		peersGraph:     sourceControlDB.CalculateChangesRequestedRateGraphForAccounts,
+	}}
+}
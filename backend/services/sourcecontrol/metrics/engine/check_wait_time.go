@@ -0,0 +1,20 @@
+package engine
+
+import (
+	"ems.dev/backend/services/sourcecontrol/database"
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+)
+
+type CheckWaitTimeRule struct {
+	queryMetricRule[int]
+}
+
+func NewCheckWaitTimeRule(baseMetricRule metrictypes.BaseMetricRule, sourceControlDB database.DB) *CheckWaitTimeRule {
+	return &CheckWaitTimeRule{queryMetricRule[int]{
+		BaseMetricRule: baseMetricRule,
+		value:          sourceControlDB.CalculateCheckWaitTime,
+		graph:          graphPerRepository(sourceControlDB.CalculateCheckWaitTimeGraph),
+		peersValue:     sourceControlDB.CalculateCheckWaitTimeForAccounts,
+		peersGraph:     sourceControlDB.CalculateCheckWaitTimeGraphForAccounts,
+	}}
+}
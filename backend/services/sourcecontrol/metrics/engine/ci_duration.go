@@ -0,0 +1,20 @@
+package engine
+
+import (
+	"ems.dev/backend/services/sourcecontrol/database"
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+)
+
+type CIDurationRule struct {
+	queryMetricRule[int]
+}
+
+func NewCIDurationRule(baseMetricRule metrictypes.BaseMetricRule, sourceControlDB database.DB) *CIDurationRule {
+	return &CIDurationRule{queryMetricRule[int]{
+		BaseMetricRule: baseMetricRule,
+		value:          sourceControlDB.CalculateCIDuration,
+		graph:          graphPerRepository(sourceControlDB.CalculateCIDurationGraph),
+		peersValue:     sourceControlDB.CalculateCIDurationForAccounts,
+		peersGraph:     sourceControlDB.CalculateCIDurationGraphForAccounts,
+	}}
+}
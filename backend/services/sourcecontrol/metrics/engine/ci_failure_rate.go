@@ -0,0 +1,20 @@
+package engine
+
+import (
+	"ems.dev/backend/services/sourcecontrol/database"
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+)
+
+type CIFailureRateRule struct {
+	queryMetricRule[float64]
+}
+
+func NewCIFailureRateRule(baseMetricRule metrictypes.BaseMetricRule, sourceControlDB database.DB) *CIFailureRateRule {
+	return &CIFailureRateRule{queryMetricRule[float64]{
+		BaseMetricRule: baseMetricRule,
+		value:          sourceControlDB.CalculateCIFailureRate,
+		graph:          graphPerRepository(sourceControlDB.CalculateCIFailureRateGraph),
+		peersValue:     sourceControlDB.CalculateCIFailureRateForAccounts,
+		peersGraph:     sourceControlDB.CalculateCIFailureRateGraphForAccounts,
+	}}
+}
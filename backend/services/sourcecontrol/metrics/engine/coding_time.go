@@ -0,0 +1,20 @@
+package engine
+
+import (
+	"ems.dev/backend/services/sourcecontrol/database"
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+)
+
+type CodingTimeRule struct {
+	queryMetricRule[int]
+}
+
+func NewCodingTimeRule(baseMetricRule metrictypes.BaseMetricRule, sourceControlDB database.DB) *CodingTimeRule {
+	return &CodingTimeRule{queryMetricRule[int]{
+		BaseMetricRule: baseMetricRule,
+		value:          sourceControlDB.CalculateCodingTime,
+		graph:          sourceControlDB.CalculateCodingTimeGraph,
+		peersValue:     sourceControlDB.CalculateCodingTimeForAccounts,
+		peersGraph:     sourceControlDB.CalculateCodingTimeGraphForAccounts,
+	}}
+}
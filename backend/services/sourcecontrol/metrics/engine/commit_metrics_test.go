@@ -0,0 +1,39 @@
+package engine
+
+import (
+	"testing"
+
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+)
+
+func TestCommitMetricRules(t *testing.T) {
+	testMetricRules(t, []metricRuleTest{
+		{
+			name: "commits per PR",
+			rule: func(base metrictypes.BaseMetricRule, db *MockSourceControlDB) metrictypes.MetricRule {
+				return NewCommitsPerPRRule(base, db)
+			},
+			method:    "CalculateCommitsPerPR",
+			operation: metrictypes.MetricOperationAverage,
+			value:     floatPtr(4),
+		},
+		{
+			name: "coding time",
+			rule: func(base metrictypes.BaseMetricRule, db *MockSourceControlDB) metrictypes.MetricRule {
+				return NewCodingTimeRule(base, db)
+			},
+			method:    "CalculateCodingTime",
+			operation: metrictypes.MetricOperationMedian,
+			value:     intPtr(4),
+		},
+		{
+			name: "commits after first review",
+			rule: func(base metrictypes.BaseMetricRule, db *MockSourceControlDB) metrictypes.MetricRule {
+				return NewCommitsAfterFirstReviewRule(base, db)
+			},
+			method:    "CalculateCommitsAfterFirstReview",
+			operation: metrictypes.MetricOperationAverage,
+			value:     floatPtr(4),
+		},
+	})
+}
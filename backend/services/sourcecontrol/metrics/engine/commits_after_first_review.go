@@ -0,0 +1,20 @@
+package engine
+
+import (
+	"ems.dev/backend/services/sourcecontrol/database"
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+)
+
+type CommitsAfterFirstReviewRule struct {
+	queryMetricRule[float64]
+}
+
+func NewCommitsAfterFirstReviewRule(baseMetricRule metrictypes.BaseMetricRule, sourceControlDB database.DB) *CommitsAfterFirstReviewRule {
+	return &CommitsAfterFirstReviewRule{queryMetricRule[float64]{
+		BaseMetricRule: baseMetricRule,
+		value:          sourceControlDB.CalculateCommitsAfterFirstReview,
+		graph:          sourceControlDB.CalculateCommitsAfterFirstReviewGraph,
+		peersValue:     sourceControlDB.CalculateCommitsAfterFirstReviewForAccounts,
+		peersGraph:     sourceControlDB.CalculateCommitsAfterFirstReviewGraphForAccounts,
+	}}
+}
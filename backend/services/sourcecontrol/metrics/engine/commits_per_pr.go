@@ -0,0 +1,20 @@
+package engine
+
+import (
+	"ems.dev/backend/services/sourcecontrol/database"
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+)
+
+type CommitsPerPRRule struct {
+	queryMetricRule[float64]
+}
+
+func NewCommitsPerPRRule(baseMetricRule metrictypes.BaseMetricRule, sourceControlDB database.DB) *CommitsPerPRRule {
+	return &CommitsPerPRRule{queryMetricRule[float64]{
+		BaseMetricRule: baseMetricRule,
+		value:          sourceControlDB.CalculateCommitsPerPR,
+		graph:          sourceControlDB.CalculateCommitsPerPRGraph,
+		peersValue:     sourceControlDB.CalculateCommitsPerPRForAccounts,
+		peersGraph:     sourceControlDB.CalculateCommitsPerPRGraphForAccounts,
+	}}
+}
@@ -0,0 +1,20 @@
+package engine
+
+import (
+	"ems.dev/backend/services/sourcecontrol/database"
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+)
+
+type DeploymentFrequencyRule struct {
+	queryMetricRule[int]
+}
+
+func NewDeploymentFrequencyRule(baseMetricRule metrictypes.BaseMetricRule, sourceControlDB database.DB) *DeploymentFrequencyRule {
+	return &DeploymentFrequencyRule{queryMetricRule[int]{
+		BaseMetricRule: baseMetricRule,
+		value:          sourceControlDB.CalculateDeploymentFrequency,
+		graph:          sourceControlDB.CalculateDeploymentFrequencyGraph,
+		peersValue:     sourceControlDB.CalculateDeploymentFrequencyForAccounts,
+		peersGraph:     sourceControlDB.CalculateDeploymentFrequencyGraphForAccounts,
+	}}
+}
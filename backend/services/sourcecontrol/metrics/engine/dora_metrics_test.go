@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"testing"
+
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+)
+
+func TestDORAMetricRules(t *testing.T) {
+	testMetricRules(t, []metricRuleTest{
+		{
+			name: "deployment frequency",
+			rule: func(base metrictypes.BaseMetricRule, db *MockSourceControlDB) metrictypes.MetricRule {
+				return NewDeploymentFrequencyRule(base, db)
+			},
+			method:    "CalculateDeploymentFrequency",
+			operation: metrictypes.MetricOperationCount,
+			value:     intPtr(4),
+		},
+		{
+			name: "lead time for changes",
+			rule: func(base metrictypes.BaseMetricRule, db *MockSourceControlDB) metrictypes.MetricRule {
+				return NewLeadTimeForChangesRule(base, db)
+			},
+			method:    "CalculateLeadTimeForChanges",
+			operation: metrictypes.MetricOperationMedian,
+			value:     intPtr(4),
+		},
+		{
+			name: "change failure rate",
+			rule: func(base metrictypes.BaseMetricRule, db *MockSourceControlDB) metrictypes.MetricRule {
+				return NewChangeFailureRateRule(base, db)
+			},
+			method:    "CalculateChangeFailureRate",
+			operation: metrictypes.MetricOperationAverage,
+			value:     floatPtr(4),
+		},
+		{
+			name: "time to restore",
+			rule: func(base metrictypes.BaseMetricRule, db *MockSourceControlDB) metrictypes.MetricRule {
+				return NewTimeToRestoreRule(base, db)
+			},
+			method:    "CalculateTimeToRestore",
+			operation: metrictypes.MetricOperationMedian,
+			value:     intPtr(4),
+		},
+	})
+}
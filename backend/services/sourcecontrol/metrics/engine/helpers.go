@@ -1,6 +1,13 @@
 package engine
 
-import "ems.dev/backend/services/sourcecontrol/types"
+import (
+	"encoding/json"
+	"time"
+
+	"ems.dev/backend/libraries/errors"
+	"ems.dev/backend/services/sourcecontrol/types"
+	"github.com/google/uuid"
+)
 
 // mergeTimeSeriesWithPeers merges member time series data with peer values by date
 func mergeTimeSeriesWithPeers(memberSeries []types.TimeSeriesEntry, peerSeries []types.TimeSeriesEntry, memberLabel string) []types.TimeSeriesEntry {
@@ -37,3 +44,103 @@ func mergeTimeSeriesWithPeers(memberSeries []types.TimeSeriesEntry, peerSeries [
 	return merged
 }
 
+// extractMetricRuleParams validates the metric rule params and extracts the organization, date range, accounts, teams
+// and repositories
+func extractMetricRuleParams(params types.MetricRuleParams) (*string, *time.Time, *time.Time, []string, []string, []string, []string, error) {
+	if params.Interval == "" {
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("interval is required")
+	}
+
+	if params.Interval != "daily" && params.Interval != "weekly" && params.Interval != "monthly" {
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid interval")
+	}
+
+	if params.StartDate == nil {
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("start date is required")
+	}
+
+	if params.EndDate == nil {
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("end date is required")
+	}
+
+	if params.MetricParams == nil {
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("metric params is required")
+	}
+
+	// Unmarshal MetricParams to check for organization ID
+	var metricParams map[string]interface{}
+	if err := json.Unmarshal(params.MetricParams, &metricParams); err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid metric params format")
+	}
+
+	// Check if organization ID is present, this is needed also a security measure to prevent unauthorized access to other organizations
+	orgID, exists := metricParams["organizationId"]
+	if !exists {
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("organization id is required")
+	}
+
+	organizationID, ok := orgID.(string)
+	if !ok {
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid organization id format")
+	}
+
+	// If sourcecontrolaccountids is present check that these are valid uuids
+	srcControlAccountIDs, exists := metricParams["sourceControlAccountIDs"]
+	var sourceControlAccountIDs []string
+	if exists {
+		if srcControlAccountIDsArray, ok := srcControlAccountIDs.([]interface{}); ok {
+			for _, idInterface := range srcControlAccountIDsArray {
+				if id, ok := idInterface.(string); ok {
+					if _, err := uuid.Parse(id); err != nil {
+						return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid source control account id")
+					}
+					sourceControlAccountIDs = append(sourceControlAccountIDs, id)
+				}
+			}
+		}
+	}
+
+	// If peerssourcecontrolaccountids is present check that these are valid uuids
+	peersSrcControlAccountIDs, exists := metricParams["peersSourceControlAccountIDs"]
+	var peersSourceControlAccountIDs []string
+	if exists {
+		if peersSrcControlAccountIDsArray, ok := peersSrcControlAccountIDs.([]interface{}); ok {
+			for _, idInterface := range peersSrcControlAccountIDsArray {
+				if id, ok := idInterface.(string); ok {
+					if _, err := uuid.Parse(id); err != nil {
+						return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid peers source control account id")
+					}
+					peersSourceControlAccountIDs = append(peersSourceControlAccountIDs, id)
+				}
+			}
+		}
+	}
+
+	// Extract team_ids if present
+	teamIDsInterface, exists := metricParams["team_ids"]
+	var teamIDs []string
+	if exists {
+		if teamIDsArray, ok := teamIDsInterface.([]interface{}); ok {
+			for _, teamIDInterface := range teamIDsArray {
+				if teamID, ok := teamIDInterface.(string); ok {
+					teamIDs = append(teamIDs, teamID)
+				}
+			}
+		}
+	}
+
+	// Extract repository_ids if present
+	repositoryIDsInterface, exists := metricParams["repository_ids"]
+	var repositoryIDs []string
+	if exists {
+		if repositoryIDsArray, ok := repositoryIDsInterface.([]interface{}); ok {
+			for _, repositoryIDInterface := range repositoryIDsArray {
+				if repositoryID, ok := repositoryIDInterface.(string); ok {
+					repositoryIDs = append(repositoryIDs, repositoryID)
+				}
+			}
+		}
+	}
+
+	return &organizationID, params.StartDate, params.EndDate, sourceControlAccountIDs, peersSourceControlAccountIDs, teamIDs, repositoryIDs, nil
+}
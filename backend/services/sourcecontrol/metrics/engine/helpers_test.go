@@ -0,0 +1,235 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+	"ems.dev/backend/services/sourcecontrol/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+const (
+	testOrganizationID = "org-1"
+	testAccountID      = "11111111-1111-1111-1111-111111111111"
+	testPeerAccountID  = "22222222-2222-2222-2222-222222222222"
+)
+
+var (
+	testStartDate = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	testEndDate   = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+// newMetricRuleParams returns weekly metric rule params of the test organization with the given metric params
+func newMetricRuleParams(t *testing.T, metricParams map[string]interface{}) types.MetricRuleParams {
+	params := map[string]interface{}{"organizationId": testOrganizationID}
+	for key, value := range metricParams {
+		params[key] = value
+	}
+
+	raw, err := json.Marshal(params)
+	require.NoError(t, err)
+
+	startDate, endDate := testStartDate, testEndDate
+	return types.MetricRuleParams{
+		MetricParams: datatypes.JSON(raw),
+		StartDate:    &startDate,
+		EndDate:      &endDate,
+		Interval:     "weekly",
+	}
+}
+
+// series returns a time series with one data point per date
+func series(key string, values map[string]float64) []types.TimeSeriesEntry {
+	entries := []types.TimeSeriesEntry{}
+	for _, date := range []string{"2024-01-01", "2024-01-08", "2024-01-15"} {
+		if value, ok := values[date]; ok {
+			entries = append(entries, types.TimeSeriesEntry{Date: date, Data: []types.TimeSeriesDataPoint{{Key: key, Value: value}}})
+		}
+	}
+	return entries
+}
+
+func TestExtractMetricRuleParams(t *testing.T) {
+	valid := newMetricRuleParams(t, map[string]interface{}{
+		"sourceControlAccountIDs":      []string{testAccountID},
+		"peersSourceControlAccountIDs": []string{testPeerAccountID},
+		"team_ids":                     []string{"team-1"},
+		"repository_ids":               []string{"repo-1", "repo-2"},
+	})
+
+	tests := []struct {
+		name          string
+		params        func() types.MetricRuleParams
+		expectedError string
+	}{
+		{name: "valid", params: func() types.MetricRuleParams { return valid }},
+		{name: "missing interval", params: func() types.MetricRuleParams { p := valid; p.Interval = ""; return p }, expectedError: "interval is required"},
+		{name: "invalid interval", params: func() types.MetricRuleParams { p := valid; p.Interval = "hourly"; return p }, expectedError: "invalid interval"},
+		{name: "missing start date", params: func() types.MetricRuleParams { p := valid; p.StartDate = nil; return p }, expectedError: "start date is required"},
+		{name: "missing end date", params: func() types.MetricRuleParams { p := valid; p.EndDate = nil; return p }, expectedError: "end date is required"},
+		{name: "missing metric params", params: func() types.MetricRuleParams { p := valid; p.MetricParams = nil; return p }, expectedError: "metric params is required"},
+		{name: "invalid metric params", params: func() types.MetricRuleParams { p := valid; p.MetricParams = datatypes.JSON(`[]`); return p }, expectedError: "invalid metric params format"},
+		{name: "missing organization", params: func() types.MetricRuleParams { p := valid; p.MetricParams = datatypes.JSON(`{}`); return p }, expectedError: "organization id is required"},
+		{name: "invalid organization", params: func() types.MetricRuleParams {
+			p := valid
+			p.MetricParams = datatypes.JSON(`{"organizationId": 1}`)
+			return p
+		}, expectedError: "invalid organization id format"},
+		{
+			name: "invalid account id",
+			params: func() types.MetricRuleParams {
+				return newMetricRuleParams(t, map[string]interface{}{"sourceControlAccountIDs": []string{"not-a-uuid"}})
+			},
+			expectedError: "invalid source control account id",
+		},
+		{
+			name: "invalid peer account id",
+			params: func() types.MetricRuleParams {
+				return newMetricRuleParams(t, map[string]interface{}{"peersSourceControlAccountIDs": []string{"not-a-uuid"}})
+			},
+			expectedError: "invalid peers source control account id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			organizationID, startDate, endDate, accountIDs, peerAccountIDs, teamIDs, repositoryIDs, err := extractMetricRuleParams(tt.params())
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, testOrganizationID, *organizationID)
+			assert.Equal(t, testStartDate, *startDate)
+			assert.Equal(t, testEndDate, *endDate)
+			assert.Equal(t, []string{testAccountID}, accountIDs)
+			assert.Equal(t, []string{testPeerAccountID}, peerAccountIDs)
+			assert.Equal(t, []string{"team-1"}, teamIDs)
+			assert.Equal(t, []string{"repo-1", "repo-2"}, repositoryIDs)
+		})
+	}
+}
+
+func TestMergeTimeSeriesWithPeers(t *testing.T) {
+	member := series("Approvals", map[string]float64{"2024-01-01": 1, "2024-01-08": 2})
+	peers := series("Peers", map[string]float64{"2024-01-08": 3, "2024-01-15": 4})
+
+	expected := []types.TimeSeriesEntry{
+		{Date: "2024-01-01", Data: []types.TimeSeriesDataPoint{{Key: "Approvals", Value: 1}}},
+		{Date: "2024-01-08", Data: []types.TimeSeriesDataPoint{{Key: "Approvals", Value: 2}, {Key: "Peers", Value: 3}}},
+	}
+	assert.Equal(t, expected, mergeTimeSeriesWithPeers(member, peers, "Approvals"))
+}
+
+// metricRuleTest is a metric rule calculated from the four database calculations shared by the member and peer
+// metric rules
+type metricRuleTest struct {
+	name string
+	rule func(base metrictypes.BaseMetricRule, db *MockSourceControlDB) metrictypes.MetricRule
+	// method is the name of the database calculation, suffixed with Graph, ForAccounts and GraphForAccounts
+	// for the time series and the peers
+	method    string
+	operation metrictypes.MetricOperation
+	value     interface{}
+}
+
+// testMetricRules tests the calculation of metric rules for teams and for members compared with their peers
+func testMetricRules(t *testing.T, tests []metricRuleTest) {
+	memberSeries := series("metric", map[string]float64{"2024-01-01": 1, "2024-01-08": 3})
+	peerSeries := series("Peers", map[string]float64{"2024-01-01": 2})
+
+	for _, tt := range tests {
+		newRule := func(db *MockSourceControlDB) metrictypes.MetricRule {
+			return tt.rule(metrictypes.BaseMetricRule{Name: tt.name, Unit: types.UnitCount, Operation: tt.operation}, db)
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Run("team metrics have no peers", func(t *testing.T) {
+				db := &MockSourceControlDB{}
+				params := newMetricRuleParams(t, map[string]interface{}{"team_ids": []string{"team-1"}})
+
+				db.On(tt.method, mock.Anything, testOrganizationID, []string(nil), []string{"team-1"}, []string(nil), testStartDate, testEndDate, tt.operation).Return(tt.value, nil)
+				db.On(tt.method+"Graph", mock.Anything, testOrganizationID, []string(nil), []string{"team-1"}, []string(nil), testStartDate, testEndDate, tt.operation, tt.name, "weekly").Return(memberSeries, nil)
+
+				snapshot, graph, err := newRule(db).Calculate(context.Background(), params)
+				require.NoError(t, err)
+				assert.Equal(t, float64(4), snapshot.Value)
+				assert.Equal(t, float64(0), snapshot.PeersValue)
+				assert.Equal(t, memberSeries, graph.TimeSeries)
+				db.AssertNotCalled(t, tt.method+"ForAccounts", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			})
+
+			t.Run("member metrics are compared with their peers", func(t *testing.T) {
+				db := &MockSourceControlDB{}
+				params := newMetricRuleParams(t, map[string]interface{}{
+					"sourceControlAccountIDs":      []string{testAccountID},
+					"peersSourceControlAccountIDs": []string{testPeerAccountID},
+				})
+
+				db.On(tt.method, mock.Anything, testOrganizationID, []string{testAccountID}, []string(nil), []string(nil), testStartDate, testEndDate, tt.operation).Return(tt.value, nil)
+				db.On(tt.method+"Graph", mock.Anything, testOrganizationID, []string{testAccountID}, []string(nil), []string(nil), testStartDate, testEndDate, tt.operation, tt.name, "weekly").Return(memberSeries, nil)
+				db.On(tt.method+"ForAccounts", mock.Anything, testOrganizationID, []string{testPeerAccountID}, []string(nil), []string(nil), testStartDate, testEndDate).Return(floatPtr(2.5), nil)
+				db.On(tt.method+"GraphForAccounts", mock.Anything, testOrganizationID, []string{testPeerAccountID}, []string(nil), []string(nil), testStartDate, testEndDate, "weekly").Return(peerSeries, nil)
+
+				snapshot, graph, err := newRule(db).Calculate(context.Background(), params)
+				require.NoError(t, err)
+				assert.Equal(t, float64(4), snapshot.Value)
+				assert.Equal(t, 2.5, snapshot.PeersValue)
+				assert.Equal(t, []types.TimeSeriesEntry{
+					{Date: "2024-01-01", Data: []types.TimeSeriesDataPoint{{Key: "metric", Value: 1}, {Key: "Peers", Value: 2}}},
+					{Date: "2024-01-08", Data: []types.TimeSeriesDataPoint{{Key: "metric", Value: 3}}},
+				}, graph.TimeSeries)
+			})
+
+			t.Run("database errors are returned", func(t *testing.T) {
+				db := &MockSourceControlDB{}
+				db.On(tt.method, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+
+				_, _, err := newRule(db).Calculate(context.Background(), newMetricRuleParams(t, nil))
+				assert.EqualError(t, err, "database error")
+			})
+
+			t.Run("invalid params are rejected", func(t *testing.T) {
+				params := newMetricRuleParams(t, nil)
+				params.Interval = ""
+
+				_, _, err := newRule(&MockSourceControlDB{}).Calculate(context.Background(), params)
+				assert.EqualError(t, err, "interval is required")
+			})
+		})
+	}
+}
+
+func TestGraphPerRepository(t *testing.T) {
+	repositorySeries := series("acme/api", map[string]float64{"2024-01-01": 1})
+	graph := graphPerRepository(func(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, interval string) ([]types.TimeSeriesEntry, error) {
+		assert.Equal(t, testOrganizationID, organizationID)
+		assert.Equal(t, []string{testAccountID}, sourceControlAccountIDs)
+		assert.Equal(t, []string{"team-1"}, teamIDs)
+		assert.Equal(t, []string{"repo-1"}, repositoryIDs)
+		assert.Equal(t, metrictypes.MetricOperationAverage, metricOperation)
+		assert.Equal(t, "weekly", interval)
+		return repositorySeries, nil
+	})
+
+	// The series are labelled by repository, the metric's name is dropped
+	timeSeries, err := graph(context.Background(), testOrganizationID, []string{testAccountID}, []string{"team-1"}, []string{"repo-1"}, testStartDate, testEndDate, metrictypes.MetricOperationAverage, "CI duration", "weekly")
+	require.NoError(t, err)
+	assert.Equal(t, repositorySeries, timeSeries)
+}
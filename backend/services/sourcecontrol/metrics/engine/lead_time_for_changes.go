@@ -0,0 +1,20 @@
+package engine
+
+import (
+	"ems.dev/backend/services/sourcecontrol/database"
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+)
+
+type LeadTimeForChangesRule struct {
+	queryMetricRule[int]
+}
+
+func NewLeadTimeForChangesRule(baseMetricRule metrictypes.BaseMetricRule, sourceControlDB database.DB) *LeadTimeForChangesRule {
+	return &LeadTimeForChangesRule{queryMetricRule[int]{
+		BaseMetricRule: baseMetricRule,
+		value:          sourceControlDB.CalculateLeadTimeForChanges,
+		graph:          sourceControlDB.CalculateLeadTimeForChangesGraph,
+		peersValue:     sourceControlDB.CalculateLeadTimeForChangesForAccounts,
+		peersGraph:     sourceControlDB.CalculateLeadTimeForChangesGraphForAccounts,
+	}}
+}
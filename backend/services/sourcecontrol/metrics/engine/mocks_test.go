@@ -0,0 +1,199 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"ems.dev/backend/services/sourcecontrol/database"
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+	"ems.dev/backend/services/sourcecontrol/types"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSourceControlDB is a mock of the source control database, only the calculations of the tested metric rules
+// are implemented
+type MockSourceControlDB struct {
+	database.DB
+	mock.Mock
+}
+
+func intResult(args mock.Arguments) (*int, error) {
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*int), args.Error(1)
+}
+
+func floatResult(args mock.Arguments) (*float64, error) {
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*float64), args.Error(1)
+}
+
+func seriesResult(args mock.Arguments) ([]types.TimeSeriesEntry, error) {
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.TimeSeriesEntry), args.Error(1)
+}
+
+func (m *MockSourceControlDB) CalculateApprovalsGiven(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
+	return intResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricOperation))
+}
+
+func (m *MockSourceControlDB) CalculateApprovalsGivenGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+	return seriesResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricOperation, metricLabel, interval))
+}
+
+func (m *MockSourceControlDB) CalculateApprovalsGivenForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	return floatResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate))
+}
+
+func (m *MockSourceControlDB) CalculateApprovalsGivenGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	return seriesResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, interval))
+}
+
+func (m *MockSourceControlDB) CalculateChangesRequestedRate(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*float64, error) {
+	return floatResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricOperation))
+}
+
+func (m *MockSourceControlDB) CalculateChangesRequestedRateGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+	return seriesResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricOperation, metricLabel, interval))
+}
+
+func (m *MockSourceControlDB) CalculateChangesRequestedRateForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	return floatResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate))
+}
+
+func (m *MockSourceControlDB) CalculateChangesRequestedRateGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	return seriesResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, interval))
+}
+
+func (m *MockSourceControlDB) CalculatePRsMergedWithoutApproval(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
+	return intResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricOperation))
+}
+
+func (m *MockSourceControlDB) CalculatePRsMergedWithoutApprovalGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+	return seriesResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricOperation, metricLabel, interval))
+}
+
+func (m *MockSourceControlDB) CalculatePRsMergedWithoutApprovalForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	return floatResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate))
+}
+
+func (m *MockSourceControlDB) CalculatePRsMergedWithoutApprovalGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	return seriesResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, interval))
+}
+
+func (m *MockSourceControlDB) CalculateCommitsPerPR(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*float64, error) {
+	return floatResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricOperation))
+}
+
+func (m *MockSourceControlDB) CalculateCommitsPerPRGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+	return seriesResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricOperation, metricLabel, interval))
+}
+
+func (m *MockSourceControlDB) CalculateCommitsPerPRForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	return floatResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate))
+}
+
+func (m *MockSourceControlDB) CalculateCommitsPerPRGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	return seriesResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, interval))
+}
+
+func (m *MockSourceControlDB) CalculateCodingTime(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
+	return intResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricOperation))
+}
+
+func (m *MockSourceControlDB) CalculateCodingTimeGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+	return seriesResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricOperation, metricLabel, interval))
+}
+
+func (m *MockSourceControlDB) CalculateCodingTimeForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	return floatResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate))
+}
+
+func (m *MockSourceControlDB) CalculateCodingTimeGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	return seriesResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, interval))
+}
+
+func (m *MockSourceControlDB) CalculateCommitsAfterFirstReview(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*float64, error) {
+	return floatResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricOperation))
+}
+
+func (m *MockSourceControlDB) CalculateCommitsAfterFirstReviewGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+	return seriesResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricOperation, metricLabel, interval))
+}
+
+func (m *MockSourceControlDB) CalculateCommitsAfterFirstReviewForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	return floatResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate))
+}
+
+func (m *MockSourceControlDB) CalculateCommitsAfterFirstReviewGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	return seriesResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, interval))
+}
+
+func (m *MockSourceControlDB) CalculateDeploymentFrequency(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
+	return intResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricOperation))
+}
+
+func (m *MockSourceControlDB) CalculateDeploymentFrequencyGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+	return seriesResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricOperation, metricLabel, interval))
+}
+
+func (m *MockSourceControlDB) CalculateDeploymentFrequencyForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	return floatResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate))
+}
+
+func (m *MockSourceControlDB) CalculateDeploymentFrequencyGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	return seriesResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, interval))
+}
+
+func (m *MockSourceControlDB) CalculateLeadTimeForChanges(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
+	return intResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricOperation))
+}
+
+func (m *MockSourceControlDB) CalculateLeadTimeForChangesGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+	return seriesResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricOperation, metricLabel, interval))
+}
+
+func (m *MockSourceControlDB) CalculateLeadTimeForChangesForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	return floatResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate))
+}
+
+func (m *MockSourceControlDB) CalculateLeadTimeForChangesGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	return seriesResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, interval))
+}
+
+func (m *MockSourceControlDB) CalculateChangeFailureRate(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*float64, error) {
+	return floatResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricOperation))
+}
+
+func (m *MockSourceControlDB) CalculateChangeFailureRateGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+	return seriesResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricOperation, metricLabel, interval))
+}
+
+func (m *MockSourceControlDB) CalculateChangeFailureRateForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	return floatResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate))
+}
+
+func (m *MockSourceControlDB) CalculateChangeFailureRateGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	return seriesResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, interval))
+}
+
+func (m *MockSourceControlDB) CalculateTimeToRestore(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*int, error) {
+	return intResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricOperation))
+}
+
+func (m *MockSourceControlDB) CalculateTimeToRestoreGraph(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+	return seriesResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricOperation, metricLabel, interval))
+}
+
+func (m *MockSourceControlDB) CalculateTimeToRestoreForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error) {
+	return floatResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate))
+}
+
+func (m *MockSourceControlDB) CalculateTimeToRestoreGraphForAccounts(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error) {
+	return seriesResult(m.Called(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, interval))
+}
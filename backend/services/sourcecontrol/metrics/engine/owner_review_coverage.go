@@ -0,0 +1,20 @@
+package engine
+
+import (
+	"ems.dev/backend/services/sourcecontrol/database"
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+)
+
+type OwnerReviewCoverageRule struct {
+	queryMetricRule[float64]
+}
+
+func NewOwnerReviewCoverageRule(baseMetricRule metrictypes.BaseMetricRule, sourceControlDB database.DB) *OwnerReviewCoverageRule {
+	return &OwnerReviewCoverageRule{queryMetricRule[float64]{
+		BaseMetricRule: baseMetricRule,
+		value:          sourceControlDB.CalculateOwnerReviewCoverage,
+		graph:          graphPerRepository(sourceControlDB.CalculateOwnerReviewCoverageGraph),
+		peersValue:     sourceControlDB.CalculateOwnerReviewCoverageForAccounts,
+		peersGraph:     sourceControlDB.CalculateOwnerReviewCoverageGraphForAccounts,
+	}}
+}
@@ -30,7 +30,7 @@ func NewPRReviewComplexityRule(base metrictypes.BaseMetricRule, sourceControlDB
 // Calculate implements the MetricRule interface
 func (r *PRReviewComplexityRule) Calculate(ctx context.Context, params types.MetricRuleParams) (*types.SnapshotMetric, *types.GraphMetric, error) {
 	// Extract parameters
-	organizationID, startDate, endDate, sourceControlAccountIDs, peersSourceControlAccountIDs, prPrefixes, err := r.extractParams(params)
+	organizationID, startDate, endDate, sourceControlAccountIDs, peersSourceControlAccountIDs, teamIDs, repositoryIDs, err := r.extractParams(params)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -40,7 +40,8 @@ func (r *PRReviewComplexityRule) Calculate(ctx context.Context, params types.Met
 		ctx,
 		*organizationID,
 		sourceControlAccountIDs,
-		prPrefixes,
+		teamIDs,
+		repositoryIDs,
 		*startDate,
 		*endDate,
 		r.Operation,
@@ -57,6 +58,7 @@ func (r *PRReviewComplexityRule) Calculate(ctx context.Context, params types.Met
 			*organizationID,
 			peersSourceControlAccountIDs,
 			nil,
+			nil,
 			*startDate,
 			*endDate,
 		)
@@ -78,7 +80,7 @@ func (r *PRReviewComplexityRule) Calculate(ctx context.Context, params types.Met
 	}
 
 	// Calculate graph metric
-	graphMetric, err := r.calculateGraphMetric(ctx, *organizationID, sourceControlAccountIDs, peersSourceControlAccountIDs, prPrefixes, *startDate, *endDate, params.Interval)
+	graphMetric, err := r.calculateGraphMetric(ctx, *organizationID, sourceControlAccountIDs, peersSourceControlAccountIDs, teamIDs, repositoryIDs, *startDate, *endDate, params.Interval)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to calculate graph metric: %w", err)
 	}
@@ -87,13 +89,14 @@ func (r *PRReviewComplexityRule) Calculate(ctx context.Context, params types.Met
 }
 
 // calculateGraphMetric calculates the time series data for the metric
-func (r *PRReviewComplexityRule) calculateGraphMetric(ctx context.Context, organizationID string, sourceControlAccountIDs []string, peersSourceControlAccountIDs []string, prPrefixes []string, startDate, endDate time.Time, interval string) (*types.GraphMetric, error) {
+func (r *PRReviewComplexityRule) calculateGraphMetric(ctx context.Context, organizationID string, sourceControlAccountIDs []string, peersSourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) (*types.GraphMetric, error) {
 	// Calculate time series data
 	timeSeriesData, err := r.sourceControlDB.CalculatePRReviewComplexityGraph(
 		ctx,
 		organizationID,
 		sourceControlAccountIDs,
-		prPrefixes,
+		teamIDs,
+		repositoryIDs,
 		startDate,
 		endDate,
 		r.Operation,
@@ -108,7 +111,7 @@ func (r *PRReviewComplexityRule) calculateGraphMetric(ctx context.Context, organ
 	var finalTimeSeries []types.TimeSeriesEntry
 	if len(peersSourceControlAccountIDs) > 0 {
 		// Calculate peer PR review complexity graph value
-		peersPRReviewComplexityGraphValue, err := r.sourceControlDB.CalculatePRReviewComplexityGraphForAccounts(ctx, organizationID, peersSourceControlAccountIDs, nil, startDate, endDate, interval)
+		peersPRReviewComplexityGraphValue, err := r.sourceControlDB.CalculatePRReviewComplexityGraphForAccounts(ctx, organizationID, peersSourceControlAccountIDs, nil, nil, startDate, endDate, interval)
 		if err != nil {
 			return nil, fmt.Errorf("failed to calculate peers PR review complexity graph: %w", err)
 		}
@@ -131,42 +134,42 @@ func (r *PRReviewComplexityRule) calculateGraphMetric(ctx context.Context, organ
 }
 
 // extractParams extracts and validates the metric parameters
-func (r *PRReviewComplexityRule) extractParams(params types.MetricRuleParams) (*string, *time.Time, *time.Time, []string, []string, []string, error) {
+func (r *PRReviewComplexityRule) extractParams(params types.MetricRuleParams) (*string, *time.Time, *time.Time, []string, []string, []string, []string, error) {
 	if params.Interval == "" {
-		return nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("interval is required")
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("interval is required")
 	}
 
 	if params.Interval != "daily" && params.Interval != "weekly" && params.Interval != "monthly" {
-		return nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid interval")
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid interval")
 	}
 
 	if params.StartDate == nil {
-		return nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("start date is required")
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("start date is required")
 	}
 
 	if params.EndDate == nil {
-		return nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("end date is required")
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("end date is required")
 	}
 
 	if params.MetricParams == nil {
-		return nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("metric params is required")
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("metric params is required")
 	}
 
 	// Unmarshal MetricParams to check for organization ID
 	var metricParams map[string]interface{}
 	if err := json.Unmarshal(params.MetricParams, &metricParams); err != nil {
-		return nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid metric params format")
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid metric params format")
 	}
 
 	// Check if organization ID is present, this is needed also a security measure to prevent unauthorized access to other organizations
 	orgID, exists := metricParams["organizationId"]
 	if !exists {
-		return nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("organization id is required")
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("organization id is required")
 	}
 
 	organizationID, ok := orgID.(string)
 	if !ok {
-		return nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid organization id format")
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid organization id format")
 	}
 
 	// If sourcecontrolaccountids is present check that these are valid uuids
@@ -177,7 +180,7 @@ func (r *PRReviewComplexityRule) extractParams(params types.MetricRuleParams) (*
 			for _, idInterface := range srcControlAccountIDsArray {
 				if id, ok := idInterface.(string); ok {
 					if _, err := uuid.Parse(id); err != nil {
-						return nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid source control account id")
+						return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid source control account id")
 					}
 					sourceControlAccountIDs = append(sourceControlAccountIDs, id)
 				}
@@ -193,7 +196,7 @@ func (r *PRReviewComplexityRule) extractParams(params types.MetricRuleParams) (*
 			for _, idInterface := range peersSrcControlAccountIDsArray {
 				if id, ok := idInterface.(string); ok {
 					if _, err := uuid.Parse(id); err != nil {
-						return nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid peer source control account id")
+						return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid peer source control account id")
 					}
 					peersSourceControlAccountIDs = append(peersSourceControlAccountIDs, id)
 				}
@@ -201,20 +204,33 @@ func (r *PRReviewComplexityRule) extractParams(params types.MetricRuleParams) (*
 		}
 	}
 
-	// Extract pr_prefixes if present
-	prPrefixesInterface, exists := metricParams["pr_prefixes"]
-	var prPrefixes []string
+	// Extract team_ids if present
+	teamIDsInterface, exists := metricParams["team_ids"]
+	var teamIDs []string
+	if exists {
+		if teamIDsArray, ok := teamIDsInterface.([]interface{}); ok {
+			for _, teamIDInterface := range teamIDsArray {
+				if teamID, ok := teamIDInterface.(string); ok {
+					teamIDs = append(teamIDs, teamID)
+				}
+			}
+		}
+	}
+
+	// Extract repository_ids if present
+	repositoryIDsInterface, exists := metricParams["repository_ids"]
+	var repositoryIDs []string
 	if exists {
-		if prPrefixesArray, ok := prPrefixesInterface.([]interface{}); ok {
-			for _, prefixInterface := range prPrefixesArray {
-				if prefix, ok := prefixInterface.(string); ok {
-					prPrefixes = append(prPrefixes, prefix)
+		if repositoryIDsArray, ok := repositoryIDsInterface.([]interface{}); ok {
+			for _, repositoryIDInterface := range repositoryIDsArray {
+				if repositoryID, ok := repositoryIDInterface.(string); ok {
+					repositoryIDs = append(repositoryIDs, repositoryID)
 				}
 			}
 		}
 	}
 
-	return &organizationID, params.StartDate, params.EndDate, sourceControlAccountIDs, peersSourceControlAccountIDs, prPrefixes, nil
+	return &organizationID, params.StartDate, params.EndDate, sourceControlAccountIDs, peersSourceControlAccountIDs, teamIDs, repositoryIDs, nil
 }
 
 // Category returns the category of the metric
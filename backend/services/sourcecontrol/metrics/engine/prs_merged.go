@@ -26,13 +26,13 @@ func NewPRsMergedRule(baseMetricRule metrictypes.BaseMetricRule, sourceControlDB
 
 func (r *PRsMergedRule) Calculate(ctx context.Context, params types.MetricRuleParams) (*types.SnapshotMetric, *types.GraphMetric, error) {
 	// Validate params
-	organizationID, startDate, endDate, sourceControlAccountIDs, peersSourceControlAccountIDs, prPrefixes, err := r.extractParams(params)
+	organizationID, startDate, endDate, sourceControlAccountIDs, peersSourceControlAccountIDs, teamIDs, repositoryIDs, err := r.extractParams(params)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// Calculate PRs merged value
-	prsMergedValue, err := r.sourceControlDB.CalculatePRsMerged(ctx, *organizationID, sourceControlAccountIDs, prPrefixes, *startDate, *endDate, r.Operation)
+	prsMergedValue, err := r.sourceControlDB.CalculatePRsMerged(ctx, *organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, *startDate, *endDate, r.Operation)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -42,7 +42,7 @@ func (r *PRsMergedRule) Calculate(ctx context.Context, params types.MetricRulePa
 	var timeSeries []types.TimeSeriesEntry
 
 	// Calculate PRs merged graph value
-	prsMergedGraphValue, err := r.sourceControlDB.CalculatePRsMergedGraph(ctx, *organizationID, sourceControlAccountIDs, prPrefixes, *startDate, *endDate, r.Operation, r.Name, params.Interval)
+	prsMergedGraphValue, err := r.sourceControlDB.CalculatePRsMergedGraph(ctx, *organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, *startDate, *endDate, r.Operation, r.Name, params.Interval)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -50,14 +50,14 @@ func (r *PRsMergedRule) Calculate(ctx context.Context, params types.MetricRulePa
 	// Only calculate peer values if peer account IDs are provided (member metrics only)
 	if len(peersSourceControlAccountIDs) > 0 {
 		// Calculate PRs merged peers value
-		peersPRsMergedValue, err := r.sourceControlDB.CalculatePRsMergedForAccounts(ctx, *organizationID, peersSourceControlAccountIDs, nil, *startDate, *endDate)
+		peersPRsMergedValue, err := r.sourceControlDB.CalculatePRsMergedForAccounts(ctx, *organizationID, peersSourceControlAccountIDs, nil, nil, *startDate, *endDate)
 		if err != nil {
 			return nil, nil, err
 		}
 		peersValue = float64(*peersPRsMergedValue)
 
 		// Calculate peer PRs merged graph value
-		peersPRsMergedGraphValue, err := r.sourceControlDB.CalculatePRsMergedGraphForAccounts(ctx, *organizationID, peersSourceControlAccountIDs, nil, *startDate, *endDate, params.Interval)
+		peersPRsMergedGraphValue, err := r.sourceControlDB.CalculatePRsMergedGraphForAccounts(ctx, *organizationID, peersSourceControlAccountIDs, nil, nil, *startDate, *endDate, params.Interval)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -93,42 +93,42 @@ func (r *PRsMergedRule) Category() types.MetricRuleCategory {
 	return r.BaseMetricRule.Category
 }
 
-func (r *PRsMergedRule) extractParams(params types.MetricRuleParams) (*string, *time.Time, *time.Time, []string, []string, []string, error) {
+func (r *PRsMergedRule) extractParams(params types.MetricRuleParams) (*string, *time.Time, *time.Time, []string, []string, []string, []string, error) {
 	if params.Interval == "" {
-		return nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("interval is required")
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("interval is required")
 	}
 
 	if params.Interval != "daily" && params.Interval != "weekly" && params.Interval != "monthly" {
-		return nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid interval")
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid interval")
 	}
 
 	if params.StartDate == nil {
-		return nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("start date is required")
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("start date is required")
 	}
 
 	if params.EndDate == nil {
-		return nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("end date is required")
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("end date is required")
 	}
 
 	if params.MetricParams == nil {
-		return nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("metric params is required")
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("metric params is required")
 	}
 
 	// Unmarshal MetricParams to check for organization ID
 	var metricParams map[string]interface{}
 	if err := json.Unmarshal(params.MetricParams, &metricParams); err != nil {
-		return nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid metric params format")
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid metric params format")
 	}
 
 	// Check if organization ID is present, this is needed also a security measure to prevent unauthorized access to other organizations
 	orgID, exists := metricParams["organizationId"]
 	if !exists {
-		return nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("organization id is required")
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("organization id is required")
 	}
 
 	organizationID, ok := orgID.(string)
 	if !ok {
-		return nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid organization id format")
+		return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid organization id format")
 	}
 
 	// If sourcecontrolaccountids is present check that these are valid uuids
@@ -139,7 +139,7 @@ func (r *PRsMergedRule) extractParams(params types.MetricRuleParams) (*string, *
 			for _, idInterface := range srcControlAccountIDsArray {
 				if id, ok := idInterface.(string); ok {
 					if _, err := uuid.Parse(id); err != nil {
-						return nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid source control account id")
+						return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid source control account id")
 					}
 					sourceControlAccountIDs = append(sourceControlAccountIDs, id)
 				}
@@ -155,7 +155,7 @@ func (r *PRsMergedRule) extractParams(params types.MetricRuleParams) (*string, *
 			for _, idInterface := range peersSrcControlAccountIDsArray {
 				if id, ok := idInterface.(string); ok {
 					if _, err := uuid.Parse(id); err != nil {
-						return nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid peers source control account id")
+						return nil, nil, nil, nil, nil, nil, nil, errors.NewBadRequestError("invalid peers source control account id")
 					}
 					peersSourceControlAccountIDs = append(peersSourceControlAccountIDs, id)
 				}
@@ -163,18 +163,31 @@ func (r *PRsMergedRule) extractParams(params types.MetricRuleParams) (*string, *
 		}
 	}
 
-	// Extract pr_prefixes if present
-	prPrefixesInterface, exists := metricParams["pr_prefixes"]
-	var prPrefixes []string
+	// Extract team_ids if present
+	teamIDsInterface, exists := metricParams["team_ids"]
+	var teamIDs []string
 	if exists {
-		if prPrefixesArray, ok := prPrefixesInterface.([]interface{}); ok {
-			for _, prefixInterface := range prPrefixesArray {
-				if prefix, ok := prefixInterface.(string); ok {
-					prPrefixes = append(prPrefixes, prefix)
+		if teamIDsArray, ok := teamIDsInterface.([]interface{}); ok {
+			for _, teamIDInterface := range teamIDsArray {
+				if teamID, ok := teamIDInterface.(string); ok {
+					teamIDs = append(teamIDs, teamID)
 				}
 			}
 		}
 	}
 
-	return &organizationID, params.StartDate, params.EndDate, sourceControlAccountIDs, peersSourceControlAccountIDs, prPrefixes, nil
+	// Extract repository_ids if present
+	repositoryIDsInterface, exists := metricParams["repository_ids"]
+	var repositoryIDs []string
+	if exists {
+		if repositoryIDsArray, ok := repositoryIDsInterface.([]interface{}); ok {
+			for _, repositoryIDInterface := range repositoryIDsArray {
+				if repositoryID, ok := repositoryIDInterface.(string); ok {
+					repositoryIDs = append(repositoryIDs, repositoryID)
+				}
+			}
+		}
+	}
+
+	return &organizationID, params.StartDate, params.EndDate, sourceControlAccountIDs, peersSourceControlAccountIDs, teamIDs, repositoryIDs, nil
 }
@@ -0,0 +1,20 @@
+package engine
+
+import (
+	"ems.dev/backend/services/sourcecontrol/database"
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+)
+
+type PRsMergedWithoutApprovalRule struct {
+	queryMetricRule[int]
+}
+
+func NewPRsMergedWithoutApprovalRule(baseMetricRule metrictypes.BaseMetricRule, sourceControlDB database.DB) *PRsMergedWithoutApprovalRule {
+	return &PRsMergedWithoutApprovalRule{queryMetricRule[int]{
+		BaseMetricRule: baseMetricRule,
+		value:          sourceControlDB.CalculatePRsMergedWithoutApproval,
+		graph:          sourceControlDB.CalculatePRsMergedWithoutApprovalGraph,
+		peersValue:     sourceControlDB.CalculatePRsMergedWithoutApprovalForAccounts,
+		peersGraph:     sourceControlDB.CalculatePRsMergedWithoutApprovalGraphForAccounts,
+	}}
+}
@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+	"ems.dev/backend/services/sourcecontrol/types"
+)
+
+type valueQuery[T int | float64] func(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation) (*T, error)
+
+type graphQuery func(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error)
+
+type peersValueQuery func(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time) (*float64, error)
+
+type peersGraphQuery func(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, interval string) ([]types.TimeSeriesEntry, error)
+
+// queryMetricRule is a metric rule whose snapshot and line graph are computed by database queries: the
+// value and graph of the selected accounts, teams and repositories, and the value and graph of the peers
+// for member metrics
+type queryMetricRule[T int | float64] struct {
+	metrictypes.BaseMetricRule
+	value      valueQuery[T]
+	graph      graphQuery
+	peersValue peersValueQuery
+	peersGraph peersGraphQuery
+}
+
+// graphPerRepository adapts a graph query with a series per repository, which are labelled by repository
+// instead of by the metric's name
+func graphPerRepository(graph func(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, interval string) ([]types.TimeSeriesEntry, error)) graphQuery {
+	return func(ctx context.Context, organizationID string, sourceControlAccountIDs []string, teamIDs []string, repositoryIDs []string, startDate, endDate time.Time, metricOperation metrictypes.MetricOperation, metricLabel string, interval string) ([]types.TimeSeriesEntry, error) {
+		return graph(ctx, organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, startDate, endDate, metricOperation, interval)
+	}
+}
+
+func (r *queryMetricRule[T]) Calculate(ctx context.Context, params types.MetricRuleParams) (*types.SnapshotMetric, *types.GraphMetric, error) {
+	// Validate params
+	organizationID, startDate, endDate, sourceControlAccountIDs, peersSourceControlAccountIDs, teamIDs, repositoryIDs, err := extractMetricRuleParams(params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	value, err := r.value(ctx, *organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, *startDate, *endDate, r.Operation)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	timeSeries, err := r.graph(ctx, *organizationID, sourceControlAccountIDs, teamIDs, repositoryIDs, *startDate, *endDate, r.Operation, r.Name, params.Interval)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Only calculate peer values if peer account IDs are provided (member metrics only)
+	var peersValue float64
+	if len(peersSourceControlAccountIDs) > 0 {
+		peersValueResult, err := r.peersValue(ctx, *organizationID, peersSourceControlAccountIDs, nil, nil, *startDate, *endDate)
+		if err != nil {
+			return nil, nil, err
+		}
+		peersValue = *peersValueResult
+
+		peersTimeSeries, err := r.peersGraph(ctx, *organizationID, peersSourceControlAccountIDs, nil, nil, *startDate, *endDate, params.Interval)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Merge peer values into the member's time series
+		timeSeries = mergeTimeSeriesWithPeers(timeSeries, peersTimeSeries, r.Name)
+	}
+
+	snapshotMetric := types.SnapshotMetric{
+		Label:          r.Name,
+		Description:    r.Description,
+		Unit:           r.Unit,
+		Value:          float64(*value),
+		PeersValue:     peersValue,
+		IconIdentifier: r.IconIdentifier,
+		IconColor:      r.IconColor,
+	}
+
+	graphMetric := types.GraphMetric{
+		Label:      r.Name,
+		Type:       "line",
+		Unit:       r.Unit,
+		TimeSeries: timeSeries,
+	}
+
+	return &snapshotMetric, &graphMetric, nil
+}
+
+func (r *queryMetricRule[T]) Category() types.MetricRuleCategory {
+	return r.BaseMetricRule.Category
+}
@@ -0,0 +1,39 @@
+package engine
+
+import (
+	"testing"
+
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+)
+
+func TestReviewMetricRules(t *testing.T) {
+	testMetricRules(t, []metricRuleTest{
+		{
+			name: "approvals given",
+			rule: func(base metrictypes.BaseMetricRule, db *MockSourceControlDB) metrictypes.MetricRule {
+				return NewApprovalsGivenRule(base, db)
+			},
+			method:    "CalculateApprovalsGiven",
+			operation: metrictypes.MetricOperationCount,
+			value:     intPtr(4),
+		},
+		{
+			name: "changes requested rate",
+			rule: func(base metrictypes.BaseMetricRule, db *MockSourceControlDB) metrictypes.MetricRule {
+				return NewChangesRequestedRateRule(base, db)
+			},
+			method:    "CalculateChangesRequestedRate",
+			operation: metrictypes.MetricOperationAverage,
+			value:     floatPtr(4),
+		},
+		{
+			name: "PRs merged without approval",
+			rule: func(base metrictypes.BaseMetricRule, db *MockSourceControlDB) metrictypes.MetricRule {
+				return NewPRsMergedWithoutApprovalRule(base, db)
+			},
+			method:    "CalculatePRsMergedWithoutApproval",
+			operation: metrictypes.MetricOperationCount,
+			value:     intPtr(4),
+		},
+	})
+}
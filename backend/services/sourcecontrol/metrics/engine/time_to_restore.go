@@ -0,0 +1,20 @@
+package engine
+
+import (
+	"ems.dev/backend/services/sourcecontrol/database"
+	metrictypes "ems.dev/backend/services/sourcecontrol/metrics/types"
+)
+
+type TimeToRestoreRule struct {
+	queryMetricRule[int]
+}
+
+func NewTimeToRestoreRule(baseMetricRule metrictypes.BaseMetricRule, sourceControlDB database.DB) *TimeToRestoreRule {
+	return &TimeToRestoreRule{queryMetricRule[int]{
+		BaseMetricRule: baseMetricRule,
+		value:          sourceControlDB.CalculateTimeToRestore,
+		graph:          sourceControlDB.CalculateTimeToRestoreGraph,
+		peersValue:     sourceControlDB.CalculateTimeToRestoreForAccounts,
+		peersGraph:     sourceControlDB.CalculateTimeToRestoreGraphForAccounts,
+	}}
+}
@@ -26,6 +26,20 @@ const (
 	MetricDimensionLOCAdded           MetricDimension = "LOC_ADDED"
 	MetricDimensionLOCRemoved         MetricDimension = "LOC_REMOVED"
 	MetricDimensionPRReviewComplexity MetricDimension = "PR_REVIEW_COMPLEXITY"
+	MetricDimensionApprovalsGiven     MetricDimension = "APPROVALS_GIVEN"
+	MetricDimensionChangesRequested   MetricDimension = "CHANGES_REQUESTED_RATE"
+	MetricDimensionUnapprovedPRs      MetricDimension = "MERGED_WITHOUT_APPROVAL"
+	MetricDimensionOwnerReviews       MetricDimension = "OWNER_REVIEW_COVERAGE"
+	MetricDimensionDeployments        MetricDimension = "DEPLOYMENT_FREQUENCY"
+	MetricDimensionLeadTime           MetricDimension = "LEAD_TIME_FOR_CHANGES"
+	MetricDimensionFailedDeployments  MetricDimension = "CHANGE_FAILURE_RATE"
+	MetricDimensionTimeToRestore      MetricDimension = "TIME_TO_RESTORE"
+	MetricDimensionCIDuration         MetricDimension = "CI_DURATION"
+	MetricDimensionCIFailures         MetricDimension = "CI_FAILURE_RATE"
+	MetricDimensionCheckWaitTime      MetricDimension = "CHECK_WAIT_TIME"
+	MetricDimensionCommitsPerPR       MetricDimension = "COMMITS_PER_PR"
+	MetricDimensionCodingTime         MetricDimension = "CODING_TIME"
+	MetricDimensionCommitsAfterReview MetricDimension = "COMMITS_AFTER_FIRST_REVIEW"
 )
 
 type MetricRule interface {
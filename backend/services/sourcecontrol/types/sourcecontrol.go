@@ -17,6 +17,7 @@ type SourceControlAccount struct {
 	ProviderID     string         `json:"provider_id"`
 	Username       string         `json:"username"`
 	Metadata       datatypes.JSON `json:"metadata,omitempty"`
+	IsBot          bool           `json:"is_bot"`
 	LastSyncedAt   *time.Time     `json:"last_synced_at,omitempty"`
 }
 
@@ -39,11 +40,20 @@ type PullRequest struct {
 	Additions         int            `json:"additions"`
 	Deletions         int            `json:"deletions"`
 	ChangedFiles      int            `json:"changed_files"`
-	Prefix            *string        `json:"prefix"`
+	Prefix            *string        `json:"prefix"`                         // Ticket key prefix the PR was attributed to its team by
+	TeamID            *string        `gorm:"type:uuid" json:"team_id"`       // Team the PR is attributed to by the team attribution rules
+	RepositoryID      *string        `gorm:"type:uuid" json:"repository_id"` // Repository the PR was opened against
+	HeadBranch        string         `json:"head_branch"`
+	Labels            datatypes.JSON `json:"labels"`
 	Metrics           datatypes.JSON `json:"metrics"`
+	MetricsVersion    int            `json:"metrics_version"` // Version of the calculation the metrics were stored with
 	Metadata          datatypes.JSON `json:"metadata"`
 }
 
+// PullRequestMetricsVersion is the version of the calculation of the metrics stored on pull requests. Bump it when
+// the calculation changes, the recompute job then rebuilds the metrics of the pull requests stored with an older one.
+const PullRequestMetricsVersion = 2
+
 // PRComment represents a comment on a pull request
 type PRComment struct {
 	ID                string `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
@@ -54,8 +64,221 @@ type PRComment struct {
 	Type              string
 	CreatedAt         time.Time
 	UpdatedAt         *time.Time
+	ReviewState       *string // State of REVIEW comments, one of the ReviewState constants
+}
+
+// Review states of REVIEW comments. GitHub's review states are stored as is, the other providers map
+// their approvals to them.
+const (
+	ReviewStateApproved         = "APPROVED"
+	ReviewStateChangesRequested = "CHANGES_REQUESTED"
+	ReviewStateCommented        = "COMMENTED"
+	ReviewStateDismissed        = "DISMISSED"
+)
+
+// PRCommit represents a commit of a pull request
+type PRCommit struct {
+	ID                string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	PRID              string    `json:"pr_id"`
+	ExternalAccountID *string   `gorm:"column:external_account_id" json:"external_account_id,omitempty"` // Nil when the author isn't linked to a provider account
+	SHA               string    `gorm:"column:sha" json:"sha"`
+	Message           string    `json:"message"`
+	AuthorName        string    `json:"author_name"`
+	AuthorEmail       string    `json:"author_email"`
+	AuthoredAt        time.Time `json:"authored_at"`
+	CommitterName     string    `json:"committer_name"`
+	CommitterEmail    string    `json:"committer_email"`
+	CommittedAt       time.Time `json:"committed_at"`
+	Additions         int       `json:"additions"`
+	Deletions         int       `json:"deletions"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// PRFile represents a file changed by a pull request
+type PRFile struct {
+	ID           string  `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	PRID         string  `json:"pr_id"`
+	Path         string  `json:"path"`
+	PreviousPath *string `json:"previous_path,omitempty"` // Only set on renamed files
+	Status       string  `json:"status"`                  // added, removed, modified, renamed, copied or changed
+	Additions    int     `json:"additions"`
+	Deletions    int     `json:"deletions"`
+}
+
+// PRCodeOwner represents a code owner required to review a pull request by the CODEOWNERS file of its repository
+type PRCodeOwner struct {
+	ID         string  `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	PRID       string  `json:"pr_id"`
+	Owner      string  `json:"owner"` // @user, @org/team or email
+	Files      int     `json:"files"` // Changed files owned by the owner
+	Reviewed   bool    `json:"reviewed"`
+	ReviewedBy *string `json:"reviewed_by,omitempty"` // External account of the first owner review
+}
+
+// FileHotspotParams represents the parameters for querying the most frequently changed files
+type FileHotspotParams struct {
+	OrganizationID string
+	RepositoryID   *string
+	StartDate      *time.Time
+	EndDate        *time.Time
+	Limit          int // Hotspots returned per repository
+}
+
+// FileHotspot represents a file which is frequently changed, with who changes it
+type FileHotspot struct {
+	RepositoryID   string               `json:"repository_id"`
+	RepositoryName string               `json:"repository_name"` // Owner and name of the repository
+	Path           string               `json:"path"`
+	Changes        int                  `json:"changes"` // Pull requests which changed the file
+	Additions      int                  `json:"additions"`
+	Deletions      int                  `json:"deletions"`
+	LastChangedAt  time.Time            `json:"last_changed_at"`
+	Contributors   []HotspotContributor `gorm:"-" json:"contributors"`
+	Teams          []HotspotTeam        `gorm:"-" json:"teams"`
+}
+
+// HotspotContributor represents an account which changed a hotspot
+type HotspotContributor struct {
+	RepositoryID      string  `json:"-"`
+	Path              string  `json:"-"`
+	ExternalAccountID string  `json:"external_account_id"`
+	Username          string  `json:"username"`
+	MemberID          *string `json:"member_id,omitempty"`
+	Changes           int     `json:"changes"`
+}
+
+// HotspotTeam represents a team whose members changed a hotspot
+type HotspotTeam struct {
+	RepositoryID string `json:"-"`
+	Path         string `json:"-"`
+	TeamID       string `json:"team_id"`
+	Name         string `json:"name"`
+	Changes      int    `json:"changes"`
+}
+
+// RepositoryHotspots represents the hotspots of a repository, most frequently changed first
+type RepositoryHotspots struct {
+	RepositoryID   string         `json:"repository_id"`
+	RepositoryName string         `json:"repository_name"` // Owner and name of the repository
+	Hotspots       []*FileHotspot `json:"hotspots"`
+}
+
+// PullRequestEvent represents a state or reviewer change on the timeline of a pull request
+type PullRequestEvent struct {
+	ID                string         `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	PRID              string         `json:"pr_id"`
+	ExternalAccountID string         `gorm:"column:external_account_id" json:"external_account_id"` // Account which triggered the event
+	ProviderID        string         `json:"provider_id"`
+	Event             string         `json:"event"` // One of the PullRequestEvent constants
+	Metadata          datatypes.JSON `json:"metadata"`
+	CreatedAt         time.Time      `json:"created_at"`
+}
+
+// Pull request timeline events, named after GitHub's timeline events
+const (
+	PullRequestEventReadyForReview  = "ready_for_review"
+	PullRequestEventConvertToDraft  = "convert_to_draft"
+	PullRequestEventReviewRequested = "review_requested"
+	PullRequestEventMerged          = "merged"
+	PullRequestEventClosed          = "closed"
+	PullRequestEventReopened        = "reopened"
+)
+
+// Repository represents a repository synced from a source control provider
+type Repository struct {
+	ID             string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	Provider       string    `json:"provider"`
+	Owner          string    `json:"owner"` // GitHub owner, GitLab namespace, Bitbucket workspace or project key
+	Name           string    `json:"name"`
+	DefaultBranch  *string   `json:"default_branch,omitempty"`
+	Archived       bool      `json:"archived"`
+	Visibility     *string   `json:"visibility,omitempty"`     // public, private or internal
+	TeamID         *string   `gorm:"type:uuid" json:"team_id"` // Team owning the repository
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
+// Repository visibilities
+const (
+	RepositoryVisibilityPublic   = "public"
+	RepositoryVisibilityPrivate  = "private"
+	RepositoryVisibilityInternal = "internal"
+)
+
+// RepositoryParams represents the parameters for querying the repositories of an organization
+type RepositoryParams struct {
+	OrganizationID  string
+	Provider        string
+	TeamID          *string
+	IncludeArchived bool
+}
+
+// Deployment represents a deployment of a repository, from the provider's deployments or its releases
+type Deployment struct {
+	ID                string     `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	OrganizationID    string     `json:"organization_id"`
+	RepositoryName    string     `json:"repository_name"`
+	RepositoryID      *string    `gorm:"type:uuid" json:"repository_id"` // Repository the deployment shipped
+	ProviderID        string     `json:"provider_id"`
+	Source            string     `json:"source"` // One of the DeploymentSource constants
+	Environment       string     `json:"environment"`
+	Production        bool       `json:"production"`
+	SHA               string     `gorm:"column:sha" json:"sha"`
+	Ref               string     `json:"ref"`
+	Status            string     `json:"status"`                                                          // One of the DeploymentStatus constants
+	ExternalAccountID *string    `gorm:"column:external_account_id" json:"external_account_id,omitempty"` // Account which created the deployment
+	CreatedAt         time.Time  `json:"created_at"`
+	FinishedAt        *time.Time `json:"finished_at,omitempty"` // Time of the final status, nil while pending
+}
+
+// Deployment sources
+const (
+	DeploymentSourceDeployment = "deployment"
+	DeploymentSourceRelease    = "release"
+)
+
+// Final deployment statuses, deployments without a final status yet are pending
+const (
+	DeploymentStatusSuccess = "success"
+	DeploymentStatusFailure = "failure"
+	DeploymentStatusError   = "error"
+	DeploymentStatusPending = "pending"
+)
+
+// DeploymentStatus represents a status reported for a deployment
+type DeploymentStatus struct {
+	ID           string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	DeploymentID string    `json:"deployment_id"`
+	ProviderID   string    `json:"provider_id"`
+	State        string    `json:"state"`
+	Description  string    `json:"description"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// CIRun represents a CI run of a pull request, a GitHub Actions workflow run or a check run of another CI provider
+type CIRun struct {
+	ID           string     `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	PRID         string     `json:"pr_id"`
+	RepositoryID *string    `gorm:"type:uuid" json:"repository_id"` // Repository of the pull request
+	ProviderID   string     `json:"provider_id"`
+	Source       string     `json:"source"` // One of the CIRunSource constants
+	Name         string     `json:"name"`
+	HeadSHA      string     `gorm:"column:head_sha" json:"head_sha"`
+	Status       string     `json:"status"`               // queued, in_progress or completed
+	Conclusion   *string    `json:"conclusion,omitempty"` // success, failure, timed_out, cancelled, skipped, ... once completed
+	Attempt      int        `json:"attempt"`              // Attempt of the run, every attempt of a re-run is stored
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// CI run sources
+const (
+	CIRunSourceWorkflowRun = "workflow_run"
+	CIRunSourceCheckRun    = "check_run"
+)
+
 // PullRequestParams represents the parameters for querying pull requests
 type PullRequestParams struct {
 	ProviderIDs    []string
@@ -63,11 +286,56 @@ type PullRequestParams struct {
 	RepositoryName string
 	UserIDs        []string
 	Prefix         *string
+	TeamID         *string
+	RepositoryID   *string
 	StartDate      *time.Time
 	EndDate        *time.Time
 	Status         string // "open", "closed", "merged"
 }
 
+// PullRequestAttributionParams represents the parameters for paging through the pull requests of an organization
+// to attribute them to teams
+type PullRequestAttributionParams struct {
+	OrganizationID string
+	AfterID        string // Pull requests are ordered by ID, the page starts after this one
+	Limit          int
+}
+
+// PullRequestAttribution is what a pull request is attributed to a team by, with its current team
+type PullRequestAttribution struct {
+	PRID           string
+	RepositoryName string
+	Title          string
+	HeadBranch     string
+	Labels         []string
+	Paths          []string
+	CommitMessages []string
+	TeamID         *string
+	Prefix         *string
+}
+
+// PullRequestTeam is the team attribution of a pull request, a nil TeamID detaches the pull request from its team
+type PullRequestTeam struct {
+	PRID   string
+	TeamID *string
+	Prefix *string
+}
+
+// RecomputeMetricsParams selects the pull requests whose stored metrics are rebuilt
+type RecomputeMetricsParams struct {
+	OrganizationID *string    // All organizations when nil
+	StartDate      *time.Time // Pull requests created from this date
+	EndDate        *time.Time // Pull requests created until this date
+	OutdatedOnly   bool       // Only the pull requests whose metrics were stored with an older PullRequestMetricsVersion
+	AfterID        string     // Pull requests are ordered by ID, the page starts after this one
+	Limit          int        // No limit when 0
+}
+
+// RecomputeMetricsResult reports how many pull requests had their metrics rebuilt
+type RecomputeMetricsResult struct {
+	PullRequests int `json:"pull_requests"`
+}
+
 type PullRequestMetrics struct {
 	MergedPRsCount         int
 	OpenPRsCount           int
@@ -185,6 +453,7 @@ type Unit string
 const (
 	UnitCount   Unit = "count" // nit: This is not a unit of measurement and probably should be renamed
 	UnitSeconds Unit = "seconds"
+	UnitPercent Unit = "percent"
 )
 
 // GraphMetric represents a single metric in the graph data
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	orgapi "ems.dev/backend/services/organization/api"
+	sourcecontrolapi "ems.dev/backend/services/sourcecontrol/api"
 	teamdb "ems.dev/backend/services/team/database"
 	"ems.dev/backend/services/team/types"
 )
@@ -33,16 +34,36 @@ type TeamAPI interface {
 
 	// RemoveTeamMember removes a member from a team
 	RemoveTeamMember(ctx context.Context, teamID string, memberID string) error
+
+	// ListAttributionRules returns the team attribution rules of an organization, in evaluation order
+	ListAttributionRules(ctx context.Context, organizationID string) ([]types.AttributionRule, error)
+
+	// CreateAttributionRule creates a rule attributing the matching pull requests to a team of the organization
+	CreateAttributionRule(ctx context.Context, organizationID string, req types.CreateAttributionRuleRequest) (*types.AttributionRule, error)
+
+	// UpdateAttributionRule updates an attribution rule of the organization
+	UpdateAttributionRule(ctx context.Context, organizationID string, ruleID string, req types.UpdateAttributionRuleRequest) (*types.AttributionRule, error)
+
+	// DeleteAttributionRule deletes an attribution rule of the organization
+	DeleteAttributionRule(ctx context.Context, organizationID string, ruleID string) error
+
+	// GetAttributor returns the Attributor of an organization, which the sync attributes pull requests to teams with
+	GetAttributor(ctx context.Context, organizationID string) (*types.Attributor, error)
+
+	// ReevaluateAttribution attributes the pull requests already imported to teams with the current rules
+	ReevaluateAttribution(ctx context.Context, organizationID string) (*types.ReevaluateAttributionResult, error)
 }
 
 type Api struct {
-	db     teamdb.DB
-	orgApi orgapi.OrganizationAPI
+	db               teamdb.DB
+	orgApi           orgapi.OrganizationAPI
+	sourceControlApi sourcecontrolapi.SourceControlAPI
 }
 
-func NewApi(teamDb teamdb.DB, orgApi orgapi.OrganizationAPI) *Api {
+func NewApi(teamDb teamdb.DB, orgApi orgapi.OrganizationAPI, sourceControlApi sourcecontrolapi.SourceControlAPI) *Api {
 	return &Api{
-		db:     teamDb,
-		orgApi: orgApi,
+		db:               teamDb,
+		orgApi:           orgApi,
+		sourceControlApi: sourceControlApi,
 	}
 }
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	"strings"
+
+	"ems.dev/backend/libraries/errors"
+	"ems.dev/backend/services/team/types"
+)
+
+func (a *Api) CreateAttributionRule(ctx context.Context, organizationID string, req types.CreateAttributionRuleRequest) (*types.AttributionRule, error) {
+	rule := &types.AttributionRule{
+		OrganizationID: organizationID,
+		TeamID:         req.TeamID,
+		Type:           req.Type,
+		Pattern:        strings.TrimSpace(req.Pattern),
+		Priority:       req.Priority,
+	}
+
+	if err := a.validateAttributionRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	if err := a.db.CreateAttributionRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// validateAttributionRule checks the pattern of a rule and that its team belongs to the organization
+func (a *Api) validateAttributionRule(ctx context.Context, rule *types.AttributionRule) error {
+	if err := types.ValidateAttributionRule(rule.Type, rule.Pattern); err != nil {
+		return errors.NewBadRequestError(err.Error())
+	}
+
+	team, err := a.db.GetTeam(ctx, rule.TeamID)
+	if err != nil {
+		return err
+	}
+	if team == nil || team.OrganizationID != rule.OrganizationID {
+		return errors.NewBadRequestError("team not found in this organization")
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package api
+
+import (
+	"context"
+)
+
+func (a *Api) DeleteAttributionRule(ctx context.Context, organizationID string, ruleID string) error {
+	if _, err := a.getOrganizationAttributionRule(ctx, organizationID, ruleID); err != nil {
+		return err
+	}
+
+	return a.db.DeleteAttributionRule(ctx, ruleID)
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"context"
+
+	"ems.dev/backend/services/team/types"
+)
+
+func (a *Api) GetAttributor(ctx context.Context, organizationID string) (*types.Attributor, error) {
+	teams, err := a.db.ListTeams(ctx, types.TeamSearchParams{
+		OrganizationID: &organizationID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := a.db.ListAttributionRules(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.NewAttributor(teams, rules), nil
+}
@@ -0,0 +1,11 @@
+package api
+
+import (
+	"context"
+
+	"ems.dev/backend/services/team/types"
+)
+
+func (a *Api) ListAttributionRules(ctx context.Context, organizationID string) ([]types.AttributionRule, error) {
+	return a.db.ListAttributionRules(ctx, organizationID)
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+
+	sourcecontroltypes "ems.dev/backend/services/sourcecontrol/types"
+	"ems.dev/backend/services/team/types"
+)
+
+// reevaluateBatchSize is the number of pull requests attributed at once
+const reevaluateBatchSize = 500
+
+func (a *Api) ReevaluateAttribution(ctx context.Context, organizationID string) (*types.ReevaluateAttributionResult, error) {
+	attributor, err := a.GetAttributor(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &types.ReevaluateAttributionResult{}
+	afterID := ""
+	for {
+		prs, err := a.sourceControlApi.GetPullRequestAttributions(ctx, &sourcecontroltypes.PullRequestAttributionParams{
+			OrganizationID: organizationID,
+			AfterID:        afterID,
+			Limit:          reevaluateBatchSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(prs) == 0 {
+			break
+		}
+
+		changed := []*sourcecontroltypes.PullRequestTeam{}
+		for _, pr := range prs {
+			team := &sourcecontroltypes.PullRequestTeam{PRID: pr.PRID}
+			if attribution := attributor.Attribute(types.AttributionInput{
+				RepositoryName: pr.RepositoryName,
+				Title:          pr.Title,
+				Branch:         pr.HeadBranch,
+				Labels:         pr.Labels,
+				Paths:          pr.Paths,
+				CommitMessages: pr.CommitMessages,
+			}); attribution != nil {
+				team.TeamID = &attribution.TeamID
+				team.Prefix = attribution.Prefix
+			}
+
+			if !equalStringPtr(team.TeamID, pr.TeamID) || !equalStringPtr(team.Prefix, pr.Prefix) {
+				changed = append(changed, team)
+			}
+		}
+
+		if err := a.sourceControlApi.UpdatePullRequestTeams(ctx, changed); err != nil {
+			return nil, err
+		}
+
+		result.PullRequests += len(prs)
+		result.Changed += len(changed)
+		afterID = prs[len(prs)-1].PRID
+	}
+
+	return result, nil
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sourcecontrolapi "ems.dev/backend/services/sourcecontrol/api"
+	sourcecontroltypes "ems.dev/backend/services/sourcecontrol/types"
+	teamdb "ems.dev/backend/services/team/database"
+	"ems.dev/backend/services/team/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTeamDB is a mock of the team database, only the reads of the attributor are implemented
+type MockTeamDB struct {
+	teamdb.DB
+	mock.Mock
+}
+
+func (m *MockTeamDB) ListTeams(ctx context.Context, params types.TeamSearchParams) ([]types.Team, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.Team), args.Error(1)
+}
+
+func (m *MockTeamDB) ListAttributionRules(ctx context.Context, organizationID string) ([]types.AttributionRule, error) {
+	args := m.Called(ctx, organizationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.AttributionRule), args.Error(1)
+}
+
+// MockSourceControlAPI is a mock of the source control API, only the pull request attributions are implemented
+type MockSourceControlAPI struct {
+	sourcecontrolapi.SourceControlAPI
+	mock.Mock
+}
+
+func (m *MockSourceControlAPI) GetPullRequestAttributions(ctx context.Context, params *sourcecontroltypes.PullRequestAttributionParams) ([]*sourcecontroltypes.PullRequestAttribution, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*sourcecontroltypes.PullRequestAttribution), args.Error(1)
+}
+
+func (m *MockSourceControlAPI) UpdatePullRequestTeams(ctx context.Context, teams []*sourcecontroltypes.PullRequestTeam) error {
+	args := m.Called(ctx, teams)
+	return args.Error(0)
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestReevaluateAttribution(t *testing.T) {
+	teams := []types.Team{{ID: "team-billing", PRPrefix: stringPtr("BIL")}}
+	rules := []types.AttributionRule{{ID: "rule-1", TeamID: "team-payments", Type: types.AttributionRulePathGlob, Pattern: "payments/"}}
+
+	tests := []struct {
+		name string
+		// pages are the pull requests returned by every page, the last page is followed by an empty one
+		pages          [][]*sourcecontroltypes.PullRequestAttribution
+		attributionErr error
+		updateErr      error
+		// expectedChanges are the attributions saved for every page
+		expectedChanges [][]*sourcecontroltypes.PullRequestTeam
+		expectedResult  *types.ReevaluateAttributionResult
+		expectedError   string
+	}{
+		{
+			name: "saves the pull requests whose attribution changed",
+			pages: [][]*sourcecontroltypes.PullRequestAttribution{
+				{
+					// Unchanged
+					{PRID: "pr-1", Title: "BIL-1 fix", TeamID: stringPtr("team-billing"), Prefix: stringPtr("BIL")},
+					// Attributed by a rule instead of the team prefix
+					{PRID: "pr-2", Title: "BIL-2 fix", Paths: []string{"payments/api.go"}, TeamID: stringPtr("team-billing"), Prefix: stringPtr("BIL")},
+				},
+				{
+					// No longer matching any rule
+					{PRID: "pr-3", Title: "fix", TeamID: stringPtr("team-billing")},
+					// Newly matching
+					{PRID: "pr-4", Title: "BIL-4 fix"},
+				},
+			},
+			expectedChanges: [][]*sourcecontroltypes.PullRequestTeam{
+				{{PRID: "pr-2", TeamID: stringPtr("team-payments")}},
+				{{PRID: "pr-3"}, {PRID: "pr-4", TeamID: stringPtr("team-billing"), Prefix: stringPtr("BIL")}},
+			},
+			expectedResult: &types.ReevaluateAttributionResult{PullRequests: 4, Changed: 3},
+		},
+		{
+			name:           "no pull requests",
+			expectedResult: &types.ReevaluateAttributionResult{},
+		},
+		{
+			name:           "fails when the pull requests can't be read",
+			attributionErr: errors.New("database error"),
+			expectedError:  "database error",
+		},
+		{
+			name: "fails when the attributions can't be saved",
+			pages: [][]*sourcecontroltypes.PullRequestAttribution{
+				{{PRID: "pr-1", Title: "BIL-1 fix"}},
+			},
+			updateErr:       errors.New("database error"),
+			expectedChanges: [][]*sourcecontroltypes.PullRequestTeam{{{PRID: "pr-1", TeamID: stringPtr("team-billing"), Prefix: stringPtr("BIL")}}},
+			expectedError:   "database error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &MockTeamDB{}
+			sourceControlApi := &MockSourceControlAPI{}
+
+			orgID := "org-1"
+			db.On("ListTeams", mock.Anything, types.TeamSearchParams{OrganizationID: &orgID}).Return(teams, nil)
+			db.On("ListAttributionRules", mock.Anything, orgID).Return(rules, nil)
+
+			afterID := ""
+			for _, page := range tt.pages {
+				sourceControlApi.On("GetPullRequestAttributions", mock.Anything, &sourcecontroltypes.PullRequestAttributionParams{OrganizationID: orgID, AfterID: afterID, Limit: reevaluateBatchSize}).Return(page, nil).Once()
+				afterID = page[len(page)-1].PRID
+			}
+			sourceControlApi.On("GetPullRequestAttributions", mock.Anything, &sourcecontroltypes.PullRequestAttributionParams{OrganizationID: orgID, AfterID: afterID, Limit: reevaluateBatchSize}).Return([]*sourcecontroltypes.PullRequestAttribution{}, tt.attributionErr).Maybe()
+			for _, changes := range tt.expectedChanges {
+				sourceControlApi.On("UpdatePullRequestTeams", mock.Anything, changes).Return(tt.updateErr).Once()
+			}
+
+			result, err := NewApi(db, nil, sourceControlApi).ReevaluateAttribution(context.Background(), orgID)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedResult, result)
+			}
+			sourceControlApi.AssertExpectations(t)
+		})
+	}
+}
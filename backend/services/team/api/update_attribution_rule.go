@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"ems.dev/backend/libraries/errors"
+	"ems.dev/backend/services/team/types"
+)
+
+func (a *Api) UpdateAttributionRule(ctx context.Context, organizationID string, ruleID string, req types.UpdateAttributionRuleRequest) (*types.AttributionRule, error) {
+	rule, err := a.getOrganizationAttributionRule(ctx, organizationID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.TeamID != nil {
+		rule.TeamID = *req.TeamID
+	}
+	if req.Type != nil {
+		rule.Type = *req.Type
+	}
+	if req.Pattern != nil {
+		rule.Pattern = strings.TrimSpace(*req.Pattern)
+	}
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	rule.UpdatedAt = time.Now()
+
+	if err := a.validateAttributionRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	if err := a.db.UpdateAttributionRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// getOrganizationAttributionRule returns an attribution rule, validating that it belongs to the organization
+func (a *Api) getOrganizationAttributionRule(ctx context.Context, organizationID string, ruleID string) (*types.AttributionRule, error) {
+	rule, err := a.db.GetAttributionRule(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil || rule.OrganizationID != organizationID {
+		return nil, errors.NewNotFoundError("attribution rule not found")
+	}
+	return rule, nil
+}
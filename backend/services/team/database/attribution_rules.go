@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"ems.dev/backend/services/team/types"
+	"gorm.io/gorm"
+)
+
+// ListAttributionRules retrieves the attribution rules of an organization, in evaluation order
+func (t *TeamDB) ListAttributionRules(ctx context.Context, organizationID string) ([]types.AttributionRule, error) {
+	var rules []types.AttributionRule
+	err := t.db.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Order("priority DESC, created_at ASC").
+		Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetAttributionRule retrieves an attribution rule by ID
+func (t *TeamDB) GetAttributionRule(ctx context.Context, id string) (*types.AttributionRule, error) {
+	var rule types.AttributionRule
+	err := t.db.WithContext(ctx).First(&rule, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// CreateAttributionRule creates an attribution rule
+func (t *TeamDB) CreateAttributionRule(ctx context.Context, rule *types.AttributionRule) error {
+	return t.db.WithContext(ctx).Create(rule).Error
+}
+
+// UpdateAttributionRule updates an attribution rule, a zero priority is written too
+func (t *TeamDB) UpdateAttributionRule(ctx context.Context, rule *types.AttributionRule) error {
+	return t.db.WithContext(ctx).Model(rule).
+		Select("team_id", "type", "pattern", "priority", "updated_at").
+		Updates(rule).Error
+}
+
+// DeleteAttributionRule deletes an attribution rule
+func (t *TeamDB) DeleteAttributionRule(ctx context.Context, id string) error {
+	return t.db.WithContext(ctx).Delete(&types.AttributionRule{}, "id = ?", id).Error
+}
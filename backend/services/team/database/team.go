@@ -17,6 +17,13 @@ type DB interface {
 	DeleteTeam(ctx context.Context, id string) error
 	AddTeamMember(ctx context.Context, teamID string, member *types.TeamMember) error
 	RemoveTeamMember(ctx context.Context, teamID string, memberID string) error
+
+	// Attribution rules
+	ListAttributionRules(ctx context.Context, organizationID string) ([]types.AttributionRule, error)
+	GetAttributionRule(ctx context.Context, id string) (*types.AttributionRule, error)
+	CreateAttributionRule(ctx context.Context, rule *types.AttributionRule) error
+	UpdateAttributionRule(ctx context.Context, rule *types.AttributionRule) error
+	DeleteAttributionRule(ctx context.Context, id string) error
 }
 
 type TeamDB struct {
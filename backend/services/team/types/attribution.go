@@ -0,0 +1,238 @@
+package types
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"ems.dev/backend/libraries/pathglob"
+)
+
+// AttributionRuleType represents what an attribution rule matches a pull request on
+type AttributionRuleType string
+
+const (
+	// AttributionRuleTicketPrefix matches a ticket key prefix followed by a hyphen (e.g. "WL" matches "WL-123: Fix bug")
+	// at the start of the title, the head branch or the first line of a commit message
+	AttributionRuleTicketPrefix AttributionRuleType = "ticket_prefix"
+	// AttributionRuleTitleRegex matches a regular expression against the title
+	AttributionRuleTitleRegex AttributionRuleType = "title_regex"
+	// AttributionRuleBranchRegex matches a regular expression against the head branch
+	AttributionRuleBranchRegex AttributionRuleType = "branch_regex"
+	// AttributionRuleLabel matches a label, case insensitively
+	AttributionRuleLabel AttributionRuleType = "label"
+	// AttributionRuleRepository matches the repository name, case insensitively and with * wildcards
+	AttributionRuleRepository AttributionRuleType = "repository"
+	// AttributionRulePathGlob matches a glob against the changed files, ** matches across directories
+	AttributionRulePathGlob AttributionRuleType = "path_glob"
+)
+
+// AttributionRule attributes the pull requests matching it to a team. Rules are evaluated by descending priority,
+// then by creation, and the first matching rule wins.
+type AttributionRule struct {
+	ID             string              `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	OrganizationID string              `json:"organization_id" gorm:"type:uuid"`
+	TeamID         string              `json:"team_id" gorm:"type:uuid"`
+	Type           AttributionRuleType `json:"type" gorm:"type:varchar(50)"`
+	Pattern        string              `json:"pattern" gorm:"type:varchar(255)"`
+	Priority       int                 `json:"priority"`
+	CreatedAt      time.Time           `json:"created_at" gorm:"default:now()"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (AttributionRule) TableName() string {
+	return "team_attribution_rules"
+}
+
+// CreateAttributionRuleRequest represents the request body for creating an attribution rule
+type CreateAttributionRuleRequest struct {
+	TeamID   string              `json:"team_id" binding:"required"`
+	Type     AttributionRuleType `json:"type" binding:"required,oneof=ticket_prefix title_regex branch_regex label repository path_glob"`
+	Pattern  string              `json:"pattern" binding:"required"`
+	Priority int                 `json:"priority"`
+}
+
+// UpdateAttributionRuleRequest represents the request body for updating an attribution rule
+type UpdateAttributionRuleRequest struct {
+	TeamID   *string              `json:"team_id"`
+	Type     *AttributionRuleType `json:"type" binding:"omitempty,oneof=ticket_prefix title_regex branch_regex label repository path_glob"`
+	Pattern  *string              `json:"pattern"`
+	Priority *int                 `json:"priority"`
+}
+
+// AttributionInput is what a pull request is attributed to a team by
+type AttributionInput struct {
+	RepositoryName string
+	Title          string
+	Branch         string
+	Labels         []string
+	Paths          []string
+	CommitMessages []string
+}
+
+// Attribution is the team a pull request is attributed to
+type Attribution struct {
+	TeamID string
+	// RuleID is the matching rule, nil when the pull request matched the PR prefix of the team
+	RuleID *string
+	// Prefix is the matching ticket key prefix, nil for the other rule types
+	Prefix *string
+}
+
+// ReevaluateAttributionResult reports how many imported pull requests changed team when the rules were evaluated again
+type ReevaluateAttributionResult struct {
+	PullRequests int `json:"pull_requests"`
+	Changed      int `json:"changed"`
+}
+
+// maxTicketPrefixLength is the size of the prefix column of pull requests
+const maxTicketPrefixLength = 50
+
+// ValidateAttributionRule checks that a rule pattern is valid for its type
+func ValidateAttributionRule(ruleType AttributionRuleType, pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("pattern is required")
+	}
+
+	switch ruleType {
+	case AttributionRuleTicketPrefix:
+		if strings.ContainsAny(pattern, " -") {
+			return fmt.Errorf("ticket prefix must not contain spaces or hyphens")
+		}
+		if len(pattern) > maxTicketPrefixLength {
+			return fmt.Errorf("ticket prefix must be at most %d characters", maxTicketPrefixLength)
+		}
+	case AttributionRuleTitleRegex, AttributionRuleBranchRegex:
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	case AttributionRuleLabel:
+	case AttributionRuleRepository:
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid repository pattern: %w", err)
+		}
+	case AttributionRulePathGlob:
+		if _, err := pathglob.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid path glob: %w", err)
+		}
+	default:
+		return fmt.Errorf("invalid rule type: %s", ruleType)
+	}
+
+	return nil
+}
+
+// compiledRule is an attribution rule ready to be matched
+type compiledRule struct {
+	ruleID   *string
+	teamID   string
+	ruleType AttributionRuleType
+	pattern  string
+	regexp   *regexp.Regexp
+}
+
+// Attributor attributes pull requests to the teams of an organization
+type Attributor struct {
+	rules []compiledRule
+}
+
+// NewAttributor builds an Attributor from the attribution rules and teams of an organization. The rules are evaluated
+// by descending priority, the PR prefixes of the teams last. Invalid rules are skipped, they are validated when saved.
+func NewAttributor(teams []Team, rules []AttributionRule) *Attributor {
+	sorted := make([]AttributionRule, len(rules))
+	copy(sorted, rules)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Priority != sorted[j].Priority {
+			return sorted[i].Priority > sorted[j].Priority
+		}
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	attributor := &Attributor{}
+	for _, rule := range sorted {
+		if ValidateAttributionRule(rule.Type, rule.Pattern) != nil {
+			continue
+		}
+
+		ruleID := rule.ID
+		compiled := compiledRule{ruleID: &ruleID, teamID: rule.TeamID, ruleType: rule.Type, pattern: rule.Pattern}
+		switch rule.Type {
+		case AttributionRuleTitleRegex, AttributionRuleBranchRegex:
+			compiled.regexp = regexp.MustCompile(rule.Pattern)
+		case AttributionRulePathGlob:
+			compiled.regexp, _ = pathglob.Compile(rule.Pattern)
+		}
+		attributor.rules = append(attributor.rules, compiled)
+	}
+
+	for _, team := range teams {
+		if team.PRPrefix != nil && *team.PRPrefix != "" {
+			attributor.rules = append(attributor.rules, compiledRule{teamID: team.ID, ruleType: AttributionRuleTicketPrefix, pattern: *team.PRPrefix})
+		}
+	}
+
+	return attributor
+}
+
+// Uses reports whether one of the rules is of the given type, which lets the sync skip fetching the commits or
+// changed files of a pull request when no rule needs them
+func (a *Attributor) Uses(ruleType AttributionRuleType) bool {
+	for _, rule := range a.rules {
+		if rule.ruleType == ruleType {
+			return true
+		}
+	}
+	return false
+}
+
+// Attribute returns the team of the first rule matching a pull request, nil if no rule matches
+func (a *Attributor) Attribute(input AttributionInput) *Attribution {
+	for _, rule := range a.rules {
+		if prefix, matched := rule.match(input); matched {
+			return &Attribution{TeamID: rule.teamID, RuleID: rule.ruleID, Prefix: prefix}
+		}
+	}
+	return nil
+}
+
+// match reports whether the rule matches the input, with the matching prefix of ticket prefix rules
+func (r compiledRule) match(input AttributionInput) (*string, bool) {
+	switch r.ruleType {
+	case AttributionRuleTicketPrefix:
+		lines := []string{input.Title, input.Branch}
+		for _, message := range input.CommitMessages {
+			// Check first line of commit message (commit messages can be multi-line)
+			lines = append(lines, strings.Split(strings.TrimSpace(message), "\n")[0])
+		}
+		prefix := strings.ToUpper(r.pattern) + "-"
+		for _, line := range lines {
+			if strings.HasPrefix(strings.ToUpper(line), prefix) {
+				return &r.pattern, true
+			}
+		}
+	case AttributionRuleTitleRegex:
+		return nil, r.regexp.MatchString(input.Title)
+	case AttributionRuleBranchRegex:
+		return nil, input.Branch != "" && r.regexp.MatchString(input.Branch)
+	case AttributionRuleLabel:
+		for _, label := range input.Labels {
+			if strings.EqualFold(label, r.pattern) {
+				return nil, true
+			}
+		}
+	case AttributionRuleRepository:
+		matched, _ := path.Match(strings.ToLower(r.pattern), strings.ToLower(input.RepositoryName))
+		return nil, matched
+	case AttributionRulePathGlob:
+		for _, filePath := range input.Paths {
+			if r.regexp.MatchString(strings.TrimPrefix(filePath, "/")) {
+				return nil, true
+			}
+		}
+	}
+	return nil, false
+}
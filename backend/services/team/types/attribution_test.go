@@ -0,0 +1,148 @@
+package types
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestValidateAttributionRule(t *testing.T) {
+	tests := []struct {
+		name          string
+		ruleType      AttributionRuleType
+		pattern       string
+		expectedError string
+	}{
+		{name: "ticket prefix", ruleType: AttributionRuleTicketPrefix, pattern: "WL"},
+		{name: "empty pattern", ruleType: AttributionRuleTicketPrefix, pattern: "  ", expectedError: "pattern is required"},
+		{name: "ticket prefix with a hyphen", ruleType: AttributionRuleTicketPrefix, pattern: "WL-", expectedError: "must not contain spaces or hyphens"},
+		{name: "ticket prefix with a space", ruleType: AttributionRuleTicketPrefix, pattern: "W L", expectedError: "must not contain spaces or hyphens"},
+		{name: "ticket prefix too long", ruleType: AttributionRuleTicketPrefix, pattern: strings.Repeat("A", maxTicketPrefixLength+1), expectedError: "at most 50 characters"},
+		{name: "title regex", ruleType: AttributionRuleTitleRegex, pattern: `^\[billing\]`},
+		{name: "invalid title regex", ruleType: AttributionRuleTitleRegex, pattern: "(", expectedError: "invalid pattern"},
+		{name: "invalid branch regex", ruleType: AttributionRuleBranchRegex, pattern: "[a-", expectedError: "invalid pattern"},
+		{name: "label", ruleType: AttributionRuleLabel, pattern: "team: billing"},
+		{name: "repository", ruleType: AttributionRuleRepository, pattern: "billing-*"},
+		{name: "invalid repository pattern", ruleType: AttributionRuleRepository, pattern: "billing-[", expectedError: "invalid repository pattern"},
+		{name: "path glob", ruleType: AttributionRulePathGlob, pattern: "services/billing/**"},
+		{name: "unknown type", ruleType: "owner", pattern: "x", expectedError: "invalid rule type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAttributionRule(tt.ruleType, tt.pattern)
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestAttributorAttribute(t *testing.T) {
+	tests := []struct {
+		name           string
+		rule           AttributionRule
+		input          AttributionInput
+		expectedMatch  bool
+		expectedPrefix *string
+	}{
+		{name: "ticket prefix in the title", rule: AttributionRule{Type: AttributionRuleTicketPrefix, Pattern: "WL"}, input: AttributionInput{Title: "wl-12: Fix"}, expectedMatch: true, expectedPrefix: stringPtr("WL")},
+		{name: "ticket prefix in the branch", rule: AttributionRule{Type: AttributionRuleTicketPrefix, Pattern: "WL"}, input: AttributionInput{Title: "Fix", Branch: "WL-12-fix"}, expectedMatch: true, expectedPrefix: stringPtr("WL")},
+		{name: "ticket prefix in the first line of a commit", rule: AttributionRule{Type: AttributionRuleTicketPrefix, Pattern: "WL"}, input: AttributionInput{Title: "Fix", CommitMessages: []string{"\n WL-12 fix\n\nbody"}}, expectedMatch: true, expectedPrefix: stringPtr("WL")},
+		{name: "ticket prefix in the body of a commit", rule: AttributionRule{Type: AttributionRuleTicketPrefix, Pattern: "WL"}, input: AttributionInput{Title: "Fix", CommitMessages: []string{"fix\n\nWL-12"}}},
+		{name: "ticket prefix needs the hyphen", rule: AttributionRule{Type: AttributionRuleTicketPrefix, Pattern: "WL"}, input: AttributionInput{Title: "WLAN fix"}},
+		{name: "title regex", rule: AttributionRule{Type: AttributionRuleTitleRegex, Pattern: `(?i)billing`}, input: AttributionInput{Title: "Fix Billing export"}, expectedMatch: true},
+		{name: "branch regex", rule: AttributionRule{Type: AttributionRuleBranchRegex, Pattern: `^billing/`}, input: AttributionInput{Branch: "billing/export"}, expectedMatch: true},
+		{name: "branch regex without a branch", rule: AttributionRule{Type: AttributionRuleBranchRegex, Pattern: `.*`}, input: AttributionInput{}},
+		{name: "label is case insensitive", rule: AttributionRule{Type: AttributionRuleLabel, Pattern: "Billing"}, input: AttributionInput{Labels: []string{"bug", "billing"}}, expectedMatch: true},
+		{name: "repository wildcard is case insensitive", rule: AttributionRule{Type: AttributionRuleRepository, Pattern: "Billing-*"}, input: AttributionInput{RepositoryName: "billing-api"}, expectedMatch: true},
+		{name: "repository mismatch", rule: AttributionRule{Type: AttributionRuleRepository, Pattern: "billing-*"}, input: AttributionInput{RepositoryName: "payments"}},
+		{name: "path glob matches a changed file", rule: AttributionRule{Type: AttributionRulePathGlob, Pattern: "services/billing/"}, input: AttributionInput{Paths: []string{"README.md", "/services/billing/api/export.go"}}, expectedMatch: true},
+		{name: "path glob is anchored", rule: AttributionRule{Type: AttributionRulePathGlob, Pattern: "billing/*.go"}, input: AttributionInput{Paths: []string{"services/billing/export.go"}}},
+		{name: "path glob double star", rule: AttributionRule{Type: AttributionRulePathGlob, Pattern: "**/billing/*.go"}, input: AttributionInput{Paths: []string{"services/billing/export.go"}}, expectedMatch: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.ID = "rule-1"
+			tt.rule.TeamID = "team-1"
+			attribution := NewAttributor(nil, []AttributionRule{tt.rule}).Attribute(tt.input)
+
+			if !tt.expectedMatch {
+				assert.Nil(t, attribution)
+				return
+			}
+			if assert.NotNil(t, attribution) {
+				assert.Equal(t, "team-1", attribution.TeamID)
+				assert.Equal(t, stringPtr("rule-1"), attribution.RuleID)
+				assert.Equal(t, tt.expectedPrefix, attribution.Prefix)
+			}
+		})
+	}
+}
+
+func TestAttributorPrecedence(t *testing.T) {
+	now := time.Now()
+	teams := []Team{
+		{ID: "team-prefix", PRPrefix: stringPtr("BIL")},
+		{ID: "team-without-prefix"},
+	}
+	rules := []AttributionRule{
+		{ID: "low", TeamID: "team-low", Type: AttributionRuleLabel, Pattern: "billing", Priority: 1, CreatedAt: now},
+		{ID: "high-newer", TeamID: "team-high-newer", Type: AttributionRuleLabel, Pattern: "billing", Priority: 10, CreatedAt: now.Add(time.Minute)},
+		{ID: "high-older", TeamID: "team-high-older", Type: AttributionRuleLabel, Pattern: "billing", Priority: 10, CreatedAt: now},
+		{ID: "invalid", TeamID: "team-invalid", Type: AttributionRuleTitleRegex, Pattern: "(", Priority: 100, CreatedAt: now},
+		{ID: "title", TeamID: "team-title", Type: AttributionRuleTitleRegex, Pattern: "export", Priority: 0, CreatedAt: now},
+	}
+
+	tests := []struct {
+		name           string
+		input          AttributionInput
+		expectedTeamID string
+		expectedRuleID *string
+	}{
+		{name: "highest priority wins, then the oldest rule", input: AttributionInput{Title: "BIL-1 export", Labels: []string{"billing"}}, expectedTeamID: "team-high-older", expectedRuleID: stringPtr("high-older")},
+		{name: "rules win over team prefixes", input: AttributionInput{Title: "BIL-1 export"}, expectedTeamID: "team-title", expectedRuleID: stringPtr("title")},
+		{name: "team prefixes are evaluated last", input: AttributionInput{Title: "BIL-1 fix"}, expectedTeamID: "team-prefix"},
+		{name: "no match", input: AttributionInput{Title: "fix"}},
+	}
+
+	attributor := NewAttributor(teams, rules)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attribution := attributor.Attribute(tt.input)
+			if tt.expectedTeamID == "" {
+				assert.Nil(t, attribution)
+				return
+			}
+			if assert.NotNil(t, attribution) {
+				assert.Equal(t, tt.expectedTeamID, attribution.TeamID)
+				assert.Equal(t, tt.expectedRuleID, attribution.RuleID)
+			}
+		})
+	}
+}
+
+func TestAttributorUses(t *testing.T) {
+	attributor := NewAttributor(
+		[]Team{{ID: "team-1", PRPrefix: stringPtr("WL")}},
+		[]AttributionRule{
+			{ID: "rule-1", TeamID: "team-1", Type: AttributionRuleLabel, Pattern: "billing"},
+			{ID: "rule-2", TeamID: "team-1", Type: AttributionRulePathGlob, Pattern: ""},
+		},
+	)
+
+	assert.True(t, attributor.Uses(AttributionRuleLabel))
+	assert.True(t, attributor.Uses(AttributionRuleTicketPrefix))
+	// Invalid rules are skipped
+	assert.False(t, attributor.Uses(AttributionRulePathGlob))
+	assert.False(t, attributor.Uses(AttributionRuleTitleRegex))
+}
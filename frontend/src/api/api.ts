@@ -537,6 +537,8 @@ export default class Api {
     userIds?: string[]
     repositoryName?: string
     prefix?: string
+    teamId?: string
+    repositoryId?: string
     startDate?: string
     endDate?: string
     status?: string
@@ -553,6 +555,12 @@ export default class Api {
     if (params?.prefix) {
       queryParams.append('prefix', params.prefix)
     }
+    if (params?.teamId) {
+      queryParams.append('teamId', params.teamId)
+    }
+    if (params?.repositoryId) {
+      queryParams.append('repositoryId', params.repositoryId)
+    }
     if (params?.startDate) {
       queryParams.append('startDate', params.startDate)
     }
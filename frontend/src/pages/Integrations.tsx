@@ -83,7 +83,9 @@ export default function Integrations() {
     const repositories = integration.metadata?.repositories 
       ? (typeof integration.metadata.repositories === 'string' 
           ? integration.metadata.repositories.split(',').map(r => r.trim())
-          : [])
+          : Array.isArray(integration.metadata.repositories)
+            ? integration.metadata.repositories.map((r: { name: string }) => r.name)
+            : [])
       : []
 
     return (
@@ -530,6 +530,26 @@ export default function MemberProfilePage() {
                   </span>
                 )}
 
+                {/* Coding time before the PR was opened */}
+                {activity.pr_metrics.coding_time_seconds !== undefined && (
+                  <span className="flex items-center space-x-1">
+                    <svg className="w-4 h-4 text-primary" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+                      <path strokeLinecap="round" strokeLinejoin="round" strokeWidth={2} d="M10 20l4-16m4 4l4 4-4 4M6 16l-4-4 4-4" />
+                    </svg>
+                    <span>Coding: {formatTimeMetric(activity.pr_metrics.coding_time_seconds)}</span>
+                  </span>
+                )}
+
+                {/* Commits, with the ones pushed after the first review */}
+                {activity.pr_metrics.commits_count !== undefined && activity.pr_metrics.commits_count > 0 && (
+                  <span className="flex items-center space-x-1">
+                    <span>
+                      {activity.pr_metrics.commits_count} commits
+                      {activity.pr_metrics.commits_after_first_review ? ` (${activity.pr_metrics.commits_after_first_review} after review)` : ''}
+                    </span>
+                  </span>
+                )}
+
                 {/* Show opened duration for open PRs */}
                 {activity.metadata?.state === 'open' && (
                   <span className="flex items-center space-x-1">
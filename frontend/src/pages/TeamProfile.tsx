@@ -113,17 +113,12 @@ export default function TeamProfilePage() {
     const loadOpenPRs = async () => {
       setOpenPRsLoading(true)
       try {
-        // Always use team prefix for filtering
-        if (team.pr_prefix) {
-          const prs = await Api.getOrganizationPullRequests(currentOrganization.id, {
-            prefix: team.pr_prefix,
-            status: 'open'
-          })
-          setOpenPRs(prs)
-        } else {
-          // No prefix, return empty array
-          setOpenPRs([])
-        }
+        // PRs are attributed to teams by the organization's attribution rules
+        const prs = await Api.getOrganizationPullRequests(currentOrganization.id, {
+          teamId: team.id,
+          status: 'open'
+        })
+        setOpenPRs(prs)
       } catch (err) {
         console.error('Error loading open PRs:', err)
         setOpenPRs([])
@@ -144,18 +139,13 @@ export default function TeamProfilePage() {
     const loadAllPRs = async () => {
       setAllPRsLoading(true)
       try {
-        // Always use team prefix for filtering
-        if (team.pr_prefix) {
-          const prs = await Api.getOrganizationPullRequests(currentOrganization.id, {
-            prefix: team.pr_prefix,
-            startDate: dateParams.startDate,
-            endDate: dateParams.endDate
-          })
-          setAllPRs(prs)
-        } else {
-          // No prefix, return empty array
-          setAllPRs([])
-        }
+        // PRs are attributed to teams by the organization's attribution rules
+        const prs = await Api.getOrganizationPullRequests(currentOrganization.id, {
+          teamId: team.id,
+          startDate: dateParams.startDate,
+          endDate: dateParams.endDate
+        })
+        setAllPRs(prs)
       } catch (err) {
         console.error('Error loading PRs:', err)
         setAllPRs([])
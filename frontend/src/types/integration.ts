@@ -7,6 +7,7 @@ export interface IntegrationConfig {
   provider_type: string
   encrypted_token: string
   metadata: Record<string, any>
+  sync_schedule?: string | null
   last_synced_at?: string
   created_at: string
   updated_at: string
@@ -16,9 +17,11 @@ export interface CreateIntegrationConfigRequest {
   provider_name: IntegrationProvider
   token: string
   metadata?: Record<string, any>
+  sync_schedule?: string
 }
 
 export interface UpdateIntegrationConfigRequest {
   token?: string
   metadata?: Record<string, any>
+  sync_schedule?: string
 } 
\ No newline at end of file
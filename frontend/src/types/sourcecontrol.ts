@@ -7,6 +7,7 @@ export interface ExternalAccount {
   provider_name: string
   provider_id: string
   username: string
+  is_bot: boolean // Bots are left out of the metrics
   last_synced_at?: string
 }
 
@@ -42,6 +43,7 @@ export interface PullRequest {
   deletions: number
   changed_files: number
   repository_name?: string
+  repository_id?: string
   author?: PullRequestAuthor
 }
 